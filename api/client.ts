@@ -0,0 +1,684 @@
+// Cliente de la API REST de printsnmp 1.0.0
+// Generado por `printsnmp openapi`: no editar a mano
+
+export interface CoverageReport {
+  answered: number;
+  attempted: number;
+  collected_at: string;
+  failed: number;
+  ip: string;
+  missing: number;
+  missing_sections?: string[] | null;
+  oids: {
+    error?: string;
+    name?: string;
+    oid: string;
+    op: string;
+    rows?: number;
+    status: string;
+    value?: string;
+  }[] | null;
+  printer_id: string;
+  sentinels: number;
+}
+
+export interface FleetAlert {
+  brand: string;
+  detected_at: string;
+  id: string;
+  ip: string;
+  message: string;
+  model?: string;
+  printer_id: string;
+  severity: string;
+  type: string;
+}
+
+export interface Principal {
+  method: string;
+  name: string;
+  role: string;
+}
+
+export interface PrinterSummary {
+  alert_count: number;
+  brand: string;
+  collected_at: string;
+  critical: boolean;
+  id: string;
+  ip: string;
+  location?: string;
+  model?: string;
+  page_count: number;
+  scan_id?: string;
+  serial_number?: string;
+  site?: string;
+  state: string;
+  supplies: {
+    brand?: string;
+    class?: string;
+    color?: string;
+    component_type?: string;
+    cost_per_page?: number;
+    description?: string;
+    fills_up?: boolean;
+    id: string;
+    level: number;
+    level_state?: string;
+    max_level: number;
+    model?: string;
+    name: string;
+    oem?: string;
+    page_capacity?: number;
+    part_number?: string;
+    percentage: number;
+    rated_yield?: number;
+    remaining_pages?: number;
+    replace_by?: string;
+    serial_number?: string;
+    status: string;
+    type: string;
+    unit?: string;
+  }[] | null;
+}
+
+export interface StatusResponse {
+  alerts: Record<string, number> | null;
+  events_dropped: number;
+  generated_at: string;
+  last_scan: {
+    diff: {
+      changed: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+      decommissioned?: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+      ip_changed: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+      missing: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+      new: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+      returned: {
+        change: string;
+        current?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+        fields?: string[] | null;
+        previous?: {
+          addresses?: string[] | null;
+          banner?: string;
+          brand?: string;
+          decommissioned?: boolean;
+          decommissioned_at?: string | null;
+          first_seen: string;
+          ip: string;
+          last_seen: string;
+          mac_address?: string;
+          model?: string;
+          no_snmp?: boolean;
+          online: boolean;
+          open_ports?: number[] | null;
+          printer_id: string;
+          serial_number?: string;
+        } | null;
+      }[] | null;
+    };
+    generated_at: string;
+    no_snmp?: {
+      addresses?: string[] | null;
+      banner?: string;
+      brand?: string;
+      decommissioned?: boolean;
+      decommissioned_at?: string | null;
+      first_seen: string;
+      ip: string;
+      last_seen: string;
+      mac_address?: string;
+      model?: string;
+      no_snmp?: boolean;
+      online: boolean;
+      open_ports?: number[] | null;
+      printer_id: string;
+      serial_number?: string;
+    }[] | null;
+    scan: {
+      aborted?: boolean;
+      devices_collected: number;
+      devices_found: number;
+      devices_merged?: number;
+      devices_no_snmp?: number;
+      duration_ms: number;
+      events_buffered: number;
+      events_dropped: number;
+      partial_devices: number;
+      scan_id?: string;
+      slow_devices: number;
+      started_at: string;
+    };
+    sites?: {
+      alerts: number;
+      delta_pages: number;
+      offline: number;
+      partial: number;
+      printers: number;
+      site: string;
+      total_pages: number;
+    }[] | null;
+    source: {
+      agent_id: string;
+      hostname: string;
+      os: string;
+      tenant?: string;
+      version: string;
+    };
+  } | null;
+  locale: string;
+  printers: number;
+  progress: {
+    done: boolean;
+    elapsed_ms: number;
+    eta_ms: number;
+    found: number;
+    phase: string;
+    scanned: number;
+    started_at: string;
+    total: number;
+  } | null;
+  source: {
+    agent_id: string;
+    hostname: string;
+    os: string;
+    tenant?: string;
+    version: string;
+  };
+  subscribers: number;
+}
+
+export interface TagsResponse {
+  assigned: Record<string, string> | null;
+  effective: Record<string, string> | null;
+}
+
+export interface Telemetry {
+  alerts?: {
+    detected_at: string;
+    id: string;
+    message: string;
+    severity: string;
+    type: string;
+  }[] | null;
+  capabilities?: {
+    color: boolean;
+    duplex: boolean;
+    fax: boolean;
+    oids_success_rate: number;
+    oids_supported: string[] | null;
+    scanner: boolean;
+    snmp_version: string;
+  } | null;
+  changed_sections?: string[] | null;
+  clock_skew_ms?: number | null;
+  collected_at: string;
+  costs?: {
+    color_cost_per_page?: number;
+    cost_per_page: number;
+    currency?: string;
+    monthly_color_pages?: number;
+    monthly_mono_pages?: number;
+    monthly_spend?: number;
+  } | null;
+  counters?: {
+    absolute: {
+      a3_pages?: number;
+      color_pages: number;
+      copy_pages: number;
+      duplex_pages?: number;
+      fax_pages: number;
+      mono_pages: number;
+      scan_pages: number;
+      total_pages: number;
+    };
+    anomalies?: {
+      counter: string;
+      previous?: number;
+      reason: string;
+      value: number;
+    }[] | null;
+    confidence?: Record<string, number> | null;
+    delta: {
+      a3_pages?: number;
+      color_pages: number;
+      copy_pages: number;
+      duplex_pages?: number;
+      fax_pages: number;
+      mono_pages: number;
+      scan_pages: number;
+      total_pages: number;
+    } | null;
+    reset_detected?: boolean;
+    sources?: Record<string, string> | null;
+  } | null;
+  emission?: string;
+  event_id: string;
+  extensions?: Record<string, unknown> | null;
+  idempotency_key?: string;
+  metrics?: {
+    polling?: {
+      error_count: number;
+      last_poll_at: string;
+      next_poll_at: string;
+      oid_success_rate: number;
+      poll_duration_ms: number;
+      response_time_ms: number;
+      retry_count: number;
+    } | null;
+  } | null;
+  printer: {
+    addresses?: string[] | null;
+    brand: string;
+    brand_confidence: number;
+    catalog?: {
+      class: string;
+      confidence: number;
+      name: string;
+      ppm?: number;
+      source: string;
+    } | null;
+    dns_name: string | null;
+    hostname: string | null;
+    hostname_sync?: string;
+    id: string;
+    interfaces?: {
+      admin_status?: string;
+      description?: string;
+      index: number;
+      ip_addresses?: string[] | null;
+      mac_address?: string;
+      netmasks?: string[] | null;
+      oper_status?: string;
+      speed_bps?: number;
+      type?: number;
+    }[] | null;
+    ip: string;
+    mac_address: string | null;
+    model: string | null;
+    serial_number: string | null;
+    site?: {
+      building?: string;
+      floor?: string;
+      room?: string;
+      site: string;
+    } | null;
+    sys_name: string | null;
+    tags?: Record<string, string> | null;
+  };
+  scan_id?: string;
+  schema_version: string;
+  sequence?: number;
+  source: {
+    agent_id: string;
+    hostname: string;
+    os: string;
+    tenant?: string;
+    version: string;
+  };
+  status?: {
+    error_state?: {
+      door_open: boolean;
+      input_tray_empty: boolean;
+      input_tray_missing: boolean;
+      jammed: boolean;
+      low_paper: boolean;
+      low_toner: boolean;
+      marker_supply_missing: boolean;
+      no_paper: boolean;
+      no_toner: boolean;
+      offline: boolean;
+      output_full: boolean;
+      output_near_full: boolean;
+      output_tray_missing: boolean;
+      overdue_prevent_maint: boolean;
+      service_requested: boolean;
+    } | null;
+    media_ready?: string[] | null;
+    page_count: number;
+    state: string;
+    system_location?: string;
+    system_uptime: string;
+    system_uptime_seconds: number;
+  } | null;
+  supplies?: {
+    brand?: string;
+    class?: string;
+    color?: string;
+    component_type?: string;
+    cost_per_page?: number;
+    description?: string;
+    fills_up?: boolean;
+    id: string;
+    level: number;
+    level_state?: string;
+    max_level: number;
+    model?: string;
+    name: string;
+    oem?: string;
+    page_capacity?: number;
+    part_number?: string;
+    percentage: number;
+    rated_yield?: number;
+    remaining_pages?: number;
+    replace_by?: string;
+    serial_number?: string;
+    status: string;
+    type: string;
+    unit?: string;
+  }[] | null;
+}
+
+export class PrintsnmpError extends Error {
+  constructor(public status: number, message: string) {
+    super(message);
+  }
+}
+
+export type QueryValue = string | number | boolean | Array<string | number> | undefined;
+
+export class PrintsnmpClient {
+  constructor(private baseUrl: string, private token?: string) {}
+
+  private async request<T>(method: string, path: string, query?: Record<string, QueryValue>, body?: unknown, contentType = "application/json"): Promise<T> {
+    const url = new URL(path, this.baseUrl);
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value === undefined) continue;
+      for (const item of Array.isArray(value) ? value : [value]) url.searchParams.append(key, String(item));
+    }
+    const headers: Record<string, string> = {};
+    if (this.token) headers.Authorization = "Bearer " + this.token;
+    let payload: string | undefined;
+    if (body !== undefined) {
+      headers["Content-Type"] = contentType;
+      payload = contentType === "application/json" ? JSON.stringify(body) : String(body);
+    }
+    const res = await fetch(url, { method, headers, body: payload });
+    if (!res.ok) {
+      const err = await res.json().catch(() => ({ error: res.statusText }));
+      throw new PrintsnmpError(res.status, err.error ?? res.statusText);
+    }
+    const json = res.headers.get("Content-Type")?.includes("json");
+    return (json ? res.json() : res.text()) as Promise<T>;
+  }
+
+  /** Agente, último ciclo y totales (viewer) */
+  getStatus(): Promise<StatusResponse> {
+    return this.request("GET", `/api/status`, undefined);
+  }
+
+  /** Resumen de la flota, filtrado y paginado (X-Total-Count = total antes de paginar) (viewer) */
+  listPrinters(query: { brand?: string | string[]; status?: string | string[]; supply_below?: number; supply_type?: string | string[]; site?: string | string[]; tag?: string | string[]; q?: string | string[]; scan_id?: string | string[]; sort?: string | string[]; limit?: number; offset?: number } = {}): Promise<PrinterSummary[]> {
+    return this.request("GET", `/api/printers`, query);
+  }
+
+  /** Última telemetría completa de una impresora (viewer) */
+  getPrinter(id: string): Promise<Telemetry> {
+    return this.request("GET", `/api/printers/${encodeURIComponent(id)}`, undefined);
+  }
+
+  /** OIDs intentados y respondidos en el último poll (viewer) */
+  getPrinterCoverage(id: string): Promise<CoverageReport> {
+    return this.request("GET", `/api/printers/${encodeURIComponent(id)}/coverage`, undefined);
+  }
+
+  /** Tags asignados por la API y vigentes (viewer) */
+  getPrinterTags(id: string): Promise<TagsResponse> {
+    return this.request("GET", `/api/printers/${encodeURIComponent(id)}/tags`, undefined);
+  }
+
+  /** Reemplaza los tags asignados por la API (se aplican en el próximo ciclo) (admin) */
+  setPrinterTags(id: string, body: Record<string, string>): Promise<{
+    status: string;
+  }> {
+    return this.request("PUT", `/api/printers/${encodeURIComponent(id)}/tags`, undefined, body);
+  }
+
+  /** Consulta la impresora en el momento y responde su telemetría (viewer) */
+  collectPrinter(id: string): Promise<Telemetry> {
+    return this.request("POST", `/api/printers/${encodeURIComponent(id)}/collect`, undefined);
+  }
+
+  /** Alertas activas de la flota, críticas primero (viewer) */
+  listAlerts(): Promise<FleetAlert[]> {
+    return this.request("GET", `/api/alerts`, undefined);
+  }
+
+  /** Identidad y rol del cliente (viewer) */
+  whoami(): Promise<Principal> {
+    return this.request("GET", `/api/whoami`, undefined);
+  }
+
+  /** Dispara un ciclo de escaneo (admin) */
+  triggerScan(): Promise<{
+    status: string;
+  }> {
+    return this.request("POST", `/api/scan`, undefined);
+  }
+
+  /** config.yaml tal como está en disco (admin) */
+  getConfig(): Promise<string> {
+    return this.request("GET", `/api/config`, undefined, undefined, "application/yaml");
+  }
+
+  /** Valida y reemplaza config.yaml (se aplica en el próximo ciclo) (admin) */
+  putConfig(body: string): Promise<{
+    status: string;
+  }> {
+    return this.request("PUT", `/api/config`, undefined, body, "application/yaml");
+  }
+
+  /** Consulta GraphQL (printers, printer, alerts, status, history) (viewer) */
+  graphql(body: {
+    operationName?: string;
+    query: string;
+    variables?: Record<string, unknown> | null;
+  }): Promise<Record<string, unknown>> {
+    return this.request("POST", `/api/graphql`, undefined, body);
+  }
+
+  /** Consulta GraphQL por GET (viewer) */
+  graphqlGet(query: { query?: string | string[]; operationName?: string | string[]; variables?: string | string[] } = {}): Promise<Record<string, unknown>> {
+    return this.request("GET", `/api/graphql`, query);
+  }
+}
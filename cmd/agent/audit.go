@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// auditLogs comparte un log de auditoría por archivo entre los motores del
+// proceso (serve, record y debug abren motores propios): una sola rotación
+var (
+	auditMu   sync.Mutex
+	auditLogs = make(map[string]*snmp.AuditLog)
+)
+
+// newAuditLog retorna el log de auditoría de snmp.audit (nil si está deshabilitado)
+// Sin auditoría no se consulta ningún equipo: un archivo que no abre es fatal
+func newAuditLog(cfg Config) snmp.Observer {
+	audit := cfg.SNMP.Audit
+	if !audit.Enabled {
+		return nil
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if existing, ok := auditLogs[audit.Path]; ok {
+		return existing
+	}
+	file, err := fsutil.OpenRotating(audit.Path, int64(audit.MaxSizeMB)*1024*1024, audit.Keep)
+	if err != nil {
+		log.Fatal(i18n.T("log.audit_error", err))
+	}
+	auditLog := snmp.NewAuditLog(file)
+	auditLogs[audit.Path] = auditLog
+	return auditLog
+}
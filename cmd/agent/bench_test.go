@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+)
+
+// Benchmarks de discovery y recolección contra el simulador, con la
+// configuración por defecto (motor, pacing y delay_ms de producción):
+//
+//	go test ./cmd/agent -run '^$' -bench . -benchtime 3x
+//
+// Reportan dispositivos/segundo por nivel de concurrencia (discovery.max_concurrent)
+
+// benchDevices son los simuladores: los fixtures incluidos repetidos, todos
+// en 127.0.0.1 con un puerto cada uno (como replay)
+const benchDevices = 24
+
+// benchWorkers son los niveles de concurrencia medidos
+var benchWorkers = []int{1, 8, 32}
+
+func BenchmarkDiscovery(b *testing.B) {
+	targets := startBenchSimulators(b, benchDevices)
+
+	for _, workers := range benchWorkers {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			cfg := benchConfig(workers)
+			for i := 0; i < b.N; i++ {
+				ds := scanner.NewDiscoveryScanner(newDiscoveryConfig(cfg, newEngine(cfg)))
+				results, err := ds.Scan(context.Background(), targets)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(results) != len(targets) {
+					b.Fatalf("%d de %d simuladores descubiertos", len(results), len(targets))
+				}
+			}
+			reportDeviceRate(b, len(targets))
+		})
+	}
+}
+
+// BenchmarkCollection mide la recolección completa; el colector se crea una
+// vez por nivel, así la primera iteración aprende los perfiles y el resto
+// mide el régimen normal
+func BenchmarkCollection(b *testing.B) {
+	targets := startBenchSimulators(b, benchDevices)
+
+	for _, workers := range benchWorkers {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			cfg := benchConfig(workers)
+			engine := newEngine(cfg)
+			discoveries, err := scanner.NewDiscoveryScanner(newDiscoveryConfig(cfg, engine)).Scan(context.Background(), targets)
+			if err != nil {
+				b.Fatal(err)
+			}
+			devices := newDeviceInfos(cfg, discoveries)
+
+			collectorConfig := newCollectorConfig(cfg, engine)
+			collectorConfig.ProfileDir = b.TempDir()
+			dc := collector.NewDataCollector(collectorConfig)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				data, err := dc.CollectData(context.Background(), devices)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, d := range data {
+					if d.Partial || len(d.Errors) > 0 {
+						b.Fatalf("%s: recolección incompleta: %v", d.IP, d.Errors)
+					}
+				}
+			}
+			reportDeviceRate(b, len(devices))
+		})
+	}
+}
+
+// benchConfig es la configuración por defecto con workers operaciones simultáneas
+func benchConfig(workers int) Config {
+	cfg := DefaultConfig()
+	cfg.Discovery.MaxConcurrent = workers
+	return cfg
+}
+
+// startBenchSimulators levanta n simuladores y retorna sus direcciones host:puerto
+func startBenchSimulators(b *testing.B, n int) []string {
+	b.Helper()
+	names := simulator.BuiltinNames()
+
+	targets := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		fixture, err := simulator.Builtin(names[i%len(names)])
+		if err != nil {
+			b.Fatal(err)
+		}
+		fixture.Community = "" // se acepta la community del config
+
+		agent, err := simulator.NewAgent(fixture)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := agent.Start("127.0.0.1:0"); err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { agent.Close() })
+
+		host, port := agent.Addr()
+		targets = append(targets, fmt.Sprintf("%s:%d", host, port))
+	}
+	return targets
+}
+
+// reportDeviceRate agrega la métrica devices/s
+func reportDeviceRate(b *testing.B, devices int) {
+	if elapsed := b.Elapsed().Seconds(); elapsed > 0 {
+		b.ReportMetric(float64(devices*b.N)/elapsed, "devices/s")
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/clock"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// agentClock es la hora corregida del agente, medida al arrancar
+// (nil = reloj local: clock.ntp_server vacío o servidor NTP inalcanzable)
+var agentClock *clock.Clock
+
+// detectClock mide el desfase del reloj del servidor contra clock.ntp_server
+// Un servidor inalcanzable no frena al agente: se sigue con la hora local
+func detectClock(cfg Config) *clock.Clock {
+	if cfg.Clock.NTPServer == "" {
+		return nil
+	}
+	skew, err := clock.QuerySkew(cfg.Clock.NTPServer, time.Duration(cfg.Clock.TimeoutMs)*time.Millisecond)
+	if err != nil {
+		log.Print(i18n.T("log.clock_ntp_error", cfg.Clock.NTPServer, err))
+		return nil
+	}
+
+	if skew.Abs() >= time.Duration(cfg.Clock.WarnMs)*time.Millisecond {
+		log.Print(i18n.T("log.clock_skew", skew.Round(time.Millisecond), cfg.Clock.NTPServer))
+	}
+	return clock.New(skew)
+}
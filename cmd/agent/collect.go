@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+	"github.com/asaavedra/agent-snmp/pkg/web"
+)
+
+// collectTimeout acota una consulta a pedido: alguien espera la respuesta
+const collectTimeout = 30 * time.Second
+
+// CollectPrinter consulta en el momento la impresora de last (POST
+// /api/printers/{id}/collect) con prioridad de estado: no queda detrás de los
+// walks del ciclo en curso. No toca state/ ni la queue: el delta se calcula
+// contra la última lectura guardada y el próximo ciclo emite como siempre
+func (d *daemon) CollectPrinter(ctx context.Context, last *telemetry.Telemetry) (*telemetry.Telemetry, error) {
+	id := last.Printer.ID
+	if !d.beginCollect(id) {
+		return nil, web.ErrCollectInProgress
+	}
+	defer d.endCollect(id)
+
+	cfg, ok := d.targetConfig(last.Source.Tenant)
+	if !ok {
+		return nil, fmt.Errorf("tenant %q is no longer configured", last.Source.Tenant)
+	}
+
+	device := collector.DeviceInfo{
+		IP:              last.Printer.IP,
+		Brand:           last.Printer.Brand,
+		BrandConfidence: last.Printer.BrandConfidence,
+		Community:       cfg.SNMP.Community,
+		SNMPv3:          cfg.SNMPv3(),
+	}
+	if last.Printer.DNSName != nil {
+		device.DNSName = *last.Printer.DNSName
+	}
+
+	dc := d.onDemandCollector(cfg)
+	data, ok := <-dc.CollectStream(ctx, []collector.DeviceInfo{device})
+	if !ok {
+		return nil, ctx.Err()
+	}
+	if data.Coverage == nil || data.Coverage.Answered+data.Coverage.Sentinels == 0 {
+		return nil, fmt.Errorf("%w: %s", web.ErrPrinterNoResponse, device.IP)
+	}
+	// La IP cambió de dueño (DHCP): la lectura no es de esta impresora
+	if data.PrinterID != "" && data.PrinterID != id {
+		return nil, fmt.Errorf("%s now answers as printer %s", device.IP, data.PrinterID)
+	}
+
+	// Solo lectura del estado: delta desde el último poll y volumen medido
+	stateKey := data.PrinterID
+	if stateKey == "" {
+		stateKey = data.IP
+	}
+	var delta *collector.CountersDiff
+	var resetDetected bool
+	if data.HasCounters() && !data.HasJumpAnomaly() {
+		delta, resetDetected = dc.History().CalculateDelta(stateKey, data.PageCounters)
+	}
+	if state, err := dc.History().LoadState(stateKey); err == nil && state != nil {
+		data.Usage = state.Usage
+	}
+
+	builder, err := newTelemetryBuilder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(&data, delta, resetDetected)
+}
+
+// onDemandCollector retorna el colector de las consultas a pedido del tenant de cfg:
+// se crea una vez por config (ver setConfig) y comparte con los ciclos el
+// motor y los perfiles
+func (d *daemon) onDemandCollector(cfg Config) *collector.DataCollector {
+	d.collectMu.Lock()
+	defer d.collectMu.Unlock()
+	if dc, ok := d.collectors[cfg.Tenant]; ok {
+		return dc
+	}
+
+	collectorConfig := newCollectorConfig(cfg, d.engine)
+	collectorConfig.History = collector.NewStateManager(cfg.StateDir())
+	collectorConfig.Interactive = true
+	collectorConfig.ScanBudget = 0
+	if collectorConfig.DeviceTimeout <= 0 || collectorConfig.DeviceTimeout > collectTimeout {
+		collectorConfig.DeviceTimeout = collectTimeout
+	}
+
+	dc := collector.NewDataCollector(collectorConfig)
+	if d.collectors == nil {
+		d.collectors = make(map[string]*collector.DataCollector)
+	}
+	d.collectors[cfg.Tenant] = dc
+	return dc
+}
+
+// beginCollect marca una consulta a pedido en curso; false si ya hay una para la impresora
+func (d *daemon) beginCollect(printerID string) bool {
+	d.collectMu.Lock()
+	defer d.collectMu.Unlock()
+	if d.collecting[printerID] {
+		return false
+	}
+	if d.collecting == nil {
+		d.collecting = make(map[string]bool)
+	}
+	d.collecting[printerID] = true
+	return true
+}
+
+// endCollect libera la marca de beginCollect
+func (d *daemon) endCollect(printerID string) {
+	d.collectMu.Lock()
+	defer d.collectMu.Unlock()
+	delete(d.collecting, printerID)
+}
+
+// targetConfig retorna la config vigente del objetivo de escaneo de un tenant
+// ("" = la config global sin tenants)
+func (d *daemon) targetConfig(tenant string) (Config, bool) {
+	cfg := d.config.Load()
+	if cfg == nil {
+		return Config{}, false
+	}
+	for _, target := range cfg.Targets() {
+		if target.Tenant == tenant {
+			return target, true
+		}
+	}
+	return Config{}, false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// TestCollectPrinterReusesCollector: las consultas a pedido usan un mismo
+// colector por config, con los perfiles del daemon, y se recrea al cambiar la config
+func TestCollectPrinterReusesCollector(t *testing.T) {
+	fixture, err := simulator.Builtin("hp_laserjet_m402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture.Community = ""
+	agent, err := simulator.NewAgent(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { agent.Close() })
+	host, port := agent.Addr()
+
+	prev := agentProfiles
+	agentProfiles = collector.LoadProfiles(t.TempDir())
+	t.Cleanup(func() { agentProfiles = prev })
+
+	cfg := testConfig(t)
+	cfg.SNMP.Port = port
+	cfg.State.Dir = t.TempDir()
+	d := &daemon{engine: newEngine(cfg)}
+	d.setConfig(cfg)
+
+	dc := d.onDemandCollector(cfg)
+	if dc.Profiles() != agentProfiles {
+		t.Fatal("el colector a pedido no usa los perfiles del daemon")
+	}
+
+	// El ID con que la impresora se identifica: lo que el ciclo dejó en last
+	first, ok := <-dc.CollectStream(context.Background(), []collector.DeviceInfo{{IP: host, Community: cfg.SNMP.Community}})
+	if !ok || first.PrinterID == "" {
+		t.Fatalf("la impresora no respondió: %v", first.Errors)
+	}
+	last := &telemetry.Telemetry{Printer: telemetry.PrinterInfo{ID: first.PrinterID, IP: host, Brand: first.Brand}}
+
+	for i := 0; i < 2; i++ {
+		event, err := d.CollectPrinter(context.Background(), last)
+		if err != nil {
+			t.Fatalf("consulta %d: %v", i+1, err)
+		}
+		if event.Printer.ID != first.PrinterID {
+			t.Errorf("consulta %d: impresora %q, se esperaba %q", i+1, event.Printer.ID, first.PrinterID)
+		}
+		if got := d.onDemandCollector(cfg); got != dc {
+			t.Fatalf("consulta %d creó otro colector", i+1)
+		}
+	}
+
+	d.setConfig(cfg)
+	if d.onDemandCollector(cfg) == dc {
+		t.Error("el colector sobrevivió a un cambio de config")
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/remote"
+	"github.com/asaavedra/agent-snmp/pkg/serializer"
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+const (
+	// commandLogPath guarda los IDs ya ejecutados (el backend puede reenviarlos)
+	commandLogPath = "state/commands.json"
+	// commandLogSize es cuántos IDs se recuerdan
+	commandLogSize = 200
+)
+
+// commandQueue consulta los comandos pendientes del backend (mode: cloud-sync)
+// El poll corre en segundo plano; los comandos se ejecutan en el loop del
+// daemon, entre ciclos, para no competir con la recolección
+type commandQueue struct {
+	fetcher *remote.Fetcher
+	url     string
+	pending chan remote.Command
+
+	mu   sync.Mutex
+	seen map[string]bool // encolados o ejecutados
+	done []string        // ejecutados, en orden (persistido)
+}
+
+// newCommandQueue retorna nil si no hay commands_url o el agente no está en cloud-sync
+func newCommandQueue(cfg Config) *commandQueue {
+	if cfg.Mode != "cloud-sync" || cfg.RemoteConfig.CommandsURL == "" {
+		return nil
+	}
+
+	fetcher, err := remote.NewFetcher(getAgentID(), cfg.HTTPSinkConfig())
+	if err != nil {
+		log.Print(i18n.T("log.command_fetch_error", err))
+		return nil
+	}
+
+	q := &commandQueue{
+		fetcher: fetcher,
+		url:     cfg.RemoteConfig.CommandsURL,
+		pending: make(chan remote.Command, 32),
+		seen:    make(map[string]bool),
+	}
+	if data, err := os.ReadFile(commandLogPath); err == nil {
+		if err := json.Unmarshal(data, &q.done); err != nil {
+			log.Print(i18n.T("log.command_fetch_error", err))
+		}
+	}
+	for _, id := range q.done {
+		q.seen[id] = true
+	}
+	return q
+}
+
+// commands retorna el canal de comandos por ejecutar (nil si no hay cola: nunca recibe)
+func (q *commandQueue) commands() <-chan remote.Command {
+	if q == nil {
+		return nil
+	}
+	return q.pending
+}
+
+// run consulta el backend cada interval hasta que ctx se cancele
+func (q *commandQueue) run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		q.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll encola los comandos nuevos
+func (q *commandQueue) poll(ctx context.Context) {
+	cmds, err := q.fetcher.FetchCommands(ctx, q.url)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Print(i18n.T("log.command_fetch_error", err))
+		}
+		return
+	}
+
+	for _, cmd := range cmds {
+		q.mu.Lock()
+		dup := cmd.ID == "" || q.seen[cmd.ID]
+		q.seen[cmd.ID] = true
+		q.mu.Unlock()
+		if dup {
+			continue
+		}
+
+		select {
+		case q.pending <- cmd:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// markDone registra el comando como ejecutado
+func (q *commandQueue) markDone(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.done = append(q.done, id)
+	if len(q.done) > commandLogSize {
+		q.done = q.done[len(q.done)-commandLogSize:]
+	}
+
+	data, err := json.Marshal(q.done)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(commandLogPath), 0755)
+	}
+	if err == nil {
+		err = fsutil.WriteFileAtomic(commandLogPath, data, 0644)
+	}
+	if err != nil {
+		log.Print(i18n.T("log.command_fetch_error", err))
+	}
+}
+
+// execute corre un comando remoto y encola su resultado como telemetría
+func (d *daemon) execute(ctx context.Context, cfg Config, cmd remote.Command) {
+	fmt.Println(i18n.T("log.command_received", cmd.Type, cmd.ID))
+	startedAt := time.Now()
+
+	status, message, output := d.dispatch(ctx, cfg, cmd)
+	if status == telemetry.CommandOK {
+		fmt.Println(i18n.T("log.command_done", cmd.ID, message))
+	} else {
+		log.Print(i18n.T("log.command_error", cmd.ID, message))
+	}
+
+	source := newAgentSource()
+	result := telemetry.NewBuilder(source).BuildCommandResult(cmd.ID, cmd.Type, cmd.Params, startedAt, status, message)
+	result.Output = output
+
+	if err := emitCommandResult(ctx, cfg, result); err != nil {
+		log.Print(i18n.T("log.command_error", cmd.ID, err))
+	}
+	d.commands.markDone(cmd.ID)
+}
+
+// dispatch ejecuta el comando según su tipo
+func (d *daemon) dispatch(ctx context.Context, cfg Config, cmd remote.Command) (status, message string, output json.RawMessage) {
+	switch cmd.Type {
+	case remote.CommandRescan:
+		if !d.TriggerScan() {
+			return telemetry.CommandFailed, "scan already running", nil
+		}
+		return telemetry.CommandOK, "scan triggered", nil
+
+	case remote.CommandSetInterval:
+		minutes, err := strconv.Atoi(cmd.Params["minutes"])
+		if err != nil || minutes <= 0 {
+			return telemetry.CommandRejected, "params.minutes must be a positive integer", nil
+		}
+		// Vale hasta reiniciar el daemon; luego manda daemon.interval_minutes
+		d.interval = time.Duration(minutes) * time.Minute
+		return telemetry.CommandOK, fmt.Sprintf("interval set to %d minutes", minutes), nil
+
+	case remote.CommandReprofile:
+		target := cmd.Params["ip"]
+		if target == "" {
+			target = cmd.Params["printer_id"]
+		}
+		if target == "" {
+			return telemetry.CommandRejected, "params.ip or params.printer_id required", nil
+		}
+		// Con los perfiles del daemon el olvido llega también a su caché
+		profiles := agentProfiles
+		if profiles == nil {
+			var err error
+			if profiles, err = profile.NewManager(profileDir); err != nil {
+				return telemetry.CommandFailed, err.Error(), nil
+			}
+		}
+		found, err := profiles.Forget(target)
+		if err != nil {
+			return telemetry.CommandFailed, err.Error(), nil
+		}
+		if !found {
+			return telemetry.CommandOK, "no profile stored for " + target + "; it will be discovered on the next poll", nil
+		}
+		return telemetry.CommandOK, "profile removed; it will be rediscovered on the next poll", nil
+
+	case remote.CommandDebugWalk:
+		ip := cmd.Params["ip"]
+		if net.ParseIP(ip) == nil {
+			return telemetry.CommandRejected, "params.ip must be an IP address", nil
+		}
+		fixture, err := debugWalk(ctx, cfg, ip)
+		if err != nil {
+			return telemetry.CommandFailed, err.Error(), nil
+		}
+		data, err := json.Marshal(fixture)
+		if err != nil {
+			return telemetry.CommandFailed, err.Error(), nil
+		}
+		return telemetry.CommandOK, fmt.Sprintf("%d OIDs recorded", len(fixture.Variables)), data
+	}
+
+	return telemetry.CommandRejected, fmt.Sprintf("unknown command type %q", cmd.Type), nil
+}
+
+// debugWalk graba el walk del dispositivo igual que `printsnmp record`
+func debugWalk(ctx context.Context, cfg Config, ip string) (*simulator.Fixture, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	client := newEngine(cfg).NewClient(ip, cfg.SNMP.Port, cfg.SNMP.Community, cfg.SNMP.Version,
+		time.Duration(cfg.SNMP.TimeoutMs)*time.Millisecond, cfg.SNMP.Retries).WithV3(cfg.SNMPv3()).
+		WithPriority(snmp.PriorityDiscovery)
+
+	fixture, err := simulator.Record(client, "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range fixture.Variables {
+		if v.OID == "1.3.6.1.2.1.1.1.0" {
+			fixture.Brand = detector.DetectBrand(v.Value)
+			break
+		}
+	}
+	fixture.Name = fixtureName(fixture.Model, ip)
+	fixture.Community = ""
+	fixture.Sort()
+	return fixture, nil
+}
+
+// emitCommandResult encola el resultado en el file sink (sube con el resto de la queue)
+func emitCommandResult(ctx context.Context, cfg Config, result *telemetry.CommandResult) error {
+	fileSink, err := newFileSink(cfg)
+	if err != nil {
+		return err
+	}
+	defer fileSink.Close()
+
+	jsonBytes, err := serializer.NewSerializer().SerializeCommandResult(result)
+	if err != nil {
+		return err
+	}
+	return fileSink.Write(ctx, jsonBytes, "command_"+sanitizeCommandID(result.CommandID))
+}
+
+// sanitizeCommandID deja el ID usable en un nombre de archivo
+func sanitizeCommandID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
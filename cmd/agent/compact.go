@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// historyRetention traduce state.retention
+func (cfg Config) historyRetention() collector.HistoryRetention {
+	r := cfg.State.Retention
+	return collector.HistoryRetention{
+		PollDays:  r.HistoryDays,
+		DailyDays: r.DailyDays,
+		StaleDays: r.StaleDays,
+	}
+}
+
+// retentionEnabled indica si hay algo que compactar
+func (cfg Config) retentionEnabled() bool {
+	r := cfg.State.Retention
+	return r.HistoryDays > 0 || r.DailyDays > 0 || r.StaleDays > 0
+}
+
+// compactInterval es la espera entre compactaciones del daemon
+func (cfg Config) compactInterval() time.Duration {
+	if cfg.State.Retention.CompactHours <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(cfg.State.Retention.CompactHours) * time.Hour
+}
+
+// compactState aplica state.retention al state/ de cada objetivo
+// Toma el lock de cada directorio: no corre a la par de un ciclo
+func compactState(cfg Config) {
+	for _, target := range cfg.Targets() {
+		dir := target.StateDir()
+		sm := collector.NewStateManager(dir)
+		if err := sm.Lock(stateLockTimeout); err != nil {
+			log.Print(i18n.T("log.state_locked", err))
+			continue
+		}
+		stats, err := sm.Compact(target.historyRetention(), time.Now())
+		sm.Unlock()
+		if err != nil {
+			log.Print(i18n.T("log.compaction_error", dir, err))
+		}
+		fmt.Println(i18n.T("log.compaction_done", dir, stats.Printers, stats.RolledUp, stats.Dropped, stats.DaysPruned, stats.StatesRemoved))
+	}
+}
+
+// runCompact implementa `printsnmp compact`: la compactación del daemon para
+// instalaciones que corren los ciclos por cron
+func runCompact(args []string) {
+	fs := flag.NewFlagSet("compact", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+
+	if !cfg.retentionEnabled() {
+		log.Fatal(i18n.T("log.compaction_disabled"))
+	}
+	compactState(cfg)
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOIDCRequiresAudience(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Web.OIDC.Issuer = "https://login.example.com"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "web.oidc.audience") {
+		t.Fatalf("issuer sin audience = %v, want error de web.oidc.audience", err)
+	}
+	cfg.Web.OIDC.Audience = "printsnmp"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("issuer con audience: %v", err)
+	}
+}
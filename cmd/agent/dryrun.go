@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// runDryRun hace el discovery de cada objetivo y muestra qué se recolectaría,
+// con qué credenciales y con qué perfil, sin WALKs, sin state/ y sin sinks
+// (-dry-run: para aprobar el agente antes de habilitarlo en una VLAN)
+func runDryRun(ctx context.Context, cfg Config, engine *snmp.Engine) {
+	profiles := loadProfilesReadOnly()
+
+	for _, target := range cfg.Targets() {
+		if target.Tenant != "" {
+			fmt.Println(i18n.T("log.tenant_cycle", target.Tenant, target.Discovery.IPRange))
+		}
+		ips, err := scanner.ParseIPRange(target.Discovery.IPRange)
+		if err != nil {
+			log.Print(i18n.T("log.range_invalid", err))
+			continue
+		}
+
+		discoveryConfig := newDiscoveryConfig(target, engine)
+		discoveryConfig.OnProgress = newProgressReporter(target, nil)
+		discoveries, err := scanner.NewDiscoveryScanner(discoveryConfig).Scan(ctx, ips)
+		if err != nil {
+			log.Print(i18n.T("log.discovery_error", err))
+			continue
+		}
+
+		printDryRun(target, newDeviceInfos(target, discoveries), profiles)
+	}
+}
+
+// loadProfilesReadOnly carga los perfiles existentes sin crear profiles/
+// (nil si todavía no hay ninguno)
+func loadProfilesReadOnly() *profile.Manager {
+	if _, err := os.Stat(profileDir); err != nil {
+		return nil
+	}
+	profiles, err := profile.NewManager(profileDir)
+	if err != nil {
+		return nil
+	}
+	if err := profiles.LoadAll(); err != nil {
+		log.Print(i18n.T("log.template_error", err))
+	}
+	return profiles
+}
+
+// printDryRun imprime una línea por dispositivo que se consultaría
+func printDryRun(cfg Config, devices []collector.DeviceInfo, profiles *profile.Manager) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IP\tBRAND\tSNMP\tCREDENTIALS\tPROFILE\tPLAN")
+
+	var known, fullDiscovery, probes int
+	for _, dev := range devices {
+		version := dev.SNMPVersion
+		if version == "" {
+			version = "2c"
+		}
+		port := cfg.SNMP.Port
+		if dev.Port != 0 {
+			port = dev.Port
+		}
+
+		profileDesc := "-"
+		plan := i18n.T("log.dryrun_plan_discovery")
+		var prof *profile.Profile
+		if profiles != nil {
+			prof = profiles.Lookup("", dev.IP)
+		}
+		switch {
+		case prof == nil:
+			fullDiscovery++
+		case prof.Breaker.Open():
+			profileDesc = describeProfile(prof)
+			plan = i18n.T("log.dryrun_plan_probe", prof.Breaker.OpenCycles)
+			probes++
+		default:
+			profileDesc = describeProfile(prof)
+			plan = i18n.T("log.dryrun_plan_profile")
+			known++
+		}
+
+		fmt.Fprintf(w, "%s:%d\t%s\tv%s\t%s\t%s\t%s\n", dev.IP, port, dev.Brand, version, describeCredentials(dev, version), profileDesc, plan)
+	}
+	w.Flush()
+
+	fmt.Println(i18n.T("log.dryrun_summary", len(devices), known, fullDiscovery, probes))
+}
+
+// describeProfile resume el perfil: ID, modelo y plantilla de origen
+func describeProfile(prof *profile.Profile) string {
+	desc := prof.PrinterID
+	if prof.Model != "" {
+		desc += " (" + prof.Model + ")"
+	}
+	if prof.Template != "" {
+		desc += " template=" + prof.Template
+	}
+	return desc
+}
+
+// describeCredentials identifica las credenciales sin mostrar los secretos
+func describeCredentials(dev collector.DeviceInfo, version string) string {
+	if version != "3" || dev.SNMPv3 == nil {
+		return "community=" + maskSecret(dev.Community)
+	}
+	v3 := dev.SNMPv3
+	parts := []string{"user=" + v3.Username}
+	if v3.AuthProtocol != "" {
+		parts = append(parts, "auth="+strings.ToUpper(v3.AuthProtocol))
+	}
+	if v3.PrivProtocol != "" {
+		parts = append(parts, "priv="+strings.ToUpper(v3.PrivProtocol))
+	}
+	if v3.ContextName != "" {
+		parts = append(parts, "context="+v3.ContextName)
+	}
+	return strings.Join(parts, " ")
+}
+
+// maskSecret deja ver solo el comienzo de un secreto ("pu***")
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "***"
+	}
+	return secret[:2] + "***"
+}
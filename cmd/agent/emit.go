@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/serializer"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// Sinks con modo de emisión propio (clave en state/ de lo último enviado)
+const (
+	emitQueue  = "queue"  // file sink: alimenta http, object y postgres
+	emitSyslog = "syslog" // resumen al SIEM
+)
+
+// emission es lo que se envía de una telemetría a un sink
+// commit registra el envío en state/: llamarlo solo si el sink lo aceptó
+type emission struct {
+	data   []byte
+	commit func() error
+}
+
+// planEmission serializa lo que corresponde enviar de telem a un sink según
+// su modo (sinks.*.emit). full reutiliza fullJSON y no toca state/
+func planEmission(cfg Config, sm *collector.StateManager, ser *serializer.Serializer, sinkName, mode, stateKey string, telem *telemetry.Telemetry, fullJSON []byte) (emission, error) {
+	if mode == "" || mode == telemetry.EmitFull {
+		return emission{data: fullJSON, commit: func() error { return nil }}, nil
+	}
+
+	record, sent := sm.LoadEmitted(stateKey, sinkName)
+	var prev map[string]string
+	if sent {
+		prev = record.Sections
+	}
+	forceFull := false
+	if hours := cfg.Sinks.FullSnapshotHours; hours > 0 {
+		forceFull = time.Since(record.FullAt) >= time.Duration(hours)*time.Hour
+	}
+
+	plan := telemetry.PlanEmission(telem, mode, prev, record.EventAt, forceFull)
+
+	var data []byte
+	var err error
+	switch {
+	case plan.NoChange != nil:
+		data, err = ser.SerializeNoChange(plan.NoChange)
+	case plan.Full:
+		data = fullJSON
+	default:
+		data, err = ser.Serialize(plan.Telemetry)
+	}
+	if err != nil {
+		return emission{}, err
+	}
+
+	commit := func() error {
+		record.Sections = plan.Sections
+		if plan.NoChange == nil {
+			record.EventAt = telem.CollectedAt
+		}
+		if plan.Full {
+			record.FullAt = telem.CollectedAt
+		}
+		return sm.SaveEmitted(stateKey, sinkName, record)
+	}
+	return emission{data: data, commit: commit}, nil
+}
@@ -2,32 +2,130 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/asaavedra/agent-snmp/pkg/collector"
 	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/modeldb"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
 	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/schema"
 	"github.com/asaavedra/agent-snmp/pkg/serializer"
 	"github.com/asaavedra/agent-snmp/pkg/sink"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
 	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+	"github.com/asaavedra/agent-snmp/pkg/web"
 )
 
+// stateLockTimeout es cuánto espera un ciclo a que otra instancia libere state/
+const stateLockTimeout = 10 * time.Second
+
+// agentStartedAt marca el inicio del proceso (uptime del heartbeat)
+var agentStartedAt = time.Now()
+
+// shutdownContext se cancela con la primera señal de parada (SIGINT/SIGTERM):
+// el ciclo en curso deja de consultar, emite lo ya recolectado y escribe el
+// resumen marcado como abortado. Una segunda señal corta el proceso
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop() // vuelve el manejo por defecto de las señales
+	}()
+	return ctx, stop
+}
+
+// beginScan asigna un ID nuevo (UUID v4) al ciclo que arranca y lo loguea
+// Va en cada telemetría, evento de inventario, estado escrito, resumen y
+// heartbeat del ciclo: soporte correlaciona "el escaneo de anoche" del
+// cliente entre la queue, state/, output.dir y el backend
+func beginScan(cfg *Config) {
+	cfg.ScanID = newScanID()
+	log.Print(i18n.T("log.scan_id", cfg.ScanID))
+}
+
+// newScanID genera un UUID v4
+func newScanID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // versión 4
+	b[8] = b[8]&0x3f | 0x80 // variante RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 func main() {
+	// Subcomandos: record <ip> | replay <fixture...> | problems <list|reprocess> | compact | secrets <set|delete|list> | queue <list|deadletter|requeue|flush> | meters [verify] | mib <compile|resolve> | profile <export|import|templates> | serve | schema [tipo...] | openapi [-out dir] | validate <archivo...>
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "record":
+			runRecord(os.Args[2:])
+			return
+		case "replay":
+			runReplay(os.Args[2:])
+			return
+		case "problems":
+			runProblems(os.Args[2:])
+			return
+		case "compact":
+			runCompact(os.Args[2:])
+			return
+		case "secrets":
+			runSecrets(os.Args[2:])
+			return
+		case "queue":
+			runQueue(os.Args[2:])
+			return
+		case "meters":
+			runMeters(os.Args[2:])
+			return
+		case "profile":
+			runProfile(os.Args[2:])
+			return
+		case "mib":
+			runMIB(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "schema":
+			runSchema(os.Args[2:])
+			return
+		case "openapi":
+			runOpenAPI(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		}
+	}
+
 	// Flags
 	configFile := flag.String("config", "config.yaml", "Archivo de configuración")
 	ipRangeOverride := flag.String("range", "", "Override del rango de IPs (ej: 192.168.1.1-254)")
 	verbose := flag.Bool("verbose", false, "Modo verbose (override de config)")
+	locale := flag.String("locale", "", "Idioma de logs y reportes: es | en (override de config)")
+	progress := flag.Bool("progress", false, "Barra de progreso del escaneo en stderr")
+	dryRun := flag.Bool("dry-run", false, "Solo discovery: mostrar qué dispositivos se consultarían, con qué credenciales y perfil, sin recolectar ni escribir")
 
 	flag.Parse()
 
 	// Cargar configuración desde YAML
 	cfg, err := LoadConfig(*configFile)
 	if err != nil {
-		log.Printf("⚠️  No se pudo leer config.yaml: %v", err)
+		log.Print(i18n.T("log.config_unreadable", err))
 		cfg = DefaultConfig()
 	}
 
@@ -38,180 +136,956 @@ func main() {
 	if *verbose {
 		cfg.Logging.Verbose = true
 	}
+	if *locale != "" {
+		cfg.Logging.Locale = *locale
+	}
+	if *progress {
+		cfg.Logging.ProgressBar = true
+	}
+	i18n.SetLocale(cfg.Logging.Locale)
+	loadMIBs(cfg)
+
+	// Communities y tokens pueden venir del vault cifrado ("secret:<nombre>")
+	if err := resolveSecrets(&cfg); err != nil {
+		log.Fatal(i18n.T("log.secrets_resolve_error", err))
+	}
+
+	ctx, stop := shutdownContext()
+	defer stop()
+
+	// mode: cloud-sync → rangos, credenciales y sinks asignados por el backend
+	if rs := newRemoteSync(cfg); rs != nil {
+		rs.fetch(ctx)
+		cfg = rs.apply(cfg)
+	}
 
-	// Validar rango
-	if cfg.Discovery.IPRange == "" {
-		log.Fatalf("Error: Se requiere ip_range en config.yaml o -range en flags")
+	// Validar rango (uno por tenant)
+	requireTargets(cfg)
+
+	// Motor SNMP compartido: limita concurrencia y paquetes/segundo globalmente
+	engine := newEngine(cfg)
+
+	if *dryRun {
+		if !cfg.Discovery.Enabled {
+			log.Fatal(i18n.T("log.discovery_disabled"))
+		}
+		runDryRun(ctx, cfg, engine)
+		return
+	}
+
+	agentClock = detectClock(cfg)
+
+	// Multi-tenant: un ciclo por tenant; un tenant sin equipos no corta a los demás
+	if len(cfg.Tenants) > 0 {
+		if !cfg.Discovery.Enabled {
+			log.Fatal(i18n.T("log.discovery_disabled"))
+		}
+		for _, target := range cfg.Targets() {
+			runTarget(ctx, target, engine, nil)
+		}
+		return
 	}
 
 	// Parsear rango de IPs
 	ips, err := scanner.ParseIPRange(cfg.Discovery.IPRange)
 	if err != nil {
-		log.Fatalf("Error parseando rango: %v", err)
+		log.Fatal(i18n.T("log.range_invalid", err))
 	}
 
-	discoveryConfig := scanner.DiscoveryConfig{
-		MaxConcurrentConnections: cfg.Discovery.MaxConcurrent,
-		TimeoutPerDevice:         time.Duration(cfg.SNMP.TimeoutMs) * time.Millisecond,
-		Retries:                  cfg.SNMP.Retries,
-		Community:                cfg.SNMP.Community,
-		SNMPVersion:              cfg.SNMP.Version,
-		SNMPPort:                 cfg.SNMP.Port,
-	}
+	discoveryConfig := newDiscoveryConfig(cfg, engine)
+	discoveryConfig.OnProgress = newProgressReporter(cfg, nil)
 
 	// Ejecutar discovery
 	startTime := time.Now()
-	ctx := context.Background()
+	beginScan(&cfg)
 
 	if cfg.Discovery.Enabled {
 		discoveryScanner := scanner.NewDiscoveryScanner(discoveryConfig)
 		discoveries, err := discoveryScanner.Scan(ctx, ips)
 		if err != nil {
-			log.Fatalf("Error during discovery: %v", err)
+			log.Fatal(i18n.T("log.discovery_error", err))
+		}
+		if ctx.Err() != nil {
+			log.Print(i18n.T("log.discovery_aborted"))
+			return
 		}
 
-		if len(discoveries) == 0 {
-			log.Fatalf("No SNMP devices found in range")
+		noSNMP := discoveryScanner.NoSNMP()
+		if len(discoveries) == 0 && len(noSNMP) == 0 {
+			// El heartbeat sale igual: un rango mal configurado debe verse en el backend
+			emitHeartbeat(ctx, cfg, telemetry.ScanStats{
+				ScanID:     cfg.ScanID,
+				StartedAt:  startTime.UTC(),
+				DurationMs: time.Since(startTime).Milliseconds(),
+			}, telemetry.ErrorCounts{})
+			log.Fatal(i18n.T("log.no_devices"))
+		}
+		if err := processPrinters(ctx, cfg, engine, discoveries, noSNMP, startTime, nil); err != nil {
+			log.Fatal(err)
 		}
-		processPrinters(ctx, cfg, discoveries, startTime)
 	} else {
-		log.Fatalf("Discovery disabled in config.yaml")
+		log.Fatal(i18n.T("log.discovery_disabled"))
 	}
 }
 
-func processPrinters(ctx context.Context, cfg Config, discoveries []scanner.DiscoveryResult, startTime time.Time) {
+// processPrinters corre un ciclo de recolección sobre los dispositivos descubiertos
+// noSNMP son los hosts sin SNMP del fingerprint: solo entran al inventario
+// Con store != nil cada telemetría y el resumen del ciclo se publican en el dashboard
+func processPrinters(ctx context.Context, cfg Config, engine *snmp.Engine, discoveries, noSNMP []scanner.DiscoveryResult, startTime time.Time, store *web.Store) error {
 
 	// Detectar marca para cada dispositivo
-	deviceInfos := make([]collector.DeviceInfo, 0, len(discoveries))
+	deviceInfos := newDeviceInfos(cfg, discoveries)
 
-	for _, disc := range discoveries {
-		brand := detector.DetectBrand(disc.SysDescr)
-		confidence := detector.GetBrandConfidence(disc.SysDescr, brand)
-
-		deviceInfo := collector.DeviceInfo{
-			IP:              disc.IP,
-			Brand:           brand,
-			BrandConfidence: confidence,
-			SysDescr:        disc.SysDescr,
-			Community:       cfg.SNMP.Community,
-			SNMPVersion:     cfg.SNMP.Version,
-		}
+	// Los dispositivos que no terminaron en el ciclo anterior van primero
+	stateManager := collector.NewStateManager(cfg.StateDir()) // Directorio para persistir estado
+	stateManager.SetScanID(cfg.ScanID)
 
-		deviceInfos = append(deviceInfos, deviceInfo)
+	// Un solo escritor de state/: otra instancia (o cron superpuesto) aborta este ciclo
+	if err := stateManager.Lock(stateLockTimeout); err != nil {
+		return errors.New(i18n.T("log.state_locked", err))
+	}
+	defer stateManager.Unlock()
+	if slow := stateManager.LoadSlowDevices(); len(slow) > 0 {
+		deviceInfos = prioritizeDevices(deviceInfos, slow)
+		fmt.Println(i18n.T("log.slow_prioritized", len(slow)))
 	}
 
 	// Configurar colector de datos
-	collectorConfig := collector.Config{
-		Timeout:                  time.Duration(cfg.SNMP.TimeoutMs) * time.Millisecond,
-		Retries:                  cfg.SNMP.Retries,
-		MaxConcurrentConnections: cfg.Discovery.MaxConcurrent,
-		MaxOidsPerDevice:         10,
-		MinDelayBetweenQueries:   time.Duration(cfg.Collector.DelayMs) * time.Millisecond,
-		Community:                cfg.SNMP.Community,
-		SNMPVersion:              cfg.SNMP.Version,
-		SNMPPort:                 cfg.SNMP.Port,
-	}
+	collectorConfig := newCollectorConfig(cfg, engine)
+	collectorConfig.History = stateManager
 
 	// Recolectar datos
 	if cfg.Collector.Enabled {
-		fmt.Printf("📊 Recolectando datos de impresoras...\n")
+		fmt.Println(i18n.T("log.collecting"))
 		dataCollector := collector.NewDataCollector(collectorConfig)
-		printerDataList, err := dataCollector.CollectData(ctx, deviceInfos)
+
+		// ========== FLUJO NUEVO: TELEMETRY → SINK ==========
+
+		// Crear builder y serializer
+		builder, err := newTelemetryBuilder(cfg)
 		if err != nil {
-			log.Fatalf("Error recolectando datos: %v", err)
+			return err
 		}
+		builder.SetScanID(cfg.ScanID)
+		ser := serializer.NewSerializer()
+		telemetrySchema := newTelemetrySchema(cfg)
 
-		fmt.Printf("✓ Datos recolectados de %d impresoras\n\n", len(printerDataList))
+		// Migrar estados antiguos indexados por IP al ID canónico de cada perfil
+		// (los perfiles guardados por IP se migran en su primer poll, ver profile.Manager.Lookup)
+		ipIndex := map[string]string{}
+		if pm := dataCollector.Profiles(); pm != nil {
+			ipIndex = pm.IPIndex()
+			if migrated := stateManager.MigrateIndex(ipIndex); migrated > 0 {
+				log.Print(i18n.T("log.states_migrated", migrated))
+			}
+		}
 
-		// ========== FLUJO NUEVO: TELEMETRY → SINK ==========
+		// Agendas por site: sus equipos se consultan cuando vence la agenda y
+		// nunca en blackout; los demás siguen el intervalo del ciclo
+		var notDue []string
+		var exempt map[string]bool
+		sites := newSiteScheduler(cfg, stateManager)
+		if sites != nil {
+			deviceInfos, notDue, exempt = sites.filter(deviceInfos, time.Now(), cfg.Polling.Force, cfg.Polling.SitesOnly)
+			if len(notDue) > 0 {
+				fmt.Println(i18n.T("log.site_schedule_skipped", len(notDue)))
+			}
+		}
 
-		// Crear AgentSource (quién envía)
-		agentSource := telemetry.AgentSource{
-			AgentID:  getAgentID(),         // Del entorno o generado
-			Hostname: getHostname(),        // Detectado
-			OS:       getOperatingSystem(), // Detectado
-			Version:  "1.0.0",              // Versión del agente
+		// Polling adaptativo: solo se consultan los equipos a los que ya les toca
+		pollPolicy := newPollPolicy(cfg)
+		if cfg.Polling.Adaptive && !cfg.Polling.Force {
+			var notYet []string
+			deviceInfos, notYet = dueDevices(stateManager, deviceInfos, ipIndex, exempt, agentClock.Now())
+			if len(notYet) > 0 {
+				fmt.Println(i18n.T("log.poll_not_due", len(notYet)))
+			}
+			notDue = append(notDue, notYet...)
 		}
 
-		// Crear builder, serializer y state manager
-		builder := telemetry.NewBuilder(agentSource)
-		ser := serializer.NewSerializer()
-		stateManager := collector.NewStateManager("state") // Directorio para persistir estado
+		// Equipos multi-homed (Ethernet + Wi-Fi): una sola impresora lógica; la
+		// IP secundaria no se consulta si la principal está en el ciclo
+		duplicates := stateManager.LoadDuplicateIndex()
+		deviceInfos = skipSecondaryAddresses(duplicates, deviceInfos)
 
 		// Crear file sink para buffer local (siempre disponible)
-		fileSink, err := sink.NewFileSink(cfg.Sinks.File.Path)
+		fileSink, err := newFileSink(cfg)
 		if err != nil {
-			log.Fatalf("Failed to initialize file sink: %v", err)
+			return errors.New(i18n.T("log.file_sink_error", err))
 		}
 		defer fileSink.Close()
+		syslogSink := newSyslogSink(cfg)
+		if syslogSink != nil {
+			defer syslogSink.Close()
+		}
+
+		// Apartar eventos truncados por un crash anterior antes de sumar nuevos
+		if scrub, err := fileSink.Scrub(); err != nil {
+			log.Print(i18n.T("log.queue_error", err))
+		} else if len(scrub.Quarantined) > 0 {
+			log.Print(i18n.T("log.queue_quarantined", len(scrub.Quarantined), cfg.Sinks.File.Path))
+		}
 
 		// Estadísticas
+		collectedCount := 0
 		bufferedCount := 0
+		partialCount := 0
+		mergedCount := 0
+		var errCounts telemetry.ErrorCounts
+		var inventory []collector.InventoryEntry
+		var meterReads []telemetry.MeterRead
+		cycleOut, err := startCycleOutput(cfg, startTime)
+		logOutputError(cfg, err)
+		siteTally := make(telemetry.SiteTally)
+
+		// Una parada a mitad del ciclo corta la recolección (ctx), no la emisión:
+		// lo ya recolectado se escribe igual
+		sinkCtx := context.WithoutCancel(ctx)
+
+		// Procesar CADA impresora como UN evento atómico, en cuanto se recolecta
+		progress := scanner.NewProgressTracker(scanner.PhaseCollection, len(deviceInfos), newProgressReporter(cfg, store))
+		for printerData := range dataCollector.CollectStream(ctx, deviceInfos) {
+			collectedCount++
+			progress.Step(!printerData.Partial)
+			errCounts.Collection += len(printerData.Errors)
+
+			// Misma impresora (serie/MAC) ya recolectada en este ciclo por otra IP:
+			// se fusiona en la primera, sin telemetría ni delta propios
+			if primaryIP := duplicates.Resolve(&printerData); primaryIP != "" {
+				mergedCount++
+				fmt.Println(i18n.T("log.duplicate_merged", printerData.IP, primaryIP, printerData.PrinterID))
+				continue
+			}
+			inventory = append(inventory, collector.NewInventoryEntry(&printerData, printerData.Timestamp))
+			logOutputError(cfg, cycleOut.AddRaw(&printerData))
+			if printerData.Partial {
+				partialCount++
+			}
 
-		// Procesar CADA impresora como UN evento atómico
-		for _, printerData := range printerDataList {
 			// 0. Cargar estado anterior y calcular delta
 			var delta *collector.CountersDiff
 			var resetDetected bool
 
-			if len(printerData.NormalizedCounters) > 0 || len(printerData.Counters) > 0 {
-				// Construir CountersInfo con valores actuales
-				countersToUse := printerData.NormalizedCounters
-				if len(countersToUse) == 0 {
-					countersToUse = printerData.Counters
-				}
+			// El estado se guarda por ID canónico: sobrevive a cambios de IP (DHCP)
+			stateKey := printerData.PrinterID
+			if stateKey == "" {
+				stateKey = printerData.IP
+			}
 
-				currentCounters := collector.CountersInfo{
-					TotalPages: extractCounterInt64(countersToUse, "total_pages"),
-					MonoPages:  extractCounterInt64(countersToUse, "mono_pages"),
-					ColorPages: extractCounterInt64(countersToUse, "color_pages"),
-					ScanPages:  extractCounterInt64(countersToUse, "scan_pages"),
-					CopyPages:  extractCounterInt64(countersToUse, "copy_pages"),
-					FaxPages:   extractCounterInt64(countersToUse, "fax_pages"),
+			if printerData.HasCounters() {
+				// Contadores ya tipados por el collector
+				currentCounters := printerData.PageCounters
+
+				if err := stateManager.MigrateKey(printerData.IP, stateKey); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_migrate_error", printerData.IP, err))
+				}
+				// Estado antiguo guardado por la IP anterior: el delta sigue desde esa lectura
+				if err := stateManager.MigrateKey(printerData.PreviousIP, stateKey); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_migrate_error", printerData.PreviousIP, err))
 				}
 
 				// Calcular delta
-				delta, resetDetected = stateManager.CalculateDelta(printerData.IP, currentCounters)
+				delta, resetDetected = stateManager.CalculateDelta(stateKey, currentCounters)
 
 				// Guardar estado actual para el próximo poll
-				if err := stateManager.SaveState(printerData.IP, currentCounters); err != nil {
-					log.Printf("⚠️  Failed to save state for %s: %v", printerData.IP, err)
+				// Un salto anómalo no pisa la última lectura sana (evita un falso reset)
+				if printerData.HasJumpAnomaly() {
+					delta = nil
+				} else if err := stateManager.SaveState(stateKey, currentCounters); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+				} else if cfg.State.Retention.HistoryDays > 0 {
+					point := collector.HistoryPoint{At: printerData.Timestamp, Counters: currentCounters, Delta: delta, Reset: resetDetected}
+					if err := stateManager.AppendHistory(stateKey, point); err != nil {
+						errCounts.State++
+						log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+					}
+				}
+			}
+
+			// Próximo poll del equipo (con adaptive, según las páginas desde el anterior)
+			if next, err := stateManager.PlanPoll(stateKey, pollPolicy, delta, printerData.Timestamp); err != nil {
+				errCounts.State++
+				log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+			} else {
+				printerData.NextPollAt = next
+			}
+
+			// Fecha estimada de reemplazo de cada consumible según su consumo
+			if len(printerData.SupplyList) > 0 {
+				if err := stateManager.ForecastSupplies(stateKey, printerData.SupplyList, printerData.PageCounters.TotalPages, time.Now()); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+				}
+			}
+
+			// Volumen de impresión medio (gasto mensual estimado con collector.cost_catalog)
+			if printerData.HasCounters() && !printerData.HasJumpAnomaly() {
+				if usage, err := stateManager.TrackUsage(stateKey, printerData.PageCounters, printerData.Timestamp); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+				} else {
+					printerData.Usage = usage
+				}
+			}
+
+			// Lectura para el cierre de facturación (solo se exporta en el día de cierre)
+			if cfg.Meters.Enabled {
+				if read := builder.BuildMeterRead(&printerData); read != nil {
+					meterReads = append(meterReads, *read)
 				}
 			}
 
 			// 1. Construir Telemetry
 			telem, err := builder.Build(&printerData, delta, resetDetected)
 			if err != nil {
-				log.Printf("❌ Failed to build telemetry for %s: %v", printerData.IP, err)
+				errCounts.Build++
+				log.Print(i18n.T("log.build_error", printerData.IP, err))
+				saveProblem(stateManager, stateKey, collector.ProblemStageBuild, err, &printerData, delta, resetDetected)
 				continue
 			}
+			// Orden e idempotencia: secuencia por impresora y clave derivada del contenido
+			// La secuencia se reserva recién cuando la telemetría quedó en la queue
+			if telem.Sequence, err = stateManager.PeekSequence(stateKey); err != nil {
+				errCounts.State++
+				log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+			}
+			telem.IdempotencyKey = telemetry.IdempotencyKey(telem)
 
 			// 2. Serializar a JSON
 			jsonBytes, err := ser.Serialize(telem)
 			if err != nil {
-				log.Printf("❌ Failed to serialize telemetry for %s: %v", printerData.IP, err)
+				errCounts.Serialize++
+				log.Print(i18n.T("log.serialize_error", printerData.IP, err))
+				saveProblem(stateManager, stateKey, collector.ProblemStageSerialize, err, &printerData, delta, resetDetected)
 				continue
 			}
+			if telemetrySchema != nil {
+				if errs, err := telemetrySchema.ValidateJSON(jsonBytes); err != nil || len(errs) > 0 {
+					errCounts.Serialize++
+					log.Print(i18n.T("log.schema_invalid", printerData.IP, len(errs), firstSchemaError(errs, err)))
+					saveProblem(stateManager, stateKey, collector.ProblemStageSchema, firstSchemaError(errs, err), &printerData, delta, resetDetected)
+					continue
+				}
+			}
 
-			// 3. Enviar a sink (por ahora solo file sink, HTTP vendría aquí)
-			// TODO: Integrar HTTPSink con reintentos
-			err = fileSink.Write(ctx, jsonBytes, printerData.IP)
+			// 3. Enviar a sink según su modo: snapshot completo, solo si cambió o solo lo que cambió
+			queued, err := planEmission(cfg, stateManager, ser, emitQueue, cfg.Sinks.File.Emit, stateKey, telem, jsonBytes)
 			if err != nil {
-				log.Printf("❌ Failed to buffer telemetry for %s: %v", printerData.IP, err)
+				errCounts.Serialize++
+				log.Print(i18n.T("log.serialize_error", printerData.IP, err))
 				continue
 			}
+			err = fileSink.Write(sinkCtx, queued.data, telem.Printer.ID)
+			if err != nil {
+				errCounts.Sink++
+				log.Print(i18n.T("log.buffer_error", printerData.IP, err))
+				continue
+			}
+			if telem.Sequence > 0 {
+				if _, err := stateManager.NextSequence(stateKey); err != nil {
+					errCounts.State++
+					log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+				}
+			}
+			if err := queued.commit(); err != nil {
+				errCounts.State++
+				log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+			}
+
+			bufferedCount++
+			if syslogSink != nil {
+				if forwarded, err := planEmission(cfg, stateManager, ser, emitSyslog, cfg.Sinks.Syslog.Emit, stateKey, telem, jsonBytes); err != nil {
+					log.Print(i18n.T("log.serialize_error", printerData.IP, err))
+				} else {
+					forwardSyslog(sinkCtx, syslogSink, forwarded.data, telem.Printer.ID)
+					if err := forwarded.commit(); err != nil {
+						log.Print(i18n.T("log.state_save_error", printerData.IP, err))
+					}
+				}
+			}
+			siteTally.Add(telem, printerData.Partial)
+			logOutputError(cfg, cycleOut.Add(telem))
+			if store != nil {
+				store.Update(telem)
+				store.SetCoverage(telem.Printer.ID, printerData.Coverage)
+			}
+			if cfg.Collector.CoverageDir != "" {
+				if err := writeCoverage(cfg.Collector.CoverageDir, telem.Printer.ID, printerData.Coverage); err != nil {
+					log.Print(i18n.T("log.coverage_error", printerData.IP, err))
+				}
+			}
+		}
+		progress.Finish()
+
+		fmt.Printf("%s\n\n", i18n.T("log.collected", collectedCount))
+		aborted := ctx.Err() != nil
+		if aborted {
+			log.Print(i18n.T("log.scan_aborted", collectedCount, len(deviceInfos)))
+		}
+
+		slowDevices := dataCollector.SlowDevices()
+		if err := stateManager.SaveSlowDevices(slowDevices); err != nil {
+			errCounts.State++
+			log.Print(i18n.T("log.state_save_error", "slow_devices", err))
+		}
+		if err := stateManager.SaveDuplicateIndex(duplicates); err != nil {
+			errCounts.State++
+			log.Print(i18n.T("log.state_save_error", "duplicates", err))
+		}
+		if sites != nil && !aborted {
+			if err := sites.markRun(); err != nil {
+				errCounts.State++
+				log.Print(i18n.T("log.state_save_error", "schedule_runs", err))
+			}
+		}
+
+		// Equipos sin SNMP: sin telemetría, pero en el inventario como huecos de cobertura
+		noSNMPEntries := newNoSNMPInventory(noSNMP)
+		inventory = append(inventory, noSNMPEntries...)
 
+		// Diferencias de inventario respecto del escaneo anterior (asset tracking)
+		// Los equipos no vencidos no se consultaron: no cuentan como desaparecidos
+		// Los IDs fusionados en otra impresora dejan de existir por separado
+		previousInventory := stateManager.LoadInventory()
+		for _, id := range duplicates.Merged() {
+			delete(previousInventory, id)
+		}
+		diff, nextInventory := collector.DiffInventory(previousInventory, inventory, append(slowDevices, notDue...))
+
+		// Sin responder por más de decommission_days: baja, evento y archivo en state/archive/
+		if cfg.Collector.DecommissionDays > 0 && !aborted {
+			now := time.Now()
+			diff.Decommissioned = collector.DecommissionInventory(nextInventory, now.AddDate(0, 0, -cfg.Collector.DecommissionDays), now)
+			errCounts.State += archivePrinters(stateManager, dataCollector.Profiles(), store, diff.Decommissioned)
+		}
+		if err := stateManager.SaveInventory(nextInventory); err != nil {
+			errCounts.State++
+			log.Print(i18n.T("log.state_save_error", "inventory", err))
+		}
+		if !diff.Empty() {
+			fmt.Println(i18n.T("log.inventory_diff", len(diff.New), len(diff.Missing), len(diff.Returned), len(diff.Changed), len(diff.IPChanged)))
+		}
+		for _, event := range builder.BuildInventoryEvents(diff, time.Now()) {
+			jsonBytes, err := ser.SerializeInventoryEvent(event)
+			if err != nil {
+				errCounts.Serialize++
+				log.Print(i18n.T("log.serialize_error", event.Change.Change, err))
+				continue
+			}
+			if err := fileSink.Write(sinkCtx, jsonBytes, telemetry.InventoryEventKey(event)); err != nil {
+				errCounts.Sink++
+				log.Print(i18n.T("log.buffer_error", event.Change.Change, err))
+				continue
+			}
 			bufferedCount++
+			forwardSyslog(sinkCtx, syslogSink, jsonBytes, telemetry.InventoryEventKey(event))
+		}
+
+		// Cierre mensual de contadores para facturación (un ciclo interrumpido no
+		// tiene las lecturas de toda la flota: el cierre queda para el siguiente)
+		if !aborted {
+			if err := closeMeters(cfg, stateManager, builder, meterReads); err != nil {
+				errCounts.State++
+				log.Print(i18n.T("log.meters_error", err))
+			}
+		}
+
+		// Eventos descartados por la cuota de la queue (nube caída por mucho tiempo)
+		dropped, droppedBytes := fileSink.Evicted()
+		if dropped > 0 {
+			log.Print(i18n.T("log.queue_evicted", dropped, droppedBytes))
 		}
 
 		endTime := time.Now()
-		log.Printf("✅ Scan completed in %.2f seconds. Devices: %d, Telemetry queued: %d", endTime.Sub(startTime).Seconds(), len(printerDataList), bufferedCount)
+		log.Print(i18n.T("log.scan_completed", endTime.Sub(startTime).Seconds(), collectedCount, bufferedCount))
+
+		scanStats := telemetry.ScanStats{
+			ScanID:           cfg.ScanID,
+			StartedAt:        startTime.UTC(),
+			DurationMs:       endTime.Sub(startTime).Milliseconds(),
+			DevicesFound:     len(discoveries),
+			DevicesNoSNMP:    len(noSNMP),
+			DevicesCollected: collectedCount,
+			DevicesMerged:    mergedCount,
+			EventsBuffered:   bufferedCount,
+			PartialDevices:   partialCount,
+			SlowDevices:      len(slowDevices),
+			EventsDropped:    dropped,
+			Aborted:          aborted,
+		}
+
+		summary := builder.BuildScanSummary(scanStats, diff)
+		summary.Sites = siteTally.Stats()
+		summary.NoSNMP = noSNMPEntries
+		if cfg.Collector.SummaryPath != "" {
+			if err := writeScanSummary(cfg.Collector.SummaryPath, summary, ser); err != nil {
+				log.Print(i18n.T("log.summary_error", cfg.Collector.SummaryPath, err))
+			}
+		}
+		logOutputError(cfg, cycleOut.Finish(summary, ser))
+		if store != nil {
+			store.SetSummary(summary)
+		}
+
+		emitHeartbeat(sinkCtx, cfg, scanStats, errCounts)
+
+		// Con HTTP u object storage habilitado la queue se sube al final de cada ciclo
+		if cfg.UploadEnabled() && !aborted {
+			if queue, err := newQueue(cfg); err != nil {
+				log.Print(i18n.T("log.queue_error", err))
+			} else {
+				drainQueue(ctx, cfg, queue)
+			}
+		}
 	} else {
-		fmt.Println("❌ Collector deshabilitado en config.yaml")
-		os.Exit(0)
+		fmt.Println(i18n.T("log.collector_disabled"))
+	}
+	return nil
+}
+
+// newEngine crea el motor SNMP compartido (scanner, collector y profiler)
+func newEngine(cfg Config) *snmp.Engine {
+	return snmp.NewEngine(snmp.EngineConfig{
+		MaxWorkers:       cfg.Discovery.MaxConcurrent,
+		PacketsPerSecond: cfg.SNMP.PacketsPerSecond,
+		BytesPerSecond:   cfg.SNMP.BytesPerSecond,
+		Shapes:           cfg.TrafficShapes(),
+		BackoffMax:       time.Duration(cfg.SNMP.BackoffMaxMs) * time.Millisecond,
+		AllowOIDs:        cfg.SNMP.AllowOIDs,
+		DenyOIDs:         cfg.SNMP.DenyOIDs,
+		Audit:            newAuditLog(cfg),
+		Adaptive:         newAdaptiveConfig(cfg),
+		Shares: snmp.PriorityShares{
+			Counters:  cfg.SNMP.PriorityShares.Counters,
+			Discovery: cfg.SNMP.PriorityShares.Discovery,
+		},
+	})
+}
+
+// newAdaptiveConfig traduce snmp.adaptive_concurrency (nil = concurrencia fija)
+func newAdaptiveConfig(cfg Config) *snmp.AdaptiveConfig {
+	a := cfg.SNMP.AdaptiveConcurrency
+	if !a.Enabled {
+		return nil
+	}
+	return &snmp.AdaptiveConfig{
+		MinWorkers:     a.MinWorkers,
+		MaxWorkers:     a.MaxWorkers,
+		MaxTimeoutRate: a.MaxTimeoutRate,
+		MaxRTTFactor:   a.MaxRTTFactor,
+	}
+}
+
+// newDiscoveryConfig traduce config.yaml al config del scanner
+func newDiscoveryConfig(cfg Config, engine *snmp.Engine) scanner.DiscoveryConfig {
+	return scanner.DiscoveryConfig{
+		MaxConcurrentConnections: cfg.Discovery.MaxConcurrent,
+		TimeoutPerDevice:         time.Duration(cfg.SNMP.TimeoutMs) * time.Millisecond,
+		Retries:                  cfg.SNMP.Retries,
+		Community:                cfg.SNMP.Community,
+		SNMPVersion:              cfg.SNMP.Version,
+		SNMPv3:                   cfg.SNMPv3(),
+		SNMPPort:                 cfg.SNMP.Port,
+		Engine:                   engine,
+		ReverseDNS:               cfg.Discovery.ReverseDNS,
+		DNSTimeout:               time.Duration(cfg.Discovery.DNSTimeoutMs) * time.Millisecond,
+		V1Fallback:               cfg.Discovery.V1Fallback,
+		Fingerprint: scanner.FingerprintConfig{
+			Enabled: cfg.Discovery.Fingerprint.Enabled,
+			Ports:   cfg.Discovery.Fingerprint.Ports,
+			PJL:     cfg.Discovery.Fingerprint.PJL,
+			Timeout: time.Duration(cfg.Discovery.Fingerprint.TimeoutMs) * time.Millisecond,
+		},
+		FastProbe: scanner.FastProbeConfig{
+			Enabled:       cfg.Discovery.FastProbe.Enabled,
+			Timeout:       time.Duration(cfg.Discovery.FastProbe.TimeoutMs) * time.Millisecond,
+			TimeoutFactor: cfg.Discovery.FastProbe.TimeoutFactor,
+		},
+	}
+}
+
+// skipSecondaryAddresses quita las IPs secundarias de equipos multi-homed
+// cuya IP principal también se consulta en este ciclo
+func skipSecondaryAddresses(duplicates *collector.DuplicateIndex, devices []collector.DeviceInfo) []collector.DeviceInfo {
+	ips := make(map[string]bool, len(devices))
+	for _, dev := range devices {
+		ips[dev.IP] = true
+	}
+	kept := devices[:0:0]
+	for _, dev := range devices {
+		if duplicates.Secondary(dev.IP, ips) {
+			continue
+		}
+		kept = append(kept, dev)
+	}
+	if skipped := len(devices) - len(kept); skipped > 0 {
+		fmt.Println(i18n.T("log.duplicate_skipped", skipped))
+	}
+	return kept
+}
+
+// archivePrinters mueve perfil, estado e historial de las impresoras dadas de
+// baja a state/archive/ y las quita del dashboard. Retorna los errores: la
+// baja queda en el inventario igual
+func archivePrinters(sm *collector.StateManager, profiles *profile.Manager, store *web.Store, changes []collector.InventoryChange) int {
+	failed := 0
+	for _, change := range changes {
+		entry := *change.Current
+		dir, err := sm.ArchivePrinter(entry)
+		if err == nil && profiles != nil {
+			_, err = profiles.Archive(entry.PrinterID, dir)
+		}
+		if err != nil {
+			failed++
+			log.Print(i18n.T("log.state_save_error", entry.IP, err))
+		}
+		if store != nil {
+			store.Remove(entry.PrinterID)
+		}
+		log.Print(i18n.T("log.printer_decommissioned", entry.IP, entry.PrinterID, entry.LastSeen.Format("2006-01-02"), dir))
+	}
+	return failed
+}
+
+// newNoSNMPInventory arma las entradas de inventario de los hosts sin SNMP
+// La marca se deduce del modelo leído en los banners ("" si no hay)
+func newNoSNMPInventory(noSNMP []scanner.DiscoveryResult) []collector.InventoryEntry {
+	entries := make([]collector.InventoryEntry, 0, len(noSNMP))
+	for _, disc := range noSNMP {
+		brand := ""
+		if disc.ModelGuess != "" {
+			if brand = detector.DetectBrand(disc.ModelGuess); brand == "Generic" {
+				brand = ""
+			}
+		}
+		entries = append(entries, collector.NewNoSNMPInventoryEntry(disc.IP, brand, disc.ModelGuess, disc.Banner, disc.OpenPorts, disc.DiscoveredAt))
+	}
+	return entries
+}
+
+// newDeviceInfos detecta la marca de cada dispositivo descubierto
+func newDeviceInfos(cfg Config, discoveries []scanner.DiscoveryResult) []collector.DeviceInfo {
+	deviceInfos := make([]collector.DeviceInfo, 0, len(discoveries))
+
+	for _, disc := range discoveries {
+		brand := detector.DetectBrand(disc.SysDescr)
+		confidence := detector.GetBrandConfidence(disc.SysDescr, brand)
+
+		deviceInfo := collector.DeviceInfo{
+			IP:              disc.IP,
+			Brand:           brand,
+			BrandConfidence: confidence,
+			SysDescr:        disc.SysDescr,
+			Community:       cfg.SNMP.Community,
+			SNMPVersion:     disc.SNMPVersion, // negociada en el discovery
+			SNMPv3:          cfg.SNMPv3(),
+			Port:            disc.Port,
+			DNSName:         disc.DNSName,
+		}
+
+		deviceInfos = append(deviceInfos, deviceInfo)
+	}
+	return deviceInfos
+}
+
+// newCollectorConfig traduce config.yaml al config del collector
+func newCollectorConfig(cfg Config, engine *snmp.Engine) collector.Config {
+	return collector.Config{
+		Timeout:                  time.Duration(cfg.SNMP.TimeoutMs) * time.Millisecond,
+		DeviceTimeout:            time.Duration(cfg.Collector.DeviceTimeoutMs) * time.Millisecond,
+		ScanBudget:               time.Duration(cfg.Collector.ScanBudgetMs) * time.Millisecond,
+		Retries:                  cfg.SNMP.Retries,
+		MaxConcurrentConnections: cfg.Discovery.MaxConcurrent,
+		MaxOidsPerDevice:         cfg.Collector.MaxOidsPerRequest,
+		MinDelayBetweenQueries:   time.Duration(cfg.Collector.DelayMs) * time.Millisecond,
+		Community:                cfg.SNMP.Community,
+		SNMPVersion:              cfg.SNMP.Version,
+		SNMPPort:                 cfg.SNMP.Port,
+		Engine:                   engine,
+		ProfileStore:             newProfileStore(cfg),
+		Profiles:                 agentProfiles,
+		SectionConcurrency:       cfg.Collector.SectionConcurrency,
+		BreakerFailures:          cfg.Collector.BreakerFailures,
+		BreakerCycles:            cfg.Collector.BreakerCycles,
+		Clock:                    agentClock,
+		Hooks:                    append(collector.RegisteredHooks(), newExecHooks(cfg)...),
+		HPFallback: collector.HPFallbackConfig{
+			PJL:       cfg.Collector.HPFallback.PJL,
+			PJLPort:   cfg.Collector.HPFallback.PJLPort,
+			EWS:       cfg.Collector.HPFallback.EWS,
+			EWSScheme: cfg.Collector.HPFallback.EWSScheme,
+			Timeout:   time.Duration(cfg.Collector.HPFallback.TimeoutMs) * time.Millisecond,
+		},
+		IPP: collector.IPPConfig{
+			Enabled: cfg.Collector.IPP.Enabled,
+			Always:  cfg.Collector.IPP.Always,
+			Port:    cfg.Collector.IPP.Port,
+			Path:    cfg.Collector.IPP.Path,
+			Timeout: time.Duration(cfg.Collector.IPP.TimeoutMs) * time.Millisecond,
+		},
+		Models:             newModelDatabase(cfg),
+		ModelMinConfidence: cfg.modelMinConfidence(),
+	}
+}
+
+// newModelDatabase carga la base de modelos de collector.models (nil si está
+// deshabilitada). Si el archivo propio no sirve se usa solo la incorporada
+func newModelDatabase(cfg Config) *modeldb.Database {
+	if !cfg.Collector.Models.Enabled {
+		return nil
+	}
+	db, err := modeldb.Load(cfg.Collector.Models.Path)
+	if err != nil {
+		fmt.Println(i18n.T("log.models_error", err))
+		return modeldb.Builtin()
+	}
+	return db
+}
+
+// newExecHooks traduce collector.hooks de config.yaml
+func newExecHooks(cfg Config) []collector.Hook {
+	hooks := make([]collector.Hook, 0, len(cfg.Collector.Hooks))
+	for i, h := range cfg.Collector.Hooks {
+		name := h.Name
+		if name == "" {
+			name = fmt.Sprintf("hook%d", i)
+		}
+		hooks = append(hooks, &collector.ExecHook{
+			HookName: name,
+			Stage:    h.Stage,
+			Command:  h.Command,
+			Timeout:  time.Duration(h.TimeoutMs) * time.Millisecond,
+		})
+	}
+	return hooks
+}
+
+// newPollPolicy traduce polling de config.yaml (el intervalo base sigue al
+// del daemon, o al del tenant, si polling.interval_minutes es 0)
+func newPollPolicy(cfg Config) collector.PollPolicy {
+	interval := cfg.Polling.IntervalMinutes
+	if interval <= 0 {
+		interval = cfg.Daemon.IntervalMinutes
+	}
+	return collector.PollPolicy{
+		Interval:         time.Duration(interval) * time.Minute,
+		Adaptive:         cfg.Polling.Adaptive,
+		MinInterval:      time.Duration(cfg.Polling.MinIntervalMinutes) * time.Minute,
+		MaxInterval:      time.Duration(cfg.Polling.MaxIntervalMinutes) * time.Minute,
+		BusyPagesPerHour: cfg.Polling.BusyPagesPerHour,
 	}
 }
 
+// dueDevices separa los dispositivos a los que ya les toca consultarse de
+// los que no (IPs); el estado se busca por ID canónico o, si no hay perfil, por IP
+// Los exempt (agenda de su site) se consultan siempre
+func dueDevices(sm *collector.StateManager, devices []collector.DeviceInfo, ipIndex map[string]string, exempt map[string]bool, now time.Time) ([]collector.DeviceInfo, []string) {
+	var due []collector.DeviceInfo
+	var notDue []string
+	for _, dev := range devices {
+		if exempt[dev.IP] {
+			due = append(due, dev)
+			continue
+		}
+		key := dev.IP
+		if id, ok := ipIndex[dev.IP]; ok {
+			key = id
+		}
+		if sm.Due(key, now) {
+			due = append(due, dev)
+		} else {
+			notDue = append(notDue, dev.IP)
+		}
+	}
+	return due, notDue
+}
+
+// newTagResolver combina los tags de config.yaml con los asignados por la API
+// Los de la API se guardan en el state/ global: valen para cualquier tenant
+func newTagResolver(cfg Config) *telemetry.TagResolver {
+	assigned := make(map[string]telemetry.Tags)
+	for id, tags := range collector.NewStateManager(cfg.SharedStateDir()).LoadTags() {
+		assigned[id] = tags
+	}
+	return telemetry.NewTagResolver(cfg.TagRules(), assigned)
+}
+
+// newAgentSource describe a este agente (source de telemetrías y heartbeat)
+func newAgentSource() telemetry.AgentSource {
+	return telemetry.AgentSource{
+		AgentID:  getAgentID(),         // Del entorno o generado
+		Hostname: getHostname(),        // Detectado
+		OS:       getOperatingSystem(), // Detectado
+		Version:  "1.0.0",              // Versión del agente
+	}
+}
+
+// writeScanSummary escribe scan_summary.json de forma atómica
+func writeScanSummary(path string, summary *telemetry.ScanSummary, ser *serializer.Serializer) error {
+	data, err := ser.SerializeScanSummary(summary)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
+
+// writeCoverage escribe coverage_<id>.json (se sobrescribe en cada poll)
+func writeCoverage(dir, printerID string, report *collector.CoverageReport) error {
+	if report == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(printerID)
+	return fsutil.WriteFileAtomic(filepath.Join(dir, "coverage_"+name+".json"), data, 0644)
+}
+
+// newFileSink crea el file sink con la cuota de config.yaml
+func newFileSink(cfg Config) (*sink.FileSink, error) {
+	fileSink, err := sink.NewFileSink(cfg.Sinks.File.Path)
+	if err != nil {
+		return nil, err
+	}
+	fileSink.SetQuota(sink.Quota{
+		MaxBytes: int64(cfg.Sinks.File.MaxSizeMB) * 1024 * 1024,
+		MaxFiles: cfg.Sinks.File.MaxFiles,
+	})
+	return fileSink, nil
+}
+
+// newTelemetryBuilder crea el builder de telemetría con sites, tags, reglas de
+// alerta y el diccionario de consumibles de config.yaml
+func newTelemetryBuilder(cfg Config) (*telemetry.Builder, error) {
+	// Reglas de ubicación de config.yaml (site/building/floor de cada impresora)
+	sites, err := telemetry.NewSiteResolver(cfg.SiteRules())
+	if err != nil {
+		return nil, fmt.Errorf("sites: %w", err)
+	}
+
+	builder := telemetry.NewBuilder(agentSource(cfg))
+	builder.SetSites(sites)
+	builder.SetTags(newTagResolver(cfg))
+	builder.SetClock(agentClock)
+	rules, err := telemetry.NewAlertRules(cfg.CustomAlertRules())
+	if err != nil {
+		return nil, fmt.Errorf("alert_rules: %w", err)
+	}
+	builder.SetAlertRules(rules)
+	if path := cfg.Collector.SupplyDictionary; path != "" {
+		dictionary, err := telemetry.LoadSupplyDictionary(path)
+		if err != nil {
+			return nil, fmt.Errorf("collector.supply_dictionary: %w", err)
+		}
+		builder.SetSupplyDictionary(dictionary)
+	}
+	if path := cfg.Collector.CostCatalog; path != "" {
+		costs, err := telemetry.LoadCostCatalog(path)
+		if err != nil {
+			return nil, fmt.Errorf("collector.cost_catalog: %w", err)
+		}
+		builder.SetCostCatalog(costs)
+	}
+	return builder, nil
+}
+
+// newTelemetrySchema retorna el schema de telemetría si sinks.validate_schema
+// está activo (nil = no validar)
+func newTelemetrySchema(cfg Config) *schema.Schema {
+	if !cfg.Sinks.ValidateSchema {
+		return nil
+	}
+	telemetrySchema, _ := schema.For("telemetry")
+	return telemetrySchema
+}
+
+// newSyslogSink crea el syslog sink de sinks.syslog
+// Retorna nil si está deshabilitado o no se puede crear (se loguea)
+func newSyslogSink(cfg Config) *sink.SyslogSink {
+	if !cfg.Sinks.Syslog.Enabled {
+		return nil
+	}
+	s, err := sink.NewSyslogSink(sink.SyslogSinkConfig{
+		Address:   cfg.Sinks.Syslog.Address,
+		Transport: cfg.Sinks.Syslog.Transport,
+		Facility:  cfg.Sinks.Syslog.Facility,
+		AppName:   cfg.Sinks.Syslog.AppName,
+		TLS: sink.TLSConfig{
+			CAFile:         cfg.Sinks.Syslog.CAFile,
+			ClientCertFile: cfg.Sinks.Syslog.ClientCertFile,
+			ClientKeyFile:  cfg.Sinks.Syslog.ClientKeyFile,
+		},
+	})
+	if err != nil {
+		log.Print(i18n.T("log.syslog_error", cfg.Sinks.Syslog.Address, err))
+		return nil
+	}
+	return s
+}
+
+// forwardSyslog envía el resumen de un evento ya encolado al syslog
+// Es una notificación: un error se loguea y no afecta a la queue
+func forwardSyslog(ctx context.Context, s *sink.SyslogSink, data []byte, key string) {
+	if s == nil {
+		return
+	}
+	if err := s.Write(ctx, data, key); err != nil {
+		log.Print(i18n.T("log.syslog_error", key, err))
+	}
+}
+
+// emitHeartbeat encola el heartbeat del agente en el file sink
+// Los errores solo se loguean: el heartbeat nunca interrumpe el ciclo
+func emitHeartbeat(ctx context.Context, cfg Config, scan telemetry.ScanStats, errCounts telemetry.ErrorCounts) {
+	if !cfg.Heartbeat.Enabled {
+		return
+	}
+
+	fileSink, err := newFileSink(cfg)
+	if err != nil {
+		log.Print(i18n.T("log.heartbeat_error", err))
+		return
+	}
+	defer fileSink.Close()
+
+	backlog, err := fileSink.Backlog()
+	if err != nil {
+		backlog = -1
+	}
+
+	source := agentSource(cfg)
+	hb := telemetry.NewBuilder(source).BuildHeartbeat(agentStartedAt, scan, backlog, errCounts)
+	if size, err := fileSink.Size(); err == nil {
+		hb.QueueBytes = size
+	}
+	hb.ConfigVersion = cfg.ConfigVersion
+	hb.ConfigError = cfg.ConfigError
+
+	jsonBytes, err := serializer.NewSerializer().SerializeHeartbeat(hb)
+	if err != nil {
+		log.Print(i18n.T("log.heartbeat_error", err))
+		return
+	}
+
+	if err := fileSink.Write(ctx, jsonBytes, "agent_"+source.AgentID); err != nil {
+		log.Print(i18n.T("log.heartbeat_error", err))
+		return
+	}
+	if syslogSink := newSyslogSink(cfg); syslogSink != nil {
+		forwardSyslog(ctx, syslogSink, jsonBytes, "agent_"+source.AgentID)
+		syslogSink.Close()
+	}
+
+	fmt.Println(i18n.T("log.heartbeat_sent", backlog, errCounts.Total()))
+}
+
 // getAgentID obtiene el ID del agente (env var o default)
 func getAgentID() string {
 	if id := os.Getenv("AGENT_ID"); id != "" {
@@ -238,30 +1112,24 @@ func getOperatingSystem() string {
 	return "unknown"
 }
 
-// extractCounterInt64 extrae un valor contador y lo retorna como int64
-func extractCounterInt64(counters map[string]interface{}, key string) int64 {
-	if counters == nil {
-		return 0
+// prioritizeDevices mueve al principio los dispositivos de la lista slow
+// conservando el orden relativo del resto
+func prioritizeDevices(devices []collector.DeviceInfo, slow []string) []collector.DeviceInfo {
+	pending := make(map[string]bool, len(slow))
+	for _, ip := range slow {
+		pending[ip] = true
 	}
 
-	val, ok := counters[key]
-	if !ok {
-		return 0
+	ordered := make([]collector.DeviceInfo, 0, len(devices))
+	for _, dev := range devices {
+		if pending[dev.IP] {
+			ordered = append(ordered, dev)
+		}
 	}
-
-	switch v := val.(type) {
-	case int:
-		return int64(v)
-	case int64:
-		return v
-	case float64:
-		return int64(v)
-	case string:
-		// Intentar parsear string a int
-		var num int64
-		fmt.Sscanf(v, "%d", &num)
-		return num
-	default:
-		return 0
+	for _, dev := range devices {
+		if !pending[dev.IP] {
+			ordered = append(ordered, dev)
+		}
 	}
+	return ordered
 }
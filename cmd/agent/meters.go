@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/billing"
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/serializer"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// runMeters implementa `printsnmp meters [verify <archivo>]`
+// Sin subcomando escanea el rango y exporta una lectura de contadores por impresora
+func runMeters(args []string) {
+	fs := flag.NewFlagSet("meters", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	ipRange := fs.String("range", "", "Override del rango de IPs")
+	format := fs.String("format", "", "json,csv,papercut,printfleet | both (override de config)")
+	outDir := fs.String("out", "", "Directorio de salida (override de config)")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+	loadMIBs(cfg)
+	if *format != "" {
+		cfg.Meters.Format = *format
+	}
+	if *outDir != "" {
+		cfg.Meters.OutputDir = *outDir
+	}
+
+	if fs.NArg() > 0 {
+		if fs.Arg(0) != "verify" || fs.NArg() < 2 {
+			log.Fatal(i18n.T("log.meters_usage"))
+		}
+		for _, path := range fs.Args()[1:] {
+			sig, err := billing.Verify(path, []byte(cfg.Meters.SigningKey))
+			if err != nil {
+				log.Fatal(i18n.T("log.meters_verify_failed", path, err))
+			}
+			fmt.Println(i18n.T("log.meters_verified", path, sig.Algorithm))
+		}
+		return
+	}
+
+	if *ipRange != "" {
+		cfg.Discovery.IPRange = *ipRange
+	}
+	if cfg.Discovery.IPRange == "" {
+		log.Fatal(i18n.T("log.range_required"))
+	}
+	ips, err := scanner.ParseIPRange(cfg.Discovery.IPRange)
+	if err != nil {
+		log.Fatal(i18n.T("log.range_invalid", err))
+	}
+
+	engine := newEngine(cfg)
+
+	ctx := context.Background()
+	discoveries, err := scanner.NewDiscoveryScanner(newDiscoveryConfig(cfg, engine)).Scan(ctx, ips)
+	if err != nil {
+		log.Fatal(i18n.T("log.discovery_error", err))
+	}
+	if len(discoveries) == 0 {
+		log.Fatal(i18n.T("log.no_devices"))
+	}
+
+	builder := telemetry.NewBuilder(newAgentSource())
+	builder.SetTags(newTagResolver(cfg))
+	if path := cfg.Collector.CostCatalog; path != "" {
+		costs, err := telemetry.LoadCostCatalog(path)
+		if err != nil {
+			log.Fatal(i18n.T("log.meters_error", fmt.Errorf("collector.cost_catalog: %w", err)))
+		}
+		builder.SetCostCatalog(costs)
+	}
+	dataCollector := collector.NewDataCollector(newCollectorConfig(cfg, engine))
+	stateManager := collector.NewStateManager(cfg.StateDir())
+
+	var reads []telemetry.MeterRead
+	for printerData := range dataCollector.CollectStream(ctx, newDeviceInfos(cfg, discoveries)) {
+		// Volumen medido por el agente (solo lectura: meters no toca state/)
+		stateKey := printerData.PrinterID
+		if stateKey == "" {
+			stateKey = printerData.IP
+		}
+		if state, err := stateManager.LoadState(stateKey); err == nil && state != nil {
+			printerData.Usage = state.Usage
+		}
+		if read := builder.BuildMeterRead(&printerData); read != nil {
+			reads = append(reads, *read)
+		}
+	}
+
+	report := builder.BuildMeterReport(billing.Period(time.Now()), reads)
+	if err := exportMeters(cfg, report); err != nil {
+		log.Fatal(i18n.T("log.meters_error", err))
+	}
+}
+
+// exportMeters escribe el reporte de cierre en los formatos configurados,
+// cada archivo con su firma (<archivo>.sig)
+func exportMeters(cfg Config, report *telemetry.MeterReport) error {
+	key := []byte(cfg.Meters.SigningKey)
+	if len(key) == 0 {
+		log.Print(i18n.T("log.meters_unsigned"))
+	}
+
+	formats, err := billing.ParseFormats(cfg.Meters.Format)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, format := range formats {
+		var data []byte
+		switch format {
+		case billing.FormatJSON:
+			data, err = serializer.NewSerializer().SerializeMeterReport(report)
+		case billing.FormatCSV:
+			data, err = billing.CSV(report)
+		case billing.FormatPaperCut:
+			data, err = billing.PaperCutCSV(report)
+		case billing.FormatPrintFleet:
+			data, err = billing.PrintFleetXML(report)
+		}
+		if err != nil {
+			return err
+		}
+
+		path := billing.FileName(cfg.Meters.OutputDir, report.Period, report.GeneratedAt, billing.Extension(format))
+		if _, err := billing.WriteSigned(path, data, key); err != nil {
+			return err
+		}
+		files = append(files, path)
+	}
+
+	for _, path := range files {
+		fmt.Println(i18n.T("log.meters_written", len(report.Reads), report.Period, path))
+	}
+	return nil
+}
+
+// closeMeters exporta el cierre mensual si corresponde y lo registra en state/
+// Sin lecturas no se cierra: se reintenta en el próximo ciclo
+func closeMeters(cfg Config, stateManager *collector.StateManager, builder *telemetry.Builder, reads []telemetry.MeterRead) error {
+	now := time.Now()
+	if !cfg.Meters.Enabled || len(reads) == 0 || !billing.CloseDue(now, cfg.Meters.CloseDay, stateManager.LoadMeterClose()) {
+		return nil
+	}
+
+	report := builder.BuildMeterReport(billing.Period(now), reads)
+	if err := exportMeters(cfg, report); err != nil {
+		return err
+	}
+	return stateManager.SaveMeterClose(report.Period)
+}
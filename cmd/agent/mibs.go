@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/mib"
+)
+
+// loadMIBs agrega los MIBs de mibs.paths al árbol del proceso (sobre los embebidos)
+// Un MIB que no carga no detiene al agente: solo pierde nombres simbólicos
+func loadMIBs(cfg Config) {
+	tree := mib.Default()
+	for _, path := range cfg.MIBs.Paths {
+		if err := loadMIBPath(tree, path); err != nil {
+			log.Print(i18n.T("log.mib_load_error", path, err))
+		}
+	}
+	if unresolved := tree.Unresolved(); len(unresolved) > 0 {
+		log.Print(i18n.T("log.mib_unresolved", len(unresolved), strings.Join(unresolved, ", ")))
+	}
+}
+
+// loadMIBPath carga un archivo o todos los MIBs de un directorio
+func loadMIBPath(tree *mib.Tree, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return tree.LoadDir(path)
+	}
+	return tree.LoadFile(path)
+}
+
+// runMIB implementa `printsnmp mib compile|resolve`
+// compile parsea MIBs en texto y los guarda en JSON (carga rápida con mibs.paths)
+// resolve traduce OIDs a nombres y nombres a OIDs con los MIBs del config
+func runMIB(args []string) {
+	fs := flag.NewFlagSet("mib", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	out := fs.String("out", "mibs.json", "Archivo JSON de salida (compile)")
+	if len(args) < 1 {
+		log.Fatal(i18n.T("log.mib_usage"))
+	}
+	// Los flags van después del subcomando: mib compile -out x.json <dir>
+	fs.Parse(args[1:])
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+
+	if fs.NArg() < 1 {
+		log.Fatal(i18n.T("log.mib_usage"))
+	}
+
+	switch args[0] {
+	case "compile":
+		tree := mib.Default()
+		for _, path := range fs.Args() {
+			if err := loadMIBPath(tree, path); err != nil {
+				log.Print(i18n.T("log.mib_load_error", path, err))
+			}
+		}
+		if unresolved := tree.Unresolved(); len(unresolved) > 0 {
+			log.Print(i18n.T("log.mib_unresolved", len(unresolved), strings.Join(unresolved, ", ")))
+		}
+		if err := tree.SaveJSON(*out); err != nil {
+			log.Fatal(i18n.T("log.mib_load_error", *out, err))
+		}
+		fmt.Println(i18n.T("log.mib_compiled", len(tree.Nodes()), *out))
+
+	case "resolve":
+		loadMIBs(cfg)
+		tree := mib.Default()
+		for _, arg := range fs.Args() {
+			printMIBSymbol(tree, arg)
+		}
+
+	default:
+		log.Fatal(i18n.T("log.mib_usage"))
+	}
+}
+
+// printMIBSymbol imprime "oid = nombre (módulo, sintaxis)" y las enumeraciones
+func printMIBSymbol(tree *mib.Tree, arg string) {
+	oid := strings.TrimPrefix(arg, ".")
+	if !isNumericOID(oid) {
+		resolved, ok := tree.Resolve(arg)
+		if !ok {
+			fmt.Println(i18n.T("log.mib_not_found", arg))
+			return
+		}
+		oid = resolved
+	}
+
+	node, _ := tree.Lookup(oid)
+	if node == nil {
+		fmt.Println(i18n.T("log.mib_not_found", arg))
+		return
+	}
+	fmt.Printf("%s = %s", oid, tree.Name(oid))
+	if node.Module != "" {
+		fmt.Printf("  [%s", node.Module)
+		if node.Syntax != "" {
+			fmt.Printf(", %s", node.Syntax)
+		}
+		fmt.Print("]")
+	}
+	fmt.Println()
+
+	values := make([]int, 0, len(node.Enums))
+	for v := range node.Enums {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	for _, v := range values {
+		fmt.Printf("    %d = %s\n", v, node.Enums[v])
+	}
+}
+
+// isNumericOID reporta si s es un OID numérico ("1.3.6.1.2.1.1.3.0")
+func isNumericOID(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/web"
+)
+
+// runOpenAPI implementa `printsnmp openapi [-out dir]`
+// Sin -out imprime openapi.json; con -out escribe openapi.json y client.ts
+// (incluyen /api/graphql, que el servidor solo documenta si está habilitado)
+func runOpenAPI(args []string) {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	out := fs.String("out", "", "Directorio donde escribir openapi.json y client.ts")
+	fs.Parse(args)
+
+	version := newAgentSource().Version
+	doc, err := json.MarshalIndent(web.OpenAPI(version, true), "", "  ")
+	if err != nil {
+		log.Fatal(i18n.T("log.openapi_error", err))
+	}
+	if *out == "" {
+		fmt.Println(string(doc))
+		return
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(i18n.T("log.openapi_error", err))
+	}
+	files := map[string][]byte{
+		"openapi.json": append(doc, '\n'),
+		"client.ts":    web.TypeScriptClient(version, true),
+	}
+	for _, name := range []string{"openapi.json", "client.ts"} {
+		path := filepath.Join(*out, name)
+		if err := fsutil.WriteFileAtomic(path, files[name], 0644); err != nil {
+			log.Fatal(i18n.T("log.openapi_error", err))
+		}
+		fmt.Println(i18n.T("log.openapi_written", path))
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/output"
+	"github.com/asaavedra/agent-snmp/pkg/serializer"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// Formatos de output.format
+const (
+	outputFormatJSON   = "json"   // un arreglo por archivo, escrito al final del ciclo
+	outputFormatNDJSON = "ndjson" // una línea por impresora, escrita a medida que se recolecta
+)
+
+// cycleOutput escribe los resultados de un ciclo en output.dir
+// printers.* son las telemetrías (lo que consume el frontend) y
+// printers_raw.* los PrinterData del collector (output.raw)
+// Todos sus métodos aceptan un receptor nil (output.dir vacío)
+type cycleOutput struct {
+	cfg Config
+	run *output.Run
+
+	// output.format: json
+	printers []*telemetry.Telemetry
+	raw      []collector.PrinterData
+
+	// output.format: ndjson
+	lines    *output.LineWriter
+	rawLines *output.LineWriter
+}
+
+// startCycleOutput crea el directorio del ciclo (nil si output.dir está vacío)
+func startCycleOutput(cfg Config, startedAt time.Time) (*cycleOutput, error) {
+	if cfg.Output.Dir == "" {
+		return nil, nil
+	}
+	run, err := output.StartRun(cfg.Output.Dir, startedAt)
+	if err != nil {
+		return nil, err
+	}
+	o := &cycleOutput{cfg: cfg, run: run}
+
+	if cfg.Output.Format == outputFormatNDJSON {
+		if o.lines, err = run.CreateLines("printers.ndjson"); err != nil {
+			return nil, err
+		}
+		if cfg.Output.Raw {
+			if o.rawLines, err = run.CreateLines("printers_raw.ndjson"); err != nil {
+				o.lines.Close()
+				return nil, err
+			}
+		}
+	}
+	return o, nil
+}
+
+// AddRaw agrega los datos crudos de una impresora (solo con output.raw)
+func (o *cycleOutput) AddRaw(data *collector.PrinterData) error {
+	if o == nil || !o.cfg.Output.Raw {
+		return nil
+	}
+	if o.rawLines != nil {
+		return o.rawLines.Write(data)
+	}
+	o.raw = append(o.raw, *data)
+	return nil
+}
+
+// Add agrega la telemetría de una impresora
+func (o *cycleOutput) Add(telem *telemetry.Telemetry) error {
+	if o == nil {
+		return nil
+	}
+	if o.lines != nil {
+		return o.lines.Write(telem)
+	}
+	o.printers = append(o.printers, telem)
+	return nil
+}
+
+// Finish cierra los archivos del ciclo, escribe scan_summary.json, mueve latest
+// y aplica la retención de output
+func (o *cycleOutput) Finish(summary *telemetry.ScanSummary, ser *serializer.Serializer) error {
+	if o == nil {
+		return nil
+	}
+	if err := o.writePrinters(ser); err != nil {
+		return err
+	}
+	summaryData, err := ser.SerializeScanSummary(summary)
+	if err != nil {
+		return err
+	}
+	if err := o.run.WriteFile("scan_summary.json", summaryData); err != nil {
+		return err
+	}
+
+	removed, err := o.run.Finish(output.Retention{
+		KeepRuns: o.cfg.Output.KeepRuns,
+		MaxAge:   time.Duration(o.cfg.Output.MaxAgeDays) * 24 * time.Hour,
+	})
+	fmt.Println(i18n.T("log.output_written", o.run.Dir()))
+	if len(removed) > 0 {
+		fmt.Println(i18n.T("log.output_pruned", len(removed)))
+	}
+	return err
+}
+
+// writePrinters publica printers.* y printers_raw.* según el formato
+func (o *cycleOutput) writePrinters(ser *serializer.Serializer) error {
+	if o.lines != nil {
+		err := o.lines.Close()
+		if o.rawLines != nil {
+			if rawErr := o.rawLines.Close(); err == nil {
+				err = rawErr
+			}
+		}
+		return err
+	}
+
+	printers, err := ser.SerializeSnapshot(o.printers)
+	if err != nil {
+		return err
+	}
+	if err := o.run.WriteFile("printers.json", printers); err != nil {
+		return err
+	}
+	if !o.cfg.Output.Raw {
+		return nil
+	}
+	raw, err := ser.SerializeRawSnapshot(o.raw)
+	if err != nil {
+		return err
+	}
+	return o.run.WriteFile("printers_raw.json", raw)
+}
+
+// logOutputError informa un error de output.dir sin detener el ciclo
+func logOutputError(cfg Config, err error) {
+	if err != nil {
+		log.Print(i18n.T("log.summary_error", cfg.Output.Dir, err))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/serializer"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// saveProblem guarda en state/problems/ una lectura que no llegó a la queue
+func saveProblem(sm *collector.StateManager, stateKey, stage string, cause error, data *collector.PrinterData, delta *collector.CountersDiff, resetDetected bool) {
+	if err := sm.SaveProblem(stateKey, stage, cause, data, delta, resetDetected); err != nil {
+		log.Print(i18n.T("log.problem_save_error", data.IP, err))
+		return
+	}
+	log.Print(i18n.T("log.problem_saved", data.IP, stage))
+}
+
+// runProblems implementa `printsnmp problems list | reprocess [-all] <archivo...>`
+// Las lecturas que fallaron al construir, serializar o validar la telemetría
+// quedan en state/problems/; reprocess las vuelve a pasar por el builder
+// (típicamente después de instalar la versión que corrige el fallo) y las
+// escribe en la queue como snapshot completo, sin importar sinks.file.emit:
+// son lecturas viejas y no deben pisar el último estado emitido
+func runProblems(args []string) {
+	fs := flag.NewFlagSet("problems", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+
+	if fs.NArg() < 1 {
+		log.Fatal(i18n.T("log.problems_usage"))
+	}
+
+	stateManager := collector.NewStateManager(cfg.StateDir())
+	problems, err := stateManager.Problems()
+	if err != nil {
+		log.Print(i18n.T("log.problems_error", err))
+	}
+
+	switch fs.Arg(0) {
+	case "list":
+		for _, p := range problems {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", p.Name, p.StateKey, p.FailedAt.Format(time.RFC3339), p.Stage, p.Error)
+		}
+		fmt.Println(i18n.T("log.problems_total", len(problems)))
+
+	case "reprocess":
+		rp := flag.NewFlagSet("reprocess", flag.ExitOnError)
+		all := rp.Bool("all", false, "Reprocesar todas las lecturas de problems/")
+		rp.Parse(fs.Args()[1:])
+
+		selected := problems
+		if !*all {
+			if rp.NArg() == 0 {
+				log.Fatal(i18n.T("log.problems_usage"))
+			}
+			selected = selectProblems(problems, rp.Args())
+		}
+
+		// NextSequence escribe state/: se excluye con un ciclo en curso
+		if err := stateManager.Lock(stateLockTimeout); err != nil {
+			log.Fatal(i18n.T("log.state_locked", err))
+		}
+		defer stateManager.Unlock()
+
+		done, err := reprocessProblems(cfg, stateManager, selected)
+		if err != nil {
+			log.Print(i18n.T("log.problems_error", err))
+		}
+		fmt.Println(i18n.T("log.problems_reprocessed", done, len(selected)-done))
+
+	default:
+		log.Fatal(i18n.T("log.problems_usage"))
+	}
+}
+
+// selectProblems filtra por nombre de archivo; los inexistentes se informan
+func selectProblems(problems []collector.Problem, names []string) []collector.Problem {
+	byName := make(map[string]collector.Problem, len(problems))
+	for _, p := range problems {
+		byName[p.Name] = p
+	}
+	var selected []collector.Problem
+	for _, name := range names {
+		p, ok := byName[name]
+		if !ok {
+			log.Print(i18n.T("log.problems_error", fmt.Errorf("%s: no existe", name)))
+			continue
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+// reprocessProblems arma, serializa y encola cada lectura; las que pasan se
+// borran de problems/ y las que vuelven a fallar quedan con el error original
+// Retorna cuántas llegaron a la queue
+func reprocessProblems(cfg Config, sm *collector.StateManager, problems []collector.Problem) (int, error) {
+	builder, err := newTelemetryBuilder(cfg)
+	if err != nil {
+		return 0, err
+	}
+	ser := serializer.NewSerializer()
+	telemetrySchema := newTelemetrySchema(cfg)
+	fileSink, err := newFileSink(cfg)
+	if err != nil {
+		return 0, errors.New(i18n.T("log.file_sink_error", err))
+	}
+	defer fileSink.Close()
+
+	done := 0
+	for _, p := range problems {
+		data := p.PrinterData()
+		builder.SetScanID(p.ScanID) // el ciclo que la recolectó, no el reproceso
+
+		telem, err := builder.Build(&data, p.Delta, p.ResetDetected)
+		if err != nil {
+			log.Print(i18n.T("log.build_error", data.IP, err))
+			continue
+		}
+		if telem.Sequence, err = sm.PeekSequence(p.StateKey); err != nil {
+			log.Print(i18n.T("log.state_save_error", data.IP, err))
+		}
+		telem.IdempotencyKey = telemetry.IdempotencyKey(telem)
+
+		jsonBytes, err := ser.Serialize(telem)
+		if err != nil {
+			log.Print(i18n.T("log.serialize_error", data.IP, err))
+			continue
+		}
+		if telemetrySchema != nil {
+			if errs, err := telemetrySchema.ValidateJSON(jsonBytes); err != nil || len(errs) > 0 {
+				log.Print(i18n.T("log.schema_invalid", data.IP, len(errs), firstSchemaError(errs, err)))
+				continue
+			}
+		}
+
+		if err := fileSink.Write(context.Background(), jsonBytes, telem.Printer.ID); err != nil {
+			log.Print(i18n.T("log.buffer_error", data.IP, err))
+			continue
+		}
+		if telem.Sequence > 0 {
+			if _, err := sm.NextSequence(p.StateKey); err != nil {
+				log.Print(i18n.T("log.state_save_error", data.IP, err))
+			}
+		}
+		done++
+		if err := sm.RemoveProblem(p.Name); err != nil {
+			log.Print(i18n.T("log.problems_error", err))
+		}
+		fmt.Println(i18n.T("log.problem_reprocessed", p.Name, telem.Printer.ID))
+	}
+	return done, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/profilestore"
+)
+
+// profileDir es donde el collector guarda los perfiles (y profiles/templates/ las plantillas)
+const profileDir = "profiles"
+
+// agentProfiles son los perfiles del daemon, compartidos por los ciclos y las
+// consultas a pedido (nil fuera de serve: cada colector carga los suyos)
+var agentProfiles *profile.Manager
+
+// newProfileStore crea el profile store remoto de profile_store (nil si no hay)
+// Un store mal configurado no detiene el scan: se sigue con las plantillas locales
+func newProfileStore(cfg Config) profile.ProfileStore {
+	store, err := profilestore.New(cfg.ProfileStoreConfig(), getAgentID(), cfg.HTTPSinkConfig())
+	if err != nil {
+		log.Print(i18n.T("log.profile_store_error", err))
+		return nil
+	}
+	return store
+}
+
+// runProfile implementa `printsnmp profile export|import|templates|push|pull`
+// export genera una plantilla de modelo sin IP ni serial a partir del perfil
+// de un equipo; import la instala para que los equipos del mismo modelo se
+// perfilen sin el WALK de discovery. push/pull las intercambian con el
+// profile store remoto (profile_store en config.yaml)
+func runProfile(args []string) {
+	fs := flag.NewFlagSet("profile", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+	if err := resolveSecrets(&cfg); err != nil {
+		log.Fatal(i18n.T("log.secrets_resolve_error", err))
+	}
+
+	if fs.NArg() < 1 {
+		log.Fatal(i18n.T("log.profile_usage"))
+	}
+
+	profiles, err := profile.NewManager(profileDir)
+	if err != nil {
+		log.Fatal(i18n.T("log.template_error", err))
+	}
+
+	ctx := context.Background()
+
+	switch fs.Arg(0) {
+	case "export":
+		ex := flag.NewFlagSet("export", flag.ExitOnError)
+		out := ex.String("out", "", "Archivo de salida (default: <marca>_<modelo>.json)")
+		contributor := ex.String("contributor", "", "Autor de la plantilla (se publica con ella)")
+		ex.Parse(fs.Args()[1:])
+		if ex.NArg() != 1 {
+			log.Fatal(i18n.T("log.profile_usage"))
+		}
+
+		t, err := profiles.ExportTemplate(ex.Arg(0))
+		if err != nil {
+			log.Fatal(i18n.T("log.template_error", err))
+		}
+		t.Contributor = *contributor
+		path := *out
+		if path == "" {
+			path = t.Key() + ".json"
+		}
+		if err := profile.WriteTemplate(path, t); err != nil {
+			log.Fatal(i18n.T("log.template_error", err))
+		}
+		fmt.Println(i18n.T("log.template_exported", t.Brand, t.Model, path))
+
+	case "import":
+		if fs.NArg() < 2 {
+			log.Fatal(i18n.T("log.profile_usage"))
+		}
+		for _, path := range fs.Args()[1:] {
+			t, err := profile.LoadTemplate(path)
+			if err == nil {
+				path, err = profiles.ImportTemplate(t)
+			}
+			if err != nil {
+				log.Fatal(i18n.T("log.template_error", err))
+			}
+			fmt.Println(i18n.T("log.template_imported", t.Brand, t.Model, path))
+		}
+
+	case "templates":
+		templates, err := profiles.Templates()
+		if err != nil {
+			log.Fatal(i18n.T("log.template_error", err))
+		}
+		for _, t := range templates {
+			categories := make([]string, 0, len(t.OIDs))
+			for category, oids := range t.OIDs {
+				categories = append(categories, fmt.Sprintf("%s=%d", category, len(oids)))
+			}
+			sort.Strings(categories)
+			line := fmt.Sprintf("%s\t%s\t%s\t%s", t.Key(), t.Brand, t.Model, strings.Join(categories, ","))
+			if t.Contributor != "" {
+				line += "\t" + t.Contributor
+			}
+			fmt.Println(line)
+		}
+		fmt.Println(i18n.T("log.template_total", len(templates)))
+
+	case "push":
+		store := requireProfileStore(cfg)
+		if fs.NArg() < 2 {
+			log.Fatal(i18n.T("log.profile_usage"))
+		}
+		for _, path := range fs.Args()[1:] {
+			t, err := profile.LoadTemplate(path)
+			if err == nil {
+				err = store.PutTemplate(ctx, t)
+			}
+			if err != nil {
+				log.Fatal(i18n.T("log.template_error", err))
+			}
+			fmt.Println(i18n.T("log.template_pushed", t.Brand, t.Model, t.Key()))
+		}
+
+	case "pull":
+		store := requireProfileStore(cfg)
+		keys := fs.Args()[1:]
+		if len(keys) == 0 {
+			var err error
+			if keys, err = store.ListTemplates(ctx); err != nil {
+				log.Fatal(i18n.T("log.profile_store_error", err))
+			}
+		}
+		for _, key := range keys {
+			t, err := store.GetTemplate(ctx, key)
+			if err != nil {
+				log.Fatal(i18n.T("log.profile_store_error", err))
+			}
+			if t == nil {
+				log.Print(i18n.T("log.template_not_found", key))
+				continue
+			}
+			path, err := profiles.ImportTemplate(t)
+			if err != nil {
+				log.Fatal(i18n.T("log.template_error", err))
+			}
+			fmt.Println(i18n.T("log.template_imported", t.Brand, t.Model, path))
+		}
+
+	default:
+		log.Fatal(i18n.T("log.profile_usage"))
+	}
+}
+
+// requireProfileStore termina con error si profile_store no está configurado
+func requireProfileStore(cfg Config) profile.ProfileStore {
+	store, err := profilestore.New(cfg.ProfileStoreConfig(), getAgentID(), cfg.HTTPSinkConfig())
+	if err != nil {
+		log.Fatal(i18n.T("log.profile_store_error", err))
+	}
+	if store == nil {
+		log.Fatal(i18n.T("log.profile_store_disabled"))
+	}
+	return store
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/web"
+)
+
+// progressPublishInterval limita los eventos scan_progress del dashboard
+const progressPublishInterval = time.Second
+
+// progressBarWidth es el ancho en caracteres de la barra de la CLI
+const progressBarWidth = 30
+
+// newProgressReporter reparte el avance del escaneo entre el log (cada
+// logging.progress_interval_s), el dashboard (store != nil) y la barra de
+// la CLI (logging.progress_bar). Retorna nil si no hay a quién avisar
+func newProgressReporter(cfg Config, store *web.Store) scanner.ProgressFunc {
+	logEvery := time.Duration(cfg.Logging.ProgressIntervalS) * time.Second
+	if logEvery <= 0 && store == nil && !cfg.Logging.ProgressBar {
+		return nil
+	}
+
+	var lastLog, lastPublish time.Time
+	return func(p scanner.Progress) {
+		now := time.Now()
+		if logEvery > 0 && !p.Done && now.Sub(lastLog) >= logEvery {
+			if !lastLog.IsZero() { // el primer aviso sale recién después de un intervalo
+				fmt.Println(i18n.T("log.scan_progress", p.Phase, p.Scanned, p.Total, p.Percent(), p.Found, p.ETA().Round(time.Second)))
+			}
+			lastLog = now
+		}
+		if store != nil && (p.Done || now.Sub(lastPublish) >= progressPublishInterval) {
+			store.SetProgress(p)
+			lastPublish = now
+		}
+		if cfg.Logging.ProgressBar {
+			renderProgressBar(p)
+		}
+	}
+}
+
+// renderProgressBar redibuja la barra en stderr (stdout queda para los logs)
+func renderProgressBar(p scanner.Progress) {
+	filled := int(p.Percent() / 100 * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	line := fmt.Sprintf("\r%-10s %s %3.0f%% %d/%d  %d  ETA %v ", p.Phase, bar, p.Percent(), p.Scanned, p.Total, p.Found, p.ETA().Round(time.Second))
+	if p.Done {
+		line += "\n"
+	}
+	fmt.Fprint(os.Stderr, line)
+}
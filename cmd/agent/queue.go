@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/sink"
+)
+
+// runQueue implementa `printsnmp queue list|deadletter|requeue|flush`
+func runQueue(args []string) {
+	fs := flag.NewFlagSet("queue", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+
+	if fs.NArg() < 1 {
+		log.Fatal(i18n.T("log.queue_usage"))
+	}
+
+	queue, err := newQueue(cfg)
+	if err != nil {
+		log.Fatal(i18n.T("log.queue_error", err))
+	}
+
+	switch fs.Arg(0) {
+	case "list":
+		entries, err := queue.Entries()
+		if err != nil {
+			log.Fatal(i18n.T("log.queue_error", err))
+		}
+		printQueueEntries(entries)
+
+	case "deadletter":
+		entries, err := queue.DeadLetters()
+		if err != nil {
+			log.Fatal(i18n.T("log.queue_error", err))
+		}
+		printQueueEntries(entries)
+
+	case "requeue":
+		rq := flag.NewFlagSet("requeue", flag.ExitOnError)
+		all := rq.Bool("all", false, "Devolver todos los eventos de deadletter/")
+		rq.Parse(fs.Args()[1:])
+
+		if *all {
+			n, err := queue.RequeueAll()
+			if err != nil {
+				log.Fatal(i18n.T("log.queue_error", err))
+			}
+			fmt.Println(i18n.T("log.queue_requeued", n))
+			return
+		}
+		if rq.NArg() == 0 {
+			log.Fatal(i18n.T("log.queue_usage"))
+		}
+		for _, name := range rq.Args() {
+			if err := queue.Requeue(name); err != nil {
+				log.Fatal(i18n.T("log.queue_error", err))
+			}
+		}
+		fmt.Println(i18n.T("log.queue_requeued", rq.NArg()))
+
+	case "flush":
+		if !cfg.UploadEnabled() {
+			log.Fatal(i18n.T("log.queue_http_disabled"))
+		}
+		drainQueue(context.Background(), cfg, queue)
+
+	default:
+		log.Fatal(i18n.T("log.queue_usage"))
+	}
+}
+
+// newQueue abre la queue del file sink con la política de config.yaml
+func newQueue(cfg Config) (*sink.Queue, error) {
+	return sink.NewQueue(cfg.Sinks.File.Path, sink.QueuePolicy{
+		MaxAttempts: cfg.Sinks.File.MaxAttempts,
+		MaxAge:      time.Duration(cfg.Sinks.File.MaxAgeHours) * time.Hour,
+	})
+}
+
+// drainQueue sube la queue a su destino (ver UploadSink) y reporta el resultado
+func drainQueue(ctx context.Context, cfg Config, queue *sink.Queue) {
+	dest, err := cfg.UploadSink()
+	if err != nil {
+		log.Print(i18n.T("log.queue_error", err))
+		return
+	}
+	defer dest.Close()
+
+	stats, err := queue.Drain(ctx, dest)
+	if err != nil {
+		log.Print(i18n.T("log.queue_error", err))
+	}
+	fmt.Println(i18n.T("log.queue_drained", stats.Sent, stats.Failed, stats.DeadLettered))
+}
+
+// printQueueEntries imprime una línea por evento
+func printQueueEntries(entries []sink.QueueEntry) {
+	for _, e := range entries {
+		line := fmt.Sprintf("%s\t%s\t%s\t%d", e.Name, e.PrinterID, e.QueuedAt.Format(time.RFC3339), e.Attempts)
+		if e.LastError != "" {
+			line += "\t" + e.LastError
+		}
+		fmt.Println(line)
+	}
+	fmt.Println(i18n.T("log.queue_total", len(entries)))
+}
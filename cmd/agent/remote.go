@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/remote"
+	"gopkg.in/yaml.v3"
+)
+
+// remoteSync mantiene la configuración asignada por el backend (mode: cloud-sync)
+// El pull corre en segundo plano; la config se aplica al inicio de cada ciclo
+type remoteSync struct {
+	fetcher   *remote.Fetcher
+	url       string
+	cachePath string
+
+	mu       sync.Mutex
+	latest   *remote.Document // último documento recibido (o el de la caché)
+	good     *remote.Document // último documento aplicado sin errores
+	reported string           // última versión logueada (aplicada o rechazada)
+}
+
+// newRemoteSync retorna nil si el agente no está en modo cloud-sync
+// La caché permite arrancar con la última config aplicada aunque el backend no responda
+func newRemoteSync(cfg Config) *remoteSync {
+	if !cfg.RemoteEnabled() {
+		return nil
+	}
+
+	fetcher, err := remote.NewFetcher(getAgentID(), cfg.HTTPSinkConfig())
+	if err != nil {
+		log.Print(i18n.T("log.remote_error", err))
+		return nil
+	}
+
+	rs := &remoteSync{fetcher: fetcher, url: cfg.RemoteConfig.URL, cachePath: cfg.RemoteConfig.CachePath}
+	if rs.cachePath != "" {
+		cached, err := remote.LoadCached(rs.cachePath)
+		if err != nil {
+			log.Print(i18n.T("log.remote_error", err))
+		}
+		rs.latest, rs.good = cached, cached
+	}
+	return rs
+}
+
+// fetch pide la config al backend; si falla se sigue con la última conocida
+func (rs *remoteSync) fetch(ctx context.Context) {
+	rs.mu.Lock()
+	previous := rs.latest
+	rs.mu.Unlock()
+
+	doc, err := rs.fetcher.Fetch(ctx, rs.url, previous)
+	if err != nil {
+		log.Print(i18n.T("log.remote_error", err))
+		if previous != nil {
+			fmt.Println(i18n.T("log.remote_cached", previous.Version))
+		}
+		return
+	}
+	if doc == nil || (previous != nil && doc.Version == previous.Version) {
+		return // sin cambios
+	}
+
+	fmt.Println(i18n.T("log.remote_fetched", doc.Version))
+	rs.mu.Lock()
+	rs.latest = doc
+	rs.mu.Unlock()
+}
+
+// run repite el pull cada interval hasta que ctx se cancele
+func (rs *remoteSync) run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.fetch(ctx)
+		}
+	}
+}
+
+// apply superpone la config remota sobre la local (cfg, ya con secretos resueltos)
+// Un documento inválido se rechaza y se mantiene el último aplicado; el
+// rechazo queda en cfg.ConfigError para reportarlo en el heartbeat
+func (rs *remoteSync) apply(cfg Config) Config {
+	if rs == nil {
+		return cfg
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.latest == nil {
+		return cfg
+	}
+
+	next, err := overlayRemote(cfg, rs.latest)
+	if err == nil {
+		if rs.reported != rs.latest.Version {
+			rs.reported = rs.latest.Version
+			fmt.Println(i18n.T("log.remote_applied", rs.latest.Version))
+		}
+		if rs.good != rs.latest && rs.cachePath != "" {
+			if err := remote.SaveCached(rs.cachePath, rs.latest); err != nil {
+				log.Print(i18n.T("log.remote_error", err))
+			}
+		}
+		rs.good = rs.latest
+		return next
+	}
+
+	if rs.reported != rs.latest.Version {
+		rs.reported = rs.latest.Version
+		log.Print(i18n.T("log.remote_rejected", rs.latest.Version, err))
+	}
+	rejected := fmt.Sprintf("%s: %v", rs.latest.Version, err)
+
+	if rs.good != nil && rs.good != rs.latest {
+		if prev, err := overlayRemote(cfg, rs.good); err == nil {
+			cfg = prev
+		}
+	}
+	cfg.ConfigError = rejected
+	return cfg
+}
+
+// overlayRemote aplica doc sobre una copia de cfg, valida y resuelve secretos
+// El backend no puede cambiar el modo, la propia sección remote_config ni el vault
+func overlayRemote(cfg Config, doc *remote.Document) (Config, error) {
+	// Copia profunda vía YAML: los slices del original no se comparten
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return cfg, err
+	}
+	var next Config
+	if err := yaml.Unmarshal(data, &next); err != nil {
+		return cfg, err
+	}
+
+	// JSON es YAML válido: las claves son las mismas de config.yaml
+	if len(doc.Config) > 0 {
+		if err := yaml.Unmarshal(doc.Config, &next); err != nil {
+			return cfg, fmt.Errorf("config inválida: %w", err)
+		}
+	}
+
+	next.Mode = cfg.Mode
+	next.RemoteConfig = cfg.RemoteConfig
+	next.Secrets = cfg.Secrets
+	pinLocalOnly(&next, cfg)
+
+	if err := next.Validate(); err != nil {
+		return cfg, err
+	}
+	if next.Discovery.IPRange == "" {
+		return cfg, fmt.Errorf("discovery.ip_range vacío")
+	}
+	if err := resolveSecrets(&next); err != nil {
+		return cfg, err
+	}
+
+	next.ConfigVersion = doc.Version
+	return next, nil
+}
+
+// pinLocalOnly copia a next las secciones que solo manda el config.yaml local:
+// los programas externos corren en el agente, y aceptarlos del backend o de
+// PUT /api/config sería ejecutar cualquier comando que manden
+func pinLocalOnly(next *Config, local Config) {
+	next.Collector.Hooks = local.Collector.Hooks
+	next.Sinks.Exec = local.Sinks.Exec
+}
+
+// localOnlyChange retorna la primera sección local-only que difiere entre
+// local y next ("" = ninguna)
+func localOnlyChange(local, next Config) string {
+	if !sameList(local.Collector.Hooks, next.Collector.Hooks) {
+		return "collector.hooks"
+	}
+	if a, b := local.Sinks.Exec, next.Sinks.Exec; a.Enabled != b.Enabled || !sameList(a.Command, b.Command) || !sameList(a.Env, b.Env) || a.TimeoutSeconds != b.TimeoutSeconds {
+		return "sinks.exec"
+	}
+	return ""
+}
+
+// sameList compara dos listas de config; vacía y ausente son iguales
+func sameList(a, b interface{}) bool {
+	return reflect.ValueOf(a).Len() == 0 && reflect.ValueOf(b).Len() == 0 || reflect.DeepEqual(a, b)
+}
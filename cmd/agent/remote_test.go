@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/remote"
+	"gopkg.in/yaml.v3"
+)
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Discovery.IPRange = "192.168.1.1-254"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("config de prueba inválida: %v", err)
+	}
+	return cfg
+}
+
+func TestOverlayRemoteKeepsLocalHooks(t *testing.T) {
+	cfg := testConfig(t)
+	doc := &remote.Document{
+		Version: "7",
+		Config: []byte(`{"collector": {"hooks": [{"name": "x", "stage": "post", "command": ["sh", "-c", "id"]}]},
+			"sinks": {"exec": {"enabled": true, "command": ["sh", "-c", "id"]}}}`),
+	}
+
+	next, err := overlayRemote(cfg, doc)
+	if err != nil {
+		t.Fatalf("overlayRemote: %v", err)
+	}
+	if len(next.Collector.Hooks) != 0 {
+		t.Errorf("hooks del backend aplicados: %+v", next.Collector.Hooks)
+	}
+	if next.Sinks.Exec.Enabled || len(next.Sinks.Exec.Command) != 0 {
+		t.Errorf("exec sink del backend aplicado: %+v", next.Sinks.Exec)
+	}
+	if next.ConfigVersion != "7" {
+		t.Errorf("ConfigVersion = %q, want 7", next.ConfigVersion)
+	}
+}
+
+func TestUpdateConfigRejectsExecSinkChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	local, err := yaml.Marshal(testConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, local, 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := &daemon{configFile: path}
+
+	changed := testConfig(t)
+	changed.Sinks.Exec.Enabled = true
+	changed.Sinks.Exec.Command = []string{"sh", "-c", "id"}
+	data, err := yaml.Marshal(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpdateConfig(data); err == nil || !strings.Contains(err.Error(), "sinks.exec") {
+		t.Fatalf("UpdateConfig con sinks.exec = %v, want error de sinks.exec", err)
+	}
+}
+
+func TestUpdateConfigRejectsHookChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	local, err := yaml.Marshal(testConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, local, 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := &daemon{configFile: path}
+
+	// Sin cambios en las secciones local-only se guarda
+	if err := d.UpdateConfig(local); err != nil {
+		t.Fatalf("UpdateConfig sin cambios: %v", err)
+	}
+
+	changed := testConfig(t)
+	changed.Collector.Hooks = append(changed.Collector.Hooks, struct {
+		Name      string   `yaml:"name"`
+		Stage     string   `yaml:"stage"`
+		Command   []string `yaml:"command"`
+		TimeoutMs int      `yaml:"timeout_ms"`
+	}{Name: "x", Stage: "post", Command: []string{"sh", "-c", "id"}})
+	data, err := yaml.Marshal(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.UpdateConfig(data)
+	if err == nil || !strings.Contains(err.Error(), "collector.hooks") {
+		t.Fatalf("UpdateConfig con hooks = %v, want error de collector.hooks", err)
+	}
+	if saved, _ := os.ReadFile(path); string(saved) != string(local) {
+		t.Error("el config.yaml se sobrescribió")
+	}
+}
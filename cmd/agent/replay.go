@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+	"github.com/asaavedra/agent-snmp/pkg/web"
+)
+
+// runRecord implementa `printsnmp record <ip>`
+// Graba un walk completo del dispositivo en un fixture portable (JSON)
+func runRecord(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	community := fs.String("community", "", "Comunidad SNMP (override de config)")
+	port := fs.Uint("port", 0, "Puerto SNMP (override de config)")
+	out := fs.String("out", "", "Archivo de salida (default: <modelo>.json)")
+	name := fs.String("name", "", "Nombre del fixture (default: modelo normalizado)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal(i18n.T("log.record_usage"))
+	}
+	ip := fs.Arg(0)
+
+	cfg := loadConfigOrDefault(*configFile)
+	if *community != "" {
+		cfg.SNMP.Community = *community
+	}
+	if *port != 0 {
+		cfg.SNMP.Port = uint16(*port)
+	}
+
+	client := newEngine(cfg).NewClient(ip, cfg.SNMP.Port, cfg.SNMP.Community, cfg.SNMP.Version,
+		time.Duration(cfg.SNMP.TimeoutMs)*time.Millisecond, cfg.SNMP.Retries).WithV3(cfg.SNMPv3()).
+		WithPriority(snmp.PriorityDiscovery)
+
+	fmt.Println(i18n.T("log.record_start", ip))
+	fixture, err := simulator.Record(client, *name, "", nil)
+	if err != nil {
+		log.Fatal(i18n.T("log.record_error", ip, err))
+	}
+
+	// Marca y nombre a partir de lo grabado
+	for _, v := range fixture.Variables {
+		if v.OID == "1.3.6.1.2.1.1.1.0" {
+			fixture.Brand = detector.DetectBrand(v.Value)
+			break
+		}
+	}
+	if fixture.Name == "" {
+		fixture.Name = fixtureName(fixture.Model, ip)
+	}
+
+	path := *out
+	if path == "" {
+		path = fixture.Name + ".json"
+	}
+	if err := fixture.Save(path); err != nil {
+		log.Fatal(i18n.T("log.record_error", ip, err))
+	}
+
+	fmt.Println(i18n.T("log.record_saved", len(fixture.Variables), path))
+}
+
+// runReplay implementa `printsnmp replay <fixture|dir|nombre>...`
+// Levanta un simulador por fixture y corre el pipeline completo contra ellos
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	listen := fs.String("listen", "", "Servir el dashboard con el resultado (ej: 127.0.0.1:8080)")
+	fs.Parse(args)
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+	loadMIBs(cfg)
+
+	sources := fs.Args()
+	if len(sources) == 0 {
+		sources = simulator.BuiltinNames()
+	}
+
+	fixtures, err := loadFixtures(sources)
+	if err != nil {
+		log.Fatal(i18n.T("log.replay_error", err))
+	}
+
+	startTime := time.Now()
+	discoveries := make([]scanner.DiscoveryResult, 0, len(fixtures))
+
+	for _, fixture := range fixtures {
+		// En replay se acepta cualquier community (la del config)
+		fixture.Community = ""
+
+		agent, err := simulator.NewAgent(fixture)
+		if err != nil {
+			log.Fatal(i18n.T("log.replay_error", err))
+		}
+		if err := agent.Start("127.0.0.1:0"); err != nil {
+			log.Fatal(i18n.T("log.replay_error", err))
+		}
+		defer agent.Close()
+
+		host, port := agent.Addr()
+		fmt.Println(i18n.T("log.replay_agent", fixture.Name, host, port))
+
+		discoveries = append(discoveries, scanner.DiscoveryResult{
+			IP:           host,
+			Port:         port,
+			Community:    cfg.SNMP.Community,
+			SNMPVersion:  cfg.SNMP.Version,
+			SysDescr:     fixtureValue(fixture, "1.3.6.1.2.1.1.1.0"),
+			SysObjectID:  fixtureValue(fixture, "1.3.6.1.2.1.1.2.0"),
+			IsResponsive: true,
+			DiscoveredAt: time.Now(),
+		})
+	}
+
+	engine := newEngine(cfg)
+	cfg.Polling.Force = true // un replay consulta todos los fixtures, vencidos o no
+
+	// Con -listen el dashboard se levanta antes del ciclo (eventos en vivo) y
+	// queda arriba con los datos del replay (demos y desarrollo de la UI)
+	var store *web.Store
+	var serverDone <-chan struct{}
+	ctx, stop := shutdownContext()
+	defer stop()
+	if *listen != "" {
+		cfg.Web.Listen = *listen
+		store = web.NewStore(newAgentSource())
+		serverDone = startDashboard(ctx, cfg, store, nil)
+	}
+
+	beginScan(&cfg)
+	if err := processPrinters(ctx, cfg, engine, discoveries, nil, startTime, store); err != nil {
+		log.Fatal(i18n.T("log.replay_error", err))
+	}
+
+	if serverDone != nil {
+		<-serverDone
+	}
+}
+
+// loadConfigOrDefault carga el config o usa los defaults si no existe
+func loadConfigOrDefault(path string) Config {
+	cfg := loadConfigFile(path)
+	if err := resolveSecrets(&cfg); err != nil {
+		log.Fatal(i18n.T("log.secrets_resolve_error", err))
+	}
+	return cfg
+}
+
+// loadConfigFile carga la configuración sin resolver secretos
+func loadConfigFile(path string) Config {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		log.Print(i18n.T("log.config_unreadable", err))
+		return DefaultConfig()
+	}
+	return cfg
+}
+
+// loadFixtures resuelve archivos, directorios y nombres de fixtures incluidos
+func loadFixtures(sources []string) ([]*simulator.Fixture, error) {
+	var fixtures []*simulator.Fixture
+
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		switch {
+		case err == nil && info.IsDir():
+			paths, _ := filepath.Glob(filepath.Join(source, "*.json"))
+			for _, path := range paths {
+				f, err := simulator.LoadFixture(path)
+				if err != nil {
+					return nil, err
+				}
+				fixtures = append(fixtures, f)
+			}
+		case err == nil:
+			f, err := simulator.LoadFixture(source)
+			if err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, f)
+		default:
+			f, err := simulator.Builtin(source)
+			if err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, f)
+		}
+	}
+
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no se encontraron fixtures en %s", strings.Join(sources, ", "))
+	}
+	return fixtures, nil
+}
+
+// fixtureValue retorna el valor de un OID del fixture ("" si no existe)
+func fixtureValue(f *simulator.Fixture, oid string) string {
+	for _, v := range f.Variables {
+		if v.OID == oid {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fixtureName genera un nombre de archivo a partir del modelo (o la IP)
+func fixtureName(model, ip string) string {
+	name := strings.Trim(nonAlnum.ReplaceAllString(strings.ToLower(model), "_"), "_")
+	if name == "" {
+		name = strings.ReplaceAll(ip, ".", "_")
+	}
+	return name
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/cron"
+)
+
+// validateSchedule rechaza las expresiones inválidas o que nunca vencen ("0 0 30 2 *")
+func validateSchedule(expr string) error {
+	s, err := cron.Parse(expr)
+	if err != nil {
+		return err
+	}
+	if s.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron %q: nunca vence", expr)
+	}
+	return nil
+}
+
+// CycleSchedule retorna la agenda cron del daemon (nil = interval_minutes)
+// Validate ya rechazó las expresiones inválidas
+func (cfg Config) CycleSchedule() *cron.Schedule {
+	if cfg.Daemon.Schedule == "" {
+		return nil
+	}
+	s, err := cron.Parse(cfg.Daemon.Schedule)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// CycleBlackout retorna las franjas en que el daemon no arranca ciclos
+func (cfg Config) CycleBlackout() cron.Blackout {
+	b, _ := cron.ParseBlackout(cfg.Daemon.Blackout)
+	return b
+}
+
+// siteSchedule es la agenda propia de la subred de un site
+type siteSchedule struct {
+	key      string // subnet: identifica la agenda en state/_schedule_runs.json
+	subnet   *net.IPNet
+	schedule *cron.Schedule // nil = solo blackout
+	blackout cron.Blackout
+}
+
+// siteSchedules retorna los sites con schedule o blackout, en el orden del config
+func (cfg Config) siteSchedules() []siteSchedule {
+	var sites []siteSchedule
+	for _, s := range cfg.Sites {
+		if s.Subnet == "" || (s.Schedule == "" && len(s.Blackout) == 0) {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(s.Subnet)
+		if err != nil {
+			continue
+		}
+		site := siteSchedule{key: s.Subnet, subnet: subnet}
+		if s.Schedule != "" {
+			site.schedule, _ = cron.Parse(s.Schedule)
+		}
+		site.blackout, _ = cron.ParseBlackout(s.Blackout)
+		sites = append(sites, site)
+	}
+	return sites
+}
+
+// nextSiteRun es el próximo vencimiento de alguna agenda de site posterior a
+// after, fuera de su blackout (cero si ningún site tiene schedule)
+func (cfg Config) nextSiteRun(after time.Time) time.Time {
+	var next time.Time
+	for _, site := range cfg.siteSchedules() {
+		if site.schedule == nil {
+			continue
+		}
+		at := site.schedule.Next(after)
+		if at.IsZero() {
+			continue
+		}
+		if at = site.blackout.Postpone(at); next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+	return next
+}
+
+// siteIPs filtra ips a las subredes de los sites con schedule (ciclo SitesOnly)
+func (cfg Config) siteIPs(ips []string) []string {
+	sites := cfg.siteSchedules()
+	var kept []string
+	for _, ip := range ips {
+		if site := matchSite(sites, ip); site != nil && site.schedule != nil {
+			kept = append(kept, ip)
+		}
+	}
+	return kept
+}
+
+// matchSite retorna la primera agenda cuya subred contiene ip
+func matchSite(sites []siteSchedule, ip string) *siteSchedule {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil
+	}
+	for i := range sites {
+		if sites[i].subnet.Contains(parsed) {
+			return &sites[i]
+		}
+	}
+	return nil
+}
+
+// siteScheduler aplica las agendas por site a los equipos de un ciclo
+// Un equipo de un site con schedule se consulta solo cuando la agenda venció
+// desde su última corrida (sin importar next_poll_at); en blackout, nunca
+type siteScheduler struct {
+	sites []siteSchedule
+	sm    *collector.StateManager
+	runs  map[string]time.Time // última corrida de cada agenda
+	fired map[string]bool      // agendas que corren en este ciclo
+	at    time.Time            // instante en que se evaluaron (ver filter)
+}
+
+// newSiteScheduler retorna nil si ningún site tiene agenda propia
+func newSiteScheduler(cfg Config, sm *collector.StateManager) *siteScheduler {
+	sites := cfg.siteSchedules()
+	if len(sites) == 0 {
+		return nil
+	}
+	return &siteScheduler{sites: sites, sm: sm, runs: sm.LoadScheduleRuns(), fired: make(map[string]bool)}
+}
+
+// filter separa los equipos que se consultan en este ciclo de los que no (IPs)
+// exempt son los que corren por la agenda de su site: el polling adaptativo no
+// los posterga. Con force se ignoran las agendas pero no los blackouts; con
+// sitesOnly quedan solo los equipos de sites cuya agenda venció
+func (s *siteScheduler) filter(devices []collector.DeviceInfo, now time.Time, force, sitesOnly bool) ([]collector.DeviceInfo, []string, map[string]bool) {
+	var due []collector.DeviceInfo
+	var skipped []string
+	exempt := make(map[string]bool)
+	s.at = now
+
+	for _, dev := range devices {
+		site := matchSite(s.sites, dev.IP)
+		switch {
+		case site == nil || (site.schedule == nil && !blackedOut(site, now)):
+			if sitesOnly {
+				skipped = append(skipped, dev.IP)
+				continue
+			}
+			due = append(due, dev)
+		case blackedOut(site, now):
+			skipped = append(skipped, dev.IP)
+		case force || s.due(site, now):
+			s.fired[site.key] = true
+			exempt[dev.IP] = true
+			due = append(due, dev)
+		default:
+			skipped = append(skipped, dev.IP)
+		}
+	}
+	return due, skipped, exempt
+}
+
+// blackedOut indica si el site está en una franja de exclusión
+func blackedOut(site *siteSchedule, now time.Time) bool {
+	_, active := site.blackout.Active(now)
+	return active
+}
+
+// due indica si la agenda del site venció desde su última corrida
+func (s *siteScheduler) due(site *siteSchedule, now time.Time) bool {
+	last, ok := s.runs[site.key]
+	if !ok {
+		return true
+	}
+	next := site.schedule.Next(last.In(now.Location()))
+	return !next.IsZero() && !now.Before(next)
+}
+
+// markRun guarda la corrida de las agendas que vencieron en este ciclo
+// Cuenta desde la evaluación: un vencimiento durante el ciclo no se pierde
+func (s *siteScheduler) markRun() error {
+	if len(s.fired) == 0 {
+		return nil
+	}
+	for key := range s.fired {
+		s.runs[key] = s.at.UTC()
+	}
+	return s.sm.SaveScheduleRuns(s.runs)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+)
+
+// runSchema implementa `printsnmp schema [-out dir] [tipo...]`
+// Sin -out imprime el schema pedido; con -out escribe <tipo>.schema.json de cada uno
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	out := fs.String("out", "", "Directorio donde escribir <tipo>.schema.json")
+	fs.Parse(args)
+
+	kinds := fs.Args()
+	if len(kinds) == 0 {
+		if *out == "" {
+			log.Fatal(i18n.T("log.schema_usage", strings.Join(schema.Kinds(), ", ")))
+		}
+		kinds = schema.Kinds()
+	}
+
+	for _, kind := range kinds {
+		s, err := schema.For(kind)
+		if err != nil {
+			log.Fatal(i18n.T("log.schema_usage", strings.Join(schema.Kinds(), ", ")))
+		}
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			log.Fatal(i18n.T("log.schema_error", kind, err))
+		}
+
+		if *out == "" {
+			fmt.Println(string(data))
+			continue
+		}
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			log.Fatal(i18n.T("log.schema_error", kind, err))
+		}
+		path := filepath.Join(*out, kind+".schema.json")
+		if err := fsutil.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+			log.Fatal(i18n.T("log.schema_error", kind, err))
+		}
+		fmt.Println(i18n.T("log.schema_written", path))
+	}
+}
+
+// runValidate implementa `printsnmp validate [-kind tipo] <archivo...>`
+// El tipo se deduce del contenido si no se indica; sale con 1 si algún archivo no cumple
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	kind := fs.String("kind", "", "Tipo de payload ("+strings.Join(schema.Kinds(), ", ")+"); vacío = deducir")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatal(i18n.T("log.validate_usage"))
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		errs, err := validateFile(path, *kind)
+		if err != nil {
+			failed++
+			fmt.Println(i18n.T("log.validate_error", path, err))
+			continue
+		}
+		if len(errs) > 0 {
+			failed++
+			fmt.Println(i18n.T("log.validate_invalid", path, len(errs)))
+			for _, e := range errs {
+				fmt.Printf("   %s\n", e)
+			}
+			continue
+		}
+		fmt.Println(i18n.T("log.validate_ok", path))
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// validateFile valida un archivo contra el schema de kind (o el deducido)
+// Un .ndjson se valida línea por línea
+func validateFile(path, kind string) ([]schema.ValidationError, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) != ".ndjson" {
+		return validateDocument(data, kind)
+	}
+
+	var all []schema.ValidationError
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		errs, err := validateDocument(line, kind)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", i+1, err)
+		}
+		for _, e := range errs {
+			e.Path = fmt.Sprintf("línea %d %s", i+1, e.Path)
+			all = append(all, e)
+		}
+	}
+	return all, nil
+}
+
+// validateDocument valida un documento JSON contra el schema de kind (o el deducido)
+func validateDocument(data []byte, kind string) ([]schema.ValidationError, error) {
+	if kind == "" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if kind = schema.Detect(doc); kind == "" {
+			return nil, fmt.Errorf("tipo de payload no reconocido (usar -kind)")
+		}
+	}
+	s, err := schema.For(kind)
+	if err != nil {
+		return nil, err
+	}
+	return s.ValidateJSON(data)
+}
+
+// firstSchemaError resume una validación fallida para el log del ciclo
+func firstSchemaError(errs []schema.ValidationError, err error) error {
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
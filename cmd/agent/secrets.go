@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/secrets"
+)
+
+// runSecrets implementa `printsnmp secrets set|delete|list`
+// El valor de `set` se lee de stdin si no se pasa como argumento,
+// para que no quede en el historial de la shell
+func runSecrets(args []string) {
+	fs := flag.NewFlagSet("secrets", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	vaultPath := fs.String("vault", "", "Archivo del vault (override de config)")
+	fs.Parse(args)
+
+	cfg := loadConfigFile(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+	if *vaultPath != "" {
+		cfg.Secrets.VaultPath = *vaultPath
+	}
+
+	if fs.NArg() < 1 {
+		log.Fatal(i18n.T("log.secrets_usage"))
+	}
+
+	vault, err := secrets.Open(cfg.Secrets.VaultPath)
+	if err != nil {
+		log.Fatal(i18n.T("log.secrets_error", err))
+	}
+
+	switch fs.Arg(0) {
+	case "set":
+		if fs.NArg() < 2 || fs.NArg() > 3 {
+			log.Fatal(i18n.T("log.secrets_usage"))
+		}
+		name := fs.Arg(1)
+		value := fs.Arg(2)
+		if fs.NArg() == 2 {
+			value, err = readSecretValue()
+			if err != nil {
+				log.Fatal(i18n.T("log.secrets_error", err))
+			}
+		}
+		if err := vault.Set(name, value); err != nil {
+			log.Fatal(i18n.T("log.secrets_error", err))
+		}
+		fmt.Println(i18n.T("log.secrets_saved", name, cfg.Secrets.VaultPath, vault.KeySource()))
+		fmt.Println(i18n.T("log.secrets_ref_hint", secrets.RefPrefix+name))
+
+	case "delete":
+		if fs.NArg() != 2 {
+			log.Fatal(i18n.T("log.secrets_usage"))
+		}
+		if err := vault.Delete(fs.Arg(1)); err != nil {
+			log.Fatal(i18n.T("log.secrets_error", err))
+		}
+		fmt.Println(i18n.T("log.secrets_deleted", fs.Arg(1)))
+
+	case "list":
+		for _, name := range vault.Names() {
+			fmt.Println(name)
+		}
+
+	default:
+		log.Fatal(i18n.T("log.secrets_usage"))
+	}
+}
+
+// readSecretValue lee el valor de stdin (una línea)
+func readSecretValue() (string, error) {
+	fmt.Fprint(os.Stderr, i18n.T("log.secrets_prompt"))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// resolveSecrets reemplaza las referencias "secret:<nombre>" de la config por
+// sus valores del vault. Sin referencias el vault ni siquiera se abre
+func resolveSecrets(cfg *Config) error {
+	fields := []*string{
+		&cfg.SNMP.Community,
+		&cfg.SNMP.V3.AuthPassword,
+		&cfg.SNMP.V3.PrivPassword,
+		&cfg.Sinks.HTTP.AuthToken,
+		&cfg.Sinks.HTTP.OAuth2.ClientSecret,
+		&cfg.Meters.SigningKey,
+		&cfg.ProfileStore.S3.SecretAccessKey,
+		&cfg.Sinks.Object.S3.SecretAccessKey,
+		&cfg.Sinks.Object.Azure.SASToken,
+		&cfg.Sinks.Postgres.URL,
+	}
+	for i := range cfg.Web.APIKeys {
+		fields = append(fields, &cfg.Web.APIKeys[i].Key)
+	}
+	for i := range cfg.Tenants {
+		fields = append(fields, &cfg.Tenants[i].Community, &cfg.Tenants[i].HTTP.AuthToken)
+	}
+
+	var vault *secrets.Vault
+	for _, field := range fields {
+		if !secrets.IsRef(*field) {
+			continue
+		}
+
+		if vault == nil {
+			var err error
+			vault, err = secrets.Open(cfg.Secrets.VaultPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		value, err := vault.Resolve(*field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+
+	return nil
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+	"github.com/asaavedra/agent-snmp/pkg/web"
+	"gopkg.in/yaml.v3"
+)
+
+// runServe implementa `printsnmp serve`: modo daemon con dashboard web
+// Corre un ciclo de discovery + recolección cada daemon.interval_minutes o
+// según daemon.schedule (o cuando un admin lo pide por la API), nunca dentro
+// de daemon.blackout, y sirve el dashboard y la API REST en web.listen hasta
+// recibir SIGINT/SIGTERM
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configFile := fs.String("config", "config.yaml", "Archivo de configuración")
+	ipRange := fs.String("range", "", "Override del rango de IPs")
+	listen := fs.String("listen", "", "Dirección del dashboard (override de config)")
+	fs.Parse(args)
+
+	d := &daemon{
+		configFile: *configFile,
+		trigger:    make(chan struct{}, 1),
+		lastRun:    make(map[string]time.Time),
+		lastCycle:  make(map[string]time.Time),
+		started:    time.Now(),
+		overrides: func(cfg *Config) {
+			if *ipRange != "" {
+				cfg.Discovery.IPRange = *ipRange
+			}
+			if *listen != "" {
+				cfg.Web.Listen = *listen
+			}
+		},
+	}
+
+	cfg := loadConfigOrDefault(*configFile)
+	i18n.SetLocale(cfg.Logging.Locale)
+	loadMIBs(cfg)
+
+	ctx, stop := shutdownContext()
+	defer stop()
+
+	// mode: cloud-sync → pull periódico; se aplica al inicio de cada ciclo
+	d.remote = newRemoteSync(cfg)
+	if d.remote != nil {
+		d.remote.fetch(ctx)
+		go d.remote.run(ctx, time.Duration(cfg.RemoteConfig.IntervalMinutes)*time.Minute)
+	}
+	cfg = d.remote.apply(cfg)
+	d.overrides(&cfg)
+
+	// Comandos remotos (rescan, rediscover_profile, debug_walk, set_interval)
+	d.commands = newCommandQueue(cfg)
+	if d.commands != nil {
+		go d.commands.run(ctx, time.Duration(cfg.RemoteConfig.CommandsIntervalSeconds)*time.Second)
+	}
+
+	requireTargets(cfg)
+	d.stateDir = cfg.StateDir()
+	agentClock = detectClock(cfg)
+	agentProfiles = collector.LoadProfiles(profileDir)
+
+	// El motor SNMP y el servidor web toman la config de arranque (cambiarlos requiere reiniciar)
+	engine := newEngine(cfg)
+	d.engine = engine
+	d.setConfig(cfg)
+
+	store := web.NewStore(newAgentSource())
+	serverDone := startDashboard(ctx, cfg, store, d)
+
+	forced := false
+	for {
+		// Cada tenant corre según su propio intervalo; POST /api/scan los corre todos
+		d.running.Store(true)
+		for _, target := range d.dueTargets(cfg, forced) {
+			if ctx.Err() != nil {
+				break
+			}
+			runTarget(ctx, d.scheduled(target, forced), engine, store)
+			if !target.Polling.SitesOnly {
+				d.lastRun[target.Tenant] = time.Now()
+			}
+			d.lastCycle[target.Tenant] = time.Now()
+		}
+		if cfg.retentionEnabled() && ctx.Err() == nil && time.Since(d.lastCompaction) >= cfg.compactInterval() {
+			compactState(cfg)
+			d.lastCompaction = time.Now()
+		}
+		d.running.Store(false)
+		forced = false
+
+		nextAt := d.nextDue(cfg)
+		fmt.Println(i18n.T("log.serve_next_cycle", nextAt.Format("15:04")))
+		next := time.NewTimer(time.Until(nextAt))
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				next.Stop()
+				<-serverDone
+				fmt.Println(i18n.T("log.serve_stopped"))
+				return
+			case <-next.C:
+				break wait
+			case <-d.trigger:
+				next.Stop()
+				forced = true
+				break wait
+			case cmd := <-d.commands.commands():
+				d.execute(ctx, cfg, cmd)
+				if changed := d.nextDue(cfg); !changed.Equal(nextAt) {
+					nextAt = changed
+					next.Reset(time.Until(nextAt))
+					fmt.Println(i18n.T("log.serve_next_cycle", nextAt.Format("15:04")))
+				}
+			}
+		}
+		// Desde acá el ciclo cuenta como en curso: un disparo durante la recarga
+		// de config no encola otro ciclo completo detrás de este
+		d.running.Store(true)
+
+		// Los cambios de config.yaml (PUT /api/config o edición manual) se aplican acá
+		cfg = d.reload(cfg)
+		d.setConfig(cfg)
+	}
+}
+
+// daemon es el estado de `printsnmp serve` que la API puede controlar
+// Implementa web.Controller
+type daemon struct {
+	configFile string
+	overrides  func(*Config) // flags de la línea de comandos, ganan sobre el archivo
+	trigger    chan struct{}
+	running    atomic.Bool
+	remote     *remoteSync   // nil fuera de cloud-sync
+	commands   *commandQueue // nil sin remote_config.commands_url
+	interval   time.Duration // set_interval remoto; gana sobre daemon.interval_minutes
+	tagsMu     sync.Mutex    // serializa PUT /api/printers/{id}/tags
+	stateDir   string        // state/ global (tags de la API)
+
+	// Consultas a pedido de la API (ver CollectPrinter)
+	config     atomic.Pointer[Config] // config vigente del ciclo
+	engine     *snmp.Engine           // motor compartido con los ciclos: comparten sus slots
+	collectMu  sync.Mutex
+	collecting map[string]bool                     // impresoras con una consulta en curso
+	collectors map[string]*collector.DataCollector // colector por tenant, se descartan con cada config
+
+	lastRun   map[string]time.Time // fin del último ciclo completo por tenant ("" = sin tenants)
+	lastCycle map[string]time.Time // fin del último ciclo de cualquier tipo (incluye los de agenda por site)
+	started   time.Time            // arranque: referencia de las agendas cron antes del primer ciclo
+
+	lastCompaction time.Time // última compactación de state/ (ver compactState)
+}
+
+// cycleInterval es la espera entre ciclos
+// Con polling adaptativo el ciclo corre al ritmo del equipo más frecuente
+// (min_interval_minutes); cada ciclo consulta solo a los vencidos
+func (d *daemon) cycleInterval(cfg Config) time.Duration {
+	interval := d.baseInterval(cfg)
+	if tick := time.Duration(cfg.Polling.MinIntervalMinutes) * time.Minute; cfg.Polling.Adaptive && tick > 0 && tick < interval {
+		return tick
+	}
+	return interval
+}
+
+// baseInterval es el intervalo de escaneo configurado (o el de set_interval)
+func (d *daemon) baseInterval(cfg Config) time.Duration {
+	if d.interval > 0 {
+		return d.interval
+	}
+	if cfg.Daemon.IntervalMinutes <= 0 {
+		return time.Hour
+	}
+	return time.Duration(cfg.Daemon.IntervalMinutes) * time.Minute
+}
+
+// scheduled prepara un objetivo para correr: el intervalo real del daemon
+// define el next_poll_at de cada equipo y un ciclo forzado consulta a todos
+func (d *daemon) scheduled(target Config, forced bool) Config {
+	target.Daemon.IntervalMinutes = int(d.baseInterval(target) / time.Minute)
+	target.Polling.Force = forced
+	return target
+}
+
+// dueTargets retorna los objetivos cuyo intervalo o agenda ya venció (todos
+// si force); los que solo tienen vencida la agenda de un site corren con
+// Polling.SitesOnly. Dentro de daemon.blackout no corre ninguno, ni forzado
+func (d *daemon) dueTargets(cfg Config, force bool) []Config {
+	now := time.Now()
+	var due []Config
+	for _, target := range cfg.Targets() {
+		if w, active := target.CycleBlackout().Active(now); active {
+			if force {
+				log.Print(i18n.T("log.blackout_skip", w))
+			}
+			continue
+		}
+		run, siteRun := d.nextRun(target), d.nextSiteRun(target)
+		switch {
+		case force || (!run.IsZero() && !now.Before(run)):
+			due = append(due, target)
+		case !siteRun.IsZero() && !now.Before(siteRun):
+			target.Polling.SitesOnly = true
+			due = append(due, target)
+		}
+	}
+	return due
+}
+
+// nextRun es el próximo ciclo completo de un objetivo: el siguiente
+// vencimiento de daemon.schedule o el fin del intervalo, corrido al final
+// del blackout si cae adentro
+func (d *daemon) nextRun(target Config) time.Time {
+	last, ok := d.lastRun[target.Tenant]
+	var at time.Time
+	switch sched := target.CycleSchedule(); {
+	case sched != nil:
+		if !ok {
+			last = d.started
+		}
+		at = sched.Next(last)
+	case !ok:
+		at = time.Now() // tenant nuevo (agregado en un reload): corre ya
+	default:
+		at = last.Add(d.cycleInterval(target))
+	}
+	return target.CycleBlackout().Postpone(at)
+}
+
+// nextSiteRun es el próximo vencimiento de la agenda de algún site del
+// objetivo (cero si ningún site tiene schedule)
+func (d *daemon) nextSiteRun(target Config) time.Time {
+	last, ok := d.lastCycle[target.Tenant]
+	if !ok {
+		last = d.started
+	}
+	next := target.nextSiteRun(last)
+	if next.IsZero() {
+		return next
+	}
+	return target.CycleBlackout().Postpone(next)
+}
+
+// nextDue es el instante en que vence el próximo objetivo
+func (d *daemon) nextDue(cfg Config) time.Time {
+	var next time.Time
+	for _, target := range cfg.Targets() {
+		for _, at := range []time.Time{d.nextRun(target), d.nextSiteRun(target)} {
+			if !at.IsZero() && (next.IsZero() || at.Before(next)) {
+				next = at
+			}
+		}
+	}
+	return next
+}
+
+// TriggerScan adelanta el próximo ciclo; false si hay uno en curso o ya
+// pedido (dos pedidos seguidos no encadenan dos ciclos completos)
+func (d *daemon) TriggerScan() bool {
+	if d.running.Load() {
+		return false
+	}
+	select {
+	case d.trigger <- struct{}{}:
+		return true
+	default: // ya había un disparo pendiente
+		return false
+	}
+}
+
+// Config retorna el config.yaml tal como está en disco (referencias secret: sin resolver)
+func (d *daemon) Config() ([]byte, error) {
+	return os.ReadFile(d.configFile)
+}
+
+// UpdateConfig valida el YAML (incluidas las referencias al vault) y lo guarda
+// Rechaza cambios en las secciones local-only (ver pinLocalOnly)
+func (d *daemon) UpdateConfig(data []byte) error {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("error parseando YAML: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	// Los programas externos solo se cambian editando el archivo en el equipo
+	var local Config
+	if current, err := os.ReadFile(d.configFile); err == nil {
+		yaml.Unmarshal(current, &local)
+	}
+	if section := localOnlyChange(local, cfg); section != "" {
+		return fmt.Errorf("%s solo se puede cambiar en el config.yaml local", section)
+	}
+	if err := resolveSecrets(&cfg); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(d.configFile, data, 0600)
+}
+
+// PrinterTags retorna los tags asignados por la API a una impresora
+func (d *daemon) PrinterTags(printerID string) (map[string]string, error) {
+	tags := collector.NewStateManager(d.stateDir).LoadTags()[printerID]
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	return tags, nil
+}
+
+// SetPrinterTags guarda en state/ los tags asignados por la API
+// No toma el lock de state/: _tags.json solo lo escribe la API
+func (d *daemon) SetPrinterTags(printerID string, tags map[string]string) error {
+	d.tagsMu.Lock()
+	defer d.tagsMu.Unlock()
+
+	stateManager := collector.NewStateManager(d.stateDir)
+	all := stateManager.LoadTags()
+	if len(tags) == 0 {
+		delete(all, printerID)
+	} else {
+		all[printerID] = tags
+	}
+	return stateManager.SaveTags(all)
+}
+
+// PrinterHistory lee el historial de contadores de la impresora del state/
+// de su tenant (GraphQL history)
+func (d *daemon) PrinterHistory(last *telemetry.Telemetry, since time.Time) (*collector.PrinterHistory, error) {
+	cfg, ok := d.targetConfig(last.Source.Tenant)
+	if !ok {
+		return nil, fmt.Errorf("tenant %q is no longer configured", last.Source.Tenant)
+	}
+	return collector.NewStateManager(cfg.StateDir()).LoadHistory(last.Printer.ID, since)
+}
+
+// setConfig publica la config vigente para las consultas de la API (copia:
+// el loop sigue reasignando la suya). Los colectores a pedido se recrean con
+// la nueva y los perfiles compartidos toman su profile_store
+func (d *daemon) setConfig(cfg Config) {
+	d.config.Store(&cfg)
+	if agentProfiles != nil {
+		agentProfiles.SetStore(newProfileStore(cfg))
+	}
+
+	d.collectMu.Lock()
+	d.collectors = nil
+	d.collectMu.Unlock()
+}
+
+// reload relee config.yaml y superpone la config remota vigente
+// Si config.yaml no se puede usar se mantiene la config anterior
+func (d *daemon) reload(prev Config) Config {
+	cfg, err := LoadConfig(d.configFile)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err == nil {
+		err = resolveSecrets(&cfg)
+	}
+	if err != nil {
+		log.Print(i18n.T("log.serve_reload_error", err))
+		return prev
+	}
+
+	cfg = d.remote.apply(cfg)
+	d.overrides(&cfg)
+	i18n.SetLocale(cfg.Logging.Locale)
+	return cfg
+}
+
+// startDashboard levanta el servidor web en segundo plano; controller puede ser nil
+// El canal se cierra cuando el servidor terminó de apagarse
+func startDashboard(ctx context.Context, cfg Config, store *web.Store, controller web.Controller) <-chan struct{} {
+	done := make(chan struct{})
+	server := web.NewServer(cfg.WebConfig(), store, controller)
+
+	go func() {
+		defer close(done)
+		if err := server.ListenAndServe(ctx); err != nil {
+			log.Fatal(i18n.T("log.web_error", err))
+		}
+	}()
+
+	fmt.Println(i18n.T("log.web_listening", cfg.Web.Listen))
+	if !server.AuthEnabled() && !isLoopbackListen(cfg.Web.Listen) {
+		log.Print(i18n.T("log.web_open", cfg.Web.Listen))
+	}
+	return done
+}
+
+// isLoopbackListen indica si la dirección solo escucha en la máquina local
+func isLoopbackListen(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireTargets termina el proceso si algún objetivo no tiene un rango usable
+func requireTargets(cfg Config) {
+	for _, target := range cfg.Targets() {
+		if target.Discovery.IPRange == "" {
+			log.Fatal(i18n.T("log.range_required"))
+		}
+		if _, err := scanner.ParseIPRange(target.Discovery.IPRange); err != nil {
+			log.Fatal(i18n.T("log.range_invalid", err))
+		}
+	}
+}
+
+// runTarget corre un ciclo de un objetivo de escaneo (la config global o un tenant)
+func runTarget(ctx context.Context, cfg Config, engine *snmp.Engine, store *web.Store) {
+	if cfg.Tenant != "" {
+		fmt.Println(i18n.T("log.tenant_cycle", cfg.Tenant, cfg.Discovery.IPRange))
+	}
+	ips, err := scanner.ParseIPRange(cfg.Discovery.IPRange)
+	if err != nil {
+		log.Print(i18n.T("log.range_invalid", err))
+		return
+	}
+	// Ciclo de agenda por site: el discovery se limita a sus subredes
+	if cfg.Polling.SitesOnly {
+		ips = cfg.siteIPs(ips)
+		fmt.Println(i18n.T("log.site_cycle", len(ips)))
+	}
+	runCycle(ctx, cfg, engine, ips, store)
+}
+
+// runCycle ejecuta un ciclo completo (discovery + recolección)
+// Los errores se loguean: un ciclo fallido no detiene el daemon
+func runCycle(ctx context.Context, cfg Config, engine *snmp.Engine, ips []string, store *web.Store) {
+	startTime := time.Now()
+	beginScan(&cfg)
+
+	discoveryConfig := newDiscoveryConfig(cfg, engine)
+	discoveryConfig.OnProgress = newProgressReporter(cfg, store)
+	discoveryScanner := scanner.NewDiscoveryScanner(discoveryConfig)
+	discoveries, err := discoveryScanner.Scan(ctx, ips)
+	if err != nil {
+		log.Print(i18n.T("log.discovery_error", err))
+		return
+	}
+	if ctx.Err() != nil {
+		log.Print(i18n.T("log.discovery_aborted"))
+		return
+	}
+
+	noSNMP := discoveryScanner.NoSNMP()
+	if len(discoveries) == 0 && len(noSNMP) == 0 {
+		emitHeartbeat(ctx, cfg, telemetry.ScanStats{
+			ScanID:     cfg.ScanID,
+			StartedAt:  startTime.UTC(),
+			DurationMs: time.Since(startTime).Milliseconds(),
+		}, telemetry.ErrorCounts{})
+		log.Print(i18n.T("log.no_devices"))
+		return
+	}
+
+	if err := processPrinters(ctx, cfg, engine, discoveries, noSNMP, startTime, store); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// Tenants: varios objetivos de escaneo en un mismo agente
+//
+// Un MSP con un agente por edificio define un tenant por oficina, cada uno con
+// su rango, credenciales, intervalo y endpoint. Cada tenant escribe en su
+// propia queue y su propio state/: los contadores, el inventario y los eventos
+// de un cliente nunca se mezclan con los de otro. El motor SNMP (límites
+// globales), los perfiles de modelo y el dashboard se comparten
+
+// defaultStateDir es el directorio de estado sin state.dir
+const defaultStateDir = "state"
+
+// TenantConfig es un objetivo de escaneo de la sección tenants
+// Los campos vacíos heredan la config global
+type TenantConfig struct {
+	Name            string `yaml:"name"`             // [a-zA-Z0-9_-]; nombra la queue y el state/ del tenant
+	IPRange         string `yaml:"ip_range"`         // requerido
+	Community       string `yaml:"community"`        // "" = snmp.community; usar "secret:snmp.community.<name>"
+	Version         string `yaml:"version"`          // "" = snmp.version
+	IntervalMinutes int    `yaml:"interval_minutes"` // 0 = daemon.interval_minutes
+	QueuePath       string `yaml:"queue_path"`       // "" = <sinks.file.path>/<name>
+	StateDir        string `yaml:"state_dir"`        // "" = <state.dir>/<name>
+	HTTP            struct {
+		Endpoint  string `yaml:"endpoint"`   // "" = sinks.http.endpoint
+		AuthToken string `yaml:"auth_token"` // "" = sinks.http.auth_token; usar "secret:sinks.http.auth_token.<name>"
+	} `yaml:"http"`
+}
+
+// tenantNamePattern restringe los nombres a algo usable como directorio
+var tenantNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// StateDir es el directorio de estado de esta config (la del tenant si es derivada)
+func (cfg Config) StateDir() string {
+	if cfg.State.Dir == "" {
+		return defaultStateDir
+	}
+	return cfg.State.Dir
+}
+
+// SharedStateDir es el state/ global: datos que no son de un tenant (tags de la API)
+func (cfg Config) SharedStateDir() string {
+	if cfg.State.Shared != "" {
+		return cfg.State.Shared
+	}
+	return cfg.StateDir()
+}
+
+// Targets retorna una config por objetivo de escaneo
+// Sin tenants es la config global tal cual
+func (cfg Config) Targets() []Config {
+	if len(cfg.Tenants) == 0 {
+		return []Config{cfg}
+	}
+	targets := make([]Config, 0, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		targets = append(targets, cfg.ForTenant(t))
+	}
+	return targets
+}
+
+// ForTenant deriva la config de un tenant: rango, credenciales, agenda, queue,
+// state/ y salidas propias sobre la config global
+func (cfg Config) ForTenant(t TenantConfig) Config {
+	tc := cfg
+	tc.Tenant = t.Name
+	tc.Tenants = nil
+
+	tc.Discovery.IPRange = t.IPRange
+	if t.Community != "" {
+		tc.SNMP.Community = t.Community
+	}
+	if t.Version != "" {
+		tc.SNMP.Version = t.Version
+	}
+	if t.IntervalMinutes > 0 {
+		tc.Daemon.IntervalMinutes = t.IntervalMinutes
+	}
+
+	tc.State.Shared = cfg.StateDir()
+	tc.State.Dir = t.StateDir
+	if tc.State.Dir == "" {
+		tc.State.Dir = filepath.Join(cfg.StateDir(), t.Name)
+	}
+	tc.Sinks.File.Path = t.QueuePath
+	if tc.Sinks.File.Path == "" {
+		tc.Sinks.File.Path = filepath.Join(cfg.Sinks.File.Path, t.Name)
+	}
+	if t.HTTP.Endpoint != "" {
+		tc.Sinks.HTTP.Endpoint = t.HTTP.Endpoint
+	}
+	if t.HTTP.AuthToken != "" {
+		tc.Sinks.HTTP.AuthToken = t.HTTP.AuthToken
+	}
+
+	// Salidas por archivo: una por tenant junto a la global
+	if p := cfg.Collector.SummaryPath; p != "" {
+		ext := filepath.Ext(p)
+		tc.Collector.SummaryPath = strings.TrimSuffix(p, ext) + "_" + t.Name + ext
+	}
+	if cfg.Collector.CoverageDir != "" {
+		tc.Collector.CoverageDir = filepath.Join(cfg.Collector.CoverageDir, t.Name)
+	}
+	if cfg.Output.Dir != "" {
+		tc.Output.Dir = filepath.Join(cfg.Output.Dir, t.Name)
+	}
+	if cfg.Meters.OutputDir != "" {
+		tc.Meters.OutputDir = filepath.Join(cfg.Meters.OutputDir, t.Name)
+	}
+	return tc
+}
+
+// validateTenants revisa nombres únicos y rangos de cada tenant
+func validateTenants(tenants []TenantConfig) error {
+	seen := make(map[string]bool)
+	for i, t := range tenants {
+		if !tenantNamePattern.MatchString(t.Name) {
+			return fmt.Errorf("tenants[%d].name: %q inválido (letras, números, _ y -)", i, t.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("tenants[%d].name: %q repetido", i, t.Name)
+		}
+		seen[t.Name] = true
+
+		if t.IPRange == "" {
+			return fmt.Errorf("tenants.%s.ip_range: requerido", t.Name)
+		}
+		if _, err := scanner.ParseIPRange(t.IPRange); err != nil {
+			return fmt.Errorf("tenants.%s.ip_range: %w", t.Name, err)
+		}
+		switch t.Version {
+		case "", "1", "2c", "3":
+		default:
+			return fmt.Errorf("tenants.%s.version: %q no soportada (1, 2c, 3)", t.Name, t.Version)
+		}
+		if t.IntervalMinutes < 0 {
+			return fmt.Errorf("tenants.%s.interval_minutes: debe ser >= 0", t.Name)
+		}
+	}
+	return nil
+}
+
+// agentSource es la identidad del agente en los eventos de cfg (con su tenant)
+func agentSource(cfg Config) telemetry.AgentSource {
+	source := newAgentSource()
+	source.Tenant = cfg.Tenant
+	return source
+}
@@ -0,0 +1,38 @@
+// Package pbkdf2 implementa PBKDF2-HMAC-SHA256 (RFC 8018) sin dependencias
+// externas: golang.org/x/crypto no está disponible en los builds sin red
+package pbkdf2
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// Key deriva keyLen bytes de password y salt con iterations rondas
+func Key(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	out := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, hashLen)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+
+	return out[:keyLen]
+}
@@ -0,0 +1,27 @@
+package pbkdf2
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Vectores de PBKDF2-HMAC-SHA256 de RFC 7914 (sección 11) y de uso común;
+// 40 bytes cubre una salida que no es múltiplo del bloque
+func TestKey(t *testing.T) {
+	for _, tc := range []struct {
+		password, salt string
+		iterations     int
+		keyLen         int
+		want           string
+	}{
+		{"passwd", "salt", 1, 64, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, 64, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{"password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{"password", "salt", 4096, 40, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134af7ad98c1b458ce3f"},
+	} {
+		got := hex.EncodeToString(Key([]byte(tc.password), []byte(tc.salt), tc.iterations, tc.keyLen))
+		if got != tc.want {
+			t.Errorf("Key(%q, %q, %d, %d) = %s, se esperaba %s", tc.password, tc.salt, tc.iterations, tc.keyLen, got, tc.want)
+		}
+	}
+}
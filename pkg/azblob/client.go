@@ -0,0 +1,123 @@
+// Package azblob es un cliente mínimo de Azure Blob Storage (PUT de block
+// blobs) autenticado con un SAS token, sin depender del SDK
+// Azure cifra siempre en reposo; EncryptionScope elige una llave propia
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiVersion es la versión del REST API de Blob Storage usada (x-ms-version)
+const apiVersion = "2021-08-06"
+
+// Config configura el acceso a un contenedor
+type Config struct {
+	Account         string // cuenta de almacenamiento
+	Container       string
+	Endpoint        string // "" = https://<account>.blob.core.windows.net; URL de Azurite u otra nube
+	SASToken        string // "sv=...&sig=..." con permiso de escritura (create/write)
+	EncryptionScope string // scope de cifrado del contenedor ("" = el default de la cuenta)
+	Timeout         time.Duration
+}
+
+// Client sube blobs a un contenedor
+type Client struct {
+	config Config
+	base   *url.URL // URL del contenedor
+	client *http.Client
+}
+
+// New crea el cliente; falla si falta cuenta, contenedor o SAS token
+func New(config Config) (*Client, error) {
+	if config.Container == "" {
+		return nil, errors.New("azblob: falta container")
+	}
+	if config.SASToken == "" {
+		return nil, errors.New("azblob: falta sas_token")
+	}
+	if config.Endpoint == "" {
+		if config.Account == "" {
+			return nil, errors.New("azblob: falta account")
+		}
+		config.Endpoint = "https://" + config.Account + ".blob.core.windows.net"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	base, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("azblob: endpoint inválido %q", config.Endpoint)
+	}
+	base.Path += "/" + config.Container
+	base.RawQuery = strings.TrimPrefix(config.SASToken, "?")
+
+	return &Client{
+		config: config,
+		base:   base,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Put sube (o reemplaza) un block blob
+func (c *Client) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	u := *c.base
+	u.Path += "/" + strings.TrimPrefix(key, "/")
+	u.RawPath = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.config.EncryptionScope != "" {
+		req.Header.Set("x-ms-encryption-scope", c.config.EncryptionScope)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var azErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&azErr)
+	if azErr.Code != "" {
+		return &Error{StatusCode: resp.StatusCode, Code: azErr.Code, Message: strings.TrimSpace(azErr.Message)}
+	}
+	return &Error{StatusCode: resp.StatusCode}
+}
+
+// Error es una respuesta != 2xx del servicio
+type Error struct {
+	StatusCode int
+	Code       string // "AuthenticationFailed", "ContainerNotFound"...
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("azblob: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("azblob: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
@@ -0,0 +1,113 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// csvHeader son las columnas del CSV de lecturas
+// Una fila por impresora, nombres en snake_case como esperan los importadores MPS
+var csvHeader = []string{
+	"period",
+	"serial_number",
+	"printer_id",
+	"brand",
+	"model",
+	"ip_address",
+	"mac_address",
+	"read_at",
+	"total_pages",
+	"mono_pages",
+	"color_pages",
+	"scan_pages",
+	"copy_pages",
+	"fax_pages",
+	"duplex_pages",
+	"a3_pages",
+	"partial",
+	"tags", // "clave=valor;clave=valor" ordenado por clave
+	// Costos estimados (vacíos sin catálogo de costos, ver telemetry.CostInfo)
+	"currency",
+	"cost_per_page",
+	"color_cost_per_page",
+	"monthly_spend",
+}
+
+// CSV convierte el reporte de cierre a CSV (UTF-8, separador coma, con encabezado)
+func CSV(report *telemetry.MeterReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, read := range report.Reads {
+		c := read.Counters
+		row := []string{
+			report.Period,
+			read.SerialNumber,
+			read.PrinterID,
+			read.Brand,
+			read.Model,
+			read.IP,
+			read.MacAddress,
+			read.ReadAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(c.TotalPages, 10),
+			strconv.FormatInt(c.MonoPages, 10),
+			strconv.FormatInt(c.ColorPages, 10),
+			strconv.FormatInt(c.ScanPages, 10),
+			strconv.FormatInt(c.CopyPages, 10),
+			strconv.FormatInt(c.FaxPages, 10),
+			strconv.FormatInt(c.DuplexPages, 10),
+			strconv.FormatInt(c.A3Pages, 10),
+			strconv.FormatBool(read.Partial),
+			formatTags(read.Tags),
+		}
+		row = append(row, formatCosts(read.Costs)...)
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatCosts retorna las columnas de costos (vacías si no hay estimación)
+func formatCosts(costs *telemetry.CostInfo) []string {
+	if costs == nil {
+		return []string{"", "", "", ""}
+	}
+	formatCost := func(v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return []string{costs.Currency, formatCost(costs.CostPerPage), formatCost(costs.ColorCostPerPage), formatCost(costs.MonthlySpend)}
+}
+
+// formatTags serializa los tags en una sola columna ("" si no hay)
+func formatTags(tags telemetry.Tags) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return strings.Join(pairs, ";")
+}
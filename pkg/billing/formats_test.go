@@ -0,0 +1,151 @@
+package billing
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+func testReport() *telemetry.MeterReport {
+	readAt := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	return &telemetry.MeterReport{
+		Period:      "2026-10",
+		GeneratedAt: readAt.Add(time.Hour),
+		Source:      telemetry.AgentSource{AgentID: "AGT-CL-001"},
+		Reads: []telemetry.MeterRead{
+			{
+				PrinterID:    "PRN-1",
+				SerialNumber: "CN123",
+				Brand:        "HP",
+				Model:        "LaserJet, M507", // coma: tiene que ir entre comillas
+				IP:           "10.0.0.5",
+				MacAddress:   "00:11:22:33:44:55",
+				Hostname:     "hp-piso2",
+				Location:     "Piso 2",
+				Tags:         telemetry.Tags{"site": "stgo", "area": "ventas"},
+				ReadAt:       readAt,
+				Counters:     collector.CountersInfo{TotalPages: 1500, MonoPages: 1000, ColorPages: 500, DuplexPages: 200},
+				Costs:        &telemetry.CostInfo{Currency: "USD", CostPerPage: 0.031, MonthlySpend: 130.8},
+			},
+			{
+				PrinterID: "PRN-2",
+				IP:        "10.0.0.6",
+				ReadAt:    readAt,
+				Counters:  collector.CountersInfo{TotalPages: 42},
+				Partial:   true,
+			},
+		},
+	}
+}
+
+func readCSV(t *testing.T, data []byte) []map[string]string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, col := range records[0] {
+			row[col] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestCSV(t *testing.T) {
+	data, err := CSV(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSV(t, data)
+	if len(rows) != 2 {
+		t.Fatalf("%d filas, se esperaban 2", len(rows))
+	}
+
+	for col, want := range map[string]string{
+		"period":              "2026-10",
+		"model":               "LaserJet, M507",
+		"read_at":             "2026-10-01T08:00:00Z",
+		"total_pages":         "1500",
+		"color_pages":         "500",
+		"partial":             "false",
+		"tags":                "area=ventas;site=stgo",
+		"currency":            "USD",
+		"cost_per_page":       "0.031",
+		"color_cost_per_page": "",
+		"monthly_spend":       "130.8",
+	} {
+		if got := rows[0][col]; got != want {
+			t.Errorf("%s = %q, se esperaba %q", col, got, want)
+		}
+	}
+
+	// Sin tags ni costos las columnas van vacías
+	for _, col := range []string{"tags", "currency", "cost_per_page", "monthly_spend"} {
+		if got := rows[1][col]; got != "" {
+			t.Errorf("fila sin datos: %s = %q", col, got)
+		}
+	}
+	if rows[1]["partial"] != "true" {
+		t.Errorf("partial = %q", rows[1]["partial"])
+	}
+}
+
+func TestPaperCutCSV(t *testing.T) {
+	data, err := PaperCutCSV(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSV(t, data)
+	if len(rows) != 2 {
+		t.Fatalf("%d filas, se esperaban 2", len(rows))
+	}
+	if rows[0]["Device Name"] != "hp-piso2" || rows[0]["Device Type"] != "PRINTER" || rows[0]["Grayscale Page Count"] != "1000" {
+		t.Errorf("fila %v", rows[0])
+	}
+	// Sin hostname el nombre es el ID de la impresora
+	if rows[1]["Device Name"] != "PRN-2" {
+		t.Errorf("Device Name = %q, se esperaba PRN-2", rows[1]["Device Name"])
+	}
+}
+
+func TestPrintFleetXML(t *testing.T) {
+	data, err := PrintFleetXML(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("falta el encabezado XML")
+	}
+
+	var audit printFleetAudit
+	if err := xml.Unmarshal(data, &audit); err != nil {
+		t.Fatal(err)
+	}
+	if audit.Period != "2026-10" || audit.Collector != "AGT-CL-001" || audit.AuditDate != "2026-10-01T09:00:00Z" || len(audit.Devices) != 2 {
+		t.Fatalf("auditoría %+v", audit)
+	}
+
+	meters := func(d printFleetDevice) string {
+		var s []string
+		for _, m := range d.Meters {
+			s = append(s, m.Type)
+		}
+		return strings.Join(s, ",")
+	}
+	if got := meters(audit.Devices[0]); got != "LifeCount,LifeCountMono,LifeCountColor,DuplexCount" {
+		t.Errorf("medidores %s", got)
+	}
+	// LifeCount va aunque el resto sea 0
+	if got := meters(audit.Devices[1]); got != "LifeCount" || audit.Devices[1].Meters[0].Value != 42 {
+		t.Errorf("medidores del equipo parcial %s", got)
+	}
+}
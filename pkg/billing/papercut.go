@@ -0,0 +1,72 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// paperCutHeader son las columnas de la importación masiva de dispositivos de
+// PaperCut MF (Devices → Import). Las columnas de contadores se agregan al
+// final para conciliar la migración contra los totales que ya tiene PaperCut
+var paperCutHeader = []string{
+	"Device Name",
+	"Hostname/IP",
+	"Device Type",
+	"Location",
+	"Serial Number",
+	"Make",
+	"Model",
+	"MAC Address",
+	"Total Page Count",
+	"Color Page Count",
+	"Grayscale Page Count",
+	"Duplex Page Count",
+}
+
+// paperCutDeviceType es el tipo de dispositivo que PaperCut asigna a
+// impresoras de red monitoreadas por SNMP
+const paperCutDeviceType = "PRINTER"
+
+// PaperCutCSV convierte el reporte a la plantilla de importación de PaperCut MF
+func PaperCutCSV(report *telemetry.MeterReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(paperCutHeader); err != nil {
+		return nil, err
+	}
+
+	for _, read := range report.Reads {
+		name := read.Hostname
+		if name == "" {
+			name = read.PrinterID
+		}
+
+		row := []string{
+			name,
+			read.IP,
+			paperCutDeviceType,
+			read.Location,
+			read.SerialNumber,
+			read.Brand,
+			read.Model,
+			read.MacAddress,
+			strconv.FormatInt(read.Counters.TotalPages, 10),
+			strconv.FormatInt(read.Counters.ColorPages, 10),
+			strconv.FormatInt(read.Counters.MonoPages, 10),
+			strconv.FormatInt(read.Counters.DuplexPages, 10),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
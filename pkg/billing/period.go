@@ -0,0 +1,83 @@
+package billing
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Formatos de export
+const (
+	FormatJSON       = "json"
+	FormatCSV        = "csv"
+	FormatBoth       = "both"       // json + csv (compatibilidad)
+	FormatPaperCut   = "papercut"   // CSV de importación de dispositivos de PaperCut MF
+	FormatPrintFleet = "printfleet" // XML de auditoría PrintFleet/FMAudit
+)
+
+// ParseFormats interpreta meters.format: un formato o varios separados por coma
+// ("json,papercut"). Vacío equivale a "both"
+func ParseFormats(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		value = FormatBoth
+	}
+
+	var formats []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(value, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		expanded := []string{f}
+		if f == FormatBoth {
+			expanded = []string{FormatJSON, FormatCSV}
+		}
+		for _, e := range expanded {
+			switch e {
+			case FormatJSON, FormatCSV, FormatPaperCut, FormatPrintFleet:
+			default:
+				return nil, fmt.Errorf("formato de export desconocido: %s", e)
+			}
+			if !seen[e] {
+				seen[e] = true
+				formats = append(formats, e)
+			}
+		}
+	}
+	return formats, nil
+}
+
+// Extension retorna la extensión de archivo de cada formato
+func Extension(format string) string {
+	switch format {
+	case FormatPaperCut:
+		return "papercut.csv"
+	case FormatPrintFleet:
+		return "printfleet.xml"
+	default:
+		return format
+	}
+}
+
+// Period retorna el período de facturación de t ("2026-10")
+func Period(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// CloseDue indica si corresponde el cierre mensual en now: ya pasó el día de
+// cierre del mes y el último cierre registrado es de otro período
+// closeDay fuera de 1-28 se ajusta para que todos los meses tengan cierre
+func CloseDue(now time.Time, closeDay int, lastPeriod string) bool {
+	if closeDay < 1 {
+		closeDay = 1
+	}
+	if closeDay > 28 {
+		closeDay = 28
+	}
+	return now.Day() >= closeDay && lastPeriod != Period(now)
+}
+
+// FileName retorna el nombre del export: meters_<período>_<timestamp>.<ext>
+// El timestamp evita pisar un cierre anterior del mismo período (lecturas manuales)
+func FileName(dir, period string, generatedAt time.Time, ext string) string {
+	return filepath.Join(dir, fmt.Sprintf("meters_%s_%s.%s", period, generatedAt.UTC().Format("20060102T150405Z"), ext))
+}
@@ -0,0 +1,93 @@
+package billing
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFormats(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  string
+	}{
+		{"", "json,csv"},
+		{"both", "json,csv"},
+		{"CSV", "csv"},
+		{" json , papercut ", "json,papercut"},
+		{"both,json,printfleet", "json,csv,printfleet"},
+	} {
+		got, err := ParseFormats(tc.value)
+		if err != nil {
+			t.Errorf("%q: %v", tc.value, err)
+			continue
+		}
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("%q = %v, se esperaba %s", tc.value, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"xml", "json,", "json,pdf"} {
+		if _, err := ParseFormats(bad); err == nil {
+			t.Errorf("%q: se aceptó un formato desconocido", bad)
+		}
+	}
+}
+
+func TestExtension(t *testing.T) {
+	for format, want := range map[string]string{
+		FormatJSON:       "json",
+		FormatCSV:        "csv",
+		FormatPaperCut:   "papercut.csv",
+		FormatPrintFleet: "printfleet.xml",
+	} {
+		if got := Extension(format); got != want {
+			t.Errorf("Extension(%s) = %s, se esperaba %s", format, got, want)
+		}
+	}
+}
+
+func TestCloseDue(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 10, 0, 0, 0, time.UTC) }
+	for _, tc := range []struct {
+		name       string
+		now        time.Time
+		closeDay   int
+		lastPeriod string
+		want       bool
+	}{
+		{"antes del día de cierre", day(4), 5, "2026-01", false},
+		{"el día de cierre", day(5), 5, "2026-01", true},
+		{"después del día de cierre", day(20), 5, "2026-01", true},
+		{"ya cerrado este mes", day(20), 5, "2026-02", false},
+		{"sin cierre previo", day(1), 1, "", true},
+		{"día 0 se toma como 1", day(1), 0, "2026-01", true},
+		// Febrero no tiene día 31: se ajusta a 28
+		{"día 31 se ajusta a 28", day(28), 31, "2026-01", true},
+		{"día 31 antes del 28", day(27), 31, "2026-01", false},
+	} {
+		if got := CloseDue(tc.now, tc.closeDay, tc.lastPeriod); got != tc.want {
+			t.Errorf("%s: CloseDue = %v, se esperaba %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	generatedAt := time.Date(2026, 10, 5, 9, 30, 15, 0, time.FixedZone("CLT", -3*3600))
+	if p := Period(generatedAt); p != "2026-10" {
+		t.Errorf("Period = %s", p)
+	}
+
+	got := FileName("exports", "2026-10", generatedAt, Extension(FormatPaperCut))
+	want := filepath.Join("exports", "meters_2026-10_20261005T123015Z.papercut.csv")
+	if got != want {
+		t.Errorf("FileName = %s, se esperaba %s", got, want)
+	}
+
+	// Dos cierres del mismo período no se pisan
+	later := FileName("exports", "2026-10", generatedAt.Add(time.Second), "csv")
+	if later == FileName("exports", "2026-10", generatedAt, "csv") {
+		t.Error("dos exports del mismo período con el mismo nombre")
+	}
+}
@@ -0,0 +1,87 @@
+package billing
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// printFleetAudit es la raíz del XML estilo PrintFleet/FMAudit: un <Audit>
+// con un <Device> por impresora y sus medidores como <Meter Type="...">
+type printFleetAudit struct {
+	XMLName   xml.Name           `xml:"Audit"`
+	Version   string             `xml:"Version,attr"`
+	AuditDate string             `xml:"AuditDate,attr"`
+	Period    string             `xml:"Period,attr"`
+	Collector string             `xml:"Collector,attr"`
+	Devices   []printFleetDevice `xml:"Devices>Device"`
+}
+
+type printFleetDevice struct {
+	SerialNumber string            `xml:"SerialNumber"`
+	Manufacturer string            `xml:"Manufacturer"`
+	Model        string            `xml:"Model"`
+	IPAddress    string            `xml:"IPAddress"`
+	MACAddress   string            `xml:"MACAddress"`
+	HostName     string            `xml:"HostName,omitempty"`
+	Location     string            `xml:"Location,omitempty"`
+	AssetID      string            `xml:"AssetID"`
+	ScanDate     string            `xml:"ScanDate"`
+	Meters       []printFleetMeter `xml:"Meters>Meter"`
+}
+
+type printFleetMeter struct {
+	Type  string `xml:"Type,attr"`
+	Value int64  `xml:",chardata"`
+}
+
+// PrintFleetXML convierte el reporte al XML de auditoría que importan
+// PrintFleet/FMAudit. Los medidores en 0 que el equipo no reporta se omiten,
+// salvo LifeCount que siempre va
+func PrintFleetXML(report *telemetry.MeterReport) ([]byte, error) {
+	audit := printFleetAudit{
+		Version:   "1.0",
+		AuditDate: report.GeneratedAt.UTC().Format(time.RFC3339),
+		Period:    report.Period,
+		Collector: report.Source.AgentID,
+		Devices:   make([]printFleetDevice, 0, len(report.Reads)),
+	}
+
+	for _, read := range report.Reads {
+		c := read.Counters
+		meters := []printFleetMeter{{Type: "LifeCount", Value: c.TotalPages}}
+		for _, m := range []printFleetMeter{
+			{Type: "LifeCountMono", Value: c.MonoPages},
+			{Type: "LifeCountColor", Value: c.ColorPages},
+			{Type: "CopyCount", Value: c.CopyPages},
+			{Type: "FaxCount", Value: c.FaxPages},
+			{Type: "ScanCount", Value: c.ScanPages},
+			{Type: "DuplexCount", Value: c.DuplexPages},
+			{Type: "LargeCount", Value: c.A3Pages},
+		} {
+			if m.Value > 0 {
+				meters = append(meters, m)
+			}
+		}
+
+		audit.Devices = append(audit.Devices, printFleetDevice{
+			SerialNumber: read.SerialNumber,
+			Manufacturer: read.Brand,
+			Model:        read.Model,
+			IPAddress:    read.IP,
+			MACAddress:   read.MacAddress,
+			HostName:     read.Hostname,
+			Location:     read.Location,
+			AssetID:      read.PrinterID,
+			ScanDate:     read.ReadAt.UTC().Format(time.RFC3339),
+			Meters:       meters,
+		})
+	}
+
+	data, err := xml.MarshalIndent(audit, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
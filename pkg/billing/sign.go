@@ -0,0 +1,110 @@
+package billing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// SignatureSuffix es la extensión del archivo de firma junto a cada export
+const SignatureSuffix = ".sig"
+
+// Algoritmos de firma
+const (
+	AlgHMACSHA256 = "hmac-sha256" // firmado con la llave de facturación
+	AlgSHA256     = "sha256"      // sin llave: solo integridad, no autenticidad
+)
+
+// ErrBadSignature indica que el archivo no coincide con su firma
+var ErrBadSignature = errors.New("la firma no coincide con el archivo")
+
+// Signature es el contenido de <archivo>.sig
+type Signature struct {
+	Algorithm string    `json:"algorithm"`
+	File      string    `json:"file"`   // nombre del archivo firmado (sin directorio)
+	Digest    string    `json:"digest"` // hex
+	SignedAt  time.Time `json:"signed_at"`
+}
+
+// Sign calcula la firma de data; con key vacía solo se calcula el SHA-256
+func Sign(data, key []byte) (algorithm, digest string) {
+	if len(key) == 0 {
+		sum := sha256.Sum256(data)
+		return AlgSHA256, hex.EncodeToString(sum[:])
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return AlgHMACSHA256, hex.EncodeToString(mac.Sum(nil))
+}
+
+// WriteSigned escribe data en path de forma atómica y su firma en path+".sig"
+func WriteSigned(path string, data, key []byte) (*Signature, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0644); err != nil {
+		return nil, err
+	}
+
+	algorithm, digest := Sign(data, key)
+	sig := &Signature{
+		Algorithm: algorithm,
+		File:      filepath.Base(path),
+		Digest:    digest,
+		SignedAt:  time.Now().UTC(),
+	}
+
+	sigBytes, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := fsutil.WriteFileAtomic(path+SignatureSuffix, sigBytes, 0644); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// Verify comprueba path contra path+".sig"
+// Un archivo firmado con HMAC requiere la misma llave para verificarse
+func Verify(path string, key []byte) (*Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sigBytes, err := os.ReadFile(path + SignatureSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(sigBytes, &sig); err != nil {
+		return nil, fmt.Errorf("firma ilegible: %w", err)
+	}
+
+	switch sig.Algorithm {
+	case AlgHMACSHA256:
+		if len(key) == 0 {
+			return &sig, fmt.Errorf("el archivo está firmado con %s y no se configuró la llave", sig.Algorithm)
+		}
+	case AlgSHA256:
+		key = nil
+	default:
+		return &sig, fmt.Errorf("algoritmo de firma desconocido: %s", sig.Algorithm)
+	}
+
+	_, digest := Sign(data, key)
+	if !hmac.Equal([]byte(digest), []byte(sig.Digest)) {
+		return &sig, ErrBadSignature
+	}
+	return &sig, nil
+}
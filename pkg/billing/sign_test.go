@@ -0,0 +1,95 @@
+package billing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// SHA-256 de "abc" (FIPS 180-2)
+	alg, digest := Sign([]byte("abc"), nil)
+	if alg != AlgSHA256 || digest != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("sin llave: %s %s", alg, digest)
+	}
+
+	// HMAC-SHA256, RFC 4231 caso 2
+	alg, digest = Sign([]byte("what do ya want for nothing?"), []byte("Jefe"))
+	if alg != AlgHMACSHA256 || digest != "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843" {
+		t.Errorf("con llave: %s %s", alg, digest)
+	}
+}
+
+func TestWriteSignedAndVerify(t *testing.T) {
+	key := []byte("llave-de-facturacion")
+	path := filepath.Join(t.TempDir(), "meters", "meters_2026-10.csv")
+	data := []byte("period,serial_number\n2026-10,CN123\n")
+
+	sig, err := WriteSigned(path, data, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Algorithm != AlgHMACSHA256 || sig.File != "meters_2026-10.csv" {
+		t.Errorf("firma %+v", sig)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(data) {
+		t.Fatalf("archivo %q, %v", got, err)
+	}
+
+	if _, err := Verify(path, key); err != nil {
+		t.Errorf("Verify con la llave correcta: %v", err)
+	}
+	if _, err := Verify(path, []byte("otra")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify con otra llave = %v, se esperaba ErrBadSignature", err)
+	}
+	if _, err := Verify(path, nil); err == nil || errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify sin llave = %v, se esperaba error de llave faltante", err)
+	}
+
+	// Un archivo modificado después de firmar no verifica
+	if err := os.WriteFile(path, []byte("period,serial_number\n2026-10,CN999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path, key); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify del archivo alterado = %v, se esperaba ErrBadSignature", err)
+	}
+}
+
+// Sin llave la firma es solo SHA-256 y se verifica con cualquier llave
+func TestVerifyUnsigned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meters_2026-10.json")
+	if _, err := WriteSigned(path, []byte(`{"reads":[]}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range [][]byte{nil, []byte("cualquiera")} {
+		sig, err := Verify(path, key)
+		if err != nil || sig.Algorithm != AlgSHA256 {
+			t.Errorf("Verify(%q) = %+v, %v", key, sig, err)
+		}
+	}
+}
+
+func TestVerifyBadSignatureFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "meters.csv")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path, nil); !os.IsNotExist(err) {
+		t.Errorf("sin .sig: %v", err)
+	}
+
+	for _, tc := range []struct{ name, sig, want string }{
+		{"ilegible", `{"algorithm":`, "ilegible"},
+		{"algoritmo desconocido", `{"algorithm":"md5","digest":"00"}`, "desconocido"},
+	} {
+		if err := os.WriteFile(path+SignatureSuffix, []byte(tc.sig), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Verify(path, nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v", tc.name, err)
+		}
+	}
+}
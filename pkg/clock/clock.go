@@ -0,0 +1,41 @@
+// Package clock corrige la hora del servidor del agente: mide el desfase
+// contra un servidor NTP al arrancar y da timestamps corregidos que avanzan
+// con el reloj monotónico (un ajuste del reloj a mitad del ciclo no los mueve)
+package clock
+
+import "time"
+
+// Clock es la hora corregida del agente. Un *Clock nil es el reloj local
+type Clock struct {
+	skew   time.Duration // reloj local - hora de referencia (positivo = adelantado)
+	anchor time.Time     // instante de la medición (con lectura monotónica)
+	ref    time.Time     // hora de referencia en anchor
+}
+
+// New crea un reloj con el desfase medido (skew > 0 = el reloj local adelanta)
+func New(skew time.Duration) *Clock {
+	anchor := time.Now()
+	return &Clock{skew: skew, anchor: anchor, ref: anchor.Add(-skew).Round(0)}
+}
+
+// Now retorna la hora corregida: la de referencia más lo transcurrido según
+// el reloj monotónico desde la medición
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.ref.Add(time.Since(c.anchor))
+}
+
+// Skew retorna el desfase del reloj local (0 para un reloj nil)
+func (c *Clock) Skew() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.skew
+}
+
+// SkewMs retorna el desfase en milisegundos (clock_skew_ms)
+func (c *Clock) SkewMs() int64 {
+	return c.Skew().Milliseconds()
+}
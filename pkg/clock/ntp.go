@@ -0,0 +1,85 @@
+package clock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ntpEpochOffset son los segundos entre 1900-01-01 (época NTP) y 1970-01-01
+const ntpEpochOffset = 2208988800
+
+// QuerySkew mide el desfase del reloj local contra server (host o host:puerto)
+// con una consulta SNTP (RFC 4330). Positivo = el reloj local adelanta
+func QuerySkew(server string, timeout time.Duration) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	// LI=0, VN=4, Mode=3 (cliente); transmit timestamp = hora local de envío
+	request := make([]byte, 48)
+	request[0] = 0x23
+	sent := time.Now()
+	putTimestamp(request[40:], sent)
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, err
+	}
+	received := sent.Add(time.Since(sent)) // monotónico: inmune a ajustes durante la consulta
+	if n < 48 {
+		return 0, fmt.Errorf("respuesta NTP corta (%d bytes)", n)
+	}
+	if mode := response[0] & 0x07; mode != 4 {
+		return 0, fmt.Errorf("respuesta NTP con modo %d", mode)
+	}
+	// El originate timestamp repite nuestro transmit: descarta respuestas
+	// viejas, duplicadas o falsificadas
+	if !bytes.Equal(response[24:32], request[40:48]) {
+		return 0, errors.New("respuesta NTP que no corresponde a la consulta (originate timestamp distinto)")
+	}
+	if li := response[0] >> 6; li == 3 {
+		return 0, errors.New("servidor NTP no sincronizado (leap indicator de alarma)")
+	}
+	if stratum := response[1]; stratum == 0 || stratum > 15 {
+		return 0, errors.New("servidor NTP no sincronizado (kiss-o'-death o stratum inválido)")
+	}
+
+	serverReceive := readTimestamp(response[32:])
+	serverTransmit := readTimestamp(response[40:])
+
+	// offset = ((T2 - T1) + (T3 - T4)) / 2; el desfase local es el opuesto
+	offset := (serverReceive.Sub(sent) + serverTransmit.Sub(received)) / 2
+	return -offset, nil
+}
+
+// putTimestamp escribe t en formato NTP (32 bits de segundos, 32 de fracción)
+func putTimestamp(b []byte, t time.Time) {
+	seconds := uint64(t.Unix()) + ntpEpochOffset
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	binary.BigEndian.PutUint32(b[0:], uint32(seconds))
+	binary.BigEndian.PutUint32(b[4:], uint32(fraction))
+}
+
+// readTimestamp lee un timestamp NTP
+func readTimestamp(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:])) - ntpEpochOffset
+	fraction := uint64(binary.BigEndian.Uint32(b[4:]))
+	nanos := int64(fraction * uint64(time.Second) >> 32)
+	return time.Unix(seconds, nanos)
+}
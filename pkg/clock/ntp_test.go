@@ -0,0 +1,80 @@
+package clock
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serverSkew es el adelanto del servidor NTP de prueba respecto del reloj local
+const serverSkew = 2 * time.Second
+
+// startNTPServer responde cada consulta con una respuesta válida adelantada
+// serverSkew, pasada por edit antes de enviarla
+func startNTPServer(t *testing.T, edit func(response []byte)) string {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		request := make([]byte, 48)
+		for {
+			n, addr, err := conn.ReadFromUDP(request)
+			if err != nil {
+				return
+			}
+			if n < 48 {
+				continue
+			}
+			response := make([]byte, 48)
+			response[0] = 0x24 // LI=0, VN=4, Mode=4 (servidor)
+			response[1] = 2    // stratum
+			copy(response[24:32], request[40:48])
+			now := time.Now().Add(serverSkew)
+			putTimestamp(response[32:], now)
+			putTimestamp(response[40:], now)
+			if edit != nil {
+				edit(response)
+			}
+			conn.WriteToUDP(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQuerySkew(t *testing.T) {
+	skew, err := QuerySkew(startNTPServer(t, nil), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// El servidor adelanta: el reloj local atrasa serverSkew
+	if diff := skew + serverSkew; diff < -100*time.Millisecond || diff > 100*time.Millisecond {
+		t.Errorf("desfase %v, se esperaba cerca de %v", skew, -serverSkew)
+	}
+}
+
+func TestQuerySkewRejectsInvalidReplies(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(response []byte)
+		want string
+	}{
+		{"originate distinto", func(r []byte) { r[31]++ }, "originate"},
+		{"originate vacío", func(r []byte) { clear(r[24:32]) }, "originate"},
+		{"leap indicator de alarma", func(r []byte) { r[0] |= 0xc0 }, "leap indicator"},
+		{"stratum 0", func(r []byte) { r[1] = 0 }, "stratum"},
+		{"stratum 16", func(r []byte) { r[1] = 16 }, "stratum"},
+		{"modo cliente", func(r []byte) { r[0] = r[0]&^0x07 | 3 }, "modo"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := QuerySkew(startNTPServer(t, tc.edit), time.Second)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, se esperaba uno sobre %q", err, tc.want)
+			}
+		})
+	}
+}
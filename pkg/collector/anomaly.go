@@ -0,0 +1,124 @@
+package collector
+
+// Detección de lecturas anómalas de contadores
+//
+// Antes se descartaban en silencio valores de una lista fija (observados en
+// un Samsung). Ahora se distinguen dos niveles:
+//   - sin historial: solo los rellenos de bits en uno (2^n-1, INT_MAX, UINT32_MAX)
+//     que los firmwares devuelven para campos no implementados
+//   - con la lectura anterior de state/: saltos de ×1000 y mesetas en potencias de 2
+//
+// Las anomalías se informan en la telemetría (counters.anomalies) en vez de
+// desaparecer: el backend decide si descarta o corrige la lectura
+
+// Motivos de una anomalía
+const (
+	AnomalySentinel = "sentinel" // relleno 2^n-1 (INT_MAX, UINT32_MAX...)
+	AnomalyJump     = "jump"     // creció ×1000 o más desde la lectura anterior
+	AnomalyPlateau  = "plateau"  // clavado en la misma potencia de 2 que la lectura anterior
+)
+
+// Umbrales de la detección
+const (
+	sentinelMinValue = int64(1) << 20 // por debajo, 2^n-1 es un contador plausible
+	plateauMinValue  = int64(1) << 16
+	jumpFactor       = 1000
+	jumpMinPrevious  = 100 // un equipo casi nuevo puede multiplicar su contador
+)
+
+// CounterAnomaly es una lectura de contador que no parece real
+type CounterAnomaly struct {
+	Counter  string `json:"counter"`            // total_pages, mono_pages... (u OID si no llegó a mapearse)
+	Value    int64  `json:"value"`              // valor leído
+	Previous int64  `json:"previous,omitempty"` // lectura anterior en state/ (0 = sin historial)
+	Reason   string `json:"reason"`             // sentinel | jump | plateau
+}
+
+// isSentinelValue indica si val es un relleno de bits en uno (2^n-1)
+// Cubre INT32_MAX, UINT32_MAX, INT64_MAX y los 2^21-1...2^25-1 de firmwares viejos
+func isSentinelValue(val int64) bool {
+	return val >= sentinelMinValue-1 && (val+1)&val == 0
+}
+
+// isPowerOfTwo indica si val es una potencia de 2
+func isPowerOfTwo(val int64) bool {
+	return val > 0 && val&(val-1) == 0
+}
+
+// flagAnomaly registra una anomalía en data (una por contador y motivo)
+func (data *PrinterData) flagAnomaly(anomaly CounterAnomaly) {
+	for _, a := range data.CounterAnomalies {
+		if a.Counter == anomaly.Counter && a.Reason == anomaly.Reason {
+			return
+		}
+	}
+	data.CounterAnomalies = append(data.CounterAnomalies, anomaly)
+}
+
+// HasJumpAnomaly indica si algún contador saltó respecto de la lectura anterior
+// Con un salto no conviene pisar state/: la próxima lectura sana daría un falso reset
+func (data *PrinterData) HasJumpAnomaly() bool {
+	for _, a := range data.CounterAnomalies {
+		if a.Reason == AnomalyJump {
+			return true
+		}
+	}
+	return false
+}
+
+// namedCounters retorna los contadores con su nombre JSON
+func namedCounters(c CountersInfo) map[string]int64 {
+	return map[string]int64{
+		"total_pages":  c.TotalPages,
+		"mono_pages":   c.MonoPages,
+		"color_pages":  c.ColorPages,
+		"scan_pages":   c.ScanPages,
+		"copy_pages":   c.CopyPages,
+		"fax_pages":    c.FaxPages,
+		"duplex_pages": c.DuplexPages,
+		"a3_pages":     c.A3Pages,
+	}
+}
+
+// DetectCounterAnomalies compara los contadores actuales con la lectura anterior
+// Sin estado previo solo se detectan centinelas
+func DetectCounterAnomalies(current CountersInfo, previous *PrinterState) []CounterAnomaly {
+	var prev map[string]int64
+	if previous != nil {
+		prev = namedCounters(previous.Counters)
+	}
+
+	values := namedCounters(current)
+	var anomalies []CounterAnomaly
+	for _, name := range counterOrder {
+		value := values[name]
+		if value <= 0 {
+			continue
+		}
+		before := prev[name]
+
+		switch {
+		case isSentinelValue(value):
+			anomalies = append(anomalies, CounterAnomaly{Counter: name, Value: value, Previous: before, Reason: AnomalySentinel})
+		case before >= jumpMinPrevious && value/before >= jumpFactor:
+			anomalies = append(anomalies, CounterAnomaly{Counter: name, Value: value, Previous: before, Reason: AnomalyJump})
+		case value >= plateauMinValue && value == before && isPowerOfTwo(value):
+			anomalies = append(anomalies, CounterAnomaly{Counter: name, Value: value, Previous: before, Reason: AnomalyPlateau})
+		}
+	}
+	return anomalies
+}
+
+// counterOrder fija el orden de las anomalías en el JSON
+var counterOrder = []string{"total_pages", "mono_pages", "color_pages", "scan_pages", "copy_pages", "fax_pages", "duplex_pages", "a3_pages"}
+
+// checkCounterHistory agrega a data las anomalías respecto del estado guardado
+func (dc *DataCollector) checkCounterHistory(data *PrinterData) {
+	var previous *PrinterState
+	if dc.config.History != nil && data.PrinterID != "" {
+		previous, _ = dc.config.History.LoadState(data.PrinterID)
+	}
+	for _, anomaly := range DetectCounterAnomalies(data.PageCounters, previous) {
+		data.flagAnomaly(anomaly)
+	}
+}
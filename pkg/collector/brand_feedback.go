@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// applyProfileBrand usa la marca corregida en polls anteriores
+// El sysDescr se vuelve a adivinar en cada ciclo; si el perfil ya registró que
+// los OIDs del fabricante lo contradicen, manda la marca del perfil
+func applyProfileBrand(data *PrinterData, prof *profile.Profile) {
+	if prof == nil || prof.BrandFeedback.CorrectedFrom == "" || prof.Brand == "" {
+		return
+	}
+	data.Brand = prof.Brand
+	if prof.BrandFeedback.Confidence > 0 {
+		data.Confidence = prof.BrandFeedback.Confidence
+	}
+}
+
+// vendorEvidence retorna la marca que indican sysObjectID o, si no es
+// concluyente, los árboles privados que respondieron en el discovery del perfil
+func vendorEvidence(data *PrinterData, prof *profile.Profile) string {
+	sysObjectID, _ := data.Identification["sysObjectID"].(string)
+	if brand := detector.BrandFromOID(sysObjectID); brand != "" {
+		return brand
+	}
+
+	if prof == nil {
+		return ""
+	}
+
+	// Marca con más OIDs propietarios descubiertos
+	votes := make(map[string]int)
+	best := ""
+	for _, oid := range prof.OIDs[string(profile.CatVendor)] {
+		brand := detector.BrandFromOID(oid)
+		if brand == "" {
+			continue
+		}
+		votes[brand]++
+		if votes[brand] > votes[best] {
+			best = brand
+		}
+	}
+	return best
+}
+
+// probeVendorOIDs consulta los OIDs propietarios de la marca actual
+// Retorna (consultado, respondió); consultado=false si la marca no tiene OIDs conocidos
+func probeVendorOIDs(data *PrinterData, client *snmp.SNMPClient) (bool, bool) {
+	oids := vendorCounterOIDs(data.Brand)
+	if len(oids) == 0 {
+		return false, false
+	}
+
+	results, err := client.GetMultiple(oids, snmp.NewContext())
+	if err != nil {
+		return true, false
+	}
+	for _, val := range results {
+		if val != nil {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// calibrateBrand contrasta la marca adivinada por sysDescr con lo que
+// efectivamente respondió el dispositivo y ajusta la confianza en el perfil
+// Si el árbol privado es de otra marca, se corrige la marca y se reintentan
+// los contadores propietarios de la marca correcta
+func (dc *DataCollector) calibrateBrand(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, prof *profile.Profile) {
+	sysDescr, _ := data.Identification["sysDescr"].(string)
+
+	hit := false
+	evidence := vendorEvidence(data, prof)
+	switch {
+	case evidence != "" && evidence != data.Brand:
+		fmt.Println(i18n.T("log.brand_corrected", data.IP, data.Brand, evidence))
+		data.Brand = evidence
+		hit = true
+
+		if data.NormalizedCounters["total_pages"] == nil {
+			collectCountersVendorSpecific(ctx, data, client, nil)
+		}
+		collectVendorMeters(data, client)
+	case evidence != "":
+		hit = true
+	default:
+		probed, answered := probeVendorOIDs(data, client)
+		if !probed {
+			return // Sin evidencia: queda la confianza estática
+		}
+		hit = answered
+	}
+
+	base := detector.GetBrandConfidence(sysDescr, data.Brand)
+	if dc.profileManager == nil || prof == nil {
+		data.Confidence = base
+		return
+	}
+
+	fb, err := dc.profileManager.RecordBrandFeedback(prof.PrinterID, data.Brand, hit, base)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("Calibración de marca: %v", err))
+		return
+	}
+	data.Confidence = fb.Confidence
+}
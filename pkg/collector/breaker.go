@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// breakerMinSuccess es la proporción de OIDs respondidos por debajo de la cual
+// un poll que venció el deadline cuenta como fallido
+const breakerMinSuccess = 0.5
+
+// openBreaker retorna el perfil del dispositivo si su circuit breaker está
+// abierto (nil si se recolecta normalmente)
+// Una consulta interactiva lo ignora: quien la pide espera una lectura completa
+func (dc *DataCollector) openBreaker(devInfo DeviceInfo) *profile.Profile {
+	if dc.profileManager == nil || dc.config.BreakerFailures <= 0 || dc.config.Interactive {
+		return nil
+	}
+	prof := dc.profileManager.Lookup("", devInfo.IP)
+	if prof == nil || !prof.Breaker.Open() {
+		return nil
+	}
+	return prof
+}
+
+// probeLiveness reemplaza la recolección de un dispositivo con el breaker
+// abierto: un único GET de estado (sysUpTime, hrDeviceStatus...) que basta
+// para informarlo vivo o caído, y descuenta un ciclo del breaker
+func (dc *DataCollector) probeLiveness(data *PrinterData, client *snmp.SNMPClient, prof *profile.Profile) {
+	data.PrinterID = prof.PrinterID
+	applyProfileBrand(data, prof)
+	dc.collectStatus(data, client.WithPriority(snmp.PriorityStatus))
+
+	breaker, err := dc.profileManager.SkipCollection(prof.PrinterID)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("Circuit breaker: %v", err))
+		return
+	}
+	data.Errors = append(data.Errors, fmt.Sprintf("Circuit breaker abierto: solo sondeo de vida (quedan %d ciclos)", breaker.OpenCycles))
+	data.Partial = true
+	fmt.Println(i18n.T("log.breaker_probe", data.IP, breaker.OpenCycles))
+}
+
+// recordCollection registra en el perfil si el poll completo falló: sin
+// ningún OID respondido, o con el deadline vencido y pocas respuestas
+// Las consultas interactivas no cuentan: el breaker sigue los ciclos
+func (dc *DataCollector) recordCollection(data *PrinterData) {
+	if dc.profileManager == nil || dc.config.BreakerFailures <= 0 || dc.config.Interactive {
+		return
+	}
+	prof := dc.profileManager.Lookup(data.PrinterID, data.IP)
+	if prof == nil {
+		return
+	}
+
+	coverage := data.Coverage
+	failed := coverage == nil || coverage.Answered+coverage.Sentinels == 0 ||
+		(data.Partial && data.Capabilities.SuccessRate < breakerMinSuccess)
+
+	breaker, err := dc.profileManager.RecordCollection(prof.PrinterID, failed, dc.config.BreakerFailures, dc.config.BreakerCycles)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("Circuit breaker: %v", err))
+		return
+	}
+	if breaker.Open() && breaker.OpenCycles == dc.config.BreakerCycles {
+		fmt.Println(i18n.T("log.breaker_open", data.IP, dc.config.BreakerFailures, breaker.OpenCycles))
+	}
+}
@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// capabilityTables son las tablas de Printer MIB que delatan capacidades y
+// que el poll ya recorrió (se sirven del cache de WALKs)
+var capabilityTables = []string{
+	"1.3.6.1.2.1.43.10.2.1.6", // prtMarkerProcessColorants
+	"1.3.6.1.2.1.43.12.1.1.4", // prtMarkerColorantValue
+	"1.3.6.1.2.1.43.13.4.1",   // prtMediaPathTable
+}
+
+// capabilityCounters son los contadores que, con lectura, prueban una capacidad
+var capabilityCounters = []string{"scan_pages", "fax_pages", "color_pages", "duplex_pages"}
+
+// detectCapabilities fija data.Capabilities con lo que respondió el poll
+// Del perfil solo se heredan escáner y fax (sus cubiertas, canales e
+// intérpretes no se recorren en cada poll); color y dúplex de perfiles
+// antiguos venían fijos en true y se detectan siempre de nuevo
+func detectCapabilities(data *PrinterData, client *snmp.SNMPClient, walks *WalkCache, ctx *snmp.Context, prof *profile.Profile) {
+	var caps profile.CapabilityMap
+	if prof != nil {
+		caps.Scanner = prof.Capabilities.Scanner
+		caps.Fax = prof.Capabilities.Fax
+	}
+
+	var results []snmp.WalkResult
+	for _, table := range capabilityTables {
+		if rows, err := walks.Walk(table, ctx); err == nil {
+			results = append(results, rows...)
+		}
+	}
+	var counters []string
+	for _, name := range capabilityCounters {
+		if toInt64(data.NormalizedCounters[name]) > 0 {
+			counters = append(counters, name)
+		}
+	}
+	profile.DetectCapabilities(&caps, results, counters)
+
+	data.Capabilities = Capabilities{
+		SNMPVersion: client.Version(),
+		Duplex:      caps.Duplex,
+		Color:       caps.Color,
+		Scanner:     caps.Scanner,
+		Fax:         caps.Fax,
+	}
+}
@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"strings"
+)
+
+// applyCatalog identifica el modelo en la base de modelos (Config.Models) y
+// completa el rendimiento nominal de los consumibles que no son residuos
+// Se descarta la coincidencia si su confianza no alcanza ModelMinConfidence
+// o si la marca del catálogo no es la del equipo
+func (dc *DataCollector) applyCatalog(data *PrinterData) {
+	if dc.config.Models == nil {
+		return
+	}
+	match, ok := dc.config.Models.Lookup(data.Info.SysObjectID, profileModel(data), data.Info.Model, data.Info.SysDescr)
+	if !ok || match.Confidence < dc.config.ModelMinConfidence {
+		return
+	}
+	if brand := match.Model.Brand; brand != "" && data.Brand != "" && data.Brand != "Generic" && !strings.EqualFold(brand, data.Brand) {
+		return
+	}
+	data.Catalog = &match
+
+	for i := range data.SupplyList {
+		s := &data.SupplyList[i]
+		if s.FillsUp {
+			continue
+		}
+		s.RatedYield = match.Model.Yield(s.Description, s.Color)
+	}
+}
@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Confianza por defecto de cada origen (la clasificación calcula la suya)
+var sourceConfidence = map[string]float64{
+	CounterSourceProfile:   0.9,
+	CounterSourceMarker:    0.95,
+	CounterSourceVendor:    0.95,
+	CounterSourceHeuristic: 0.4,
+	CounterSourcePageCount: 0.7,
+	CounterSourcePJL:       0.9,
+	CounterSourceEWS:       0.95,
+}
+
+// classifyPollInterval es la pausa entre los dos polls rápidos de la clasificación
+const classifyPollInterval = 300 * time.Millisecond
+
+// counterCandidate es un OID de contador sin nombre explícito en el perfil
+type counterCandidate struct {
+	oid    string
+	value  int64
+	hint   string // nombre documentado por el fabricante ("" = sin mapeo)
+	rank   int    // consistencia del discovery (ver consistencyRank)
+	delta  int64  // cambio entre los dos polls rápidos
+	reread bool   // false si el segundo poll no lo devolvió
+}
+
+// Modo de color deducido de prtMarkerProcessColorants
+const (
+	colorModeUnknown = iota
+	colorModeMono
+	colorModeColor
+)
+
+// classifyCounters asigna nombres a contadores sin mapeo explícito con una
+// confianza registrada, en lugar de suponer "el mayor es el total, el segundo
+// el color". Usa:
+//   - dos polls rápidos: un contador que baja no es un contador de páginas y
+//     los deltas que suman el delta del total refuerzan mono/color
+//   - prtMarkerProcessColorants: un equipo monocromo nunca tiene color_pages
+//   - los nombres documentados del fabricante (hint): mono/color/scan/copy/fax
+//     solo salen de ahí; sin hint solo se deduce total_pages
+//
+// Los contadores ya asignados por perfil, marcadores o medidores no se pisan
+func classifyCounters(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, candidates []counterCandidate, markers []profile.MarkerRow) {
+	if len(candidates) == 0 || !anyClassifiable(data) {
+		return
+	}
+	repollCandidates(ctx, client, candidates)
+
+	var stable []counterCandidate
+	for _, c := range candidates {
+		if c.reread && c.delta < 0 {
+			continue // bajó entre dos lecturas: no es acumulativo
+		}
+		stable = append(stable, c)
+	}
+	if len(stable) == 0 {
+		return
+	}
+
+	// total_pages: el documentado por el fabricante si es el mayor, si no el
+	// mayor entre los más consistentes del discovery
+	totalDelta := int64(-1)
+	if canClassify(data, "total_pages") {
+		sort.SliceStable(stable, func(i, j int) bool {
+			if stable[i].rank != stable[j].rank {
+				return stable[i].rank > stable[j].rank
+			}
+			return stable[i].value > stable[j].value
+		})
+		best := 0
+		for i, c := range stable {
+			if c.hint == "total_pages" && c.value >= maxCandidate(stable) {
+				best = i
+				break
+			}
+		}
+		total := stable[best]
+		stable = append(stable[:best:best], stable[best+1:]...)
+
+		confidence := 0.5
+		if total.hint == "total_pages" {
+			confidence = 0.8
+		}
+		if sumsTo(stable, total.value) {
+			confidence += 0.1 // dos contadores que suman el total lo confirman
+		}
+		setCounterConfidence(data, "total_pages", total.value, CounterSourceHeuristic, confidence)
+		if total.reread {
+			totalDelta = total.delta
+		}
+	}
+	total := toInt64(data.NormalizedCounters["total_pages"])
+
+	hinted := make(map[string]counterCandidate)
+	for _, c := range stable {
+		if c.hint != "" && c.hint != "total_pages" {
+			if _, dup := hinted[c.hint]; !dup {
+				hinted[c.hint] = c
+			}
+		}
+	}
+
+	switch markerColorMode(markers) {
+	case colorModeMono:
+		// Equipo monocromo: todo lo impreso es B/N y un "color" del fabricante no aplica
+		if canClassify(data, "mono_pages") && total > 0 {
+			setCounterConfidence(data, "mono_pages", total, CounterSourceMarker, 0.9)
+		}
+	default:
+		mono, hasMono := hinted["mono_pages"]
+		color, hasColor := hinted["color_pages"]
+		switch {
+		case hasMono && hasColor:
+			confidence := 0.7
+			if total > 0 && near(mono.value+color.value, total) {
+				confidence = 0.9
+			}
+			if totalDelta > 0 && mono.reread && color.reread && mono.delta+color.delta == totalDelta {
+				confidence += 0.05
+			}
+			if mono.value > total || color.value > total {
+				confidence = 0.3 // un parcial mayor que el total: mapeo dudoso
+			}
+			classifyAs(data, "mono_pages", mono.value, confidence)
+			classifyAs(data, "color_pages", color.value, confidence)
+		case hasMono && (total == 0 || mono.value <= total):
+			classifyAs(data, "mono_pages", mono.value, 0.6)
+		case hasColor && (total == 0 || color.value <= total):
+			classifyAs(data, "color_pages", color.value, 0.6)
+		}
+	}
+
+	for _, name := range []string{"scan_pages", "copy_pages", "fax_pages"} {
+		if c, ok := hinted[name]; ok {
+			classifyAs(data, name, c.value, 0.7)
+		}
+	}
+}
+
+// classifyAs asigna un contador clasificado si no lo asignó otra fuente
+func classifyAs(data *PrinterData, name string, value int64, confidence float64) {
+	if canClassify(data, name) {
+		setCounterConfidence(data, name, value, CounterSourceHeuristic, confidence)
+	}
+}
+
+// canClassify indica si el contador está libre (o solo lo asignó la heurística)
+func canClassify(data *PrinterData, name string) bool {
+	_, ok := data.NormalizedCounters[name]
+	return !ok || data.CounterSources[name] == CounterSourceHeuristic
+}
+
+// classifiedCounters son los contadores que puede asignar la clasificación
+var classifiedCounters = []string{"total_pages", "mono_pages", "color_pages", "scan_pages", "copy_pages", "fax_pages"}
+
+// anyClassifiable indica si queda algún contador libre para la clasificación:
+// si otras fuentes los asignaron todos, el segundo poll no haría falta
+func anyClassifiable(data *PrinterData) bool {
+	for _, name := range classifiedCounters {
+		if canClassify(data, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// repollCandidates relee los candidatos tras una pausa corta y guarda el delta
+// La pausa se corta si se cancela ctx: los candidatos quedan sin releer
+func repollCandidates(ctx context.Context, client *snmp.SNMPClient, candidates []counterCandidate) {
+	if client == nil || len(candidates) == 0 {
+		return
+	}
+	oids := make([]string, len(candidates))
+	for i, c := range candidates {
+		oids[i] = c.oid
+	}
+
+	timer := time.NewTimer(classifyPollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	results, err := client.GetMultiple(oids, snmp.NewContext())
+	if err != nil {
+		return
+	}
+	for i := range candidates {
+		val, ok := results[candidates[i].oid].(snmp.Value)
+		if !ok {
+			continue
+		}
+		value, ok := val.Int64()
+		if !ok {
+			continue
+		}
+		candidates[i].delta = value - candidates[i].value
+		candidates[i].reread = true
+	}
+}
+
+// markerColorMode deduce si el equipo es monocromo o color por sus marcadores
+func markerColorMode(markers []profile.MarkerRow) int {
+	mode := colorModeUnknown
+	for _, row := range markers {
+		switch {
+		case row.Colorants > 1:
+			return colorModeColor
+		case row.Colorants == 1:
+			mode = colorModeMono
+		}
+	}
+	return mode
+}
+
+// maxCandidate es el mayor valor entre los candidatos
+func maxCandidate(candidates []counterCandidate) int64 {
+	var max int64
+	for _, c := range candidates {
+		if c.value > max {
+			max = c.value
+		}
+	}
+	return max
+}
+
+// sumsTo indica si dos candidatos suman total (±1%)
+func sumsTo(candidates []counterCandidate, total int64) bool {
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if near(candidates[i].value+candidates[j].value, total) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// near compara dos contadores con una tolerancia del 1% (mínimo 2 páginas:
+// los contadores no se leen en el mismo instante)
+func near(a, b int64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	tolerance := b / 100
+	if tolerance < 2 {
+		tolerance = 2
+	}
+	return diff <= tolerance
+}
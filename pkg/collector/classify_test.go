@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// prtMarkerLifeCount del primer marcador (Printer-MIB)
+const prtMarkerLifeCount = "1.3.6.1.2.1.43.10.2.1.4.1.1"
+
+// startCounterFixture levanta hp_laserjet_m402 y retorna un cliente y su prtMarkerLifeCount
+func startCounterFixture(t *testing.T) (*snmp.SNMPClient, int64) {
+	t.Helper()
+	device := startFixture(t, "hp_laserjet_m402")
+	fixture, err := simulator.Builtin("hp_laserjet_m402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := strconv.ParseInt(fixtureValue(fixture, prtMarkerLifeCount), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := snmp.NewEngine(snmp.EngineConfig{MaxWorkers: 1})
+	return engine.NewClient(device.IP, device.Port, "public", "2c", 2*time.Second, 1), count
+}
+
+func TestRepollCandidates(t *testing.T) {
+	client, count := startCounterFixture(t)
+
+	candidates := []counterCandidate{
+		{oid: prtMarkerLifeCount, value: count - 5},
+		{oid: "1.3.6.1.4.1.99999.1.0", value: 10}, // el agente no lo tiene
+	}
+	repollCandidates(context.Background(), client, candidates)
+
+	if !candidates[0].reread || candidates[0].delta != 5 {
+		t.Errorf("contador: reread=%v delta=%d, se esperaba delta 5", candidates[0].reread, candidates[0].delta)
+	}
+	if candidates[1].reread {
+		t.Error("un OID inexistente quedó como releído")
+	}
+}
+
+// TestRepollCandidatesCanceled: la pausa entre polls respeta ctx
+func TestRepollCandidatesCanceled(t *testing.T) {
+	client, count := startCounterFixture(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	candidates := []counterCandidate{{oid: prtMarkerLifeCount, value: count}}
+
+	start := time.Now()
+	repollCandidates(ctx, client, candidates)
+	if elapsed := time.Since(start); elapsed >= classifyPollInterval {
+		t.Errorf("con ctx cancelado tardó %v", elapsed)
+	}
+	if candidates[0].reread {
+		t.Error("con ctx cancelado se releyó el candidato")
+	}
+}
+
+// TestClassifySkipsRepollWhenAssigned: sin contadores libres no hay segundo poll
+func TestClassifySkipsRepollWhenAssigned(t *testing.T) {
+	client, count := startCounterFixture(t)
+
+	data := &PrinterData{NormalizedCounters: make(map[string]interface{})}
+	for _, name := range classifiedCounters {
+		setCounter(data, name, count, CounterSourceProfile)
+	}
+
+	start := time.Now()
+	classifyCounters(context.Background(), data, client, []counterCandidate{{oid: prtMarkerLifeCount, value: count}}, nil)
+	if elapsed := time.Since(start); elapsed >= classifyPollInterval {
+		t.Errorf("releyó los candidatos con todos los contadores asignados (%v)", elapsed)
+	}
+	for _, name := range classifiedCounters {
+		if data.CounterSources[name] != CounterSourceProfile {
+			t.Errorf("%s: origen %q, se esperaba %q", name, data.CounterSources[name], CounterSourceProfile)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Columnas de Printer MIB para el color de los consumibles
+const (
+	oidSuppliesColorantIndex = "1.3.6.1.2.1.43.11.1.1.3" // prtMarkerSuppliesColorantIndex
+	oidSuppliesType          = "1.3.6.1.2.1.43.11.1.1.5" // prtMarkerSuppliesType
+	oidColorantValue         = "1.3.6.1.2.1.43.12.1.1.4" // prtMarkerColorantValue
+)
+
+// supplyColorNames son los colorantes de proceso con clave normalizada propia
+// (tonerBlack, drumCyan...); el resto se informa en "color" sin cambiar la clave
+var supplyColorNames = map[string]string{
+	"black":   "Black",
+	"cyan":    "Cyan",
+	"magenta": "Magenta",
+	"yellow":  "Yellow",
+}
+
+// supplyColorants resuelve el color de cada consumible por índice con
+// prtMarkerSuppliesColorantIndex → prtMarkerColorantValue (RFC 3805)
+// prtMarkerColorantValue es un nombre estándar ("black", "cyan"...) que no
+// depende del idioma del firmware, a diferencia de la descripción
+// Retorna índice de consumible → color en minúsculas ("light_cyan")
+func supplyColorants(walks *WalkCache, ctx *snmp.Context) map[string]string {
+	colors := make(map[string]string)
+
+	indexes, err := walks.Walk(oidSuppliesColorantIndex, ctx)
+	if err != nil || len(indexes) == 0 {
+		return colors
+	}
+	values, err := walks.Walk(oidColorantValue, ctx)
+	if err != nil || len(values) == 0 {
+		return colors
+	}
+
+	// "<dispositivo>.<colorante>" → color
+	colorants := make(map[string]string)
+	for _, result := range values {
+		suffix, ok := strings.CutPrefix(strings.TrimPrefix(result.OID, "."), oidColorantValue+".")
+		if !ok {
+			continue
+		}
+		if color := normalizeColorant(result.Value); color != "" {
+			colorants[suffix] = color
+		}
+	}
+
+	for _, result := range indexes {
+		suffix, ok := strings.CutPrefix(strings.TrimPrefix(result.OID, "."), oidSuppliesColorantIndex+".")
+		if !ok {
+			continue
+		}
+		device, supply, ok := strings.Cut(suffix, ".")
+		if !ok {
+			continue
+		}
+		// 0 = el consumible no tiene colorante (fusor, rodillos, residuos)
+		colorant := strings.TrimSpace(result.Value)
+		if colorant == "" || colorant == "0" {
+			continue
+		}
+		if color, ok := colorants[device+"."+colorant]; ok {
+			colors[supply] = color
+		}
+	}
+	return colors
+}
+
+// supplyTypeCodes retorna índice de consumible → prtMarkerSuppliesType
+func supplyTypeCodes(walks *WalkCache, ctx *snmp.Context) map[string]string {
+	return supplyColumn(walks, ctx, oidSuppliesType)
+}
+
+// normalizeColorant lleva prtMarkerColorantValue a minúsculas con "_"
+// ("Light Cyan" → "light_cyan"); "unknown" y "other" no son colores
+func normalizeColorant(value string) string {
+	color := strings.ToLower(strings.TrimSpace(value))
+	color = strings.Join(strings.Fields(color), "_")
+	switch color {
+	case "", "unknown", "other", "none":
+		return ""
+	}
+	return color
+}
+
+// supplyKeyFromColorant arma la clave normalizada de un tóner o drum a partir
+// del tipo (prtMarkerSuppliesType) y el colorante; "" si no aplica
+func supplyKeyFromColorant(typeCode, color string) string {
+	colorName := supplyColorNames[color]
+	if colorName == "" {
+		return ""
+	}
+	switch strings.TrimSpace(typeCode) {
+	case "3", "5", "6", "21": // toner, ink, inkCartridge, tonerCartridge
+		return "toner" + colorName
+	case "9": // opc
+		return "drum" + colorName
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/mib"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Reporte de cobertura de OIDs
+//
+// Para soporte: qué OIDs se consultaron en un poll y qué respondió el equipo,
+// de modo que una sección faltante ("no hay contadores") se pueda explicar
+// sin pedirle al cliente un WALK completo
+
+// Resultado de un OID en el poll
+const (
+	OIDAnswered = "answered" // respondió con un valor
+	OIDMissing  = "missing"  // noSuchObject/noSuchInstance o WALK vacío
+	OIDFailed   = "failed"   // timeout o error de red/protocolo
+	OIDSentinel = "sentinel" // respondió, pero con un valor centinela (-1/-2/-3, 2^n-1)
+)
+
+// OIDCoverage es el resultado de un OID (GET) o de un subárbol (WALK)
+type OIDCoverage struct {
+	OID    string `json:"oid"`
+	Name   string `json:"name,omitempty"` // nombre simbólico según los MIBs cargados
+	Op     string `json:"op"`             // get | walk
+	Status string `json:"status"`         // answered | missing | failed | sentinel
+	Value  string `json:"value,omitempty"`
+	Rows   int    `json:"rows,omitempty"` // filas retornadas por el WALK
+	Error  string `json:"error,omitempty"`
+}
+
+// CoverageReport resume qué OIDs se intentaron en el poll de un dispositivo
+type CoverageReport struct {
+	PrinterID   string        `json:"printer_id"`
+	IP          string        `json:"ip"`
+	CollectedAt time.Time     `json:"collected_at"`
+	Attempted   int           `json:"attempted"`
+	Answered    int           `json:"answered"`
+	Missing     int           `json:"missing"`
+	Failed      int           `json:"failed"`
+	Sentinels   int           `json:"sentinels"`
+	Sections    []string      `json:"missing_sections,omitempty"` // secciones vacías del poll
+	OIDs        []OIDCoverage `json:"oids"`
+}
+
+// coverageRecorder implementa snmp.Observer para un dispositivo
+// Un OID consultado varias veces conserva el último resultado
+type coverageRecorder struct {
+	mu      sync.Mutex
+	entries map[string]OIDCoverage // clave: op + OID
+}
+
+// newCoverageRecorder crea un recorder vacío
+func newCoverageRecorder() *coverageRecorder {
+	return &coverageRecorder{entries: make(map[string]OIDCoverage)}
+}
+
+// ObserveGet registra el resultado de un GET
+func (cr *coverageRecorder) ObserveGet(_ string, oid string, value snmp.Value, err error) {
+	entry := OIDCoverage{OID: strings.TrimPrefix(oid, "."), Op: "get"}
+	switch {
+	case err != nil:
+		entry.Status = OIDFailed
+		entry.Error = err.Error()
+	case value.IsNull():
+		entry.Status = OIDMissing
+	default:
+		entry.Value = value.String()
+		entry.Status = OIDAnswered
+		if isCoverageSentinel(value) {
+			entry.Status = OIDSentinel
+		}
+	}
+	cr.record(entry)
+}
+
+// ObserveWalk registra el resultado de un WALK y, aparte, cada fila centinela
+func (cr *coverageRecorder) ObserveWalk(_ string, baseOID string, results []snmp.WalkResult, err error) {
+	entry := OIDCoverage{OID: strings.TrimPrefix(baseOID, "."), Op: "walk", Rows: len(results)}
+	switch {
+	case err != nil:
+		entry.Status = OIDFailed
+		entry.Error = err.Error()
+	case len(results) == 0:
+		entry.Status = OIDMissing
+	default:
+		entry.Status = OIDAnswered
+	}
+	cr.record(entry)
+
+	for _, result := range results {
+		if isCoverageSentinel(result.Typed) {
+			cr.record(OIDCoverage{
+				OID:    strings.TrimPrefix(result.OID, "."),
+				Op:     "walk",
+				Status: OIDSentinel,
+				Value:  result.Typed.String(),
+			})
+		}
+	}
+}
+
+// record guarda un resultado
+func (cr *coverageRecorder) record(entry OIDCoverage) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.entries[entry.Op+" "+entry.OID] = entry
+}
+
+// isCoverageSentinel indica si un valor numérico es un centinela conocido
+func isCoverageSentinel(value snmp.Value) bool {
+	n, ok := value.Int64()
+	if !ok || !value.IsNumeric() {
+		return false
+	}
+	return (n < 0 && n >= SupplyLevelSomeRemaining) || isSentinelValue(n)
+}
+
+// report arma el reporte ordenado por OID
+func (cr *coverageRecorder) report(data *PrinterData) *CoverageReport {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	report := &CoverageReport{
+		PrinterID:   data.PrinterID,
+		IP:          data.IP,
+		CollectedAt: data.Timestamp.UTC(),
+		Sections:    data.MissingSections,
+		OIDs:        make([]OIDCoverage, 0, len(cr.entries)),
+	}
+
+	tree := mib.Default()
+	for _, entry := range cr.entries {
+		if name := tree.Name(entry.OID); name != entry.OID {
+			entry.Name = name
+		}
+		report.OIDs = append(report.OIDs, entry)
+
+		report.Attempted++
+		switch entry.Status {
+		case OIDAnswered:
+			report.Answered++
+		case OIDMissing:
+			report.Missing++
+		case OIDFailed:
+			report.Failed++
+		case OIDSentinel:
+			report.Sentinels++
+		}
+	}
+
+	sort.Slice(report.OIDs, func(i, j int) bool {
+		if report.OIDs[i].OID != report.OIDs[j].OID {
+			return oidLess(report.OIDs[i].OID, report.OIDs[j].OID)
+		}
+		return report.OIDs[i].Op < report.OIDs[j].Op
+	})
+	return report
+}
+
+// oidLess ordena OIDs por arco numérico (43.10 < 43.9 sería incorrecto como texto)
+func oidLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] == pb[i] {
+			continue
+		}
+		if len(pa[i]) != len(pb[i]) {
+			return len(pa[i]) < len(pb[i])
+		}
+		return pa[i] < pb[i]
+	}
+	return len(pa) < len(pb)
+}
+
+// supported retorna los subárboles recorridos que respondieron y la proporción
+// de OIDs con respuesta (los centinelas cuentan: el equipo respondió)
+func (r *CoverageReport) supported() ([]string, float64) {
+	if r == nil || r.Attempted == 0 {
+		return nil, 0
+	}
+	var subtrees []string
+	for _, entry := range r.OIDs {
+		if entry.Op == "walk" && entry.Status == OIDAnswered {
+			subtrees = append(subtrees, entry.OID)
+		}
+	}
+	return subtrees, float64(r.Answered+r.Sentinels) / float64(r.Attempted)
+}
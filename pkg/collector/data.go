@@ -2,19 +2,30 @@ package collector
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/asaavedra/agent-snmp/pkg/clock"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/modeldb"
 	"github.com/asaavedra/agent-snmp/pkg/profile"
 	"github.com/asaavedra/agent-snmp/pkg/snmp"
+	"github.com/gosnmp/gosnmp"
 )
 
 // PrinterData contiene la información recolectada de una impresora
 type PrinterData struct {
+	PrinterID          string                 `json:"printerId"` // ID canónico (MAC → serial → IP)
 	IP                 string                 `json:"ip"`
+	DNSName            string                 `json:"dnsName,omitempty"`    // nombre PTR (ver DeviceInfo.DNSName)
+	PreviousIP         string                 `json:"previousIp,omitempty"` // IP anterior si la impresora cambió de IP desde el último poll
+	Addresses          []string               `json:"addresses,omitempty"`  // IPs de un equipo multi-homed (ver DuplicateIndex)
 	Brand              string                 `json:"brand"`
 	Confidence         float64                `json:"confidence"`
 	Identification     map[string]interface{} `json:"identification"`
@@ -25,45 +36,91 @@ type PrinterData struct {
 	AdminInfo          map[string]interface{} `json:"adminInfo,omitempty"`
 	NormalizedCounters map[string]interface{} `json:"normalizedCounters,omitempty"`
 	NormalizedSupplies map[string]interface{} `json:"normalizedSupplies,omitempty"`
+	CounterSources     map[string]string      `json:"counterSources,omitempty"`    // contador normalizado → origen del mapeo (ver CounterSource*)
+	CounterConfidence  map[string]float64     `json:"counterConfidence,omitempty"` // contador normalizado → confianza del mapeo (0.0-1.0)
+	CounterAnomalies   []CounterAnomaly       `json:"counterAnomalies,omitempty"`  // lecturas que no parecen reales (ver anomaly.go)
 	Errors             []string               `json:"errors"`
 	MissingSections    []string               `json:"missingSections"`
 	Timestamp          time.Time              `json:"timestamp"`
 	ResponseTime       time.Duration          `json:"responseTime"`
 	ProbeAttempts      int                    `json:"probeAttempts"`
+	Partial            bool                   `json:"partial,omitempty"`    // true si venció el deadline antes de terminar
+	Extensions         map[string]interface{} `json:"extensions,omitempty"` // datos agregados por hooks (ver hooks.go)
+
+	// Modelo tipado (ver model.go): se llena al final de la recolección
+	// y no se serializa para no alterar el JSON histórico
+	Info         Identification `json:"-"`
+	State        Status         `json:"-"`
+	Network      Network        `json:"-"`
+	SupplyList   []Supply       `json:"-"`
+	PageCounters CountersInfo   `json:"-"`
+	Capabilities Capabilities   `json:"-"`
+	Catalog      *modeldb.Match `json:"-"` // modelo identificado en la base de modelos (nil = sin coincidencia)
+	Usage        *PageUsage     `json:"-"` // volumen de impresión medio según el estado (ver TrackUsage)
+
+	// OIDs intentados en el poll y qué respondió cada uno (soporte/debug)
+	Coverage *CoverageReport `json:"-"`
+
+	// Próximo poll según el plan de consultas (cero = Timestamp + DefaultPollInterval)
+	NextPollAt time.Time `json:"-"`
 }
 
 // CountersInfo agrupa contadores absolutos (para state/ y en queue/)
 type CountersInfo struct {
-	TotalPages int64 `json:"total_pages"`
-	MonoPages  int64 `json:"mono_pages"`
-	ColorPages int64 `json:"color_pages"`
-	ScanPages  int64 `json:"scan_pages"`
-	CopyPages  int64 `json:"copy_pages"`
-	FaxPages   int64 `json:"fax_pages"`
+	TotalPages  int64 `json:"total_pages"`
+	MonoPages   int64 `json:"mono_pages"`
+	ColorPages  int64 `json:"color_pages"`
+	ScanPages   int64 `json:"scan_pages"`
+	CopyPages   int64 `json:"copy_pages"`
+	FaxPages    int64 `json:"fax_pages"`
+	DuplexPages int64 `json:"duplex_pages,omitempty"` // Hojas impresas a doble cara
+	A3Pages     int64 `json:"a3_pages,omitempty"`     // Impresiones A3/Ledger (tamaño grande)
 }
 
 // CountersDiff contiene solo cambios (deltas)
 type CountersDiff struct {
-	TotalPages int64 `json:"total_pages"`
-	MonoPages  int64 `json:"mono_pages"`
-	ColorPages int64 `json:"color_pages"`
-	ScanPages  int64 `json:"scan_pages"`
-	CopyPages  int64 `json:"copy_pages"`
-	FaxPages   int64 `json:"fax_pages"`
+	TotalPages  int64 `json:"total_pages"`
+	MonoPages   int64 `json:"mono_pages"`
+	ColorPages  int64 `json:"color_pages"`
+	ScanPages   int64 `json:"scan_pages"`
+	CopyPages   int64 `json:"copy_pages"`
+	FaxPages    int64 `json:"fax_pages"`
+	DuplexPages int64 `json:"duplex_pages,omitempty"` // Hojas impresas a doble cara
+	A3Pages     int64 `json:"a3_pages,omitempty"`     // Impresiones A3/Ledger (tamaño grande)
 }
 
 // CountersSnapshot contiene contadores absolutos + deltas (para queue/)
 type CountersSnapshot struct {
-	Absolute      CountersInfo  `json:"absolute"`                 // Valores actuales
-	Delta         *CountersDiff `json:"delta"`                    // Cambios desde última lectura (null si reset o sin estado)
-	ResetDetected bool          `json:"reset_detected,omitempty"` // true si hubo reset
+	Absolute      CountersInfo       `json:"absolute"`                 // Valores actuales
+	Delta         *CountersDiff      `json:"delta"`                    // Cambios desde última lectura (null si reset o sin estado)
+	ResetDetected bool               `json:"reset_detected,omitempty"` // true si hubo reset
+	Sources       map[string]string  `json:"sources,omitempty"`        // contador → profile | marker | vendor | heuristic | page_count
+	Confidence    map[string]float64 `json:"confidence,omitempty"`     // contador → confianza del mapeo (0.0-1.0)
+	Anomalies     []CounterAnomaly   `json:"anomalies,omitempty"`      // lecturas sospechosas (se informan, no se descartan)
 }
 
+// Origen del valor de cada contador normalizado
+const (
+	CounterSourceProfile   = "profile"    // CounterMappings del perfil (OID → nombre explícito)
+	CounterSourceMarker    = "marker"     // Filas de prtMarkerTable (RFC 3805)
+	CounterSourceVendor    = "vendor"     // Medidor propietario con significado documentado
+	CounterSourceHeuristic = "heuristic"  // Deducido por tamaño del valor
+	CounterSourcePageCount = "page_count" // Fallback al page_count del estado
+	CounterSourcePJL       = "pjl"        // @PJL INFO PAGECOUNT (HP sin contadores por SNMP, ver hp_fallback.go)
+	CounterSourceEWS       = "ews"        // Servidor web embebido de HP (LEDM)
+)
+
 // PrinterState representa la última lectura conocida (almacenada en state/)
 // Se usa para calcular deltas en el siguiente poll
 type PrinterState struct {
-	LastPollAt time.Time    `json:"last_poll_at"`
-	Counters   CountersInfo `json:"counters"`
+	LastPollAt time.Time                `json:"last_poll_at"`
+	Counters   CountersInfo             `json:"counters"`
+	Supplies   map[string]SupplyHistory `json:"supplies,omitempty"` // por Supply.Key, ver ForecastSupplies
+	Emitted    map[string]EmitRecord    `json:"emitted,omitempty"`  // por sink, ver SaveEmitted
+	Sequence   uint64                   `json:"sequence,omitempty"` // último número de secuencia, ver NextSequence
+	Schedule   *PollSchedule            `json:"schedule,omitempty"` // próximo poll, ver PlanPoll
+	ScanID     string                   `json:"scan_id,omitempty"`  // último ciclo que escribió el estado, ver SetScanID
+	Usage      *PageUsage               `json:"usage,omitempty"`    // volumen de impresión medio, ver TrackUsage
 }
 
 // DeviceInfo contiene información sobre un dispositivo a procesar
@@ -74,13 +131,19 @@ type DeviceInfo struct {
 	SysDescr        string
 	Community       string
 	SNMPVersion     string
+	SNMPv3          *snmp.V3Credentials // credenciales USM si SNMPVersion es "3"
+	Port            uint16              // 0 = Config.SNMPPort (replay usa puertos locales por dispositivo)
+	DNSName         string              // nombre PTR del discovery ("" = sin DNS inverso)
 }
 
 // DataCollector recolecta datos de impresoras
 type DataCollector struct {
 	config         Config
-	rateLimiter    *RateLimiter
+	engine         *snmp.Engine
 	profileManager *profile.Manager
+
+	mu          sync.Mutex
+	slowDevices []string // IPs lentas/pendientes de la última recolección
 }
 
 // getPageCountFromStatus extrae page_count del mapa Status
@@ -101,6 +164,10 @@ func toInt64(val interface{}) int64 {
 	if val == nil {
 		return 0
 	}
+	if v, ok := val.(snmp.Value); ok {
+		n, _ := v.Int64()
+		return n
+	}
 	if v, ok := val.(int64); ok {
 		return v
 	}
@@ -118,107 +185,177 @@ func toInt64(val interface{}) int64 {
 	return 0
 }
 
-// isSuspiciousValue detecta si un valor es sospechoso (overflow/garbage)
-func isSuspiciousValue(val int64) bool {
-	// Valores conocidos sospechosos
-	suspiciousValues := map[int64]bool{
-		2147483647: true, // INT32_MAX
-		4294967295: true, // UINT32_MAX
-		9223372036: true, // INT64_MAX/billion aproximadamente
-		268435456:  true, // 2^28
-		536870912:  true, // 2^29
-		1073741824: true, // 2^30
-		2097151:    true, // 2^21-1
-		4194303:    true, // 2^22-1
-		8388607:    true, // 2^23-1
-		16777215:   true, // 2^24-1
-		33554431:   true, // 2^25-1
-		27327487:   true, // Observado en Samsung 192.168.150.35
-		18935871:   true, // Observado en Samsung 192.168.150.35
-		2002943:    true, // Observado en Samsung 192.168.150.35
-	}
-
-	if suspiciousValues[val] {
-		return true
-	}
-
-	// Si es una potencia de 2, es sospechoso
-	if val > 1000 && (val&(val-1)) == 0 {
-		return true
-	}
-
-	return false
+// isTypedCounter indica si el agente declaró el valor como contador SNMP
+func isTypedCounter(v snmp.Value) bool {
+	return v.Type == gosnmp.Counter32 || v.Type == gosnmp.Counter64
 }
 
 // Config contiene configuración del colector
 type Config struct {
 	Timeout                  time.Duration
+	DeviceTimeout            time.Duration // deadline total por dispositivo (0 = sin límite)
+	ScanBudget               time.Duration // presupuesto de toda la recolección (0 = sin límite)
 	Retries                  int
 	MaxConcurrentConnections int
-	MaxOidsPerDevice         int
-	MinDelayBetweenQueries   time.Duration
+	MaxOidsPerDevice         int           // OIDs por GET a un mismo equipo (0 = 50)
+	MinDelayBetweenQueries   time.Duration // espera mínima entre paquetes a un mismo equipo (0 = sin espera)
 	Community                string
 	SNMPVersion              string
 	SNMPPort                 uint16
+	Engine                   *snmp.Engine         // Motor SNMP compartido (nil = crear uno propio)
+	ProfileStore             profile.ProfileStore // Plantillas compartidas entre agentes (nil = solo local; con Profiles lo asigna su dueño)
+	ProfileDir               string               // perfiles aprendidos por dispositivo ("" = profiles; se ignora con Profiles)
+	Profiles                 *profile.Manager     // perfiles compartidos entre colectores (nil = cargar los de ProfileDir, ver LoadProfiles)
+	SectionConcurrency       int                  // Secciones de un dispositivo consultadas a la vez (0 = 3, 1 = secuencial)
+	History                  *StateManager        // Lecturas anteriores para detectar anomalías (nil = solo centinelas)
+	BreakerFailures          int                  // polls fallidos seguidos que abren el circuit breaker (0 = deshabilitado)
+	BreakerCycles            int                  // ciclos con solo sondeo de vida tras abrirse el breaker
+	Clock                    *clock.Clock         // hora corregida para Timestamp (nil = reloj local)
+	Hooks                    []Hook               // pasos propios antes y después de las consultas (ver hooks.go)
+	HPFallback               HPFallbackConfig     // PJL/EWS para HP sin contadores o consumibles por SNMP (ver hp_fallback.go)
+	IPP                      IPPConfig            // estado, consumibles y bandejas por IPP si SNMP no los trae (ver ipp.go)
+	Models                   *modeldb.Database    // base de modelos: nombre canónico, velocidad y rendimientos (nil = sin consulta)
+	ModelMinConfidence       float64              // confianza mínima para aceptar una coincidencia de Models
+	Interactive              bool                 // consulta a pedido (API): prioridad status y sin circuit breaker
 }
 
 // NewDataCollector crea un nuevo colector
 func NewDataCollector(config Config) *DataCollector {
-	profileDir := "profiles"
-	pm, err := profile.NewManager(profileDir)
-	if err != nil {
-		pm = nil
+	pm := config.Profiles
+	if pm == nil {
+		pm = LoadProfiles(config.ProfileDir)
+		if pm != nil {
+			pm.SetStore(config.ProfileStore)
+		}
+	}
+
+	engine := config.Engine
+	if engine == nil {
+		engine = snmp.NewEngine(snmp.EngineConfig{MaxWorkers: config.MaxConcurrentConnections})
 	}
 
 	return &DataCollector{
 		config:         config,
-		rateLimiter:    NewRateLimiter(config.MaxConcurrentConnections),
+		engine:         engine,
 		profileManager: pm,
 	}
 }
 
+// LoadProfiles abre los perfiles aprendidos de dir ("" = profiles) para
+// compartirlos entre colectores con Config.Profiles; nil si no se puede crear
+// el directorio
+func LoadProfiles(dir string) *profile.Manager {
+	if dir == "" {
+		dir = "profiles"
+	}
+	pm, err := profile.NewManager(dir)
+	if err != nil {
+		return nil
+	}
+	if err := pm.LoadAll(); err != nil {
+		fmt.Println(i18n.T("log.profiles_load_error", err))
+	}
+	return pm
+}
+
+// Profiles retorna el gestor de perfiles (nil si no se pudo inicializar)
+func (dc *DataCollector) Profiles() *profile.Manager {
+	return dc.profileManager
+}
+
+// History retorna las lecturas anteriores del colector (Config.History)
+func (dc *DataCollector) History() *StateManager {
+	return dc.config.History
+}
+
 // CollectData recolecta datos de múltiples dispositivos en paralelo
+// Espera a que terminen todos; para procesar cada dispositivo apenas termina usar CollectStream
 func (dc *DataCollector) CollectData(ctx context.Context, devices []DeviceInfo) ([]PrinterData, error) {
 	results := make([]PrinterData, 0, len(devices))
-	resultsChan := make(chan PrinterData, len(devices))
-	var wg sync.WaitGroup
+	for data := range dc.CollectStream(ctx, devices) {
+		results = append(results, data)
+	}
+	return results, nil
+}
 
-	fmt.Printf("Iniciando recolección de %d dispositivos...\n", len(devices))
-	startTime := time.Now()
+// CollectStream recolecta en paralelo y emite cada PrinterData en cuanto termina
+// su dispositivo. El canal se cierra al finalizar la recolección; recién entonces
+// SlowDevices() refleja este ciclo
+// Con ScanBudget, al agotarse el presupuesto se cierran los resultados parciales
+// y los dispositivos lentos o no alcanzados quedan en SlowDevices()
+func (dc *DataCollector) CollectStream(ctx context.Context, devices []DeviceInfo) <-chan PrinterData {
+	// Buffer completo: un consumidor lento no retiene a los workers SNMP
+	out := make(chan PrinterData, len(devices))
+
+	go func() {
+		defer close(out)
 
-	for _, device := range devices {
-		wg.Add(1)
+		fmt.Println(i18n.T("log.collection_start", len(devices)))
+		startTime := time.Now()
 
-		go func(devInfo DeviceInfo) {
-			defer wg.Done()
+		scanCtx := ctx
+		if dc.config.ScanBudget > 0 {
+			var cancel context.CancelFunc
+			scanCtx, cancel = context.WithTimeout(ctx, dc.config.ScanBudget)
+			defer cancel()
+		}
 
-			dc.rateLimiter.Wait()
-			defer dc.rateLimiter.Release()
+		var mu sync.Mutex
+		var slow []string
+		started := make([]bool, len(devices))
+
+		// Pool acotado de workers del motor SNMP (no una goroutine por dispositivo)
+		dc.engine.ForEach(scanCtx, len(devices), func(i int) {
+			started[i] = true
+			data := dc.collectFromDevice(scanCtx, devices[i])
+			if data.Partial {
+				mu.Lock()
+				slow = append(slow, data.IP)
+				mu.Unlock()
+			}
+			out <- data
+		})
+
+		// Dispositivos que no alcanzaron a empezar antes de agotar el presupuesto
+		pending := 0
+		for i, ok := range started {
+			if !ok {
+				slow = append(slow, devices[i].IP)
+				pending++
+			}
+		}
+		switch {
+		case pending > 0 && ctx.Err() != nil:
+			fmt.Println(i18n.T("log.collection_aborted", pending))
+		case pending > 0:
+			fmt.Println(i18n.T("log.scan_budget_exceeded", dc.config.ScanBudget, pending))
+		}
 
-			data := dc.collectFromDevice(ctx, devInfo)
-			resultsChan <- data
-		}(device)
-	}
+		dc.mu.Lock()
+		dc.slowDevices = slow
+		dc.mu.Unlock()
 
-	go func() {
-		wg.Wait()
-		close(resultsChan)
+		elapsed := time.Since(startTime)
+		fmt.Println(i18n.T("log.collection_done", elapsed.Seconds()))
 	}()
 
-	for data := range resultsChan {
-		results = append(results, data)
-	}
-
-	elapsed := time.Since(startTime)
-	fmt.Printf("Recolección completada en %.2f segundos.\n", elapsed.Seconds())
+	return out
+}
 
-	return results, nil
+// SlowDevices retorna las IPs que en la última recolección vencieron su deadline
+// o quedaron sin recolectar por el presupuesto del escaneo
+// Se priorizan en el próximo ciclo
+func (dc *DataCollector) SlowDevices() []string {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return append([]string(nil), dc.slowDevices...)
 }
 
 // collectFromDevice recolecta datos de un dispositivo específico
-func (dc *DataCollector) collectFromDevice(_ context.Context, devInfo DeviceInfo) PrinterData {
+func (dc *DataCollector) collectFromDevice(ctx context.Context, devInfo DeviceInfo) PrinterData {
 	data := PrinterData{
 		IP:                 devInfo.IP,
+		DNSName:            devInfo.DNSName,
 		Brand:              devInfo.Brand,
 		Confidence:         devInfo.BrandConfidence,
 		Identification:     make(map[string]interface{}),
@@ -231,55 +368,63 @@ func (dc *DataCollector) collectFromDevice(_ context.Context, devInfo DeviceInfo
 		NormalizedSupplies: make(map[string]interface{}),
 		Errors:             []string{},
 		MissingSections:    []string{},
-		Timestamp:          time.Now(),
+		Timestamp:          dc.config.Clock.Now(),
 		ProbeAttempts:      1,
 	}
 
 	startTime := time.Now()
 
-	// Crear cliente SNMP
-	client := snmp.NewSNMPClient(devInfo.IP, dc.config.SNMPPort, devInfo.Community, "2c", dc.config.Timeout, dc.config.Retries)
-
-	// Cargar perfil si está disponible, o ejecutar discovery
-	var prof *profile.Profile
-	var err error
-	if dc.profileManager != nil {
-		prof = dc.profileManager.GetOrDiscover(devInfo.IP)
-
-		// Si no existe perfil, ejecutar discovery y guardar
-		if prof == nil {
-			fmt.Printf("[DISCOVERY] Ejecutando discovery para %s (%s)...\n", devInfo.IP, devInfo.Brand)
-			prof, err = dc.profileManager.DiscoverAndSave(client, devInfo.IP, devInfo.Brand, "", "")
-			if err != nil {
-				data.Errors = append(data.Errors, fmt.Sprintf("Discovery failed: %v", err))
-				fmt.Printf("[DISCOVERY] Error: %v\n", err)
-			} else if prof != nil {
-				fmt.Printf("[DISCOVERY] Perfil guardado para %s\n", devInfo.IP)
-			}
-		}
+	// Deadline por dispositivo: un equipo lento no puede frenar todo el ciclo
+	deviceCtx := ctx
+	if dc.config.DeviceTimeout > 0 {
+		var cancel context.CancelFunc
+		deviceCtx, cancel = context.WithTimeout(ctx, dc.config.DeviceTimeout)
+		defer cancel()
 	}
 
-	// PASO 1: Recolectar identificación
-	dc.collectIdentification(&data, client)
+	// PASO 0: hooks previos (pueden ajustar marca y credenciales)
+	dc.runPreHooks(deviceCtx, &devInfo, &data)
+	data.Brand = devInfo.Brand
 
-	// PASO 2: Recolectar estado
-	dc.collectStatus(&data, client)
+	// Crear cliente SNMP
+	port := dc.config.SNMPPort
+	if devInfo.Port != 0 {
+		port = devInfo.Port
+	}
+	coverage := newCoverageRecorder()
+	client := dc.engine.NewClient(devInfo.IP, port, devInfo.Community, dc.snmpVersion(devInfo), dc.config.Timeout, dc.config.Retries).
+		WithV3(devInfo.SNMPv3).
+		WithContext(deviceCtx).
+		WithObserver(coverage).
+		WithLimits(dc.config.MaxOidsPerDevice, dc.config.MinDelayBetweenQueries)
+	if dc.config.Interactive {
+		client = client.WithPriority(snmp.PriorityStatus)
+	}
+
+	// PASOS 1-6: consultas SNMP (se cortan al vencer el deadline)
+	// Con el circuit breaker abierto solo se sondea que el equipo siga vivo
+	breakerProf := dc.openBreaker(devInfo)
+	if breakerProf != nil {
+		dc.probeLiveness(&data, client, breakerProf)
+	} else {
+		dc.collectSections(deviceCtx, &data, client, devInfo)
 
-	// PASO 3: Recolectar info de red
-	dc.collectNetworkInfo(&data, client)
+		// PASO 6b: HP con SNMP restringido, contadores y consumibles por PJL/EWS
+		if deviceCtx.Err() == nil {
+			dc.collectHPFallback(deviceCtx, &data)
+		}
 
-	// PASO 4: Recolectar consumibles dinámicamente
-	walkCtx := snmp.NewContext()
-	consumibles := dc.collectConsumiblesViaWalk(client, walkCtx, prof)
-	for k, v := range consumibles {
-		data.Supplies[k] = v
+		// PASO 6c: consumibles y estado por IPP (tablas SNMP vacías o rotas)
+		if deviceCtx.Err() == nil {
+			dc.collectIPP(deviceCtx, &data)
+		}
 	}
 
-	// PASO 5: Recolectar contadores
-	dc.collectCounters(&data, client, prof)
-
-	// PASO 6: Realizar WALK exhaustivo para descubrir datos adicionales
-	dc.discoverAdditionalData(&data, client)
+	if err := deviceCtx.Err(); err != nil {
+		data.Partial = true
+		data.Errors = append(data.Errors, fmt.Sprintf("Deadline excedido: %v", err))
+		fmt.Println(i18n.T("log.device_deadline", devInfo.IP, time.Since(startTime).Round(time.Millisecond)))
+	}
 
 	// PASO 7: Extraer contadores que están disfrazados en supplies
 	dc.extractPageCountersFromSupplies(&data)
@@ -287,6 +432,18 @@ func (dc *DataCollector) collectFromDevice(_ context.Context, devInfo DeviceInfo
 	// PASO 8: Normalizar datos para presentación legible
 	dc.normalizeData(&data)
 
+	// PASO 8b: hooks posteriores (enriquecen antes del modelo tipado)
+	dc.runPostHooks(deviceCtx, &data)
+
+	// PASO 9: Modelo tipado para telemetry/state (parseo único)
+	data.populateTyped()
+
+	// PASO 9b: Modelo de catálogo (velocidad, clase, rendimiento de consumibles)
+	dc.applyCatalog(&data)
+
+	// PASO 10: Contrastar contadores con la lectura anterior (saltos, mesetas)
+	dc.checkCounterHistory(&data)
+
 	data.ResponseTime = time.Since(startTime)
 
 	// Contar secciones vacías
@@ -300,9 +457,132 @@ func (dc *DataCollector) collectFromDevice(_ context.Context, devInfo DeviceInfo
 		data.MissingSections = append(data.MissingSections, "counters")
 	}
 
+	data.Coverage = coverage.report(&data)
+	data.Capabilities.Subtrees, data.Capabilities.SuccessRate = data.Coverage.supported()
+
+	if breakerProf == nil {
+		dc.recordCollection(&data)
+	}
+
 	return data
 }
 
+// snmpVersion retorna la versión con la que consultar un dispositivo: v3 si
+// está configurada, v1 si el discovery o el perfil la negociaron (el equipo no
+// responde v2c), si no la de DeviceInfo (config) y por defecto 2c
+func (dc *DataCollector) snmpVersion(devInfo DeviceInfo) string {
+	if devInfo.SNMPVersion == "1" || devInfo.SNMPVersion == "3" {
+		return devInfo.SNMPVersion
+	}
+	if dc.profileManager != nil {
+		if prof := dc.profileManager.Lookup("", devInfo.IP); prof != nil && prof.SNMPVersion == "1" {
+			return "1"
+		}
+	}
+	if devInfo.SNMPVersion == "" {
+		return "2c"
+	}
+	return devInfo.SNMPVersion
+}
+
+// collectSections ejecuta las consultas SNMP de un dispositivo en orden
+// Retorna en cuanto ctx vence: lo recolectado hasta ahí se emite como parcial
+func (dc *DataCollector) collectSections(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, devInfo DeviceInfo) {
+	// PASOS 1-3: identificación, estado y red son independientes entre sí
+	dc.runSections(ctx, data,
+		func(part *PrinterData) { dc.collectIdentification(part, client) },
+		func(part *PrinterData) { dc.collectStatus(part, client) },
+		func(part *PrinterData) { dc.collectNetworkInfo(part, client) },
+	)
+
+	// Con MAC/serial ya conocidos se puede calcular el ID estable
+	data.PrinterID = resolvePrinterID(data)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Cargar perfil si está disponible, o ejecutar discovery
+	var prof *profile.Profile
+	var err error
+	if dc.profileManager != nil {
+		prof = dc.profileManager.Lookup(data.PrinterID, devInfo.IP)
+
+		// Impresora conocida (MAC/serial) en otra IP: el perfil la sigue
+		if prof != nil && data.PrinterID != devInfo.IP {
+			if previous, err := dc.profileManager.Relocate(prof.PrinterID, devInfo.IP); err != nil {
+				fmt.Println(i18n.T("log.profile_relocate_error", prof.PrinterID, err))
+			} else if previous != "" {
+				data.PreviousIP = previous
+				fmt.Println(i18n.T("log.printer_ip_changed", data.PrinterID, previous, devInfo.IP))
+			}
+		}
+
+		// Si no existe perfil, ejecutar discovery y guardar
+		if prof == nil {
+			fmt.Println(i18n.T("log.profile_discovery", devInfo.IP, devInfo.Brand))
+			serial, _ := data.Identification["serial_number"].(string)
+			// Los walks del discovery ceden los slots del motor a los polls y refrescos
+			prof, err = dc.profileManager.DiscoverAndSave(client.WithPriority(snmp.PriorityDiscovery), data.PrinterID, devInfo.IP, devInfo.Brand, profileModel(data), serial)
+			if err != nil {
+				data.Errors = append(data.Errors, fmt.Sprintf("Discovery failed: %v", err))
+				fmt.Println(i18n.T("log.profile_discovery_err", err))
+			} else if prof != nil {
+				fmt.Println(i18n.T("log.profile_saved", devInfo.IP, data.PrinterID))
+			}
+		}
+	}
+
+	// La marca corregida en polls anteriores manda sobre el sysDescr
+	applyProfileBrand(data, prof)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Los subárboles de Printer MIB se recorren una sola vez por poll y
+	// se comparten entre consumibles, contadores y discovery adicional
+	walkCtx := snmp.NewContext()
+	walks := NewWalkCache(client)
+	walks.SetFallback(vendorCounterOIDs(data.Brand))
+	walks.Prefetch(walkCtx, prefetchSubtrees...)
+
+	// PASOS 4-5: consumibles y contadores en paralelo (comparten el cache de WALKs)
+	dc.runSections(ctx, data,
+		func(part *PrinterData) {
+			for k, v := range dc.collectConsumiblesViaWalk(walks, walkCtx, prof) {
+				part.Supplies[k] = v
+			}
+			for k, v := range collectFinisherSupplies(walks, walkCtx) {
+				if _, exists := part.Supplies[k]; !exists {
+					part.Supplies[k] = v
+				}
+			}
+		},
+		func(part *PrinterData) { dc.collectCounters(ctx, part, client, walks, prof) },
+	)
+
+	// Capacidades reales con lo que respondió este poll
+	detectCapabilities(data, client, walks, walkCtx, prof)
+
+	// Realimentar la confianza de la marca con los OIDs del fabricante
+	dc.calibrateBrand(ctx, data, client, prof)
+
+	// PASO 6: Realizar WALK exhaustivo para descubrir datos adicionales
+	dc.discoverAdditionalData(data, walks)
+}
+
+// profileModel es el modelo con el que se guarda el perfil y se busca su
+// plantilla: hrDeviceDescr ("Xerox AltaLink C8055") antes que el OID de
+// Printer MIB, que muchos equipos llenan con el serial
+func profileModel(data *PrinterData) string {
+	if model, _ := data.Identification["device_description"].(string); model != "" {
+		return model
+	}
+	model, _ := data.Identification["model"].(string)
+	return model
+}
+
 // collectIdentification recolecta datos de identificación
 func (dc *DataCollector) collectIdentification(data *PrinterData, client *snmp.SNMPClient) {
 	oids := []string{
@@ -312,6 +592,7 @@ func (dc *DataCollector) collectIdentification(data *PrinterData, client *snmp.S
 		"1.3.6.1.2.1.43.5.1.1.17.1",    // Modelo (RFC 3805)
 		"1.3.6.1.2.1.43.5.1.1.5.1",     // Serial Number (RFC 3805: printerSerialNumber)
 		"1.3.6.1.4.1.11.2.3.9.1.1.7.0", // HP Device Identification String
+		"1.3.6.1.2.1.25.3.2.1.3.1",     // hrDeviceDescr (modelo comercial: perfiles y plantillas)
 	}
 
 	ctx := snmp.NewContext()
@@ -328,8 +609,10 @@ func (dc *DataCollector) collectIdentification(data *PrinterData, client *snmp.S
 		"1.3.6.1.2.1.1.2.0":         "sysObjectID",
 		"1.3.6.1.2.1.43.5.1.1.17.1": "model",
 		"1.3.6.1.2.1.43.5.1.1.5.1":  "serial_number",
+		"1.3.6.1.2.1.25.3.2.1.3.1":  "device_description",
 	}
 
+	var hpIdentification string
 	for oid, val := range results {
 		if val == nil {
 			continue
@@ -340,9 +623,9 @@ func (dc *DataCollector) collectIdentification(data *PrinterData, client *snmp.S
 			continue
 		}
 
-		// HP Device Identification String: parse HP-specific format
+		// HP Device Identification String: se parsea al final
 		if oid == "1.3.6.1.4.1.11.2.3.9.1.1.7.0" {
-			dc.parseHPIdentificationString(valStr, data)
+			hpIdentification = valStr
 			continue
 		}
 
@@ -352,6 +635,12 @@ func (dc *DataCollector) collectIdentification(data *PrinterData, client *snmp.S
 		}
 	}
 
+	// Después de los OIDs estándar: MDL/SN de HP tienen prioridad sin depender
+	// del orden en que se recorre results
+	if hpIdentification != "" {
+		dc.parseHPIdentificationString(hpIdentification, data)
+	}
+
 	if len(data.Identification) == 0 {
 		data.MissingSections = append(data.MissingSections, "identification")
 	}
@@ -402,6 +691,8 @@ func (dc *DataCollector) collectStatus(data *PrinterData, client *snmp.SNMPClien
 		"1.3.6.1.2.1.43.13.4.1.7.1.1", // printer status (HR-MIB)
 		"1.3.6.1.2.1.43.8.2.1.13.1.1", // error status
 		"1.3.6.1.2.1.1.3.0",           // sysUpTime (centisegundos desde reinicio)
+		oidHrPrinterStatus,            // hrPrinterStatus (idle/printing/warmup)
+		oidHrPrinterDetectedErrorState,
 	}
 
 	ctx := snmp.NewContext()
@@ -417,6 +708,15 @@ func (dc *DataCollector) collectStatus(data *PrinterData, client *snmp.SNMPClien
 			continue
 		}
 
+		// El bitfield de errores se guarda en hex: como texto sería ilegible
+		// y "sin errores" (0x00) se perdería en el filtro de vacíos de abajo
+		if oid == oidHrPrinterDetectedErrorState {
+			if v, ok := val.(snmp.Value); ok && !v.IsNull() {
+				data.Status["detected_error_state"] = hex.EncodeToString(v.Bytes())
+			}
+			continue
+		}
+
 		valStr := strings.TrimSpace(fmt.Sprintf("%v", val))
 		if valStr == "" || valStr == "0" {
 			continue
@@ -445,6 +745,9 @@ func (dc *DataCollector) collectStatus(data *PrinterData, client *snmp.SNMPClien
 		case "1.3.6.1.2.1.43.8.2.1.13.1.1":
 			data.Status["error_status"] = valStr
 
+		case oidHrPrinterStatus:
+			data.Status["hr_printer_status"] = valStr
+
 		case "1.3.6.1.2.1.1.3.0":
 			// sysUpTime en centisegundos
 			if uptimeCentiseconds, err := strconv.ParseInt(valStr, 10, 64); err == nil {
@@ -455,6 +758,18 @@ func (dc *DataCollector) collectStatus(data *PrinterData, client *snmp.SNMPClien
 		}
 	}
 
+	// hrPrinterStatus es más preciso que hrDeviceStatus (distingue idle/printing/warmup)
+	if state := printerStatusState(mapInt64(data.Status, "hr_printer_status")); state != "" {
+		data.Status["state"] = state
+	}
+
+	// Condiciones que impiden imprimir dominan sobre el estado informado
+	if errState := DecodeErrorStateHex(mapString(data.Status, "detected_error_state")); errState.Offline {
+		data.Status["state"] = "offline"
+	} else if errState.Blocking() {
+		data.Status["state"] = "error"
+	}
+
 	// Si no hay state aún, establecer como desconocido
 	if _, ok := data.Status["state"]; !ok {
 		data.Status["state"] = "unknown"
@@ -481,22 +796,51 @@ func (dc *DataCollector) formatUptime(seconds int64) string {
 }
 
 // collectNetworkInfo recolecta información de red
+// La MAC y la IP salen de la interfaz que tiene la IP consultada (ver interfaces.go);
+// sin ifTable se usan los índices fijos de siempre
 func (dc *DataCollector) collectNetworkInfo(data *PrinterData, client *snmp.SNMPClient) {
+	ctx := snmp.NewContext()
+
+	if location, err := client.Get("1.3.6.1.2.1.1.6.0", ctx); err == nil && location != nil {
+		if valStr := fmt.Sprintf("%v", location); valStr != "" {
+			data.NetworkInfo["location"] = valStr
+		}
+	}
+
+	interfaces := walkInterfaces(client, ctx)
+	if len(interfaces) > 0 {
+		data.NetworkInfo["interfaces"] = interfaces
+		if iface := selectInterface(interfaces, data.IP); iface != nil {
+			data.NetworkInfo["ifIndex"] = iface.Index
+			if iface.MACAddress != "" {
+				data.NetworkInfo["macAddress"] = iface.MACAddress
+			}
+			if len(iface.IPAddresses) > 0 {
+				data.NetworkInfo["ipAddress"] = iface.IPAddresses[0]
+				for _, ip := range iface.IPAddresses {
+					if ip == data.IP {
+						data.NetworkInfo["ipAddress"] = ip
+					}
+				}
+			}
+			return
+		}
+	}
+
 	oids := []string{
 		"1.3.6.1.2.1.2.2.1.6.1",  // MAC address interface 1
 		"1.3.6.1.2.1.2.2.1.6.2",  // MAC address interface 2 (useful for multi-interface devices)
 		"1.3.6.1.2.1.4.20.1.1.1", // IP address
-		"1.3.6.1.2.1.1.6.0",      // sysLocation
 	}
 
-	ctx := snmp.NewContext()
 	results, err := client.GetMultiple(oids, ctx)
 	if err != nil {
 		data.Errors = append(data.Errors, fmt.Sprintf("Error en networkInfo: %v", err))
 		return
 	}
 
-	for oid, val := range results {
+	for _, oid := range oids {
+		val := results[oid]
 		if val == nil {
 			continue
 		}
@@ -509,69 +853,74 @@ func (dc *DataCollector) collectNetworkInfo(data *PrinterData, client *snmp.SNMP
 		switch oid {
 		case "1.3.6.1.2.1.2.2.1.6.1", "1.3.6.1.2.1.2.2.1.6.2":
 			// Take the first non-empty MAC address found
-			if _, exists := data.NetworkInfo["macAddress"]; !exists && valStr != "" {
+			if _, exists := data.NetworkInfo["macAddress"]; !exists {
 				data.NetworkInfo["macAddress"] = valStr
 			}
 		case "1.3.6.1.2.1.4.20.1.1.1":
 			data.NetworkInfo["ipAddress"] = valStr
-		case "1.3.6.1.2.1.1.6.0":
-			data.NetworkInfo["location"] = valStr
 		}
 	}
 }
 
 // collectCounters recolecta contadores de páginas
-func (dc *DataCollector) collectCounters(data *PrinterData, client *snmp.SNMPClient, prof *profile.Profile) {
-	ctx := snmp.NewContext()
+func (dc *DataCollector) collectCounters(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, walks *WalkCache, prof *profile.Profile) {
+	walkCtx := snmp.NewContext()
 
 	// WALK del árbol completo de contadores RFC 3805: 1.3.6.1.2.1.43.10.2
-	results, err := client.Walk("1.3.6.1.2.1.43.10.2", ctx)
+	results, err := walks.Walk("1.3.6.1.2.1.43.10.2", walkCtx)
 	if err != nil || len(results) == 0 {
-		results, _ = client.Walk("1.3.6.1.2.1.43.10", ctx)
+		results, _ = walks.Walk("1.3.6.1.2.1.43.10", walkCtx)
 	}
 
 	// Recolectar TODOS los valores de contadores
 	allCounters := make(map[string]int64)
 
 	for _, result := range results {
-		if result.Value == "" {
+		parsed, ok := result.Typed.Int64()
+		if !ok || parsed <= 0 {
 			continue
 		}
 
-		valStr := strings.TrimSpace(fmt.Sprintf("%v", result.Value))
-		if valStr == "" {
+		// Filtrar valores de overflow (> 3 mil millones es casi seguro basura)
+		// salvo que el agente los declare Counter32/Counter64: ahí el rango
+		// completo es legítimo (un Counter32 cerca de 2^32 está por dar la vuelta)
+		if parsed > 3_000_000_000 && !isTypedCounter(result.Typed) {
 			continue
 		}
 
-		if parsed, err := strconv.ParseInt(valStr, 10, 64); err == nil {
-			// Filtrar valores de overflow (> 3 mil millones es casi seguro basura)
-			if parsed > 0 && parsed <= 3_000_000_000 {
-				normalizedOID := strings.TrimPrefix(result.OID, ".")
-				allCounters[normalizedOID] = parsed
-				data.Counters[normalizedOID] = parsed
-			}
-		}
+		normalizedOID := strings.TrimPrefix(result.OID, ".")
+		allCounters[normalizedOID] = parsed
+		data.Counters[normalizedOID] = parsed
 	}
 
+	// prtMarkerLifeCount.<dispositivo>.<marcador>: una fila por motor de marcado
+	markers := profile.ParseMarkers(results)
+
 	// Usar el perfil si está disponible para mapeo más preciso
 	if prof != nil && len(prof.OIDs["counters"]) > 0 {
-		collectCountersFromProfile(data, client, prof)
+		collectCountersFromProfile(ctx, data, client, prof, markers)
 	} else {
-		// Fallback: mapeo basado en patrones y valores
-		mapCountersFromWalk(data, allCounters)
+		// Fallback: marcadores y clasificación de lo que devolvió el WALK
+		mapCountersFromWalk(ctx, data, client, allCounters, markers)
 	}
 
 	// Asegurar que al menos intentamos vendor-specific
 	if len(data.NormalizedCounters) == 0 || data.NormalizedCounters["total_pages"] == nil {
-		collectCountersVendorSpecific(data, client)
+		collectCountersVendorSpecific(ctx, data, client, markers)
 	}
 
-	// Fallback final: si total_pages no existe o es sospechoso, usar page_count
+	// Medidores propietarios con nombre (B/N, color, escaneo, tamaño grande)
+	collectVendorMeters(data, client)
+
+	// Fallback final: si total_pages no existe o es un centinela, usar page_count
 	pageCount := getPageCountFromStatus(data.Status)
 	totalPages, hasTotal := data.NormalizedCounters["total_pages"]
-	if !hasTotal || totalPages == nil || isSuspiciousValue(toInt64(totalPages)) {
+	if hasTotal && isSentinelValue(toInt64(totalPages)) {
+		data.flagAnomaly(CounterAnomaly{Counter: "total_pages", Value: toInt64(totalPages), Reason: AnomalySentinel})
+	}
+	if !hasTotal || totalPages == nil || isSentinelValue(toInt64(totalPages)) {
 		if pageCount > 0 {
-			data.NormalizedCounters["total_pages"] = pageCount
+			setCounter(data, "total_pages", pageCount, CounterSourcePageCount)
 			fmt.Printf("[DEBUG_COUNTER] Using page_count (%d) as total_pages (original was suspicious)\n", pageCount)
 		}
 	}
@@ -581,126 +930,201 @@ func (dc *DataCollector) collectCounters(data *PrinterData, client *snmp.SNMPCli
 	}
 }
 
-// mapCountersFromWalk mapea contadores del WALK basándose en valores y patrones
-func mapCountersFromWalk(data *PrinterData, allCounters map[string]int64) {
-	// Estrategia: encontrar el valor más alto (probablemente total_pages)
-	// y luego mapear el resto según lógica
+// setMarkerCounters asigna los contadores deducidos de prtMarkerTable (ver
+// profile.MarkerCounters); pisan a la heurística pero no al perfil ni al fabricante
+func setMarkerCounters(data *PrinterData, markers []profile.MarkerRow) {
+	for name, value := range profile.MarkerCounters(markers) {
+		if canClassify(data, name) {
+			setCounter(data, name, value, CounterSourceMarker)
+		}
+	}
+}
 
-	var maxVal int64 = 0
-	var maxOID string
-	var secondMaxVal int64 = 0
-	var secondMaxOID string
+// mapCountersFromWalk mapea contadores del WALK: primero prtMarkerTable y, si
+// no alcanza, la clasificación de los demás OIDs (ver classifyCounters)
+func mapCountersFromWalk(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, allCounters map[string]int64, markers []profile.MarkerRow) {
+	setMarkerCounters(data, markers)
 
+	// Las filas de prtMarkerTable ya se interpretaron (unidades, colorantes, longitudes)
+	var candidates []counterCandidate
 	for oid, val := range allCounters {
-		if val > maxVal {
-			// Mover max actual a secondMax
-			secondMaxVal = maxVal
-			secondMaxOID = maxOID
-			// Nuevo max
-			maxVal = val
-			maxOID = oid
-		} else if val > secondMaxVal && val != maxVal {
-			secondMaxVal = val
-			secondMaxOID = oid
+		if !profile.IsMarkerOID(oid) {
+			candidates = append(candidates, counterCandidate{oid: oid, value: val, rank: 1})
 		}
 	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].oid < candidates[j].oid })
+	classifyCounters(ctx, data, client, candidates, markers)
 
-	// Mapeo simple: el valor más alto es total_pages
-	if maxVal > 0 {
-		data.NormalizedCounters["total_pages"] = maxVal
+	// Valores pequeños sin nombre quedan como contadores genéricos
+	for _, c := range candidates {
+		if c.value > 0 && c.value < 10000 && toInt64(data.NormalizedCounters["total_pages"]) != c.value {
+			counterKey := fmt.Sprintf("counter_%s", strings.ReplaceAll(c.oid, ".", "_"))
+			data.NormalizedCounters[counterKey] = c.value
+		}
 	}
+}
+
+// collectCountersFromProfile extrae contadores usando el perfil descubierto
+// Los OIDs con nombre explícito en CounterMappings se asignan tal cual; solo los
+// no mapeados pasan por la heurística de consistencia y valor descendente
+func collectCountersFromProfile(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, prof *profile.Profile, markers []profile.MarkerRow) {
+	snmpCtx := snmp.NewContext()
 
-	// El segundo valor más alto probablemente sea color_pages o mono_pages
-	if secondMaxVal > 0 && secondMaxVal != maxVal {
-		data.NormalizedCounters["color_pages"] = secondMaxVal
+	if len(prof.OIDs["counters"]) == 0 {
+		return
 	}
 
-	// Intentar encontrar otros contadores por patrón de OID o valor
-	for oid, val := range allCounters {
-		if val == maxVal || val == secondMaxVal {
-			continue // Ya asignados
+	// Los OIDs mapeados se consultan aunque no hayan quedado en OIDs["counters"]
+	// (plantillas editadas a mano). Los mapeos de perfiles antiguos que tomaban
+	// los marcadores de prtMarkerLifeCount como mono/color/scan/copy/fax se ignoran
+	mappings := make(map[string]string, len(prof.CounterMappings))
+	for oid, name := range prof.CounterMappings {
+		if !profile.IsLegacyMarkerMapping(oid, name) {
+			mappings[oid] = name
 		}
-		if val > 0 && val < 10000 { // Valores pequeños probablemente sean scan/copy/fax
-			// Guardar como counter genérico
-			counterKey := fmt.Sprintf("counter_%s", strings.ReplaceAll(oid, ".", "_"))
-			data.NormalizedCounters[counterKey] = val
+	}
+	// El resto de prtMarkerTable no pasa por la heurística: lo interpreta setMarkerCounters
+	var vendorOIDs []string
+	known := make(map[string]bool)
+	for _, oid := range prof.OIDs["counters"] {
+		if _, mapped := mappings[oid]; profile.IsMarkerOID(oid) && !mapped {
+			continue
+		}
+		vendorOIDs = append(vendorOIDs, oid)
+		known[oid] = true
+	}
+	for oid := range mappings {
+		if !known[oid] {
+			vendorOIDs = append(vendorOIDs, oid)
 		}
 	}
-}
-
-// collectCountersFromProfile extrae contadores usando el perfil descubierto
-func collectCountersFromProfile(data *PrinterData, client *snmp.SNMPClient, prof *profile.Profile) {
-	ctx := snmp.NewContext()
-
-	vendorOIDs := prof.OIDs["counters"]
 	if len(vendorOIDs) == 0 {
 		return
 	}
 
 	// Para cada OID en el perfil, obtener su valor
-	results, err := client.GetMultiple(vendorOIDs, ctx)
+	results, err := client.GetMultiple(vendorOIDs, snmpCtx)
 	if err != nil {
 		return
 	}
 
-	// Recolectar todos los valores con sus índices
-	type counterValue struct {
-		idx   int
-		oid   string
-		value int64
-	}
-
-	var allValues []counterValue
-
-	for i, oid := range vendorOIDs {
+	// Primero el mapeo explícito del perfil: no depende del tamaño del valor
+	// (en un equipo B/N muy usado el mono supera a cualquier otro contador)
+	mapped := make(map[string]bool)
+	var candidates []counterCandidate
+	for _, oid := range vendorOIDs {
 		val, exists := results[oid]
 		if !exists || val == nil {
 			continue
 		}
 
 		valStr := strings.TrimSpace(fmt.Sprintf("%v", val))
-		if intVal, err := strconv.ParseInt(valStr, 10, 64); err == nil && intVal > 0 && intVal <= 3_000_000_000 {
-			// IMPORTANTE: Filtrar valores sospechosos AQUÍ también
-			if isSuspiciousValue(intVal) {
-				continue
-			}
-			allValues = append(allValues, counterValue{idx: i, oid: oid, value: intVal})
+		intVal, err := strconv.ParseInt(valStr, 10, 64)
+		if err != nil || intVal <= 0 || intVal > 3_000_000_000 {
+			continue
+		}
+		// Un centinela ganaría el orden por valor: se informa y queda fuera del mapeo
+		if isSentinelValue(intVal) {
+			data.flagAnomaly(CounterAnomaly{Counter: oid, Value: intVal, Reason: AnomalySentinel})
+			continue
 		}
+
+		name, ok := mappings[oid]
+		if !ok || name == "" {
+			candidates = append(candidates, counterCandidate{oid: oid, value: intVal, rank: consistencyRank(prof, oid)})
+			continue
+		}
+		if mapped[name] {
+			continue // Dos OIDs con el mismo nombre: gana el primero del perfil
+		}
+		mapped[name] = true
+		setCounter(data, name, intVal, CounterSourceProfile)
 	}
 
-	// Mapeo simple: por índice o por valor
-	// Asumir que los primeros OIDs significativos corresponden a: total, mono, color, scan, copy, fax
-	counterNames := []string{"total_pages", "mono_pages", "color_pages", "scan_pages", "copy_pages", "fax_pages"}
+	// Después los marcadores y, para los OIDs sin mapeo, la clasificación
+	setMarkerCounters(data, markers)
+	classifyCounters(ctx, data, client, candidates, markers)
+}
 
-	// Si encontramos el patrón correcto: valor grande es total_pages
-	// Reordenar para que el más grande sea primero
-	for i := 0; i < len(allValues); i++ {
-		for j := i + 1; j < len(allValues); j++ {
-			if allValues[j].value > allValues[i].value {
-				allValues[i], allValues[j] = allValues[j], allValues[i]
-			}
-		}
+// setCounter asigna un contador normalizado y registra de dónde salió, con la
+// confianza por defecto de ese origen
+func setCounter(data *PrinterData, name string, value int64, source string) {
+	setCounterConfidence(data, name, value, source, sourceConfidence[source])
+}
+
+// setCounterConfidence asigna un contador con una confianza calculada
+func setCounterConfidence(data *PrinterData, name string, value int64, source string, confidence float64) {
+	data.NormalizedCounters[name] = value
+	if data.CounterSources == nil {
+		data.CounterSources = make(map[string]string)
 	}
+	data.CounterSources[name] = source
+	if data.CounterConfidence == nil {
+		data.CounterConfidence = make(map[string]float64)
+	}
+	data.CounterConfidence[name] = confidence
+}
 
-	// Asignar en orden de valor descendente
-	for i, cv := range allValues {
-		if i >= len(counterNames) {
-			break
-		}
-		data.NormalizedCounters[counterNames[i]] = cv.value
+// consistencyRank ordena OIDs del perfil por la validación del discovery:
+// 2 = lecturas estables, 1 = sin validar (perfiles antiguos), 0 = inconsistente
+func consistencyRank(prof *profile.Profile, oid string) int {
+	metadata, ok := prof.OIDMetadata[oid]
+	switch {
+	case !ok || metadata.Samples == 0:
+		return 1
+	case metadata.Consistent:
+		return 2
+	default:
+		return 0
 	}
 }
 
 // collectCountersVendorSpecific intenta extraer contadores de OIDs específicos por fabricante
-func collectCountersVendorSpecific(data *PrinterData, client *snmp.SNMPClient) {
-	ctx := snmp.NewContext()
+func collectCountersVendorSpecific(ctx context.Context, data *PrinterData, client *snmp.SNMPClient, markers []profile.MarkerRow) {
+	snmpCtx := snmp.NewContext()
 
-	var vendorOIDs []string
+	vendorOIDs := vendorCounterOIDs(data.Brand)
+	if len(vendorOIDs) == 0 {
+		return
+	}
 
-	switch data.Brand {
+	results, err := client.GetMultiple(vendorOIDs, snmpCtx)
+	if err != nil {
+		return
+	}
+
+	// Las listas siguen el orden total, mono, color, scan, copy, fax
+	counterNames := []string{"total_pages", "mono_pages", "color_pages", "scan_pages", "copy_pages", "fax_pages"}
+
+	var candidates []counterCandidate
+	for i, oid := range vendorOIDs {
+		if i >= len(counterNames) {
+			break
+		}
+
+		val, exists := results[oid]
+		if !exists || val == nil {
+			continue
+		}
+
+		intVal, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprintf("%v", val)), 10, 64)
+		// Filtrar ceros y overflow
+		if err != nil || intVal <= 0 || intVal > 3_000_000_000 {
+			continue
+		}
+		candidates = append(candidates, counterCandidate{oid: oid, value: intVal, hint: counterNames[i], rank: 1})
+	}
+
+	classifyCounters(ctx, data, client, candidates, markers)
+}
+
+// vendorCounterOIDs retorna los OIDs propietarios de contadores de una marca
+// (nil si no hay OIDs conocidos para esa marca)
+func vendorCounterOIDs(brand string) []string {
+	switch brand {
 	case "Samsung":
 		// Samsung OIDs específicos
-		vendorOIDs = []string{
+		return []string{
 			"1.3.6.1.4.1.236.11.5.1.1.1.1",  // total
 			"1.3.6.1.4.1.236.11.5.1.1.1.4",  // mono
 			"1.3.6.1.4.1.236.11.5.1.1.1.26", // color
@@ -708,14 +1132,14 @@ func collectCountersVendorSpecific(data *PrinterData, client *snmp.SNMPClient) {
 		}
 	case "HP":
 		// HP OIDs específicos
-		vendorOIDs = []string{
+		return []string{
 			"1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.1", // total
 			"1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.2", // mono
 			"1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.3", // color
 		}
 	case "Xerox":
 		// Xerox OIDs específicos
-		vendorOIDs = []string{
+		return []string{
 			"1.3.6.1.4.1.253.8.53.3.2.1.1.1", // total pages
 			"1.3.6.1.4.1.253.8.53.3.2.1.2.1", // mono pages
 			"1.3.6.1.4.1.253.8.53.3.2.1.3.1", // color pages
@@ -723,87 +1147,132 @@ func collectCountersVendorSpecific(data *PrinterData, client *snmp.SNMPClient) {
 			"1.3.6.1.4.1.253.8.53.3.2.1.5.1", // copy pages
 			"1.3.6.1.4.1.253.8.53.3.2.1.6.1", // fax pages
 		}
+	case "Lexmark":
+		// Lexmark OIDs específicos (LEXMARK-MPS-MIB)
+		return []string{
+			"1.3.6.1.4.1.641.6.4.2.1.1.4.1.1", // total
+			"1.3.6.1.4.1.641.6.4.2.1.1.4.1.2", // mono
+			"1.3.6.1.4.1.641.6.4.2.1.1.4.1.3", // color
+		}
+	case "Epson":
+		// Epson WorkForce OIDs específicos
+		return []string{
+			"1.3.6.1.4.1.1248.1.2.2.27.1.1.3.1.1", // total
+			"1.3.6.1.4.1.1248.1.2.2.27.1.1.4.1.1", // mono
+			"1.3.6.1.4.1.1248.1.2.2.27.1.1.5.1.1", // color
+			"1.3.6.1.4.1.1248.1.2.2.27.1.1.6.1.1", // scan
+		}
+	case "Sharp":
+		// Sharp MX OIDs específicos
+		return []string{
+			"1.3.6.1.4.1.2385.1.1.19.2.1.3.5.4.60", // total
+			"1.3.6.1.4.1.2385.1.1.19.2.1.3.5.4.61", // mono
+			"1.3.6.1.4.1.2385.1.1.19.2.1.3.5.4.62", // color
+			"1.3.6.1.4.1.2385.1.1.19.2.1.3.5.4.63", // scan
+			"1.3.6.1.4.1.2385.1.1.19.2.1.3.5.4.64", // copy
+		}
+	case "KonicaMinolta":
+		// Konica Minolta bizhub OIDs específicos
+		return []string{
+			"1.3.6.1.4.1.18334.1.1.1.5.7.2.1.1.0",     // total
+			"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.1.1", // mono
+			"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.2.1", // color
+			"1.3.6.1.4.1.18334.1.1.1.5.7.2.3.1.5.1.1", // scan
+			"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.1.2", // copy
+		}
+	case "Toshiba":
+		// Toshiba e-STUDIO OIDs específicos
+		return []string{
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.1", // total
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.2", // mono
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.3", // color
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.4", // scan
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.5", // copy
+			"1.3.6.1.4.1.1129.2.3.50.1.3.21.6.1.2.1.6", // fax
+		}
 	}
+	return nil
+}
 
-	if len(vendorOIDs) == 0 {
-		return
-	}
+// vendorMeter es un contador propietario con nombre conocido
+// A diferencia de vendorCounterOIDs no se asigna por tamaño del valor
+type vendorMeter struct {
+	oid     string
+	counter string
+}
 
-	results, err := client.GetMultiple(vendorOIDs, ctx)
-	if err != nil {
+// vendorMeters son los medidores propietarios con significado documentado
+// Pisan a los contadores deducidos por heurística (RFC 3805 solo da el total)
+var vendorMeters = map[string][]vendorMeter{
+	"HP": {
+		{"1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.2.22.0", "duplex_pages"}, // duplex-page-count
+	},
+	"Xerox": {
+		{"1.3.6.1.4.1.253.8.53.13.2.1.6.1.20.34", "duplex_pages"}, // Hojas a doble cara
+		{"1.3.6.1.4.1.253.8.53.13.2.1.6.1.20.44", "a3_pages"},     // Impresiones tamaño grande
+	},
+	"KonicaMinolta": {
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.1.1.0", "total_pages"},      // Total general
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.1.1", "mono_pages"},   // Impresión B/N
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.2.1", "color_pages"},  // Impresión color
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.3.1.5.1.1", "scan_pages"},   // Escaneos
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.1.2", "copy_pages"},   // Copias B/N
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.5.2.2", "copy_color"},   // Copias color (se suma a copy_pages)
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.7.1.1", "a3_pages"},     // Tamaño grande (A3/Ledger)
+		{"1.3.6.1.4.1.18334.1.1.1.5.7.2.2.1.8.1.1", "duplex_pages"}, // Hojas doble cara
+	},
+}
+
+// collectVendorMeters lee los medidores propietarios de la marca (si los hay)
+// y los asigna por nombre en NormalizedCounters
+func collectVendorMeters(data *PrinterData, client *snmp.SNMPClient) {
+	meters := vendorMeters[data.Brand]
+	if len(meters) == 0 {
 		return
 	}
 
-	counterNames := []string{"total_pages", "mono_pages", "color_pages", "scan_pages", "copy_pages", "fax_pages"}
-
-	// Recolectar valores válidos con sus índices
-	type counterValue struct {
-		idx   int
-		name  string
-		value int64
-		oid   string
+	oids := make([]string, 0, len(meters))
+	for _, m := range meters {
+		oids = append(oids, m.oid)
 	}
 
-	var validValues []counterValue
-
-	// Map OID responses to counter names in order
-	for i, oid := range vendorOIDs {
-		if i >= len(counterNames) {
-			break
-		}
+	results, err := client.GetMultiple(oids, snmp.NewContext())
+	if err != nil {
+		return
+	}
 
-		val, exists := results[oid]
+	values := make(map[string]int64)
+	for _, m := range meters {
+		val, exists := results[m.oid]
 		if !exists || val == nil {
 			continue
 		}
-
-		valStr := strings.TrimSpace(fmt.Sprintf("%v", val))
-		if valStr == "" || valStr == "0" {
+		intVal, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprintf("%v", val)), 10, 64)
+		if err != nil || intVal < 0 || intVal > 3_000_000_000 {
 			continue
 		}
-
-		if intVal, err := strconv.ParseInt(valStr, 10, 64); err == nil && intVal > 0 {
-			// Filtrar overflow
-			if intVal > 3_000_000_000 {
-				continue
-			}
-
-			validValues = append(validValues, counterValue{idx: i, name: counterNames[i], value: intVal, oid: oid})
-		}
+		values[m.counter] = intVal
 	}
 
-	// Ordenar por valor descendente para identificar correctamente
-	for i := 0; i < len(validValues); i++ {
-		for j := i + 1; j < len(validValues); j++ {
-			if validValues[j].value > validValues[i].value {
-				validValues[i], validValues[j] = validValues[j], validValues[i]
-			}
-		}
+	// Las copias a color se reportan aparte: copy_pages es el total de copias
+	if color, ok := values["copy_color"]; ok {
+		values["copy_pages"] += color
+		delete(values, "copy_color")
 	}
 
-	// Asignar: el mayor es total_pages, luego color_pages, etc.
-	for i, cv := range validValues {
-		if i == 0 {
-			// El mayor debe ser total_pages
-			data.NormalizedCounters["total_pages"] = cv.value
-		} else if i == 1 {
-			// Segundo mayor: probablemente color_pages
-			data.NormalizedCounters["color_pages"] = cv.value
-		} else {
-			// El resto por nombre original pero validado
-			data.NormalizedCounters[cv.name] = cv.value
-		}
+	for name, value := range values {
+		setCounter(data, name, value, CounterSourceVendor)
 	}
 }
 
 // collectConsumiblesViaWalk descubre consumibles dinámicamente via WALK
 // Si hay un profile, usa los OIDs descubiertos para extraer datos completos
-func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx *snmp.Context, prof *profile.Profile) map[string]interface{} {
+func (dc *DataCollector) collectConsumiblesViaWalk(walks *WalkCache, ctx *snmp.Context, prof *profile.Profile) map[string]interface{} {
 	consumibles := make(map[string]interface{})
 
 	// Si tenemos un perfil con OIDs de supplies, usar esos directamente para obtener datos completos
 	if prof != nil && len(prof.OIDs["supplies"]) > 0 {
-		return dc.collectSuppliesFromProfile(client, ctx, prof)
+		return dc.collectSuppliesFromProfile(walks, ctx, prof)
 	}
 
 	// Fallback: WALK en múltiples OIDs estándar
@@ -818,7 +1287,7 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 
 	// Intentar WALK en cada OID hasta obtener resultados
 	for _, oid := range oidsToTry {
-		resultsDesc, err = client.Walk(oid, ctx)
+		resultsDesc, err = walks.Walk(oid, ctx)
 		if err == nil && len(resultsDesc) > 0 {
 			break // Encontramos resultados, usar estos
 		}
@@ -830,17 +1299,24 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 	}
 
 	// WALK 2: Obtener niveles actuales (RFC 3805: 1.3.6.1.2.1.43.11.1.1.9)
-	resultsLevel, err := client.Walk("1.3.6.1.2.1.43.11.1.1.9", ctx)
+	resultsLevel, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.9", ctx)
 	if err != nil {
 		resultsLevel = []snmp.WalkResult{}
 	}
 
 	// WALK 3: Obtener máximos (RFC 3805: 1.3.6.1.2.1.43.11.1.1.8)
-	resultsMax, err := client.Walk("1.3.6.1.2.1.43.11.1.1.8", ctx)
+	resultsMax, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.8", ctx)
 	if err != nil {
 		resultsMax = []snmp.WalkResult{}
 	}
 
+	// Tipos y colorantes: identifican tóner y drum de cada color sin depender
+	// del idioma de la descripción
+	supplyTypes := supplyTypeCodes(walks, ctx)
+	colorants := supplyColorants(walks, ctx)
+	classes := supplyColumn(walks, ctx, oidSuppliesClass)
+	units := supplyColumn(walks, ctx, oidSuppliesUnit)
+
 	// Mapeo de descripciones a claves normalizadas
 	consumibleMapping := map[string]string{
 		"black toner":     "tonerBlack",
@@ -857,6 +1333,9 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 		"yellow drum":     "drumYellow",
 		"fuser":           "fusor",
 		"transfer roller": "transferRoller",
+		"transfer belt":   "transferUnit",
+		"transfer kit":    "transferUnit",
+		"maintenance kit": "kitMantenimiento",
 		"waste":           "cajaResiduos",
 		"drum":            "drum",
 	}
@@ -890,15 +1369,20 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 		}
 		index := parts[len(parts)-1]
 
-		// Normalizar descripción
-		normalizedKey := ""
+		// Normalizar: primero por tipo y colorante, si no por descripción
+		normalizedKey := supplyKeyFromColorant(supplyTypes[index], colorants[index])
 		descLower := strings.ToLower(result.Value)
 		for desc, key := range consumibleMapping {
+			if normalizedKey != "" {
+				break
+			}
 			if strings.Contains(descLower, strings.ToLower(desc)) {
 				normalizedKey = key
-				break
 			}
 		}
+		if normalizedKey == "" {
+			normalizedKey = lifePartKey(supplyTypes[index])
+		}
 
 		if normalizedKey != "" {
 			// Construir OIDs de nivel y máximo
@@ -909,11 +1393,16 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 			levelVal := levelMap[levelOID]
 			maxVal := maxMap[maxOID]
 
-			consumibles[normalizedKey] = map[string]interface{}{
+			supplyInfo := map[string]interface{}{
 				"description": result.Value,
 				"level":       levelVal,
 				"max":         maxVal,
 			}
+			if color := colorants[index]; color != "" {
+				supplyInfo["color"] = color
+			}
+			setSupplyLife(supplyInfo, classes[index], units[index], supplyTypes[index], levelVal, maxVal)
+			consumibles[normalizedKey] = supplyInfo
 		}
 	}
 
@@ -922,44 +1411,44 @@ func (dc *DataCollector) collectConsumiblesViaWalk(client *snmp.SNMPClient, ctx
 
 // collectSuppliesFromProfile extrae información COMPLETA de supplies usando OIDs del perfil
 // IMPORTANTE: Se queda con las implementaciones simples de WALK RFC3805
-func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx *snmp.Context, _ *profile.Profile) map[string]interface{} {
+func (dc *DataCollector) collectSuppliesFromProfile(walks *WalkCache, ctx *snmp.Context, prof *profile.Profile) map[string]interface{} {
 	// Para ahora, usar el WALK estándar - es más confiable
 	// Las OIDs del perfil tienen estructura muy compleja y varían por marca
 
 	consumibles := make(map[string]interface{})
 
 	// WALK 1: Obtener descripciones de consumibles (RFC 3805: 1.3.6.1.2.1.43.11.1.1.6)
-	resultsDesc, err := client.Walk("1.3.6.1.2.1.43.11.1.1.6", ctx)
+	resultsDesc, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.6", ctx)
 	if err != nil {
 		return consumibles
 	}
 
 	// WALK 2: Obtener niveles actuales (RFC 3805: 1.3.6.1.2.1.43.11.1.1.9)
-	resultsLevel, err := client.Walk("1.3.6.1.2.1.43.11.1.1.9", ctx)
+	resultsLevel, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.9", ctx)
 	if err != nil {
 		resultsLevel = []snmp.WalkResult{}
 	}
 
 	// WALK 3: Obtener máximos (RFC 3805: 1.3.6.1.2.1.43.11.1.1.8)
-	resultsMax, err := client.Walk("1.3.6.1.2.1.43.11.1.1.8", ctx)
+	resultsMax, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.8", ctx)
 	if err != nil {
 		resultsMax = []snmp.WalkResult{}
 	}
 
 	// WALK 4: Obtener tipos (RFC 3805: 1.3.6.1.2.1.43.11.1.1.2)
-	resultsType, err := client.Walk("1.3.6.1.2.1.43.11.1.1.2", ctx)
+	resultsType, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.2", ctx)
 	if err != nil {
 		resultsType = []snmp.WalkResult{}
 	}
 
 	// WALK 5: Obtener modelos/números de pieza (RFC 3805: 1.3.6.1.2.1.43.11.1.1.4)
-	resultsModel, err := client.Walk("1.3.6.1.2.1.43.11.1.1.4", ctx)
+	resultsModel, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.4", ctx)
 	if err != nil {
 		resultsModel = []snmp.WalkResult{}
 	}
 
 	// WALK 6: Obtener estados (RFC 3805: 1.3.6.1.2.1.43.11.1.1.7)
-	resultsState, err := client.Walk("1.3.6.1.2.1.43.11.1.1.7", ctx)
+	resultsState, err := walks.Walk("1.3.6.1.2.1.43.11.1.1.7", ctx)
 	if err != nil {
 		resultsState = []snmp.WalkResult{}
 	}
@@ -976,6 +1465,9 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 		"yellow drum":     "drumYellow",
 		"fuser":           "fusor",
 		"transfer roller": "transferRoller",
+		"transfer belt":   "transferUnit",
+		"transfer kit":    "transferUnit",
+		"maintenance kit": "kitMantenimiento",
 		"waste":           "cajaResiduos",
 		"drum":            "drum",
 	}
@@ -1008,6 +1500,15 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 		stateMap[normalizedOID] = result.Value
 	}
 
+	// Números de parte propietarios (indexados igual que prtMarkerSuppliesTable)
+	vendorParts := dc.collectVendorSupplyParts(walks, ctx, prof)
+
+	// Tipo y color de cada consumible (independientes del idioma de la descripción)
+	supplyTypes := supplyTypeCodes(walks, ctx)
+	colorants := supplyColorants(walks, ctx)
+	classes := supplyColumn(walks, ctx, oidSuppliesClass)
+	units := supplyColumn(walks, ctx, oidSuppliesUnit)
+
 	// Procesar descripciones
 	for _, result := range resultsDesc {
 		if result.Value == "" {
@@ -1024,16 +1525,22 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 		}
 		index := parts[len(parts)-1]
 
-		// Normalizar descripción
-		normalizedKey := ""
+		// Normalizar: primero por tipo y colorante, si no por descripción
+		normalizedKey := supplyKeyFromColorant(supplyTypes[index], colorants[index])
 		descLower := strings.ToLower(result.Value)
 		for desc, key := range consumibleMapping {
+			if normalizedKey != "" {
+				break
+			}
 			if strings.Contains(descLower, strings.ToLower(desc)) {
 				normalizedKey = key
-				break
 			}
 		}
 
+		if normalizedKey == "" {
+			normalizedKey = lifePartKey(supplyTypes[index])
+		}
+
 		// Si no matchea con mapping conocido, usar la descripción como está
 		if normalizedKey == "" {
 			normalizedKey = strings.ToLower(strings.ReplaceAll(result.Value, " ", "_"))
@@ -1052,6 +1559,13 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 			maxVal := maxMap[maxOID]
 			typeVal := typeMap[typeOID]
 			modelVal := modelMap[modelOID]
+			// Un valor numérico es un código de clase, no un número de parte
+			if _, err := strconv.Atoi(modelVal); err == nil {
+				modelVal = ""
+			}
+			if part := vendorParts[index]; part != "" {
+				modelVal = part
+			}
 			stateVal := stateMap[stateOID]
 
 			supplyInfo := map[string]interface{}{
@@ -1075,6 +1589,10 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 			if stateVal != "" && stateVal != "0" {
 				supplyInfo["state_code"] = stateVal
 			}
+			if color := colorants[index]; color != "" {
+				supplyInfo["color"] = color
+			}
+			setSupplyLife(supplyInfo, classes[index], units[index], supplyTypes[index], levelVal, maxVal)
 
 			// Extraer brand/OEM de la descripción o modelo
 			brand := dc.extractBrandFromSupply(result.Value, modelVal)
@@ -1089,6 +1607,40 @@ func (dc *DataCollector) collectSuppliesFromProfile(client *snmp.SNMPClient, ctx
 	return consumibles
 }
 
+// vendorSupplyPartColumns son las columnas propietarias con el número de parte
+// de cada consumible, indexadas igual que prtMarkerSuppliesTable
+var vendorSupplyPartColumns = map[string]string{
+	"Lexmark": "1.3.6.1.4.1.641.6.4.4.1.1.6.1", // LEXMARK-MPS-MIB: supplyPartNumber
+}
+
+// collectVendorSupplyParts retorna índice de consumible → número de parte
+// para marcas que lo publican en su árbol privado (vacío si no aplica)
+func (dc *DataCollector) collectVendorSupplyParts(walks *WalkCache, ctx *snmp.Context, prof *profile.Profile) map[string]string {
+	parts := make(map[string]string)
+	if prof == nil {
+		return parts
+	}
+
+	column, ok := vendorSupplyPartColumns[prof.Brand]
+	if !ok {
+		return parts
+	}
+
+	results, err := walks.Walk(column, ctx)
+	if err != nil {
+		return parts
+	}
+	for _, result := range results {
+		value := strings.TrimSpace(result.Value)
+		if value == "" {
+			continue
+		}
+		oid := strings.TrimPrefix(result.OID, ".")
+		parts[oid[strings.LastIndex(oid, ".")+1:]] = value
+	}
+	return parts
+}
+
 // mapSupplyTypeToComponentType mapea códigos SNMP de tipo a nombres legibles
 func (dc *DataCollector) mapSupplyTypeToComponentType(typeCode string) string {
 	// Mapeo de RFC 3805 supply types
@@ -1125,9 +1677,18 @@ func (dc *DataCollector) mapSupplyTypeToComponentType(typeCode string) string {
 	return ""
 }
 
+// Números de parte de consumibles por fabricante (en minúsculas)
+var (
+	sharpPartNumber   = regexp.MustCompile(`\bmx-\d{2,3}[a-z]{2}`)              // MX-61NTBA, MX-36GTCA
+	toshibaPartNumber = regexp.MustCompile(`\bt-(fc)?\d{3,4}`)                  // T-FC330U-K, T-4590
+	lexmarkPartNumber = regexp.MustCompile(`^\d{2}[a-z]\d[a-z0-9]{3}$`)         // 58D2H00, 74C2HC0
+	konicaPartNumber  = regexp.MustCompile(`\b(tn|dr|iu|du|wx)-?\d{3}[a-z]?\b`) // TN514K, DR-313, IU-214C
+	epsonPartNumber   = regexp.MustCompile(`\b(c13)?t[0-9]{2}[0-9a-z]{2,4}\b`)  // T9451, C13T01C100
+)
+
 // extractBrandFromSupply intenta detectar la marca/fabricante del consumible
 func (dc *DataCollector) extractBrandFromSupply(description, model string) string {
-	brands := []string{"Samsung", "Canon", "Fujifilm", "Xerox", "HP", "Ricoh", "Konica Minolta", "Sharp", "OKI", "Lexmark"}
+	brands := []string{"Samsung", "Canon", "Fujifilm", "Xerox", "HP", "Ricoh", "Konica Minolta", "Sharp", "OKI", "Lexmark", "Epson", "Toshiba"}
 
 	desc_lower := strings.ToLower(description)
 	model_lower := strings.ToLower(model)
@@ -1146,6 +1707,21 @@ func (dc *DataCollector) extractBrandFromSupply(description, model string) strin
 	if strings.Contains(model_lower, "006r") || strings.Contains(model_lower, "001r") {
 		return "Xerox"
 	}
+	if sharpPartNumber.MatchString(desc_lower) || sharpPartNumber.MatchString(model_lower) {
+		return "Sharp"
+	}
+	if toshibaPartNumber.MatchString(desc_lower) || toshibaPartNumber.MatchString(model_lower) {
+		return "Toshiba"
+	}
+	if konicaPartNumber.MatchString(desc_lower) || konicaPartNumber.MatchString(model_lower) {
+		return "Konica Minolta"
+	}
+	if lexmarkPartNumber.MatchString(model_lower) {
+		return "Lexmark"
+	}
+	if epsonPartNumber.MatchString(desc_lower) || epsonPartNumber.MatchString(model_lower) {
+		return "Epson"
+	}
 	if strings.Contains(model_lower, "ce") || strings.Contains(model_lower, "cf") {
 		return "HP"
 	}
@@ -1154,7 +1730,7 @@ func (dc *DataCollector) extractBrandFromSupply(description, model string) strin
 }
 
 // discoverAdditionalData realiza WALK exhaustivo para descubrir datos adicionales
-func (dc *DataCollector) discoverAdditionalData(data *PrinterData, client *snmp.SNMPClient) {
+func (dc *DataCollector) discoverAdditionalData(data *PrinterData, walks *WalkCache) {
 	type OIDGroup struct {
 		name   string
 		basOID string
@@ -1168,13 +1744,18 @@ func (dc *DataCollector) discoverAdditionalData(data *PrinterData, client *snmp.
 	ctx := snmp.NewContext()
 
 	for _, oidGroup := range oidsToWalk {
-		results, err := client.Walk(oidGroup.basOID, ctx)
+		results, err := walks.Walk(oidGroup.basOID, ctx)
 		if err != nil {
 			continue
 		}
 
 		for _, result := range results {
-			if result.Value == "" || result.Value == "0" || strings.HasPrefix(result.Value, "-") {
+			if result.Value == "" || result.Value == "0" {
+				continue
+			}
+			// Negativos descartados, excepto los centinelas de nivel de consumibles
+			// (-2 desconocido, -3 quedan unidades) que tienen significado propio
+			if strings.HasPrefix(result.Value, "-") && !isSupplyLevelSentinel(result.OID, result.Value) {
 				continue
 			}
 
@@ -1204,6 +1785,14 @@ func (dc *DataCollector) discoverAdditionalData(data *PrinterData, client *snmp.
 	}
 }
 
+// isSupplyLevelSentinel indica si oid/valor es un nivel centinela de prtMarkerSuppliesLevel
+func isSupplyLevelSentinel(oid, value string) bool {
+	if !strings.HasPrefix(strings.TrimPrefix(oid, "."), "1.3.6.1.2.1.43.11.1.1.9.") {
+		return false
+	}
+	return value == "-2" || value == "-3"
+}
+
 // extractPageCountersFromSupplies extrae contadores de página que están en supplies (Xerox, Samsung)
 func (dc *DataCollector) extractPageCountersFromSupplies(data *PrinterData) {
 	if data.Supplies == nil {
@@ -1273,17 +1862,31 @@ func (dc *DataCollector) normalizeSupplies(supplies map[string]interface{}) map[
 				fmt.Sscanf(mx, "%f", &max)
 			}
 
+			desc := ""
+			if d, ok := supplyMap["description"].(string); ok {
+				desc = d
+			}
+
+			// Niveles centinela de RFC 3805 (-2 desconocido, -3 quedan unidades):
+			// no son porcentajes calculables
+			if levelState := SupplyLevelState(int64(level)); levelState != LevelStateKnown {
+				normalized[name] = map[string]interface{}{
+					"description": desc,
+					"level":       level,
+					"max":         max,
+					"percentage":  "N/A",
+					"status":      getSentinelSupplyStatus(levelState),
+					"level_state": levelState,
+				}
+				continue
+			}
+
 			// Calcular porcentaje
 			var percentage float64
 			if max > 0 {
 				percentage = (level / max) * 100
 			}
 
-			desc := ""
-			if d, ok := supplyMap["description"].(string); ok {
-				desc = d
-			}
-
 			normalized[name] = map[string]interface{}{
 				"description": desc,
 				"level":       level,
@@ -1297,33 +1900,42 @@ func (dc *DataCollector) normalizeSupplies(supplies map[string]interface{}) map[
 	return normalized
 }
 
-// getSupplyStatus retorna el estado legible de un consumible
+// getSupplyStatus retorna el estado legible de un consumible (en el idioma activo)
 func getSupplyStatus(percentage float64) string {
 	if percentage >= 75 {
-		return "OK"
+		return i18n.T("supply.status.ok")
 	} else if percentage >= 50 {
-		return "Bueno"
+		return i18n.T("supply.status.good")
 	} else if percentage >= 25 {
-		return "Bajo"
+		return i18n.T("supply.status.low")
 	} else if percentage >= 10 {
-		return "Crítico"
+		return i18n.T("supply.status.critical")
 	} else {
-		return "Agotado"
+		return i18n.T("supply.status.empty")
+	}
+}
+
+// getSentinelSupplyStatus retorna el estado legible para niveles centinela
+func getSentinelSupplyStatus(levelState string) string {
+	if levelState == LevelStateSomeRemaining {
+		return i18n.T("supply.status.some_remaining")
 	}
+	return i18n.T("supply.status.unknown")
 }
 
 // normalizeCounters convierte contadores a formato legible
 func (dc *DataCollector) normalizeCounters(counters map[string]interface{}) map[string]interface{} {
 	normalized := make(map[string]interface{})
 
+	// Nombres legibles en el idioma activo (ver pkg/i18n)
 	counterNames := map[string]string{
-		"totalPages":       "Páginas Totales",
-		"colorPages":       "Páginas a Color",
-		"monochromedPages": "Páginas Monocromáticas",
-		"printedPages":     "Páginas Impresas",
-		"copiedPages":      "Páginas Copiadas",
-		"scannedPages":     "Páginas Escaneadas",
-		"faxedPages":       "Páginas Faxeadas",
+		"totalPages":       i18n.T("counter.totalPages"),
+		"colorPages":       i18n.T("counter.colorPages"),
+		"monochromedPages": i18n.T("counter.monochromedPages"),
+		"printedPages":     i18n.T("counter.printedPages"),
+		"copiedPages":      i18n.T("counter.copiedPages"),
+		"scannedPages":     i18n.T("counter.scannedPages"),
+		"faxedPages":       i18n.T("counter.faxedPages"),
 	}
 
 	for key, val := range counters {
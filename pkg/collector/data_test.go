@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+)
+
+// TestCollectFixtures verifica lo que toda recolección de un fixture debe
+// cumplir, más allá del detalle de los esperados de TestGolden
+func TestCollectFixtures(t *testing.T) {
+	for _, name := range simulator.BuiltinNames() {
+		t.Run(name, func(t *testing.T) {
+			fixture, err := simulator.Builtin(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := collectFixture(t, name)
+
+			if data.Brand != fixture.Brand {
+				t.Errorf("marca %q, el fixture es %q", data.Brand, fixture.Brand)
+			}
+			if data.Partial || len(data.Errors) > 0 || len(data.MissingSections) > 0 {
+				t.Errorf("recolección incompleta: errores %v, secciones faltantes %v", data.Errors, data.MissingSections)
+			}
+
+			counters := data.PageCounters
+			if counters.TotalPages <= 0 {
+				t.Errorf("total_pages = %d", counters.TotalPages)
+			}
+			if counters.MonoPages > counters.TotalPages || counters.ColorPages > counters.TotalPages {
+				t.Errorf("parciales mayores al total: %+v", counters)
+			}
+			if got := toInt64(data.NormalizedCounters["total_pages"]); got != counters.TotalPages {
+				t.Errorf("normalized_counters.total_pages = %d, page_counters.total_pages = %d", got, counters.TotalPages)
+			}
+
+			if len(data.SupplyList) == 0 {
+				t.Fatal("sin consumibles")
+			}
+			for _, s := range data.SupplyList {
+				if s.Percentage < 0 || s.Percentage > 100 {
+					t.Errorf("%s: porcentaje %d", s.Key, s.Percentage)
+				}
+				if s.LevelState != "known" && s.Percentage != 0 {
+					t.Errorf("%s: nivel %s con porcentaje %d", s.Key, s.LevelState, s.Percentage)
+				}
+				if _, ok := data.NormalizedSupplies[s.Key]; !ok {
+					t.Errorf("%s: falta en normalized_supplies", s.Key)
+				}
+			}
+		})
+	}
+}
+
+// TestCollectWithLearnedProfile: la segunda recolección usa el perfil que
+// aprendió la primera y normaliza igual
+func TestCollectWithLearnedProfile(t *testing.T) {
+	for _, name := range []string{"hp_laserjet_m402", "xerox_altalink_c8055", "samsung_m332x"} {
+		t.Run(name, func(t *testing.T) {
+			dc := newTestCollector(t)
+			device := startFixture(t, name)
+
+			first := collectDevice(t, dc, device)
+			second := collectDevice(t, dc, device)
+
+			if !reflect.DeepEqual(first.PageCounters, second.PageCounters) {
+				t.Errorf("contadores distintos con perfil: %+v, sin perfil %+v", second.PageCounters, first.PageCounters)
+			}
+			if !reflect.DeepEqual(first.NormalizedSupplies, second.NormalizedSupplies) {
+				t.Errorf("consumibles distintos con perfil: %v, sin perfil %v", second.NormalizedSupplies, first.NormalizedSupplies)
+			}
+			if first.Info.SerialNumber != second.Info.SerialNumber || first.Info.Model != second.Info.Model {
+				t.Errorf("identificación distinta con perfil: %+v, sin perfil %+v", second.Info, first.Info)
+			}
+		})
+	}
+}
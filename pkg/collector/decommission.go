@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// archiveDir guarda lo que queda de las impresoras dadas de baja:
+// state/archive/<clave>/ con su entrada de inventario, estado e historial
+const archiveDir = "archive"
+
+// DecommissionInventory da de baja las impresoras que no responden desde
+// cutoff: las marca en inventory y retorna un cambio por cada una
+// Las que ya estaban dadas de baja no se repiten
+func DecommissionInventory(inventory map[string]InventoryEntry, cutoff, now time.Time) []InventoryChange {
+	var changes []InventoryChange
+	for id, entry := range inventory {
+		if entry.Online || entry.Decommissioned || !entry.LastSeen.Before(cutoff) {
+			continue
+		}
+		prev := entry
+		at := now.UTC()
+		entry.Decommissioned = true
+		entry.DecommissionedAt = &at
+		inventory[id] = entry
+
+		cur := entry
+		changes = append(changes, InventoryChange{Change: ChangeDecommissioned, Previous: &prev, Current: &cur})
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// ArchiveDir retorna el directorio de archivo de una impresora
+func (sm *StateManager) ArchiveDir(printerKey string) string {
+	return filepath.Join(sm.stateDir, archiveDir, sanitizeKey(printerKey))
+}
+
+// ArchivePrinter mueve el estado y el historial de una impresora dada de baja
+// a su directorio de archivo, junto con su entrada de inventario
+// Si vuelve a aparecer, su primer poll es una lectura base (sin delta)
+func (sm *StateManager) ArchivePrinter(entry InventoryEntry) (string, error) {
+	dir := sm.ArchiveDir(entry.PrinterID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dir, err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return dir, err
+	}
+	if err := fsutil.WriteFileAtomic(filepath.Join(dir, "inventory.json"), data, 0644); err != nil {
+		return dir, err
+	}
+
+	key := sanitizeKey(entry.PrinterID)
+	moves := map[string]string{
+		sm.getStateFilename(entry.PrinterID):                        "state.json",
+		filepath.Join(sm.stateDir, historyDir, key+historyPollsExt): "history" + historyPollsExt,
+		filepath.Join(sm.stateDir, historyDir, key+historyDailyExt): "history" + historyDailyExt,
+	}
+	for src, name := range moves {
+		if err := os.Rename(src, filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return dir, err
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// duplicatesFile guarda las impresoras multi-homed (Ethernet + Wi-Fi) que
+// responden en más de una IP: cada interfaz tiene su MAC y por lo tanto su
+// propio ID canónico, pero es un solo equipo con un solo contador
+const duplicatesFile = "_duplicates.json"
+
+// minSerialLength descarta series de relleno ("0", "N/A") al buscar duplicados
+const minSerialLength = 4
+
+// DuplicateIndex reconoce la misma impresora respondiendo en varias IPs y la
+// reduce a una impresora lógica: el primer ID con el que se la vio
+// Las coincidencias se buscan dentro del ciclo (por serie con la misma marca,
+// o por la MAC consultada dentro de la ifTable de otra); los alias quedan
+// guardados para los ciclos siguientes
+// No es seguro para uso concurrente: se usa desde el loop de emisión
+type DuplicateIndex struct {
+	Aliases   map[string]string   `json:"aliases"`   // ID de la interfaz secundaria → ID lógico
+	Addresses map[string][]string `json:"addresses"` // ID lógico → IPs en que responde (la primera es la que se consulta)
+
+	bySerial map[string]string // marca/serie → ID lógico visto en este ciclo
+	byMAC    map[string]string // MAC de cualquier interfaz → ID lógico visto en este ciclo
+	seen     map[string]string // ID lógico → IP que lo reportó en este ciclo
+	merged   []string          // IDs que pasaron a ser alias en este ciclo
+}
+
+// LoadDuplicateIndex carga los duplicados conocidos (vacío si no hay)
+func (sm *StateManager) LoadDuplicateIndex() *DuplicateIndex {
+	d := &DuplicateIndex{}
+	if data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, duplicatesFile)); err == nil {
+		json.Unmarshal(data, d)
+	}
+	if d.Aliases == nil {
+		d.Aliases = make(map[string]string)
+	}
+	if d.Addresses == nil {
+		d.Addresses = make(map[string][]string)
+	}
+	d.bySerial = make(map[string]string)
+	d.byMAC = make(map[string]string)
+	d.seen = make(map[string]string)
+	return d
+}
+
+// SaveDuplicateIndex guarda los duplicados conocidos (se sobrescribe)
+func (sm *StateManager) SaveDuplicateIndex(d *DuplicateIndex) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, duplicatesFile), data, 0644)
+}
+
+// Secondary indica si ip es una dirección secundaria de una impresora
+// lógica cuya dirección principal también está en ips: no hace falta
+// consultarla (se consulta la principal)
+func (d *DuplicateIndex) Secondary(ip string, ips map[string]bool) bool {
+	for _, addresses := range d.Addresses {
+		if len(addresses) < 2 || addresses[0] == ip || !ips[addresses[0]] {
+			continue
+		}
+		for _, address := range addresses[1:] {
+			if address == ip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve lleva data a su impresora lógica: reescribe PrinterID con el ID
+// lógico y, si es multi-homed, completa Addresses. Retorna la IP que ya
+// reportó esa impresora si data es un duplicado dentro de este ciclo ("" si no)
+func (d *DuplicateIndex) Resolve(data *PrinterData) string {
+	if data.PrinterID == "" || data.PrinterID == data.IP {
+		return "" // sin MAC ni serie no hay con qué comparar
+	}
+
+	id := data.PrinterID
+	if logical, ok := d.Aliases[id]; ok {
+		id = logical
+	} else if logical := d.match(data); logical != "" && logical != id {
+		d.Aliases[id] = logical
+		d.merged = append(d.merged, id)
+		id = logical
+	}
+	data.PrinterID = id
+
+	if ip, ok := d.seen[id]; ok && ip != data.IP {
+		d.addAddress(id, ip)
+		d.addAddress(id, data.IP)
+		return ip
+	}
+	d.seen[id] = data.IP
+	if serial := serialKey(data); serial != "" {
+		if _, taken := d.bySerial[serial]; !taken {
+			d.bySerial[serial] = id
+		}
+	}
+	for _, mac := range interfaceMACs(data) {
+		if _, taken := d.byMAC[mac]; !taken {
+			d.byMAC[mac] = id
+		}
+	}
+	if _, multiHomed := d.Addresses[id]; multiHomed {
+		data.Addresses = d.addAddress(id, data.IP)
+	}
+	return ""
+}
+
+// Merged retorna los IDs que pasaron a ser alias en este ciclo (ya no son
+// impresoras propias: se quitan del inventario)
+func (d *DuplicateIndex) Merged() []string {
+	return d.merged
+}
+
+// match busca una impresora de este ciclo con la misma serie y marca, o que
+// comparte la MAC de alguna interfaz con data
+func (d *DuplicateIndex) match(data *PrinterData) string {
+	if serial := serialKey(data); serial != "" {
+		if id, ok := d.bySerial[serial]; ok {
+			return id
+		}
+	}
+	for _, mac := range interfaceMACs(data) {
+		if id, ok := d.byMAC[mac]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// addAddress agrega ip a las direcciones de la impresora lógica
+func (d *DuplicateIndex) addAddress(id, ip string) []string {
+	addresses := d.Addresses[id]
+	for _, address := range addresses {
+		if address == ip {
+			return addresses
+		}
+	}
+	addresses = append(addresses, ip)
+	d.Addresses[id] = addresses
+	return addresses
+}
+
+// serialKey es la marca y la serie de data ("" si la serie no sirve para comparar)
+func serialKey(data *PrinterData) string {
+	serial := strings.ToLower(strings.TrimSpace(data.Info.SerialNumber))
+	if len(serial) < minSerialLength {
+		return ""
+	}
+	return strings.ToLower(data.Brand) + "/" + serial
+}
+
+// interfaceMACs retorna las MACs de la interfaz consultada y de las demás
+// interfaces físicas de la ifTable, sin separadores y ordenadas
+func interfaceMACs(data *PrinterData) []string {
+	set := map[string]bool{}
+	if mac := normalizeMAC(data.Network.MACAddress); mac != "" {
+		set[mac] = true
+	}
+	for _, iface := range data.Network.PhysicalInterfaces() {
+		if mac := normalizeMAC(iface.MACAddress); mac != "" {
+			set[mac] = true
+		}
+	}
+	macs := make([]string, 0, len(set))
+	for mac := range set {
+		macs = append(macs, mac)
+	}
+	sort.Strings(macs)
+	return macs
+}
+
+// normalizeMAC retorna la MAC sin separadores ("" si es vacía o todo ceros)
+func normalizeMAC(mac string) string {
+	clean := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(mac)))
+	if len(clean) < 12 || strings.Trim(clean, "0") == "" {
+		return ""
+	}
+	return clean
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// update reescribe los esperados: go test ./pkg/collector -run Golden -update
+var update = flag.Bool("update", false, "reescribir testdata/golden con los resultados actuales")
+
+// goldenSnapshot es lo que se compara de cada fixture: el resultado de la
+// normalización, sin los campos que cambian entre corridas (uptime, IP)
+type goldenSnapshot struct {
+	Fixture            string                 `json:"fixture"`
+	Brand              string                 `json:"brand"`
+	Identification     Identification         `json:"identification"`
+	Status             Status                 `json:"status"`
+	Network            Network                `json:"network"`
+	Counters           CountersInfo           `json:"counters"`
+	CounterSources     map[string]string      `json:"counter_sources,omitempty"`
+	CounterConfidence  map[string]float64     `json:"counter_confidence,omitempty"`
+	Supplies           []Supply               `json:"supplies"`
+	Capabilities       Capabilities           `json:"capabilities"`
+	NormalizedCounters map[string]interface{} `json:"normalized_counters,omitempty"`
+	NormalizedSupplies map[string]interface{} `json:"normalized_supplies,omitempty"`
+	Anomalies          []CounterAnomaly       `json:"anomalies,omitempty"`
+	MissingSections    []string               `json:"missing_sections,omitempty"`
+}
+
+// TestGolden corre cada fixture del simulador por la recolección y la
+// normalización y compara con testdata/golden/<fixture>.json: un cambio de
+// OIDs o perfiles que altere la normalización de una marca falla acá
+func TestGolden(t *testing.T) {
+	for _, name := range simulator.BuiltinNames() {
+		t.Run(name, func(t *testing.T) {
+			data := collectFixture(t, name)
+
+			status := data.State
+			status.UptimeSeconds = 0
+			network := data.Network
+			network.IPAddress = ""
+			got, err := json.MarshalIndent(goldenSnapshot{
+				Fixture:            name,
+				Brand:              data.Brand,
+				Identification:     data.Info,
+				Status:             status,
+				Network:            network,
+				Counters:           data.PageCounters,
+				CounterSources:     data.CounterSources,
+				CounterConfidence:  data.CounterConfidence,
+				Supplies:           data.SupplyList,
+				Capabilities:       data.Capabilities,
+				NormalizedCounters: data.NormalizedCounters,
+				NormalizedSupplies: data.NormalizedSupplies,
+				Anomalies:          data.CounterAnomalies,
+				MissingSections:    data.MissingSections,
+			}, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("falta el esperado (generarlo con -update): %v", err)
+			}
+			if !bytes.Equal(want, got) {
+				t.Errorf("la normalización cambió respecto de %s (si el cambio es correcto, correr con -update):\n%s", path, lineDiff(want, got))
+			}
+		})
+	}
+}
+
+// collectFixture levanta el simulador de un fixture incluido y lo recolecta
+// con un colector sin estado (primer contacto, sin perfiles aprendidos)
+func collectFixture(t *testing.T, name string) *PrinterData {
+	t.Helper()
+	return collectDevice(t, newTestCollector(t), startFixture(t, name))
+}
+
+// newTestCollector crea un colector con perfiles en un directorio temporal
+func newTestCollector(t *testing.T) *DataCollector {
+	return NewDataCollector(Config{
+		Timeout:                  2 * time.Second,
+		Retries:                  1,
+		MaxConcurrentConnections: 1,
+		Community:                "public",
+		SNMPVersion:              "2c",
+		Engine:                   snmp.NewEngine(snmp.EngineConfig{MaxWorkers: 4}),
+		ProfileDir:               t.TempDir(),
+	})
+}
+
+// startFixture levanta el simulador de un fixture incluido y retorna el
+// dispositivo como lo entregaría el discovery
+func startFixture(t *testing.T, name string) DeviceInfo {
+	t.Helper()
+	fixture, err := simulator.Builtin(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture.Community = "" // se acepta "public"
+
+	agent, err := simulator.NewAgent(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { agent.Close() })
+
+	sysDescr := fixtureValue(fixture, "1.3.6.1.2.1.1.1.0")
+	brand := detector.DetectBrand(sysDescr)
+	host, port := agent.Addr()
+	return DeviceInfo{
+		IP:              host,
+		Port:            port,
+		Brand:           brand,
+		BrandConfidence: detector.GetBrandConfidence(sysDescr, brand),
+		SysDescr:        sysDescr,
+		Community:       "public",
+		SNMPVersion:     "2c",
+	}
+}
+
+func collectDevice(t *testing.T, dc *DataCollector, device DeviceInfo) *PrinterData {
+	t.Helper()
+	results, err := dc.CollectData(context.Background(), []DeviceInfo{device})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d resultados, se esperaba 1", len(results))
+	}
+	return &results[0]
+}
+
+func fixtureValue(f *simulator.Fixture, oid string) string {
+	for _, v := range f.Variables {
+		if v.OID == oid {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+// lineDiff retorna las líneas distintas ("-" esperada, "+" actual), hasta 20 pares
+func lineDiff(want, got []byte) string {
+	w := strings.Split(string(want), "\n")
+	g := strings.Split(string(got), "\n")
+
+	var diff []string
+	for i := 0; i < max(len(w), len(g)) && len(diff) < 40; i++ {
+		var a, b string
+		if i < len(w) {
+			a = w[i]
+		}
+		if i < len(g) {
+			b = g[i]
+		}
+		if a != b {
+			diff = append(diff, "- "+strings.TrimSpace(a), "+ "+strings.TrimSpace(b))
+		}
+	}
+	return strings.Join(diff, "\n")
+}
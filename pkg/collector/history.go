@@ -0,0 +1,374 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// historyDir guarda el historial de contadores de cada impresora
+// (state/history/): <clave>.ndjson con una línea por poll y <clave>.daily.json
+// con los agregados diarios de las lecturas que salieron de la retención
+const historyDir = "history"
+
+// Sufijos de los archivos de historial
+const (
+	historyPollsExt = ".ndjson"
+	historyDailyExt = ".daily.json"
+)
+
+// HistoryPoint es la lectura de contadores de un poll
+type HistoryPoint struct {
+	At       time.Time     `json:"at"`
+	Counters CountersInfo  `json:"counters"`
+	Delta    *CountersDiff `json:"delta,omitempty"` // nil en la primera lectura o tras un reset
+	Reset    bool          `json:"reset,omitempty"`
+}
+
+// HistoryDay agrega las lecturas de un día (hora local del agente)
+// Pages suma los deltas de cada poll: Last - First sería incorrecto con un
+// reset de contadores a mitad del día
+type HistoryDay struct {
+	Date   string       `json:"date"` // 2006-01-02
+	Polls  int          `json:"polls"`
+	Resets int          `json:"resets,omitempty"`
+	First  CountersInfo `json:"first"`
+	Last   CountersInfo `json:"last"`
+	Pages  CountersDiff `json:"pages"`
+}
+
+// PrinterHistory es el historial de contadores de una impresora: agregados
+// diarios de lo que salió de la retención por poll y las lecturas por poll
+type PrinterHistory struct {
+	Daily []HistoryDay   `json:"daily"`
+	Polls []HistoryPoint `json:"polls"`
+}
+
+// HistoryRetention define qué se conserva al compactar state/
+type HistoryRetention struct {
+	PollDays  int // días de lecturas por poll; las anteriores pasan a agregados diarios
+	DailyDays int // días de agregados diarios (0 = sin límite)
+	StaleDays int // estados de impresoras sin poll en N días se eliminan (0 = nunca)
+}
+
+// CompactionStats resume una compactación
+type CompactionStats struct {
+	Printers      int // historiales revisados
+	Dropped       int // lecturas sin páginas nuevas descartadas dentro de la retención
+	RolledUp      int // lecturas llevadas a agregados diarios
+	DaysPruned    int // agregados diarios eliminados por antigüedad
+	StatesRemoved int // estados de impresoras que dejaron de responder
+}
+
+// AppendHistory agrega la lectura de un poll al historial de la impresora
+func (sm *StateManager) AppendHistory(printerKey string, point HistoryPoint) error {
+	dir := filepath.Join(sm.stateDir, historyDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(point)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, sanitizeKey(printerKey)+historyPollsExt), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadHistory lee el historial de la impresora desde since (cero = completo)
+func (sm *StateManager) LoadHistory(printerKey string, since time.Time) (*PrinterHistory, error) {
+	base := filepath.Join(sm.stateDir, historyDir, sanitizeKey(printerKey))
+	points, err := readHistoryPoints(base + historyPollsExt)
+	if err != nil {
+		return nil, err
+	}
+	days, err := readHistoryDays(base + historyDailyExt)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &PrinterHistory{Daily: []HistoryDay{}, Polls: []HistoryPoint{}}
+	sinceDay := since.Format("2006-01-02")
+	for _, d := range days {
+		if since.IsZero() || d.Date >= sinceDay {
+			history.Daily = append(history.Daily, d)
+		}
+	}
+	for _, p := range points {
+		if !p.At.Before(since) {
+			history.Polls = append(history.Polls, p)
+		}
+	}
+	return history, nil
+}
+
+// Compact aplica la retención a state/: las lecturas por poll más viejas que
+// PollDays se agregan por día, los días más viejos que DailyDays se
+// eliminan y los estados de impresoras sin poll en StaleDays se borran
+// Dentro de la retención, de cada racha de polls sin páginas nuevas se
+// conservan la primera y la última lectura (el resto no aporta información)
+func (sm *StateManager) Compact(retention HistoryRetention, now time.Time) (CompactionStats, error) {
+	var stats CompactionStats
+	var errs []string
+
+	dir := filepath.Join(sm.stateDir, historyDir)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return stats, err
+	}
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, historyDailyExt):
+			keys[strings.TrimSuffix(name, historyDailyExt)] = true
+		case strings.HasSuffix(name, historyPollsExt):
+			keys[strings.TrimSuffix(name, historyPollsExt)] = true
+		}
+	}
+	for key := range keys {
+		stats.Printers++
+		if err := sm.compactHistory(dir, key, retention, now, &stats); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	if retention.StaleDays > 0 {
+		removed, err := sm.removeStaleStates(now.AddDate(0, 0, -retention.StaleDays))
+		stats.StatesRemoved = removed
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return stats, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return stats, nil
+}
+
+// compactHistory compacta el historial de una impresora
+func (sm *StateManager) compactHistory(dir, key string, retention HistoryRetention, now time.Time, stats *CompactionStats) error {
+	pollsPath := filepath.Join(dir, key+historyPollsExt)
+	dailyPath := filepath.Join(dir, key+historyDailyExt)
+
+	points, err := readHistoryPoints(pollsPath)
+	if err != nil {
+		return err
+	}
+	days, err := readHistoryDays(dailyPath)
+	if err != nil {
+		return err
+	}
+
+	// Lecturas fuera de la retención por poll → agregados diarios
+	var kept []HistoryPoint
+	if retention.PollDays > 0 {
+		cutoff := now.AddDate(0, 0, -retention.PollDays)
+		byDate := make(map[string]int, len(days))
+		for i, d := range days {
+			byDate[d.Date] = i
+		}
+		for _, p := range points {
+			if !p.At.Before(cutoff) {
+				kept = append(kept, p)
+				continue
+			}
+			date := p.At.In(now.Location()).Format("2006-01-02")
+			i, ok := byDate[date]
+			if !ok {
+				days = append(days, HistoryDay{Date: date, First: p.Counters})
+				i = len(days) - 1
+				byDate[date] = i
+			}
+			days[i].add(p)
+			stats.RolledUp++
+		}
+	} else {
+		kept = points
+	}
+
+	before := len(kept)
+	kept = dropIdlePolls(kept)
+	stats.Dropped += before - len(kept)
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	if retention.DailyDays > 0 {
+		oldest := now.AddDate(0, 0, -retention.DailyDays).In(now.Location()).Format("2006-01-02")
+		i := sort.Search(len(days), func(i int) bool { return days[i].Date >= oldest })
+		stats.DaysPruned += i
+		days = days[i:]
+	}
+
+	if err := writeHistoryPoints(pollsPath, kept, len(points)); err != nil {
+		return err
+	}
+	return writeHistoryDays(dailyPath, days)
+}
+
+// add suma una lectura al día (las lecturas llegan en orden)
+func (d *HistoryDay) add(p HistoryPoint) {
+	d.Polls++
+	if p.Reset {
+		d.Resets++
+	}
+	d.Last = p.Counters
+	if p.Delta != nil {
+		d.Pages.TotalPages += p.Delta.TotalPages
+		d.Pages.MonoPages += p.Delta.MonoPages
+		d.Pages.ColorPages += p.Delta.ColorPages
+		d.Pages.ScanPages += p.Delta.ScanPages
+		d.Pages.CopyPages += p.Delta.CopyPages
+		d.Pages.FaxPages += p.Delta.FaxPages
+		d.Pages.DuplexPages += p.Delta.DuplexPages
+		d.Pages.A3Pages += p.Delta.A3Pages
+	}
+}
+
+// dropIdlePolls conserva la primera y la última lectura de cada racha de
+// polls con delta cero
+func dropIdlePolls(points []HistoryPoint) []HistoryPoint {
+	idle := func(p HistoryPoint) bool {
+		return p.Delta != nil && !p.Reset && *p.Delta == (CountersDiff{})
+	}
+	kept := points[:0:0]
+	for i, p := range points {
+		if idle(p) && i > 0 && idle(points[i-1]) && i+1 < len(points) && idle(points[i+1]) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// readHistoryPoints lee el historial por poll; las líneas ilegibles se saltean
+func readHistoryPoints(path string) ([]HistoryPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var points []HistoryPoint
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var p HistoryPoint
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].At.Before(points[j].At) })
+	return points, scanner.Err()
+}
+
+// writeHistoryPoints reescribe el historial por poll (sin cambios no toca el archivo)
+func writeHistoryPoints(path string, points []HistoryPoint, previous int) error {
+	if len(points) == previous {
+		return nil
+	}
+	if len(points) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, p := range points {
+		line, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return fsutil.WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// readHistoryDays lee los agregados diarios (vacío si no hay)
+func readHistoryDays(path string) ([]HistoryDay, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var days []HistoryDay
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// writeHistoryDays guarda los agregados diarios (sin días borra el archivo)
+func writeHistoryDays(path string, days []HistoryDay) error {
+	if len(days) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(days, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
+
+// removeStaleStates borra los estados de impresoras sin poll desde cutoff
+// Si la impresora vuelve, su primer poll es una lectura base (sin delta)
+func (sm *StateManager) removeStaleStates(cutoff time.Time) (int, error) {
+	matches, err := filepath.Glob(filepath.Join(sm.stateDir, "printer_*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range matches {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state PrinterState
+		if err := json.Unmarshal(raw, &state); err != nil {
+			continue
+		}
+		last := state.LastPollAt
+		if s := state.Schedule; s != nil && s.PolledAt.After(last) {
+			last = s.PolledAt
+		}
+		if last.IsZero() {
+			if info, err := os.Stat(path); err == nil {
+				last = info.ModTime()
+			}
+		}
+		if !last.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
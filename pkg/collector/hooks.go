@@ -0,0 +1,211 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Hook es un paso propio del integrador en la recolección de cada dispositivo
+// (consultar la API web del fabricante, enriquecer con la base de activos...)
+// PreCollect corre antes de las consultas SNMP y puede ajustar el DeviceInfo
+// (brand, community); PostCollect corre con los datos normalizados y antes
+// del modelo tipado, así que lo que cambie en los mapas llega a la telemetría
+// Un error no corta la recolección: se loguea y queda en PrinterData.Errors
+type Hook interface {
+	Name() string
+	PreCollect(ctx context.Context, dev *DeviceInfo) error
+	PostCollect(ctx context.Context, data *PrinterData) error
+}
+
+// HookFuncs arma un Hook con funciones sueltas (nil = paso sin acción)
+type HookFuncs struct {
+	HookName string
+	Pre      func(ctx context.Context, dev *DeviceInfo) error
+	Post     func(ctx context.Context, data *PrinterData) error
+}
+
+func (h HookFuncs) Name() string { return h.HookName }
+
+func (h HookFuncs) PreCollect(ctx context.Context, dev *DeviceInfo) error {
+	if h.Pre == nil {
+		return nil
+	}
+	return h.Pre(ctx, dev)
+}
+
+func (h HookFuncs) PostCollect(ctx context.Context, data *PrinterData) error {
+	if h.Post == nil {
+		return nil
+	}
+	return h.Post(ctx, data)
+}
+
+var (
+	hooksMu    sync.Mutex
+	registered []Hook
+)
+
+// RegisterHook agrega un hook global (típicamente desde un init() del
+// integrador); los colectores lo reciben vía RegisteredHooks en Config.Hooks
+func RegisterHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	registered = append(registered, h)
+}
+
+// RegisteredHooks retorna los hooks registrados, en orden de registro
+func RegisteredHooks() []Hook {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	return append([]Hook(nil), registered...)
+}
+
+// runPreHooks corre los PreCollect en orden sobre devInfo
+func (dc *DataCollector) runPreHooks(ctx context.Context, devInfo *DeviceInfo, data *PrinterData) {
+	for _, h := range dc.config.Hooks {
+		if err := h.PreCollect(ctx, devInfo); err != nil {
+			fmt.Println(i18n.T("log.hook_error", h.Name(), devInfo.IP, err))
+			data.Errors = append(data.Errors, fmt.Sprintf("Hook %s: %v", h.Name(), err))
+		}
+	}
+}
+
+// runPostHooks corre los PostCollect en orden sobre data
+func (dc *DataCollector) runPostHooks(ctx context.Context, data *PrinterData) {
+	for _, h := range dc.config.Hooks {
+		if err := h.PostCollect(ctx, data); err != nil {
+			fmt.Println(i18n.T("log.hook_error", h.Name(), data.IP, err))
+			data.Errors = append(data.Errors, fmt.Sprintf("Hook %s: %v", h.Name(), err))
+		}
+	}
+}
+
+// Etapas de un ExecHook
+const (
+	HookStagePre  = "pre"
+	HookStagePost = "post"
+)
+
+// ExecHook corre un programa externo por dispositivo, con JSON por stdin/stdout:
+//   - pre: recibe {ip, port, brand, snmp_version, sys_descr} (sin credenciales)
+//     y puede responder {"brand": "..."} para corregir la marca detectada
+//   - post: recibe el PrinterData y responde {"extensions": {...}}, que se
+//     suma a PrinterData.Extensions (telemetría: "extensions")
+//
+// Una salida vacía no cambia nada
+type ExecHook struct {
+	HookName string
+	Stage    string        // pre | post
+	Command  []string      // programa y argumentos
+	Timeout  time.Duration // 0 = 10s
+}
+
+func (h *ExecHook) Name() string { return h.HookName }
+
+// execPreInput es lo que recibe un hook pre
+type execPreInput struct {
+	IP          string `json:"ip"`
+	Port        uint16 `json:"port,omitempty"`
+	Brand       string `json:"brand"`
+	SNMPVersion string `json:"snmp_version,omitempty"`
+	SysDescr    string `json:"sys_descr,omitempty"`
+}
+
+// execOutput es la respuesta de un hook (campos según la etapa)
+type execOutput struct {
+	Brand      string                 `json:"brand"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func (h *ExecHook) PreCollect(ctx context.Context, dev *DeviceInfo) error {
+	if h.Stage != HookStagePre {
+		return nil
+	}
+	out, err := h.run(ctx, execPreInput{
+		IP:          dev.IP,
+		Port:        dev.Port,
+		Brand:       dev.Brand,
+		SNMPVersion: dev.SNMPVersion,
+		SysDescr:    dev.SysDescr,
+	})
+	if err != nil {
+		return err
+	}
+	if out.Brand != "" {
+		dev.Brand = out.Brand
+	}
+	return nil
+}
+
+func (h *ExecHook) PostCollect(ctx context.Context, data *PrinterData) error {
+	if h.Stage != HookStagePost {
+		return nil
+	}
+	out, err := h.run(ctx, data)
+	if err != nil {
+		return err
+	}
+	data.SetExtensions(out.Extensions)
+	return nil
+}
+
+// run ejecuta el comando con input en stdin y decodifica su stdout
+func (h *ExecHook) run(ctx context.Context, input interface{}) (execOutput, error) {
+	var out execOutput
+	if len(h.Command) == 0 {
+		return out, fmt.Errorf("comando vacío")
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return out, err
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, err
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return out, fmt.Errorf("respuesta inválida: %w", err)
+	}
+	return out, nil
+}
+
+// SetExtensions suma datos propios de un hook a la impresora; las claves
+// repetidas se reemplazan
+func (data *PrinterData) SetExtensions(values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	if data.Extensions == nil {
+		data.Extensions = make(map[string]interface{}, len(values))
+	}
+	for k, v := range values {
+		data.Extensions[k] = v
+	}
+}
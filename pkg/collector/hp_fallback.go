@@ -0,0 +1,384 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Respaldo para HP con SNMP restringido
+//
+// Varios HP (políticas de seguridad de fábrica, SNMP solo de lectura para la
+// MIB-II) no exponen contadores ni consumibles por SNMP, pero sí por PJL
+// (puerto 9100, @PJL INFO) o por el servidor web embebido (LEDM:
+// /DevMgmt/*.xml). Si al terminar las consultas SNMP faltan contadores o
+// consumibles, se consultan por ahí y se suman al mismo PrinterData: el resto
+// del pipeline (normalización, modelo tipado, telemetría) no distingue el origen
+
+// HPFallbackConfig configura el respaldo PJL/EWS (todo deshabilitado = no se usa)
+type HPFallbackConfig struct {
+	PJL       bool          // consultar @PJL INFO por el puerto raw
+	PJLPort   int           // 0 = 9100
+	EWS       bool          // consultar el servidor web embebido (LEDM)
+	EWSScheme string        // http | https ("" = http); https acepta certificados autofirmados
+	Timeout   time.Duration // por consulta (0 = 5s)
+}
+
+// enabled indica si hay algún respaldo configurado
+func (c HPFallbackConfig) enabled() bool {
+	return c.PJL || c.EWS
+}
+
+// pjlUEL es el Universal Exit Language que abre y cierra un trabajo PJL
+const pjlUEL = "\x1b%-12345X"
+
+// maxFallbackResponse acota lo que se lee de una respuesta PJL o EWS
+const maxFallbackResponse = 1 << 20
+
+// collectHPFallback completa contadores, consumibles e identificación de un
+// HP por PJL/EWS cuando SNMP no los trajo
+func (dc *DataCollector) collectHPFallback(ctx context.Context, data *PrinterData) {
+	cfg := dc.config.HPFallback
+	if !cfg.enabled() || data.Brand != "HP" {
+		return
+	}
+	needCounters := len(data.Counters) == 0
+	needSupplies := len(data.Supplies) == 0
+	if !needCounters && !needSupplies {
+		return
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	var used []string
+	if cfg.EWS {
+		if dc.collectEWS(ctx, cfg, data, needCounters, needSupplies) {
+			used = append(used, "ews")
+		}
+	}
+	if cfg.PJL && len(data.Counters) == 0 {
+		if collectPJL(ctx, cfg, data) {
+			used = append(used, "pjl")
+		}
+	}
+	if len(used) == 0 {
+		return
+	}
+
+	// Las secciones completadas dejan de figurar como faltantes
+	if len(data.Counters) > 0 {
+		data.MissingSections = removeSection(data.MissingSections, "counters")
+	}
+	if len(data.Supplies) > 0 {
+		data.MissingSections = removeSection(data.MissingSections, "supplies")
+	}
+	if len(data.Identification) > 0 {
+		data.MissingSections = removeSection(data.MissingSections, "identification")
+	}
+	fmt.Println(i18n.T("log.hp_fallback", data.IP, strings.Join(used, "+")))
+}
+
+// removeSection quita section de la lista de secciones faltantes
+func removeSection(sections []string, section string) []string {
+	kept := sections[:0]
+	for _, s := range sections {
+		if s != section {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// ========== PJL ==========
+
+// collectPJL lee contador e identificación por @PJL INFO
+// Retorna true si obtuvo el contador
+func collectPJL(ctx context.Context, cfg HPFallbackConfig, data *PrinterData) bool {
+	port := cfg.PJLPort
+	if port == 0 {
+		port = 9100
+	}
+	addr := net.JoinHostPort(data.IP, strconv.Itoa(port))
+
+	pageCount, err := pjlQuery(ctx, addr, "PAGECOUNT", cfg.Timeout)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("PJL: %v", err))
+		return false
+	}
+	count, ok := parsePJLPageCount(pageCount)
+	if !ok {
+		data.Errors = append(data.Errors, fmt.Sprintf("PJL: PAGECOUNT inválido: %q", pageCount))
+		return false
+	}
+	data.Counters["pjl_pagecount"] = count
+	setCounter(data, "total_pages", count, CounterSourcePJL)
+
+	if _, ok := data.Identification["model"]; !ok {
+		if id, err := pjlQuery(ctx, addr, "ID", cfg.Timeout); err == nil {
+			if model := strings.Trim(strings.TrimSpace(id), `"`); model != "" {
+				data.Identification["model"] = model
+			}
+		}
+	}
+	return true
+}
+
+// pjlQuery envía @PJL INFO <category> y retorna el cuerpo de la respuesta
+// (sin el eco del comando ni el form feed final)
+func pjlQuery(ctx context.Context, addr, category string, timeout time.Duration) (string, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := pjlUEL + "@PJL INFO " + category + "\r\n" + pjlUEL
+	if _, err := io.WriteString(conn, request); err != nil {
+		return "", err
+	}
+
+	// La respuesta termina con un form feed
+	reader := bufio.NewReader(io.LimitReader(conn, maxFallbackResponse))
+	raw, err := reader.ReadString('\f')
+	if err != nil && raw == "" {
+		return "", err
+	}
+	raw = strings.TrimSuffix(raw, "\f")
+
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	var body []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(strings.ToUpper(line), "@PJL") {
+			continue
+		}
+		body = append(body, line)
+	}
+	if len(body) == 0 {
+		return "", fmt.Errorf("respuesta vacía a INFO %s", category)
+	}
+	return strings.Join(body, "\n"), nil
+}
+
+// parsePJLPageCount acepta "12345" y "PAGECOUNT=12345"
+func parsePJLPageCount(body string) (int64, bool) {
+	value := strings.TrimSpace(strings.SplitN(body, "\n", 2)[0])
+	if i := strings.IndexByte(value, '='); i >= 0 {
+		value = strings.TrimSpace(value[i+1:])
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// ========== EWS (LEDM) ==========
+
+// Documentos LEDM del servidor web embebido de HP
+const (
+	ewsUsagePath       = "/DevMgmt/ProductUsageDyn.xml"
+	ewsConsumablesPath = "/DevMgmt/ConsumableConfigDyn.xml"
+	ewsProductPath     = "/DevMgmt/ProductConfigDyn.xml"
+)
+
+// ledmUsage es lo que se usa de ProductUsageDyn.xml
+// encoding/xml compara por nombre local: los prefijos (pudyn:, dd:) no importan
+type ledmUsage struct {
+	PrinterSubunit struct {
+		TotalImpressions      int64 `xml:"TotalImpressions"`
+		MonochromeImpressions int64 `xml:"MonochromeImpressions"`
+		ColorImpressions      int64 `xml:"ColorImpressions"`
+		DuplexSheets          int64 `xml:"DuplexSheets"`
+	} `xml:"PrinterSubunit"`
+	ScannerEngineSubunit struct {
+		ScanImages int64 `xml:"ScanImages"`
+	} `xml:"ScannerEngineSubunit"`
+}
+
+// ledmConsumables es lo que se usa de ConsumableConfigDyn.xml
+type ledmConsumables struct {
+	Consumables []struct {
+		Type       string `xml:"ConsumableTypeEnum"`
+		LabelCode  string `xml:"ConsumableLabelCode"`
+		Percentage *int   `xml:"ConsumablePercentageLevelRemaining"`
+		Product    string `xml:"ProductNumber"`
+		Serial     string `xml:"SerialNumber"`
+		Color      string `xml:"MarkerColor"`
+	} `xml:"ConsumableInfo"`
+}
+
+// ledmProduct es lo que se usa de ProductConfigDyn.xml
+type ledmProduct struct {
+	Info struct {
+		MakeAndModel string `xml:"MakeAndModel"`
+		SerialNumber string `xml:"SerialNumber"`
+	} `xml:"ProductInformation"`
+}
+
+// collectEWS lee contadores, consumibles e identificación del servidor web
+// Retorna true si aportó algo
+func (dc *DataCollector) collectEWS(ctx context.Context, cfg HPFallbackConfig, data *PrinterData, needCounters, needSupplies bool) bool {
+	scheme := cfg.EWSScheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	if scheme == "https" {
+		// Los EWS traen certificados autofirmados con el nombre de fábrica
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	host := data.IP
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+	base := scheme + "://" + host
+
+	got := false
+	if needCounters {
+		var usage ledmUsage
+		if err := fetchLEDM(ctx, client, base+ewsUsagePath, &usage); err != nil {
+			data.Errors = append(data.Errors, fmt.Sprintf("EWS: %v", err))
+		} else if applyLEDMUsage(data, usage) {
+			got = true
+		}
+	}
+	if needSupplies {
+		var consumables ledmConsumables
+		if err := fetchLEDM(ctx, client, base+ewsConsumablesPath, &consumables); err != nil {
+			data.Errors = append(data.Errors, fmt.Sprintf("EWS: %v", err))
+		} else if applyLEDMConsumables(data, consumables) {
+			got = true
+		}
+	}
+	if got && (data.Identification["model"] == nil || data.Identification["serial_number"] == nil) {
+		var product ledmProduct
+		if err := fetchLEDM(ctx, client, base+ewsProductPath, &product); err == nil {
+			if _, ok := data.Identification["model"]; !ok && product.Info.MakeAndModel != "" {
+				data.Identification["model"] = strings.TrimSpace(product.Info.MakeAndModel)
+			}
+			if _, ok := data.Identification["serial_number"]; !ok && product.Info.SerialNumber != "" {
+				data.Identification["serial_number"] = strings.TrimSpace(product.Info.SerialNumber)
+			}
+		}
+	}
+	return got
+}
+
+// fetchLEDM descarga y decodifica un documento LEDM
+func fetchLEDM(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxFallbackResponse)).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	return nil
+}
+
+// applyLEDMUsage carga los contadores de ProductUsageDyn.xml
+func applyLEDMUsage(data *PrinterData, usage ledmUsage) bool {
+	counters := []struct {
+		raw, name string
+		value     int64
+	}{
+		{"ews_total_impressions", "total_pages", usage.PrinterSubunit.TotalImpressions},
+		{"ews_mono_impressions", "mono_pages", usage.PrinterSubunit.MonochromeImpressions},
+		{"ews_color_impressions", "color_pages", usage.PrinterSubunit.ColorImpressions},
+		{"ews_duplex_sheets", "duplex_pages", usage.PrinterSubunit.DuplexSheets},
+		{"ews_scan_images", "scan_pages", usage.ScannerEngineSubunit.ScanImages},
+	}
+	got := false
+	for _, c := range counters {
+		if c.value <= 0 {
+			continue
+		}
+		data.Counters[c.raw] = c.value
+		setCounter(data, c.name, c.value, CounterSourceEWS)
+		got = true
+	}
+	return got
+}
+
+// applyLEDMConsumables carga los consumibles de ConsumableConfigDyn.xml con el
+// mismo formato que la recolección SNMP (nivel sobre máximo 100)
+func applyLEDMConsumables(data *PrinterData, consumables ledmConsumables) bool {
+	got := false
+	for _, c := range consumables.Consumables {
+		if c.Percentage == nil {
+			continue
+		}
+		key := ledmSupplyKey(c.Type, c.Color, c.LabelCode)
+		if key == "" {
+			continue
+		}
+		supplyInfo := map[string]interface{}{
+			"description": strings.TrimSpace(strings.Join([]string{c.Color, c.Type, c.Product}, " ")),
+			"level":       strconv.Itoa(*c.Percentage),
+			"max":         "100",
+			"source":      CounterSourceEWS,
+		}
+		if color := normalizeColorant(c.Color); color != "" {
+			supplyInfo["color"] = color
+		}
+		if c.Product != "" {
+			supplyInfo["part_number"] = strings.TrimSpace(c.Product)
+		}
+		if c.Serial != "" {
+			supplyInfo["serial_number"] = strings.TrimSpace(c.Serial)
+		}
+		data.Supplies[key] = supplyInfo
+		got = true
+	}
+	return got
+}
+
+// ledmSupplyKey arma la clave normalizada (tonerBlack, drumCyan...) de un
+// consumible LEDM; los que no son tóner ni drum usan la etiqueta ("hpSupply_K")
+func ledmSupplyKey(consumableType, color, labelCode string) string {
+	colorName := supplyColorNames[normalizeColorant(color)]
+	switch strings.ToLower(strings.TrimSpace(consumableType)) {
+	case "toner", "ink", "inkcartridge", "tonercartridge":
+		if colorName != "" {
+			return "toner" + colorName
+		}
+	case "imagingdrum", "drum", "opc":
+		if colorName != "" {
+			return "drum" + colorName
+		}
+	case "fuser", "fuserkit":
+		return "fusor"
+	case "transferkit", "transferbelt":
+		return "transferUnit"
+	case "maintenancekit":
+		return "kitMantenimiento"
+	case "tonercollectionunit", "wastetonercontainer":
+		return "cajaResiduos"
+	}
+	if label := strings.TrimSpace(labelCode); label != "" {
+		return "hpSupply_" + label
+	}
+	return ""
+}
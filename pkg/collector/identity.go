@@ -0,0 +1,69 @@
+package collector
+
+import "strings"
+
+// CanonicalPrinterID genera el ID estable de una impresora
+// Prioridad: MAC (más estable) → Serial (única) → IP (fallback)
+// Resultado es lowercase sin caracteres especiales
+func CanonicalPrinterID(macAddress, serialNumber, ip string) string {
+	// 1. MAC address sin separadores
+	cleanMac := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(macAddress), ":", ""))
+	if len(cleanMac) >= 12 {
+		return cleanMac
+	}
+
+	// 2. Serial number
+	serial := strings.TrimSpace(serialNumber)
+	if serial != "" {
+		return strings.ToLower(serial)
+	}
+
+	// 3. IP (cambia con DHCP, solo como último recurso)
+	return ip
+}
+
+// resolvePrinterID calcula el ID canónico a partir de los datos ya recolectados
+// Debe llamarse después de collectIdentification y collectNetworkInfo
+func resolvePrinterID(data *PrinterData) string {
+	mac, _ := data.NetworkInfo["macAddress"].(string)
+	serial, _ := data.Identification["serial_number"].(string)
+	return CanonicalPrinterID(mac, serial, data.IP)
+}
+
+// Resultado de comparar sysName con el nombre DNS
+const (
+	HostnameMatch    = "match"     // sysName coincide con la etiqueta corta del PTR
+	HostnameMismatch = "mismatch"  // ambos existen y difieren (equipo renombrado o PTR viejo)
+	HostnameSNMPOnly = "snmp_only" // sin PTR: el equipo no está registrado en DNS
+	HostnameDNSOnly  = "dns_only"  // el equipo no reporta sysName
+)
+
+// ReconcileHostname compara sysName con el nombre PTR
+// Retorna el hostname a publicar (sysName, o la etiqueta corta del PTR si falta)
+// y el estado de la comparación ("" si no hay ninguno de los dos)
+// La comparación ignora mayúsculas y el dominio: "PRN-01" = "prn-01.corp.local"
+func ReconcileHostname(sysName, dnsName string) (hostname, status string) {
+	sysName = strings.TrimSpace(sysName)
+	dnsName = strings.TrimSuffix(strings.TrimSpace(dnsName), ".")
+
+	switch {
+	case sysName == "" && dnsName == "":
+		return "", ""
+	case dnsName == "":
+		return sysName, HostnameSNMPOnly
+	case sysName == "":
+		return shortHostname(dnsName), HostnameDNSOnly
+	case strings.EqualFold(shortHostname(sysName), shortHostname(dnsName)):
+		return sysName, HostnameMatch
+	default:
+		return sysName, HostnameMismatch
+	}
+}
+
+// shortHostname retorna la primera etiqueta de un nombre DNS
+func shortHostname(name string) string {
+	if i := strings.Index(name, "."); i > 0 {
+		return name[:i]
+	}
+	return name
+}
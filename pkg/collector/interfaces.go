@@ -0,0 +1,203 @@
+package collector
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Tabla de interfaces (IF-MIB ifTable + IP-MIB ipAddrTable)
+//
+// Equipos con Wi-Fi y Ethernet no garantizan que ifIndex 1 sea la interfaz
+// por la que se los consulta: se recorren ambas tablas y se elige la interfaz
+// que tiene la IP consultada
+
+// Columnas de ifTable e ipAddrTable
+const (
+	oidIfDescr       = "1.3.6.1.2.1.2.2.1.2"
+	oidIfType        = "1.3.6.1.2.1.2.2.1.3"
+	oidIfSpeed       = "1.3.6.1.2.1.2.2.1.5"
+	oidIfPhysAddress = "1.3.6.1.2.1.2.2.1.6"
+	oidIfAdminStatus = "1.3.6.1.2.1.2.2.1.7"
+	oidIfOperStatus  = "1.3.6.1.2.1.2.2.1.8"
+	oidIpAdEntIfIdx  = "1.3.6.1.2.1.4.20.1.2"
+	oidIpAdEntMask   = "1.3.6.1.2.1.4.20.1.3"
+)
+
+// ifTypeLoopback es softwareLoopback (IANAifType 24)
+const ifTypeLoopback = 24
+
+// NetworkInterface es una fila de ifTable con sus direcciones IPv4
+type NetworkInterface struct {
+	Index       int      `json:"index"`                 // ifIndex
+	Description string   `json:"description,omitempty"` // ifDescr
+	Type        int      `json:"type,omitempty"`        // IANAifType (6 = ethernet, 71 = wifi, 24 = loopback)
+	MACAddress  string   `json:"mac_address,omitempty"` // aa:bb:cc:dd:ee:ff
+	SpeedBps    uint64   `json:"speed_bps,omitempty"`   // ifSpeed
+	AdminStatus string   `json:"admin_status,omitempty"`
+	OperStatus  string   `json:"oper_status,omitempty"` // up | down | testing | dormant...
+	IPAddresses []string `json:"ip_addresses,omitempty"`
+	Netmasks    []string `json:"netmasks,omitempty"` // mismo orden que IPAddresses
+}
+
+// PhysicalInterfaces retorna las interfaces de la ifTable sin loopback ni
+// interfaces sin MAC ni IP
+func (n Network) PhysicalInterfaces() []NetworkInterface {
+	var physical []NetworkInterface
+	for _, iface := range n.Interfaces {
+		if iface.Type == ifTypeLoopback || (iface.MACAddress == "" && len(iface.IPAddresses) == 0) {
+			continue
+		}
+		physical = append(physical, iface)
+	}
+	return physical
+}
+
+// ifStatusNames traduce ifAdminStatus/ifOperStatus
+var ifStatusNames = map[int64]string{
+	1: "up",
+	2: "down",
+	3: "testing",
+	4: "unknown",
+	5: "dormant",
+	6: "not_present",
+	7: "lower_layer_down",
+}
+
+// walkInterfaces recorre ifTable e ipAddrTable y retorna las interfaces por ifIndex
+// Retorna nil si el equipo no expone ifTable
+func walkInterfaces(client *snmp.SNMPClient, ctx *snmp.Context) []NetworkInterface {
+	byIndex := make(map[int]*NetworkInterface)
+	row := func(index int) *NetworkInterface {
+		iface, ok := byIndex[index]
+		if !ok {
+			iface = &NetworkInterface{Index: index}
+			byIndex[index] = iface
+		}
+		return iface
+	}
+
+	columns := []string{oidIfDescr, oidIfType, oidIfSpeed, oidIfPhysAddress, oidIfAdminStatus, oidIfOperStatus}
+	for _, column := range columns {
+		results, err := client.Walk(column, ctx)
+		if err != nil {
+			continue
+		}
+		for _, result := range results {
+			index, ok := tableIndex(result.OID, column)
+			if !ok {
+				continue
+			}
+			iface := row(index)
+			switch column {
+			case oidIfDescr:
+				iface.Description = strings.TrimSpace(result.Typed.String())
+			case oidIfType:
+				n, _ := result.Typed.Int64()
+				iface.Type = int(n)
+			case oidIfSpeed:
+				iface.SpeedBps, _ = result.Typed.Uint64()
+			case oidIfPhysAddress:
+				iface.MACAddress = formatMAC(result.Typed)
+			case oidIfAdminStatus:
+				n, _ := result.Typed.Int64()
+				iface.AdminStatus = ifStatusNames[n]
+			case oidIfOperStatus:
+				n, _ := result.Typed.Int64()
+				iface.OperStatus = ifStatusNames[n]
+			}
+		}
+	}
+
+	// ipAddrTable: el índice de la fila es la propia IP (ipAdEntIfIndex.<a.b.c.d>)
+	masks := make(map[string]string)
+	if results, err := client.Walk(oidIpAdEntMask, ctx); err == nil {
+		for _, result := range results {
+			masks[tableSuffix(result.OID, oidIpAdEntMask)] = result.Typed.String()
+		}
+	}
+	if results, err := client.Walk(oidIpAdEntIfIdx, ctx); err == nil {
+		for _, result := range results {
+			ip := tableSuffix(result.OID, oidIpAdEntIfIdx)
+			index, ok := result.Typed.Int64()
+			if ip == "" || !ok {
+				continue
+			}
+			iface := row(int(index))
+			iface.IPAddresses = append(iface.IPAddresses, ip)
+			iface.Netmasks = append(iface.Netmasks, masks[ip])
+		}
+	}
+
+	if len(byIndex) == 0 {
+		return nil
+	}
+	interfaces := make([]NetworkInterface, 0, len(byIndex))
+	for _, iface := range byIndex {
+		interfaces = append(interfaces, *iface)
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Index < interfaces[j].Index })
+	return interfaces
+}
+
+// selectInterface elige la interfaz por la que se consultó el equipo
+// Prioridad: la que tiene la IP consultada → la primera operativa con MAC
+// que no sea loopback → la primera con MAC
+func selectInterface(interfaces []NetworkInterface, polledIP string) *NetworkInterface {
+	for i := range interfaces {
+		for _, ip := range interfaces[i].IPAddresses {
+			if ip == polledIP {
+				return &interfaces[i]
+			}
+		}
+	}
+	for i := range interfaces {
+		iface := &interfaces[i]
+		if iface.MACAddress != "" && iface.Type != ifTypeLoopback && iface.OperStatus == "up" {
+			return iface
+		}
+	}
+	for i := range interfaces {
+		if interfaces[i].MACAddress != "" && interfaces[i].Type != ifTypeLoopback {
+			return &interfaces[i]
+		}
+	}
+	return nil
+}
+
+// tableIndex retorna el índice numérico de una fila (<column>.<index>)
+func tableIndex(oid, column string) (int, bool) {
+	index, err := strconv.Atoi(tableSuffix(oid, column))
+	return index, err == nil
+}
+
+// tableSuffix retorna lo que sigue a column en oid ("" si no pertenece)
+func tableSuffix(oid, column string) string {
+	oid = strings.TrimPrefix(oid, ".")
+	if !strings.HasPrefix(oid, column+".") {
+		return ""
+	}
+	return oid[len(column)+1:]
+}
+
+// formatMAC formatea ifPhysAddress como aa:bb:cc:dd:ee:ff
+// Se usan los bytes crudos: una MAC cuyos bytes son imprimibles no debe leerse como texto
+func formatMAC(value snmp.Value) string {
+	b := value.Bytes()
+	if len(b) != 6 {
+		return value.String()
+	}
+	hexStr := hex.EncodeToString(b)
+	parts := make([]string, 0, 6)
+	for i := 0; i < len(hexStr); i += 2 {
+		parts = append(parts, hexStr[i:i+2])
+	}
+	mac := strings.Join(parts, ":")
+	if mac == "00:00:00:00:00:00" {
+		return ""
+	}
+	return mac
+}
@@ -0,0 +1,287 @@
+package collector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// inventoryFile guarda el inventario del último escaneo en el directorio de estado
+const inventoryFile = "_inventory.json"
+
+// Tipos de cambio de inventario entre dos escaneos
+const (
+	ChangeNew      = "new"      // impresora nunca vista
+	ChangeMissing  = "missing"  // impresora conocida que no respondió
+	ChangeReturned = "returned" // impresora marcada missing que volvió a responder
+	ChangeChanged  = "changed"  // modelo/serie distintos, o otra impresora en la misma IP
+
+	ChangeIPChanged = "ip_changed" // la misma impresora (MAC/serie) responde en otra IP
+
+	ChangeDecommissioned = "decommissioned" // sin responder por más de N días: archivada (ver decommission.go)
+)
+
+// InventoryEntry es lo que se recuerda de cada impresora entre escaneos
+type InventoryEntry struct {
+	PrinterID    string    `json:"printer_id"`
+	IP           string    `json:"ip"`
+	Brand        string    `json:"brand,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	MACAddress   string    `json:"mac_address,omitempty"`
+	Addresses    []string  `json:"addresses,omitempty"` // todas las IPs si es multi-homed (Ethernet + Wi-Fi)
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Online       bool      `json:"online"`
+	NoSNMP       bool      `json:"no_snmp,omitempty"`    // responde en puertos de impresión pero no SNMP: sin telemetría
+	OpenPorts    []int     `json:"open_ports,omitempty"` // solo NoSNMP
+	Banner       string    `json:"banner,omitempty"`     // solo NoSNMP; Model es lo deducido de acá
+
+	// Dada de baja: su estado está en state/archive/ y no vuelve a reportarse
+	// hasta que se la descubra de nuevo (returned)
+	Decommissioned   bool       `json:"decommissioned,omitempty"`
+	DecommissionedAt *time.Time `json:"decommissioned_at,omitempty"`
+}
+
+// InventoryChange es una diferencia detectada en un escaneo
+type InventoryChange struct {
+	Change   string          `json:"change"` // new | missing | returned | changed | ip_changed | decommissioned
+	Fields   []string        `json:"fields,omitempty"`
+	Previous *InventoryEntry `json:"previous,omitempty"`
+	Current  *InventoryEntry `json:"current,omitempty"`
+}
+
+// InventoryDiff agrupa los cambios de un escaneo respecto del anterior
+type InventoryDiff struct {
+	New       []InventoryChange `json:"new"`
+	Missing   []InventoryChange `json:"missing"`
+	Returned  []InventoryChange `json:"returned"`
+	Changed   []InventoryChange `json:"changed"`
+	IPChanged []InventoryChange `json:"ip_changed"`
+
+	Decommissioned []InventoryChange `json:"decommissioned,omitempty"` // ver DecommissionInventory
+}
+
+// All retorna todos los cambios en un orden estable (new, returned,
+// ip_changed, changed, missing, decommissioned)
+func (d InventoryDiff) All() []InventoryChange {
+	all := make([]InventoryChange, 0, len(d.New)+len(d.Returned)+len(d.IPChanged)+len(d.Changed)+len(d.Missing)+len(d.Decommissioned))
+	all = append(all, d.New...)
+	all = append(all, d.Returned...)
+	all = append(all, d.IPChanged...)
+	all = append(all, d.Changed...)
+	all = append(all, d.Missing...)
+	all = append(all, d.Decommissioned...)
+	return all
+}
+
+// Empty indica si el escaneo no cambió el inventario
+func (d InventoryDiff) Empty() bool {
+	return len(d.New)+len(d.Missing)+len(d.Returned)+len(d.Changed)+len(d.IPChanged)+len(d.Decommissioned) == 0
+}
+
+// NewInventoryEntry arma la entrada de inventario de un PrinterData recolectado
+func NewInventoryEntry(data *PrinterData, seenAt time.Time) InventoryEntry {
+	id := data.PrinterID
+	if id == "" {
+		id = data.IP
+	}
+	return InventoryEntry{
+		PrinterID:    id,
+		IP:           data.IP,
+		Brand:        data.Brand,
+		Model:        data.Info.Model,
+		SerialNumber: data.Info.SerialNumber,
+		MACAddress:   data.Network.MACAddress,
+		Addresses:    data.Addresses,
+		FirstSeen:    seenAt,
+		LastSeen:     seenAt,
+		Online:       true,
+	}
+}
+
+// NewNoSNMPInventoryEntry arma la entrada de un host que no responde SNMP
+// pero tiene puertos de impresión abiertos; se identifica por IP
+func NewNoSNMPInventoryEntry(ip, brand, modelGuess, banner string, openPorts []int, seenAt time.Time) InventoryEntry {
+	return InventoryEntry{
+		PrinterID: ip,
+		IP:        ip,
+		Brand:     brand,
+		Model:     modelGuess,
+		FirstSeen: seenAt,
+		LastSeen:  seenAt,
+		Online:    true,
+		NoSNMP:    true,
+		OpenPorts: openPorts,
+		Banner:    banner,
+	}
+}
+
+// DiffInventory compara el escaneo actual con el inventario anterior
+// skipIPs son dispositivos no recolectados por falta de tiempo (ScanBudget):
+// no se reportan como missing. Retorna el diff y el inventario a persistir
+func DiffInventory(previous map[string]InventoryEntry, current []InventoryEntry, skipIPs []string) (InventoryDiff, map[string]InventoryEntry) {
+	diff := InventoryDiff{
+		New:       []InventoryChange{},
+		Missing:   []InventoryChange{},
+		Returned:  []InventoryChange{},
+		Changed:   []InventoryChange{},
+		IPChanged: []InventoryChange{},
+	}
+	next := make(map[string]InventoryEntry, len(previous)+len(current))
+	for id, entry := range previous {
+		next[id] = entry
+	}
+
+	// IP → impresora online en el escaneo anterior (para detectar reemplazos)
+	prevByIP := make(map[string]InventoryEntry)
+	for _, entry := range previous {
+		if entry.Online {
+			prevByIP[entry.IP] = entry
+		}
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, cur := range current {
+		cur := cur
+		seen[cur.PrinterID] = true
+
+		prev, known := previous[cur.PrinterID]
+		switch {
+		case known && !prev.Online:
+			cur.FirstSeen = prev.FirstSeen
+			diff.Returned = append(diff.Returned, InventoryChange{Change: ChangeReturned, Previous: &prev, Current: &cur})
+			if movedIP(prev, cur) {
+				diff.IPChanged = append(diff.IPChanged, InventoryChange{Change: ChangeIPChanged, Fields: []string{"ip"}, Previous: &prev, Current: &cur})
+			}
+
+		case known:
+			cur.FirstSeen = prev.FirstSeen
+			fields := changedFields(prev, cur)
+			// El cambio de IP va aparte (DHCP): el resto de los campos sigue en changed
+			if len(fields) > 0 && fields[0] == "ip" {
+				diff.IPChanged = append(diff.IPChanged, InventoryChange{Change: ChangeIPChanged, Fields: fields[:1], Previous: &prev, Current: &cur})
+				fields = fields[1:]
+			}
+			if len(fields) > 0 {
+				diff.Changed = append(diff.Changed, InventoryChange{Change: ChangeChanged, Fields: fields, Previous: &prev, Current: &cur})
+			}
+
+		default:
+			// Otra impresora ocupa la IP de una conocida: cambio de equipo en esa IP
+			if old, ok := prevByIP[cur.IP]; ok && old.PrinterID != cur.PrinterID && !containsID(current, old.PrinterID) {
+				old := old
+				seen[old.PrinterID] = true
+				old.Online = false
+				next[old.PrinterID] = old
+				diff.Changed = append(diff.Changed, InventoryChange{Change: ChangeChanged, Fields: []string{"printer_id"}, Previous: &old, Current: &cur})
+			} else {
+				diff.New = append(diff.New, InventoryChange{Change: ChangeNew, Current: &cur})
+			}
+		}
+
+		next[cur.PrinterID] = cur
+	}
+
+	skip := make(map[string]bool, len(skipIPs))
+	for _, ip := range skipIPs {
+		skip[ip] = true
+	}
+
+	for id, prev := range previous {
+		if seen[id] || !prev.Online || skip[prev.IP] {
+			continue
+		}
+		prev := prev
+		diff.Missing = append(diff.Missing, InventoryChange{Change: ChangeMissing, Previous: &prev})
+
+		offline := prev
+		offline.Online = false
+		next[id] = offline
+	}
+
+	sortChanges(diff.New)
+	sortChanges(diff.Missing)
+	sortChanges(diff.Returned)
+	sortChanges(diff.Changed)
+	sortChanges(diff.IPChanged)
+
+	return diff, next
+}
+
+// changedFields retorna los campos de identidad que cambiaron
+func changedFields(prev, cur InventoryEntry) []string {
+	var fields []string
+	if movedIP(prev, cur) {
+		fields = append(fields, "ip")
+	}
+	if prev.Model != "" && cur.Model != "" && prev.Model != cur.Model {
+		fields = append(fields, "model")
+	}
+	if prev.SerialNumber != "" && cur.SerialNumber != "" && prev.SerialNumber != cur.SerialNumber {
+		fields = append(fields, "serial_number")
+	}
+	if prev.NoSNMP != cur.NoSNMP {
+		fields = append(fields, "no_snmp")
+	}
+	return fields
+}
+
+// movedIP indica si la impresora cambió de IP; un equipo multi-homed que
+// responde por otra de sus interfaces no se movió
+func movedIP(prev, cur InventoryEntry) bool {
+	if prev.IP == cur.IP {
+		return false
+	}
+	for _, ip := range cur.Addresses {
+		if ip == prev.IP {
+			return false
+		}
+	}
+	return true
+}
+
+func containsID(entries []InventoryEntry, id string) bool {
+	for _, e := range entries {
+		if e.PrinterID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortChanges ordena por IP para que el diff sea estable entre corridas
+func sortChanges(changes []InventoryChange) {
+	key := func(c InventoryChange) string {
+		if c.Current != nil {
+			return c.Current.IP
+		}
+		return c.Previous.IP
+	}
+	sort.Slice(changes, func(i, j int) bool { return key(changes[i]) < key(changes[j]) })
+}
+
+// LoadInventory carga el inventario del último escaneo (vacío si no hay)
+func (sm *StateManager) LoadInventory() map[string]InventoryEntry {
+	inventory := make(map[string]InventoryEntry)
+
+	data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, inventoryFile))
+	if err != nil {
+		return inventory
+	}
+	json.Unmarshal(data, &inventory)
+	return inventory
+}
+
+// SaveInventory guarda el inventario (se sobrescribe)
+func (sm *StateManager) SaveInventory(inventory map[string]InventoryEntry) error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, inventoryFile), data, 0644)
+}
@@ -0,0 +1,393 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Respaldo IPP (puerto 631)
+//
+// Varios Brother y Epson recientes responden la tabla de consumibles de
+// Printer MIB vacía o con niveles centinela (-2 desconocido) en todas las
+// filas, pero informan marker-levels por IPP (Get-Printer-Attributes). Si
+// SNMP no trajo consumibles utilizables, o el estado quedó desconocido, se
+// consultan printer-state, marker-* y media-ready y se suman al PrinterData
+
+// IPPConfig configura el respaldo IPP (Enabled false = no se usa)
+type IPPConfig struct {
+	Enabled bool
+	Always  bool          // consultar en cada poll (media-ready), no solo si SNMP falló
+	Port    int           // 0 = 631
+	Path    string        // "" = /ipp/print (IPP Everywhere)
+	Timeout time.Duration // por consulta (0 = 5s)
+}
+
+// Atributos que se piden con Get-Printer-Attributes
+var ippRequestedAttributes = []string{
+	"printer-state",
+	"printer-state-reasons",
+	"marker-names",
+	"marker-types",
+	"marker-colors",
+	"marker-levels",
+	"media-ready",
+}
+
+// Etiquetas y códigos de IPP/1.1 (RFC 8010)
+const (
+	ippOpGetPrinterAttributes = 0x000B
+
+	ippTagOperation    = 0x01
+	ippTagEnd          = 0x03
+	ippTagInteger      = 0x21
+	ippTagEnum         = 0x23
+	ippTagBegCollect   = 0x34
+	ippTagEndCollect   = 0x37
+	ippTagKeyword      = 0x44
+	ippTagURI          = 0x45
+	ippTagCharset      = 0x47
+	ippTagNaturalLang  = 0x48
+	ippStatusOKMaximum = 0x00FF // successful-ok-*
+)
+
+// collectIPP completa estado, consumibles y bandejas por IPP cuando SNMP no
+// los trajo (o siempre con IPPConfig.Always)
+func (dc *DataCollector) collectIPP(ctx context.Context, data *PrinterData) {
+	cfg := dc.config.IPP
+	if !cfg.Enabled {
+		return
+	}
+	needSupplies := !hasUsableSupplies(data.Supplies)
+	needState := mapString(data.Status, "state") == "" || mapString(data.Status, "state") == "unknown"
+	if !cfg.Always && !needSupplies && !needState {
+		return
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	attrs, err := ippGetPrinterAttributes(ctx, cfg, data.IP)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("IPP: %v", err))
+		return
+	}
+
+	var filled []string
+	if needState && applyIPPState(data, attrs) {
+		filled = append(filled, "status")
+		data.MissingSections = removeSection(data.MissingSections, "status")
+	}
+	if applyIPPMarkers(data, attrs, needSupplies) {
+		filled = append(filled, "supplies")
+		data.MissingSections = removeSection(data.MissingSections, "supplies")
+	}
+	if media := ippStrings(attrs["media-ready"]); len(media) > 0 {
+		data.Status["media_ready"] = media
+	}
+	if len(filled) > 0 {
+		fmt.Println(i18n.T("log.ipp_fallback", data.IP, strings.Join(filled, ", ")))
+	}
+}
+
+// hasUsableSupplies indica si algún consumible de SNMP tiene un nivel medido
+// Una tabla con todas las filas en -1/-2 se considera rota
+func hasUsableSupplies(supplies map[string]interface{}) bool {
+	for _, val := range supplies {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if hasMeasuredLevel(m) || mapInt64(m, "level") == SupplyLevelSomeRemaining {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMeasuredLevel indica si el consumible tiene un nivel real (no centinela)
+func hasMeasuredLevel(supply map[string]interface{}) bool {
+	if _, ok := supply["level"]; !ok {
+		return false
+	}
+	return SupplyLevelState(mapInt64(supply, "level")) == LevelStateKnown
+}
+
+// applyIPPState traduce printer-state (3 idle, 4 processing, 5 stopped)
+func applyIPPState(data *PrinterData, attrs map[string][]interface{}) bool {
+	states := attrs["printer-state"]
+	if len(states) == 0 {
+		return false
+	}
+	code, _ := states[0].(int64)
+	var state string
+	switch code {
+	case 3:
+		state = "idle"
+	case 4:
+		state = "printing"
+	case 5:
+		state = "error"
+	default:
+		return false
+	}
+	for _, reason := range ippStrings(attrs["printer-state-reasons"]) {
+		if strings.HasPrefix(reason, "offline") || strings.HasPrefix(reason, "shutdown") {
+			state = "offline"
+		}
+	}
+	data.Status["state"] = state
+	data.Status["ipp_printer_state"] = code
+	return true
+}
+
+// applyIPPMarkers arma consumibles con marker-* (niveles en porcentaje, con
+// los mismos centinelas que RFC 3805). Con replace se completan o reemplazan
+// los de SNMP sin nivel medido; si no, solo se agregan los que faltan
+func applyIPPMarkers(data *PrinterData, attrs map[string][]interface{}, replace bool) bool {
+	names := ippStrings(attrs["marker-names"])
+	types := ippStrings(attrs["marker-types"])
+	colors := ippStrings(attrs["marker-colors"])
+	levels := attrs["marker-levels"]
+
+	added := false
+	for i, raw := range levels {
+		level, ok := raw.(int64)
+		if !ok {
+			continue
+		}
+		name, markerType, color := ippAt(names, i), ippAt(types, i), ippColorName(ippAt(colors, i))
+		key := ippSupplyKey(markerType, color, i)
+
+		existing, exists := data.Supplies[key].(map[string]interface{})
+		if !replace && (exists || strings.HasPrefix(key, "ippSupply_")) {
+			continue // con SNMP sano solo se suman los que tienen clave propia y faltan
+		}
+		if exists && hasMeasuredLevel(existing) {
+			continue
+		}
+
+		supplyInfo := map[string]interface{}{
+			"description": name,
+			"level":       strconv.FormatInt(level, 10),
+			"max":         "100",
+			"source":      "ipp",
+		}
+		if color != "" {
+			supplyInfo["color"] = color
+		}
+		if strings.HasPrefix(markerType, "waste-") {
+			supplyInfo["fills_up"] = true
+		}
+		data.Supplies[key] = supplyInfo
+		added = true
+	}
+	return added
+}
+
+// ippSupplyKey arma la clave normalizada de un marker (tonerBlack, drumCyan...)
+func ippSupplyKey(markerType, color string, index int) string {
+	colorName := supplyColorNames[color]
+	switch markerType {
+	case "toner", "toner-cartridge", "ink", "ink-cartridge", "ink-ribbon", "solid-wax":
+		if colorName != "" {
+			return "toner" + colorName
+		}
+	case "opc", "developer":
+		if colorName != "" {
+			return "drum" + colorName
+		}
+	case "fuser", "fuser-cleaning-pad", "fuser-oil":
+		return "fusor"
+	case "transfer-unit":
+		return "transferUnit"
+	case "waste-toner", "waste-ink", "waste-wax", "waste-water":
+		return "cajaResiduos"
+	}
+	return fmt.Sprintf("ippSupply_%d", index+1)
+}
+
+// ippColorName traduce marker-colors ("#00FFFF", "cyan") a un colorante
+// Los markers con varios colores ("#00FFFF#FF00FF#FFFF00") no tienen uno solo
+func ippColorName(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	switch value {
+	case "#000000":
+		return "black"
+	case "#00FFFF":
+		return "cyan"
+	case "#FF00FF":
+		return "magenta"
+	case "#FFFF00":
+		return "yellow"
+	}
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	return normalizeColorant(value)
+}
+
+// ippAt retorna values[i] o "" si no existe
+func ippAt(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+// ippStrings retorna los valores de texto de un atributo
+func ippStrings(values []interface{}) []string {
+	var out []string
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ippGetPrinterAttributes hace un Get-Printer-Attributes y retorna los
+// atributos de impresora (nombre → valores: int64 para integer/enum, string
+// para el resto)
+func ippGetPrinterAttributes(ctx context.Context, cfg IPPConfig, ip string) (map[string][]interface{}, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = 631
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "/ipp/print"
+	}
+	hostPort := net.JoinHostPort(ip, strconv.Itoa(port))
+
+	body := ippRequest("ipp://"+hostPort+path, ippRequestedAttributes)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+hostPort+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ipp")
+
+	resp, err := (&http.Client{Timeout: cfg.Timeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxFallbackResponse))
+	if err != nil {
+		return nil, err
+	}
+	return parseIPPResponse(raw)
+}
+
+// ippRequest codifica un Get-Printer-Attributes (IPP/2.0)
+func ippRequest(printerURI string, requested []string) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x02, 0x00}) // versión 2.0
+	binary.Write(&buf, binary.BigEndian, uint16(ippOpGetPrinterAttributes))
+	binary.Write(&buf, binary.BigEndian, uint32(1)) // request-id
+
+	buf.WriteByte(ippTagOperation)
+	ippWriteAttr(&buf, ippTagCharset, "attributes-charset", "utf-8")
+	ippWriteAttr(&buf, ippTagNaturalLang, "attributes-natural-language", "en")
+	ippWriteAttr(&buf, ippTagURI, "printer-uri", printerURI)
+	for i, name := range requested {
+		if i == 0 {
+			ippWriteAttr(&buf, ippTagKeyword, "requested-attributes", name)
+		} else {
+			ippWriteAttr(&buf, ippTagKeyword, "", name) // valor adicional del mismo atributo
+		}
+	}
+	buf.WriteByte(ippTagEnd)
+	return buf.Bytes()
+}
+
+// ippWriteAttr escribe tag, nombre y valor con sus longitudes
+func ippWriteAttr(buf *bytes.Buffer, tag byte, name, value string) {
+	buf.WriteByte(tag)
+	binary.Write(buf, binary.BigEndian, uint16(len(name)))
+	buf.WriteString(name)
+	binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	buf.WriteString(value)
+}
+
+// parseIPPResponse decodifica la respuesta; las colecciones se saltean
+func parseIPPResponse(raw []byte) (map[string][]interface{}, error) {
+	if len(raw) < 8 {
+		return nil, fmt.Errorf("respuesta IPP truncada")
+	}
+	if status := binary.BigEndian.Uint16(raw[2:4]); status > ippStatusOKMaximum {
+		return nil, fmt.Errorf("status IPP 0x%04x", status)
+	}
+
+	attrs := make(map[string][]interface{})
+	var current string
+	depth := 0
+	pos := 8
+	for pos < len(raw) {
+		tag := raw[pos]
+		pos++
+		if tag == ippTagEnd {
+			break
+		}
+		if tag < 0x10 { // delimitador de grupo
+			continue
+		}
+		if pos+2 > len(raw) {
+			return attrs, fmt.Errorf("respuesta IPP truncada")
+		}
+		nameLen := int(binary.BigEndian.Uint16(raw[pos:]))
+		pos += 2
+		if pos+nameLen+2 > len(raw) {
+			return attrs, fmt.Errorf("respuesta IPP truncada")
+		}
+		name := string(raw[pos : pos+nameLen])
+		pos += nameLen
+		valueLen := int(binary.BigEndian.Uint16(raw[pos:]))
+		pos += 2
+		if pos+valueLen > len(raw) {
+			return attrs, fmt.Errorf("respuesta IPP truncada")
+		}
+		value := raw[pos : pos+valueLen]
+		pos += valueLen
+
+		switch tag {
+		case ippTagBegCollect:
+			depth++
+			continue
+		case ippTagEndCollect:
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth > 0 {
+			continue // miembros de una colección (media-col y similares)
+		}
+		if name != "" {
+			current = name
+		}
+		if current == "" {
+			continue
+		}
+
+		switch {
+		case (tag == ippTagInteger || tag == ippTagEnum) && len(value) == 4:
+			attrs[current] = append(attrs[current], int64(int32(binary.BigEndian.Uint32(value))))
+		case tag >= 0x40:
+			attrs[current] = append(attrs[current], string(value))
+		}
+	}
+	return attrs, nil
+}
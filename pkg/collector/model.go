@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Modelo tipado de PrinterData
+//
+// Los mapas de PrinterData (Identification, Status, Supplies, Counters...)
+// son la capa de compatibilidad: conservan el JSON histórico del collector.
+// Los tipos de este archivo se llenan UNA vez al final de la recolección
+// (populateTyped) y son lo que deben consumir telemetry, state y reportes,
+// sin volver a parsear interface{} con fmt.Sprintf
+
+// Identification datos de identidad del equipo
+type Identification struct {
+	Model        string `json:"model,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	Hostname     string `json:"hostname,omitempty"` // sysName (o el PTR si el equipo no lo reporta)
+	SysName      string `json:"sys_name,omitempty"`
+	DNSName      string `json:"dns_name,omitempty"`      // PTR de la IP consultada
+	HostnameSync string `json:"hostname_sync,omitempty"` // match | mismatch | snmp_only | dns_only
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Designation  string `json:"designation,omitempty"` // HP DES (código de producto)
+	SysDescr     string `json:"sys_descr,omitempty"`
+	SysObjectID  string `json:"sys_object_id,omitempty"`
+}
+
+// Status estado operativo del equipo
+type Status struct {
+	State         string `json:"state"`                    // idle | offline | testing | unknown
+	DeviceStatus  int    `json:"device_status,omitempty"`  // hrDeviceStatus (1=up, 2=down, 3=testing)
+	PrinterStatus string `json:"printer_status,omitempty"` // bitfield crudo
+	ErrorStatus   string `json:"error_status,omitempty"`
+	UptimeSeconds int64  `json:"uptime_seconds,omitempty"` // segundos (sysUpTime / 100)
+	PageCount     int64  `json:"page_count,omitempty"`     // páginas
+
+	MediaReady []string `json:"media_ready,omitempty"` // media-ready de IPP (papel cargado en las bandejas)
+
+	Errors ErrorState `json:"errors"` // hrPrinterDetectedErrorState decodificado
+}
+
+// Valores centinela de prtMarkerSuppliesLevel / MaxCapacity (RFC 3805)
+const (
+	SupplyLevelOther         = -1 // el equipo no usa ninguna unidad conocida
+	SupplyLevelUnknown       = -2 // nivel desconocido
+	SupplyLevelSomeRemaining = -3 // "queda al menos una unidad" (sin medición precisa)
+)
+
+// Estados explícitos del nivel de un consumible
+const (
+	LevelStateKnown         = "known"          // Level/MaxLevel son mediciones reales
+	LevelStateOther         = "other"          // -1
+	LevelStateUnknown       = "level_unknown"  // -2
+	LevelStateSomeRemaining = "some_remaining" // -3
+)
+
+// SupplyLevelState clasifica un nivel crudo de RFC 3805
+func SupplyLevelState(level int64) string {
+	switch level {
+	case SupplyLevelOther:
+		return LevelStateOther
+	case SupplyLevelUnknown:
+		return LevelStateUnknown
+	case SupplyLevelSomeRemaining:
+		return LevelStateSomeRemaining
+	}
+	if level < 0 {
+		return LevelStateUnknown
+	}
+	return LevelStateKnown
+}
+
+// Supply consumible con unidades explícitas
+type Supply struct {
+	Key           string `json:"key"` // clave en PrinterData.Supplies (tonerBlack, fusor...)
+	Description   string `json:"description"`
+	Level         int64  `json:"level"`       // en la unidad del equipo (prtMarkerSuppliesSupplyUnit)
+	MaxLevel      int64  `json:"max_level"`   // misma unidad que Level; <= 0 = desconocido
+	Percentage    int    `json:"percentage"`  // 0-100 (calculado si el equipo no lo reporta); 0 si LevelState != known
+	LevelState    string `json:"level_state"` // known | other | level_unknown | some_remaining
+	TypeCode      int    `json:"type_code,omitempty"`
+	ComponentType string `json:"component_type,omitempty"`
+	Color         string `json:"color,omitempty"` // prtMarkerColorantValue: black, cyan, magenta, yellow...
+	Model         string `json:"model,omitempty"` // número de parte
+	SerialNumber  string `json:"serial_number,omitempty"`
+	Brand         string `json:"brand,omitempty"`
+	StateCode     int    `json:"state_code,omitempty"`
+	PageCapacity  int64  `json:"page_capacity,omitempty"` // páginas
+	RatedYield    int64  `json:"rated_yield,omitempty"`   // rendimiento nominal según la base de modelos (ver catalog.go)
+
+	Class          string `json:"class,omitempty"`           // consumed | receptacle (prtMarkerSuppliesClass)
+	Unit           string `json:"unit,omitempty"`            // impressions, sheets, percent... (prtMarkerSuppliesSupplyUnit)
+	RemainingPages int64  `json:"remaining_pages,omitempty"` // vida restante, solo en unidades de página
+	FillsUp        bool   `json:"fills_up,omitempty"`        // se llena con el uso (residuos): Level es el espacio libre
+	ReplaceBy      string `json:"replace_by,omitempty"`      // fecha estimada de reemplazo (YYYY-MM-DD), ver ForecastSupplies
+}
+
+// Capabilities son las capacidades detectadas del equipo (ver detectCapabilities)
+type Capabilities struct {
+	SNMPVersion string   `json:"snmp_version"`
+	Duplex      bool     `json:"duplex"`
+	Color       bool     `json:"color"`
+	Scanner     bool     `json:"scanner"`
+	Fax         bool     `json:"fax"`
+	Subtrees    []string `json:"subtrees,omitempty"`     // subárboles recorridos que respondieron
+	SuccessRate float64  `json:"success_rate,omitempty"` // OIDs respondidos / intentados en el poll
+}
+
+// Network datos de red del equipo
+type Network struct {
+	MACAddress string `json:"mac_address,omitempty"` // aa:bb:cc:dd:ee:ff
+	IPAddress  string `json:"ip_address,omitempty"`
+	Location   string `json:"location,omitempty"` // sysLocation
+
+	InterfaceIndex int                `json:"if_index,omitempty"`   // ifIndex de la interfaz consultada
+	Interfaces     []NetworkInterface `json:"interfaces,omitempty"` // ifTable completa
+}
+
+// populateTyped llena los campos tipados a partir de los mapas recolectados
+func (data *PrinterData) populateTyped() {
+	data.Info = Identification{
+		Model:        mapString(data.Identification, "model", "model_name", "modelName", "printerModel"),
+		SerialNumber: mapString(data.Identification, "serial_number", "serialNumber"),
+		SysName:      mapString(data.Identification, "hostname", "sysName"),
+		DNSName:      data.DNSName,
+		Manufacturer: mapString(data.Identification, "manufacturer"),
+		Designation:  mapString(data.Identification, "designation"),
+		SysDescr:     mapString(data.Identification, "sysDescr", "description"),
+		SysObjectID:  mapString(data.Identification, "sysObjectID"),
+	}
+	data.Info.Hostname, data.Info.HostnameSync = ReconcileHostname(data.Info.SysName, data.Info.DNSName)
+
+	data.State = Status{
+		State:         mapString(data.Status, "state"),
+		DeviceStatus:  int(mapInt64(data.Status, "device_status")),
+		PrinterStatus: mapString(data.Status, "printer_status"),
+		ErrorStatus:   mapString(data.Status, "error_status"),
+		UptimeSeconds: mapInt64(data.Status, "system_uptime_seconds"),
+		PageCount:     getPageCountFromStatus(data.Status),
+		Errors:        DecodeErrorStateHex(mapString(data.Status, "detected_error_state")),
+		MediaReady:    mapStrings(data.Status, "media_ready"),
+	}
+	if data.State.State == "" {
+		data.State.State = "unknown"
+	}
+
+	data.Network = Network{
+		MACAddress: mapString(data.NetworkInfo, "macAddress"),
+		IPAddress:  mapString(data.NetworkInfo, "ipAddress"),
+		Location:   mapString(data.NetworkInfo, "location"),
+	}
+	if interfaces, ok := data.NetworkInfo["interfaces"].([]NetworkInterface); ok {
+		data.Network.Interfaces = interfaces
+		data.Network.InterfaceIndex, _ = data.NetworkInfo["ifIndex"].(int)
+	}
+
+	// Contadores: NormalizedCounters es la fuente precisa, Counters el fallback
+	counters := data.NormalizedCounters
+	if len(counters) == 0 {
+		counters = data.Counters
+	}
+	data.PageCounters = CountersInfo{
+		TotalPages:  mapInt64(counters, "total_pages"),
+		MonoPages:   mapInt64(counters, "mono_pages"),
+		ColorPages:  mapInt64(counters, "color_pages"),
+		ScanPages:   mapInt64(counters, "scan_pages"),
+		CopyPages:   mapInt64(counters, "copy_pages"),
+		FaxPages:    mapInt64(counters, "fax_pages"),
+		DuplexPages: mapInt64(counters, "duplex_pages"),
+		A3Pages:     mapInt64(counters, "a3_pages"),
+	}
+
+	data.SupplyList = buildSupplyList(data.Supplies)
+}
+
+// HasCounters indica si se recolectó algún contador
+func (data *PrinterData) HasCounters() bool {
+	return len(data.NormalizedCounters) > 0 || len(data.Counters) > 0
+}
+
+// buildSupplyList convierte el mapa de supplies en una lista tipada
+// Solo incluye entradas estructuradas (las claves crudas de WALK se ignoran)
+func buildSupplyList(supplies map[string]interface{}) []Supply {
+	keys := make([]string, 0, len(supplies))
+	for key, val := range supplies {
+		if _, ok := val.(map[string]interface{}); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys) // orden estable entre polls
+
+	list := make([]Supply, 0, len(keys))
+	for _, key := range keys {
+		m := supplies[key].(map[string]interface{})
+
+		s := Supply{
+			Key:           key,
+			Description:   mapString(m, "name", "description"),
+			Level:         mapInt64(m, "level", "current"),
+			MaxLevel:      mapInt64(m, "maxLevel", "max"),
+			Percentage:    int(mapInt64(m, "percentage", "percent")),
+			TypeCode:      int(mapInt64(m, "type_code")),
+			ComponentType: mapString(m, "component_type"),
+			Color:         mapString(m, "color"),
+			Model:         mapString(m, "model", "partnumber", "part_number"),
+			SerialNumber:  mapString(m, "serial_number", "serial", "sn"),
+			Brand:         mapString(m, "oem", "brand", "manufacturer"),
+			StateCode:     int(mapInt64(m, "state_code")),
+			PageCapacity:  mapInt64(m, "page_capacity", "pages", "capacity"),
+
+			Class:          mapString(m, "class"),
+			Unit:           mapString(m, "unit"),
+			RemainingPages: mapInt64(m, "remaining_pages"),
+		}
+		s.FillsUp, _ = m["fills_up"].(bool)
+
+		// Los centinelas (-1/-2/-3) no son niveles: nunca calcular porcentaje con ellos
+		s.LevelState = SupplyLevelState(s.Level)
+		if s.LevelState != LevelStateKnown {
+			s.Percentage = 0
+		} else if s.Percentage == 0 && s.MaxLevel > 0 && s.Level > 0 {
+			s.Percentage = int((s.Level * 100) / s.MaxLevel)
+		}
+
+		list = append(list, s)
+	}
+
+	return list
+}
+
+// mapString retorna el primer valor string no vacío entre las claves dadas
+func mapString(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		val, ok := m[key]
+		if !ok || val == nil {
+			continue
+		}
+		s := strings.TrimSpace(fmt.Sprintf("%v", val))
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// mapStrings retorna una lista de strings ([]string, o []interface{} si el
+// mapa viene de JSON)
+func mapStrings(m map[string]interface{}, key string) []string {
+	switch v := m[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// mapInt64 retorna el primer valor numérico entre las claves dadas
+// Acepta int/int64/float64 y strings numéricos ("50", "50.0")
+func mapInt64(m map[string]interface{}, keys ...string) int64 {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case int:
+			return int64(v)
+		case int64:
+			return v
+		case uint32:
+			return int64(v)
+		case uint64:
+			return int64(v)
+		case float64:
+			return int64(v)
+		case string:
+			s := strings.TrimSpace(v)
+			if s == "" {
+				continue
+			}
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return int64(f)
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package collector
+
+import "encoding/hex"
+
+// OIDs de HOST-RESOURCES-MIB (RFC 2790) para el estado de la impresora
+const (
+	oidHrPrinterStatus             = "1.3.6.1.2.1.25.3.5.1.1.1" // other(1) unknown(2) idle(3) printing(4) warmup(5)
+	oidHrPrinterDetectedErrorState = "1.3.6.1.2.1.25.3.5.1.2.1" // bitfield (OCTET STRING)
+)
+
+// ErrorState es hrPrinterDetectedErrorState decodificado
+// Los bits se numeran desde el MSB del primer octeto (RFC 2790 / RFC 3805)
+type ErrorState struct {
+	LowPaper            bool `json:"low_paper"`
+	NoPaper             bool `json:"no_paper"`
+	LowToner            bool `json:"low_toner"`
+	NoToner             bool `json:"no_toner"`
+	DoorOpen            bool `json:"door_open"`
+	Jammed              bool `json:"jammed"`
+	Offline             bool `json:"offline"`
+	ServiceRequested    bool `json:"service_requested"`
+	InputTrayMissing    bool `json:"input_tray_missing"`
+	OutputTrayMissing   bool `json:"output_tray_missing"`
+	MarkerSupplyMissing bool `json:"marker_supply_missing"`
+	OutputNearFull      bool `json:"output_near_full"`
+	OutputFull          bool `json:"output_full"`
+	InputTrayEmpty      bool `json:"input_tray_empty"`
+	OverduePreventMaint bool `json:"overdue_prevent_maint"`
+	Reported            bool `json:"-"` // el equipo respondió el OID (false = sin datos)
+}
+
+// errorStateBits asocia cada bit con su nombre estable (IDs de alertas)
+var errorStateBits = []struct {
+	bit  int
+	name string
+	flag func(*ErrorState) *bool
+}{
+	{0, "low_paper", func(e *ErrorState) *bool { return &e.LowPaper }},
+	{1, "no_paper", func(e *ErrorState) *bool { return &e.NoPaper }},
+	{2, "low_toner", func(e *ErrorState) *bool { return &e.LowToner }},
+	{3, "no_toner", func(e *ErrorState) *bool { return &e.NoToner }},
+	{4, "door_open", func(e *ErrorState) *bool { return &e.DoorOpen }},
+	{5, "jammed", func(e *ErrorState) *bool { return &e.Jammed }},
+	{6, "offline", func(e *ErrorState) *bool { return &e.Offline }},
+	{7, "service_requested", func(e *ErrorState) *bool { return &e.ServiceRequested }},
+	{8, "input_tray_missing", func(e *ErrorState) *bool { return &e.InputTrayMissing }},
+	{9, "output_tray_missing", func(e *ErrorState) *bool { return &e.OutputTrayMissing }},
+	{10, "marker_supply_missing", func(e *ErrorState) *bool { return &e.MarkerSupplyMissing }},
+	{11, "output_near_full", func(e *ErrorState) *bool { return &e.OutputNearFull }},
+	{12, "output_full", func(e *ErrorState) *bool { return &e.OutputFull }},
+	{13, "input_tray_empty", func(e *ErrorState) *bool { return &e.InputTrayEmpty }},
+	{14, "overdue_prevent_maint", func(e *ErrorState) *bool { return &e.OverduePreventMaint }},
+}
+
+// DecodeErrorState decodifica el OCTET STRING de hrPrinterDetectedErrorState
+// Octetos faltantes se consideran 0 (muchos equipos envían un solo octeto)
+func DecodeErrorState(raw []byte) ErrorState {
+	state := ErrorState{Reported: true}
+	for _, b := range errorStateBits {
+		octet := b.bit / 8
+		if octet >= len(raw) {
+			continue
+		}
+		if raw[octet]&(0x80>>(b.bit%8)) != 0 {
+			*b.flag(&state) = true
+		}
+	}
+	return state
+}
+
+// DecodeErrorStateHex decodifica la forma hex guardada en PrinterData.Status
+func DecodeErrorStateHex(s string) ErrorState {
+	if s == "" {
+		return ErrorState{}
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return ErrorState{}
+	}
+	return DecodeErrorState(raw)
+}
+
+// Active retorna los nombres de las condiciones activas (orden de bits)
+func (e ErrorState) Active() []string {
+	var active []string
+	for _, b := range errorStateBits {
+		if *b.flag(&e) {
+			active = append(active, b.name)
+		}
+	}
+	return active
+}
+
+// Blocking indica si alguna condición impide imprimir
+func (e ErrorState) Blocking() bool {
+	return e.NoPaper || e.NoToner || e.DoorOpen || e.Jammed || e.Offline ||
+		e.MarkerSupplyMissing || e.OutputFull || e.InputTrayMissing
+}
+
+// printerStatusState traduce hrPrinterStatus a un estado legible ("" si no aplica)
+func printerStatusState(status int64) string {
+	switch status {
+	case 3:
+		return "idle"
+	case 4:
+		return "printing"
+	case 5:
+		return "warmup"
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// problemsDir guarda las lecturas que no se pudieron convertir en telemetría
+// (state/problems/): un bug del builder o del serializer no pierde el dato,
+// queda para `printsnmp problems reprocess` después de corregirlo
+const problemsDir = "problems"
+
+// Etapas en las que una lectura puede fallar antes de llegar a la queue
+const (
+	ProblemStageBuild     = "build"
+	ProblemStageSerialize = "serialize"
+	ProblemStageSchema    = "schema"
+)
+
+// Problem es una lectura recolectada que no llegó a la queue
+// Guarda el modelo tipado aparte porque PrinterData no lo serializa
+type Problem struct {
+	Name          string        `json:"-"` // archivo dentro de problems/
+	Stage         string        `json:"stage"`
+	Error         string        `json:"error"`
+	FailedAt      time.Time     `json:"failed_at"`
+	ScanID        string        `json:"scan_id,omitempty"`
+	StateKey      string        `json:"state_key"`
+	Delta         *CountersDiff `json:"delta,omitempty"`
+	ResetDetected bool          `json:"reset_detected,omitempty"`
+	Data          PrinterData   `json:"data"`
+	Typed         problemTyped  `json:"typed"`
+}
+
+// problemTyped son los campos json:"-" de PrinterData
+type problemTyped struct {
+	Info         Identification `json:"identification"`
+	State        Status         `json:"status"`
+	Network      Network        `json:"network"`
+	SupplyList   []Supply       `json:"supplies"`
+	PageCounters CountersInfo   `json:"counters"`
+	Capabilities Capabilities   `json:"capabilities"`
+	NextPollAt   time.Time      `json:"next_poll_at"`
+}
+
+// PrinterData retorna la lectura con el modelo tipado restaurado
+func (p *Problem) PrinterData() PrinterData {
+	data := p.Data
+	data.Info = p.Typed.Info
+	data.State = p.Typed.State
+	data.Network = p.Typed.Network
+	data.SupplyList = p.Typed.SupplyList
+	data.PageCounters = p.Typed.PageCounters
+	data.Capabilities = p.Typed.Capabilities
+	data.NextPollAt = p.Typed.NextPollAt
+	return data
+}
+
+// SaveProblem guarda una lectura que falló en stage, con el delta calculado
+// en el ciclo (el estado de contadores ya avanzó: no se puede recalcular)
+func (sm *StateManager) SaveProblem(stateKey, stage string, cause error, data *PrinterData, delta *CountersDiff, resetDetected bool) error {
+	dir := filepath.Join(sm.stateDir, problemsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	problem := Problem{
+		Stage:         stage,
+		Error:         cause.Error(),
+		FailedAt:      time.Now().UTC(),
+		ScanID:        sm.scanID,
+		StateKey:      stateKey,
+		Delta:         delta,
+		ResetDetected: resetDetected,
+		Data:          *data,
+		Typed: problemTyped{
+			Info:         data.Info,
+			State:        data.State,
+			Network:      data.Network,
+			SupplyList:   data.SupplyList,
+			PageCounters: data.PageCounters,
+			Capabilities: data.Capabilities,
+			NextPollAt:   data.NextPollAt,
+		},
+	}
+	out, err := json.MarshalIndent(problem, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Un archivo por lectura: la misma impresora puede fallar en varios ciclos
+	name := fmt.Sprintf("%s_%s.json", sanitizeKey(stateKey), problem.FailedAt.Format("20060102T150405.000000000"))
+	return fsutil.WriteFileAtomic(filepath.Join(dir, name), out, 0644)
+}
+
+// Problems retorna las lecturas guardadas, de la más vieja a la más nueva
+// Los archivos ilegibles se informan en el error sin cortar el resto
+func (sm *StateManager) Problems() ([]Problem, error) {
+	dir := filepath.Join(sm.stateDir, problemsDir)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var problems []Problem
+	var bad []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		raw, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			bad = append(bad, entry.Name())
+			continue
+		}
+		var p Problem
+		if err := json.Unmarshal(raw, &p); err != nil {
+			bad = append(bad, entry.Name())
+			continue
+		}
+		p.Name = entry.Name()
+		problems = append(problems, p)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].FailedAt.Before(problems[j].FailedAt)
+	})
+
+	if len(bad) > 0 {
+		return problems, fmt.Errorf("archivos ilegibles en %s: %s", dir, strings.Join(bad, ", "))
+	}
+	return problems, nil
+}
+
+// RemoveProblem borra una lectura ya reprocesada
+func (sm *StateManager) RemoveProblem(name string) error {
+	if name != filepath.Base(name) {
+		return fmt.Errorf("nombre inválido: %q", name)
+	}
+	return os.Remove(filepath.Join(sm.stateDir, problemsDir, name))
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// DefaultPollInterval es el intervalo de consulta sin configuración
+const DefaultPollInterval = time.Hour
+
+// PollPolicy define cada cuánto se consulta cada dispositivo
+// Con Adaptive, un equipo con mucho uso se consulta más seguido (hasta Min)
+// y uno sin páginas nuevas cada vez menos (hasta Max)
+type PollPolicy struct {
+	Interval         time.Duration // intervalo base (0 = DefaultPollInterval)
+	Adaptive         bool
+	MinInterval      time.Duration
+	MaxInterval      time.Duration
+	BusyPagesPerHour float64 // desde este ritmo el intervalo se acorta a la mitad
+}
+
+// PollSchedule es el plan de consultas de una impresora (ver PlanPoll)
+type PollSchedule struct {
+	PolledAt   time.Time `json:"polled_at"`
+	IntervalMs int64     `json:"interval_ms"`
+	NextPollAt time.Time `json:"next_poll_at"`
+}
+
+// base retorna el intervalo base
+func (p PollPolicy) base() time.Duration {
+	if p.Interval <= 0 {
+		return DefaultPollInterval
+	}
+	return p.Interval
+}
+
+// NextInterval calcula el próximo intervalo de un dispositivo según las
+// páginas (delta) impresas en elapsed desde el poll anterior
+// Sin delta (primer poll, reset) se vuelve al intervalo base
+func (p PollPolicy) NextInterval(previous time.Duration, delta *CountersDiff, elapsed time.Duration) time.Duration {
+	base := p.base()
+	if !p.Adaptive || delta == nil || elapsed <= 0 {
+		return base
+	}
+	if previous <= 0 {
+		previous = base
+	}
+
+	var next time.Duration
+	switch rate := float64(delta.TotalPages) / elapsed.Hours(); {
+	case p.BusyPagesPerHour > 0 && rate >= p.BusyPagesPerHour:
+		next = previous / 2
+	case delta.TotalPages == 0:
+		next = previous * 2
+	default:
+		next = base
+	}
+
+	if p.MinInterval > 0 && next < p.MinInterval {
+		next = p.MinInterval
+	}
+	if p.MaxInterval > 0 && next > p.MaxInterval {
+		next = p.MaxInterval
+	}
+	return next
+}
+
+// PlanPoll calcula y guarda el próximo poll de una impresora consultada en now
+func (sm *StateManager) PlanPoll(printerKey string, policy PollPolicy, delta *CountersDiff, now time.Time) (time.Time, error) {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if state == nil {
+		state = &PrinterState{}
+	}
+
+	var previous, elapsed time.Duration
+	if s := state.Schedule; s != nil {
+		previous = time.Duration(s.IntervalMs) * time.Millisecond
+		elapsed = now.Sub(s.PolledAt)
+	}
+	interval := policy.NextInterval(previous, delta, elapsed)
+
+	state.Schedule = &PollSchedule{
+		PolledAt:   now.UTC(),
+		IntervalMs: interval.Milliseconds(),
+		NextPollAt: now.UTC().Add(interval),
+	}
+	if err := sm.writeState(printerKey, *state); err != nil {
+		return time.Time{}, err
+	}
+	return state.Schedule.NextPollAt, nil
+}
+
+// Due indica si a una impresora ya le toca consultarse (sin plan = sí)
+// Se tolera un 10% del intervalo: el ciclo arranca antes de que se consulte
+// cada equipo y sin margen un equipo vencido por segundos saltearía un ciclo
+func (sm *StateManager) Due(printerKey string, now time.Time) bool {
+	state, err := sm.LoadState(printerKey)
+	if err != nil || state == nil || state.Schedule == nil {
+		return true
+	}
+	slack := time.Duration(state.Schedule.IntervalMs) * time.Millisecond / 10
+	return !now.Before(state.Schedule.NextPollAt.Add(-slack))
+}
+
+// scheduleRunsFile guarda la última corrida de cada agenda cron por site
+const scheduleRunsFile = "_schedule_runs.json"
+
+// LoadScheduleRuns carga la última corrida de cada agenda (vacío si no hay)
+func (sm *StateManager) LoadScheduleRuns() map[string]time.Time {
+	runs := make(map[string]time.Time)
+	data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, scheduleRunsFile))
+	if err != nil {
+		return runs
+	}
+	json.Unmarshal(data, &runs)
+	return runs
+}
+
+// SaveScheduleRuns guarda la última corrida de cada agenda (se sobrescribe)
+func (sm *StateManager) SaveScheduleRuns(runs map[string]time.Time) error {
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, scheduleRunsFile), data, 0644)
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// defaultSectionConcurrency es cuántas secciones de un mismo dispositivo se
+// consultan a la vez cuando Config.SectionConcurrency es 0
+const defaultSectionConcurrency = 3
+
+// section es un paso independiente de la recolección de un dispositivo
+// Escribe solo en el PrinterData que recibe (una copia propia, ver runSections)
+type section func(part *PrinterData)
+
+// runSections ejecuta secciones independientes de un dispositivo en paralelo,
+// con a lo sumo Config.SectionConcurrency a la vez, y fusiona lo recolectado en data
+// Cada sección trabaja sobre una copia de data: los mapas y slices de PrinterData
+// no son seguros para escrituras concurrentes
+// Las secciones comparten el cliente SNMP; el tráfico total lo sigue acotando el motor
+func (dc *DataCollector) runSections(ctx context.Context, data *PrinterData, sections ...section) {
+	limit := dc.config.SectionConcurrency
+	if limit <= 0 {
+		limit = defaultSectionConcurrency
+	}
+
+	// Secuencial: sin copias, mismo comportamiento que antes
+	if limit == 1 || len(sections) == 1 {
+		for _, run := range sections {
+			if ctx.Err() != nil {
+				return
+			}
+			run(data)
+		}
+		return
+	}
+
+	base := data.fork()
+	parts := make([]PrinterData, len(sections))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, run := range sections {
+		parts[i] = data.fork()
+		wg.Add(1)
+		go func(part *PrinterData, run section) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
+			run(part)
+		}(&parts[i], run)
+	}
+	wg.Wait()
+
+	// Fusión en el orden de las secciones: el resultado no depende de cuál terminó primero
+	for i := range parts {
+		data.merge(&parts[i], &base)
+	}
+}
+
+// fork retorna una copia de data para que una sección escriba sin compartir mapas
+// Los slices acumulativos (Errors, MissingSections, CounterAnomalies) empiezan
+// vacíos: merge agrega solo lo que aportó la sección
+func (data *PrinterData) fork() PrinterData {
+	part := *data
+	part.Identification = cloneMap(data.Identification)
+	part.Status = cloneMap(data.Status)
+	part.Supplies = cloneMap(data.Supplies)
+	part.Counters = cloneMap(data.Counters)
+	part.NetworkInfo = cloneMap(data.NetworkInfo)
+	part.AdminInfo = cloneMap(data.AdminInfo)
+	part.NormalizedCounters = cloneMap(data.NormalizedCounters)
+	part.NormalizedSupplies = cloneMap(data.NormalizedSupplies)
+	part.CounterSources = nil
+	part.CounterConfidence = nil
+	part.CounterAnomalies = nil
+	part.Errors = nil
+	part.MissingSections = nil
+	return part
+}
+
+// merge incorpora a data lo que una sección recolectó en su copia
+// Solo se copian las entradas que la sección agregó o cambió respecto de base
+// (el estado al hacer fork): las copias de las demás secciones no pisan datos
+func (data *PrinterData) merge(part, base *PrinterData) {
+	mergeMap(data.Identification, part.Identification, base.Identification)
+	mergeMap(data.Status, part.Status, base.Status)
+	mergeMap(data.Supplies, part.Supplies, base.Supplies)
+	mergeMap(data.Counters, part.Counters, base.Counters)
+	mergeMap(data.NetworkInfo, part.NetworkInfo, base.NetworkInfo)
+	mergeMap(data.AdminInfo, part.AdminInfo, base.AdminInfo)
+	mergeMap(data.NormalizedCounters, part.NormalizedCounters, base.NormalizedCounters)
+	mergeMap(data.NormalizedSupplies, part.NormalizedSupplies, base.NormalizedSupplies)
+
+	for name, source := range part.CounterSources {
+		if data.CounterSources == nil {
+			data.CounterSources = make(map[string]string)
+		}
+		data.CounterSources[name] = source
+	}
+	for name, confidence := range part.CounterConfidence {
+		if data.CounterConfidence == nil {
+			data.CounterConfidence = make(map[string]float64)
+		}
+		data.CounterConfidence[name] = confidence
+	}
+	for _, anomaly := range part.CounterAnomalies {
+		data.flagAnomaly(anomaly)
+	}
+	data.Errors = append(data.Errors, part.Errors...)
+	data.MissingSections = append(data.MissingSections, part.MissingSections...)
+}
+
+// cloneMap copia un mapa de primer nivel (nil se mantiene nil)
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// mergeMap copia en dst las entradas de src que no están igual en base
+func mergeMap(dst, src, base map[string]interface{}) {
+	for k, v := range src {
+		if prev, ok := base[k]; ok && reflect.DeepEqual(prev, v) {
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -6,12 +6,41 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
 )
 
 // StateManager maneja la persistencia de estado por impresora
+// Un solo escritor por directorio: el proceso que escribe debe tomar Lock()
+// (dos instancias del agente o corridas de cron superpuestas se excluyen)
 type StateManager struct {
 	stateDir string
+	lock     *fsutil.Lock
+	scanID   string // ciclo en curso, se anota en cada escritura
+}
+
+// stateLockFile es el lock advisory del directorio de estado
+const stateLockFile = ".lock"
+
+// Lock toma el lock exclusivo del directorio de estado, esperando hasta timeout
+// Retorna un error que envuelve fsutil.ErrLocked si otra instancia lo tiene
+func (sm *StateManager) Lock(timeout time.Duration) error {
+	lock, err := fsutil.AcquireLock(filepath.Join(sm.stateDir, stateLockFile), timeout)
+	if err != nil {
+		return err
+	}
+	sm.lock = lock
+	return nil
+}
+
+// Unlock libera el lock del directorio de estado
+func (sm *StateManager) Unlock() error {
+	err := sm.lock.Release()
+	sm.lock = nil
+	return err
 }
 
 // NewStateManager crea un nuevo gestor de estado
@@ -21,9 +50,15 @@ func NewStateManager(stateDir string) *StateManager {
 	return &StateManager{stateDir: stateDir}
 }
 
+// SetScanID asigna el ciclo que se anota en cada estado escrito (PrinterState.ScanID)
+func (sm *StateManager) SetScanID(id string) {
+	sm.scanID = id
+}
+
 // LoadState carga el estado anterior de una impresora
-func (sm *StateManager) LoadState(printerIP string) (*PrinterState, error) {
-	filename := sm.getStateFilename(printerIP)
+// printerKey es el ID canónico de la impresora (ver CanonicalPrinterID)
+func (sm *StateManager) LoadState(printerKey string) (*PrinterState, error) {
+	filename := sm.getStateFilename(printerKey)
 
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -41,61 +76,317 @@ func (sm *StateManager) LoadState(printerIP string) (*PrinterState, error) {
 	return &state, nil
 }
 
-// SaveState guarda el estado actual de una impresora (se sobrescribe)
-func (sm *StateManager) SaveState(printerIP string, counters CountersInfo) error {
-	state := PrinterState{
-		LastPollAt: time.Now().UTC(),
-		Counters:   counters,
+// SaveState guarda la lectura actual de contadores de una impresora
+// El resto del estado (historial de consumibles, envíos, secuencia, plan de
+// consultas, volumen) se conserva: lo actualizan ForecastSupplies, SaveEmitted,
+// NextSequence, PlanPoll y TrackUsage
+func (sm *StateManager) SaveState(printerKey string, counters CountersInfo) error {
+	var state PrinterState
+	if previous, err := sm.LoadState(printerKey); err == nil && previous != nil {
+		state = *previous
+	}
+	state.LastPollAt = time.Now().UTC()
+	state.Counters = counters
+
+	return sm.writeState(printerKey, state)
+}
+
+// EmitRecord es lo último que se envió de una impresora a un sink: la huella
+// de cada sección de la telemetría (ver telemetry.PlanEmission)
+type EmitRecord struct {
+	Sections map[string]string `json:"sections"`
+	EventAt  time.Time         `json:"event_at"` // último envío con datos (completo o delta)
+	FullAt   time.Time         `json:"full_at"`  // último snapshot completo
+}
+
+// LoadEmitted retorna lo último enviado de una impresora a un sink
+func (sm *StateManager) LoadEmitted(printerKey, sink string) (EmitRecord, bool) {
+	state, err := sm.LoadState(printerKey)
+	if err != nil || state == nil {
+		return EmitRecord{}, false
+	}
+	record, ok := state.Emitted[sink]
+	return record, ok
+}
+
+// SaveEmitted guarda lo último enviado de una impresora a un sink
+func (sm *StateManager) SaveEmitted(printerKey, sink string, record EmitRecord) error {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &PrinterState{}
 	}
+	if state.Emitted == nil {
+		state.Emitted = make(map[string]EmitRecord)
+	}
+	state.Emitted[sink] = record
+	return sm.writeState(printerKey, *state)
+}
 
+// NextSequence reserva el siguiente número de secuencia de una impresora
+// Crece en cada telemetría y sobrevive reinicios: ordena los eventos de la
+// impresora aunque la queue los entregue desordenados
+func (sm *StateManager) NextSequence(printerKey string) (uint64, error) {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return 0, err
+	}
+	if state == nil {
+		state = &PrinterState{}
+	}
+	state.Sequence++
+	if err := sm.writeState(printerKey, *state); err != nil {
+		return 0, err
+	}
+	return state.Sequence, nil
+}
+
+// PeekSequence retorna el número que reservará el próximo NextSequence, sin
+// reservarlo: la telemetría lo lleva y NextSequence lo confirma recién cuando
+// quedó en la queue, así una telemetría descartada no gasta un número
+func (sm *StateManager) PeekSequence(printerKey string) (uint64, error) {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return 0, err
+	}
+	if state == nil {
+		return 1, nil
+	}
+	return state.Sequence + 1, nil
+}
+
+// writeState escribe el archivo de estado de una impresora
+func (sm *StateManager) writeState(printerKey string, state PrinterState) error {
+	if sm.scanID != "" {
+		state.ScanID = sm.scanID
+	}
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	filename := sm.getStateFilename(printerIP)
-	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+	// Escritura atómica: un crash a mitad nunca deja JSON truncado
+	filename := sm.getStateFilename(printerKey)
+	if err := fsutil.WriteFileAtomic(filename, data, 0644); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// slowDevicesFile guarda las IPs que no terminaron en el último ciclo
+const slowDevicesFile = "_slow_devices.json"
+
+// SaveSlowDevices guarda las IPs lentas o pendientes del último ciclo (se sobrescribe)
+func (sm *StateManager) SaveSlowDevices(ips []string) error {
+	if ips == nil {
+		ips = []string{}
+	}
+
+	data, err := json.MarshalIndent(ips, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, slowDevicesFile), data, 0644)
+}
+
+// LoadSlowDevices carga las IPs lentas del ciclo anterior (vacío si no hay)
+func (sm *StateManager) LoadSlowDevices() []string {
+	data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, slowDevicesFile))
+	if err != nil {
+		return nil
+	}
+
+	var ips []string
+	if err := json.Unmarshal(data, &ips); err != nil {
+		return nil
+	}
+	return ips
+}
+
+// meterCloseFile guarda el período del último cierre de facturación exportado
+const meterCloseFile = "_meters_close.json"
+
+// meterClose es el contenido de _meters_close.json
+type meterClose struct {
+	Period   string    `json:"period"`
+	ClosedAt time.Time `json:"closed_at"`
+}
+
+// SaveMeterClose registra que el cierre de facturación de period ya se exportó
+func (sm *StateManager) SaveMeterClose(period string) error {
+	data, err := json.MarshalIndent(meterClose{Period: period, ClosedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, meterCloseFile), data, 0644)
+}
+
+// LoadMeterClose retorna el período del último cierre exportado ("" si nunca hubo)
+func (sm *StateManager) LoadMeterClose() string {
+	data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, meterCloseFile))
+	if err != nil {
+		return ""
+	}
+
+	var mc meterClose
+	if err := json.Unmarshal(data, &mc); err != nil {
+		return ""
+	}
+	return mc.Period
+}
+
 // CalculateDelta calcula la diferencia entre estado actual y anterior
 // Retorna nil si hay reset o no hay estado anterior
 // También retorna un booleano indicando si se detectó un reset
-func (sm *StateManager) CalculateDelta(printerIP string, currentCounters CountersInfo) (*CountersDiff, bool) {
-	previousState, err := sm.LoadState(printerIP)
+func (sm *StateManager) CalculateDelta(printerKey string, currentCounters CountersInfo) (*CountersDiff, bool) {
+	previousState, err := sm.LoadState(printerKey)
 	if err != nil {
 		return nil, false
 	}
 
 	// Si no hay estado anterior, no hay delta (primer poll)
-	if previousState == nil {
+	// Un estado sin LastPollAt solo tiene historial de consumibles o de envíos
+	if previousState == nil || previousState.LastPollAt.IsZero() {
 		return nil, false
 	}
 
-	// Detectar resets: si actual < anterior, es un reset
-	if currentCounters.TotalPages < previousState.Counters.TotalPages {
+	prev := previousState.Counters
+
+	// Detectar resets: si actual < anterior y no es una vuelta de Counter32, es un reset
+	if currentCounters.TotalPages < prev.TotalPages && !isCounter32Wrap(prev.TotalPages, currentCounters.TotalPages) {
 		return nil, true // delta = nil cuando hay reset, pero reset_detected = true
 	}
 
-	// Calcular delta
+	// Calcular delta (tolerando la vuelta de contadores de 32 bits)
 	delta := &CountersDiff{
-		TotalPages: currentCounters.TotalPages - previousState.Counters.TotalPages,
-		MonoPages:  currentCounters.MonoPages - previousState.Counters.MonoPages,
-		ColorPages: currentCounters.ColorPages - previousState.Counters.ColorPages,
-		ScanPages:  currentCounters.ScanPages - previousState.Counters.ScanPages,
-		CopyPages:  currentCounters.CopyPages - previousState.Counters.CopyPages,
-		FaxPages:   currentCounters.FaxPages - previousState.Counters.FaxPages,
+		TotalPages:  counterDelta(prev.TotalPages, currentCounters.TotalPages),
+		MonoPages:   counterDelta(prev.MonoPages, currentCounters.MonoPages),
+		ColorPages:  counterDelta(prev.ColorPages, currentCounters.ColorPages),
+		ScanPages:   counterDelta(prev.ScanPages, currentCounters.ScanPages),
+		CopyPages:   counterDelta(prev.CopyPages, currentCounters.CopyPages),
+		FaxPages:    counterDelta(prev.FaxPages, currentCounters.FaxPages),
+		DuplexPages: optionalCounterDelta(prev.DuplexPages, currentCounters.DuplexPages),
+		A3Pages:     optionalCounterDelta(prev.A3Pages, currentCounters.A3Pages),
 	}
 
 	return delta, false
 }
 
+// counter32Modulus es el rango de un Counter32 (RFC 2578: da la vuelta en 2^32)
+const counter32Modulus = int64(1) << 32
+
+// isCounter32Wrap indica si current < previous se explica por la vuelta de un Counter32:
+// el valor anterior estaba en el cuarto superior del rango y el actual en el inferior
+// Un reset real (cambio de placa, reinicio de contadores) vuelve a valores bajos
+// desde cualquier punto, por lo que solo se asume vuelta cerca del tope
+func isCounter32Wrap(previous, current int64) bool {
+	return previous < counter32Modulus &&
+		previous >= counter32Modulus/4*3 &&
+		current >= 0 && current < counter32Modulus/4
+}
+
+// counterDelta calcula current - previous considerando la vuelta de Counter32
+func counterDelta(previous, current int64) int64 {
+	if current < previous && isCounter32Wrap(previous, current) {
+		return current + counter32Modulus - previous
+	}
+	return current - previous
+}
+
+// optionalCounterDelta es counterDelta para contadores que no todos los equipos
+// (ni los estados guardados por versiones anteriores) reportan: sin valor previo
+// no hay delta, en vez de facturar el acumulado completo como consumo del período
+func optionalCounterDelta(previous, current int64) int64 {
+	if previous == 0 {
+		return 0
+	}
+	return counterDelta(previous, current)
+}
+
+// MigrateKey renombra el estado guardado bajo oldKey (ej: IP) a newKey (ID canónico)
+// No hace nada si no hay estado antiguo o si ya existe estado con la clave nueva
+func (sm *StateManager) MigrateKey(oldKey, newKey string) error {
+	if oldKey == "" || newKey == "" || oldKey == newKey {
+		return nil
+	}
+
+	oldFile := sm.getStateFilename(oldKey)
+	newFile := sm.getStateFilename(newKey)
+
+	if _, err := os.Stat(oldFile); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(newFile); err == nil {
+		return nil // Ya migrado: conservar el estado más reciente
+	}
+
+	return os.Rename(oldFile, newFile)
+}
+
+// MigrateIndex migra todos los estados indexados por IP a su ID canónico
+// Se usa al arrancar con el índice IP → PrinterID del gestor de perfiles
+func (sm *StateManager) MigrateIndex(index map[string]string) int {
+	migrated := 0
+	for ip, printerID := range index {
+		if ip == printerID {
+			continue
+		}
+		if _, err := os.Stat(sm.getStateFilename(ip)); err != nil {
+			continue
+		}
+		if err := sm.MigrateKey(ip, printerID); err != nil {
+			fmt.Println(i18n.T("log.state_rekey_error", ip, printerID, err))
+			continue
+		}
+		migrated++
+	}
+	return migrated
+}
+
 // getStateFilename retorna la ruta del archivo de estado para una impresora
-func (sm *StateManager) getStateFilename(printerIP string) string {
-	// Sanitizar IP para usarla como filename (reemplazar puntos)
-	sanitized := printerIP // puede mejorar si es necesario
-	return filepath.Join(sm.stateDir, fmt.Sprintf("printer_%s.json", sanitized))
+func (sm *StateManager) getStateFilename(printerKey string) string {
+	return filepath.Join(sm.stateDir, fmt.Sprintf("printer_%s.json", sanitizeKey(printerKey)))
+}
+
+// sanitizeKey adapta una clave de impresora para usarla como filename
+func sanitizeKey(printerKey string) string {
+	sanitized := printerKey
+	for _, ch := range []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"} {
+		sanitized = strings.ReplaceAll(sanitized, ch, "_")
+	}
+	return sanitized
+}
+
+// tagsFile guarda los tags asignados por la API (ID de impresora → tags)
+const tagsFile = "_tags.json"
+
+// SaveTags reemplaza los tags asignados por la API
+func (sm *StateManager) SaveTags(tags map[string]map[string]string) error {
+	if tags == nil {
+		tags = map[string]map[string]string{}
+	}
+
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(sm.stateDir, tagsFile), data, 0644)
+}
+
+// LoadTags carga los tags asignados por la API (vacío si no hay)
+func (sm *StateManager) LoadTags() map[string]map[string]string {
+	data, err := ioutil.ReadFile(filepath.Join(sm.stateDir, tagsFile))
+	if err != nil {
+		return map[string]map[string]string{}
+	}
+
+	var tags map[string]map[string]string
+	if err := json.Unmarshal(data, &tags); err != nil || tags == nil {
+		return map[string]map[string]string{}
+	}
+	return tags
 }
@@ -0,0 +1,23 @@
+package collector
+
+import "testing"
+
+func TestSequenceReservedOnlyByNext(t *testing.T) {
+	sm := NewStateManager(t.TempDir())
+
+	// Sin estado previo la primera secuencia es 1
+	for i := 0; i < 2; i++ {
+		if seq, err := sm.PeekSequence("p1"); err != nil || seq != 1 {
+			t.Fatalf("PeekSequence = %d, %v; se esperaba 1", seq, err)
+		}
+	}
+	if seq, err := sm.NextSequence("p1"); err != nil || seq != 1 {
+		t.Fatalf("NextSequence = %d, %v; se esperaba 1", seq, err)
+	}
+	if seq, _ := sm.PeekSequence("p1"); seq != 2 {
+		t.Errorf("PeekSequence tras reservar = %d, se esperaba 2", seq)
+	}
+	if seq, _ := sm.PeekSequence("p2"); seq != 1 {
+		t.Errorf("otra impresora: %d, se esperaba 1", seq)
+	}
+}
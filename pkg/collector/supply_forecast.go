@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"time"
+)
+
+// SupplyHistory es el historial de un consumible entre polls: el nivel actual
+// y el nivel desde el que se mide el consumo (el último reemplazo o la primera lectura)
+type SupplyHistory struct {
+	Level      int64     `json:"level"`
+	At         time.Time `json:"at"`
+	StartLevel int64     `json:"start_level"`
+	StartAt    time.Time `json:"start_at"`
+	Pages      int64     `json:"pages,omitempty"`       // contador total de páginas en At
+	StartPages int64     `json:"start_pages,omitempty"` // contador total de páginas en StartAt
+}
+
+// minForecastWindow es el consumo observado mínimo para estimar un reemplazo:
+// con menos, un trabajo grande aislado da fechas sin sentido
+const minForecastWindow = 24 * time.Hour
+
+// maxForecastDays acota la estimación (un consumible casi sin uso no tiene fecha útil)
+const maxForecastDays = 3 * 365
+
+// ForecastSupplies estima la fecha de reemplazo de cada consumible con el
+// consumo medio desde su último reemplazo y guarda el historial en el estado
+// de la impresora. Aplica igual a tóner, drums, fusores y kits (el nivel baja
+// con el uso) y a los receptáculos (el nivel es el espacio libre). Un nivel
+// que sube es un reemplazo y reinicia la medición
+// Si el nivel no bajó lo suficiente (equipos que lo reportan en saltos de
+// 10-25%), se estima con las páginas impresas y el rendimiento del consumible
+func (sm *StateManager) ForecastSupplies(printerKey string, supplies []Supply, totalPages int64, now time.Time) error {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &PrinterState{}
+	}
+	history := make(map[string]SupplyHistory, len(supplies))
+
+	for i := range supplies {
+		s := &supplies[i]
+		if s.LevelState != LevelStateKnown {
+			// Sin nivel real no hay consumo que medir: conservar lo anterior
+			if h, ok := state.Supplies[s.Key]; ok {
+				history[s.Key] = h
+			}
+			continue
+		}
+
+		h, ok := state.Supplies[s.Key]
+		if !ok || s.Level > h.Level {
+			h = SupplyHistory{StartLevel: s.Level, StartAt: now, StartPages: totalPages}
+		}
+		if h.StartPages <= 0 || totalPages < h.StartPages {
+			h.StartPages = totalPages // historial previo sin páginas o contador reiniciado
+		}
+		h.Level = s.Level
+		h.At = now
+		h.Pages = totalPages
+		history[s.Key] = h
+
+		s.ReplaceBy = forecastReplacement(h)
+		if s.ReplaceBy == "" && !s.FillsUp {
+			s.ReplaceBy = forecastByPages(h, remainingYield(*s))
+		}
+	}
+
+	state.Supplies = history
+	return sm.writeState(printerKey, *state)
+}
+
+// forecastReplacement retorna la fecha (YYYY-MM-DD) en que el nivel llega a 0
+// al ritmo medio observado ("" sin consumo suficiente)
+func forecastReplacement(h SupplyHistory) string {
+	elapsed := h.At.Sub(h.StartAt)
+	used := h.StartLevel - h.Level
+	if elapsed < minForecastWindow || used <= 0 {
+		return ""
+	}
+	perDay := float64(used) / elapsed.Hours() * 24
+	days := float64(h.Level) / perDay
+	if days > maxForecastDays {
+		return ""
+	}
+	return h.At.Add(time.Duration(days * 24 * float64(time.Hour))).UTC().Format("2006-01-02")
+}
+
+// remainingYield son las páginas que le quedan al consumible según su
+// rendimiento: el reportado por el equipo o, si no, el nominal del catálogo
+func remainingYield(s Supply) int64 {
+	if s.RemainingPages > 0 {
+		return s.RemainingPages
+	}
+	capacity := s.PageCapacity
+	if capacity <= 0 {
+		capacity = s.RatedYield
+	}
+	return capacity * int64(s.Percentage) / 100
+}
+
+// forecastByPages retorna la fecha (YYYY-MM-DD) en que se imprimen las
+// páginas restantes al ritmo medio de impresión ("" sin datos suficientes)
+func forecastByPages(h SupplyHistory, remaining int64) string {
+	elapsed := h.At.Sub(h.StartAt)
+	printed := h.Pages - h.StartPages
+	if remaining <= 0 || elapsed < minForecastWindow || printed <= 0 {
+		return ""
+	}
+	perDay := float64(printed) / elapsed.Hours() * 24
+	days := float64(remaining) / perDay
+	if days > maxForecastDays {
+		return ""
+	}
+	return h.At.Add(time.Duration(days * 24 * float64(time.Hour))).UTC().Format("2006-01-02")
+}
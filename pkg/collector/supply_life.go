@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Columnas de prtMarkerSuppliesTable para la vida útil de los consumibles
+const (
+	oidSuppliesClass = "1.3.6.1.2.1.43.11.1.1.4" // prtMarkerSuppliesClass
+	oidSuppliesUnit  = "1.3.6.1.2.1.43.11.1.1.7" // prtMarkerSuppliesSupplyUnit
+)
+
+// Valores de prtMarkerSuppliesClass
+const (
+	SupplyClassConsumed   = "consumed"   // supplyThatIsConsumed: el nivel es lo que queda
+	SupplyClassReceptacle = "receptacle" // receptacleThatIsFilled: el nivel es el espacio libre
+)
+
+// supplyClassNames traduce prtMarkerSuppliesClass
+var supplyClassNames = map[string]string{
+	"3": SupplyClassConsumed,
+	"4": SupplyClassReceptacle,
+}
+
+// supplyUnitNames traduce prtMarkerSuppliesSupplyUnit (RFC 3805 y RFC 3805bis)
+var supplyUnitNames = map[string]string{
+	"3":  "ten_thousandths_inches",
+	"4":  "micrometers",
+	"7":  "impressions",
+	"8":  "sheets",
+	"11": "hours",
+	"12": "thousandths_ounces",
+	"13": "tenths_grams",
+	"14": "hundredths_fluid_ounces",
+	"15": "tenths_milliliters",
+	"16": "feet",
+	"17": "meters",
+	"18": "items",
+	"19": "percent",
+}
+
+// IsPageUnit indica si la unidad de un consumible cuenta páginas: el nivel de
+// un drum, fusor o kit en esa unidad son las páginas de vida que le quedan
+func IsPageUnit(unit string) bool {
+	return unit == "impressions" || unit == "sheets"
+}
+
+// lifePartTypes son las piezas de vida útil por prtMarkerSuppliesType, para
+// las que la descripción no matchea ninguna clave conocida
+var lifePartTypes = map[string]string{
+	"9":  "drum",         // opc
+	"10": "developer",    // developer
+	"15": "fusor",        // fuser
+	"18": "cleanerUnit",  // cleanerUnit
+	"20": "transferUnit", // transferUnit
+}
+
+// lifePartKey retorna la clave normalizada de una pieza de vida útil ("" si no lo es)
+func lifePartKey(typeCode string) string {
+	return lifePartTypes[strings.TrimSpace(typeCode)]
+}
+
+// supplyColumn retorna índice de consumible → valor de una columna de prtMarkerSuppliesTable
+func supplyColumn(walks *WalkCache, ctx *snmp.Context, column string) map[string]string {
+	values := make(map[string]string)
+	results, err := walks.Walk(column, ctx)
+	if err != nil {
+		return values
+	}
+	for _, result := range results {
+		oid := strings.TrimPrefix(result.OID, ".")
+		values[oid[strings.LastIndex(oid, ".")+1:]] = strings.TrimSpace(result.Value)
+	}
+	return values
+}
+
+// setSupplyLife agrega clase y unidad al consumible; en unidades de página el
+// nivel son las páginas restantes y el máximo la capacidad en páginas
+// Un receptáculo o un tipo de residuo se marca "fills_up": se llena con el uso
+func setSupplyLife(supplyInfo map[string]interface{}, classCode, unitCode, typeCode, level, max string) {
+	class := supplyClassNames[classCode]
+	if class != "" {
+		supplyInfo["class"] = class
+	}
+	if class == SupplyClassReceptacle || wasteTypeCodes[strings.TrimSpace(typeCode)] {
+		supplyInfo["fills_up"] = true
+	}
+	unit := supplyUnitNames[unitCode]
+	if unit == "" {
+		return
+	}
+	supplyInfo["unit"] = unit
+	if !IsPageUnit(unit) {
+		return
+	}
+	if pages, err := strconv.ParseInt(strings.TrimSpace(level), 10, 64); err == nil && pages >= 0 {
+		supplyInfo["remaining_pages"] = pages
+	}
+	if capacity, err := strconv.ParseInt(strings.TrimSpace(max), 10, 64); err == nil && capacity > 0 {
+		supplyInfo["page_capacity"] = capacity
+	}
+}
+
+// Columnas de finSupplyTable (Finisher MIB, RFC 3806): grapas, alambre y otros
+// consumibles del finalizador, que no aparecen en prtMarkerSuppliesTable
+const (
+	oidFinSupplyClass       = "1.3.6.1.2.1.43.31.1.3" // finSupplyClass
+	oidFinSupplyDescription = "1.3.6.1.2.1.43.31.1.5" // finSupplyDescription
+	oidFinSupplyUnit        = "1.3.6.1.2.1.43.31.1.6" // finSupplyUnit
+	oidFinSupplyMaxCapacity = "1.3.6.1.2.1.43.31.1.7" // finSupplyMaxCapacity
+	oidFinSupplyLevel       = "1.3.6.1.2.1.43.31.1.8" // finSupplyCurrentLevel
+)
+
+// wasteTypeCodes son los prtMarkerSuppliesType que se llenan con el uso
+var wasteTypeCodes = map[string]bool{
+	"4":  true, // wasteToner
+	"8":  true, // wasteInk
+	"14": true, // wasteWax
+	"24": true, // wasteWater
+	"26": true, // wastePaper
+}
+
+// collectFinisherSupplies retorna los consumibles del finalizador con las
+// mismas claves de mapa que prtMarkerSuppliesTable; las grapas usan la clave
+// "grapas" ("grapas_2"... si hay más de una unidad)
+func collectFinisherSupplies(walks *WalkCache, ctx *snmp.Context) map[string]interface{} {
+	supplies := make(map[string]interface{})
+
+	descriptions, err := walks.Walk(oidFinSupplyDescription, ctx)
+	if err != nil || len(descriptions) == 0 {
+		return supplies
+	}
+	classes := supplyColumn(walks, ctx, oidFinSupplyClass)
+	units := supplyColumn(walks, ctx, oidFinSupplyUnit)
+	maxes := supplyColumn(walks, ctx, oidFinSupplyMaxCapacity)
+	levels := supplyColumn(walks, ctx, oidFinSupplyLevel)
+
+	for _, result := range descriptions {
+		description := strings.TrimSpace(result.Value)
+		if description == "" {
+			continue
+		}
+		oid := strings.TrimPrefix(result.OID, ".")
+		index := oid[strings.LastIndex(oid, ".")+1:]
+
+		key := "finisher_" + strings.ToLower(strings.ReplaceAll(description, " ", "_"))
+		if strings.Contains(strings.ToLower(description), "staple") {
+			key = "grapas"
+		}
+		for n := 2; supplies[key] != nil; n++ {
+			key = strings.TrimSuffix(key, "_"+strconv.Itoa(n-1)) + "_" + strconv.Itoa(n)
+		}
+
+		supplyInfo := map[string]interface{}{
+			"description":    description,
+			"component_type": "finisher",
+		}
+		if level := levels[index]; level != "" {
+			supplyInfo["level"] = level
+		}
+		if max := maxes[index]; max != "" {
+			supplyInfo["max"] = max
+		}
+		setSupplyLife(supplyInfo, classes[index], units[index], "", levels[index], maxes[index])
+		supplies[key] = supplyInfo
+	}
+	return supplies
+}
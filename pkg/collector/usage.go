@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"time"
+)
+
+// minUsageWindow es el período mínimo observado para estimar el volumen de
+// impresión: con menos, un trabajo grande aislado da volúmenes sin sentido
+const minUsageWindow = 24 * time.Hour
+
+// maxUsageWindow reinicia la medición para que el volumen siga al uso
+// reciente (la última estimación se conserva mientras tanto)
+const maxUsageWindow = 90 * 24 * time.Hour
+
+// PageUsage es el volumen de impresión medio de una impresora, medido entre
+// lecturas de contadores desde StartAt
+type PageUsage struct {
+	StartAt     time.Time    `json:"start_at"`
+	Start       CountersInfo `json:"start"`
+	MonoPerDay  float64      `json:"mono_per_day"`
+	ColorPerDay float64      `json:"color_per_day"`
+	EstimatedAt time.Time    `json:"estimated_at,omitempty"` // cero = todavía sin estimación
+}
+
+// Known indica si hay una estimación de volumen
+func (u *PageUsage) Known() bool {
+	return u != nil && !u.EstimatedAt.IsZero()
+}
+
+// TrackUsage actualiza el volumen de impresión de una impresora con la lectura
+// actual y lo guarda en su estado. Un contador que baja (reset, cambio de
+// placa) reinicia la medición sin perder la última estimación
+// Equipos sin contadores mono/color separados cuentan el total como mono
+func (sm *StateManager) TrackUsage(printerKey string, counters CountersInfo, now time.Time) (*PageUsage, error) {
+	state, err := sm.LoadState(printerKey)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &PrinterState{}
+	}
+
+	usage := state.Usage
+	if usage == nil || counters.TotalPages < usage.Start.TotalPages ||
+		counters.MonoPages < usage.Start.MonoPages || counters.ColorPages < usage.Start.ColorPages {
+		restarted := PageUsage{StartAt: now, Start: counters}
+		if usage != nil {
+			restarted.MonoPerDay, restarted.ColorPerDay, restarted.EstimatedAt = usage.MonoPerDay, usage.ColorPerDay, usage.EstimatedAt
+		}
+		usage = &restarted
+	}
+
+	if elapsed := now.Sub(usage.StartAt); elapsed >= minUsageWindow {
+		days := elapsed.Hours() / 24
+		mono := counters.MonoPages - usage.Start.MonoPages
+		color := counters.ColorPages - usage.Start.ColorPages
+		if mono == 0 && color == 0 {
+			mono = counters.TotalPages - usage.Start.TotalPages
+		}
+		usage.MonoPerDay = float64(mono) / days
+		usage.ColorPerDay = float64(color) / days
+		usage.EstimatedAt = now
+		if elapsed > maxUsageWindow {
+			usage.StartAt, usage.Start = now, counters
+		}
+	}
+
+	state.Usage = usage
+	return usage, sm.writeState(printerKey, *state)
+}
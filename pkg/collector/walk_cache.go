@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/oids"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// prefetchSubtrees son los subárboles de Printer MIB que varios pasos de la
+// recolección recorren (contadores, consumibles, alertas). Se caminan una vez
+// y las columnas (ej: 43.11.1.1.6) se sirven filtrando el subárbol padre
+var prefetchSubtrees = []string{
+	"1.3.6.1.2.1.43.10", // prtMarker (contadores)
+	"1.3.6.1.2.1.43.11", // prtMarkerSupplies
+	"1.3.6.1.2.1.43.12", // prtMarkerColorant
+	"1.3.6.1.2.1.43.13", // prtMediaPath / estado
+}
+
+// walkEntry es el resultado de un WALK guardado en el cache
+// done se cierra cuando results/err están listos
+type walkEntry struct {
+	results []snmp.WalkResult
+	err     error
+	done    chan struct{}
+}
+
+// ready indica si el WALK de la entrada ya terminó
+func (e *walkEntry) ready() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// WalkCache guarda los WALKs de un dispositivo durante un poll
+// Cada subárbol se recorre como máximo una vez por dispositivo y por poll
+// Es seguro para las secciones que corren en paralelo: quien pide un subárbol
+// que otra sección está recorriendo espera ese mismo WALK
+type WalkCache struct {
+	client   *snmp.SNMPClient
+	fallback []string // OIDs directos adicionales (ej: contadores del fabricante)
+
+	mu      sync.Mutex
+	entries map[string]*walkEntry
+}
+
+// NewWalkCache crea un cache vacío para un cliente
+func NewWalkCache(client *snmp.SNMPClient) *WalkCache {
+	return &WalkCache{
+		client:  client,
+		entries: make(map[string]*walkEntry),
+	}
+}
+
+// SetFallback agrega OIDs para el GET directo cuando el WALK viene vacío
+// (además de las listas curadas de pkg/oids)
+func (wc *WalkCache) SetFallback(oidList []string) {
+	wc.fallback = oidList
+}
+
+// Prefetch recorre los subárboles indicados para servir sus columnas desde cache
+func (wc *WalkCache) Prefetch(ctx *snmp.Context, baseOIDs ...string) {
+	for _, baseOID := range baseOIDs {
+		wc.Walk(baseOID, ctx)
+	}
+}
+
+// Walk retorna el WALK de baseOID, desde cache si ya se recorrió el mismo
+// subárbol o uno que lo contiene
+func (wc *WalkCache) Walk(baseOID string, ctx *snmp.Context) ([]snmp.WalkResult, error) {
+	baseOID = strings.TrimPrefix(baseOID, ".")
+
+	wc.mu.Lock()
+	if entry, ok := wc.entries[baseOID]; ok {
+		wc.mu.Unlock()
+		<-entry.done
+		return entry.results, entry.err
+	}
+
+	// Buscar un ancestro ya recorrido con éxito
+	for cached, entry := range wc.entries {
+		if !entry.ready() || entry.err != nil || !strings.HasPrefix(baseOID, cached+".") {
+			continue
+		}
+
+		results := filterSubtree(entry.results, baseOID)
+		done := make(chan struct{})
+		close(done)
+		wc.entries[baseOID] = &walkEntry{results: results, done: done}
+		wc.mu.Unlock()
+		return results, nil
+	}
+
+	entry := &walkEntry{done: make(chan struct{})}
+	wc.entries[baseOID] = entry
+	wc.mu.Unlock()
+
+	results, err := wc.client.Walk(baseOID, ctx)
+
+	// gosnmp corta el WALK sin error ante noSuchName/noAccess/authorizationError:
+	// equipos que bloquean los subárboles suelen responder GETs directos
+	if err == nil && len(results) == 0 {
+		results = wc.getDirect(baseOID, ctx)
+	}
+
+	entry.results, entry.err = results, err
+	close(entry.done)
+	return results, err
+}
+
+// getDirect consulta por GET los OIDs curados del subárbol y los retorna
+// como si vinieran de un WALK (solo los que el agente respondió)
+func (wc *WalkCache) getDirect(baseOID string, ctx *snmp.Context) []snmp.WalkResult {
+	direct := oids.Directos(baseOID, wc.fallback...)
+	if len(direct) == 0 {
+		return nil
+	}
+
+	values, err := wc.client.GetMultiple(direct, ctx)
+	if err != nil {
+		return nil
+	}
+
+	var results []snmp.WalkResult
+	for _, oid := range direct {
+		typed, ok := values[oid].(snmp.Value)
+		if !ok || typed.IsNull() {
+			continue
+		}
+		results = append(results, snmp.WalkResult{
+			OID:   "." + oid,
+			Value: typed.String(),
+			Typed: typed,
+		})
+	}
+
+	if len(results) > 0 {
+		fmt.Println(i18n.T("log.walk_fallback", baseOID, len(results)))
+	}
+	return results
+}
+
+// filterSubtree retorna los resultados que pertenecen al subárbol baseOID
+func filterSubtree(results []snmp.WalkResult, baseOID string) []snmp.WalkResult {
+	var filtered []snmp.WalkResult
+	for _, result := range results {
+		oid := strings.TrimPrefix(result.OID, ".")
+		if oid == baseOID || strings.HasPrefix(oid, baseOID+".") {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,195 @@
+// Package cron interpreta expresiones cron de 5 campos y ventanas horarias
+// de exclusión (blackout) para agendar los ciclos de escaneo
+// Todo se evalúa en la zona horaria de los time.Time recibidos (la local del
+// agente): "0 7 * * *" es a las 07:00 del servidor
+package cron
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule es una expresión cron: minuto hora día-del-mes mes día-de-la-semana
+type Schedule struct {
+	expr   string
+	minute uint64 // bit i = minuto i (0-59)
+	hour   uint64 // 0-23
+	dom    uint64 // 1-31
+	month  uint64 // 1-12
+	dow    uint64 // 0-6 (0 = domingo)
+
+	// Con día del mes y día de la semana restringidos alcanza con que
+	// coincida uno de los dos (semántica de cron clásico)
+	domAny bool
+	dowAny bool
+}
+
+// macros son las abreviaturas habituales
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Nombres de meses y días aceptados en los campos (en inglés, como cron)
+var (
+	monthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	dayNames   = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// Parse interpreta "*/15 8-18 * * mon-fri", listas ("0,30"), rangos con paso
+// ("1-10/2"), nombres de mes y día, y las macros @hourly, @daily, @weekly y @monthly
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: se esperan 5 campos (minuto hora día mes día-semana)", expr)
+	}
+
+	s := &Schedule{expr: expr}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: minuto: %w", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: hora: %w", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: día del mes: %w", expr, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("cron %q: mes: %w", expr, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("cron %q: día de la semana: %w", expr, err)
+	}
+	if s.dow&(1<<7) != 0 { // 7 también es domingo
+		s.dow = (s.dow | 1) &^ (1 << 7)
+	}
+	s.domAny = fields[2] == "*" || fields[2] == "?"
+	s.dowAny = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+// String retorna la expresión original
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// parseField convierte un campo a un bitset de valores entre min y max
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("paso inválido en %q", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], names); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseValue(part, names)
+			if err != nil {
+				return 0, err
+			}
+			// "5/15" es desde 5 cada 15; sin paso, solo 5
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q fuera de rango (%d-%d)", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// parseValue convierte un número o un nombre ("mon", "jan")
+func parseValue(value string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido %q", value)
+	}
+	return v, nil
+}
+
+// matchDay aplica la regla de día del mes / día de la semana
+func (s *Schedule) matchDay(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowOK
+	case s.dowAny:
+		return domOK
+	}
+	return domOK || dowOK
+}
+
+// maxSearchYears acota la búsqueda de Next (p. ej. "0 0 30 2 *" nunca ocurre)
+const maxSearchYears = 5
+
+// Next retorna el primer instante posterior a after que coincide con la
+// expresión (cero si no ocurre en los próximos años)
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(maxSearchYears, 0, 0)
+	loc := t.Location()
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			// Salto directo al próximo minuto habilitado de esta hora
+			if rest := s.minute >> uint(t.Minute()); rest != 0 {
+				t = t.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+				continue
+			}
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// at arma una hora UTC de marzo de 2026 (el 2 es lunes)
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	for _, tc := range []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"*/15 8-18 * * mon-fri", at(2, 8, 0), at(2, 8, 15)},          // estrictamente posterior
+		{"*/15 8-18 * * mon-fri", at(2, 8, 7), at(2, 8, 15)},          // salto al próximo minuto habilitado
+		{"*/15 8-18 * * mon-fri", at(2, 18, 45), at(3, 8, 0)},         // fin de la jornada
+		{"*/15 8-18 * * mon-fri", at(6, 18, 50), at(9, 8, 0)},         // viernes → lunes
+		{"0 7 * * *", at(2, 7, 0).Add(30 * time.Second), at(3, 7, 0)}, // los segundos no cuentan
+		{"@daily", at(2, 12, 0), at(3, 0, 0)},
+		{"@hourly", at(2, 12, 59), at(2, 13, 0)},
+		{"@weekly", at(2, 0, 0), at(8, 0, 0)},   // domingo
+		{"0 0 * * 7", at(2, 0, 0), at(8, 0, 0)}, // 7 también es domingo
+		{"@monthly", at(2, 0, 0), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", at(2, 10, 6), at(2, 10, 25)}, // desde 5 cada 20
+		{"0,30 9 * * *", at(2, 9, 0), at(2, 9, 30)},   // lista
+		{"0 0 1-10/3 * *", at(2, 0, 0), at(4, 0, 0)},  // rango con paso: 1,4,7,10
+		{"0 0 29 feb *", at(2, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", at(2, 0, 0), at(6, 0, 0)}, // día del mes O de la semana
+		{"0 0 30 2 *", at(2, 0, 0), time.Time{}},   // nunca ocurre
+	} {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if got := s.Next(tc.after); !got.Equal(tc.want) {
+			t.Errorf("%s después de %v: %v, se esperaba %v", tc.expr, tc.after, got, tc.want)
+		}
+	}
+}
+
+// Next respeta la zona horaria recibida, también en el cambio de horario
+func TestNextLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skip("sin base de zonas horarias:", err)
+	}
+	s, err := Parse("0 7 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2026, 4, 4, 12, 0, 0, 0, loc) // la noche del 4 al 5 termina el horario de verano
+	got := s.Next(after)
+	if want := time.Date(2026, 4, 5, 7, 0, 0, 0, loc); !got.Equal(want) || got.Hour() != 7 {
+		t.Errorf("Next = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", "5 campos"},
+		{"@yearly", "5 campos"},
+		{"60 * * * *", "minuto"},
+		{"* 24 * * *", "hora"},
+		{"* * 0 * *", "día del mes"},
+		{"* * * 13 *", "mes"},
+		{"* * * * 8", "día de la semana"},
+		{"*/0 * * * *", "paso inválido"},
+		{"10-5 * * * *", "fuera de rango"},
+		{"* * * foo *", "valor inválido"},
+	} {
+		_, err := Parse(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, se esperaba uno con %q", tc.expr, err, tc.want)
+		}
+	}
+}
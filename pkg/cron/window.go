@@ -0,0 +1,135 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window es una franja horaria de exclusión: "08:00-10:00" todos los días o
+// "mon-fri 08:00-10:00" solo esos días. Una franja que cruza la medianoche
+// ("22:00-06:00") pertenece al día en que empieza
+type Window struct {
+	spec string
+	days uint8 // bit i = día i (0 = domingo)
+	from int   // minutos desde las 00:00
+	to   int   // minutos desde las 00:00 (hasta 24:00)
+}
+
+// ParseWindow interpreta "[días] HH:MM-HH:MM"; días acepta "mon-fri", "sat,sun"
+func ParseWindow(spec string) (Window, error) {
+	w := Window{spec: spec, days: 0x7f}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+	case 2:
+		days, err := parseField(fields[0], 0, 7, dayNames)
+		if err != nil {
+			return w, fmt.Errorf("blackout %q: días: %w", spec, err)
+		}
+		if days&(1<<7) != 0 {
+			days = (days | 1) &^ (1 << 7)
+		}
+		w.days = uint8(days)
+		fields = fields[1:]
+	default:
+		return w, fmt.Errorf("blackout %q: se espera \"[días] HH:MM-HH:MM\"", spec)
+	}
+
+	bounds := strings.SplitN(fields[0], "-", 2)
+	if len(bounds) != 2 {
+		return w, fmt.Errorf("blackout %q: se espera HH:MM-HH:MM", spec)
+	}
+	var err error
+	if w.from, err = parseClock(bounds[0]); err != nil {
+		return w, fmt.Errorf("blackout %q: %w", spec, err)
+	}
+	if w.to, err = parseClock(bounds[1]); err != nil {
+		return w, fmt.Errorf("blackout %q: %w", spec, err)
+	}
+	if w.from == w.to {
+		return w, fmt.Errorf("blackout %q: la franja está vacía", spec)
+	}
+	return w, nil
+}
+
+// parseClock convierte "HH:MM" (00:00-24:00) a minutos
+func parseClock(value string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(value, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("hora inválida %q", value)
+	}
+	return h*60 + m, nil
+}
+
+// String retorna la franja tal como se configuró
+func (w Window) String() string {
+	return w.spec
+}
+
+// end retorna el fin de la franja que contiene t (cero si no la contiene)
+func (w Window) end(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	minute := t.Hour()*60 + t.Minute()
+
+	// Franja que empezó hoy
+	if w.days&(1<<uint(t.Weekday())) != 0 && minute >= w.from {
+		if w.from < w.to && minute < w.to {
+			return midnight.Add(time.Duration(w.to) * time.Minute)
+		}
+		if w.from > w.to { // cruza la medianoche: termina mañana
+			return midnight.AddDate(0, 0, 1).Add(time.Duration(w.to) * time.Minute)
+		}
+	}
+	// Franja que empezó ayer y cruza la medianoche
+	yesterday := midnight.AddDate(0, 0, -1)
+	if w.from > w.to && w.days&(1<<uint(yesterday.Weekday())) != 0 && minute < w.to {
+		return midnight.Add(time.Duration(w.to) * time.Minute)
+	}
+	return time.Time{}
+}
+
+// Blackout es un conjunto de franjas en las que no se escanea
+type Blackout []Window
+
+// ParseBlackout interpreta una lista de franjas
+func ParseBlackout(specs []string) (Blackout, error) {
+	var b Blackout
+	for _, spec := range specs {
+		w, err := ParseWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, w)
+	}
+	return b, nil
+}
+
+// Active retorna la franja que contiene t, si hay una
+func (b Blackout) Active(t time.Time) (Window, bool) {
+	for _, w := range b {
+		if !w.end(t).IsZero() {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// Postpone mueve t al final de las franjas que lo contienen (encadenadas:
+// "08:00-10:00" y "10:00-12:00" llevan las 09:00 a las 12:00)
+// Sin franja activa retorna t
+func (b Blackout) Postpone(t time.Time) time.Time {
+	for i := 0; i <= len(b)*8; i++ { // 8 = días de la semana + 1: cota de franjas encadenadas
+		moved := false
+		for _, w := range b {
+			if end := w.end(t); !end.IsZero() {
+				t = end
+				moved = true
+			}
+		}
+		if !moved {
+			return t
+		}
+	}
+	return t
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlackoutActive(t *testing.T) {
+	b, err := ParseBlackout([]string{"mon-fri 08:00-10:00", "sat 22:00-06:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		at     time.Time
+		active string
+	}{
+		{at(2, 8, 0), "mon-fri 08:00-10:00"}, // lunes, el inicio está incluido
+		{at(2, 9, 59), "mon-fri 08:00-10:00"},
+		{at(2, 10, 0), ""}, // el fin no
+		{at(8, 9, 0), ""},  // domingo: solo días hábiles
+		{at(7, 23, 0), "sat 22:00-06:00"},
+		{at(8, 5, 59), "sat 22:00-06:00"}, // domingo de madrugada: empezó el sábado
+		{at(9, 5, 0), ""},                 // lunes de madrugada: el domingo no tiene franja
+	} {
+		w, ok := b.Active(tc.at)
+		if got := w.String(); ok != (tc.active != "") || got != tc.active {
+			t.Errorf("%v: activa %q (%v), se esperaba %q", tc.at, got, ok, tc.active)
+		}
+	}
+}
+
+func TestBlackoutPostpone(t *testing.T) {
+	b, err := ParseBlackout([]string{"08:00-10:00", "10:00-12:00", "fri 23:00-24:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		at, want time.Time
+	}{
+		{at(2, 9, 0), at(2, 12, 0)}, // franjas encadenadas
+		{at(2, 7, 0), at(2, 7, 0)},  // sin franja activa
+		{at(6, 23, 30), at(7, 0, 0)},
+	} {
+		if got := b.Postpone(tc.at); !got.Equal(tc.want) {
+			t.Errorf("Postpone(%v) = %v, se esperaba %v", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestParseWindowErrors(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want string
+	}{
+		{"08:00", "HH:MM-HH:MM"},
+		{"mon fri 08:00-10:00", "[días]"},
+		{"lun 08:00-10:00", "días"},
+		{"08:00-25:00", "hora inválida"},
+		{"24:30-01:00", "hora inválida"},
+		{"08:60-10:00", "hora inválida"},
+		{"08:00-08:00", "vacía"},
+	} {
+		_, err := ParseWindow(tc.spec)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, se esperaba uno con %q", tc.spec, err, tc.want)
+		}
+	}
+	if _, err := ParseBlackout([]string{"08:00-10:00", "x"}); err == nil {
+		t.Error("ParseBlackout aceptó una franja inválida")
+	}
+}
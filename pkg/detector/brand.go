@@ -34,7 +34,7 @@ func DetectBrand(sysDescr string) string {
 	}
 
 	// Konica Minolta
-	if matchesPatterns(descLower, []string{"konica", "minolta", "bizhub", "accurio"}) {
+	if matchesPatterns(descLower, []string{"konica", "minolta", "bizhub", "accurio", "ineo"}) {
 		return "KonicaMinolta"
 	}
 
@@ -63,6 +63,16 @@ func DetectBrand(sysDescr string) string {
 		return "Samsung"
 	}
 
+	// Lexmark
+	if matchesPatterns(descLower, []string{"lexmark"}) {
+		return "Lexmark"
+	}
+
+	// Epson
+	if matchesPatterns(descLower, []string{"epson", "workforce", "wf-c", "wf-m"}) {
+		return "Epson"
+	}
+
 	// Generic / Unknown
 	return "Generic"
 }
@@ -120,6 +130,36 @@ func GetBrandConfidence(sysDescr string, brand string) float64 {
 		} else if strings.Contains(descLower, "samsung") {
 			return 0.96
 		}
+	case "KonicaMinolta":
+		if strings.Contains(descLower, "konica minolta") && strings.Contains(descLower, "bizhub") {
+			return 0.99
+		} else if strings.Contains(descLower, "konica") || strings.Contains(descLower, "minolta") {
+			return 0.96
+		} else if strings.Contains(descLower, "bizhub") {
+			return 0.92
+		}
+	case "Lexmark":
+		if strings.Contains(descLower, "lexmark") {
+			return 0.98
+		}
+	case "Epson":
+		if strings.Contains(descLower, "epson") && strings.Contains(descLower, "workforce") {
+			return 0.99
+		} else if strings.Contains(descLower, "epson") {
+			return 0.96
+		}
+	case "Sharp":
+		if strings.Contains(descLower, "sharp") && strings.Contains(descLower, "mx-") {
+			return 0.98
+		} else if strings.Contains(descLower, "sharp") {
+			return 0.95
+		}
+	case "Toshiba":
+		if strings.Contains(descLower, "toshiba") && strings.Contains(descLower, "e-studio") {
+			return 0.99
+		} else if strings.Contains(descLower, "e-studio") {
+			return 0.95
+		}
 	case "Generic":
 		return 0.50 // Baja confianza para Generic
 	}
@@ -0,0 +1,78 @@
+package detector
+
+import (
+	"strings"
+)
+
+// enterprisePrefix es la raíz de los árboles privados (IANA Private Enterprise Numbers)
+const enterprisePrefix = "1.3.6.1.4.1."
+
+// enterpriseBrands mapea el Private Enterprise Number de cada fabricante a su marca
+// sysObjectID y los OIDs propietarios cuelgan de 1.3.6.1.4.1.<PEN>
+var enterpriseBrands = map[string]string{
+	"11":    "HP",
+	"253":   "Xerox",
+	"2435":  "Brother",
+	"367":   "Ricoh",
+	"1602":  "Canon",
+	"18334": "KonicaMinolta",
+	"2001":  "OKI",
+	"1347":  "Kyocera",
+	"2385":  "Sharp",
+	"1129":  "Toshiba",
+	"236":   "Samsung",
+	"641":   "Lexmark",
+	"1248":  "Epson",
+}
+
+// BrandFromOID retorna la marca dueña del árbol privado al que pertenece oid
+// Sirve tanto para sysObjectID como para OIDs propietarios que respondieron
+// Retorna "" si el OID no es de un fabricante conocido
+func BrandFromOID(oid string) string {
+	oid = strings.TrimPrefix(strings.TrimSpace(oid), ".")
+	if !strings.HasPrefix(oid, enterprisePrefix) {
+		return ""
+	}
+
+	pen := strings.TrimPrefix(oid, enterprisePrefix)
+	if i := strings.Index(pen, "."); i >= 0 {
+		pen = pen[:i]
+	}
+	return enterpriseBrands[pen]
+}
+
+// EnterpriseRoot retorna la raíz del árbol privado de una marca ("" si no se conoce)
+func EnterpriseRoot(brand string) string {
+	for pen, b := range enterpriseBrands {
+		if b == brand {
+			return enterprisePrefix + pen
+		}
+	}
+	return ""
+}
+
+// calibrationPrior es cuántas observaciones "vale" la confianza estática de
+// GetBrandConfidence: con pocas observaciones manda el sysDescr, con muchas
+// manda lo que efectivamente respondió el dispositivo
+const calibrationPrior = 5.0
+
+// CalibrateConfidence ajusta la confianza base de una marca con la evidencia
+// acumulada: hits son ciclos donde los OIDs del fabricante respondieron,
+// misses donde no respondieron o el árbol privado era de otra marca
+func CalibrateConfidence(base float64, hits, misses int) float64 {
+	if hits < 0 {
+		hits = 0
+	}
+	if misses < 0 {
+		misses = 0
+	}
+
+	confidence := (base*calibrationPrior + float64(hits)) / (calibrationPrior + float64(hits+misses))
+	if confidence > 0.99 {
+		confidence = 0.99
+	}
+	if confidence < 0.01 {
+		confidence = 0.01
+	}
+	return confidence
+}
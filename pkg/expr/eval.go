@@ -0,0 +1,381 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Env son las variables de la evaluación: mapas, listas y escalares como los
+// que deja encoding/json en un interface{}
+type Env map[string]interface{}
+
+// Eval evalúa la expresión; los errores son de tipos (`"a" < 1`)
+func (e *Expr) Eval(env Env) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// Bool evalúa la expresión como condición (ver Truthy)
+func (e *Expr) Bool(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(v), nil
+}
+
+// Truthy es la verdad de un valor: null, false, 0, "" y las colecciones
+// vacías son falsos
+func Truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// Format formatea un valor para un mensaje: null = "?", números sin decimales de más
+func Format(v interface{}) string {
+	switch v := normalize(v).(type) {
+	case nil:
+		return "?"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// node es un nodo del árbol de la expresión
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literal struct{ value interface{} }
+
+type variable struct{ name string }
+
+type list struct{ items []node }
+
+// index es target.campo o target[clave]
+type index struct{ target, key node }
+
+type unary struct {
+	op      string
+	operand node
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+// logical es && o || con cortocircuito; retorna un bool
+type logical struct {
+	or          bool
+	left, right node
+}
+
+type call struct {
+	name string
+	fn   function
+	args []node
+	re   *regexp.Regexp // regex literal de matches, compilada al compilar
+}
+
+func (n *literal) eval(Env) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *variable) eval(env Env) (interface{}, error) {
+	return normalize(env[n.name]), nil
+}
+
+func (n *list) eval(env Env) (interface{}, error) {
+	items := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// eval de un campo inexistente (o de null) es null
+func (n *index) eval(env Env) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch target := target.(type) {
+	case map[string]interface{}:
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("un objeto se indexa con un string, no con %s", typeName(key))
+		}
+		return normalize(target[k]), nil
+	case []interface{}:
+		i, ok := key.(float64)
+		if !ok {
+			return nil, fmt.Errorf("una lista se indexa con un número, no con %s", typeName(key))
+		}
+		if i < 0 || int(i) >= len(target) || i != math.Trunc(i) {
+			return nil, nil
+		}
+		return normalize(target[int(i)]), nil
+	}
+	return nil, nil
+}
+
+func (n *unary) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !Truthy(v), nil
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return -v, nil
+	}
+	return nil, fmt.Errorf("- no se aplica a %s", typeName(v))
+}
+
+func (n *logical) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(left) == n.or {
+		return n.or, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return Truthy(right), nil
+}
+
+func (n *binary) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	}
+
+	// Aritmética: null se propaga (un contador ausente no es 0)
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if n.op == "+" {
+		if l, ok := left.(string); ok {
+			return l + Format(right), nil
+		}
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s entre %s y %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, nil
+		}
+		return l / r, nil
+	default: // %
+		if r == 0 {
+			return nil, nil
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+func (n *call) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	if n.re != nil {
+		s, ok := args[0].(string)
+		return ok && n.re.MatchString(s), nil
+	}
+	v, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+// equal compara valores del mismo tipo; tipos distintos nunca son iguales
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case float64, string, bool:
+		return a == b
+	}
+	return false
+}
+
+// compare aplica <, <=, >, >= a números o strings; con null es falso
+func compare(op string, a, b interface{}) (interface{}, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+	var c int
+	switch a := a.(type) {
+	case float64:
+		n, ok := b.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s entre número y %s", op, typeName(b))
+		}
+		switch {
+		case a < n:
+			c = -1
+		case a > n:
+			c = 1
+		}
+	case string:
+		s, ok := b.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s entre string y %s", op, typeName(b))
+		}
+		c = strings.Compare(a, s)
+	default:
+		return nil, fmt.Errorf("%s no se aplica a %s", op, typeName(a))
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// contains es `item in collection`: elemento de una lista, clave de un
+// objeto o substring
+func contains(collection, item interface{}) (interface{}, error) {
+	switch c := collection.(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, v := range c {
+			if equal(normalize(v), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		k, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[k]
+		return found, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("in sobre un string requiere un string, no %s", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	}
+	return nil, fmt.Errorf("in no se aplica a %s", typeName(collection))
+}
+
+// normalize lleva los números a float64 (el Env puede venir de código Go, no
+// solo de encoding/json)
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	}
+	return v
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "número"
+	case string:
+		return "string"
+	case []interface{}:
+		return "lista"
+	case map[string]interface{}:
+		return "objeto"
+	}
+	return fmt.Sprintf("%T", v)
+}
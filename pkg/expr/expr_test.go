@@ -0,0 +1,167 @@
+package expr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testEnv es una telemetría reducida, como la deja encoding/json
+func testEnv(t *testing.T) Env {
+	t.Helper()
+	var env Env
+	err := json.Unmarshal([]byte(`{
+		"printer": {"brand": "HP", "ip": "10.0.0.5", "tags": {"cost center": "CC-12"}},
+		"supplies": {"tonerBlack": {"percentage": 12, "status": "low"}},
+		"supply_list": [{"id": "toner_black"}, {"id": "drum"}],
+		"counters": {"total_pages": 150000, "delta": {"total_pages": 6000}}
+	}`), &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func TestEval(t *testing.T) {
+	env := testEnv(t)
+	env["pages"] = int64(40) // desde código Go, no desde JSON
+
+	for _, tc := range []struct {
+		src  string
+		want interface{}
+	}{
+		{`supplies.tonerBlack.percentage < 15 && printer.brand == "HP"`, true},
+		{`printer.tags["cost center"] == 'CC-12'`, true},
+		{`counters.delta.total_pages > 5000 || false`, true},
+		{`supplies.drum.percentage < 15`, false},                 // consumible que no existe: null
+		{`supplies.drum.percentage >= 0`, false},                 // orden con null siempre falso
+		{`supplies.drum.percentage == null`, true},               // pero se puede preguntar por él
+		{`counters.total_pages + supplies.drum.percentage`, nil}, // la aritmética propaga null
+		{`printer.brand in ["HP", "Canon"]`, true},
+		{`"tags" in printer`, true},
+		{`"10.0" in printer.ip`, true},
+		{`supply_list[1].id`, "drum"},
+		{`supply_list[2].id`, nil},
+		{`supply_list[0.5]`, nil},
+		{`!(1 + 2 * 3 == 7)`, false},
+		{`-pages % 7`, -5.0},
+		{`counters.total_pages / 0`, nil},
+		{`"Tóner " + supplies.tonerBlack.percentage + "%"`, "Tóner 12%"},
+		{`lower(printer.brand) == "hp" && upper("x") == "X"`, true},
+		{`startsWith(printer.ip, "10.") && endsWith(printer.ip, ".5")`, true},
+		{`matches(printer.ip, "^10\\.0\\.")`, true},
+		{`matches(printer.brand, lower("H") + ".")`, false},
+		{`contains(supply_list[0].id, "black")`, true},
+		{`len(supply_list) + len("año") + len(null)`, 5.0},
+		{`min(3, null, 1, 2)`, 1.0},
+		{`max([4, 9, 2])`, 9.0},
+		{`max(null)`, nil},
+		{`"b" > "a"`, true},
+		{`1 == "1"`, false},
+		{`null || 0 || "" || []`, false},
+	} {
+		e, err := Compile(tc.src)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		got, err := e.Eval(env)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s = %#v, se esperaba %#v", tc.src, got, tc.want)
+		}
+	}
+}
+
+// Los errores de tipos aparecen al evaluar; el cortocircuito los evita
+func TestEvalTypeErrors(t *testing.T) {
+	env := testEnv(t)
+	for _, src := range []string{
+		`printer.brand < 15`,
+		`printer.brand * 2`,
+		`-printer.brand`,
+		`printer[1]`,
+		`supply_list["id"]`,
+		`1 in "HP"`,
+		`1 in 2`,
+		`lower(1)`,
+		`min("a")`,
+		`len(true)`,
+		`matches(printer.ip, printer)`,
+	} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Errorf("%s: no compiló: %v", src, err)
+			continue
+		}
+		if _, err := e.Eval(env); err == nil {
+			t.Errorf("%s: se esperaba un error de tipos", src)
+		}
+	}
+
+	e, err := Compile(`false && printer.brand < 15`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := e.Bool(env); ok || err != nil {
+		t.Errorf("cortocircuito: %v, %v", ok, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{`printer.brand ==`, "termina antes de tiempo"},
+		{`"HP`, "string sin cerrar"},
+		{`printer.brand # 1`, "carácter inesperado"},
+		{`1 2`, "sobra"},
+		{`(1 + 2`, `se esperaba ")"`},
+		{`printer.`, "se esperaba un campo"},
+		{`1.2.3`, "número inválido"},
+		{`foo(1)`, "función desconocida"},
+		{`lower()`, "recibe 1 argumento"},
+		{`min()`, "al menos 1 argumentos"},
+		{`matches(printer.ip, "[")`, "regex"},
+		{`matches(printer.ip, 1)`, "regex como string"},
+	} {
+		_, err := Compile(tc.src)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, se esperaba uno con %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	env := testEnv(t)
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"Tóner negro en {supplies.tonerBlack.percentage}% ({printer.ip})", "Tóner negro en 12% (10.0.0.5)"},
+		{"{{literal}} y {printer.brand}", "{literal} y HP"},
+		{"sin dato: {supplies.drum.percentage}", "sin dato: ?"},
+		{"con error: {printer.brand * 2}", "con error: ?"},
+		{"lista: {supply_list[0]}", `lista: {"id":"toner_black"}`},
+		{"{counters.delta.total_pages / 1000} mil páginas", "6 mil páginas"},
+	} {
+		tmpl, err := CompileTemplate(tc.src)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		if got := tmpl.Render(env); got != tc.want {
+			t.Errorf("%s: %q, se esperaba %q", tc.src, got, tc.want)
+		}
+	}
+
+	for _, src := range []string{"sin cerrar {printer.ip", "inválida {printer.}"} {
+		if _, err := CompileTemplate(src); err == nil {
+			t.Errorf("%s: se esperaba un error", src)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// function es una función de la expresión (maxArgs < 0 = variádica)
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []interface{}) (interface{}, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("al menos %d argumentos", f.minArgs)
+	case f.minArgs == 1 && f.maxArgs == 1:
+		return "1 argumento"
+	}
+	return fmt.Sprintf("%d argumentos", f.minArgs)
+}
+
+// functions son las funciones disponibles
+var functions = map[string]function{
+	"lower":      {1, 1, stringFunc(strings.ToLower)},
+	"upper":      {1, 1, stringFunc(strings.ToUpper)},
+	"contains":   {2, 2, func(args []interface{}) (interface{}, error) { return contains(args[0], args[1]) }},
+	"startsWith": {2, 2, stringTest(strings.HasPrefix)},
+	"endsWith":   {2, 2, stringTest(strings.HasSuffix)},
+	"matches":    {2, 2, matchesFunc},
+	"len":        {1, 1, lenFunc},
+	"min":        {1, -1, extremeFunc(func(a, b float64) bool { return a < b })},
+	"max":        {1, -1, extremeFunc(func(a, b float64) bool { return a > b })},
+}
+
+// stringFunc transforma un string; null queda null
+func stringFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		switch s := args[0].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return f(s), nil
+		}
+		return nil, fmt.Errorf("espera un string, no %s", typeName(args[0]))
+	}
+}
+
+// stringTest compara dos strings; con null es falso
+func stringTest(f func(s, affix string) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok1 := args[0].(string)
+		affix, ok2 := args[1].(string)
+		return ok1 && ok2 && f(s, affix), nil
+	}
+}
+
+// matchesFunc es matches(s, regex) con la regex calculada (la literal se
+// compila en parseCall)
+func matchesFunc(args []interface{}) (interface{}, error) {
+	pattern, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("la regex debe ser un string, no %s", typeName(args[1]))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := args[0].(string)
+	return ok && re.MatchString(s), nil
+}
+
+func lenFunc(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return float64(0), nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("no se aplica a %s", typeName(args[0]))
+}
+
+// extremeFunc es min/max sobre los argumentos o sobre una lista; ignora los null
+func extremeFunc(better func(a, b float64) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if items, ok := args[0].([]interface{}); ok && len(args) == 1 {
+			args = items
+		}
+		var best interface{}
+		for _, arg := range args {
+			switch v := normalize(arg).(type) {
+			case nil:
+			case float64:
+				if best == nil || better(v, best.(float64)) {
+					best = v
+				}
+			default:
+				return nil, fmt.Errorf("espera números, no %s", typeName(v))
+			}
+		}
+		return best, nil
+	}
+}
@@ -0,0 +1,390 @@
+// Package expr es un lenguaje de expresiones chico para las reglas de alerta
+// de config.yaml: `supplies.tonerBlack.percentage < 15 && printer.brand == "HP"`
+//
+//	literales   15  2.5  "texto"  'texto'  true  false  null  ["HP", "Canon"]
+//	campos      printer.brand  supplies.tonerBlack.percentage  tags["cost center"]
+//	operadores  ||  &&  !  ==  !=  <  <=  >  >=  in  +  -  *  /  %  ( )
+//	funciones   lower upper contains startsWith endsWith matches len min max
+//
+// Un campo que no existe vale null: las comparaciones de orden con null son
+// falsas, así una regla sobre un consumible que el equipo no tiene no dispara
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr es una expresión compilada; es segura para usar desde varias goroutines
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile interpreta la expresión
+func Compile(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("posición %d: sobra %q", tok.pos+1, tok.text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String retorna la expresión tal como se escribió
+func (e *Expr) String() string {
+	return e.src
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp // operadores y puntuación
+)
+
+type token struct {
+	kind tokenKind
+	text string // texto del token; en tokString, el valor sin comillas
+	pos  int
+}
+
+// operators se prueban en orden: los de dos caracteres primero
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", "."}
+
+// lex separa la expresión en tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, src[start:i], start})
+
+		case c == '"' || c == '\'':
+			start := i
+			var value strings.Builder
+			for i++; i < len(src) && rune(src[i]) != c; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				value.WriteByte(src[i])
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("posición %d: string sin cerrar", start+1)
+			}
+			i++
+			tokens = append(tokens, token{tokString, value.String(), start})
+
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, src[start:i], start})
+
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("posición %d: carácter inesperado %q", i+1, c)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// parser es un descenso recursivo por niveles de precedencia:
+// || < && < comparación < + - < * / % < unario < primario
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consume el operador (o palabra clave) si es el siguiente token
+func (p *parser) accept(text string) bool {
+	if tok := p.peek(); (tok.kind == tokOp || tok.kind == tokIdent) && tok.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			return fmt.Errorf("posición %d: se esperaba %q y terminó la expresión", tok.pos+1, text)
+		}
+		return fmt.Errorf("posición %d: se esperaba %q, no %q", tok.pos+1, text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseComparison no encadena: `a < b < c` es un error
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if p.peek().kind != tokOp || (op != "+" && op != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if p.peek().kind != tokOp || (op != "*" && op != "/" && op != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &unary{op: op, operand: operand}, nil
+		}
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix agrega los accesos .campo y [índice] al primario
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.next()
+			if tok.kind != tokIdent {
+				return nil, fmt.Errorf("posición %d: se esperaba un campo después de '.'", tok.pos+1)
+			}
+			n = &index{target: n, key: &literal{value: tok.text}}
+		case p.accept("["):
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &index{target: n, key: key}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("posición %d: número inválido %q", tok.pos+1, tok.text)
+		}
+		return &literal{value: f}, nil
+
+	case tokString:
+		return &literal{value: tok.text}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null":
+			return &literal{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		return &variable{name: tok.text}, nil
+
+	case tokOp:
+		switch tok.text {
+		case "(":
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &list{items: items}, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("posición %d: la expresión termina antes de tiempo", tok.pos+1)
+	}
+	return nil, fmt.Errorf("posición %d: inesperado %q", tok.pos+1, tok.text)
+}
+
+// parseList lee expresiones separadas por comas hasta end
+func (p *parser) parseList(end string) ([]node, error) {
+	var items []node
+	if p.accept(end) {
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.accept(end) {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseCall valida nombre y aridad al compilar; una regex literal de matches
+// se compila una sola vez
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("posición %d: función desconocida %q", name.pos+1, name.text)
+	}
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("posición %d: %s recibe %s", name.pos+1, name.text, fn.arity())
+	}
+
+	c := &call{name: name.text, fn: fn, args: args}
+	if name.text == "matches" {
+		if pattern, ok := args[1].(*literal); ok {
+			s, ok := pattern.value.(string)
+			if !ok {
+				return nil, fmt.Errorf("posición %d: matches espera una regex como string", name.pos+1)
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, fmt.Errorf("posición %d: regex %q: %w", name.pos+1, s, err)
+			}
+			c.re = re
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,70 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template es un texto con expresiones entre llaves:
+// "Tóner negro en {supplies.tonerBlack.percentage}% ({printer.ip})"
+// "{{" y "}}" son llaves literales
+type Template struct {
+	parts []templatePart
+}
+
+// templatePart es texto fijo o una expresión (expr != nil)
+type templatePart struct {
+	text string
+	expr *Expr
+}
+
+// CompileTemplate compila las expresiones del texto
+func CompileTemplate(src string) (*Template, error) {
+	t := &Template{}
+	var text strings.Builder
+	for i := 0; i < len(src); i++ {
+		switch {
+		case strings.HasPrefix(src[i:], "{{"), strings.HasPrefix(src[i:], "}}"):
+			text.WriteByte(src[i])
+			i++
+		case src[i] == '{':
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("posición %d: '{' sin cerrar", i+1)
+			}
+			e, err := Compile(src[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("{%s}: %w", src[i+1:i+end], err)
+			}
+			if text.Len() > 0 {
+				t.parts = append(t.parts, templatePart{text: text.String()})
+				text.Reset()
+			}
+			t.parts = append(t.parts, templatePart{expr: e})
+			i += end
+		default:
+			text.WriteByte(src[i])
+		}
+	}
+	if text.Len() > 0 {
+		t.parts = append(t.parts, templatePart{text: text.String()})
+	}
+	return t, nil
+}
+
+// Render arma el texto; una expresión que falla se muestra como "?"
+func (t *Template) Render(env Env) string {
+	var out strings.Builder
+	for _, part := range t.parts {
+		if part.expr == nil {
+			out.WriteString(part.text)
+			continue
+		}
+		v, err := part.expr.Eval(env)
+		if err != nil {
+			v = nil
+		}
+		out.WriteString(Format(v))
+	}
+	return out.String()
+}
@@ -0,0 +1,56 @@
+// Package fsutil agrupa las primitivas de archivos compartidas por queue,
+// state y secrets: escritura atómica, locks advisory entre procesos y
+// archivos de log que rotan por tamaño
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// TmpSuffix marca archivos a medio escribir por WriteFileAtomic
+const TmpSuffix = ".tmp"
+
+// WriteFileAtomic escribe en un temporal del mismo directorio, hace fsync y
+// lo renombra al destino. El rename es atómico: el lector ve el archivo
+// completo o no lo ve
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+TmpSuffix)
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// fsync del directorio para que el rename sobreviva a un corte de luz
+	// (no soportado en Windows: se ignora el error)
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLocked indica que otro proceso tiene el lock
+var ErrLocked = errors.New("lock tomado por otro proceso")
+
+// Lock es un lock advisory exclusivo sobre un archivo (flock / LockFileEx)
+// El SO lo libera si el proceso muere, así que no quedan locks huérfanos
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// AcquireLock toma el lock de path, reintentando hasta timeout
+// timeout 0 = un solo intento. Retorna ErrLocked (envuelto) si no se pudo
+func AcquireLock(path string, timeout time.Duration) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = tryLock(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrLocked) || !time.Now().Before(deadline) {
+			holder := readHolder(file)
+			file.Close()
+			if errors.Is(err, ErrLocked) && holder != "" {
+				return nil, fmt.Errorf("%w (pid %s): %s", ErrLocked, holder, path)
+			}
+			return nil, fmt.Errorf("%w: %s", err, path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// PID del dueño, solo informativo para el mensaje de error de otros procesos
+	file.Truncate(0)
+	file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	file.Sync()
+
+	return &Lock{path: path, file: file}, nil
+}
+
+// Release libera el lock
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlock(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// readHolder lee el PID guardado por el dueño del lock
+func readHolder(file *os.File) string {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	return strings.TrimSpace(string(buf[:n]))
+}
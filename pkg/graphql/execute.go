@@ -0,0 +1,471 @@
+// Package graphql implementa el subconjunto de GraphQL que sirve la API del
+// dashboard: consultas (sin mutaciones ni suscripciones) con argumentos,
+// variables, alias, fragmentos y @include/@skip. No hay introspección: los
+// campos de cada objeto son los del JSON de la API REST, validados contra el
+// schema que pkg/schema deriva de los tipos de Go. Las consultas que se pasan
+// de los límites de profundidad, campos o listas se rechazan sin resolver
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+)
+
+// Field es un campo raíz: sus argumentos, el tipo de lo que retorna y cómo se resuelve
+type Field struct {
+	Args    []string       // argumentos admitidos (los demás se rechazan)
+	Type    *schema.Schema // forma del resultado (ej: schema.Generate([]telemetry.Telemetry{}))
+	Resolve func(args map[string]interface{}) (interface{}, error)
+}
+
+// Schema son los campos raíz de Query y los límites de una consulta, que se
+// verifican antes de resolver nada
+type Schema struct {
+	Query map[string]Field
+
+	MaxDepth  int // niveles de selección anidados (0 = 10)
+	MaxFields int // campos seleccionados, con los fragmentos expandidos (0 = 500)
+	MaxLists  int // campos de tipo lista seleccionados: cada uno se repite por elemento (0 = 25)
+}
+
+// Límites por defecto de Schema
+const (
+	defaultMaxDepth  = 10
+	defaultMaxFields = 500
+	defaultMaxLists  = 25
+)
+
+// Request es el cuerpo de un POST /graphql (o los parámetros de un GET)
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response es la respuesta GraphQL: los datos resueltos y los errores
+// Una consulta inválida no tiene data; un campo raíz que falla queda en null
+type Response struct {
+	Data   *object `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error es un error de la respuesta; Path es el campo raíz que lo produjo
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// object es un objeto de la respuesta con sus campos en el orden de la consulta
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]interface{})}
+}
+
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON respeta el orden de los campos pedidos
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Execute parsea, valida y resuelve la consulta
+func (s *Schema) Execute(req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return failed(err)
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return failed(err)
+	}
+	if err := s.checkSize(doc, op.selections); err != nil {
+		return failed(err)
+	}
+	ex := &executor{doc: doc, maxLists: orDefault(s.MaxLists, defaultMaxLists)}
+	if ex.variables, err = op.coerceVariables(req.Variables); err != nil {
+		return failed(err)
+	}
+
+	fields, err := ex.collectFields(op.selections, map[string]bool{})
+	if err != nil {
+		return failed(err)
+	}
+
+	// Validación completa antes de resolver: una consulta con errores no toca los datos
+	for _, f := range fields {
+		root, ok := s.Query[f.name]
+		if !ok {
+			return failed(fmt.Errorf("unknown field %q on Query", f.name))
+		}
+		if err := checkArgs(f, root.Args); err != nil {
+			return failed(err)
+		}
+		if err := ex.validate(f.selections, root.Type, f.responseKey()); err != nil {
+			return failed(err)
+		}
+	}
+
+	resp := &Response{Data: newObject()}
+	for _, f := range fields {
+		key := f.responseKey()
+		value, err := ex.resolve(f, s.Query[f.name])
+		if err != nil {
+			resp.Data.set(key, nil)
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: []interface{}{key}})
+			continue
+		}
+		resp.Data.set(key, value)
+	}
+	return resp
+}
+
+func failed(err error) *Response {
+	return &Response{Errors: []Error{{Message: err.Error()}}}
+}
+
+// checkSize recorre las selecciones con los fragmentos expandidos y corta en
+// cuanto se pasa de MaxDepth o MaxFields: fragmentos que se expanden en cadena
+// no llegan a armarse enteros. Cuenta también lo que excluyan @include/@skip
+func (s *Schema) checkSize(doc *document, selections []selection) error {
+	maxDepth := orDefault(s.MaxDepth, defaultMaxDepth)
+	maxFields := orDefault(s.MaxFields, defaultMaxFields)
+
+	fields := 0
+	visiting := make(map[string]bool)
+	var walk func(sels []selection, depth int) error
+	walk = func(sels []selection, depth int) error {
+		if depth > maxDepth {
+			return fmt.Errorf("query is nested deeper than %d levels", maxDepth)
+		}
+		for _, sel := range sels {
+			switch {
+			case sel.field != nil:
+				if fields++; fields > maxFields {
+					return fmt.Errorf("query selects more than %d fields", maxFields)
+				}
+				if len(sel.field.selections) > 0 {
+					if err := walk(sel.field.selections, depth+1); err != nil {
+						return err
+					}
+				}
+			case sel.spread != "":
+				// Fragmentos desconocidos o recursivos los rechaza collectFields
+				frag, ok := doc.fragments[sel.spread]
+				if !ok || visiting[frag.name] {
+					continue
+				}
+				visiting[frag.name] = true
+				err := walk(frag.selections, depth)
+				delete(visiting, frag.name)
+				if err != nil {
+					return err
+				}
+			default:
+				if err := walk(sel.inline, depth); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(selections, 1)
+}
+
+// orDefault es n, o def si n no es positivo
+func orDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// operation elige la operación a ejecutar (por nombre si hay varias)
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// coerceVariables aplica los defaults y exige las variables obligatorias
+func (op *operation) coerceVariables(given map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		value, ok := given[def.name]
+		switch {
+		case ok && value != nil:
+			vars[def.name] = value
+		case def.defaultValue != nil:
+			vars[def.name] = def.defaultValue
+		case def.required:
+			return nil, fmt.Errorf("variable $%s is required", def.name)
+		}
+	}
+	return vars, nil
+}
+
+// executor resuelve una operación con sus variables
+type executor struct {
+	doc       *document
+	variables map[string]interface{}
+	lists     int // campos de tipo lista validados (ver Schema.MaxLists)
+	maxLists  int
+}
+
+// collectFields aplana fragmentos y directivas en la lista de campos a resolver
+// Dos selecciones con la misma clave se fusionan (GraphQL lo permite si piden lo mismo)
+func (ex *executor) collectFields(selections []selection, visiting map[string]bool) ([]*field, error) {
+	var fields []*field
+	byKey := make(map[string]*field)
+	var add func(sels []selection) error
+	add = func(sels []selection) error {
+		for _, sel := range sels {
+			include, err := ex.included(sel.directives)
+			if err != nil {
+				return err
+			}
+			if !include {
+				continue
+			}
+			switch {
+			case sel.field != nil:
+				key := sel.field.responseKey()
+				if prev, ok := byKey[key]; ok {
+					if prev.name != sel.field.name {
+						return fmt.Errorf("fields %q and %q conflict on key %q", prev.name, sel.field.name, key)
+					}
+					merged := *prev
+					merged.selections = append(append([]selection(nil), prev.selections...), sel.field.selections...)
+					*prev = merged
+					continue
+				}
+				f := *sel.field
+				byKey[key] = &f
+				fields = append(fields, &f)
+			case sel.spread != "":
+				frag, ok := ex.doc.fragments[sel.spread]
+				if !ok {
+					return fmt.Errorf("unknown fragment %q", sel.spread)
+				}
+				if visiting[frag.name] {
+					return fmt.Errorf("fragment %q spreads itself", frag.name)
+				}
+				visiting[frag.name] = true
+				err := add(frag.selections)
+				delete(visiting, frag.name)
+				if err != nil {
+					return err
+				}
+			default:
+				if err := add(sel.inline); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return fields, add(selections)
+}
+
+// included evalúa @include(if:) y @skip(if:)
+func (ex *executor) included(dirs []directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "include" && d.name != "skip" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		cond, ok := ex.value(d.arguments["if"]).(bool)
+		if !ok {
+			return false, fmt.Errorf("@%s requires a boolean if argument", d.name)
+		}
+		if (d.name == "include") != cond {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// value reemplaza las variables de un literal por sus valores
+func (ex *executor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case variable:
+		return ex.variables[string(v)]
+	case enumValue:
+		return string(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = ex.value(item)
+		}
+		return list
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			obj[k] = ex.value(item)
+		}
+		return obj
+	}
+	return v
+}
+
+// checkArgs rechaza los argumentos que el campo no admite
+func checkArgs(f *field, allowed []string) error {
+	for name := range f.arguments {
+		known := false
+		for _, a := range allowed {
+			known = known || a == name
+		}
+		if !known {
+			return fmt.Errorf("unknown argument %q on field %q", name, f.name)
+		}
+	}
+	return nil
+}
+
+// validate comprueba las selecciones contra el tipo: los objetos exigen
+// elegir campos existentes y los escalares no admiten selección
+func (ex *executor) validate(selections []selection, t *schema.Schema, path string) error {
+	if t.Items != nil {
+		if ex.lists++; ex.lists > ex.maxLists {
+			return fmt.Errorf("query selects more than %d list fields (at %q)", ex.maxLists, path)
+		}
+	}
+	for t.Items != nil {
+		t = t.Items
+	}
+	if t.Properties == nil {
+		if len(selections) > 0 {
+			return fmt.Errorf("field %q is a scalar and cannot have a selection", path)
+		}
+		return nil
+	}
+	if len(selections) == 0 {
+		return fmt.Errorf("field %q is an object: select its fields (%s)", path, strings.Join(propertyNames(t), ", "))
+	}
+
+	fields, err := ex.collectFields(selections, map[string]bool{})
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if len(f.arguments) > 0 {
+			return fmt.Errorf("field %q does not take arguments", path+"."+f.name)
+		}
+		prop, ok := t.Properties[f.name]
+		if !ok {
+			return fmt.Errorf("unknown field %q on %q", f.name, path)
+		}
+		if err := ex.validate(f.selections, prop, path+"."+f.responseKey()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func propertyNames(t *schema.Schema) []string {
+	names := make([]string, 0, len(t.Properties))
+	for name := range t.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve ejecuta el resolver del campo raíz y proyecta su resultado JSON
+// sobre las selecciones
+func (ex *executor) resolve(f *field, root Field) (interface{}, error) {
+	args := make(map[string]interface{}, len(f.arguments))
+	for name, v := range f.arguments {
+		args[name] = ex.value(v)
+	}
+	result, err := root.Resolve(args)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return ex.project(value, f.selections)
+}
+
+// project deja de value solo los campos seleccionados (con sus alias)
+func (ex *executor) project(value interface{}, selections []selection) (interface{}, error) {
+	if len(selections) == 0 {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			projected, err := ex.project(item, selections)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = projected
+		}
+		return list, nil
+	case map[string]interface{}:
+		fields, err := ex.collectFields(selections, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		obj := newObject()
+		for _, f := range fields {
+			projected, err := ex.project(v[f.name], f.selections)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(f.responseKey(), projected)
+		}
+		return obj, nil
+	}
+	return value, nil // null (u omitido en el JSON)
+}
@@ -0,0 +1,93 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+)
+
+type testItem struct {
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Child struct {
+		Name  string `json:"name"`
+		Child struct {
+			Name string `json:"name"`
+		} `json:"child"`
+	} `json:"child"`
+}
+
+// testSchema retorna un schema con items y cuántas veces se resolvió
+func testSchema() (*Schema, *int) {
+	resolved := 0
+	s := &Schema{Query: map[string]Field{
+		"items": {
+			Type: schema.Generate([]testItem{}),
+			Resolve: func(map[string]interface{}) (interface{}, error) {
+				resolved++
+				item := testItem{Name: "a", Tags: []string{"x"}}
+				item.Child.Name = "b"
+				item.Child.Child.Name = "c"
+				return []testItem{item}, nil
+			},
+		},
+	}}
+	return s, &resolved
+}
+
+func TestExecuteWithinLimits(t *testing.T) {
+	s, resolved := testSchema()
+	s.MaxDepth = 4
+	resp := s.Execute(Request{Query: "{ items { name tags child { child { name } } } }"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errores: %+v", resp.Errors)
+	}
+	if *resolved != 1 {
+		t.Errorf("items se resolvió %d veces", *resolved)
+	}
+}
+
+func TestExecuteRejectsOverLimit(t *testing.T) {
+	// Cadena de fragmentos que se expanden al doble en cada nivel: 2^30 campos
+	var bomb strings.Builder
+	bomb.WriteString("{ items { ...F0 } }\n")
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&bomb, "fragment F%d on Item { name ...F%d ...F%d }\n", i, i+1, i+1)
+	}
+	bomb.WriteString("fragment F30 on Item { name }\n")
+
+	var aliases strings.Builder
+	aliases.WriteString("{")
+	for i := 0; i < defaultMaxLists+1; i++ {
+		fmt.Fprintf(&aliases, " a%d: items { name }", i)
+	}
+	aliases.WriteString(" }")
+
+	for _, tc := range []struct {
+		name   string
+		query  string
+		limits func(s *Schema)
+		want   string
+	}{
+		{"profundidad", "{ items { child { child { name } } } }", func(s *Schema) { s.MaxDepth = 3 }, "nested deeper than 3"},
+		{"profundidad en fragmento", "{ items { ...C } } fragment C on Item { child { child { name } } }", func(s *Schema) { s.MaxDepth = 3 }, "nested deeper than 3"},
+		{"campos", "{ items { name tags child { name } } }", func(s *Schema) { s.MaxFields = 4 }, "more than 4 fields"},
+		{"fragmentos en cadena", bomb.String(), func(*Schema) {}, "more than 500 fields"},
+		{"listas por alias", aliases.String(), func(*Schema) {}, "more than 25 list fields"},
+		{"listas anidadas", "{ items { tags } }", func(s *Schema) { s.MaxLists = 1 }, "more than 1 list fields"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, resolved := testSchema()
+			tc.limits(s)
+			resp := s.Execute(Request{Query: tc.query})
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tc.want) {
+				t.Fatalf("errores %+v, se esperaba %q", resp.Errors, tc.want)
+			}
+			if resp.Data != nil || *resolved > 0 {
+				t.Error("la consulta se resolvió a pesar de pasarse del límite")
+			}
+		})
+	}
+}
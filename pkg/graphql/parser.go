@@ -0,0 +1,596 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document es una consulta parseada: sus operaciones y fragmentos con nombre
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation es una operación query (las mutaciones y suscripciones se rechazan al parsear)
+type operation struct {
+	name       string
+	variables  []variableDefinition
+	selections []selection
+}
+
+// variableDefinition es un $variable declarado por la operación
+type variableDefinition struct {
+	name         string
+	required     bool        // tipo con "!"
+	defaultValue interface{} // literal (nil = sin default)
+}
+
+// fragment es un fragmento con nombre; su condición de tipo no se evalúa
+type fragment struct {
+	name       string
+	selections []selection
+}
+
+// selection es un campo, un ...Fragmento o un fragmento inline
+type selection struct {
+	field      *field
+	spread     string      // nombre del fragmento de un ...Fragmento
+	inline     []selection // selecciones de un fragmento inline
+	directives []directive
+}
+
+// field es un campo seleccionado
+type field struct {
+	alias      string
+	name       string
+	arguments  map[string]interface{} // literales; las variables quedan como variable
+	selections []selection
+}
+
+// responseKey es la clave del campo en la respuesta (el alias si lo tiene)
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// directive es un @include(if:) o @skip(if:)
+type directive struct {
+	name      string
+	arguments map[string]interface{}
+}
+
+// variable es una referencia $nombre dentro de un valor
+type variable string
+
+// enumValue es un valor enum sin comillas (ej: sort: PAGE_COUNT); se trata como string
+type enumValue string
+
+// tokenKind clasifica los tokens del lexer
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// parser es un parser descendente recursivo sobre el texto de la consulta
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+// parse parsea una consulta completa
+func parse(src string) (*document, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.isPunct("{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{selections: selections})
+		case p.isName("query"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.isName("fragment"):
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.fragments[frag.name]; dup {
+				return nil, fmt.Errorf("fragment %q defined more than once", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		case p.isName("mutation"), p.isName("subscription"):
+			return nil, fmt.Errorf("only query operations are supported, got %s", p.tok.value)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("document has no operation")
+	}
+	return doc, nil
+}
+
+// parseOperation: query Nombre? ($var: Tipo = default, ...)? directivas? { ... }
+func (p *parser) parseOperation() (*operation, error) {
+	if err := p.next(); err != nil { // "query"
+		return nil, err
+	}
+	op := &operation{}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isPunct("(") {
+		vars, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.variables = vars
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+// parseVariableDefinitions: ($a: String!, $b: [String] = ["x"])
+func (p *parser) parseVariableDefinitions() ([]variableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var vars []variableDefinition
+	for !p.isPunct(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		required, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		def := variableDefinition{name: name, required: required}
+		if p.isPunct("=") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if def.defaultValue, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		vars = append(vars, def)
+	}
+	return vars, p.next()
+}
+
+// parseType consume Tipo, [Tipo] o Tipo! y retorna si es obligatorio
+func (p *parser) parseType() (bool, error) {
+	if p.isPunct("[") {
+		if err := p.next(); err != nil {
+			return false, err
+		}
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return false, err
+	}
+	if p.isPunct("!") {
+		return true, p.next()
+	}
+	return false, nil
+}
+
+// parseFragment: fragment Nombre on Tipo { ... }
+func (p *parser) parseFragment() (*fragment, error) {
+	if err := p.next(); err != nil { // "fragment"
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isName("on") {
+		return nil, p.unexpected()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expectName(); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, selections: selections}, nil
+}
+
+// parseSelectionSet: { campo, ...Fragmento, ... on Tipo { } }
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.isPunct("}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set at %d", p.tok.pos)
+	}
+	return selections, p.next()
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	var sel selection
+	if p.isPunct("...") {
+		if err := p.next(); err != nil {
+			return sel, err
+		}
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			sel.spread = p.tok.value
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			dirs, err := p.parseDirectives()
+			sel.directives = dirs
+			return sel, err
+		}
+		if p.isName("on") {
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			if _, err := p.expectName(); err != nil {
+				return sel, err
+			}
+		}
+		dirs, err := p.parseDirectives()
+		if err != nil {
+			return sel, err
+		}
+		sel.directives = dirs
+		sel.inline, err = p.parseSelectionSet()
+		return sel, err
+	}
+
+	f := &field{}
+	name, err := p.expectName()
+	if err != nil {
+		return sel, err
+	}
+	if p.isPunct(":") {
+		if err := p.next(); err != nil {
+			return sel, err
+		}
+		f.alias = name
+		if name, err = p.expectName(); err != nil {
+			return sel, err
+		}
+	}
+	f.name = name
+	if p.isPunct("(") {
+		if f.arguments, err = p.parseArguments(); err != nil {
+			return sel, err
+		}
+	}
+	if sel.directives, err = p.parseDirectives(); err != nil {
+		return sel, err
+	}
+	if p.isPunct("{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return sel, err
+		}
+	}
+	sel.field = f
+	return sel, nil
+}
+
+// parseArguments: (nombre: valor, ...)
+func (p *parser) parseArguments() (map[string]interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make(map[string]interface{})
+	for !p.isPunct(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if _, dup := args[name]; dup {
+			return nil, fmt.Errorf("argument %q given more than once", name)
+		}
+		if args[name], err = p.parseValue(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.next()
+}
+
+// parseDirectives: @include(if: $x) @skip(if: true)
+func (p *parser) parseDirectives() ([]directive, error) {
+	var dirs []directive
+	for p.isPunct("@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		d := directive{name: name}
+		if p.isPunct("(") {
+			if d.arguments, err = p.parseArguments(); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+// parseValue parsea un valor; constant prohíbe variables (defaults de variables)
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch {
+	case p.isPunct("$") && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		return variable(name), err
+	case p.isPunct("["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.isPunct("]") {
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.next()
+	case p.isPunct("{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		for !p.isPunct("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.parseValue(constant); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.next()
+	case tok.kind == tokenInt:
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s at %d", tok.value, tok.pos)
+		}
+		return n, p.next()
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", tok.value, tok.pos)
+		}
+		return f, p.next()
+	case tok.kind == tokenString:
+		return tok.value, p.next()
+	case tok.kind == tokenName:
+		var v interface{}
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = enumValue(tok.value)
+		}
+		return v, p.next()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) isPunct(s string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == s
+}
+
+func (p *parser) isName(s string) bool {
+	return p.tok.kind == tokenName && p.tok.value == s
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.isPunct(punct) {
+		return fmt.Errorf("expected %q at %d, got %s", punct, p.tok.pos, p.describe())
+	}
+	return p.next()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", fmt.Errorf("expected a name at %d, got %s", p.tok.pos, p.describe())
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *parser) unexpected() error {
+	return fmt.Errorf("unexpected %s at %d", p.describe(), p.tok.pos)
+}
+
+func (p *parser) describe() string {
+	if p.tok.kind == tokenEOF {
+		return "end of query"
+	}
+	return strconv.Quote(p.tok.value)
+}
+
+// next avanza al próximo token; las comas, los espacios y los comentarios (#) se ignoran
+func (p *parser) next() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.IndexByte("{}()[]:!$=@", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	case c == '-' || c >= '0' && c <= '9':
+		return p.lexNumber()
+	case c == '"':
+		return p.lexString()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		return fmt.Errorf("unexpected character %q at %d", r, start)
+	}
+	return nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// lexNumber lee un Int o Float (-12, 3.5, 1e3)
+func (p *parser) lexNumber() error {
+	start := p.pos
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+	return nil
+}
+
+// lexString lee un string entre comillas con los escapes de JSON ("""bloques""" no se soportan)
+func (p *parser) lexString() error {
+	start := p.pos
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		return fmt.Errorf("block strings are not supported (at %d)", start)
+	}
+	p.pos++
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos += 2
+			continue
+		case '\n':
+			return fmt.Errorf("unterminated string at %d", start)
+		case '"':
+			p.pos++
+			value, err := strconv.Unquote(p.src[start:p.pos])
+			if err != nil {
+				return fmt.Errorf("invalid string at %d: %v", start, err)
+			}
+			p.tok = token{kind: tokenString, value: value, pos: start}
+			return nil
+		}
+		p.pos++
+	}
+	return fmt.Errorf("unterminated string at %d", start)
+}
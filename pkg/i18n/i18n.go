@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Locale identifica un idioma de salida
+type Locale string
+
+const (
+	Spanish Locale = "es"
+	English Locale = "en"
+)
+
+// DefaultLocale es el idioma histórico del agente
+const DefaultLocale = Spanish
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLocale
+)
+
+// SetLocale cambia el idioma global de logs, reportes y strings de estado
+// Acepta "es", "en", "es-CL", "en_US", etc. Idiomas desconocidos → español
+func SetLocale(locale string) Locale {
+	l := Parse(locale)
+
+	mu.Lock()
+	current = l
+	mu.Unlock()
+
+	return l
+}
+
+// Current retorna el idioma activo
+func Current() Locale {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Parse normaliza un código de idioma a un Locale soportado
+func Parse(locale string) Locale {
+	code := strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(code, "-_"); idx != -1 {
+		code = code[:idx]
+	}
+
+	if _, ok := catalogs[Locale(code)]; ok {
+		return Locale(code)
+	}
+	return DefaultLocale
+}
+
+// T traduce una clave al idioma activo y aplica fmt.Sprintf con args
+// Si la clave no existe en el idioma activo se usa español, y si tampoco
+// existe se retorna la clave (nunca un string vacío)
+func T(key string, args ...interface{}) string {
+	return TL(Current(), key, args...)
+}
+
+// TL traduce una clave a un idioma específico
+func TL(locale Locale, key string, args ...interface{}) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		msg = key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
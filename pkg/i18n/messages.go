@@ -0,0 +1,383 @@
+package i18n
+
+// catalogs contiene los textos por idioma
+// Las claves son estables: el backend/frontend no debe depender del texto traducido
+var catalogs = map[Locale]map[string]string{
+	Spanish: {
+		// Estados de consumibles (normalizedSupplies / frontend)
+		"supply.status.ok":             "OK",
+		"supply.status.good":           "Bueno",
+		"supply.status.low":            "Bajo",
+		"supply.status.critical":       "Crítico",
+		"supply.status.empty":          "Agotado",
+		"supply.status.unknown":        "Desconocido",
+		"supply.status.some_remaining": "Quedan unidades",
+
+		// Nombres de contadores (normalizedCounters / reportes)
+		"counter.totalPages":       "Páginas Totales",
+		"counter.colorPages":       "Páginas a Color",
+		"counter.monochromedPages": "Páginas Monocromáticas",
+		"counter.printedPages":     "Páginas Impresas",
+		"counter.copiedPages":      "Páginas Copiadas",
+		"counter.scannedPages":     "Páginas Escaneadas",
+		"counter.faxedPages":       "Páginas Faxeadas",
+
+		// Logs del agente
+		"log.config_unreadable":      "⚠️  No se pudo leer config.yaml: %v",
+		"log.range_required":         "Error: Se requiere ip_range en config.yaml o -range en flags",
+		"log.range_invalid":          "Error parseando rango: %v",
+		"log.discovery_error":        "Error durante el descubrimiento: %v",
+		"log.no_devices":             "No se encontraron dispositivos SNMP en el rango",
+		"log.discovery_disabled":     "Discovery deshabilitado en config.yaml",
+		"log.collecting":             "📊 Recolectando datos de impresoras...",
+		"log.collect_error":          "Error recolectando datos: %v",
+		"log.collected":              "✓ Datos recolectados de %d impresoras",
+		"log.states_migrated":        "🔁 Migrados %d estados de impresora de IP a ID canónico",
+		"log.file_sink_error":        "No se pudo inicializar el file sink: %v",
+		"log.state_migrate_error":    "⚠️  No se pudo migrar el estado de %s: %v",
+		"log.state_rekey_error":      "⚠️  No se pudo migrar el estado %s → %s: %v",
+		"log.state_save_error":       "⚠️  No se pudo guardar el estado de %s: %v",
+		"log.build_error":            "❌ No se pudo construir la telemetría de %s: %v",
+		"log.serialize_error":        "❌ No se pudo serializar la telemetría de %s: %v",
+		"log.buffer_error":           "❌ No se pudo encolar la telemetría de %s: %v",
+		"log.syslog_error":           "⚠️  Syslog (%s): %v",
+		"log.scan_id":                "🔖 Escaneo %s",
+		"log.scan_completed":         "✅ Escaneo completado en %.2f segundos. Dispositivos: %d, Telemetría encolada: %d",
+		"log.collector_disabled":     "❌ Collector deshabilitado en config.yaml",
+		"log.discovery_start":        "Iniciando descubrimiento de %d IPs...",
+		"log.discovery_done":         "Descubrimiento completado en %.2f segundos. Encontradas %d impresoras.",
+		"log.scan_progress":          "⏳ %s: %d/%d (%.0f%%), %d encontradas, ETA %v",
+		"log.collection_start":       "Iniciando recolección de %d dispositivos...",
+		"log.collection_done":        "Recolección completada en %.2f segundos.",
+		"log.device_deadline":        "⏱️  %s: deadline excedido tras %v, se emiten datos parciales",
+		"log.collection_aborted":     "⏹️  Recolección interrumpida: %d dispositivos quedan para el próximo ciclo",
+		"log.scan_aborted":           "⏹️  Ciclo interrumpido: se emitieron %d de %d dispositivos; el resumen queda marcado como abortado y la queue se sube en el próximo ciclo",
+		"log.discovery_aborted":      "⏹️  Discovery interrumpido: no hay datos recolectados para emitir",
+		"log.hook_error":             "⚠️  Hook %s falló en %s: %v",
+		"log.alert_rule_error":       "⚠️  Regla de alerta %s falló en %s: %v",
+		"log.clock_ntp_error":        "⚠️  No se pudo medir el reloj contra %s: %v (se usa la hora local)",
+		"log.clock_skew":             "⚠️  El reloj del servidor difiere %v de %s: se corrigen los timestamps",
+		"log.oid_denied":             "🚫 %s %s a %s bloqueado por la política de OIDs (%s)",
+		"log.concurrency_reduced":    "🐢 Red saturada: concurrencia SNMP %d → %d (RTT medio %v, base %v, timeouts %.1f%%)",
+		"log.audit_error":            "❌ Error escribiendo el log de auditoría SNMP: %v",
+		"log.breaker_open":           "🔌 %s: %d polls fallidos seguidos, solo sondeo de vida durante %d ciclos",
+		"log.breaker_probe":          "🔌 %s: circuit breaker abierto, solo sondeo de vida (quedan %d ciclos)",
+		"log.scan_budget_exceeded":   "⏱️  Presupuesto de escaneo (%v) agotado: %d dispositivos quedan para el próximo ciclo",
+		"log.slow_prioritized":       "⏫ Priorizando %d dispositivos lentos/pendientes del ciclo anterior",
+		"log.poll_not_due":           "⏭️  %d dispositivos todavía no vencen su intervalo de consulta: se saltean",
+		"log.heartbeat_sent":         "💓 Heartbeat del agente encolado (backlog: %d, errores: %d)",
+		"log.heartbeat_error":        "⚠️  No se pudo emitir el heartbeat del agente: %v",
+		"log.secrets_usage":          "Uso: printsnmp secrets [-vault archivo] set <nombre> [valor] | delete <nombre> | list",
+		"log.secrets_error":          "❌ Error en el vault de secretos: %v",
+		"log.secrets_resolve_error":  "❌ No se pudieron resolver los secretos de la configuración: %v",
+		"log.secrets_saved":          "🔐 Secreto %s guardado en %s (clave: %s)",
+		"log.secrets_ref_hint":       "   Usar en config.yaml como: \"%s\"",
+		"log.secrets_deleted":        "🗑️  Secreto %s eliminado",
+		"log.secrets_prompt":         "Valor: ",
+		"log.queue_usage":            "Uso: printsnmp queue list | deadletter | requeue [-all] <archivo...> | flush",
+		"log.queue_error":            "⚠️  Error en la queue: %v",
+		"log.queue_total":            "%d eventos",
+		"log.queue_requeued":         "↩️  %d eventos devueltos a la queue",
+		"log.queue_drained":          "📤 Queue: %d enviados, %d fallidos, %d a deadletter",
+		"log.queue_http_disabled":    "La queue no tiene destino en config.yaml (sinks.http.enabled, sinks.object.enabled, sinks.postgres.enabled o sinks.exec.enabled)",
+		"log.queue_evicted":          "🗑️  Cuota de la queue superada: %d eventos descartados (%d bytes), los más viejos primero",
+		"log.queue_quarantined":      "⚠️  %d eventos corruptos movidos a %s/corrupt/",
+		"log.state_locked":           "❌ Otra instancia del agente está usando state/, se omite este ciclo: %v",
+		"log.inventory_diff":         "📋 Inventario: %d nuevas, %d faltantes, %d de vuelta, %d cambiadas, %d con IP nueva",
+		"log.summary_error":          "⚠️  No se pudo escribir %s: %v",
+		"log.output_written":         "🗂️  Resultados del ciclo en %s",
+		"log.output_pruned":          "🧹 %d ciclos anteriores eliminados por retención",
+		"log.schema_usage":           "Uso: printsnmp schema [-out dir] <tipo...> (tipos: %s)",
+		"log.schema_error":           "❌ Error generando schema %s: %v",
+		"log.openapi_error":          "❌ Error generando el documento OpenAPI: %v",
+		"log.openapi_written":        "📐 Escrito: %s",
+		"log.schema_written":         "📐 Schema escrito: %s",
+		"log.schema_invalid":         "⚠️  Telemetría de %s no cumple el schema (%d diferencias): %v",
+		"log.validate_usage":         "Uso: printsnmp validate [-kind tipo] <archivo...>",
+		"log.validate_error":         "❌ %s: %v",
+		"log.validate_invalid":       "❌ %s: %d diferencias con el schema",
+		"log.validate_ok":            "✅ %s",
+		"log.coverage_error":         "⚠️  No se pudo escribir el reporte de cobertura de %s: %v",
+		"log.meters_usage":           "Uso: printsnmp meters [-range r] [-format json,csv,papercut,printfleet] [-out dir] | meters verify <archivo...>",
+		"log.meters_written":         "🧾 Lecturas de %d impresoras (cierre %s) en %s",
+		"log.meters_error":           "⚠️  Error exportando lecturas de contadores: %v",
+		"log.meters_unsigned":        "⚠️  meters.signing_key vacío: el export lleva solo SHA-256 (integridad, no autenticidad)",
+		"log.meters_verified":        "✅ %s: firma %s válida",
+		"log.meters_verify_failed":   "❌ %s: %v",
+		"log.profile_discovery":      "[DISCOVERY] Ejecutando discovery para %s (%s)...",
+		"log.profile_discovery_err":  "[DISCOVERY] Error: %v",
+		"log.profile_saved":          "[DISCOVERY] Perfil guardado para %s (%s)",
+		"log.profiles_load_error":    "⚠️  Error cargando perfiles: %v",
+		"log.profile_inconsistent":   "[DISCOVERY] %s: %d/%d OIDs de %s inconsistentes",
+		"log.profile_migrate_error":  "⚠️  No se pudo migrar el perfil %s → %s: %v",
+		"log.profile_remove_error":   "⚠️  No se pudo eliminar el perfil antiguo %s: %v",
+		"log.profile_index_corrupt":  "⚠️  Índice de perfiles corrupto, se reconstruirá: %v",
+		"log.profile_migrated":       "[PROFILE] Perfil migrado %s → %s",
+		"log.profile_usage":          "Uso: printsnmp profile export [-out archivo] [-contributor nombre] <ip|printer_id> | profile import <plantilla.json...> | profile templates | profile push <plantilla.json...> | profile pull [clave...]",
+		"log.template_exported":      "✅ Plantilla %s %s exportada a %s (sin IP, serial ni valores leídos)",
+		"log.template_imported":      "✅ Plantilla %s %s importada en %s",
+		"log.template_error":         "❌ Error de plantilla: %v",
+		"log.template_total":         "%d plantillas",
+		"log.template_pushed":        "✅ Plantilla %s %s publicada en el profile store (%s)",
+		"log.template_not_found":     "❓ %s: el profile store no tiene esa plantilla",
+		"log.profile_store_error":    "⚠️  Error del profile store: %v",
+		"log.profile_store_disabled": "❌ profile_store.type no está configurado en config.yaml",
+		"log.profile_store_warning":  "⚠️  Profile store: %s: %v",
+		"log.template_save_error":    "⚠️  No se pudo guardar la plantilla %s: %v",
+		"log.template_downloaded":    "[PROFILE] Plantilla %s descargada del profile store",
+		"log.template_published":     "[PROFILE] Plantilla %s publicada en el profile store",
+		"log.template_applied":       "[PROFILE] %s: perfil desde plantilla %s",
+		"log.template_ignored":       "⚠️  Plantilla de %s %s ignorada: %v",
+		"log.template_load_error":    "⚠️  Plantilla ilegible: %v",
+		"log.brand_corrected":        "[DISCOVERY] %s: el sysDescr indica %s pero los OIDs del fabricante son de %s, se corrige la marca",
+		"log.record_usage":           "Uso: printsnmp record [-community c] [-port p] [-out archivo.json] <ip>",
+		"log.record_start":           "📼 Grabando walk completo de %s...",
+		"log.record_error":           "❌ Error grabando %s: %v",
+		"log.record_saved":           "✅ Fixture con %d OIDs guardado en %s",
+		"log.replay_error":           "❌ Error en replay: %v",
+		"log.web_listening":          "🌐 Dashboard en http://%s",
+		"log.web_error":              "❌ Error en el servidor web: %v",
+		"log.serve_next_cycle":       "⏱️  Próximo ciclo a las %s",
+		"log.tenant_cycle":           "🏢 Tenant %s: %s",
+		"log.dryrun_plan_discovery":  "discovery por WALK y recolección completa",
+		"log.dryrun_plan_profile":    "recolección completa con el perfil",
+		"log.dryrun_plan_probe":      "solo sondeo de vida (circuit breaker, %d ciclos)",
+		"log.dryrun_summary":         "🧪 Dry-run: %d dispositivos (%d con perfil, %d sin perfil, %d en sondeo de vida); no se recolectó ni se escribió nada",
+		"log.serve_stopped":          "👋 Daemon detenido",
+		"log.serve_reload_error":     "⚠️  config.yaml inválido, se mantiene la configuración anterior: %v",
+		"log.web_open":               "⚠️  Dashboard en %s sin autenticación: cualquiera en la red ve la flota (configurar web.api_keys o web.oidc)",
+		"log.web_forbidden":          "🔒 %s sin permisos para %s %s",
+		"log.web_rate_limited":       "⏳ %s superó el límite de pedidos (%s %s)",
+		"log.web_scan_triggered":     "▶️  Escaneo solicitado por %s",
+		"log.web_config_updated":     "📝 config.yaml actualizado por %s (se aplica en el próximo ciclo)",
+		"log.web_tags_updated":       "🏷️  Tags de %s actualizados por %s (se aplican en el próximo ciclo)",
+		"log.web_printer_collected":  "🔄 %s consultada a pedido de %s",
+		"log.replay_agent":           "🔁 Simulador %s escuchando en %s:%d",
+		"log.problem_saved":          "🗂️  Lectura de %s guardada en state/problems/ (falló en %s); reprocesar con `printsnmp problems reprocess`",
+		"log.problem_save_error":     "❌ No se pudo guardar la lectura fallida de %s: %v",
+		"log.problem_reprocessed":    "✅ %s reprocesada (%s)",
+		"log.problems_usage":         "Uso: printsnmp problems list | reprocess [-all] <archivo...>",
+		"log.problems_error":         "❌ problems: %v",
+		"log.problems_total":         "%d lecturas en problems/",
+		"log.problems_reprocessed":   "🗂️  %d lecturas reprocesadas a la queue, %d siguen fallando",
+		"log.hp_fallback":            "🔌 %s: contadores/consumibles completados por %s (SNMP restringido)",
+		"log.ipp_fallback":           "🖨️ %s: %s completados por IPP",
+		"log.discovery_no_snmp":      "🔍 %d equipo(s) con puertos de impresión abiertos no responden SNMP (se informan sin telemetría)",
+		"log.site_schedule_skipped":  "⏸️ %d equipo(s) fuera de la agenda o en blackout de su site (se consultan más tarde)",
+		"log.site_cycle":             "🗓️ Ciclo por agenda de site: %d IP(s) de sus subredes",
+		"log.blackout_skip":          "⏸️ Ciclo forzado descartado: blackout %s en curso",
+		"log.compaction_done":        "🧹 %s compactado: %d historial(es), %d lectura(s) agregadas por día, %d lectura(s) sin páginas descartadas, %d día(s) y %d estado(s) eliminados",
+		"log.compaction_error":       "⚠️ Error compactando %s: %v",
+		"log.compaction_disabled":    "❌ state.retention no tiene nada configurado (history_days, daily_days o stale_days)",
+		"log.printer_decommissioned": "📦 %s (%s) dada de baja: sin responder desde %s; archivada en %s",
+		"log.printer_ip_changed":     "🔀 %s cambió de IP: %s → %s (perfil y estado la siguen)",
+		"log.profile_relocate_error": "⚠️  No se pudo actualizar la IP del perfil %s: %v",
+		"log.duplicate_merged":       "🔗 %s es la misma impresora que %s (%s): fusionada",
+		"log.duplicate_skipped":      "🔗 %d IP(s) secundaria(s) de impresoras multi-homed no se consultan en este ciclo",
+		"log.models_error":           "⚠️  Base de modelos propia inválida, se usa solo la incorporada: %v",
+		"log.fast_probe_done":        "⚡ Sondeo rápido: %d/%d IPs responden sysUpTime (p50 %v, p95 %v); timeout del probe completo: %v",
+		"log.remote_fetched":         "☁️  Configuración remota %s recibida",
+		"log.remote_applied":         "✅ Configuración remota %s aplicada",
+		"log.remote_rejected":        "⚠️  Configuración remota %s rechazada, se mantiene la anterior: %v",
+		"log.remote_error":           "⚠️  No se pudo obtener la configuración remota: %v",
+		"log.remote_cached":          "📦 Usando configuración remota en caché %s",
+		"log.mib_load_error":         "⚠️  Error cargando MIBs de %s: %v",
+		"log.mib_builtin_error":      "⚠️  MIB embebido %s: %v",
+		"log.mib_unresolved":         "⚠️  %d símbolos sin resolver (falta el MIB que los define): %s",
+		"log.mib_usage":              "Uso: printsnmp mib compile [-out archivo.json] <mib|dir...> | mib resolve <oid|nombre...>",
+		"log.mib_compiled":           "✅ %d nodos compilados en %s",
+		"log.mib_not_found":          "❓ %s: no está en los MIBs cargados",
+		"log.command_received":       "📨 Comando remoto %s (%s)",
+		"log.command_done":           "✅ Comando %s: %s",
+		"log.command_error":          "⚠️  Comando %s falló: %v",
+		"log.command_fetch_error":    "⚠️  No se pudieron obtener los comandos remotos: %v",
+		"log.walk_fallback":          "↪️  WALK de %s bloqueado, %d OIDs obtenidos por GET directo",
+	},
+	English: {
+		"supply.status.ok":             "OK",
+		"supply.status.good":           "Good",
+		"supply.status.low":            "Low",
+		"supply.status.critical":       "Critical",
+		"supply.status.empty":          "Empty",
+		"supply.status.unknown":        "Unknown",
+		"supply.status.some_remaining": "Some remaining",
+
+		"counter.totalPages":       "Total Pages",
+		"counter.colorPages":       "Color Pages",
+		"counter.monochromedPages": "Monochrome Pages",
+		"counter.printedPages":     "Printed Pages",
+		"counter.copiedPages":      "Copied Pages",
+		"counter.scannedPages":     "Scanned Pages",
+		"counter.faxedPages":       "Faxed Pages",
+
+		"log.config_unreadable":      "⚠️  Could not read config.yaml: %v",
+		"log.range_required":         "Error: ip_range is required in config.yaml or -range flag",
+		"log.range_invalid":          "Error parsing range: %v",
+		"log.discovery_error":        "Error during discovery: %v",
+		"log.no_devices":             "No SNMP devices found in range",
+		"log.discovery_disabled":     "Discovery disabled in config.yaml",
+		"log.collecting":             "📊 Collecting printer data...",
+		"log.collect_error":          "Error collecting data: %v",
+		"log.collected":              "✓ Collected data from %d printers",
+		"log.states_migrated":        "🔁 Migrated %d printer states from IP to printer ID",
+		"log.file_sink_error":        "Failed to initialize file sink: %v",
+		"log.state_migrate_error":    "⚠️  Failed to migrate state for %s: %v",
+		"log.state_rekey_error":      "⚠️  Could not migrate state %s → %s: %v",
+		"log.state_save_error":       "⚠️  Failed to save state for %s: %v",
+		"log.build_error":            "❌ Failed to build telemetry for %s: %v",
+		"log.serialize_error":        "❌ Failed to serialize telemetry for %s: %v",
+		"log.buffer_error":           "❌ Failed to buffer telemetry for %s: %v",
+		"log.syslog_error":           "⚠️  Syslog (%s): %v",
+		"log.scan_id":                "🔖 Scan %s",
+		"log.scan_completed":         "✅ Scan completed in %.2f seconds. Devices: %d, Telemetry queued: %d",
+		"log.collector_disabled":     "❌ Collector disabled in config.yaml",
+		"log.discovery_start":        "Starting discovery of %d IPs...",
+		"log.discovery_done":         "Discovery completed in %.2f seconds. Found %d printers.",
+		"log.scan_progress":          "⏳ %s: %d/%d (%.0f%%), %d found, ETA %v",
+		"log.collection_start":       "Starting collection from %d devices...",
+		"log.collection_done":        "Collection completed in %.2f seconds.",
+		"log.device_deadline":        "⏱️  %s: deadline exceeded after %v, emitting partial data",
+		"log.collection_aborted":     "⏹️  Collection interrupted: %d devices left for the next cycle",
+		"log.scan_aborted":           "⏹️  Cycle interrupted: emitted %d of %d devices; the summary is flagged as aborted and the queue is uploaded on the next cycle",
+		"log.discovery_aborted":      "⏹️  Discovery interrupted: no collected data to emit",
+		"log.hook_error":             "⚠️  Hook %s failed on %s: %v",
+		"log.alert_rule_error":       "⚠️  Alert rule %s failed on %s: %v",
+		"log.clock_ntp_error":        "⚠️  Could not check the clock against %s: %v (using local time)",
+		"log.clock_skew":             "⚠️  The server clock is off by %v from %s: correcting timestamps",
+		"log.oid_denied":             "🚫 %s %s to %s blocked by the OID policy (%s)",
+		"log.concurrency_reduced":    "🐢 Network saturated: SNMP concurrency %d → %d (mean RTT %v, baseline %v, timeouts %.1f%%)",
+		"log.audit_error":            "❌ Error writing the SNMP audit log: %v",
+		"log.breaker_open":           "🔌 %s: %d consecutive failed polls, liveness probe only for %d cycles",
+		"log.breaker_probe":          "🔌 %s: circuit breaker open, liveness probe only (%d cycles left)",
+		"log.scan_budget_exceeded":   "⏱️  Scan budget (%v) exhausted: %d devices deferred to the next cycle",
+		"log.slow_prioritized":       "⏫ Prioritizing %d slow/pending devices from the previous cycle",
+		"log.poll_not_due":           "⏭️  %d devices are not yet due for polling: skipped",
+		"log.heartbeat_sent":         "💓 Agent heartbeat queued (backlog: %d, errors: %d)",
+		"log.heartbeat_error":        "⚠️  Failed to emit agent heartbeat: %v",
+		"log.secrets_usage":          "Usage: printsnmp secrets [-vault file] set <name> [value] | delete <name> | list",
+		"log.secrets_error":          "❌ Secrets vault error: %v",
+		"log.secrets_resolve_error":  "❌ Failed to resolve configuration secrets: %v",
+		"log.secrets_saved":          "🔐 Secret %s saved to %s (key: %s)",
+		"log.secrets_ref_hint":       "   Use in config.yaml as: \"%s\"",
+		"log.secrets_deleted":        "🗑️  Secret %s deleted",
+		"log.secrets_prompt":         "Value: ",
+		"log.queue_usage":            "Usage: printsnmp queue list | deadletter | requeue [-all] <file...> | flush",
+		"log.queue_error":            "⚠️  Queue error: %v",
+		"log.queue_total":            "%d events",
+		"log.queue_requeued":         "↩️  %d events returned to the queue",
+		"log.queue_drained":          "📤 Queue: %d sent, %d failed, %d dead-lettered",
+		"log.queue_http_disabled":    "The queue has no destination in config.yaml (sinks.http.enabled, sinks.object.enabled, sinks.postgres.enabled or sinks.exec.enabled)",
+		"log.queue_evicted":          "🗑️  Queue quota exceeded: %d events dropped (%d bytes), oldest first",
+		"log.queue_quarantined":      "⚠️  %d corrupt events moved to %s/corrupt/",
+		"log.state_locked":           "❌ Another agent instance is using state/, skipping this cycle: %v",
+		"log.inventory_diff":         "📋 Inventory: %d new, %d missing, %d returned, %d changed, %d with a new IP",
+		"log.summary_error":          "⚠️  Failed to write %s: %v",
+		"log.output_written":         "🗂️  Cycle results in %s",
+		"log.output_pruned":          "🧹 %d previous cycles removed by retention",
+		"log.schema_usage":           "Usage: printsnmp schema [-out dir] <kind...> (kinds: %s)",
+		"log.schema_error":           "❌ Error generating schema %s: %v",
+		"log.openapi_error":          "❌ Error generating the OpenAPI document: %v",
+		"log.openapi_written":        "📐 Written: %s",
+		"log.schema_written":         "📐 Schema written: %s",
+		"log.schema_invalid":         "⚠️  Telemetry for %s does not match the schema (%d differences): %v",
+		"log.validate_usage":         "Usage: printsnmp validate [-kind kind] <file...>",
+		"log.validate_error":         "❌ %s: %v",
+		"log.validate_invalid":       "❌ %s: %d differences with the schema",
+		"log.validate_ok":            "✅ %s",
+		"log.coverage_error":         "⚠️  Failed to write the coverage report for %s: %v",
+		"log.meters_usage":           "Usage: printsnmp meters [-range r] [-format json,csv,papercut,printfleet] [-out dir] | meters verify <file...>",
+		"log.meters_written":         "🧾 Meter reads for %d printers (close %s) in %s",
+		"log.meters_error":           "⚠️  Error exporting meter reads: %v",
+		"log.meters_unsigned":        "⚠️  meters.signing_key is empty: the export only carries SHA-256 (integrity, not authenticity)",
+		"log.meters_verified":        "✅ %s: valid %s signature",
+		"log.meters_verify_failed":   "❌ %s: %v",
+		"log.profile_discovery":      "[DISCOVERY] Running discovery for %s (%s)...",
+		"log.profile_discovery_err":  "[DISCOVERY] Error: %v",
+		"log.profile_saved":          "[DISCOVERY] Profile saved for %s (%s)",
+		"log.profiles_load_error":    "⚠️  Error loading profiles: %v",
+		"log.profile_inconsistent":   "[DISCOVERY] %s: %d/%d %s OIDs are inconsistent",
+		"log.profile_migrate_error":  "⚠️  Could not migrate profile %s → %s: %v",
+		"log.profile_remove_error":   "⚠️  Could not remove old profile %s: %v",
+		"log.profile_index_corrupt":  "⚠️  Corrupt profile index, it will be rebuilt: %v",
+		"log.profile_migrated":       "[PROFILE] Profile migrated %s → %s",
+		"log.profile_usage":          "Usage: printsnmp profile export [-out file] [-contributor name] <ip|printer_id> | profile import <template.json...> | profile templates | profile push <template.json...> | profile pull [key...]",
+		"log.template_exported":      "✅ Template %s %s exported to %s (no IP, serial or read values)",
+		"log.template_imported":      "✅ Template %s %s imported into %s",
+		"log.template_error":         "❌ Template error: %v",
+		"log.template_total":         "%d templates",
+		"log.template_pushed":        "✅ Template %s %s published to the profile store (%s)",
+		"log.template_not_found":     "❓ %s: the profile store has no such template",
+		"log.profile_store_error":    "⚠️  Profile store error: %v",
+		"log.profile_store_disabled": "❌ profile_store.type is not configured in config.yaml",
+		"log.profile_store_warning":  "⚠️  Profile store: %s: %v",
+		"log.template_save_error":    "⚠️  Could not save template %s: %v",
+		"log.template_downloaded":    "[PROFILE] Template %s downloaded from the profile store",
+		"log.template_published":     "[PROFILE] Template %s published to the profile store",
+		"log.template_applied":       "[PROFILE] %s: profile from template %s",
+		"log.template_ignored":       "⚠️  Template for %s %s ignored: %v",
+		"log.template_load_error":    "⚠️  Unreadable template: %v",
+		"log.brand_corrected":        "[DISCOVERY] %s: sysDescr says %s but the vendor OIDs belong to %s, correcting brand",
+		"log.record_usage":           "Usage: printsnmp record [-community c] [-port p] [-out file.json] <ip>",
+		"log.record_start":           "📼 Recording full walk of %s...",
+		"log.record_error":           "❌ Failed to record %s: %v",
+		"log.record_saved":           "✅ Fixture with %d OIDs saved to %s",
+		"log.replay_error":           "❌ Replay error: %v",
+		"log.web_listening":          "🌐 Dashboard at http://%s",
+		"log.web_error":              "❌ Web server error: %v",
+		"log.serve_next_cycle":       "⏱️  Next cycle at %s",
+		"log.tenant_cycle":           "🏢 Tenant %s: %s",
+		"log.dryrun_plan_discovery":  "WALK discovery and full collection",
+		"log.dryrun_plan_profile":    "full collection with profile",
+		"log.dryrun_plan_probe":      "liveness probe only (circuit breaker, %d cycles)",
+		"log.dryrun_summary":         "🧪 Dry-run: %d devices (%d with profile, %d without profile, %d on liveness probe); nothing was collected or written",
+		"log.serve_stopped":          "👋 Daemon stopped",
+		"log.serve_reload_error":     "⚠️  Invalid config.yaml, keeping the previous configuration: %v",
+		"log.web_open":               "⚠️  Dashboard at %s without authentication: anyone on the network can see the fleet (configure web.api_keys or web.oidc)",
+		"log.web_forbidden":          "🔒 %s is not allowed to %s %s",
+		"log.web_rate_limited":       "⏳ %s exceeded the request rate limit (%s %s)",
+		"log.web_scan_triggered":     "▶️  Scan requested by %s",
+		"log.web_config_updated":     "📝 config.yaml updated by %s (applied on the next cycle)",
+		"log.web_tags_updated":       "🏷️  Tags for %s updated by %s (applied on the next cycle)",
+		"log.web_printer_collected":  "🔄 %s polled on demand by %s",
+		"log.replay_agent":           "🔁 Simulator %s listening on %s:%d",
+		"log.problem_saved":          "🗂️  Reading from %s saved to state/problems/ (failed at %s); reprocess with `printsnmp problems reprocess`",
+		"log.problem_save_error":     "❌ Failed to save the failed reading from %s: %v",
+		"log.problem_reprocessed":    "✅ %s reprocessed (%s)",
+		"log.problems_usage":         "Usage: printsnmp problems list | reprocess [-all] <file...>",
+		"log.problems_error":         "❌ problems: %v",
+		"log.problems_total":         "%d readings in problems/",
+		"log.problems_reprocessed":   "🗂️  %d readings reprocessed into the queue, %d still failing",
+		"log.hp_fallback":            "🔌 %s: counters/supplies filled in via %s (restricted SNMP)",
+		"log.ipp_fallback":           "🖨️ %s: %s filled in via IPP",
+		"log.discovery_no_snmp":      "🔍 %d host(s) with open printing ports do not answer SNMP (reported without telemetry)",
+		"log.site_schedule_skipped":  "⏸️ %d device(s) outside their site schedule or in its blackout (polled later)",
+		"log.site_cycle":             "🗓️ Site schedule cycle: %d IP(s) in its subnets",
+		"log.blackout_skip":          "⏸️ Forced cycle dropped: blackout %s in progress",
+		"log.compaction_done":        "🧹 %s compacted: %d history file(s), %d reading(s) rolled up by day, %d idle reading(s) dropped, %d day(s) and %d state(s) removed",
+		"log.compaction_error":       "⚠️ Error compacting %s: %v",
+		"log.compaction_disabled":    "❌ state.retention has nothing configured (history_days, daily_days or stale_days)",
+		"log.printer_decommissioned": "📦 %s (%s) decommissioned: no response since %s; archived in %s",
+		"log.printer_ip_changed":     "🔀 %s changed IP: %s → %s (profile and state follow it)",
+		"log.profile_relocate_error": "⚠️  Could not update the IP of profile %s: %v",
+		"log.duplicate_merged":       "🔗 %s is the same printer as %s (%s): merged",
+		"log.duplicate_skipped":      "🔗 %d secondary IP(s) of multi-homed printers skipped this cycle",
+		"log.models_error":           "⚠️  Invalid custom model database, using the built-in one only: %v",
+		"log.fast_probe_done":        "⚡ Fast probe: %d/%d IPs answer sysUpTime (p50 %v, p95 %v); full probe timeout: %v",
+		"log.remote_fetched":         "☁️  Remote configuration %s received",
+		"log.remote_applied":         "✅ Remote configuration %s applied",
+		"log.remote_rejected":        "⚠️  Remote configuration %s rejected, keeping the previous one: %v",
+		"log.remote_error":           "⚠️  Could not fetch the remote configuration: %v",
+		"log.remote_cached":          "📦 Using cached remote configuration %s",
+		"log.mib_load_error":         "⚠️  Error loading MIBs from %s: %v",
+		"log.mib_builtin_error":      "⚠️  Embedded MIB %s: %v",
+		"log.mib_unresolved":         "⚠️  %d unresolved symbols (missing the MIB that defines them): %s",
+		"log.mib_usage":              "Usage: printsnmp mib compile [-out file.json] <mib|dir...> | mib resolve <oid|name...>",
+		"log.mib_compiled":           "✅ %d nodes compiled into %s",
+		"log.mib_not_found":          "❓ %s: not in the loaded MIBs",
+		"log.command_received":       "📨 Remote command %s (%s)",
+		"log.command_done":           "✅ Command %s: %s",
+		"log.command_error":          "⚠️  Command %s failed: %v",
+		"log.command_fetch_error":    "⚠️  Could not fetch remote commands: %v",
+		"log.walk_fallback":          "↪️  WALK of %s blocked, %d OIDs fetched with direct GETs",
+	},
+}
@@ -0,0 +1,45 @@
+package mib
+
+import (
+	"embed"
+	"log"
+	"path"
+	"sync"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// builtinMIBs son los subconjuntos de MIBs estándar que el agente consulta
+// (SNMPv2-MIB, HOST-RESOURCES-MIB, Printer-MIB) y las raíces de fabricantes
+//
+//go:embed builtin/*.mib
+var builtinMIBs embed.FS
+
+var (
+	defaultTree *Tree
+	defaultOnce sync.Once
+)
+
+// Default retorna el árbol compartido del proceso, con los MIBs embebidos ya
+// cargados. Los MIBs de mibs.paths se agregan sobre este mismo árbol
+func Default() *Tree {
+	defaultOnce.Do(func() {
+		defaultTree = NewTree()
+
+		entries, err := builtinMIBs.ReadDir("builtin")
+		if err != nil {
+			log.Print(i18n.T("log.mib_builtin_error", "builtin", err))
+			return
+		}
+		for _, entry := range entries {
+			data, err := builtinMIBs.ReadFile(path.Join("builtin", entry.Name()))
+			if err == nil {
+				err = defaultTree.LoadText(string(data))
+			}
+			if err != nil {
+				log.Print(i18n.T("log.mib_builtin_error", entry.Name(), err))
+			}
+		}
+	})
+	return defaultTree
+}
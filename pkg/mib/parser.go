@@ -0,0 +1,357 @@
+package mib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// definition es un OID declarado en un módulo, pendiente de resolver contra su padre
+type definition struct {
+	module string
+	name   string
+	parent string
+	arcs   []arc
+	syntax string
+	access string
+	enums  map[int]string
+}
+
+// arc es un componente del valor OID ({ parent name(3) 4 })
+type arc struct {
+	name   string // "" si el componente es solo numérico
+	number int
+}
+
+// typeDef es un tipo declarado en un módulo (TEXTUAL-CONVENTION o asignación)
+type typeDef struct {
+	syntax string
+	enums  map[int]string
+}
+
+// module es el resultado de parsear un archivo MIB (SMIv1/SMIv2)
+type module struct {
+	name  string
+	defs  []definition
+	types map[string]typeDef
+}
+
+// macros que asignan un OID a un símbolo (name MACRO ... ::= { parent n })
+var oidMacros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"MODULE-IDENTITY":    true,
+	"OBJECT-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+}
+
+// parseModule parsea el texto de un MIB
+// Solo interesa lo necesario para nombres y enumeraciones: asignaciones de
+// OID, SYNTAX, MAX-ACCESS y tipos enumerados. El resto se ignora
+func parseModule(source string) (*module, error) {
+	p := &parser{tokens: tokenize(source)}
+	return p.parse()
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek(offset int) string {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	tok := p.peek(0)
+	p.pos++
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return fmt.Errorf("se esperaba %q y se encontró %q", tok, got)
+	}
+	return nil
+}
+
+func (p *parser) parse() (*module, error) {
+	m := &module{types: make(map[string]typeDef)}
+
+	for p.pos < len(p.tokens) {
+		tok := p.peek(0)
+
+		switch {
+		case p.peek(1) == "DEFINITIONS":
+			m.name = tok
+			p.pos += 2
+
+		case tok == "IMPORTS" || tok == "EXPORTS":
+			p.skipUntil(";")
+
+		case p.peek(1) == "MACRO":
+			p.skipUntil("END")
+
+		case isValueName(tok) && p.peek(1) == "OBJECT" && p.peek(2) == "IDENTIFIER" && p.peek(3) == "::=":
+			p.pos += 4
+			def, err := p.parseOIDValue(m.name, tok)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", tok, err)
+			}
+			m.defs = append(m.defs, def)
+
+		case isValueName(tok) && oidMacros[p.peek(1)]:
+			def, err := p.parseMacro(m.name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", tok, err)
+			}
+			m.defs = append(m.defs, def)
+
+		case isValueName(tok) && p.peek(1) == "TRAP-TYPE":
+			// SMIv1: el valor es un número de trap, no un OID
+			p.skipUntil("::=")
+			p.next()
+
+		case isTypeName(tok) && p.peek(1) == "::=":
+			p.pos += 2
+			if p.peek(0) == "TEXTUAL-CONVENTION" {
+				p.skipUntil("SYNTAX")
+				p.next()
+			}
+			syntax, enums := p.parseSyntax()
+			m.types[tok] = typeDef{syntax: syntax, enums: enums}
+
+		default:
+			p.pos++
+		}
+	}
+
+	if m.name == "" {
+		return nil, fmt.Errorf("no es un módulo MIB (falta DEFINITIONS ::= BEGIN)")
+	}
+	return m, nil
+}
+
+// parseMacro lee "name MACRO <cláusulas> ::= { parent n }"
+func (p *parser) parseMacro(moduleName string) (definition, error) {
+	name := p.next()
+	p.next() // macro
+
+	var syntax, access string
+	var enums map[int]string
+	for p.pos < len(p.tokens) && p.peek(0) != "::=" {
+		switch p.next() {
+		case "SYNTAX":
+			syntax, enums = p.parseSyntax()
+		case "MAX-ACCESS", "ACCESS":
+			access = p.next()
+		case "{":
+			// INDEX { ... }, OBJECTS { ... }, etc.
+			p.pos--
+			p.skipBraces()
+		}
+	}
+	if err := p.expect("::="); err != nil {
+		return definition{}, err
+	}
+
+	def, err := p.parseOIDValue(moduleName, name)
+	def.syntax, def.access, def.enums = syntax, access, enums
+	return def, err
+}
+
+// parseSyntax lee un tipo: "INTEGER { a(1), b(2) }", "OCTET STRING (SIZE (0..255))",
+// "SEQUENCE OF PrtAlertEntry", "DisplayString"...
+func (p *parser) parseSyntax() (string, map[int]string) {
+	syntax := p.next()
+	switch syntax {
+	case "OCTET", "OBJECT":
+		syntax += " " + p.next() // STRING / IDENTIFIER
+	case "SEQUENCE":
+		if p.peek(0) == "OF" {
+			p.next()
+			syntax += " OF " + p.next()
+		}
+	}
+
+	var enums map[int]string
+	if p.peek(0) == "{" {
+		if syntax == "SEQUENCE" {
+			p.skipBraces()
+		} else {
+			enums = p.parseEnums()
+		}
+	}
+	if p.peek(0) == "(" {
+		p.skipParens()
+	}
+	return syntax, enums
+}
+
+// parseEnums lee "{ other(1), unknown(2) }" (también BITS)
+func (p *parser) parseEnums() map[int]string {
+	enums := make(map[int]string)
+	p.next() // {
+	for p.pos < len(p.tokens) && p.peek(0) != "}" {
+		label := p.next()
+		if label == "," {
+			continue
+		}
+		if p.peek(0) != "(" {
+			continue
+		}
+		p.next()
+		value, err := strconv.Atoi(p.next())
+		p.skipUntil(")")
+		p.next()
+		if err == nil {
+			enums[value] = label
+		}
+	}
+	p.next() // }
+	return enums
+}
+
+// parseOIDValue lee "{ parent 1 }", "{ iso(1) org(3) 6 }" o "{ parent name(2) 3 }"
+func (p *parser) parseOIDValue(moduleName, name string) (definition, error) {
+	def := definition{module: moduleName, name: name}
+	if err := p.expect("{"); err != nil {
+		return def, err
+	}
+
+	first := true
+	for p.pos < len(p.tokens) && p.peek(0) != "}" {
+		tok := p.next()
+		if n, err := strconv.Atoi(tok); err == nil {
+			if first {
+				def.parent = "" // OID absoluto desde la raíz
+			}
+			def.arcs = append(def.arcs, arc{number: n})
+		} else if p.peek(0) == "(" {
+			p.next()
+			n, err := strconv.Atoi(p.next())
+			if err != nil {
+				return def, fmt.Errorf("componente OID inválido %s", tok)
+			}
+			if err := p.expect(")"); err != nil {
+				return def, err
+			}
+			def.arcs = append(def.arcs, arc{name: tok, number: n})
+		} else if first {
+			def.parent = tok
+		} else {
+			return def, fmt.Errorf("componente OID inválido %s", tok)
+		}
+		first = false
+	}
+	return def, p.expect("}")
+}
+
+func (p *parser) skipUntil(tok string) {
+	for p.pos < len(p.tokens) && p.peek(0) != tok {
+		p.pos++
+	}
+}
+
+func (p *parser) skipBraces() { p.skipBalanced("{", "}") }
+func (p *parser) skipParens() { p.skipBalanced("(", ")") }
+
+func (p *parser) skipBalanced(open, close string) {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		switch p.next() {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// tokenize separa el texto en tokens ASN.1: identificadores, números y
+// símbolos. Comentarios ("--" hasta fin de línea o el siguiente "--") y
+// strings (DESCRIPTION, REFERENCE...) se descartan
+func tokenize(source string) []string {
+	var tokens []string
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			i += 2
+			for i < len(runes) && runes[i] != '\n' {
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			i++
+
+		case r == '\'':
+			// Literales hex/binarios ('00'H): se ignoran
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				i++
+			}
+			i += 2
+
+		case r == ':' && strings.HasPrefix(string(runes[i:min(i+3, len(runes))]), "::="):
+			tokens = append(tokens, "::=")
+			i += 3
+
+		case r == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			tokens = append(tokens, "..")
+			i += 2
+
+		case strings.ContainsRune("{}(),;|[]", r):
+			tokens = append(tokens, string(r))
+			i++
+
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && (isIdentRune(runes[i]) || (runes[i] == '-' && i+1 < len(runes) && runes[i+1] != '-')) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isValueName: los valores ASN.1 empiezan con minúscula (sysDescr)
+func isValueName(tok string) bool {
+	return tok != "" && unicode.IsLower(rune(tok[0]))
+}
+
+// isTypeName: los tipos empiezan con mayúscula (DisplayString)
+func isTypeName(tok string) bool {
+	return tok != "" && unicode.IsUpper(rune(tok[0]))
+}
@@ -0,0 +1,322 @@
+// Package mib carga archivos MIB (SMIv1/SMIv2) o su forma compilada en JSON
+// para traducir OIDs a nombres simbólicos y valores a sus enumeraciones
+package mib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// Node es un objeto del árbol MIB
+type Node struct {
+	OID    string         `json:"oid"`
+	Name   string         `json:"name"`
+	Module string         `json:"module,omitempty"`
+	Syntax string         `json:"syntax,omitempty"` // "Counter32", "DisplayString", "PrtMarkerSuppliesTypeTC"...
+	Access string         `json:"access,omitempty"` // "read-only", "not-accessible"...
+	Enums  map[int]string `json:"enums,omitempty"`  // valor → etiqueta (INTEGER enumerado)
+}
+
+// roots son los nodos que SNMPv2-SMI define y casi ningún MIB trae consigo
+var roots = []Node{
+	{OID: "0", Name: "ccitt"},
+	{OID: "1", Name: "iso"},
+	{OID: "2", Name: "joint-iso-ccitt"},
+	{OID: "0.0", Name: "zeroDotZero"},
+	{OID: "1.3", Name: "org"},
+	{OID: "1.3.6", Name: "dod"},
+	{OID: "1.3.6.1", Name: "internet"},
+	{OID: "1.3.6.1.1", Name: "directory"},
+	{OID: "1.3.6.1.2", Name: "mgmt"},
+	{OID: "1.3.6.1.2.1", Name: "mib-2"},
+	{OID: "1.3.6.1.2.1.10", Name: "transmission"},
+	{OID: "1.3.6.1.3", Name: "experimental"},
+	{OID: "1.3.6.1.4", Name: "private"},
+	{OID: "1.3.6.1.4.1", Name: "enterprises"},
+	{OID: "1.3.6.1.5", Name: "security"},
+	{OID: "1.3.6.1.6", Name: "snmpV2"},
+	{OID: "1.3.6.1.6.1", Name: "snmpDomains"},
+	{OID: "1.3.6.1.6.2", Name: "snmpProxys"},
+	{OID: "1.3.6.1.6.3", Name: "snmpModules"},
+}
+
+// Tree es el conjunto de MIBs cargados. Es seguro para uso concurrente
+type Tree struct {
+	mu      sync.RWMutex
+	byOID   map[string]*Node
+	byName  map[string]*Node
+	types   map[string]typeDef
+	pending []definition // definiciones cuyo padre todavía no se cargó
+}
+
+// NewTree crea un árbol con las raíces de SNMPv2-SMI
+func NewTree() *Tree {
+	t := &Tree{
+		byOID:  make(map[string]*Node),
+		byName: make(map[string]*Node),
+		types:  make(map[string]typeDef),
+	}
+	for i := range roots {
+		node := roots[i]
+		t.add(&node)
+	}
+	return t
+}
+
+// LoadFile carga un MIB en texto o compilado (.json)
+func (t *Tree) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return t.LoadJSON(data)
+	}
+	if err := t.LoadText(string(data)); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// mibExtensions son las extensiones que LoadDir considera MIBs
+var mibExtensions = map[string]bool{".mib": true, ".my": true, ".txt": true, ".smi": true, ".json": true, "": true}
+
+// LoadDir carga todos los MIBs de un directorio (sin recursión)
+// Los archivos que fallan no impiden cargar el resto; se informan juntos
+func (t *Tree) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !mibExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		if err := t.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadText parsea un módulo MIB y agrega sus objetos al árbol
+// El orden de carga no importa: lo que depende de un módulo todavía no
+// cargado queda pendiente y se resuelve al cargarlo
+func (t *Tree) LoadText(source string) error {
+	m, err := parseModule(source)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, td := range m.types {
+		t.types[name] = td
+	}
+	t.pending = append(t.pending, m.defs...)
+	t.resolve()
+	return nil
+}
+
+// LoadJSON agrega nodos compilados (el formato de WriteJSON)
+func (t *Tree) LoadJSON(data []byte) error {
+	var nodes []*Node
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return fmt.Errorf("MIB compilado inválido: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, node := range nodes {
+		if node.OID == "" || node.Name == "" {
+			continue
+		}
+		node.OID = strings.TrimPrefix(node.OID, ".")
+		t.add(node)
+	}
+	t.resolve()
+	return nil
+}
+
+// resolve asigna OID a las definiciones pendientes cuyo padre ya se conoce
+// (requiere lock tomado)
+func (t *Tree) resolve() {
+	for progress := true; progress; {
+		progress = false
+		remaining := t.pending[:0]
+
+		for _, def := range t.pending {
+			base := ""
+			if def.parent != "" {
+				parent, ok := t.byName[def.parent]
+				if !ok {
+					remaining = append(remaining, def)
+					continue
+				}
+				base = parent.OID
+			}
+
+			oid := base
+			for _, a := range def.arcs {
+				if oid == "" {
+					oid = strconv.Itoa(a.number)
+				} else {
+					oid += "." + strconv.Itoa(a.number)
+				}
+				// Componentes con nombre ({ iso(1) org(3) }) también son nodos
+				if a.name != "" {
+					if _, ok := t.byName[a.name]; !ok {
+						t.add(&Node{OID: oid, Name: a.name, Module: def.module})
+					}
+				}
+			}
+
+			node := &Node{OID: oid, Name: def.name, Module: def.module, Syntax: def.syntax, Access: def.access, Enums: def.enums}
+			if node.Enums == nil {
+				if td, ok := t.types[def.syntax]; ok && len(td.enums) > 0 {
+					node.Enums = td.enums
+				}
+			}
+			t.add(node)
+			progress = true
+		}
+		t.pending = remaining
+	}
+}
+
+// add registra un nodo (requiere lock tomado)
+func (t *Tree) add(node *Node) {
+	if existing, ok := t.byOID[node.OID]; ok && existing.Module != "" && node.Module == "" {
+		return // un nodo compilado sin módulo no pisa uno parseado
+	}
+	t.byOID[node.OID] = node
+	t.byName[node.Name] = node
+}
+
+// Lookup retorna el nodo más específico que contiene oid y el sufijo de
+// instancia ("1.3.6.1.2.1.43.10.2.1.4.1.1" → prtMarkerLifeCount, "1.1")
+func (t *Tree) Lookup(oid string) (*Node, string) {
+	oid = strings.TrimPrefix(oid, ".")
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for prefix := oid; prefix != ""; {
+		if node, ok := t.byOID[prefix]; ok {
+			return node, strings.TrimPrefix(strings.TrimPrefix(oid, prefix), ".")
+		}
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return nil, oid
+}
+
+// Name traduce un OID a su forma simbólica ("sysUpTime.0")
+// Si ningún MIB cargado lo cubre retorna el OID numérico
+func (t *Tree) Name(oid string) string {
+	node, suffix := t.Lookup(oid)
+	if node == nil {
+		return strings.TrimPrefix(oid, ".")
+	}
+	if suffix == "" {
+		return node.Name
+	}
+	return node.Name + "." + suffix
+}
+
+// Resolve traduce un nombre simbólico ("sysUpTime.0", "prtMarkerLifeCount")
+// o "MODULO::nombre" a OID numérico
+func (t *Tree) Resolve(name string) (string, bool) {
+	if _, after, ok := strings.Cut(name, "::"); ok {
+		name = after
+	}
+	symbol, suffix, _ := strings.Cut(name, ".")
+
+	t.mu.RLock()
+	node, ok := t.byName[symbol]
+	t.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if suffix == "" {
+		return node.OID, true
+	}
+	return node.OID + "." + suffix, true
+}
+
+// Enum retorna la etiqueta de value para el objeto de oid ("toner" para
+// prtMarkerSuppliesType = 3). false si el objeto no es enumerado
+func (t *Tree) Enum(oid string, value int) (string, bool) {
+	node, _ := t.Lookup(oid)
+	if node == nil || node.Enums == nil {
+		return "", false
+	}
+	label, ok := node.Enums[value]
+	return label, ok
+}
+
+// Nodes retorna todos los nodos ordenados por OID
+func (t *Tree) Nodes() []*Node {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	nodes := make([]*Node, 0, len(t.byOID))
+	for _, node := range t.byOID {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return compareOIDs(nodes[i].OID, nodes[j].OID) < 0 })
+	return nodes
+}
+
+// Unresolved retorna los símbolos cuyo padre no está en ningún MIB cargado
+func (t *Tree) Unresolved() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names := make([]string, 0, len(t.pending))
+	for _, def := range t.pending {
+		names = append(names, def.module+"::"+def.name+" (padre "+def.parent+")")
+	}
+	return names
+}
+
+// SaveJSON compila el árbol a JSON (se carga mucho más rápido que los MIBs en texto)
+func (t *Tree) SaveJSON(path string) error {
+	data, err := json.MarshalIndent(t.Nodes(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
+
+// compareOIDs compara OIDs numéricos componente a componente
+func compareOIDs(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(pa) - len(pb)
+}
@@ -0,0 +1,265 @@
+// Package modeldb identifica el modelo comercial de una impresora a partir de
+// su sysObjectID y de las cadenas de modelo que reporta (hrDeviceDescr,
+// prtGeneralPrinterName, sysDescr) y aporta sus datos de catálogo: velocidad,
+// clase mono/color y rendimiento nominal de los consumibles
+// La base incorporada (models.json) se puede ampliar con un archivo propio
+package modeldb
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed models.json
+var builtinModels []byte
+
+// Model es un modelo del catálogo
+type Model struct {
+	Name         string        `json:"name"` // nombre canónico: "HP LaserJet Pro M402"
+	Brand        string        `json:"brand"`
+	Aliases      []string      `json:"aliases,omitempty"`        // fragmentos del nombre que reporta el equipo ("laserjet pro m402")
+	SysObjectIDs []string      `json:"sys_object_ids,omitempty"` // solo los propios del modelo (no los genéricos de la marca)
+	PPM          int           `json:"ppm,omitempty"`            // páginas por minuto (A4); 0 = desconocido
+	Color        bool          `json:"color"`
+	Supplies     []SupplyYield `json:"supplies,omitempty"`
+}
+
+// SupplyYield es el rendimiento nominal (ISO/IEC 19752, 19798) de un consumible
+// Aplica a los consumibles cuya descripción contiene alguna de Match y, si
+// Color no está vacío, con ese colorante
+type SupplyYield struct {
+	Match []string `json:"match"`
+	Color string   `json:"color,omitempty"`
+	Pages int64    `json:"pages"`
+}
+
+// File es el formato de models.json y de los archivos propios
+type File struct {
+	Models []Model `json:"models"`
+}
+
+// Origen de una identificación
+const (
+	SourceSysObjectID = "sys_object_id" // solo por sysObjectID
+	SourceName        = "name"          // solo por el nombre reportado
+	SourceBoth        = "sys_object_id+name"
+)
+
+// Confianza de cada tipo de coincidencia
+const (
+	confidenceBoth      = 1.0
+	confidenceExactName = 0.95 // el nombre reportado es el canónico o un alias completo
+	confidenceOID       = 0.85 // sysObjectID propio del modelo, sin nombre que lo confirme
+	confidenceName      = 0.75 // el nombre reportado contiene un alias
+)
+
+// Match es el resultado de Lookup
+type Match struct {
+	Model      *Model
+	Confidence float64 // 0.0-1.0
+	Source     string  // sys_object_id | name | sys_object_id+name
+}
+
+// Database es un catálogo de modelos
+type Database struct {
+	models []Model
+	byOID  map[string][]int // sysObjectID → índices en models
+}
+
+// Builtin retorna la base incorporada
+func Builtin() *Database {
+	db, err := parse(builtinModels)
+	if err != nil {
+		panic(fmt.Sprintf("modeldb: models.json incorporado inválido: %v", err))
+	}
+	return db
+}
+
+// Load retorna la base incorporada ampliada con el archivo path ("" = solo la
+// incorporada). Los modelos del archivo se evalúan antes que los incorporados
+func Load(path string) (*Database, error) {
+	if path == "" {
+		return Builtin(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return extra.merge(Builtin()), nil
+}
+
+// parse interpreta un archivo de modelos
+func parse(data []byte) (*Database, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i, m := range file.Models {
+		if m.Name == "" {
+			return nil, fmt.Errorf("models[%d]: falta name", i)
+		}
+		for j, s := range m.Supplies {
+			if s.Pages <= 0 || len(s.Match) == 0 {
+				return nil, fmt.Errorf("models[%d] (%s): supplies[%d]: se esperan match y pages > 0", i, m.Name, j)
+			}
+		}
+	}
+	return newDatabase(file.Models), nil
+}
+
+// newDatabase indexa los modelos por sysObjectID
+func newDatabase(models []Model) *Database {
+	db := &Database{models: models, byOID: make(map[string][]int)}
+	for i, m := range models {
+		for _, oid := range m.SysObjectIDs {
+			oid = strings.TrimPrefix(strings.TrimSpace(oid), ".")
+			db.byOID[oid] = append(db.byOID[oid], i)
+		}
+	}
+	return db
+}
+
+// merge retorna una base con los modelos de db seguidos de los de other
+func (db *Database) merge(other *Database) *Database {
+	models := make([]Model, 0, len(db.models)+len(other.models))
+	models = append(models, db.models...)
+	models = append(models, other.models...)
+	return newDatabase(models)
+}
+
+// Len retorna la cantidad de modelos
+func (db *Database) Len() int {
+	return len(db.models)
+}
+
+// Lookup identifica el modelo con el sysObjectID y los nombres que reporta el
+// equipo. Gana la coincidencia de mayor confianza; a igual confianza, el
+// alias más largo (el más específico)
+func (db *Database) Lookup(sysObjectID string, names ...string) (Match, bool) {
+	if db == nil {
+		return Match{}, false
+	}
+	oidMatches := make(map[int]bool)
+	for _, i := range db.byOID[strings.TrimPrefix(strings.TrimSpace(sysObjectID), ".")] {
+		oidMatches[i] = true
+	}
+
+	var normalized []string
+	for _, name := range names {
+		if n := normalize(name); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+
+	best, bestLen := Match{}, -1
+	for i := range db.models {
+		m := &db.models[i]
+		confidence, length := nameMatch(m, normalized)
+		source := SourceName
+		switch {
+		case oidMatches[i] && confidence > 0:
+			confidence, source = confidenceBoth, SourceBoth
+		case oidMatches[i] && len(oidMatches) == 1:
+			confidence, source = confidenceOID, SourceSysObjectID
+		}
+		if confidence == 0 {
+			continue
+		}
+		if confidence > best.Confidence || (confidence == best.Confidence && length > bestLen) {
+			best, bestLen = Match{Model: m, Confidence: confidence, Source: source}, length
+		}
+	}
+	return best, best.Model != nil
+}
+
+// nameMatch compara los nombres reportados con el nombre canónico y los
+// alias del modelo. Retorna la confianza (0 = no coincide) y el largo del
+// alias que coincidió
+func nameMatch(m *Model, names []string) (float64, int) {
+	candidates := append([]string{m.Name}, m.Aliases...)
+	confidence, length := 0.0, 0
+	for _, candidate := range candidates {
+		alias := normalize(candidate)
+		if alias == "" {
+			continue
+		}
+		for _, name := range names {
+			c := 0.0
+			switch {
+			case name == alias:
+				c = confidenceExactName
+			case containsWords(name, alias):
+				c = confidenceName
+			}
+			if c > confidence || (c == confidence && c > 0 && len(alias) > length) {
+				confidence, length = c, len(alias)
+			}
+		}
+	}
+	return confidence, length
+}
+
+// containsWords indica si alias aparece en name empezando en un límite de
+// palabra ("laserjet pro m402" está en "hp laserjet pro m402dn", "m402" no
+// está en "xm402")
+func containsWords(name, alias string) bool {
+	for from := 0; ; {
+		i := strings.Index(name[from:], alias)
+		if i < 0 {
+			return false
+		}
+		i += from
+		if i == 0 || name[i-1] == ' ' {
+			return true
+		}
+		from = i + 1
+	}
+}
+
+// normalize pasa a minúsculas y reduce los separadores a un espacio
+// ("HP_LaserJet  Pro" → "hp laserjet pro"); conserva los guiones de "MX-3071"
+func normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '\t' || r == ';' || r == ','
+	})
+	return strings.Join(fields, " ")
+}
+
+// Yield retorna el rendimiento nominal del consumible con esa descripción y
+// colorante (0 si el catálogo no lo tiene). Sin colorante se busca el color
+// en la descripción ("Black Cartridge")
+func (m *Model) Yield(description, color string) int64 {
+	if m == nil {
+		return 0
+	}
+	description = strings.ToLower(description)
+	for _, s := range m.Supplies {
+		switch {
+		case s.Color == "":
+		case color == "" && strings.Contains(description, strings.ToLower(s.Color)):
+		case !strings.EqualFold(s.Color, color):
+			continue
+		}
+		for _, keyword := range s.Match {
+			if strings.Contains(description, strings.ToLower(keyword)) {
+				return s.Pages
+			}
+		}
+	}
+	return 0
+}
+
+// Class retorna "color" o "mono"
+func (m *Model) Class() string {
+	if m.Color {
+		return "color"
+	}
+	return "mono"
+}
@@ -0,0 +1,70 @@
+// Package oids reúne listas curadas de OIDs que se consultan con GET directo
+// cuando el dispositivo no permite recorrer (WALK) el subárbol
+package oids
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxFilasDirectas es cuántas filas de cada tabla se piden por GET
+// Las impresoras indexan marcadores y consumibles desde 1 y rara vez pasan de 8
+const maxFilasDirectas = 8
+
+// OIDsContadoresDirectos son los contadores de páginas de Printer MIB (RFC 3805)
+// prtMarkerTable: hrDeviceIndex 1, marcadores 1 y 2 (motores de marcado, no
+// tipos de contador; colorantes y unidad dicen cómo interpretarlos)
+var OIDsContadoresDirectos = []string{
+	"1.3.6.1.2.1.43.10.2.1.4.1.1", // prtMarkerLifeCount (marcador 1)
+	"1.3.6.1.2.1.43.10.2.1.4.1.2", // prtMarkerLifeCount (marcador 2)
+	"1.3.6.1.2.1.43.10.2.1.5.1.1", // prtMarkerPowerOnCount
+	"1.3.6.1.2.1.43.10.2.1.3.1.1", // prtMarkerCounterUnit (7 = impresiones, 8 = hojas)
+	"1.3.6.1.2.1.43.10.2.1.3.1.2",
+	"1.3.6.1.2.1.43.10.2.1.6.1.1", // prtMarkerProcessColorants (1 = monocromo)
+	"1.3.6.1.2.1.43.10.2.1.6.1.2",
+}
+
+// OIDsConsumiblesDirectos son las columnas de prtMarkerSuppliesTable que usa
+// el collector, para las filas 1..8 del dispositivo 1
+var OIDsConsumiblesDirectos = tabla("1.3.6.1.2.1.43.11.1.1", []int{
+	3, // prtMarkerSuppliesColorantIndex
+	4, // prtMarkerSuppliesClass
+	5, // prtMarkerSuppliesType
+	6, // prtMarkerSuppliesDescription
+	7, // prtMarkerSuppliesSupplyUnit
+	8, // prtMarkerSuppliesMaxCapacity
+	9, // prtMarkerSuppliesLevel
+}, maxFilasDirectas)
+
+// OIDsColorantesDirectos son los valores de prtMarkerColorantTable ("black",
+// "cyan"...) a los que apunta prtMarkerSuppliesColorantIndex
+var OIDsColorantesDirectos = tabla("1.3.6.1.2.1.43.12.1.1", []int{
+	4, // prtMarkerColorantValue
+}, maxFilasDirectas)
+
+// Directos retorna los OIDs de las listas curadas (más extra) que caen dentro
+// del subárbol baseOID, en el orden de las listas
+func Directos(baseOID string, extra ...string) []string {
+	baseOID = strings.TrimPrefix(baseOID, ".")
+
+	var out []string
+	for _, list := range [][]string{OIDsContadoresDirectos, OIDsConsumiblesDirectos, OIDsColorantesDirectos, extra} {
+		for _, oid := range list {
+			if oid == baseOID || strings.HasPrefix(oid, baseOID+".") {
+				out = append(out, oid)
+			}
+		}
+	}
+	return out
+}
+
+// tabla genera base.<columna>.1.<fila> para cada columna y fila 1..filas
+func tabla(base string, columnas []int, filas int) []string {
+	out := make([]string, 0, len(columnas)*filas)
+	for _, col := range columnas {
+		for fila := 1; fila <= filas; fila++ {
+			out = append(out, base+"."+strconv.Itoa(col)+".1."+strconv.Itoa(fila))
+		}
+	}
+	return out
+}
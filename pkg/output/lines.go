@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// LineWriter escribe un archivo NDJSON (un documento JSON por línea) a medida
+// que llegan los datos, sin armar el documento completo en memoria
+// Mientras está abierto se escribe en <name>.tmp: el archivo final aparece
+// completo al cerrar, igual que con WriteFile
+type LineWriter struct {
+	path    string
+	file    *os.File
+	buf     *bufio.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+// CreateLines abre <dir del ciclo>/<name> para escritura por líneas
+func (r *Run) CreateLines(name string) (*LineWriter, error) {
+	path := filepath.Join(r.Dir(), name)
+	file, err := os.Create(path + fsutil.TmpSuffix)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriterSize(file, 64*1024)
+	encoder := json.NewEncoder(buf) // compacto: Encode agrega el \n de cada línea
+	encoder.SetEscapeHTML(false)
+	return &LineWriter{path: path, file: file, buf: buf, encoder: encoder}, nil
+}
+
+// Write agrega v como una línea
+func (w *LineWriter) Write(v interface{}) error {
+	if err := w.encoder.Encode(v); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Count retorna las líneas escritas
+func (w *LineWriter) Count() int {
+	return w.count
+}
+
+// Close vuelca lo pendiente, hace fsync y publica el archivo con su nombre final
+func (w *LineWriter) Close() error {
+	tmp := w.file.Name()
+	err := w.buf.Flush()
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
@@ -0,0 +1,136 @@
+// Package output guarda los resultados de cada ciclo en un directorio propio
+// (<dir>/<timestamp>/) con retención y un enlace latest al último, para que
+// los escaneos anteriores no se pierdan al sobrescribirse
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// runLayout es el nombre de un directorio de ciclo (UTC, ordena cronológicamente)
+const runLayout = "20060102T150405Z"
+
+// LatestLink apunta al último ciclo completo
+// Donde no se pueden crear symlinks (Windows sin privilegios) se escribe
+// LatestFile con el nombre del directorio
+const (
+	LatestLink = "latest"
+	LatestFile = "LATEST"
+)
+
+// runNamePattern reconoce directorios de ciclo (con sufijo -N si hubo dos en el mismo segundo)
+var runNamePattern = regexp.MustCompile(`^\d{8}T\d{6}Z(-\d+)?$`)
+
+// Retention decide qué ciclos se conservan; 0 = sin límite
+type Retention struct {
+	KeepRuns int           // cantidad máxima de ciclos
+	MaxAge   time.Duration // antigüedad máxima
+}
+
+// Run es el directorio de un ciclo en curso
+type Run struct {
+	root string
+	name string
+}
+
+// StartRun crea <root>/<startedAt UTC>/
+func StartRun(root string, startedAt time.Time) (*Run, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	base := startedAt.UTC().Format(runLayout)
+	name := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(filepath.Join(root, name), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return &Run{root: root, name: name}, nil
+}
+
+// Dir retorna el directorio del ciclo
+func (r *Run) Dir() string {
+	return filepath.Join(r.root, r.name)
+}
+
+// WriteFile escribe un archivo del ciclo de forma atómica
+func (r *Run) WriteFile(name string, data []byte) error {
+	return fsutil.WriteFileAtomic(filepath.Join(r.Dir(), name), data, 0644)
+}
+
+// Finish apunta latest a este ciclo y aplica la retención
+// Retorna los ciclos eliminados; el actual nunca se elimina
+func (r *Run) Finish(retention Retention) ([]string, error) {
+	if err := r.markLatest(); err != nil {
+		return nil, err
+	}
+	return Prune(r.root, retention, r.name, time.Now())
+}
+
+// markLatest reemplaza el symlink latest (o LATEST si no hay symlinks)
+func (r *Run) markLatest() error {
+	link := filepath.Join(r.root, LatestLink)
+	tmp := link + fsutil.TmpSuffix
+	os.Remove(tmp)
+	if err := os.Symlink(r.name, tmp); err == nil {
+		// rename sobre el symlink anterior: nunca hay un instante sin latest
+		return os.Rename(tmp, link)
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(r.root, LatestFile), []byte(r.name+"\n"), 0644)
+}
+
+// Prune elimina los ciclos que exceden la retención, del más viejo al más nuevo
+// keep es un ciclo que no se elimina aunque exceda (el recién escrito)
+func Prune(root string, retention Retention, keep string, now time.Time) ([]string, error) {
+	if retention.KeepRuns <= 0 && retention.MaxAge <= 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() && runNamePattern.MatchString(entry.Name()) {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Strings(runs) // más viejo primero
+
+	var removed []string
+	for i, name := range runs {
+		if name == keep {
+			continue
+		}
+		tooMany := retention.KeepRuns > 0 && len(runs)-i > retention.KeepRuns
+		tooOld := false
+		if retention.MaxAge > 0 {
+			if at, err := time.Parse(runLayout, name[:len(runLayout)]); err == nil {
+				tooOld = now.Sub(at) > retention.MaxAge
+			}
+		}
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
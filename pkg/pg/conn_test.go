@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/internal/pbkdf2"
+)
+
+func TestMessageFraming(t *testing.T) {
+	got := newMessage('Q').cstring("SELECT 1").finish()
+	want := append([]byte{'Q', 0, 0, 0, 13}, "SELECT 1\x00"...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Query: % x, se esperaba % x", got, want)
+	}
+
+	// El largo cuenta el propio int32 pero no el byte de tipo
+	got = newMessage('S').finish()
+	if !bytes.Equal(got, []byte{'S', 0, 0, 0, 4}) {
+		t.Errorf("Sync: % x", got)
+	}
+
+	got = newMessage('B').int16(-1).int32(-1).byte(7).finish()
+	want = []byte{'B', 0, 0, 0, 11, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 7}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Bind: % x, se esperaba % x", got, want)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	frame := func(typ byte, body string) []byte {
+		return newMessage(typ).bytes([]byte(body)).finish()
+	}
+
+	stream := append(frame('Z', "I"), frame('C', "INSERT 0 1\x00")...)
+	c := &Conn{reader: bufio.NewReader(bytes.NewReader(stream))}
+	for _, want := range []struct {
+		typ  byte
+		body string
+	}{{'Z', "I"}, {'C', "INSERT 0 1\x00"}} {
+		typ, data, err := c.receive()
+		if err != nil || typ != want.typ || string(data) != want.body {
+			t.Fatalf("receive = %c %q %v, se esperaba %c %q", typ, data, err, want.typ, want.body)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		stream []byte
+	}{
+		{"largo menor que el encabezado", []byte{'Z', 0, 0, 0, 3}},
+		{"largo gigante", []byte{'D', 0x7f, 0xff, 0xff, 0xff}},
+		{"cuerpo truncado", []byte{'Z', 0, 0, 0, 9, 'I'}},
+		{"encabezado truncado", []byte{'Z', 0, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Conn{reader: bufio.NewReader(bytes.NewReader(tc.stream))}
+			if _, _, err := c.receive(); err == nil {
+				t.Error("se aceptó un mensaje mal armado")
+			}
+			if !c.Broken() {
+				t.Error("la conexión no quedó marcada como rota")
+			}
+		})
+	}
+}
+
+func TestParseDataRow(t *testing.T) {
+	data := []byte{0, 3, 0, 0, 0, 2, 'h', 'p', 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}
+	row, err := parseDataRow(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row) != 3 || row[0] == nil || *row[0] != "hp" || row[1] != nil || row[2] == nil || *row[2] != "" {
+		t.Errorf("fila %v", row)
+	}
+
+	for _, bad := range [][]byte{
+		{0},
+		{0, 1, 0, 0},
+		{0, 1, 0, 0, 0, 5, 'a'},
+	} {
+		if _, err := parseDataRow(bad); err == nil {
+			t.Errorf("% x: se aceptó un DataRow truncado", bad)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	err := parseError([]byte("SERROR\x00C23505\x00Mduplicate key\x00Dya existe\x00\x00"))
+	var pgErr *Error
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" || pgErr.Message != "duplicate key" {
+		t.Fatalf("error %#v", err)
+	}
+	if got := err.Error(); got != "pg: ERROR 23505: duplicate key (ya existe)" {
+		t.Errorf("mensaje %q", got)
+	}
+}
+
+// fakeServer atiende una conexión: autentica con SCRAM-SHA-256 contra
+// password y responde cada consulta con las filas de rows
+type fakeServer struct {
+	t        *testing.T
+	password string
+	rows     [][]string
+	queries  chan string
+}
+
+func (s *fakeServer) start() string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(conn)
+	}()
+	return ln.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	send := func(m *message) { conn.Write(m.finish()) }
+	read := func() (byte, []byte) {
+		var header [5]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return 0, nil
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+		io.ReadFull(r, data)
+		return header[0], data
+	}
+
+	// StartupMessage: sin byte de tipo
+	var size [4]byte
+	io.ReadFull(r, size[:])
+	io.ReadFull(r, make([]byte, binary.BigEndian.Uint32(size[:])-4))
+
+	send(newMessage('R').int32(10).cstring("SCRAM-SHA-256").byte(0))
+	_, data := read()
+	mechanism, rest, _ := bytes.Cut(data, []byte{0})
+	clientFirst := string(rest[4:])
+	if string(mechanism) != "SCRAM-SHA-256" || !strings.HasPrefix(clientFirst, "n,,") {
+		send(newMessage('E').cstring("SFATAL").cstring("C08P01").cstring("Mmecanismo").byte(0))
+		return
+	}
+	firstBare := strings.TrimPrefix(clientFirst, "n,,")
+	salt := []byte("sal-de-prueba-16")
+	serverFirst := "r=" + scramAttributes(firstBare)["r"] + "srv,s=" + base64.StdEncoding.EncodeToString(salt) + ",i=4096"
+	send(newMessage('R').int32(11).bytes([]byte(serverFirst)))
+
+	_, data = read()
+	clientFinal := string(data)
+	withoutProof := clientFinal[:strings.LastIndex(clientFinal, ",p=")]
+	proof, _ := base64.StdEncoding.DecodeString(scramAttributes(clientFinal)["p"])
+
+	salted := pbkdf2.Key([]byte(s.password), salt, 4096, sha256.Size)
+	storedKey := sha256.Sum256(hmacSum(salted, "Client Key"))
+	authMessage := firstBare + "," + serverFirst + "," + withoutProof
+	signature := hmacSum(storedKey[:], authMessage)
+	clientKey := make([]byte, len(proof))
+	for i := range clientKey {
+		clientKey[i] = proof[i] ^ signature[i%len(signature)]
+	}
+	if len(proof) != len(signature) || sha256.Sum256(clientKey) != storedKey {
+		send(newMessage('E').cstring("SFATAL").cstring("C28P01").cstring("Mpassword authentication failed").byte(0))
+		return
+	}
+	verifier := hmacSum(hmacSum(salted, "Server Key"), authMessage)
+	send(newMessage('R').int32(12).bytes([]byte("v=" + base64.StdEncoding.EncodeToString(verifier))))
+	send(newMessage('R').int32(0))
+	send(newMessage('S').cstring("server_version").cstring("16.0"))
+	send(newMessage('Z').byte('I'))
+
+	for {
+		typ, data := read()
+		switch typ {
+		case 0, 'X':
+			return
+		case 'P':
+			_, rest, _ := bytes.Cut(data, []byte{0})
+			query, _, _ := bytes.Cut(rest, []byte{0})
+			s.queries <- string(query)
+		case 'S':
+			send(newMessage('1'))
+			send(newMessage('2'))
+			for _, row := range s.rows {
+				m := newMessage('D').int16(int16(len(row)))
+				for _, v := range row {
+					m.int32(int32(len(v))).bytes([]byte(v))
+				}
+				send(m)
+			}
+			send(newMessage('C').cstring("SELECT 1"))
+			send(newMessage('Z').byte('I'))
+		}
+	}
+}
+
+func connectFake(t *testing.T, s *fakeServer, password string) (*Conn, error) {
+	host, port, _ := net.SplitHostPort(s.start())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return Connect(ctx, Config{Host: host, Port: port, User: "agent", Password: password, SSLMode: "disable"})
+}
+
+func TestConnectSCRAMAndQuery(t *testing.T) {
+	s := &fakeServer{t: t, password: "pencil", rows: [][]string{{"HP", "150000"}}, queries: make(chan string, 1)}
+	c, err := connectFake(t, s, "pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	rows, err := c.Query(context.Background(), "SELECT brand, pages FROM printers WHERE id = $1", "SEC30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := <-s.queries; q != "SELECT brand, pages FROM printers WHERE id = $1" {
+		t.Errorf("consulta recibida %q", q)
+	}
+	if len(rows) != 1 || *rows[0][0] != "HP" || *rows[0][1] != "150000" {
+		t.Errorf("filas %v", rows)
+	}
+}
+
+func TestConnectSCRAMWrongPassword(t *testing.T) {
+	s := &fakeServer{t: t, password: "pencil", queries: make(chan string, 1)}
+	_, err := connectFake(t, s, "otra")
+	var pgErr *Error
+	if !errors.As(err, &pgErr) || pgErr.Code != "28P01" {
+		t.Errorf("error %v, se esperaba 28P01", err)
+	}
+}
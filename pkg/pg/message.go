@@ -0,0 +1,90 @@
+package pg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// message arma un mensaje del cliente: tipo + int32 largo + cuerpo
+type message struct {
+	buf []byte
+}
+
+func newMessage(typ byte) *message {
+	return &message{buf: []byte{typ, 0, 0, 0, 0}}
+}
+
+func (m *message) byte(b byte) *message {
+	m.buf = append(m.buf, b)
+	return m
+}
+
+func (m *message) int16(n int16) *message {
+	m.buf = binary.BigEndian.AppendUint16(m.buf, uint16(n))
+	return m
+}
+
+func (m *message) int32(n int32) *message {
+	m.buf = binary.BigEndian.AppendUint32(m.buf, uint32(n))
+	return m
+}
+
+func (m *message) cstring(s string) *message {
+	m.buf = append(m.buf, s...)
+	m.buf = append(m.buf, 0)
+	return m
+}
+
+func (m *message) bytes(b []byte) *message {
+	m.buf = append(m.buf, b...)
+	return m
+}
+
+// finish completa el largo (sin contar el byte de tipo)
+func (m *message) finish() []byte {
+	binary.BigEndian.PutUint32(m.buf[1:5], uint32(len(m.buf)-1))
+	return m.buf
+}
+
+// encodeParam convierte un parámetro a su representación de texto
+func encodeParam(arg interface{}) (string, bool, error) {
+	if arg == nil {
+		return "", true, nil
+	}
+	switch v := arg.(type) {
+	case string:
+		return v, false, nil
+	case []byte:
+		return string(v), false, nil
+	case bool:
+		return strconv.FormatBool(v), false, nil
+	case int:
+		return strconv.Itoa(v), false, nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), false, nil
+	case int64:
+		return strconv.FormatInt(v, 10), false, nil
+	case uint64:
+		return strconv.FormatUint(v, 10), false, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), false, nil
+	case time.Time:
+		if v.IsZero() {
+			return "", true, nil
+		}
+		return v.UTC().Format(time.RFC3339Nano), false, nil
+	}
+
+	// Punteros: nil → NULL, si no el valor apuntado
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", true, nil
+		}
+		return encodeParam(rv.Elem().Interface())
+	}
+	return "", false, fmt.Errorf("pg: tipo de parámetro %T no soportado", arg)
+}
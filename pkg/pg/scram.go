@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/internal/pbkdf2"
+)
+
+// maxSCRAMIterations acota las iteraciones que pide el servidor: PBKDF2 corre
+// en el agente y un servidor hostil o mal configurado podría pedir 2^31 y
+// colgar el sink (PostgreSQL usa 4096 por defecto)
+const maxSCRAMIterations = 1000000
+
+// scramClient implementa SCRAM-SHA-256 sin channel binding (RFC 5802 / RFC 7677)
+type scramClient struct {
+	password    string
+	nonce       string
+	firstBare   string
+	authMessage string
+	saltedPass  []byte
+}
+
+func newSCRAMClient(password string) *scramClient {
+	raw := make([]byte, 18)
+	rand.Read(raw)
+	return &scramClient{password: password, nonce: base64.RawStdEncoding.EncodeToString(raw)}
+}
+
+// clientFirst es el client-first-message (el usuario va en el StartupMessage)
+func (s *scramClient) clientFirst() string {
+	s.firstBare = "n=,r=" + s.nonce
+	return "n,," + s.firstBare
+}
+
+// clientFinal responde al server-first-message con la prueba
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce, saltB64, iterStr := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) || saltB64 == "" || iterStr == "" {
+		return "", errors.New("pg: server-first-message SCRAM inválido")
+	}
+	salt, err := base64.StdEncoding.DecodeString(saltB64)
+	if err != nil {
+		return "", errors.New("pg: salt SCRAM inválido")
+	}
+	iterations, err := strconv.Atoi(iterStr)
+	if err != nil || iterations < 1 {
+		return "", errors.New("pg: iteraciones SCRAM inválidas")
+	}
+	if iterations > maxSCRAMIterations {
+		return "", fmt.Errorf("pg: el servidor pide %d iteraciones SCRAM (máximo %d)", iterations, maxSCRAMIterations)
+	}
+
+	s.saltedPass = pbkdf2.Key([]byte(s.password), salt, iterations, sha256.Size)
+	clientKey := hmacSum(s.saltedPass, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+
+	withoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.firstBare + "," + serverFirst + "," + withoutProof
+	signature := hmacSum(storedKey[:], s.authMessage)
+
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ signature[i]
+	}
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verifyServer comprueba la firma del server-final-message
+func (s *scramClient) verifyServer(serverFinal string) bool {
+	verifier, err := base64.StdEncoding.DecodeString(scramAttributes(serverFinal)["v"])
+	if err != nil || s.saltedPass == nil {
+		return false
+	}
+	serverKey := hmacSum(s.saltedPass, "Server Key")
+	return hmac.Equal(verifier, hmacSum(serverKey, s.authMessage))
+}
+
+// scramAttributes separa "a=x,b=y"
+func scramAttributes(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(msg, ",") {
+		if len(part) > 2 && part[1] == '=' {
+			attrs[part[:1]] = part[2:]
+		}
+	}
+	return attrs
+}
+
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"strings"
+	"testing"
+)
+
+// Ejemplo de RFC 7677 (sección 3): usuario "user", contraseña "pencil"
+func TestSCRAMRFC7677(t *testing.T) {
+	s := &scramClient{password: "pencil", nonce: "rOprNGfwEbeRWgbNEkqO"}
+	s.firstBare = "n=user,r=" + s.nonce // PostgreSQL manda el usuario vacío; el RFC no
+
+	final, err := s.clientFinal("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+	if final != want {
+		t.Errorf("client-final-message:\n%s\nse esperaba\n%s", final, want)
+	}
+	if !s.verifyServer("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=") {
+		t.Error("no se aceptó la firma del servidor del RFC")
+	}
+	if s.verifyServer("v=AAAATRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=") {
+		t.Error("se aceptó una firma del servidor falsa")
+	}
+}
+
+func TestSCRAMRejectsServerFirst(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		serverFirst string
+		want        string
+	}{
+		{"nonce ajeno", "r=otro,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096", "inválido"},
+		{"sin salt", "r=abc123,i=4096", "inválido"},
+		{"salt inválido", "r=abc123,s=%%%,i=4096", "salt"},
+		{"iteraciones 0", "r=abc123,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=0", "iteraciones"},
+		{"iteraciones de más", "r=abc123,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=2147483647", "máximo"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &scramClient{password: "pencil", nonce: "abc"}
+			s.clientFirst()
+			_, err := s.clientFinal(tc.serverFirst)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, se esperaba uno con %q", err, tc.want)
+			}
+		})
+	}
+}
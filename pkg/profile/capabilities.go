@@ -0,0 +1,99 @@
+package profile
+
+import (
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// Columnas de Printer MIB que delatan capacidades del equipo (RFC 3805)
+const (
+	prtCoverDescription       = "1.3.6.1.2.1.43.6.1.1.2"   // prtCoverDescription
+	prtMediaPathType          = "1.3.6.1.2.1.43.13.4.1.9"  // prtMediaPathType
+	prtMediaPathDescription   = "1.3.6.1.2.1.43.13.4.1.10" // prtMediaPathDescription
+	prtChannelInformation     = "1.3.6.1.2.1.43.14.1.1.9"  // prtChannelInformation
+	prtInterpreterDescription = "1.3.6.1.2.1.43.15.1.1.5"  // prtInterpreterDescription
+	prtMarkerColorantValue    = "1.3.6.1.2.1.43.12.1.1.4"  // prtMarkerColorantValue
+)
+
+// Valores de prtMediaPathType con impresión a doble cara
+const (
+	mediaPathLongEdgeDuplex  = "3"
+	mediaPathShortEdgeDuplex = "4"
+)
+
+// processColorants son los colorantes que hacen a un equipo color
+var processColorants = map[string]bool{"cyan": true, "magenta": true, "yellow": true}
+
+// DetectCapabilities fija Duplex, Color, Scanner y Fax con lo que publica el
+// equipo: trayectos de papel dúplex, colorantes de proceso, descripciones de
+// cubiertas/canales/intérpretes y contadores de escaneo o fax con valor
+// results puede ser el WALK completo de Printer MIB o solo algunas tablas;
+// counters son los nombres normalizados con lectura (scan_pages, fax_pages,
+// color_pages, duplex_pages)
+// Solo enciende capacidades: lo detectado antes (perfil) no se apaga
+func DetectCapabilities(caps *CapabilityMap, results []snmp.WalkResult, counters []string) {
+	colorants := make(map[string]bool)
+
+	for _, result := range results {
+		oid := strings.TrimPrefix(result.OID, ".")
+		value := strings.ToLower(strings.TrimSpace(result.Value))
+		switch {
+		case strings.HasPrefix(oid, prtMediaPathType+"."):
+			if value == mediaPathLongEdgeDuplex || value == mediaPathShortEdgeDuplex {
+				caps.Duplex = true
+			}
+		case strings.HasPrefix(oid, prtMediaPathDescription+"."), strings.HasPrefix(oid, prtCoverDescription+"."):
+			if strings.Contains(value, "duplex") {
+				caps.Duplex = true
+			}
+			detectDeviceFunctions(caps, value)
+		case strings.HasPrefix(oid, prtChannelInformation+"."), strings.HasPrefix(oid, prtInterpreterDescription+"."):
+			detectDeviceFunctions(caps, value)
+		case strings.HasPrefix(oid, prtMarkerColorantValue+"."):
+			colorants[value] = true
+		}
+	}
+
+	for color := range processColorants {
+		if colorants[color] {
+			caps.Color = true
+		}
+	}
+	if hasColorMarker(ParseMarkers(results)) {
+		caps.Color = true
+	}
+
+	for _, name := range counters {
+		switch name {
+		case "scan_pages":
+			caps.Scanner = true
+		case "fax_pages":
+			caps.Fax = true
+		case "color_pages":
+			caps.Color = true
+		case "duplex_pages":
+			caps.Duplex = true
+		}
+	}
+}
+
+// detectDeviceFunctions enciende Scanner/Fax si una descripción los nombra
+func detectDeviceFunctions(caps *CapabilityMap, description string) {
+	if strings.Contains(description, "scan") {
+		caps.Scanner = true
+	}
+	if strings.Contains(description, "fax") {
+		caps.Fax = true
+	}
+}
+
+// hasColorMarker indica si algún motor de marcado tiene más de un colorante
+func hasColorMarker(rows []MarkerRow) bool {
+	for _, row := range rows {
+		if row.Colorants > 1 {
+			return true
+		}
+	}
+	return false
+}
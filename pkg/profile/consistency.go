@@ -64,20 +64,62 @@ func (cc *ConsistencyChecker) CheckConsistency(oid string) (bool, float64, *OIDM
 		LastValue:  values[len(values)-1],
 		MeanValue:  meanVal,
 		Consistent: isConsistent,
+		Samples:    len(values),
 	}
 
 	return isConsistent, meanVal, metadata, nil
 }
 
-// CheckMultipleOIDs valida consistencia de múltiples OIDs en paralelo
-func (cc *ConsistencyChecker) CheckMultipleOIDs(oids []string) map[string]*OIDMetadata {
+// CheckMultipleOIDs valida consistencia de múltiples OIDs en lote: cada
+// intento es un solo GET con todos los OIDs, no attempts×len(oids) requests
+// Retorna metadata de cada OID con al menos 2 lecturas numéricas; Consistent
+// indica si variaron dentro de la tolerancia y, con monotonic (contadores),
+// si nunca bajaron entre lecturas
+func (cc *ConsistencyChecker) CheckMultipleOIDs(oids []string, monotonic bool) map[string]*OIDMetadata {
 	results := make(map[string]*OIDMetadata)
+	if len(oids) == 0 {
+		return results
+	}
+
+	ctx := snmp.NewContext()
+	samples := make(map[string][]float64, len(oids))
+	for i := 0; i < cc.attempts; i++ {
+		if i > 0 {
+			time.Sleep(cc.interval)
+		}
+
+		values, err := cc.client.GetMultiple(oids, ctx)
+		if err != nil {
+			continue
+		}
+		for oid, value := range values {
+			if floatVal, ok := cc.parseToFloat(value); ok {
+				samples[oid] = append(samples[oid], floatVal)
+			}
+		}
+	}
+
+	for oid, values := range samples {
+		if len(values) < 2 {
+			continue
+		}
+
+		meanVal := cc.calculateMean(values)
+		consistent := cc.isValuesConsistent(values, meanVal)
+		if monotonic {
+			for i := 1; i < len(values); i++ {
+				if values[i] < values[i-1] {
+					consistent = false
+				}
+			}
+		}
 
-	for _, oid := range oids {
-		isConsistent, _, metadata, err := cc.CheckConsistency(oid)
-		if err == nil && isConsistent {
-			metadata.Consistent = true
-			results[oid] = metadata
+		results[oid] = &OIDMetadata{
+			OID:        oid,
+			LastValue:  values[len(values)-1],
+			MeanValue:  meanVal,
+			Consistent: consistent,
+			Samples:    len(values),
 		}
 	}
 
@@ -87,6 +129,9 @@ func (cc *ConsistencyChecker) CheckMultipleOIDs(oids []string) map[string]*OIDMe
 // parseToFloat intenta convertir un valor a float64
 func (cc *ConsistencyChecker) parseToFloat(value interface{}) (float64, bool) {
 	switch v := value.(type) {
+	case snmp.Value:
+		n, ok := v.Int64()
+		return float64(n), ok
 	case int:
 		return float64(v), true
 	case int32:
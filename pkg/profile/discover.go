@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
 	"github.com/asaavedra/agent-snmp/pkg/snmp"
 )
 
@@ -30,7 +31,7 @@ func (d *Discoverer) DiscoverProfile(ip, brand, model, serialNumber string) (*Pr
 		OIDFriendlyNames:  make(map[string]string),
 		Capabilities:      CapabilityMap{},
 		DiscoveredAt:      time.Now(),
-		SNMPVersion:       "2c",
+		SNMPVersion:       d.client.Version(), // la que respondió (ver DiscoveryConfig.V1Fallback)
 		DiscoveryAttempts: 1,
 	}
 
@@ -43,13 +44,17 @@ func (d *Discoverer) DiscoverProfile(ip, brand, model, serialNumber string) (*Pr
 	// PASO 3: Enriquecer con metadata y nombres legibles
 	d.enrichProfile(profile)
 
+	// PASO 3b: Validar que contadores y consumibles candidatos den lecturas estables
+	d.checkConsistency(profile)
+
 	// PASO 4: Generar mappings de contadores
 	if len(profile.OIDs[string(CatCounters)]) > 0 {
-		profile.CounterMappings = d.generateCounterMappings(brand, profile.OIDs[string(CatCounters)])
+		markers := ParseMarkers(allWalkResults["printer-mib"])
+		profile.CounterMappings = d.generateCounterMappings(brand, profile.OIDs[string(CatCounters)], markers)
 	}
 
 	// PASO 5: Detectar capacidades
-	d.detectCapabilities(profile)
+	d.detectCapabilities(profile, allWalkResults["printer-mib"])
 
 	return profile, nil
 }
@@ -67,6 +72,11 @@ func (d *Discoverer) walkStrategic() map[string][]snmp.WalkResult {
 		{"1.3.6.1.4.1.253", "enterprise-xerox"},
 		{"1.3.6.1.4.1.236", "enterprise-samsung"},
 		{"1.3.6.1.4.1.367", "enterprise-ricoh"},
+		{"1.3.6.1.4.1.641", "enterprise-lexmark"},
+		{"1.3.6.1.4.1.1248", "enterprise-epson"},
+		{"1.3.6.1.4.1.2385", "enterprise-sharp"},
+		{"1.3.6.1.4.1.1129", "enterprise-toshiba"},
+		{"1.3.6.1.4.1.18334", "enterprise-konica"},
 	}
 
 	ctx := snmp.NewContext()
@@ -117,14 +127,49 @@ func (d *Discoverer) classifyOIDs(profile *Profile, allResults map[string][]snmp
 	logDiscovery(profile, oidsByCategory)
 }
 
-// detectCapabilities detecta capacidades basadas en OIDs encontrados
-func (d *Discoverer) detectCapabilities(profile *Profile) {
+// checkConsistency registra en OIDMetadata si cada contador/consumible candidato
+// devuelve lecturas estables (los contadores, además, nunca deben bajar)
+func (d *Discoverer) checkConsistency(profile *Profile) {
+	checker := NewConsistencyChecker(d.client)
+
+	for _, category := range []OIDCategory{CatCounters, CatSupplies} {
+		results := checker.CheckMultipleOIDs(profile.OIDs[string(category)], category == CatCounters)
+
+		inconsistent := 0
+		for oid, result := range results {
+			metadata := profile.OIDMetadata[oid]
+			metadata.OID = oid
+			metadata.Consistent = result.Consistent
+			metadata.MeanValue = result.MeanValue
+			metadata.LastValue = result.LastValue
+			metadata.Samples = result.Samples
+			profile.OIDMetadata[oid] = metadata
+			if !result.Consistent {
+				inconsistent++
+			}
+		}
+		if inconsistent > 0 {
+			fmt.Println(i18n.T("log.profile_inconsistent", profile.PrinterID, inconsistent, len(results), category))
+		}
+	}
+}
+
+// detectCapabilities detecta capacidades basadas en OIDs encontrados y en lo
+// que publica Printer MIB (ver DetectCapabilities)
+func (d *Discoverer) detectCapabilities(profile *Profile, printerMIB []snmp.WalkResult) {
 	profile.Capabilities.Supplies = len(profile.OIDs[string(CatSupplies)]) > 0
 	profile.Capabilities.Counters = len(profile.OIDs[string(CatCounters)]) > 0
 	profile.Capabilities.Status = len(profile.OIDs[string(CatStatus)]) > 0
 	profile.Capabilities.Network = len(profile.OIDs[string(CatNetwork)]) > 0
-	profile.Capabilities.Color = true
-	profile.Capabilities.Duplex = true
+
+	// Contadores de escaneo/fax mapeados que tuvieron lectura en el discovery
+	var counters []string
+	for oid, name := range profile.CounterMappings {
+		if profile.OIDMetadata[oid].MeanValue > 0 {
+			counters = append(counters, name)
+		}
+	}
+	DetectCapabilities(&profile.Capabilities, printerMIB, counters)
 }
 
 // isUsefulOID determina si un OID tiene valor útil
@@ -218,24 +263,14 @@ func ClassifyOID(oid string) OIDCategory {
 }
 
 // generateCounterMappings genera mappings de OIDs a nombres de contadores
-func (d *Discoverer) generateCounterMappings(_ string, counterOIDs []string) map[string]string {
+// prtMarkerLifeCount se interpreta por fila de prtMarkerTable (ver MarkerCounterMappings):
+// sus índices son motores de marcado, no tipos de contador
+func (d *Discoverer) generateCounterMappings(_ string, counterOIDs []string, markers []MarkerRow) map[string]string {
 	mappings := make(map[string]string)
 
-	standardMappings := map[string]string{
-		"1.3.6.1.2.1.43.10.2.1.4.1.1": "total_pages",
-		"1.3.6.1.2.1.43.10.2.1.4.1.2": "mono_pages",
-		"1.3.6.1.2.1.43.10.2.1.4.1.3": "color_pages",
-		"1.3.6.1.2.1.43.10.2.1.4.1.4": "scan_pages",
-		"1.3.6.1.2.1.43.10.2.1.4.1.5": "copy_pages",
-		"1.3.6.1.2.1.43.10.2.1.4.1.6": "fax_pages",
-	}
-
-	for _, oid := range counterOIDs {
-		normalizedOID := strings.TrimPrefix(oid, ".")
-		if mapping, ok := standardMappings[oid]; ok {
-			mappings[oid] = mapping
-		} else if mapping, ok := standardMappings[normalizedOID]; ok {
-			mappings[normalizedOID] = mapping
+	for oid, name := range MarkerCounterMappings(markers) {
+		if contains(counterOIDs, oid) || contains(counterOIDs, "."+oid) {
+			mappings[oid] = name
 		}
 	}
 
@@ -2,27 +2,40 @@ package profile
 
 import (
 	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/mib"
 )
 
 // FriendlyNameResolver genera nombres legibles para OIDs
+// Los nombres salen de los MIBs cargados (embebidos + mibs.paths)
 type FriendlyNameResolver struct {
-	knownOIDs map[string]string // OID -> FriendlyName
+	tree   *mib.Tree
+	custom map[string]string // OID -> FriendlyName (AddCustomMapping)
 }
 
-// NewFriendlyNameResolver crea un nuevo resolver
+// NewFriendlyNameResolver crea un resolver sobre el árbol MIB del proceso
 func NewFriendlyNameResolver() *FriendlyNameResolver {
 	return &FriendlyNameResolver{
-		knownOIDs: initializeKnownOIDs(),
+		tree:   mib.Default(),
+		custom: make(map[string]string),
 	}
 }
 
 // GetFriendlyName retorna un nombre legible para un OID
+// ("1.3.6.1.2.1.43.10.2.1.4.1.1" → "prtMarkerLifeCount.1.1")
 func (fnr *FriendlyNameResolver) GetFriendlyName(oid string) string {
-	// Buscar en base de datos conocidos
-	if name, ok := fnr.knownOIDs[oid]; ok {
+	if name, ok := fnr.custom[strings.TrimPrefix(oid, ".")]; ok {
 		return name
 	}
 
+	// Nombre simbólico del MIB (las raíces de SNMPv2-SMI no cuentan: "mib-2.43..." no aporta)
+	if node, suffix := fnr.tree.Lookup(oid); node != nil && node.Module != "" {
+		if suffix == "" {
+			return node.Name
+		}
+		return node.Name + "." + suffix
+	}
+
 	// Generar automático basado en patrones
 	return fnr.generateFriendlyName(oid)
 }
@@ -36,22 +49,9 @@ func (fnr *FriendlyNameResolver) generateFriendlyName(oid string) string {
 	}
 
 	// Patrones RFC 3805
-	if strings.Contains(oid, ".43.10.2.1.4") {
-		// Contadores de páginas
-		if strings.HasSuffix(oid, ".1") {
-			return "Total Pages"
-		} else if strings.HasSuffix(oid, ".2") {
-			return "Monochrome Pages"
-		} else if strings.HasSuffix(oid, ".3") {
-			return "Color Pages"
-		} else if strings.HasSuffix(oid, ".4") {
-			return "Scan Pages"
-		} else if strings.HasSuffix(oid, ".5") {
-			return "Copy Pages"
-		} else if strings.HasSuffix(oid, ".6") {
-			return "Fax Pages"
-		}
-		return "Page Counter"
+	if IsMarkerLifeCount(oid) {
+		// Contador de vida de un motor de marcado (el índice es el marcador, no un tipo de página)
+		return "Marker Life Count " + parts[len(parts)-1]
 	}
 
 	// Consumibles RFC 3805
@@ -118,63 +118,9 @@ func (fnr *FriendlyNameResolver) generateFriendlyName(oid string) string {
 	return "Unknown OID"
 }
 
-// initializeKnownOIDs crea la base de datos de OIDs conocidos
-func initializeKnownOIDs() map[string]string {
-	return map[string]string{
-		// RFC 3805 - Printer MIB
-		"1.3.6.1.2.1.43.10.2.1.4.1.1": "Total Page Count",
-		"1.3.6.1.2.1.43.10.2.1.4.1.2": "Monochrome Page Count",
-		"1.3.6.1.2.1.43.10.2.1.4.1.3": "Color Page Count",
-		"1.3.6.1.2.1.43.10.2.1.4.1.4": "Scan Page Count",
-		"1.3.6.1.2.1.43.10.2.1.4.1.5": "Copy Page Count",
-		"1.3.6.1.2.1.43.10.2.1.4.1.6": "Fax Page Count",
-		"1.3.6.1.2.1.43.11.1.1.6.1.1": "Supply Toner Level",
-		"1.3.6.1.2.1.43.11.1.1.8.1.1": "Supply Max Capacity",
-		"1.3.6.1.2.1.43.5.1.1.2.1":    "Printer State",
-		"1.3.6.1.2.1.43.13.0":         "Printer Alerts",
-
-		// System MIB
-		"1.3.6.1.2.1.1.1.0": "System Description",
-		"1.3.6.1.2.1.1.2.0": "System OID",
-		"1.3.6.1.2.1.1.3.0": "System Uptime",
-		"1.3.6.1.2.1.1.4.0": "System Contact",
-		"1.3.6.1.2.1.1.5.0": "System Name (Hostname)",
-		"1.3.6.1.2.1.1.6.0": "System Location",
-
-		// Host Resources MIB
-		"1.3.6.1.2.1.25.3.2.1.3.1": "Printer Model",
-		"1.3.6.1.2.1.25.3.2.1.5.1": "Device Status",
-
-		// Network Interface MIB
-		"1.3.6.1.2.1.2.1.2.1":   "Interface 1 Description",
-		"1.3.6.1.2.1.2.2.1.2.1": "Interface 1 Name",
-		"1.3.6.1.2.1.2.2.1.6.1": "Interface 1 MAC Address",
-
-		// IP MIB
-		"1.3.6.1.2.1.4.20.1.1": "IP Address",
-
-		// HP Enterprise OIDs
-		".1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.1":   "HP Total Impressions",
-		".1.3.6.1.4.1.11.2.3.9.4.2.1.4.1.2":   "HP Color Impressions",
-		".1.3.6.1.4.1.11.2.3.9.4.3.1.1.8.1.1": "HP Toner Cartridge",
-
-		// Xerox Enterprise OIDs
-		"1.3.6.1.4.1.253.8.53.3.2.1.5.1.1.1": "Xerox Total Pages",
-		"1.3.6.1.4.1.253.8.53.13.0":          "Xerox Supply Status",
-
-		// Samsung Enterprise OIDs
-		"1.3.6.1.4.1.236.11.5.1.1.4.1.1":  "Samsung Total Pages",
-		"1.3.6.1.4.1.236.11.5.11.1.1.6.1": "Samsung Toner Level",
-
-		// Ricoh Enterprise OIDs
-		"1.3.6.1.4.1.367.3.2.1.5.1.1.1": "Ricoh Total Pages",
-		"1.3.6.1.4.1.367.3.2.1.5.1.1.2": "Ricoh Color Pages",
-	}
-}
-
 // AddCustomMapping permite agregar mappings personalizados
 func (fnr *FriendlyNameResolver) AddCustomMapping(oid, friendlyName string) {
-	fnr.knownOIDs[oid] = friendlyName
+	fnr.custom[strings.TrimPrefix(oid, ".")] = friendlyName
 }
 
 // DetectOIDType intenta determinar el tipo de OID basado en su nombre
@@ -192,7 +138,7 @@ func (fnr *FriendlyNameResolver) DetectOIDType(friendlyName string) string {
 	if strings.Contains(nameUpper, "TONER") || strings.Contains(nameUpper, "SUPPLY") ||
 		strings.Contains(nameUpper, "DRUM") || strings.Contains(nameUpper, "FUSER") ||
 		strings.Contains(nameUpper, "ROLLER") || strings.Contains(nameUpper, "PAD") ||
-		strings.Contains(nameUpper, "INK") {
+		strings.Contains(nameUpper, "INK") || strings.Contains(nameUpper, "SUPPLIES") {
 		return "supplies"
 	}
 
@@ -212,8 +158,9 @@ func (fnr *FriendlyNameResolver) DetectOIDType(friendlyName string) string {
 
 	// Sistema
 	if strings.Contains(nameUpper, "HOSTNAME") || strings.Contains(nameUpper, "UPTIME") ||
-		strings.Contains(nameUpper, "SYSTEM") || strings.Contains(nameUpper, "DESCRIPTION") ||
-		strings.Contains(nameUpper, "LOCATION") || strings.Contains(nameUpper, "CONTACT") {
+		strings.Contains(nameUpper, "SYSTEM") || strings.Contains(nameUpper, "DESCR") ||
+		strings.Contains(nameUpper, "SYSNAME") || strings.Contains(nameUpper, "LOCATION") ||
+		strings.Contains(nameUpper, "CONTACT") {
 		return "system"
 	}
 
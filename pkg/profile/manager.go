@@ -9,13 +9,24 @@ import (
 	"sync"
 	"time"
 
+	"github.com/asaavedra/agent-snmp/pkg/detector"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
 	"github.com/asaavedra/agent-snmp/pkg/snmp"
 )
 
+// indexFileName es el archivo con el índice IP → PrinterID
+// Empieza con "_" para que LoadAll no lo confunda con un perfil
+const indexFileName = "_index.json"
+
 // Manager maneja la persistencia y carga de perfiles
+// Los perfiles se guardan por PrinterID canónico (MAC/serial), no por IP,
+// para sobrevivir a cambios de DHCP. El índice IP → ID permite encontrarlos
+// antes de conocer el ID del dispositivo.
 type Manager struct {
 	profileDir string
 	cache      map[string]*Profile
+	index      map[string]string // IP → PrinterID
+	store      ProfileStore      // plantillas compartidas entre agentes (nil = solo local)
 	mu         sync.RWMutex
 }
 
@@ -26,10 +37,181 @@ func NewManager(profileDir string) (*Manager, error) {
 		return nil, fmt.Errorf("error creando directorio de perfiles: %w", err)
 	}
 
-	return &Manager{
+	m := &Manager{
 		profileDir: profileDir,
 		cache:      make(map[string]*Profile),
-	}, nil
+		index:      make(map[string]string),
+	}
+
+	// Cargar índice existente (si no existe se reconstruye en LoadAll)
+	if data, err := os.ReadFile(filepath.Join(profileDir, indexFileName)); err == nil {
+		if err := json.Unmarshal(data, &m.index); err != nil {
+			fmt.Println(i18n.T("log.profile_index_corrupt", err))
+			m.index = make(map[string]string)
+		}
+	}
+
+	return m, nil
+}
+
+// Lookup busca el perfil de una impresora por su ID canónico y, si no existe,
+// por su IP usando el índice. Si el perfil encontrado por IP es un perfil
+// antiguo guardado por IP, se migra al ID canónico.
+// Esa migración no puede hacerse al arrancar: el ID canónico (MAC/serial) recién
+// se conoce al consultar el equipo, así que ocurre en su primer poll. Los
+// estados sí se migran al arrancar (ver collector.StateManager.MigrateIndex)
+func (m *Manager) Lookup(printerID, ip string) *Profile {
+	if printerID != "" {
+		if p := m.GetOrDiscover(printerID); p != nil {
+			return p
+		}
+	}
+
+	m.mu.RLock()
+	indexedID, ok := m.index[ip]
+	m.mu.RUnlock()
+
+	if !ok {
+		// Perfil antiguo sin índice: el archivo se llama como la IP
+		indexedID = ip
+	}
+
+	p := m.GetOrDiscover(indexedID)
+	if p == nil {
+		return nil
+	}
+
+	if printerID != "" && p.PrinterID != printerID {
+		// Solo migrar perfiles antiguos guardados por IP. Si el perfil tiene
+		// otro ID canónico, la IP fue reasignada a otra impresora.
+		if p.PrinterID != p.IP {
+			return nil
+		}
+		if err := m.Rekey(p.PrinterID, printerID); err != nil {
+			fmt.Println(i18n.T("log.profile_migrate_error", p.PrinterID, printerID, err))
+		}
+	}
+
+	return p
+}
+
+// Rekey cambia el ID con el que se guarda un perfil (ej: IP → MAC)
+// Elimina el archivo antiguo y actualiza el índice
+func (m *Manager) Rekey(oldID, newID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.cache[oldID]
+	if !exists {
+		return fmt.Errorf("profile no encontrado: %s", oldID)
+	}
+
+	p.PrinterID = newID
+	if err := m.saveToDisk(p); err != nil {
+		p.PrinterID = oldID
+		return err
+	}
+
+	delete(m.cache, oldID)
+	m.cache[newID] = p
+
+	oldPath := filepath.Join(m.profileDir, m.getFileName(oldID))
+	if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+		fmt.Println(i18n.T("log.profile_remove_error", oldPath, err))
+	}
+
+	m.index[p.IP] = newID
+	fmt.Println(i18n.T("log.profile_migrated", oldID, newID))
+	return m.saveIndex()
+}
+
+// Forget elimina el perfil de una impresora (por ID canónico o IP) para que
+// el próximo poll lo vuelva a descubrir. Retorna false si no había perfil
+func (m *Manager) Forget(target string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	printerID := target
+	if id, ok := m.index[target]; ok {
+		printerID = id
+	}
+
+	path := filepath.Join(m.profileDir, m.getFileName(printerID))
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("error eliminando perfil: %w", err)
+	}
+	found := err == nil
+
+	delete(m.cache, printerID)
+	for ip, id := range m.index {
+		if id == printerID {
+			delete(m.index, ip)
+			found = true
+		}
+	}
+	return found, m.saveIndex()
+}
+
+// Archive copia el perfil de una impresora dada de baja a dir/profile.json y
+// lo olvida (ver Forget): si vuelve a aparecer se descubre de nuevo
+// Retorna false si no había perfil
+func (m *Manager) Archive(printerID, dir string) (bool, error) {
+	m.mu.RLock()
+	data, err := os.ReadFile(filepath.Join(m.profileDir, m.getFileName(printerID)))
+	m.mu.RUnlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m.Forget(printerID)
+		}
+		return false, fmt.Errorf("error leyendo perfil: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("error creando archivo de perfil: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "profile.json"), data, 0644); err != nil {
+		return false, fmt.Errorf("error archivando perfil: %w", err)
+	}
+	return m.Forget(printerID)
+}
+
+// Relocate registra que la impresora printerID respondió en ip: actualiza el
+// perfil y el índice (la IP anterior deja de apuntarle). Retorna la IP
+// anterior, o "" si no cambió o no hay perfil
+func (m *Manager) Relocate(printerID, ip string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.cache[printerID]
+	if !exists || ip == "" || p.IP == ip {
+		return "", nil
+	}
+
+	oldIP := p.IP
+	p.IP = ip
+	if err := m.saveToDisk(p); err != nil {
+		p.IP = oldIP
+		return "", err
+	}
+
+	if m.index[oldIP] == printerID {
+		delete(m.index, oldIP)
+	}
+	m.index[ip] = printerID
+	return oldIP, m.saveIndex()
+}
+
+// IPIndex retorna una copia del índice IP → PrinterID
+func (m *Manager) IPIndex() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	index := make(map[string]string, len(m.index))
+	for ip, id := range m.index {
+		index[ip] = id
+	}
+	return index
 }
 
 // GetOrDiscover carga un perfil existente o retorna nil para discovery
@@ -70,14 +252,39 @@ func (m *Manager) SaveProfile(profile *Profile) error {
 	m.cache[profile.PrinterID] = profile
 
 	// Guardar en disco
-	return m.saveToDisk(profile)
+	if err := m.saveToDisk(profile); err != nil {
+		return err
+	}
+
+	// Actualizar índice IP → ID
+	if profile.IP != "" && m.index[profile.IP] != profile.PrinterID {
+		m.index[profile.IP] = profile.PrinterID
+		return m.saveIndex()
+	}
+
+	return nil
 }
 
 // DiscoverAndSave ejecuta discovery de un nuevo dispositivo y guarda el perfil
-func (m *Manager) DiscoverAndSave(client *snmp.SNMPClient, ip, brand, model, serialNumber string) (*Profile, error) {
+// Si hay una plantilla importada para la marca y el modelo, el perfil sale
+// de ella y se evita el WALK
+func (m *Manager) DiscoverAndSave(client *snmp.SNMPClient, printerID, ip, brand, model, serialNumber string) (*Profile, error) {
+	if t := m.FindTemplate(brand, model); t != nil {
+		profile := t.Instantiate(printerID, ip)
+		profile.SNMPVersion = client.Version() // la de este equipo, no la de la plantilla
+		fmt.Println(i18n.T("log.template_applied", ip, t.Key()))
+		if err := m.SaveProfile(profile); err != nil {
+			return profile, fmt.Errorf("failed to save profile: %w", err)
+		}
+		return profile, nil
+	}
+
 	// Ejecutar discovery
 	discoverer := NewDiscoverer(client)
 	profile, err := discoverer.DiscoverProfile(ip, brand, model, serialNumber)
+	if err == nil && printerID != "" {
+		profile.PrinterID = printerID
+	}
 	if err != nil {
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
@@ -86,10 +293,82 @@ func (m *Manager) DiscoverAndSave(client *snmp.SNMPClient, ip, brand, model, ser
 	if err := m.SaveProfile(profile); err != nil {
 		return profile, fmt.Errorf("failed to save profile: %w", err)
 	}
+	m.publishTemplate(profile)
 
 	return profile, nil
 }
 
+// ExportTemplate genera la plantilla de modelo del perfil de una impresora
+// (por ID canónico o IP)
+func (m *Manager) ExportTemplate(target string) (*Template, error) {
+	m.mu.RLock()
+	printerID, ok := m.index[target]
+	m.mu.RUnlock()
+	if !ok {
+		printerID = target
+	}
+
+	p := m.GetOrDiscover(printerID)
+	if p == nil {
+		return nil, fmt.Errorf("profile no encontrado: %s", target)
+	}
+	return NewTemplate(p)
+}
+
+// ImportTemplate guarda una plantilla para que los próximos equipos de ese
+// modelo no necesiten discovery. Reemplaza la anterior del mismo modelo
+func (m *Manager) ImportTemplate(t *Template) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	// Las plantillas de la comunidad se limpian igual que las exportadas
+	t.sanitize("")
+
+	path := filepath.Join(m.templateDir(), t.Key()+".json")
+	return path, WriteTemplate(path, t)
+}
+
+// FindTemplate retorna la plantilla de marca y modelo: la importada en disco
+// o, si no hay, la del profile store remoto (nil si ninguno la tiene)
+func (m *Manager) FindTemplate(brand, model string) *Template {
+	if brand == "" || model == "" {
+		return nil
+	}
+	t, err := LoadTemplate(filepath.Join(m.templateDir(), TemplateKey(brand, model)+".json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Println(i18n.T("log.template_ignored", brand, model, err))
+		}
+		return m.remoteTemplate(brand, model)
+	}
+	return t
+}
+
+// Templates retorna las plantillas importadas
+func (m *Manager) Templates() ([]*Template, error) {
+	entries, err := os.ReadDir(m.templateDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error leyendo directorio de plantillas: %w", err)
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		t, err := LoadTemplate(filepath.Join(m.templateDir(), entry.Name()))
+		if err != nil {
+			fmt.Println(i18n.T("log.template_load_error", err))
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
 // TODO: Implementar validación persistente cuando sea necesario
 // UpdateValidation actualiza validez del perfil después de polling
 func (m *Manager) UpdateValidation(printerID string, success bool, err string) error {
@@ -121,6 +400,89 @@ func (m *Manager) UpdateValidation(printerID string, success bool, err string) e
 	return m.saveToDisk(p)
 }
 
+// RecordBrandFeedback registra si los OIDs del fabricante respondieron en este poll
+// y recalibra la confianza de la marca partiendo de base (confianza del sysDescr)
+// Si brand difiere de la marca del perfil, la evidencia contradijo la detección:
+// se corrige la marca, se guarda la anterior y la evidencia se reinicia
+func (m *Manager) RecordBrandFeedback(printerID, brand string, hit bool, base float64) (BrandFeedback, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.cache[printerID]
+	if !exists {
+		return BrandFeedback{}, fmt.Errorf("profile no encontrado: %s", printerID)
+	}
+
+	fb := &p.BrandFeedback
+	if p.Brand == "" {
+		p.Brand = brand
+	}
+	if brand != "" && brand != p.Brand {
+		fb.Contradictions++
+		fb.CorrectedFrom = p.Brand
+		fb.VendorHits = 0
+		fb.VendorMisses = 0
+		p.Brand = brand
+	}
+
+	if hit {
+		fb.VendorHits++
+	} else {
+		fb.VendorMisses++
+	}
+	fb.Confidence = detector.CalibrateConfidence(base, fb.VendorHits, fb.VendorMisses)
+	fb.UpdatedAt = time.Now()
+
+	return *fb, m.saveToDisk(p)
+}
+
+// RecordCollection registra el resultado de un poll completo en el circuit
+// breaker del perfil: threshold fallos seguidos lo abren por cycles ciclos
+// y un poll exitoso reinicia la cuenta (threshold <= 0 = breaker deshabilitado)
+func (m *Manager) RecordCollection(printerID string, failed bool, threshold, cycles int) (Breaker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.cache[printerID]
+	if !exists {
+		return Breaker{}, fmt.Errorf("profile no encontrado: %s", printerID)
+	}
+
+	b := &p.Breaker
+	if !failed {
+		if b.Failures == 0 {
+			return *b, nil // sin cambios: no reescribir el perfil en cada poll
+		}
+		b.Failures = 0
+		return *b, m.saveToDisk(p)
+	}
+
+	b.Failures++
+	if threshold > 0 && cycles > 0 && b.Failures >= threshold {
+		b.Failures = 0
+		b.OpenCycles = cycles
+		b.OpenedAt = time.Now()
+		b.Trips++
+	}
+	return *b, m.saveToDisk(p)
+}
+
+// SkipCollection descuenta un ciclo del breaker abierto; al llegar a 0 el
+// siguiente poll vuelve a ser completo
+func (m *Manager) SkipCollection(printerID string) (Breaker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.cache[printerID]
+	if !exists {
+		return Breaker{}, fmt.Errorf("profile no encontrado: %s", printerID)
+	}
+	if p.Breaker.OpenCycles > 0 {
+		p.Breaker.OpenCycles--
+	}
+	return p.Breaker, m.saveToDisk(p)
+}
+
 // TODO: Implementar redescubrimiento automático cuando sea necesario
 // NeedsRediscovery verifica si el perfil necesita ser redescubierto
 func (m *Manager) NeedsRediscovery(printerID string) bool {
@@ -165,7 +527,7 @@ func (m *Manager) LoadAll() error {
 			continue
 		}
 
-		if filepath.Ext(entry.Name()) != ".json" {
+		if filepath.Ext(entry.Name()) != ".json" || strings.HasPrefix(entry.Name(), "_") {
 			continue
 		}
 
@@ -183,9 +545,16 @@ func (m *Manager) LoadAll() error {
 		}
 
 		m.cache[p.PrinterID] = &p
+
+		// Reconstruir índice para perfiles que no estaban indexados
+		if p.IP != "" {
+			if _, ok := m.index[p.IP]; !ok {
+				m.index[p.IP] = p.PrinterID
+			}
+		}
 	}
 
-	return nil
+	return m.saveIndex()
 }
 
 // --- Métodos privados ---
@@ -222,6 +591,24 @@ func (m *Manager) saveToDisk(p *Profile) error {
 	return nil
 }
 
+// saveIndex persiste el índice IP → PrinterID (requiere lock tomado)
+func (m *Manager) saveIndex() error {
+	data, err := json.MarshalIndent(m.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando índice: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(m.profileDir, indexFileName), data, 0644); err != nil {
+		return fmt.Errorf("error escribiendo índice: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) templateDir() string {
+	return filepath.Join(m.profileDir, templateDirName)
+}
+
 func (m *Manager) getFileName(printerID string) string {
 	// Reemplazar caracteres especiales para nombre de archivo seguro
 	safeID := printerID
@@ -0,0 +1,204 @@
+package profile
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// prtMarkerEntry es la fila de prtMarkerTable (RFC 3805)
+// Las instancias son <columna>.<hrDeviceIndex>.<prtMarkerIndex>: cada fila es
+// un motor de marcado del equipo, no un tipo de contador
+const prtMarkerEntry = "1.3.6.1.2.1.43.10.2.1"
+
+// Columnas de prtMarkerTable que se interpretan
+const (
+	markerColCounterUnit      = 3 // prtMarkerCounterUnit
+	markerColLifeCount        = 4 // prtMarkerLifeCount
+	markerColPowerOnCount     = 5 // prtMarkerPowerOnCount
+	markerColProcessColorants = 6 // prtMarkerProcessColorants
+)
+
+// Unidades de prtMarkerCounterUnit que cuentan páginas
+const (
+	MarkerUnitImpressions = 7
+	MarkerUnitSheets      = 8
+)
+
+// MarkerRow es una fila de prtMarkerTable
+type MarkerRow struct {
+	Device       int    // hrDeviceIndex
+	Marker       int    // prtMarkerIndex
+	LifeCountOID string // prtMarkerLifeCount.<device>.<marker>
+	LifeCount    int64
+	PowerOnCount int64
+	CounterUnit  int // 7 impresiones, 8 hojas, 3/4/16/17 longitudes (0 = no reportado)
+	Colorants    int // prtMarkerProcessColorants: 1 = monocromo (0 = no reportado)
+}
+
+// CountsPages indica si el contador de la fila está en páginas
+// Sin prtMarkerCounterUnit se asume que sí (muchos equipos no lo publican)
+func (r MarkerRow) CountsPages() bool {
+	return r.CounterUnit == 0 || r.CounterUnit == MarkerUnitImpressions || r.CounterUnit == MarkerUnitSheets
+}
+
+// ParseMarkers arma las filas de prtMarkerTable a partir de un WALK (de 43.10,
+// 43.10.2 o del árbol completo de Printer MIB); las filas salen ordenadas por índice
+func ParseMarkers(results []snmp.WalkResult) []MarkerRow {
+	rows := make(map[[2]int]*MarkerRow)
+	var order [][2]int
+
+	for _, result := range results {
+		oid := strings.TrimPrefix(result.OID, ".")
+		rest, ok := strings.CutPrefix(oid, prtMarkerEntry+".")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(rest, ".")
+		if len(parts) != 3 {
+			continue
+		}
+		column, err1 := strconv.Atoi(parts[0])
+		device, err2 := strconv.Atoi(parts[1])
+		marker, err3 := strconv.Atoi(parts[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		value, ok := result.Typed.Int64()
+		if !ok {
+			value, _ = strconv.ParseInt(strings.TrimSpace(result.Value), 10, 64)
+		}
+
+		key := [2]int{device, marker}
+		row := rows[key]
+		if row == nil {
+			row = &MarkerRow{Device: device, Marker: marker}
+			rows[key] = row
+			order = append(order, key)
+		}
+		switch column {
+		case markerColCounterUnit:
+			row.CounterUnit = int(value)
+		case markerColLifeCount:
+			row.LifeCountOID = oid
+			row.LifeCount = value
+		case markerColPowerOnCount:
+			row.PowerOnCount = value
+		case markerColProcessColorants:
+			row.Colorants = int(value)
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if order[i][0] != order[j][0] {
+			return order[i][0] < order[j][0]
+		}
+		return order[i][1] < order[j][1]
+	})
+	out := make([]MarkerRow, 0, len(order))
+	for _, key := range order {
+		out = append(out, *rows[key])
+	}
+	return out
+}
+
+// pageMarkers son las filas con un contador de vida en páginas
+func pageMarkers(rows []MarkerRow) []MarkerRow {
+	var out []MarkerRow
+	for _, row := range rows {
+		if row.LifeCountOID != "" && row.LifeCount > 0 && row.CountsPages() {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// MarkerCounters deduce contadores normalizados de prtMarkerTable:
+// total_pages es la suma de los motores que cuentan páginas; mono_pages y
+// color_pages solo salen de prtMarkerProcessColorants (un equipo con un único
+// motor monocromo, o motores mono y color separados). Nunca deduce escaneo,
+// copia ni fax: Printer MIB no los publica
+func MarkerCounters(rows []MarkerRow) map[string]int64 {
+	markers := pageMarkers(rows)
+	if len(markers) == 0 {
+		return nil
+	}
+
+	counters := make(map[string]int64)
+	var total, mono, color int64
+	var hasMono, hasColor, unknown bool
+	for _, row := range markers {
+		total += row.LifeCount
+		switch {
+		case row.Colorants == 1:
+			mono += row.LifeCount
+			hasMono = true
+		case row.Colorants > 1:
+			color += row.LifeCount
+			hasColor = true
+		default:
+			unknown = true
+		}
+	}
+	counters["total_pages"] = total
+
+	// Un motor de color también imprime en B/N: su contador no separa mono de color
+	if hasMono && !unknown {
+		counters["mono_pages"] = mono
+	}
+	if hasMono && hasColor && !unknown {
+		counters["color_pages"] = color
+	}
+	return counters
+}
+
+// MarkerCounterMappings es el mapeo OID → contador que puede fijarse en un perfil:
+// un solo motor es total_pages; dos motores mono y color separados son
+// mono_pages y color_pages (el total lo suma el collector)
+func MarkerCounterMappings(rows []MarkerRow) map[string]string {
+	markers := pageMarkers(rows)
+	mappings := make(map[string]string)
+
+	switch {
+	case len(markers) == 1:
+		mappings[markers[0].LifeCountOID] = "total_pages"
+	case len(markers) == 2 && markers[0].Colorants > 0 && markers[1].Colorants > 0 &&
+		(markers[0].Colorants == 1) != (markers[1].Colorants == 1):
+		for _, row := range markers {
+			if row.Colorants == 1 {
+				mappings[row.LifeCountOID] = "mono_pages"
+			} else {
+				mappings[row.LifeCountOID] = "color_pages"
+			}
+		}
+	}
+	return mappings
+}
+
+// IsMarkerLifeCount indica si el OID es una instancia de prtMarkerLifeCount
+func IsMarkerLifeCount(oid string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(oid, "."), prtMarkerEntry+".4.")
+}
+
+// IsMarkerOID indica si el OID pertenece a prtMarkerTable
+func IsMarkerOID(oid string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(oid, "."), prtMarkerEntry+".")
+}
+
+// legacyMarkerMappings son los mapeos que generaban perfiles antiguos tomando
+// los índices de marcador de prtMarkerLifeCount como tipos de contador
+var legacyMarkerMappings = map[string]string{
+	"1.3.6.1.2.1.43.10.2.1.4.1.2": "mono_pages",
+	"1.3.6.1.2.1.43.10.2.1.4.1.3": "color_pages",
+	"1.3.6.1.2.1.43.10.2.1.4.1.4": "scan_pages",
+	"1.3.6.1.2.1.43.10.2.1.4.1.5": "copy_pages",
+	"1.3.6.1.2.1.43.10.2.1.4.1.6": "fax_pages",
+}
+
+// IsLegacyMarkerMapping indica si un mapeo del perfil es uno de esos mapeos
+// erróneos (el collector los ignora y usa las filas de prtMarkerTable)
+func IsLegacyMarkerMapping(oid, name string) bool {
+	return legacyMarkerMappings[strings.TrimPrefix(oid, ".")] == name
+}
@@ -0,0 +1,98 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// storeTimeout limita cada consulta al store remoto durante el discovery
+const storeTimeout = 15 * time.Second
+
+// ProfileStore guarda plantillas de modelo fuera del agente (API HTTP, bucket
+// S3) para que agentes de distintos sitios reutilicen lo que otro descubrió
+// Las claves son Template.Key() ("xerox_xerox-altalink-c8055")
+type ProfileStore interface {
+	// GetTemplate retorna nil, nil si el store no tiene la plantilla
+	GetTemplate(ctx context.Context, key string) (*Template, error)
+	// PutTemplate publica (o reemplaza) la plantilla
+	PutTemplate(ctx context.Context, t *Template) error
+	// ListTemplates retorna las claves disponibles
+	ListTemplates(ctx context.Context) ([]string, error)
+}
+
+// SetStore configura el store remoto compartido (nil = solo disco local)
+func (m *Manager) SetStore(store ProfileStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// remoteTemplate busca la plantilla en el store remoto y la deja en disco
+// para los próximos equipos del mismo modelo
+func (m *Manager) remoteTemplate(brand, model string) *Template {
+	m.mu.RLock()
+	store := m.store
+	m.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	key := TemplateKey(brand, model)
+	t, err := store.GetTemplate(ctx, key)
+	if err != nil {
+		fmt.Println(i18n.T("log.profile_store_warning", key, err))
+		return nil
+	}
+	if t == nil {
+		return nil
+	}
+	if err := t.Validate(); err != nil {
+		fmt.Println(i18n.T("log.profile_store_warning", key, err))
+		return nil
+	}
+	if _, err := m.ImportTemplate(t); err != nil {
+		fmt.Println(i18n.T("log.template_save_error", key, err))
+	}
+	fmt.Println(i18n.T("log.template_downloaded", key))
+	return t
+}
+
+// publishTemplate comparte el perfil recién descubierto como plantilla de su
+// modelo. No pisa una plantilla que otro agente ya publicó
+func (m *Manager) publishTemplate(p *Profile) {
+	m.mu.RLock()
+	store := m.store
+	m.mu.RUnlock()
+	if store == nil || p.Model == "" || !p.Capabilities.Counters {
+		return // sin contadores el discovery salió incompleto: no vale como plantilla
+	}
+
+	t, err := NewTemplate(p)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	existing, err := store.GetTemplate(ctx, t.Key())
+	if err == nil && existing == nil {
+		err = store.PutTemplate(ctx, t)
+		if err == nil {
+			fmt.Println(i18n.T("log.template_published", t.Key()))
+		}
+	}
+	if err != nil {
+		fmt.Println(i18n.T("log.profile_store_warning", t.Key(), err))
+		return
+	}
+	if _, err := m.ImportTemplate(t); err != nil {
+		fmt.Println(i18n.T("log.template_save_error", t.Key(), err))
+	}
+}
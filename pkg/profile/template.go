@@ -0,0 +1,238 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// TemplateKind identifica los archivos de plantilla al importar
+	TemplateKind = "printsnmp-profile-template"
+	// templateSchemaVersion es la versión del formato de plantilla
+	templateSchemaVersion = "1.0"
+	// templateDirName es el subdirectorio de profiles/ con las plantillas
+	templateDirName = "templates"
+)
+
+// Template es un Profile reutilizable por modelo: los OIDs que respondieron,
+// sus mappings y metadata, sin nada que identifique al dispositivo (IP,
+// serial, valores leídos). Un segundo equipo del mismo modelo se perfila
+// desde la plantilla sin repetir el WALK de discovery
+type Template struct {
+	Kind          string    `json:"kind"` // "printsnmp-profile-template"
+	SchemaVersion string    `json:"schema_version"`
+	Brand         string    `json:"brand"`
+	Model         string    `json:"model"`
+	CreatedAt     time.Time `json:"created_at"`
+	Contributor   string    `json:"contributor,omitempty"` // quién la publicó (plantillas de la comunidad)
+
+	// Referencia: la plantilla se generó contra este firmware
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	SNMPVersion     string `json:"snmp_version,omitempty"`
+
+	OIDs             map[string][]string    `json:"oids"`
+	CounterMappings  map[string]string      `json:"counter_mappings,omitempty"`
+	OIDMetadata      map[string]OIDMetadata `json:"oid_metadata,omitempty"`
+	OIDFriendlyNames map[string]string      `json:"oid_friendly_names,omitempty"`
+	Capabilities     CapabilityMap          `json:"capabilities"`
+}
+
+// ipIndexedTables son tablas indexadas por dirección IP (ipAddrTable,
+// ipRouteTable, ipNetToMediaTable): sus OIDs llevan la IP del equipo
+var ipIndexedTables = []string{
+	"1.3.6.1.2.1.4.20.",
+	"1.3.6.1.2.1.4.21.",
+	"1.3.6.1.2.1.4.22.",
+}
+
+// NewTemplate genera la plantilla de un perfil descubierto
+func NewTemplate(p *Profile) (*Template, error) {
+	if p.Brand == "" || p.Model == "" {
+		return nil, fmt.Errorf("el perfil %s no tiene marca/modelo: no sirve como plantilla", p.PrinterID)
+	}
+
+	t := &Template{
+		Kind:             TemplateKind,
+		SchemaVersion:    templateSchemaVersion,
+		Brand:            p.Brand,
+		Model:            p.Model,
+		CreatedAt:        time.Now().UTC(),
+		FirmwareVersion:  p.FirmwareVersion,
+		SNMPVersion:      p.SNMPVersion,
+		OIDs:             make(map[string][]string),
+		CounterMappings:  make(map[string]string),
+		OIDMetadata:      make(map[string]OIDMetadata),
+		OIDFriendlyNames: make(map[string]string),
+		Capabilities:     p.Capabilities,
+	}
+	for category, oids := range p.OIDs {
+		t.OIDs[category] = append([]string(nil), oids...)
+	}
+	for oid, name := range p.CounterMappings {
+		t.CounterMappings[oid] = name
+	}
+	for oid, meta := range p.OIDMetadata {
+		t.OIDMetadata[oid] = meta
+	}
+	for oid, name := range p.OIDFriendlyNames {
+		t.OIDFriendlyNames[oid] = name
+	}
+
+	t.sanitize(p.IP)
+	return t, nil
+}
+
+// sanitize quita todo lo propio del dispositivo: valores leídos y OIDs
+// indexados por su IP. También se aplica al importar plantillas ajenas
+func (t *Template) sanitize(ip string) {
+	for category, oids := range t.OIDs {
+		kept := oids[:0]
+		for _, oid := range oids {
+			if deviceSpecificOID(oid, ip) {
+				delete(t.CounterMappings, oid)
+				delete(t.OIDMetadata, oid)
+				delete(t.OIDFriendlyNames, oid)
+				continue
+			}
+			kept = append(kept, oid)
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i] < kept[j] })
+		if len(kept) == 0 {
+			delete(t.OIDs, category)
+		} else {
+			t.OIDs[category] = kept
+		}
+	}
+
+	for oid, meta := range t.OIDMetadata {
+		// El último valor leído puede ser el serial, el hostname o la ubicación
+		// Consistent/Samples describen al OID en ese modelo y se conservan
+		meta.LastValue = nil
+		meta.MeanValue = 0
+		t.OIDMetadata[oid] = meta
+	}
+}
+
+// deviceSpecificOID reporta si el OID identifica al equipo (lleva su IP como índice)
+func deviceSpecificOID(oid, ip string) bool {
+	normalized := strings.TrimPrefix(oid, ".")
+	for _, prefix := range ipIndexedTables {
+		if strings.HasPrefix(normalized, prefix) {
+			return true
+		}
+	}
+	return ip != "" && (strings.HasSuffix(normalized, "."+ip) || strings.Contains(normalized, "."+ip+"."))
+}
+
+// Validate verifica que el archivo sea una plantilla utilizable
+func (t *Template) Validate() error {
+	if t.Kind != TemplateKind {
+		return fmt.Errorf("no es una plantilla de perfil (kind %q)", t.Kind)
+	}
+	if t.Brand == "" || t.Model == "" {
+		return fmt.Errorf("plantilla sin marca/modelo")
+	}
+	if len(t.OIDs) == 0 {
+		return fmt.Errorf("plantilla %s %s sin OIDs", t.Brand, t.Model)
+	}
+	return nil
+}
+
+// Key es el nombre de archivo de la plantilla ("xerox_altalink-c8055")
+func (t *Template) Key() string {
+	return TemplateKey(t.Brand, t.Model)
+}
+
+// TemplateKey normaliza marca y modelo para buscar plantillas: la marca y
+// el modelo se comparan sin mayúsculas, espacios ni puntuación
+func TemplateKey(brand, model string) string {
+	return normalizeKeyPart(brand) + "_" + normalizeKeyPart(model)
+}
+
+func normalizeKeyPart(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// Instantiate crea el perfil de un dispositivo concreto a partir de la plantilla
+func (t *Template) Instantiate(printerID, ip string) *Profile {
+	p := &Profile{
+		PrinterID:        printerID,
+		IP:               ip,
+		Brand:            t.Brand,
+		Model:            t.Model,
+		OIDs:             make(map[string][]string),
+		CounterMappings:  make(map[string]string),
+		OIDMetadata:      make(map[string]OIDMetadata),
+		OIDFriendlyNames: make(map[string]string),
+		Capabilities:     t.Capabilities,
+		DiscoveredAt:     time.Now(),
+		SNMPVersion:      t.SNMPVersion,
+		Template:         t.Key(),
+	}
+	if p.PrinterID == "" {
+		p.PrinterID = ip
+	}
+	for category, oids := range t.OIDs {
+		p.OIDs[category] = append([]string(nil), oids...)
+	}
+	for oid, name := range t.CounterMappings {
+		p.CounterMappings[oid] = name
+	}
+	for oid, meta := range t.OIDMetadata {
+		p.OIDMetadata[oid] = meta
+	}
+	for oid, name := range t.OIDFriendlyNames {
+		p.OIDFriendlyNames[oid] = name
+	}
+	return p
+}
+
+// LoadTemplate lee y valida un archivo de plantilla
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%s: plantilla inválida: %w", filepath.Base(path), err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return &t, nil
+}
+
+// WriteTemplate guarda la plantilla en path
+func WriteTemplate(path string, t *Template) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando plantilla: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creando directorio de plantillas: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error escribiendo plantilla: %w", err)
+	}
+	return nil
+}
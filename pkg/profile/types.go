@@ -12,6 +12,9 @@ type Profile struct {
 	Brand     string `json:"brand"` // HP, Samsung, Xerox, etc
 	Model     string `json:"model"` // M428, CLP-365, AltaLink C8055
 
+	// Plantilla de modelo de la que salió el perfil ("" = discovery por WALK)
+	Template string `json:"template,omitempty"`
+
 	// OIDs descubiertos (por categoría)
 	OIDs map[string][]string `json:"oids"` // supplies, counters, status, etc
 
@@ -39,6 +42,38 @@ type Profile struct {
 	LastError         string  `json:"last_error,omitempty"`
 	ErrorCount        int     `json:"error_count"`
 	SuccessRate       float64 `json:"success_rate"` // 0.0-1.0
+
+	// Calibración de la marca con lo que respondió el dispositivo
+	BrandFeedback BrandFeedback `json:"brand_feedback"`
+
+	// Circuit breaker de la recolección (ver Manager.RecordCollection)
+	Breaker Breaker `json:"breaker"`
+}
+
+// Breaker limita la recolección de un equipo que falla en cada poll: tras
+// varios polls fallidos seguidos solo recibe un sondeo de vida durante
+// algunos ciclos, en lugar de la batería completa de WALKs
+type Breaker struct {
+	Failures   int       `json:"failures"`              // polls completos fallidos seguidos
+	OpenCycles int       `json:"open_cycles,omitempty"` // ciclos que quedan con solo sondeo de vida (0 = cerrado)
+	OpenedAt   time.Time `json:"opened_at,omitempty"`
+	Trips      int       `json:"trips,omitempty"` // veces que se abrió
+}
+
+// Open indica si el equipo está limitado al sondeo de vida
+func (b Breaker) Open() bool {
+	return b.OpenCycles > 0
+}
+
+// BrandFeedback acumula evidencia de los OIDs del fabricante entre polls
+// La confianza parte del sysDescr y se ajusta con cada ciclo (ver detector.CalibrateConfidence)
+type BrandFeedback struct {
+	VendorHits     int       `json:"vendor_hits"`              // Ciclos donde respondieron OIDs de la marca
+	VendorMisses   int       `json:"vendor_misses"`            // Ciclos sin respuesta o con árbol de otra marca
+	Contradictions int       `json:"contradictions"`           // Veces que sysObjectID/árbol privado contradijo la marca
+	Confidence     float64   `json:"confidence"`               // Confianza calibrada (0.0-1.0)
+	CorrectedFrom  string    `json:"corrected_from,omitempty"` // Marca adivinada por sysDescr antes de corregir
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
 }
 
 // CapabilityMap almacena qué capacidades tiene la impresora
@@ -85,6 +120,7 @@ type OIDMetadata struct {
 	LastValue  interface{} `json:"last_value,omitempty"` // Último valor leído
 	Consistent bool        `json:"consistent,omitempty"` // Pasó validación de consistencia
 	MeanValue  float64     `json:"mean_value,omitempty"` // Promedio de valores en consistency check
+	Samples    int         `json:"samples,omitempty"`    // Lecturas del consistency check (0 = no se validó)
 }
 
 // OIDClassification es el resultado de clasificar y enriquecer un OID
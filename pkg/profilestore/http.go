@@ -0,0 +1,123 @@
+package profilestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/sink"
+)
+
+// HTTPStore guarda las plantillas en una API REST:
+//
+//	GET {url}        → {"templates": ["xerox_xerox-altalink-c8055", ...]}
+//	GET {url}/{key}  → plantilla (404 si no existe)
+//	PUT {url}/{key}  ← plantilla
+type HTTPStore struct {
+	url     string
+	agentID string
+	client  *http.Client
+	auth    *sink.Authorizer
+}
+
+// NewHTTPStore crea el backend HTTP con las credenciales de backend
+func NewHTTPStore(baseURL, agentID string, timeout time.Duration, backend sink.HTTPSinkConfig) (*HTTPStore, error) {
+	if baseURL == "" {
+		return nil, errors.New("profile_store.url requerido para type http")
+	}
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+	client, err := sink.NewHTTPClient(backend.TLS, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPStore{
+		url:     strings.TrimSuffix(baseURL, "/"),
+		agentID: agentID,
+		client:  client,
+		auth:    sink.NewAuthorizer(backend, client),
+	}, nil
+}
+
+// GetTemplate implementa profile.ProfileStore
+func (s *HTTPStore) GetTemplate(ctx context.Context, key string) (*profile.Template, error) {
+	body, err := s.do(ctx, http.MethodGet, s.url+"/"+url.PathEscape(key), nil)
+	if err != nil || body == nil {
+		return nil, err
+	}
+
+	var t profile.Template
+	if err := json.Unmarshal(body, &t); err != nil {
+		return nil, fmt.Errorf("plantilla inválida: %w", err)
+	}
+	return &t, nil
+}
+
+// PutTemplate implementa profile.ProfileStore
+func (s *HTTPStore) PutTemplate(ctx context.Context, t *profile.Template) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, http.MethodPut, s.url+"/"+url.PathEscape(t.Key()), data)
+	return err
+}
+
+// ListTemplates implementa profile.ProfileStore
+func (s *HTTPStore) ListTemplates(ctx context.Context) ([]string, error) {
+	body, err := s.do(ctx, http.MethodGet, s.url, nil)
+	if err != nil || body == nil {
+		return nil, err
+	}
+
+	var list struct {
+		Templates []string `json:"templates"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("listado de plantillas inválido: %w", err)
+	}
+	return list.Templates, nil
+}
+
+// do ejecuta el request; un 404 retorna nil, nil
+func (s *HTTPStore) do(ctx context.Context, method, rawURL string, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Agent-ID", s.agentID)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := s.auth.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode == http.StatusUnauthorized:
+		s.auth.Invalidate()
+		return nil, fmt.Errorf("el profile store rechazó las credenciales (HTTP %d)", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("el profile store respondió HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxTemplateBytes))
+}
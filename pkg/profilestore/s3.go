@@ -0,0 +1,78 @@
+package profilestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/s3"
+)
+
+// S3Store guarda cada plantilla como {prefix}{key}.json en un bucket
+type S3Store struct {
+	client *s3.Client
+	prefix string
+}
+
+// NewS3Store crea el backend S3 (AWS o compatible)
+func NewS3Store(config s3.Config, prefix string) (*S3Store, error) {
+	client, err := s3.New(config)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &S3Store{client: client, prefix: prefix}, nil
+}
+
+// GetTemplate implementa profile.ProfileStore
+func (s *S3Store) GetTemplate(ctx context.Context, key string) (*profile.Template, error) {
+	data, err := s.client.Get(ctx, s.objectKey(key))
+	if errors.Is(err, s3.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t profile.Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("plantilla inválida: %w", err)
+	}
+	return &t, nil
+}
+
+// PutTemplate implementa profile.ProfileStore
+func (s *S3Store) PutTemplate(ctx context.Context, t *profile.Template) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.client.Put(ctx, s.objectKey(t.Key()), data, "application/json")
+}
+
+// ListTemplates implementa profile.ProfileStore
+func (s *S3Store) ListTemplates(ctx context.Context) ([]string, error) {
+	objects, err := s.client.List(ctx, s.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object, s.prefix)
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, ".json"))
+	}
+	return keys, nil
+}
+
+func (s *S3Store) objectKey(key string) string {
+	return s.prefix + key + ".json"
+}
@@ -0,0 +1,42 @@
+// Package profilestore implementa los backends remotos de profile.ProfileStore
+// (API HTTP y bucket S3) con los que varios agentes comparten plantillas
+package profilestore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/profile"
+	"github.com/asaavedra/agent-snmp/pkg/s3"
+	"github.com/asaavedra/agent-snmp/pkg/sink"
+)
+
+// maxTemplateBytes limita el tamaño de una plantilla descargada
+const maxTemplateBytes = 4 << 20
+
+// Config selecciona y configura el backend
+type Config struct {
+	Type    string        // "" (ninguno) | http | s3
+	URL     string        // http: base de la API ("https://api.example.com/profiles")
+	Timeout time.Duration // timeout por request
+
+	S3     s3.Config
+	Prefix string // s3: prefijo de las claves ("printsnmp/templates/")
+}
+
+// New crea el store configurado; nil, nil si Type está vacío
+// El backend HTTP usa las credenciales del sink HTTP (mTLS, token u OAuth2)
+func New(config Config, agentID string, backend sink.HTTPSinkConfig) (profile.ProfileStore, error) {
+	switch config.Type {
+	case "":
+		return nil, nil
+	case "http":
+		return NewHTTPStore(config.URL, agentID, config.Timeout, backend)
+	case "s3":
+		if config.S3.Timeout == 0 {
+			config.S3.Timeout = config.Timeout
+		}
+		return NewS3Store(config.S3, config.Prefix)
+	}
+	return nil, fmt.Errorf("profile_store.type desconocido %q (http | s3)", config.Type)
+}
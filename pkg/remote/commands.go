@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Comandos que el backend puede encolar para un agente
+const (
+	CommandRescan      = "rescan"             // adelantar el próximo ciclo
+	CommandReprofile   = "rediscover_profile" // params: ip (o printer_id)
+	CommandDebugWalk   = "debug_walk"         // params: ip; sube el walk como fixture
+	CommandSetInterval = "set_interval"       // params: minutes
+)
+
+// Command es una orden del backend para este agente
+//
+//	{"commands": [{"id": "c-102", "type": "debug_walk", "params": {"ip": "10.0.0.7"}}]}
+type Command struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Params   map[string]string `json:"params,omitempty"`
+	IssuedAt time.Time         `json:"issued_at,omitempty"`
+}
+
+// FetchCommands pide los comandos pendientes a rawURL (admite {agent_id})
+// El backend los retira de la cola al recibir el resultado (agent_command_result)
+func (f *Fetcher) FetchCommands(ctx context.Context, rawURL string) ([]Command, error) {
+	req, err := f.newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, nil
+	case http.StatusUnauthorized:
+		f.auth.Invalidate()
+		return nil, fmt.Errorf("el backend rechazó las credenciales (HTTP %d)", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("el backend respondió HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Commands []Command `json:"commands"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentBytes)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("lista de comandos inválida: %w", err)
+	}
+	return body.Commands, nil
+}
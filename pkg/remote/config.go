@@ -0,0 +1,165 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+	"github.com/asaavedra/agent-snmp/pkg/sink"
+)
+
+// AgentIDPlaceholder se reemplaza por el ID del agente en la URL
+const AgentIDPlaceholder = "{agent_id}"
+
+// maxDocumentBytes limita la respuesta del backend
+const maxDocumentBytes = 1 << 20
+
+// Document es la configuración que el backend asigna a un agente
+//
+//	{"version": "2026-10-15.3", "config": {"discovery": {"ip_range": "..."}, ...}}
+//
+// config usa las mismas claves que config.yaml y solo trae lo que cambia
+type Document struct {
+	Version   string          `json:"version"`
+	Config    json.RawMessage `json:"config"`
+	ETag      string          `json:"etag,omitempty"`       // para If-None-Match en el próximo pull
+	FetchedAt time.Time       `json:"fetched_at,omitempty"` // último pull exitoso
+}
+
+// ErrNotAssigned indica que el backend no tiene configuración para el agente (404)
+var ErrNotAssigned = errors.New("el backend no tiene configuración asignada para este agente")
+
+// Fetcher consulta la API de gestión en nombre del agente (config y comandos)
+// Usa el mismo cliente y credenciales que el sink HTTP (mTLS, token u OAuth2)
+type Fetcher struct {
+	agentID string
+	client  *http.Client
+	auth    *sink.Authorizer
+}
+
+// NewFetcher crea el cliente de la API de gestión con las credenciales de backend
+func NewFetcher(agentID string, backend sink.HTTPSinkConfig) (*Fetcher, error) {
+	timeout := backend.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+	client, err := sink.NewHTTPClient(backend.TLS, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fetcher{
+		agentID: agentID,
+		client:  client,
+		auth:    sink.NewAuthorizer(backend, client),
+	}, nil
+}
+
+// Fetch pide la configuración a rawURL (admite {agent_id}); retorna nil, nil
+// si no cambió desde previous (304)
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string, previous *Document) (*Document, error) {
+	req, err := f.newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.ETag != "" {
+		req.Header.Set("If-None-Match", previous.ETag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, ErrNotAssigned
+	case http.StatusUnauthorized:
+		f.auth.Invalidate()
+		return nil, fmt.Errorf("el backend rechazó las credenciales (HTTP %d)", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("el backend respondió HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("documento de configuración inválido: %w", err)
+	}
+	if doc.Version == "" {
+		doc.Version = strings.Trim(resp.Header.Get("ETag"), `"`)
+	}
+	if doc.Version == "" {
+		return nil, errors.New("documento de configuración sin version")
+	}
+	doc.ETag = resp.Header.Get("ETag")
+	doc.FetchedAt = time.Now().UTC()
+
+	return &doc, nil
+}
+
+// newRequest arma un request al backend con el agent ID y las credenciales
+func (f *Fetcher) newRequest(ctx context.Context, method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.expand(rawURL), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Agent-ID", f.agentID)
+	if err := f.auth.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// expand reemplaza {agent_id} en la URL
+func (f *Fetcher) expand(rawURL string) string {
+	return strings.ReplaceAll(rawURL, AgentIDPlaceholder, url.PathEscape(f.agentID))
+}
+
+// LoadCached lee el último documento aplicado (nil si no hay)
+// Permite arrancar con la config remota aunque el backend no responda
+func LoadCached(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// SaveCached guarda el documento de forma atómica (puede traer credenciales: 0600)
+func SaveCached(path string, doc *Document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0600)
+}
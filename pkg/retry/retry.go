@@ -0,0 +1,181 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Policy define cómo reintentar una operación
+// Backoff exponencial con jitter: espera = min(InitialWait * Multiplier^(n-1), MaxWait) ± Jitter
+type Policy struct {
+	MaxAttempts int              // Intentos totales incluyendo el primero (default: 3)
+	InitialWait time.Duration    // Espera antes del segundo intento (default: 1s)
+	MaxWait     time.Duration    // Tope de espera entre intentos (default: 60s)
+	Multiplier  float64          // Factor de crecimiento (default: 2)
+	Jitter      float64          // Fracción aleatoria de la espera, 0-1 (default: 0.2)
+	Retryable   func(error) bool // Clasificador de errores (default: IsRetryable)
+}
+
+// DefaultPolicy retorna la política por defecto (sinks)
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		InitialWait: 1 * time.Second,
+		MaxWait:     60 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// SNMPPolicy retorna la política para operaciones SNMP
+// Esperas cortas: un timeout SNMP ya consume varios segundos
+func SNMPPolicy(retries int) Policy {
+	if retries < 0 {
+		retries = 0
+	}
+	return Policy{
+		MaxAttempts: retries + 1,
+		InitialWait: 200 * time.Millisecond,
+		MaxWait:     2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.3,
+	}
+}
+
+// withDefaults completa los campos vacíos
+func (p Policy) withDefaults() Policy {
+	d := DefaultPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialWait <= 0 {
+		p.InitialWait = d.InitialWait
+	}
+	if p.MaxWait <= 0 {
+		p.MaxWait = d.MaxWait
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = d.Multiplier
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = d.Jitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// Backoff retorna la espera antes del intento número attempt (1 = segundo intento)
+func (p Policy) Backoff(attempt int) time.Duration {
+	p = p.withDefaults()
+
+	wait := float64(p.InitialWait)
+	for i := 1; i < attempt; i++ {
+		wait *= p.Multiplier
+		if wait >= float64(p.MaxWait) {
+			wait = float64(p.MaxWait)
+			break
+		}
+	}
+
+	// Jitter simétrico para que agentes/targets no reintenten sincronizados
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	if wait > float64(p.MaxWait) {
+		wait = float64(p.MaxWait)
+	}
+	return time.Duration(wait)
+}
+
+// Do ejecuta fn hasta que tenga éxito, se agoten los intentos,
+// el error no sea reintentable o el contexto se cancele
+// fn recibe el número de intento (0 = primero)
+func (p Policy) Do(ctx context.Context, fn func(attempt int) error) error {
+	p = p.withDefaults()
+
+	var lastErr error
+	for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(p.Backoff(attempt)):
+			case <-ctx.Done():
+				return fmt.Errorf("context cancelled after %d attempts: %w (last error: %w)", attempt, ctx.Err(), lastErr)
+			}
+		}
+
+		lastErr = fn(attempt)
+		if lastErr == nil {
+			return nil
+		}
+
+		if !p.Retryable(lastErr) {
+			return lastErr
+		}
+	}
+
+	return &ExhaustedError{Attempts: p.MaxAttempts, Err: lastErr}
+}
+
+// ExhaustedError indica que se agotaron los intentos
+type ExhaustedError struct {
+	Attempts int
+	Err      error
+}
+
+// Error implementa la interfaz error
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap permite errors.Is/As sobre el error original
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// permanentError marca un error como no reintentable
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent envuelve un error para que la política no lo reintente
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable clasifica un error:
+// - Permanent(...), context.Canceled o context.DeadlineExceeded → no reintentable
+// - errores que implementan IsRetryable() bool → lo que digan
+// - cualquier otro error (timeouts de red, 5xx) → reintentable
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var classified interface{ IsRetryable() bool }
+	if errors.As(err, &classified) {
+		return classified.IsRetryable()
+	}
+
+	return true
+}
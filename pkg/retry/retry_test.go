@@ -0,0 +1,147 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	policy := Policy{InitialWait: 100 * time.Millisecond, MaxWait: time.Second, Multiplier: 2, Jitter: 0.2}
+	for _, tc := range []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 80 * time.Millisecond, 120 * time.Millisecond},
+		{2, 160 * time.Millisecond, 240 * time.Millisecond},
+		{3, 320 * time.Millisecond, 480 * time.Millisecond},
+		{4, 640 * time.Millisecond, 960 * time.Millisecond},
+		{5, 800 * time.Millisecond, time.Second}, // tope: el jitter nunca lo supera
+		{50, 800 * time.Millisecond, time.Second},
+	} {
+		for i := 0; i < 200; i++ {
+			if wait := policy.Backoff(tc.attempt); wait < tc.min || wait > tc.max {
+				t.Fatalf("Backoff(%d) = %v, fuera de [%v, %v]", tc.attempt, wait, tc.min, tc.max)
+			}
+		}
+	}
+
+	// Sin jitter la espera es exacta
+	exact := Policy{InitialWait: 100 * time.Millisecond, MaxWait: time.Second, Multiplier: 3}
+	if wait := exact.Backoff(2); wait != 300*time.Millisecond {
+		t.Errorf("sin jitter: %v, se esperaba 300ms", wait)
+	}
+}
+
+func TestBackoffDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   Policy
+		attempt  int
+		min, max time.Duration
+	}{
+		{"vacía", Policy{}, 1, 800 * time.Millisecond, 1200 * time.Millisecond},
+		{"multiplicador menor que 1", Policy{InitialWait: time.Second, Multiplier: 0.5}, 2, 1600 * time.Millisecond, 2400 * time.Millisecond},
+		{"jitter fuera de rango", Policy{InitialWait: time.Second, Jitter: 5}, 1, 800 * time.Millisecond, 1200 * time.Millisecond},
+	} {
+		if wait := tc.policy.Backoff(tc.attempt); wait < tc.min || wait > tc.max {
+			t.Errorf("%s: Backoff(%d) = %v, fuera de [%v, %v]", tc.name, tc.attempt, wait, tc.min, tc.max)
+		}
+	}
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	fail := errors.New("timeout")
+	for _, tc := range []struct {
+		name      string
+		policy    Policy
+		results   []error // resultado de cada intento (nil = éxito)
+		wantCalls int
+		check     func(err error) bool
+	}{
+		{"éxito al primero", Policy{MaxAttempts: 3}, []error{nil}, 1, func(err error) bool { return err == nil }},
+		{"éxito al tercero", Policy{MaxAttempts: 3}, []error{fail, fail, nil}, 3, func(err error) bool { return err == nil }},
+		{"agotado", Policy{MaxAttempts: 3}, []error{fail, fail, fail, nil}, 3, func(err error) bool {
+			var exhausted *ExhaustedError
+			return errors.As(err, &exhausted) && exhausted.Attempts == 3 && errors.Is(err, fail)
+		}},
+		{"permanente", Policy{MaxAttempts: 3}, []error{Permanent(fail), nil}, 1, func(err error) bool {
+			var exhausted *ExhaustedError
+			return errors.Is(err, fail) && !errors.As(err, &exhausted)
+		}},
+		{"clasificador propio", Policy{MaxAttempts: 5, Retryable: func(err error) bool { return err != fail }}, []error{fail, nil}, 1, func(err error) bool { return errors.Is(err, fail) }},
+		{"SNMP sin reintentos", SNMPPolicy(0), []error{fail, nil}, 1, func(err error) bool { return errors.Is(err, fail) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.policy.InitialWait = time.Millisecond
+			tc.policy.MaxWait = time.Millisecond
+			calls := 0
+			err := tc.policy.Do(context.Background(), func(attempt int) error {
+				if attempt != calls {
+					t.Errorf("intento %d, se esperaba %d", attempt, calls)
+				}
+				calls++
+				return tc.results[attempt]
+			})
+			if calls != tc.wantCalls {
+				t.Errorf("%d llamadas, se esperaban %d", calls, tc.wantCalls)
+			}
+			if !tc.check(err) {
+				t.Errorf("error inesperado: %v", err)
+			}
+		})
+	}
+}
+
+// La cancelación corta la espera entre intentos y se puede detectar con errors.Is
+func TestDoCancel(t *testing.T) {
+	fail := errors.New("timeout")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	policy := Policy{MaxAttempts: 5, InitialWait: 10 * time.Second, MaxWait: 10 * time.Second}
+	calls := 0
+	start := time.Now()
+	err := policy.Do(ctx, func(int) error {
+		calls++
+		return fail
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do esperó %v con el contexto vencido", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("%d llamadas, se esperaba 1", calls)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, fail) {
+		t.Errorf("error %v: se esperaba que envuelva el vencimiento y el último error", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"genérico", errors.New("timeout"), true},
+		{"permanente", Permanent(errors.New("401")), false},
+		{"cancelado", context.Canceled, false},
+		{"vencido", context.DeadlineExceeded, false},
+		{"clasificado reintentable", classified(true), true},
+		{"clasificado permanente", classified(false), false},
+	} {
+		if got := IsRetryable(tc.err); got != tc.want {
+			t.Errorf("%s: IsRetryable = %v, se esperaba %v", tc.name, got, tc.want)
+		}
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) no es nil")
+	}
+}
+
+type classified bool
+
+func (c classified) Error() string     { return "clasificado" }
+func (c classified) IsRetryable() bool { return bool(c) }
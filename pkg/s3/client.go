@@ -0,0 +1,307 @@
+// Package s3 es un cliente mínimo de S3 (GET, PUT y LIST de objetos) con
+// firma AWS Signature V4. Sirve para AWS y para compatibles (MinIO, Ceph,
+// Wasabi) sin depender del SDK
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxObjectBytes limita lo que se lee de un objeto
+const maxObjectBytes = 16 << 20
+
+// ErrNotFound indica que el objeto no existe (404 / NoSuchKey)
+var ErrNotFound = errors.New("objeto S3 no encontrado")
+
+// Config configura el acceso a un bucket
+type Config struct {
+	Bucket          string
+	Region          string // "us-east-1" si se omite
+	Endpoint        string // "" = AWS; "https://minio.local:9000" para compatibles
+	PathStyle       bool   // endpoint/bucket/key en vez de bucket.endpoint/key (MinIO)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // credenciales temporales (STS)
+	Timeout         time.Duration
+
+	// Cifrado del lado del servidor en cada PUT
+	ServerSideEncryption string // "" (default del bucket) | AES256 | aws:kms
+	KMSKeyID             string // aws:kms: ARN o ID de la llave ("" = llave administrada por AWS)
+}
+
+// Client accede a un bucket S3
+type Client struct {
+	config Config
+	base   *url.URL // URL del bucket (incluye /bucket con path-style)
+	client *http.Client
+	now    func() time.Time
+}
+
+// New crea el cliente; falla si falta bucket o credenciales
+func New(config Config) (*Client, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("s3: falta bucket")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("s3: faltan access_key_id/secret_access_key")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("s3: endpoint inválido %q", endpoint)
+	}
+	if config.PathStyle {
+		base.Path += "/" + config.Bucket
+	} else {
+		base.Host = config.Bucket + "." + base.Host
+	}
+
+	return &Client{
+		config: config,
+		base:   base,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Get descarga un objeto; ErrNotFound si no existe
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxObjectBytes))
+}
+
+// Put sube (o reemplaza) un objeto
+func (c *Client) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	resp, err := c.do(ctx, http.MethodPut, key, nil, data, contentType)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List retorna las claves bajo prefix (ListObjectsV2, todas las páginas)
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(io.LimitReader(resp.Body, maxObjectBytes)).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3: respuesta de ListObjectsV2 inválida: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			keys = append(keys, obj.Key)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do firma y ejecuta el request; los códigos != 2xx se convierten en error
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	u := *c.base
+	if key != "" {
+		u.Path += "/" + strings.TrimPrefix(key, "/")
+	} else if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = encodePath(u.Path) // la ruta en el cable es la misma que se firma
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if method == http.MethodPut && c.config.ServerSideEncryption != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", c.config.ServerSideEncryption)
+		if c.config.KMSKeyID != "" {
+			req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", c.config.KMSKeyID)
+		}
+	}
+	c.sign(req, body)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var s3err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&s3err)
+	if resp.StatusCode == http.StatusNotFound && (s3err.Code == "" || s3err.Code == "NoSuchKey") {
+		return nil, ErrNotFound
+	}
+	if s3err.Code != "" {
+		return nil, fmt.Errorf("s3: %s %s: HTTP %d %s: %s", method, key, resp.StatusCode, s3err.Code, s3err.Message)
+	}
+	return nil, fmt.Errorf("s3: %s %s: HTTP %d", method, key, resp.StatusCode)
+}
+
+// sign agrega los headers de AWS Signature V4
+func (c *Client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+
+	// Headers firmados: host + todos los x-amz-* + content-type
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.config.SecretAccessKey), date)
+	key = hmacSHA256(key, c.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery codifica la query ordenada por clave como exige SigV4
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, encode(k, true)+"="+encode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodePath codifica la ruta segmento a segmento (las "/" se conservan)
+func encodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return encode(path, false)
+}
+
+// encode aplica el URI-encoding de SigV4: solo A-Z a-z 0-9 - _ . ~ quedan
+// literales; "/" también si encodeSlash es false
+func encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -3,25 +3,35 @@ package scanner
 import (
 	"context"
 	"fmt"
-	"sync"
+	"net"
+	"strconv"
 	"time"
 
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
 	"github.com/asaavedra/agent-snmp/pkg/snmp"
 )
 
 // DiscoveryResult contiene información de un dispositivo descubierto
 type DiscoveryResult struct {
 	IP              string
+	Port            uint16 // 0 = puerto SNMP de la configuración
 	Community       string
 	SNMPVersion     string
 	SysDescr        string
 	SysObjectID     string
+	DNSName         string // nombre PTR ("" si no se consultó o no tiene)
 	IsResponsive    bool
 	ResponseTime    time.Duration
 	DiscoveredAt    time.Time
 	Brand           string
 	BrandConfidence float64
 	Errors          []string
+
+	// Hosts sin SNMP con puertos de impresión abiertos (ver fingerprint.go)
+	NoSNMP     bool
+	OpenPorts  []int
+	Banner     string // banners leídos, separados por " | "
+	ModelGuess string // modelo deducido de los banners ("" si no se pudo)
 }
 
 // DiscoveryConfig contiene configuración para el discovery
@@ -31,84 +41,144 @@ type DiscoveryConfig struct {
 	Retries                  int
 	Community                string
 	SNMPVersion              string
+	SNMPv3                   *snmp.V3Credentials // credenciales USM si SNMPVersion es "3"
 	SNMPPort                 uint16
+	V1Fallback               bool              // Reintentar con v1 las IPs que no responden v2c
+	Engine                   *snmp.Engine      // Motor SNMP compartido (nil = crear uno propio)
+	ReverseDNS               bool              // Consultar el PTR de cada IP que responde SNMP
+	DNSTimeout               time.Duration     // Timeout de cada consulta PTR (0 = 2s)
+	OnProgress               ProgressFunc      // Avance del escaneo IP por IP (nil = sin reporte)
+	Fingerprint              FingerprintConfig // Sondeo de puertos de impresión de las IPs sin SNMP
+	FastProbe                FastProbeConfig   // GET de sysUpTime previo que filtra las IPs y ajusta TimeoutPerDevice
 }
 
 // DiscoveryScanner ejecuta escaneo SNMP en paralelo
 type DiscoveryScanner struct {
-	config DiscoveryConfig
+	config    DiscoveryConfig
+	engine    *snmp.Engine
+	noSNMP    []DiscoveryResult
+	histogram *ResponseHistogram
 }
 
 // NewDiscoveryScanner crea un nuevo scanner de discovery
 func NewDiscoveryScanner(config DiscoveryConfig) *DiscoveryScanner {
-	return &DiscoveryScanner{config: config}
+	engine := config.Engine
+	if engine == nil {
+		engine = snmp.NewEngine(snmp.EngineConfig{MaxWorkers: config.MaxConcurrentConnections})
+	}
+	return &DiscoveryScanner{config: config, engine: engine}
 }
 
 // Scan ejecuta el escaneo de IPs
+// Una IP puede traer puerto ("127.0.0.1:16100", simuladores en un mismo host)
 func (ds *DiscoveryScanner) Scan(ctx context.Context, ips []string) ([]DiscoveryResult, error) {
 	results := make([]DiscoveryResult, 0, len(ips))
 	resultsChan := make(chan DiscoveryResult, len(ips))
-	var wg sync.WaitGroup
 
-	// Semáforo para limitar concurrencia
-	semaphore := make(chan struct{}, ds.config.MaxConcurrentConnections)
-
-	fmt.Printf("Iniciando descubrimiento de %d IPs...\n", len(ips))
+	fmt.Println(i18n.T("log.discovery_start", len(ips)))
 	startTime := time.Now()
-
-	for _, ip := range ips {
-		wg.Add(1)
-
-		go func(targetIP string) {
-			defer wg.Done()
-
-			// Adquirir slot
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			result := ds.probeIP(ctx, targetIP)
-			resultsChan <- result
-		}(ip)
+	progress := NewProgressTracker(PhaseDiscovery, len(ips), ds.config.OnProgress)
+
+	// Sondeo rápido: el probe completo solo a las IPs que respondieron sysUpTime
+	timeout := ds.config.TimeoutPerDevice
+	var responsive map[string]string
+	ds.histogram = nil
+	if ds.config.FastProbe.Enabled {
+		responsive, ds.histogram = ds.fastProbe(ctx, ips)
+		timeout = ds.histogram.TunedTimeout
 	}
 
-	// Esperar a que todos terminen
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	// Pool acotado de workers del motor SNMP (no una goroutine por IP)
+	ds.engine.ForEach(ctx, len(ips), func(i int) {
+		var result DiscoveryResult
+		if version, ok := responsive[ips[i]]; ok || responsive == nil {
+			result = ds.probeIP(ctx, ips[i], version, timeout)
+		} else {
+			result = DiscoveryResult{IP: ips[i], DiscoveredAt: time.Now(), Errors: []string{"fast_probe_timeout"}}
+		}
+		if !result.IsResponsive && ds.config.Fingerprint.Enabled && ctx.Err() == nil {
+			ds.fingerprint(ctx, &result)
+		}
+		progress.Step(result.IsResponsive)
+		resultsChan <- result
+	})
+	close(resultsChan)
+	progress.Finish()
 
 	// Recolectar resultados
+	ds.noSNMP = nil
 	for result := range resultsChan {
 		if result.IsResponsive {
 			results = append(results, result)
+		} else if result.NoSNMP {
+			ds.noSNMP = append(ds.noSNMP, result)
 		}
 	}
 
-	fmt.Printf("Descubrimiento completado en %.2f segundos. Encontradas %d impresoras.\n",
-		time.Since(startTime).Seconds(), len(results))
+	fmt.Println(i18n.T("log.discovery_done", time.Since(startTime).Seconds(), len(results)))
+	if len(ds.noSNMP) > 0 {
+		fmt.Println(i18n.T("log.discovery_no_snmp", len(ds.noSNMP)))
+	}
 
 	return results, nil
 }
 
+// splitTarget separa "host:puerto"; una IP sola usa el puerto de la configuración
+func (ds *DiscoveryScanner) splitTarget(target string) (string, uint16) {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return target, ds.config.SNMPPort
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return target, ds.config.SNMPPort
+	}
+	return host, uint16(n)
+}
+
+// Histogram retorna los tiempos de respuesta del sondeo rápido del último
+// Scan (nil sin FastProbe.Enabled)
+func (ds *DiscoveryScanner) Histogram() *ResponseHistogram {
+	return ds.histogram
+}
+
+// NoSNMP retorna los hosts del último Scan que no respondieron SNMP pero
+// tienen puertos de impresión abiertos (solo con Fingerprint.Enabled)
+func (ds *DiscoveryScanner) NoSNMP() []DiscoveryResult {
+	return ds.noSNMP
+}
+
 // probeIP prueba un IP individual
-func (ds *DiscoveryScanner) probeIP(ctx context.Context, ip string) DiscoveryResult {
+// version es la versión SNMP que respondió el sondeo rápido ("" = la de la
+// configuración, con fallback a v1 si está habilitado)
+func (ds *DiscoveryScanner) probeIP(ctx context.Context, target, version string, timeout time.Duration) DiscoveryResult {
+	ip, port := ds.splitTarget(target)
 	result := DiscoveryResult{
 		IP:           ip,
 		Community:    ds.config.Community,
 		SNMPVersion:  ds.config.SNMPVersion,
 		DiscoveredAt: time.Now(),
 	}
+	if port != ds.config.SNMPPort {
+		result.Port = port
+	}
+
+	if version != "" {
+		result.SNMPVersion = version
+	} else {
+		version = ds.config.SNMPVersion
+	}
 
 	startTime := time.Now()
 
-	client := snmp.NewSNMPClient(
+	client := ds.engine.NewClient(
 		ip,
-		ds.config.SNMPPort,
+		port,
 		ds.config.Community,
-		ds.config.SNMPVersion,
-		ds.config.TimeoutPerDevice,
+		version,
+		timeout,
 		ds.config.Retries,
-	)
+	).WithV3(ds.config.SNMPv3).WithPriority(snmp.PriorityDiscovery)
 
 	// Intentar validar conexión
 	err := client.ValidateConnection()
@@ -120,13 +190,21 @@ func (ds *DiscoveryScanner) probeIP(ctx context.Context, ip string) DiscoveryRes
 
 	// Obtener sysDescr
 	sysDescr, err := client.Get("1.3.6.1.2.1.1.1.0", snmp.NewContext())
+	if err != nil && ds.config.V1Fallback && client.Version() == "2c" {
+		// Equipos antiguos solo hablan v1 y descartan v2c sin responder
+		v1 := client.WithVersion("1")
+		if descr, errV1 := v1.Get("1.3.6.1.2.1.1.1.0", snmp.NewContext()); errV1 == nil {
+			client, sysDescr, err = v1, descr, nil
+			result.SNMPVersion = "1"
+		}
+	}
 	if err != nil {
 		result.IsResponsive = false
 		result.Errors = append(result.Errors, fmt.Sprintf("sysdescr_error: %v", err))
 		return result
 	}
 
-	if sysDescr == nil || sysDescr == "" {
+	if sysDescr == nil || fmt.Sprintf("%v", sysDescr) == "" {
 		result.IsResponsive = false
 		result.Errors = append(result.Errors, "sysdescr_empty")
 		return result
@@ -143,6 +221,11 @@ func (ds *DiscoveryScanner) probeIP(ctx context.Context, ip string) DiscoveryRes
 	result.IsResponsive = true
 	result.ResponseTime = time.Since(startTime)
 
+	// PTR solo para los que responden: no consultar DNS por cada IP del rango
+	if ds.config.ReverseDNS {
+		result.DNSName = lookupPTR(ctx, ip, ds.config.DNSTimeout)
+	}
+
 	// Detectar marca (será hecho después en el flujo principal)
 
 	return result
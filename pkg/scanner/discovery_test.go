@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+)
+
+// startSimulators levanta un simulador por fixture incluido y retorna sus
+// direcciones host:puerto y el sysDescr esperado de cada una
+func startSimulators(t *testing.T) map[string]string {
+	t.Helper()
+	targets := make(map[string]string)
+	for _, name := range simulator.BuiltinNames() {
+		f, err := simulator.Builtin(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Community = ""
+
+		agent, err := simulator.NewAgent(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := agent.Start("127.0.0.1:0"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { agent.Close() })
+
+		host, port := agent.Addr()
+		for _, v := range f.Variables {
+			if v.OID == "1.3.6.1.2.1.1.1.0" {
+				targets[fmt.Sprintf("%s:%d", host, port)] = v.Value
+			}
+		}
+	}
+	return targets
+}
+
+// closedPort retorna un puerto UDP local sin nadie escuchando
+func closedPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+	return port
+}
+
+func TestScanSimulators(t *testing.T) {
+	targets := startSimulators(t)
+	silent := fmt.Sprintf("127.0.0.1:%d", closedPort(t))
+
+	for _, fast := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fast_probe=%v", fast), func(t *testing.T) {
+			ds := NewDiscoveryScanner(DiscoveryConfig{
+				MaxConcurrentConnections: 4,
+				TimeoutPerDevice:         time.Second,
+				Community:                "public",
+				SNMPVersion:              "2c",
+				SNMPPort:                 161,
+				FastProbe:                FastProbeConfig{Enabled: fast, Timeout: 500 * time.Millisecond},
+			})
+
+			ips := []string{silent}
+			for target := range targets {
+				ips = append(ips, target)
+			}
+			results, err := ds.Scan(context.Background(), ips)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(targets) {
+				t.Fatalf("%d dispositivos descubiertos, se esperaban %d", len(results), len(targets))
+			}
+
+			for _, r := range results {
+				target := fmt.Sprintf("%s:%d", r.IP, r.Port)
+				want, ok := targets[target]
+				if !ok {
+					t.Errorf("resultado para %s, que no es un simulador", target)
+					continue
+				}
+				if !r.IsResponsive || r.SysDescr != want {
+					t.Errorf("%s: responsive=%v sysDescr=%q, se esperaba %q", target, r.IsResponsive, r.SysDescr, want)
+				}
+				if r.SysObjectID == "" {
+					t.Errorf("%s: sin sysObjectID", target)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	ds := NewDiscoveryScanner(DiscoveryConfig{SNMPPort: 161})
+	for _, tc := range []struct {
+		target string
+		host   string
+		port   uint16
+	}{
+		{"192.168.1.10", "192.168.1.10", 161},
+		{"127.0.0.1:16100", "127.0.0.1", 16100},
+		{"fe80::1", "fe80::1", 161},
+		{"[fe80::1]:1161", "fe80::1", 1161},
+		{"10.0.0.1:puerto", "10.0.0.1:puerto", 161},
+	} {
+		host, port := ds.splitTarget(tc.target)
+		if host != tc.host || port != tc.port {
+			t.Errorf("splitTarget(%q) = %q, %d; se esperaba %q, %d", tc.target, host, port, tc.host, tc.port)
+		}
+	}
+}
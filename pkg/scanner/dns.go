@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+)
+
+// defaultDNSTimeout acota cada consulta PTR cuando DiscoveryConfig.DNSTimeout es 0
+const defaultDNSTimeout = 2 * time.Second
+
+// lookupPTR retorna el nombre DNS de ip (sin el punto final) o "" si no tiene PTR
+// Un DNS lento o ausente no debe frenar el discovery: se corta en timeout
+func lookupPTR(ctx context.Context, ip string, timeout time.Duration) string {
+	if timeout <= 0 {
+		timeout = defaultDNSTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// oidSysUpTime es el único OID del sondeo rápido: lo responde todo agente
+// SNMP y cabe en un paquete mínimo
+const oidSysUpTime = "1.3.6.1.2.1.1.3.0"
+
+// Valores por defecto del sondeo rápido
+const (
+	defaultFastProbeTimeout = 300 * time.Millisecond
+	defaultTimeoutFactor    = 4
+	minTunedTimeout         = 200 * time.Millisecond // piso del timeout ajustado: un p95 de 2ms no es un timeout sano
+)
+
+// histogramBounds son los límites superiores de los buckets de respuesta
+var histogramBounds = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond, time.Second,
+}
+
+// FastProbeConfig configura el sondeo rápido previo al discovery
+// Un GET de sysUpTime por IP, con timeout corto y sin reintentos, separa los
+// hosts que responden de los que no: el probe completo (sysDescr, sysObjectID)
+// solo se hace a los primeros, con un timeout ajustado a la latencia medida
+type FastProbeConfig struct {
+	Enabled       bool
+	Timeout       time.Duration // por IP (0 = 300ms)
+	TimeoutFactor float64       // timeout del probe completo = p95 × factor (0 = 4), sin superar TimeoutPerDevice
+}
+
+// HistogramBucket es la cantidad de respuestas hasta UpperMs (0 = sin límite)
+type HistogramBucket struct {
+	UpperMs int64
+	Count   int
+}
+
+// ResponseHistogram resume las respuestas del sondeo rápido
+type ResponseHistogram struct {
+	Probed       int
+	Responded    int
+	Buckets      []HistogramBucket
+	P50          time.Duration
+	P95          time.Duration
+	Max          time.Duration
+	TunedTimeout time.Duration // timeout del probe completo
+}
+
+// newResponseHistogram arma el histograma con los tiempos de respuesta
+func newResponseHistogram(probed int, samples []time.Duration) *ResponseHistogram {
+	h := &ResponseHistogram{Probed: probed, Responded: len(samples)}
+	h.Buckets = make([]HistogramBucket, len(histogramBounds)+1)
+	for i, bound := range histogramBounds {
+		h.Buckets[i].UpperMs = bound.Milliseconds()
+	}
+	for _, rtt := range samples {
+		i := sort.Search(len(histogramBounds), func(i int) bool { return rtt <= histogramBounds[i] })
+		h.Buckets[i].Count++
+	}
+
+	if len(samples) > 0 {
+		sorted := append([]time.Duration(nil), samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		h.P50 = percentile(sorted, 0.50)
+		h.P95 = percentile(sorted, 0.95)
+		h.Max = sorted[len(sorted)-1]
+	}
+	return h
+}
+
+// percentile retorna el percentil p (0-1) de sorted (ordenado)
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
+
+// tuneTimeout retorna el timeout del probe completo: p95 × factor, entre
+// minTunedTimeout y configured. Sin respuestas se mantiene configured
+func (h *ResponseHistogram) tuneTimeout(configured time.Duration, factor float64) time.Duration {
+	if h.Responded == 0 {
+		return configured
+	}
+	if factor <= 0 {
+		factor = defaultTimeoutFactor
+	}
+	tuned := time.Duration(float64(h.P95) * factor)
+	if tuned < minTunedTimeout {
+		tuned = minTunedTimeout
+	}
+	if configured > 0 && tuned > configured {
+		tuned = configured
+	}
+	return tuned
+}
+
+// fastProbe envía el GET de sysUpTime a cada IP y retorna las que
+// respondieron (con la versión SNMP que respondió) y el histograma
+func (ds *DiscoveryScanner) fastProbe(ctx context.Context, ips []string) (map[string]string, *ResponseHistogram) {
+	timeout := ds.config.FastProbe.Timeout
+	if timeout <= 0 {
+		timeout = defaultFastProbeTimeout
+	}
+
+	var mu sync.Mutex
+	responsive := make(map[string]string)
+	var samples []time.Duration
+
+	ds.engine.ForEach(ctx, len(ips), func(i int) {
+		host, port := ds.splitTarget(ips[i])
+		client := ds.engine.NewClient(host, port, ds.config.Community, ds.config.SNMPVersion, timeout, 0).
+			WithV3(ds.config.SNMPv3).
+			WithContext(ctx).
+			WithPriority(snmp.PriorityDiscovery)
+
+		start := time.Now()
+		_, err := client.Get(oidSysUpTime, snmp.NewContext())
+		if err != nil && ds.config.V1Fallback && client.Version() == "2c" {
+			client = client.WithVersion("1")
+			start = time.Now()
+			_, err = client.Get(oidSysUpTime, snmp.NewContext())
+		}
+		if err != nil {
+			return
+		}
+		rtt := time.Since(start)
+
+		mu.Lock()
+		responsive[ips[i]] = client.Version()
+		samples = append(samples, rtt)
+		mu.Unlock()
+	})
+
+	h := newResponseHistogram(len(ips), samples)
+	h.TunedTimeout = h.tuneTimeout(ds.config.TimeoutPerDevice, ds.config.FastProbe.TimeoutFactor)
+	fmt.Println(i18n.T("log.fast_probe_done", h.Responded, h.Probed, h.P50.Round(time.Millisecond), h.P95.Round(time.Millisecond), h.TunedTimeout))
+	return responsive, h
+}
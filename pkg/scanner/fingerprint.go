@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fingerprint de equipos sin SNMP
+//
+// Un host que no responde SNMP pero tiene abiertos los puertos de impresión
+// (9100 raw/PJL, 631 IPP, 80 servidor web) casi siempre es una impresora con
+// SNMP deshabilitado o con otra community. En vez de descartarlo se informa
+// como "impresora sin SNMP" con el modelo que se deduce de los banners, para
+// que el cliente vea los huecos de cobertura
+
+// defaultFingerprintPorts son los puertos sondeados si FingerprintConfig.Ports está vacío
+var defaultFingerprintPorts = []int{9100, 631, 80}
+
+// defaultFingerprintTimeout acota cada conexión cuando FingerprintConfig.Timeout es 0
+const defaultFingerprintTimeout = time.Second
+
+// maxBannerBytes acota lo que se lee de cada banner
+const maxBannerBytes = 64 << 10
+
+// FingerprintConfig configura el sondeo de los hosts sin SNMP (Enabled false = se descartan)
+type FingerprintConfig struct {
+	Enabled bool
+	Ports   []int         // vacío = 9100, 631, 80
+	PJL     bool          // @PJL INFO ID por 9100 (un equipo sin PJL podría imprimir la consulta)
+	Timeout time.Duration // por conexión (0 = 1s)
+}
+
+// titlePattern extrae el <title> de la página del servidor web embebido
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// fingerprint sondea los puertos de impresión de un host que no respondió SNMP
+// Completa OpenPorts, Banner y ModelGuess; sin puertos abiertos no cambia nada
+func (ds *DiscoveryScanner) fingerprint(ctx context.Context, result *DiscoveryResult) {
+	cfg := ds.config.Fingerprint
+	ports := cfg.Ports
+	if len(ports) == 0 {
+		ports = defaultFingerprintPorts
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultFingerprintTimeout
+	}
+
+	// Los puertos en paralelo: una IP vacía no debe costar un timeout por puerto
+	var mu sync.Mutex
+	banners := make(map[int]string)
+	var wg sync.WaitGroup
+	for _, port := range ports {
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			open, banner := probePort(ctx, result.IP, port, cfg.PJL, timeout)
+			if !open {
+				return
+			}
+			mu.Lock()
+			banners[port] = banner
+			mu.Unlock()
+		}(port)
+	}
+	wg.Wait()
+
+	if len(banners) == 0 {
+		return
+	}
+	for port := range banners {
+		result.OpenPorts = append(result.OpenPorts, port)
+	}
+	sort.Ints(result.OpenPorts)
+
+	// El ID de PJL es el nombre exacto del modelo; el título y el header
+	// Server del servidor web suelen incluirlo
+	var parts []string
+	for _, port := range result.OpenPorts {
+		if b := banners[port]; b != "" {
+			parts = append(parts, b)
+		}
+	}
+	result.Banner = strings.Join(parts, " | ")
+	if len(parts) > 0 {
+		result.ModelGuess = parts[0]
+	}
+	result.NoSNMP = true
+	result.DiscoveredAt = time.Now()
+}
+
+// probePort retorna si el puerto acepta conexiones y el banner que se pudo leer
+func probePort(ctx context.Context, ip string, port int, pjl bool, timeout time.Duration) (bool, string) {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false, ""
+	}
+
+	switch port {
+	case 9100:
+		defer conn.Close()
+		if !pjl {
+			return true, ""
+		}
+		return true, pjlInfoID(conn, timeout)
+	case 80, 631, 443, 8080:
+		conn.Close()
+		return true, httpBanner(ctx, ip, port, timeout)
+	}
+	conn.Close()
+	return true, ""
+}
+
+// pjlInfoID consulta @PJL INFO ID ("HP LaserJet M402dn" entre comillas)
+func pjlInfoID(conn net.Conn, timeout time.Duration) string {
+	conn.SetDeadline(time.Now().Add(timeout))
+	const uel = "\x1b%-12345X"
+	if _, err := fmt.Fprintf(conn, "%s@PJL INFO ID\r\n%s", uel, uel); err != nil {
+		return ""
+	}
+
+	reader := bufio.NewReader(io.LimitReader(conn, maxBannerBytes))
+	raw, _ := reader.ReadString('\f')
+	lines := strings.Split(strings.ReplaceAll(raw, "\r", ""), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "@PJL INFO ID") && i+1 < len(lines) {
+			return strings.Trim(strings.TrimSpace(lines[i+1]), "\"\f")
+		}
+	}
+	return ""
+}
+
+// httpBanner lee el <title> de la raíz del servidor web (o el header Server)
+func httpBanner(ctx context.Context, ip string, port int, timeout time.Duration) string {
+	scheme := "http"
+	if port == 443 {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+net.JoinHostPort(ip, strconv.Itoa(port))+"/", nil)
+	if err != nil {
+		return ""
+	}
+	client := &http.Client{
+		Timeout: timeout * 2,
+		Transport: &http.Transport{
+			// Los servidores embebidos usan certificados autofirmados
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBannerBytes))
+	if m := titlePattern.FindSubmatch(body); m != nil {
+		if title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " "); title != "" {
+			return title
+		}
+	}
+	return strings.TrimSpace(resp.Header.Get("Server"))
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+// Fases de un ciclo de escaneo
+const (
+	PhaseDiscovery  = "discovery"
+	PhaseCollection = "collection"
+)
+
+// Progress es el avance de una fase del escaneo
+type Progress struct {
+	Phase     string    `json:"phase"`
+	Scanned   int       `json:"scanned"`    // IPs/dispositivos terminados
+	Total     int       `json:"total"`      // IPs/dispositivos de la fase
+	Found     int       `json:"found"`      // respondieron (discovery) o se recolectaron completos (collection)
+	StartedAt time.Time `json:"started_at"` // inicio de la fase
+	ElapsedMs int64     `json:"elapsed_ms"` // tiempo transcurrido
+	ETAMs     int64     `json:"eta_ms"`     // tiempo restante estimado (0 = sin estimación)
+	Done      bool      `json:"done"`       // fase terminada
+}
+
+// ETA retorna el tiempo restante estimado (0 = sin estimación)
+func (p Progress) ETA() time.Duration {
+	return time.Duration(p.ETAMs) * time.Millisecond
+}
+
+// Percent retorna el avance en porcentaje (0-100)
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return float64(p.Scanned) * 100 / float64(p.Total)
+}
+
+// ProgressFunc recibe cada avance; se llama serializado, nunca en paralelo
+type ProgressFunc func(Progress)
+
+// ProgressTracker cuenta el avance de una fase desde workers concurrentes
+type ProgressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	report   ProgressFunc
+}
+
+// NewProgressTracker crea el tracker de una fase; report puede ser nil
+func NewProgressTracker(phase string, total int, report ProgressFunc) *ProgressTracker {
+	return &ProgressTracker{
+		progress: Progress{Phase: phase, Total: total, StartedAt: time.Now()},
+		report:   report,
+	}
+}
+
+// Step registra un elemento terminado (found = respondió o se recolectó completo)
+func (t *ProgressTracker) Step(found bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.Scanned++
+	if found {
+		t.progress.Found++
+	}
+	t.emit()
+}
+
+// Finish marca la fase como terminada
+func (t *ProgressTracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.Done = true
+	t.emit()
+}
+
+// emit calcula tiempos y ETA y avisa; se llama con t.mu tomado
+func (t *ProgressTracker) emit() {
+	p := &t.progress
+	elapsed := time.Since(p.StartedAt)
+	p.ElapsedMs = elapsed.Milliseconds()
+	p.ETAMs = 0
+	if p.Scanned > 0 && p.Scanned < p.Total && !p.Done {
+		// Ritmo medio hasta ahora: con un pool fijo de workers es estable
+		p.ETAMs = (elapsed / time.Duration(p.Scanned) * time.Duration(p.Total-p.Scanned)).Milliseconds()
+	}
+	if t.report != nil {
+		t.report(*p)
+	}
+}
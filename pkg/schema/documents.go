@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// BaseID es el prefijo del $id de los documentos publicados
+const BaseID = "https://schemas.printsnmp.dev/agent/"
+
+// Documentos publicados: un payload por tipo de archivo/evento que produce el agente
+var documents = map[string]struct {
+	title       string
+	description string
+	value       interface{}
+}{
+	"telemetry":         {"Telemetry", "Estado de una impresora en un poll (queue/ y sinks)", telemetry.Telemetry{}},
+	"heartbeat":         {"Heartbeat", "Auto-telemetría del agente al final de cada ciclo", telemetry.Heartbeat{}},
+	"printer_unchanged": {"NoChangeEvent", "Poll sin cambios respecto del último envío (sinks con emit on_change o delta)", telemetry.NoChangeEvent{}},
+	"inventory_event":   {"InventoryEvent", "Alta, baja o cambio de una impresora en el inventario", telemetry.InventoryEvent{}},
+	"command_result":    {"CommandResult", "Resultado de un comando remoto", telemetry.CommandResult{}},
+	"meter_report":      {"MeterReport", "Cierre de facturación (meters_<período>.json)", telemetry.MeterReport{}},
+	"scan_summary":      {"ScanSummary", "Resumen del ciclo (scan_summary.json)", telemetry.ScanSummary{}},
+	"snapshot":          {"Snapshot", "Telemetrías de un ciclo (output.dir/<ciclo>/printers.json)", []telemetry.Telemetry{}},
+	"printer_data":      {"PrinterData", "Datos crudos y normalizados del collector (record, dashboard)", collector.PrinterData{}},
+	"normalized_model":  {"NormalizedModel", "Modelo tipado de una impresora (identificación, estado, red, consumibles)", normalizedModel{}},
+}
+
+// normalizedModel agrupa el modelo tipado de PrinterData (sus campos no se serializan con PrinterData)
+type normalizedModel struct {
+	Identification collector.Identification `json:"identification"`
+	Status         collector.Status         `json:"status"`
+	Network        collector.Network        `json:"network"`
+	Supplies       []collector.Supply       `json:"supplies"`
+	Counters       collector.CountersInfo   `json:"counters"`
+}
+
+// Kinds retorna los documentos publicados, ordenados
+func Kinds() []string {
+	kinds := make([]string, 0, len(documents))
+	for kind := range documents {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// For genera el documento de un tipo de payload
+func For(kind string) (*Schema, error) {
+	doc, ok := documents[kind]
+	if !ok {
+		return nil, fmt.Errorf("schema desconocido %q", kind)
+	}
+	s := Generate(doc.value)
+	s.Draft = Draft
+	s.ID = BaseID + kind + ".schema.json"
+	s.Title = doc.title
+	s.Description = doc.description
+	return s, nil
+}
+
+// Detect deduce el tipo de un payload decodificado por sus campos de cabecera
+// Retorna "" si no lo reconoce
+func Detect(doc interface{}) string {
+	if _, ok := doc.([]interface{}); ok {
+		return "snapshot"
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	switch obj["event_type"] {
+	case "agent_heartbeat":
+		return "heartbeat"
+	case "inventory_change":
+		return "inventory_event"
+	case "agent_command_result":
+		return "command_result"
+	case "printer_unchanged":
+		return "printer_unchanged"
+	}
+	switch {
+	case obj["report_type"] == "meter_reads":
+		return "meter_report"
+	case has(obj, "printer", "collected_at"):
+		return "telemetry"
+	case has(obj, "scan", "generated_at"):
+		return "scan_summary"
+	case has(obj, "printerId"):
+		return "printer_data"
+	}
+	return ""
+}
+
+// has reporta si obj tiene todas las claves
+func has(obj map[string]interface{}, keys ...string) bool {
+	for _, k := range keys {
+		if _, ok := obj[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
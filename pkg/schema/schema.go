@@ -0,0 +1,185 @@
+// Package schema genera JSON Schema (draft 2020-12) a partir de los structs
+// que el agente serializa y valida payloads contra ellos, para que el agente
+// y el backend no diverjan en silencio en los tipos de un campo
+//
+// El schema se deriva de los tags json: un campo sin omitempty es requerido,
+// un puntero, slice o mapa admite null y los structs no admiten campos extra
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft es el dialecto de JSON Schema publicado
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema es un documento (o subdocumento) JSON Schema
+// Solo se usan las palabras clave que el generador emite
+type Schema struct {
+	Draft       string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type   []string `json:"-"` // se serializa como "type" (string si hay uno solo)
+	Format string   `json:"format,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"-"` // nil = permitidos (ver closed)
+	Items                *Schema            `json:"items,omitempty"`
+
+	closed bool // objeto sin propiedades extra (additionalProperties: false)
+}
+
+// Tipos JSON Schema
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// MarshalJSON emite "type" como string o arreglo y additionalProperties como bool o schema
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		Type                 interface{} `json:"type,omitempty"`
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+
+	switch len(s.Type) {
+	case 0:
+	case 1:
+		out.Type = s.Type[0]
+	default:
+		out.Type = s.Type
+	}
+	if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	} else if s.closed {
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generate deriva el schema del tipo de v (un valor o un puntero nil a él)
+func Generate(v interface{}) *Schema {
+	return generate(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// generate recorre t; seen corta tipos recursivos (quedan como "cualquier valor")
+func generate(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: []string{TypeString}, Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(generate(t.Elem(), seen))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: []string{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: []string{TypeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: []string{TypeNumber}}
+	case reflect.String:
+		return &Schema{Type: []string{TypeString}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{TypeString}} // []byte → base64
+		}
+		return nullable(&Schema{Type: []string{TypeArray}, Items: generate(t.Elem(), seen)})
+	case reflect.Array:
+		return &Schema{Type: []string{TypeArray}, Items: generate(t.Elem(), seen)}
+	case reflect.Map:
+		return nullable(&Schema{Type: []string{TypeObject}, AdditionalProperties: generate(t.Elem(), seen)})
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &Schema{Type: []string{TypeObject}, Properties: make(map[string]*Schema), closed: true}
+		addFields(s, t, seen)
+		return s
+	}
+	return &Schema{}
+}
+
+// addFields agrega las propiedades de un struct (los embebidos sin tag se aplanan, como en encoding/json)
+func addFields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = generate(field.Type, seen)
+		if !hasOption(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// hasOption busca una opción del tag json ("omitempty", "string")
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable agrega null a los tipos admitidos
+func nullable(s *Schema) *Schema {
+	if len(s.Type) == 0 {
+		return s // ya admite cualquier valor
+	}
+	for _, t := range s.Type {
+		if t == TypeNull {
+			return s
+		}
+	}
+	s.Type = append(s.Type, TypeNull)
+	return s
+}
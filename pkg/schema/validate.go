@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError es una diferencia entre un payload y su schema
+type ValidationError struct {
+	Path    string // "$.printer.ip"
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidateJSON valida un documento JSON contra s
+// Retorna un error si data no es JSON; las diferencias van en la lista
+func (s *Schema) ValidateJSON(data []byte) ([]ValidationError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // distinguir enteros de decimales
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return s.Validate(doc), nil
+}
+
+// Validate valida un valor decodificado (con json.Number para los números)
+func (s *Schema) Validate(doc interface{}) []ValidationError {
+	var errs []ValidationError
+	s.validate(doc, "$", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v interface{}, path string, errs *[]ValidationError) {
+	if len(s.Type) > 0 && !s.allows(jsonType(v)) {
+		*errs = append(*errs, ValidationError{path, fmt.Sprintf("tipo %s, se esperaba %s", jsonType(v), strings.Join(s.Type, " | "))})
+		return
+	}
+
+	switch value := v.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				*errs = append(*errs, ValidationError{path, fmt.Sprintf("%q no es date-time (RFC 3339)", value)})
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				*errs = append(*errs, ValidationError{path, fmt.Sprintf("falta el campo requerido %q", name)})
+			}
+		}
+
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) // errores en orden estable
+
+		for _, k := range keys {
+			child := path + "." + k
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(value[k], child, errs)
+				continue
+			}
+			switch {
+			case s.AdditionalProperties != nil:
+				s.AdditionalProperties.validate(value[k], child, errs)
+			case s.closed:
+				*errs = append(*errs, ValidationError{child, "campo no definido en el schema"})
+			}
+		}
+	}
+}
+
+// allows reporta si el schema admite el tipo JSON t (un entero también es number)
+func (s *Schema) allows(t string) bool {
+	for _, allowed := range s.Type {
+		if allowed == t || (allowed == TypeNumber && t == TypeInteger) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType retorna el tipo JSON Schema de un valor decodificado
+func jsonType(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return TypeInteger
+		}
+		return TypeNumber
+	case float64:
+		if value == float64(int64(value)) {
+			return TypeInteger
+		}
+		return TypeNumber
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/internal/pbkdf2"
+)
+
+// PassphraseEnv permite derivar la clave maestra de una passphrase
+// (útil en contenedores o servidores sin keyring ni DPAPI)
+const PassphraseEnv = "PRINTSNMP_VAULT_PASSPHRASE"
+
+// Orígenes posibles de la clave maestra
+const (
+	KeySourcePassphrase = "passphrase"
+	KeySourceDPAPI      = "dpapi"
+	KeySourceKeyring    = "keyring"
+	KeySourceKeyFile    = "keyfile"
+)
+
+// ErrKeyNotFound se retorna cuando el vault ya tiene secretos pero no se
+// encontró su clave maestra (keyring no disponible, otra sesión de usuario,
+// <vault>.key borrado o passphrase sin su sal)
+var ErrKeyNotFound = errors.New("no se encontró la clave maestra del vault")
+
+const (
+	keySize          = 32 // AES-256
+	pbkdf2Iterations = 600000
+)
+
+// masterKey obtiene la clave maestra del vault en vaultPath
+// Prioridad: passphrase (env) → almacenamiento del SO (platformKey)
+func masterKey(vaultPath string) ([]byte, string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		salt, err := loadOrCreateSalt(vaultPath)
+		if err != nil {
+			return nil, "", err
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, keySize), KeySourcePassphrase, nil
+	}
+
+	return platformKey(vaultPath)
+}
+
+// newRandomKey genera una clave maestra nueva
+func newRandomKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// loadOrCreateSalt lee la sal de la passphrase (hex) o crea una nueva
+// La sal no es secreta, solo evita tablas precalculadas
+func loadOrCreateSalt(vaultPath string) ([]byte, error) {
+	path := vaultPath + ".salt"
+	if data, err := os.ReadFile(path); err == nil {
+		salt, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("sal corrupta %s: %w", path, err)
+		}
+		return salt, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := checkNoEntries(vaultPath); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(salt)), 0600); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// readKeyFile lee la clave de un archivo 0600 (os.IsNotExist si no existe)
+// Último recurso cuando el SO no ofrece un almacén de claves
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("archivo de clave inválido %s", path)
+	}
+	return key, nil
+}
+
+// writeKeyFile crea el archivo de clave (falla si ya existe: nunca lo reemplaza)
+func writeKeyFile(path string, key []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(hex.EncodeToString(key)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkNoEntries falla si el vault en vaultPath ya tiene secretos
+// Se llama antes de crear una clave maestra (o sal) nueva: con otra clave los
+// secretos existentes quedarían ilegibles
+func checkNoEntries(vaultPath string) error {
+	data, err := os.ReadFile(vaultPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("vault corrupto %s: %w", vaultPath, err)
+	}
+	if len(file.Entries) > 0 {
+		return fmt.Errorf("%w: %s tiene %d secretos", ErrKeyNotFound, vaultPath, len(file.Entries))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// keyringService identifica la clave maestra en el keyring del SO
+const keyringService = "printsnmp-vault"
+
+// keyringLookup y keyringStore acceden al keyring del SO (variables para
+// poder reemplazarlas en los tests)
+var (
+	keyringLookup = osKeyringLookup
+	keyringStore  = osKeyringStore
+)
+
+// platformKey guarda la clave maestra en el keyring del SO
+// (Keychain en macOS vía `security`, Secret Service en Linux vía `secret-tool`)
+// Sin keyring disponible (servidores headless) usa <vault>.key con permisos 0600
+// Si existe <vault>.key tiene prioridad: solo se crea cuando el keyring falló
+func platformKey(vaultPath string) ([]byte, string, error) {
+	keyPath := vaultPath + ".key"
+	if key, err := readKeyFile(keyPath); err == nil {
+		return key, KeySourceKeyFile, nil
+	} else if !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	account, err := filepath.Abs(vaultPath)
+	if err != nil {
+		account = vaultPath
+	}
+
+	if key, ok := keyringLookup(account); ok {
+		return key, KeySourceKeyring, nil
+	}
+
+	// Sin clave a la vista: una nueva dejaría ilegibles los secretos guardados
+	if err := checkNoEntries(vaultPath); err != nil {
+		return nil, "", err
+	}
+
+	key, err := newRandomKey()
+	if err != nil {
+		return nil, "", err
+	}
+	if keyringStore(account, key) {
+		return key, KeySourceKeyring, nil
+	}
+
+	if err := writeKeyFile(keyPath, key); err != nil {
+		return nil, "", err
+	}
+	return key, KeySourceKeyFile, nil
+}
+
+// osKeyringLookup lee la clave del keyring (false si no hay keyring o no existe)
+func osKeyringLookup(account string) ([]byte, bool) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+	default:
+		return nil, false
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil || len(key) != keySize {
+		return nil, false
+	}
+	return key, true
+}
+
+// osKeyringStore guarda la clave en el keyring (false si no hay keyring disponible)
+// Nunca reemplaza una entrada existente: si la hay y no se pudo leer, falla
+func osKeyringStore(account string, key []byte) bool {
+	encoded := hex.EncodeToString(key)
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// En modo interactivo (-i) el comando llega por stdin y la clave no
+		// aparece en la línea de comandos (visible en ps)
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %s -a %s -w %s\n",
+			keyringService, securityQuote(account), encoded))
+	case "linux":
+		// secret-tool lee el secreto de stdin (no queda en la línea de comandos)
+		cmd = exec.Command("secret-tool", "store", "--label", fmt.Sprintf("printsnmp vault (%s)", account),
+			"service", keyringService, "account", account)
+		cmd.Stdin = bytes.NewBufferString(encoded)
+	default:
+		return false
+	}
+
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+
+	// Verificar que el keyring realmente la guardó (ej: colección bloqueada)
+	stored, ok := osKeyringLookup(account)
+	return ok && bytes.Equal(stored, key)
+}
+
+// securityQuote entrecomilla un argumento para `security -i`
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,138 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeKeyring reemplaza el keyring del SO durante el test
+// available=false simula un servidor headless o un keyring bloqueado
+type fakeKeyring struct {
+	available bool
+	keys      map[string][]byte
+}
+
+func useFakeKeyring(t *testing.T, available bool) *fakeKeyring {
+	t.Helper()
+	t.Setenv(PassphraseEnv, "")
+	k := &fakeKeyring{available: available, keys: make(map[string][]byte)}
+	lookup, store := keyringLookup, keyringStore
+	keyringLookup = func(account string) ([]byte, bool) {
+		key, ok := k.keys[account]
+		return key, k.available && ok
+	}
+	keyringStore = func(account string, key []byte) bool {
+		if !k.available {
+			return false
+		}
+		k.keys[account] = key
+		return true
+	}
+	t.Cleanup(func() { keyringLookup, keyringStore = lookup, store })
+	return k
+}
+
+func TestPlatformKeyKeyring(t *testing.T) {
+	k := useFakeKeyring(t, true)
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	key, source, err := platformKey(path)
+	if err != nil || source != KeySourceKeyring {
+		t.Fatalf("platformKey: %q, %v", source, err)
+	}
+	if len(k.keys) != 1 {
+		t.Errorf("%d claves en el keyring, se esperaba 1", len(k.keys))
+	}
+	if _, err := os.Stat(path + ".key"); !os.IsNotExist(err) {
+		t.Error("se creó <vault>.key con keyring disponible")
+	}
+
+	again, _, err := platformKey(path)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Errorf("la segunda lectura dio otra clave (%v)", err)
+	}
+}
+
+func TestPlatformKeyKeyringUnavailable(t *testing.T) {
+	k := useFakeKeyring(t, false)
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	v, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.KeySource() != KeySourceKeyFile {
+		t.Errorf("origen %q, se esperaba %q", v.KeySource(), KeySourceKeyFile)
+	}
+	info, err := os.Stat(path + ".key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permisos %o, se esperaba 600", perm)
+	}
+	if err := v.Set("snmp_community", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Si el keyring vuelve, <vault>.key sigue mandando y no se crea otra clave
+	k.available = true
+	v, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := v.Get("snmp_community"); err != nil || got != "s3cr3t" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if len(k.keys) != 0 {
+		t.Error("se guardó una clave nueva en el keyring habiendo <vault>.key")
+	}
+}
+
+func TestPlatformKeyMissingWithEntries(t *testing.T) {
+	k := useFakeKeyring(t, true)
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	v, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("snmp_community", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Otra sesión sin acceso al keyring: falla en vez de crear otra clave
+	k.available = false
+	if _, err := Open(path); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("error %v, se esperaba ErrKeyNotFound", err)
+	}
+	if _, err := os.Stat(path + ".key"); !os.IsNotExist(err) {
+		t.Error("se creó <vault>.key para un vault con secretos")
+	}
+
+	// Con el keyring de vuelta la clave original sigue ahí
+	k.available = true
+	v, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := v.Get("snmp_community"); err != nil || got != "s3cr3t" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+}
+
+func TestPlatformKeyInvalidKeyFile(t *testing.T) {
+	useFakeKeyring(t, true)
+	path := filepath.Join(t.TempDir(), "vault.json")
+	if err := os.WriteFile(path+".key", []byte("no es hex"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := platformKey(path); err == nil {
+		t.Error("se aceptó un <vault>.key inválido")
+	}
+}
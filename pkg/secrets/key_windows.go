@@ -0,0 +1,100 @@
+//go:build windows
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// DPAPI (CryptProtectData) vía syscall, sin dependencias externas
+var (
+	crypt32                = syscall.NewLazyDLL("crypt32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = crypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = kernel32.NewProc("LocalFree")
+)
+
+// cryptProtectLocalMachine permite descifrar con cualquier cuenta del equipo
+// (el agente corre como servicio y el comando secrets como administrador)
+const cryptProtectLocalMachine = 0x4
+
+// dataBlob es DATA_BLOB de wincrypt.h
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newBlob(d []byte) *dataBlob {
+	if len(d) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{cbData: uint32(len(d)), pbData: &d[0]}
+}
+
+func (b *dataBlob) bytes() []byte {
+	out := make([]byte, b.cbData)
+	copy(out, unsafe.Slice(b.pbData, b.cbData))
+	return out
+}
+
+// platformKey guarda la clave maestra en <vault>.key protegida con DPAPI
+// El archivo solo se puede descifrar en este equipo
+func platformKey(vaultPath string) ([]byte, string, error) {
+	keyPath := vaultPath + ".key"
+
+	if protected, err := os.ReadFile(keyPath); err == nil {
+		key, err := dpapiUnprotect(protected)
+		if err != nil {
+			return nil, "", fmt.Errorf("DPAPI no pudo descifrar %s: %w", keyPath, err)
+		}
+		return key, KeySourceDPAPI, nil
+	} else if !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	// Sin clave: una nueva dejaría ilegibles los secretos guardados
+	if err := checkNoEntries(vaultPath); err != nil {
+		return nil, "", err
+	}
+
+	key, err := newRandomKey()
+	if err != nil {
+		return nil, "", err
+	}
+	protected, err := dpapiProtect(key)
+	if err != nil {
+		return nil, "", fmt.Errorf("DPAPI no pudo cifrar la clave: %w", err)
+	}
+	if err := os.WriteFile(keyPath, protected, 0600); err != nil {
+		return nil, "", err
+	}
+	return key, KeySourceDPAPI, nil
+}
+
+func dpapiProtect(data []byte) ([]byte, error) {
+	var out dataBlob
+	r, _, err := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(newBlob(data))), 0, 0, 0, 0,
+		cryptProtectLocalMachine, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+	return out.bytes(), nil
+}
+
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	var out dataBlob
+	r, _, err := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(newBlob(data))), 0, 0, 0, 0,
+		0, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+	return out.bytes(), nil
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import "strings"
+
+// RefPrefix marca un valor de config.yaml como referencia al vault
+// Ej: community: "secret:snmp.community"
+const RefPrefix = "secret:"
+
+// Nombres convencionales de los secretos del agente
+const (
+	SNMPCommunity     = "snmp.community"
+	SNMPv3AuthPass    = "snmp.v3.auth_passphrase"
+	SNMPv3PrivPass    = "snmp.v3.priv_passphrase"
+	HTTPSinkAuthToken = "sinks.http.auth_token"
+	BillingSigningKey = "billing.signing_key"
+	WebAPIKeyPrefix   = "web.api_key." // + nombre de la llave: "web.api_key.helpdesk"
+)
+
+// IsRef indica si el valor es una referencia "secret:<nombre>"
+func IsRef(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), RefPrefix)
+}
+
+// RefName retorna el nombre referenciado por "secret:<nombre>"
+func RefName(value string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), RefPrefix))
+}
+
+// Resolve retorna el valor en claro: si value es una referencia la busca en el
+// vault, si no la retorna tal cual (compatibilidad con config.yaml en texto plano)
+func (v *Vault) Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	return v.Get(RefName(value))
+}
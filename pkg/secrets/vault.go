@@ -0,0 +1,187 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// Vault guarda secretos (communities SNMP, passphrases v3, tokens HTTP)
+// cifrados en disco con AES-256-GCM
+// La clave maestra nunca se guarda junto al vault en claro: la entrega un
+// KeySource (DPAPI en Windows, keyring del SO en Linux/macOS o passphrase)
+type Vault struct {
+	path   string
+	key    []byte
+	source string // origen de la clave maestra (dpapi, keyring, passphrase, keyfile)
+
+	mu      sync.Mutex
+	entries map[string]string // nombre → base64(nonce || ciphertext)
+}
+
+// vaultFile es el formato en disco del vault
+type vaultFile struct {
+	Version int               `json:"version"`
+	Entries map[string]string `json:"entries"`
+}
+
+// ErrNotFound se retorna cuando el secreto no existe en el vault
+var ErrNotFound = errors.New("secreto no encontrado")
+
+// Open abre (o crea vacío) el vault en path y obtiene su clave maestra
+func Open(path string) (*Vault, error) {
+	key, source, err := masterKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo clave del vault: %w", err)
+	}
+
+	v := &Vault{
+		path:    path,
+		key:     key,
+		source:  source,
+		entries: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return v, nil // vault nuevo
+		}
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("vault corrupto %s: %w", path, err)
+	}
+	if file.Entries != nil {
+		v.entries = file.Entries
+	}
+
+	return v, nil
+}
+
+// KeySource indica de dónde se obtuvo la clave maestra
+func (v *Vault) KeySource() string {
+	return v.source
+}
+
+// Get descifra y retorna un secreto
+func (v *Vault) Get(name string) (string, error) {
+	v.mu.Lock()
+	sealed, ok := v.entries[name]
+	v.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("secreto %s corrupto: %w", name, err)
+	}
+
+	gcm, err := v.aead()
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("secreto %s corrupto: demasiado corto", name)
+	}
+
+	nonce, ciphertext := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	// El nombre va como dato adicional: no se puede mover un secreto a otra entrada
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("no se pudo descifrar %s (¿clave maestra distinta?): %w", name, err)
+	}
+
+	return string(plain), nil
+}
+
+// Set cifra y guarda un secreto (sobrescribe si existe)
+func (v *Vault) Set(name, value string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("nombre de secreto vacío")
+	}
+
+	gcm, err := v.aead()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	v.mu.Lock()
+	v.entries[name] = base64.StdEncoding.EncodeToString(sealed)
+	v.mu.Unlock()
+
+	return v.save()
+}
+
+// Delete elimina un secreto
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	if _, ok := v.entries[name]; !ok {
+		v.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(v.entries, name)
+	v.mu.Unlock()
+
+	return v.save()
+}
+
+// Names retorna los nombres de los secretos guardados (ordenados, sin valores)
+func (v *Vault) Names() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	names := make([]string, 0, len(v.entries))
+	for name := range v.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// aead construye el cifrador AES-256-GCM con la clave maestra
+func (v *Vault) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// save escribe el vault de forma atómica con permisos 0600
+func (v *Vault) save() error {
+	v.mu.Lock()
+	data, err := json.MarshalIndent(vaultFile{Version: 1, Entries: v.entries}, "", "  ")
+	v.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(v.path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return fsutil.WriteFileAtomic(v.path, data, 0600)
+}
@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultRoundTrip(t *testing.T) {
+	t.Setenv(PassphraseEnv, "correcta")
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	v, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.KeySource() != KeySourcePassphrase {
+		t.Errorf("origen %q, se esperaba %q", v.KeySource(), KeySourcePassphrase)
+	}
+	if err := v.Set("snmp_community", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("http_token", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Delete("http_token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reabrir desde disco
+	v, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := v.Get("snmp_community"); err != nil || got != "s3cr3t" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if _, err := v.Get("http_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("secreto borrado: %v, se esperaba ErrNotFound", err)
+	}
+	if names := v.Names(); len(names) != 1 || names[0] != "snmp_community" {
+		t.Errorf("Names = %v", names)
+	}
+}
+
+func TestVaultWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	t.Setenv(PassphraseEnv, "correcta")
+	v, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("snmp_community", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PassphraseEnv, "otra")
+	v, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Get("snmp_community"); err == nil {
+		t.Error("se descifró con otra passphrase")
+	}
+}
+
+func TestVaultEntryBoundToName(t *testing.T) {
+	t.Setenv(PassphraseEnv, "correcta")
+	v, err := Open(filepath.Join(t.TempDir(), "vault.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("a", "valor"); err != nil {
+		t.Fatal(err)
+	}
+	// Mover el cifrado a otra entrada no debe descifrar
+	v.entries["b"] = v.entries["a"]
+	if _, err := v.Get("b"); err == nil {
+		t.Error("se descifró un secreto movido a otra entrada")
+	}
+}
+
+func TestVaultLostSaltWithEntries(t *testing.T) {
+	t.Setenv(PassphraseEnv, "correcta")
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("snmp_community", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sin la sal la passphrase daría otra clave: no se crea una nueva
+	if err := os.Remove(path + ".salt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("error %v, se esperaba ErrKeyNotFound", err)
+	}
+	if _, err := os.Stat(path + ".salt"); !os.IsNotExist(err) {
+		t.Error("se creó una sal nueva para un vault con secretos")
+	}
+}
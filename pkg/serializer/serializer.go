@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/asaavedra/agent-snmp/pkg/collector"
 	"github.com/asaavedra/agent-snmp/pkg/telemetry"
 )
 
@@ -27,6 +28,83 @@ func (s *Serializer) Serialize(t *telemetry.Telemetry) ([]byte, error) {
 		return nil, fmt.Errorf("telemetry cannot be nil")
 	}
 
+	return encode(t, "telemetry")
+}
+
+// SerializeHeartbeat convierte el heartbeat del agente a JSON bytes (mismo formato)
+func (s *Serializer) SerializeHeartbeat(h *telemetry.Heartbeat) ([]byte, error) {
+	if h == nil {
+		return nil, fmt.Errorf("heartbeat cannot be nil")
+	}
+
+	return encode(h, "heartbeat")
+}
+
+// SerializeNoChange convierte el aviso de un poll sin cambios a JSON bytes (mismo formato)
+func (s *Serializer) SerializeNoChange(e *telemetry.NoChangeEvent) ([]byte, error) {
+	if e == nil {
+		return nil, fmt.Errorf("no-change event cannot be nil")
+	}
+
+	return encode(e, "no-change event")
+}
+
+// SerializeInventoryEvent convierte un evento de inventario a JSON bytes (mismo formato)
+func (s *Serializer) SerializeInventoryEvent(e *telemetry.InventoryEvent) ([]byte, error) {
+	if e == nil {
+		return nil, fmt.Errorf("inventory event cannot be nil")
+	}
+
+	return encode(e, "inventory event")
+}
+
+// SerializeScanSummary convierte el resumen del escaneo a JSON bytes (mismo formato)
+func (s *Serializer) SerializeScanSummary(summary *telemetry.ScanSummary) ([]byte, error) {
+	if summary == nil {
+		return nil, fmt.Errorf("scan summary cannot be nil")
+	}
+
+	return encode(summary, "scan summary")
+}
+
+// SerializeSnapshot convierte las telemetrías de un ciclo a un arreglo JSON (mismo formato)
+func (s *Serializer) SerializeSnapshot(list []*telemetry.Telemetry) ([]byte, error) {
+	if list == nil {
+		list = []*telemetry.Telemetry{}
+	}
+
+	return encode(list, "snapshot")
+}
+
+// SerializeRawSnapshot convierte los PrinterData de un ciclo a un arreglo JSON (mismo formato)
+func (s *Serializer) SerializeRawSnapshot(list []collector.PrinterData) ([]byte, error) {
+	if list == nil {
+		list = []collector.PrinterData{}
+	}
+
+	return encode(list, "raw snapshot")
+}
+
+// SerializeMeterReport convierte el reporte de cierre de facturación a JSON bytes (mismo formato)
+func (s *Serializer) SerializeMeterReport(r *telemetry.MeterReport) ([]byte, error) {
+	if r == nil {
+		return nil, fmt.Errorf("meter report cannot be nil")
+	}
+
+	return encode(r, "meter report")
+}
+
+// SerializeCommandResult convierte el resultado de un comando remoto a JSON bytes (mismo formato)
+func (s *Serializer) SerializeCommandResult(r *telemetry.CommandResult) ([]byte, error) {
+	if r == nil {
+		return nil, fmt.Errorf("command result cannot be nil")
+	}
+
+	return encode(r, "command result")
+}
+
+// encode serializa v con el formato común de todos los eventos
+func encode(v interface{}, kind string) ([]byte, error) {
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
 
@@ -36,8 +114,8 @@ func (s *Serializer) Serialize(t *telemetry.Telemetry) ([]byte, error) {
 	// Indentación de 2 espacios para legibilidad
 	encoder.SetIndent("", "  ")
 
-	if err := encoder.Encode(t); err != nil {
-		return nil, fmt.Errorf("failed to serialize telemetry: %w", err)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to serialize %s: %w", kind, err)
 	}
 
 	// Encode agrega un newline final, lo removemos
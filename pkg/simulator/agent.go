@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxBulkRepetitions tope de repeticiones por GETBULK para no exceder un datagrama
+const maxBulkRepetitions = 50
+
+// Agent es un agente SNMP v1/v2c en memoria que responde desde un Fixture
+// Permite correr integración de punta a punta (collector, profile, telemetry)
+// sin impresoras reales
+type Agent struct {
+	fixture *Fixture
+	pdus    []gosnmp.SnmpPDU // ordenados por OID
+	conn    *net.UDPConn
+	wg      sync.WaitGroup
+}
+
+// NewAgent crea un agente a partir de un fixture
+func NewAgent(f *Fixture) (*Agent, error) {
+	f.Sort()
+
+	pdus := make([]gosnmp.SnmpPDU, 0, len(f.Variables))
+	for _, v := range f.Variables {
+		pdu, err := v.toPDU()
+		if err != nil {
+			return nil, fmt.Errorf("OID %s inválido en fixture %s: %w", v.OID, f.Name, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+
+	return &Agent{fixture: f, pdus: pdus}, nil
+}
+
+// Start escucha en addr (ej: "127.0.0.1:0" para puerto aleatorio)
+func (a *Agent) Start(addr string) error {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return fmt.Errorf("dirección inválida %s: %w", addr, err)
+	}
+
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return fmt.Errorf("error escuchando en %s: %w", addr, err)
+	}
+	a.conn = conn
+
+	a.wg.Add(1)
+	go a.serve()
+
+	return nil
+}
+
+// Addr retorna host y puerto donde escucha el agente
+func (a *Agent) Addr() (string, uint16) {
+	if a.conn == nil {
+		return "", 0
+	}
+	udpAddr := a.conn.LocalAddr().(*net.UDPAddr)
+	return udpAddr.IP.String(), uint16(udpAddr.Port)
+}
+
+// Close detiene el agente
+func (a *Agent) Close() error {
+	if a.conn == nil {
+		return nil
+	}
+	err := a.conn.Close()
+	a.wg.Wait()
+	return err
+}
+
+// serve atiende requests hasta que se cierre la conexión
+func (a *Agent) serve() {
+	defer a.wg.Done()
+
+	buf := make([]byte, 65535)
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Logger: gosnmp.NewLogger(nil)}
+
+	for {
+		n, remote, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		request, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		// Community incorrecta: un agente real no responde
+		if a.fixture.Community != "" && request.Community != a.fixture.Community {
+			continue
+		}
+
+		response := a.handle(request)
+		out, err := response.MarshalMsg()
+		if err != nil {
+			continue
+		}
+		_, _ = a.conn.WriteToUDP(out, remote)
+	}
+}
+
+// handle construye la respuesta a un GET/GETNEXT/GETBULK
+func (a *Agent) handle(request *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	response := &gosnmp.SnmpPacket{
+		Version:   request.Version,
+		Community: request.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: request.RequestID,
+	}
+
+	switch request.PDUType {
+	case gosnmp.GetRequest:
+		for i, v := range request.Variables {
+			pdu, ok := a.get(v.Name)
+			if !ok && request.Version == gosnmp.Version1 {
+				return v1Error(response, request, gosnmp.NoSuchName, i)
+			}
+			response.Variables = append(response.Variables, pdu)
+		}
+
+	case gosnmp.GetNextRequest:
+		for i, v := range request.Variables {
+			pdu, ok := a.next(v.Name)
+			if !ok && request.Version == gosnmp.Version1 {
+				return v1Error(response, request, gosnmp.NoSuchName, i)
+			}
+			response.Variables = append(response.Variables, pdu)
+		}
+
+	case gosnmp.GetBulkRequest:
+		nonRepeaters := int(request.NonRepeaters)
+		if nonRepeaters > len(request.Variables) {
+			nonRepeaters = len(request.Variables)
+		}
+		repetitions := int(request.MaxRepetitions)
+		if repetitions > maxBulkRepetitions {
+			repetitions = maxBulkRepetitions
+		}
+
+		for _, v := range request.Variables[:nonRepeaters] {
+			pdu, _ := a.next(v.Name)
+			response.Variables = append(response.Variables, pdu)
+		}
+		for _, v := range request.Variables[nonRepeaters:] {
+			name := v.Name
+			for r := 0; r < repetitions; r++ {
+				pdu, ok := a.next(name)
+				response.Variables = append(response.Variables, pdu)
+				if !ok {
+					break
+				}
+				name = pdu.Name
+			}
+		}
+
+	default:
+		response.Error = gosnmp.GenErr
+		response.Variables = request.Variables
+	}
+
+	return response
+}
+
+// get busca un OID exacto
+func (a *Agent) get(oid string) (gosnmp.SnmpPDU, bool) {
+	idx := a.search(oid)
+	if idx < len(a.pdus) && CompareOIDs(a.pdus[idx].Name, oid) == 0 {
+		return a.pdus[idx], true
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}, false
+}
+
+// next busca el primer OID estrictamente mayor
+func (a *Agent) next(oid string) (gosnmp.SnmpPDU, bool) {
+	idx := a.search(oid)
+	if idx < len(a.pdus) && CompareOIDs(a.pdus[idx].Name, oid) == 0 {
+		idx++
+	}
+	if idx < len(a.pdus) {
+		return a.pdus[idx], true
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}, false
+}
+
+// search retorna el índice del primer OID >= oid (búsqueda binaria)
+func (a *Agent) search(oid string) int {
+	lo, hi := 0, len(a.pdus)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if CompareOIDs(a.pdus[mid].Name, oid) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// v1Error arma una respuesta de error SNMPv1 (devuelve las variables originales)
+func v1Error(response, request *gosnmp.SnmpPacket, status gosnmp.SNMPError, index int) *gosnmp.SnmpPacket {
+	response.Error = status
+	response.ErrorIndex = uint8(index + 1)
+	response.Variables = nil
+	for _, v := range request.Variables {
+		response.Variables = append(response.Variables, gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.Null})
+	}
+	return response
+}
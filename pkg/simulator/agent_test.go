@@ -0,0 +1,94 @@
+package simulator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// startAgent levanta el simulador de un fixture incluido en un puerto libre
+func startAgent(t *testing.T, name, community string) (*Fixture, string, uint16) {
+	t.Helper()
+	f, err := Builtin(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Community = community
+
+	agent, err := NewAgent(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { agent.Close() })
+
+	host, port := agent.Addr()
+	return f, host, port
+}
+
+// TestRecordReplaysFixture graba cada simulador y compara con su fixture:
+// lo que responde el agente (GET, GETNEXT, GETBULK y tipos) es lo grabado
+func TestRecordReplaysFixture(t *testing.T) {
+	engine := snmp.NewEngine(snmp.EngineConfig{MaxWorkers: 4})
+
+	for _, name := range BuiltinNames() {
+		t.Run(name, func(t *testing.T) {
+			f, host, port := startAgent(t, name, "public")
+			client := engine.NewClient(host, port, "public", "2c", 2*time.Second, 1)
+
+			recorded, err := Record(client, name, f.Brand, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []Variable
+			for _, v := range f.Variables {
+				if inRoots(v.OID, RecordRoots) {
+					want = append(want, v)
+				}
+			}
+			if len(recorded.Variables) != len(want) {
+				t.Fatalf("se grabaron %d variables, el fixture tiene %d", len(recorded.Variables), len(want))
+			}
+			for i, got := range recorded.Variables {
+				if got != want[i] {
+					t.Errorf("variable %d: grabada %+v, fixture %+v", i, got, want[i])
+				}
+			}
+			if recorded.Model != f.Model {
+				t.Errorf("modelo %q, se esperaba %q", recorded.Model, f.Model)
+			}
+		})
+	}
+}
+
+// TestAgentIgnoresWrongCommunity: como un equipo real, no responde
+func TestAgentIgnoresWrongCommunity(t *testing.T) {
+	_, host, port := startAgent(t, "hp_laserjet_m402", "secreta")
+	engine := snmp.NewEngine(snmp.EngineConfig{MaxWorkers: 1})
+
+	if _, err := engine.NewClient(host, port, "public", "2c", 200*time.Millisecond, 0).Get("1.3.6.1.2.1.1.1.0", snmp.NewContext()); err == nil {
+		t.Error("respondió a una community incorrecta")
+	}
+	v, err := engine.NewClient(host, port, "secreta", "2c", 2*time.Second, 0).Get("1.3.6.1.2.1.1.1.0", snmp.NewContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descr := fmt.Sprintf("%v", v); !strings.HasPrefix(descr, "HP ETHERNET") {
+		t.Errorf("sysDescr = %q", descr)
+	}
+}
+
+func inRoots(oid string, roots []string) bool {
+	for _, root := range roots {
+		if oid == root || strings.HasPrefix(oid, root+".") {
+			return true
+		}
+	}
+	return false
+}
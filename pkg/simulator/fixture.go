@@ -0,0 +1,250 @@
+package simulator
+
+import (
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+//go:embed fixtures/*.json
+var builtinFixtures embed.FS
+
+// Fixture es un dataset de OIDs grabado de un dispositivo real
+// Es portable (JSON) para que usuarios puedan adjuntarlo a reportes de bugs
+type Fixture struct {
+	Name       string     `json:"name"`                // "hp_laserjet_m402"
+	Brand      string     `json:"brand"`               // "HP"
+	Model      string     `json:"model,omitempty"`     // "HP LaserJet Pro M402dn"
+	Community  string     `json:"community,omitempty"` // community aceptada por el simulador ("" = cualquiera)
+	RecordedAt time.Time  `json:"recorded_at"`
+	Variables  []Variable `json:"variables"`
+}
+
+// Variable es un OID con su tipo SNMP preservado
+type Variable struct {
+	OID   string `json:"oid"`
+	Type  string `json:"type"`          // integer, octet_string, oid, ip, counter32, gauge32, timeticks, counter64
+	Value string `json:"value"`         // valor textual (o hex si Hex=true)
+	Hex   bool   `json:"hex,omitempty"` // true si Value es hex de bytes binarios (MAC, bitfields)
+}
+
+// LoadFixture carga un fixture desde disco
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo fixture %s: %w", path, err)
+	}
+	return parseFixture(data)
+}
+
+// Builtin carga uno de los fixtures incluidos en el binario (HP, Xerox, Samsung, Lexmark, Epson, Sharp, Toshiba, Konica Minolta)
+func Builtin(name string) (*Fixture, error) {
+	data, err := builtinFixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("fixture no encontrado: %s", name)
+	}
+	return parseFixture(data)
+}
+
+// BuiltinNames lista los fixtures incluidos
+func BuiltinNames() []string {
+	entries, err := builtinFixtures.ReadDir("fixtures")
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseFixture(data []byte) (*Fixture, error) {
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error parseando fixture: %w", err)
+	}
+	f.Sort()
+	return &f, nil
+}
+
+// Save guarda el fixture en disco (JSON indentado)
+func (f *Fixture) Save(path string) error {
+	f.Sort()
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando fixture: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error escribiendo fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// Sort ordena las variables en orden lexicográfico de OID (como un agente SNMP)
+func (f *Fixture) Sort() {
+	for i := range f.Variables {
+		f.Variables[i].OID = strings.TrimPrefix(f.Variables[i].OID, ".")
+	}
+	sort.SliceStable(f.Variables, func(i, j int) bool {
+		return CompareOIDs(f.Variables[i].OID, f.Variables[j].OID) < 0
+	})
+}
+
+// Add agrega (o reemplaza) una variable a partir de un PDU de gosnmp
+func (f *Fixture) Add(pdu gosnmp.SnmpPDU) {
+	v, ok := variableFromPDU(pdu)
+	if !ok {
+		return
+	}
+
+	for i := range f.Variables {
+		if f.Variables[i].OID == v.OID {
+			f.Variables[i] = v
+			return
+		}
+	}
+	f.Variables = append(f.Variables, v)
+}
+
+// CompareOIDs compara dos OIDs numéricamente componente a componente
+func CompareOIDs(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "."), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "."), ".")
+
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.ParseUint(pa[i], 10, 64)
+		nb, _ := strconv.ParseUint(pb[i], 10, 64)
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return len(pa) - len(pb)
+}
+
+// variableFromPDU convierte un PDU a Variable preservando el tipo
+func variableFromPDU(pdu gosnmp.SnmpPDU) (Variable, bool) {
+	v := Variable{OID: strings.TrimPrefix(pdu.Name, ".")}
+
+	switch pdu.Type {
+	case gosnmp.Integer:
+		v.Type = "integer"
+		v.Value = fmt.Sprintf("%d", gosnmp.ToBigInt(pdu.Value).Int64())
+	case gosnmp.OctetString:
+		v.Type = "octet_string"
+		b, _ := pdu.Value.([]byte)
+		if isPrintable(b) {
+			v.Value = string(b)
+		} else {
+			v.Value = hex.EncodeToString(b)
+			v.Hex = true
+		}
+	case gosnmp.ObjectIdentifier:
+		v.Type = "oid"
+		v.Value = strings.TrimPrefix(fmt.Sprintf("%v", pdu.Value), ".")
+	case gosnmp.IPAddress:
+		v.Type = "ip"
+		v.Value = fmt.Sprintf("%v", pdu.Value)
+	case gosnmp.Counter32:
+		v.Type = "counter32"
+		v.Value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.Gauge32:
+		v.Type = "gauge32"
+		v.Value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.TimeTicks:
+		v.Type = "timeticks"
+		v.Value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.Counter64:
+		v.Type = "counter64"
+		v.Value = gosnmp.ToBigInt(pdu.Value).String()
+	default:
+		// NoSuchObject, EndOfMibView, Null, etc: no se graban
+		return v, false
+	}
+
+	return v, true
+}
+
+// toPDU convierte una Variable a PDU de gosnmp para responder
+func (v Variable) toPDU() (gosnmp.SnmpPDU, error) {
+	pdu := gosnmp.SnmpPDU{Name: "." + v.OID}
+
+	switch v.Type {
+	case "integer":
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return pdu, err
+		}
+		pdu.Type, pdu.Value = gosnmp.Integer, n
+	case "octet_string":
+		if v.Hex {
+			b, err := hex.DecodeString(v.Value)
+			if err != nil {
+				return pdu, err
+			}
+			pdu.Type, pdu.Value = gosnmp.OctetString, b
+		} else {
+			pdu.Type, pdu.Value = gosnmp.OctetString, []byte(v.Value)
+		}
+	case "oid":
+		pdu.Type, pdu.Value = gosnmp.ObjectIdentifier, "."+v.Value
+	case "ip":
+		pdu.Type, pdu.Value = gosnmp.IPAddress, v.Value
+	case "counter32", "gauge32", "timeticks":
+		n, err := strconv.ParseUint(v.Value, 10, 32)
+		if err != nil {
+			return pdu, err
+		}
+		pdu.Value = uint32(n)
+		switch v.Type {
+		case "counter32":
+			pdu.Type = gosnmp.Counter32
+		case "gauge32":
+			pdu.Type = gosnmp.Gauge32
+		default:
+			pdu.Type = gosnmp.TimeTicks
+		}
+	case "counter64":
+		n, err := strconv.ParseUint(v.Value, 10, 64)
+		if err != nil {
+			return pdu, err
+		}
+		pdu.Type, pdu.Value = gosnmp.Counter64, n
+	default:
+		return pdu, fmt.Errorf("tipo no soportado: %s", v.Type)
+	}
+
+	return pdu, nil
+}
+
+// isPrintable indica si los bytes son texto UTF-8 imprimible (sin binarios):
+// "Recepción" se graba como texto, igual que en los fixtures incluidos
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
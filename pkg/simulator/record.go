@@ -0,0 +1,71 @@
+package simulator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+)
+
+// RecordRoots son los subárboles que se graban por defecto
+// Cubren todo lo que consultan collector, profile y telemetry
+var RecordRoots = []string{
+	"1.3.6.1.2.1.1",         // system
+	"1.3.6.1.2.1.2",         // interfaces
+	"1.3.6.1.2.1.4.20",      // ipAddrTable
+	"1.3.6.1.2.1.25.3",      // hrDevice / hrPrinter
+	"1.3.6.1.2.1.43",        // Printer MIB
+	"1.3.6.1.4.1.11.2.3.9",  // HP
+	"1.3.6.1.4.1.253.8",     // Xerox
+	"1.3.6.1.4.1.236.11",    // Samsung
+	"1.3.6.1.4.1.367.3.2.1", // Ricoh
+	"1.3.6.1.4.1.1602.1",    // Canon
+	"1.3.6.1.4.1.1347.43",   // Kyocera
+	"1.3.6.1.4.1.2435.2.3",  // Brother
+	"1.3.6.1.4.1.641.6",     // Lexmark
+	"1.3.6.1.4.1.1248.1.2",  // Epson
+	"1.3.6.1.4.1.2385.1.1",  // Sharp
+	"1.3.6.1.4.1.1129.2.3",  // Toshiba
+	"1.3.6.1.4.1.18334.1.1", // Konica Minolta
+}
+
+// Record graba un walk en vivo de un dispositivo y lo convierte en fixture
+// Los subárboles que fallan (no soportados por el equipo) se omiten
+func Record(client *snmp.SNMPClient, name, brand string, roots []string) (*Fixture, error) {
+	if len(roots) == 0 {
+		roots = RecordRoots
+	}
+
+	f := &Fixture{
+		Name:       name,
+		Brand:      brand,
+		RecordedAt: time.Now().UTC(),
+	}
+
+	var errs []string
+	for _, root := range roots {
+		pdus, err := client.WalkPDUs(root)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", root, err))
+			continue
+		}
+		for _, pdu := range pdus {
+			f.Add(pdu)
+		}
+	}
+
+	if len(f.Variables) == 0 {
+		return nil, fmt.Errorf("no se grabó ningún OID: %s", strings.Join(errs, "; "))
+	}
+
+	f.Sort()
+	for _, v := range f.Variables {
+		if v.OID == "1.3.6.1.2.1.25.3.2.1.3.1" {
+			f.Model = v.Value
+			break
+		}
+	}
+
+	return f, nil
+}
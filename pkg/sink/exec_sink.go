@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecSink entrega cada evento a un programa externo por stdin (un proceso
+// por evento), para integraciones rápidas sin escribir Go: un uploader
+// propio, un importador de un sistema viejo. Es un destino de la queue: el
+// evento se borra de disco solo si el programa termina con código 0
+// El programa recibe además PRINTSNMP_PRINTER_ID en el entorno. Un código
+// de salida ExecRejectCode rechaza el evento sin reintentos (deadletter/)
+type ExecSink struct {
+	config ExecSinkConfig
+}
+
+// ExecSinkConfig configura un ExecSink
+type ExecSinkConfig struct {
+	Command []string      // programa y argumentos
+	Timeout time.Duration // por evento (default 30s)
+	Env     []string      // variables extra ("CLAVE=valor")
+}
+
+// ExecRejectCode es el código de salida con el que el programa rechaza un
+// evento que nunca va a aceptar (EX_DATAERR de sysexits.h)
+const ExecRejectCode = 65
+
+// maxExecStderr acota el stderr que se guarda en el error
+const maxExecStderr = 512
+
+// NewExecSink crea un exec sink
+func NewExecSink(config ExecSinkConfig) (*ExecSink, error) {
+	if len(config.Command) == 0 || config.Command[0] == "" {
+		return nil, fmt.Errorf("exec command is required")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &ExecSink{config: config}, nil
+}
+
+// Write corre el programa con el evento en stdin
+func (es *ExecSink) Write(ctx context.Context, data []byte, printerID string) error {
+	ctx, cancel := context.WithTimeout(ctx, es.config.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, es.config.Command[0], es.config.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), es.config.Env...)
+	cmd.Env = append(cmd.Env, "PRINTSNMP_PRINTER_ID="+printerID)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		if len(msg) > maxExecStderr {
+			msg = msg[:maxExecStderr] + "..."
+		}
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+
+	var exitErr *exec.ExitError
+	permanent := errors.As(err, &exitErr) && exitErr.ExitCode() == ExecRejectCode
+	return &SinkError{Sink: "exec", Operation: "run", Err: err, PrinterID: printerID, Permanent: permanent}
+}
+
+// Close no tiene recursos que liberar (un proceso por evento)
+func (es *ExecSink) Close() error {
+	return nil
+}
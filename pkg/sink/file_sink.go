@@ -2,16 +2,39 @@ package sink
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
 )
 
 // FileSink escribe los JSON serializados a archivos en disco
 // Usado para buffer/queue cuando la nube no está disponible
 type FileSink struct {
 	queueDir string
+	quota    Quota
+
+	mu           sync.Mutex
+	evicted      int   // eventos descartados por cuota desde que se creó el sink
+	evictedBytes int64 // bytes liberados por esos descartes
+}
+
+// Quota limita el espacio que puede ocupar la queue cuando la nube no responde
+// Al superarse se descartan eventos del más viejo al más nuevo, empezando por
+// deadletter/ (ya dados por perdidos) y siguiendo con los pendientes
+type Quota struct {
+	MaxBytes int64 // tamaño total máximo (0 = sin límite)
+	MaxFiles int   // cantidad máxima de eventos (0 = sin límite)
+}
+
+// enabled indica si hay algún límite configurado
+func (q Quota) enabled() bool {
+	return q.MaxBytes > 0 || q.MaxFiles > 0
 }
 
 // NewFileSink crea un nuevo file sink
@@ -40,8 +63,8 @@ func (fs *FileSink) Write(ctx context.Context, data []byte, printerID string) er
 	filename := fmt.Sprintf("%d_%s.json", epoch, printerID)
 	filepath := filepath.Join(fs.queueDir, filename)
 
-	// Escribir archivo
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	// Escribir archivo (temp + fsync + rename: un crash nunca deja JSON truncado)
+	if err := fsutil.WriteFileAtomic(filepath, data, 0644); err != nil {
 		return &SinkError{
 			Sink:      "file",
 			Operation: "write",
@@ -50,9 +73,180 @@ func (fs *FileSink) Write(ctx context.Context, data []byte, printerID string) er
 		}
 	}
 
+	if fs.quota.enabled() {
+		if err := fs.enforceQuota(); err != nil {
+			return &SinkError{
+				Sink:      "file",
+				Operation: "quota",
+				Err:       err,
+				PrinterID: printerID,
+			}
+		}
+	}
+
 	return nil
 }
 
+// QuarantineDir es el subdirectorio con los eventos corruptos apartados por Scrub
+const QuarantineDir = "corrupt"
+
+// ScrubResult resume una pasada de Scrub
+type ScrubResult struct {
+	Quarantined []string // eventos con JSON inválido movidos a corrupt/
+	TempRemoved int      // temporales huérfanos de escrituras interrumpidas
+}
+
+// Scrub revisa la queue al arrancar: borra temporales huérfanos y mueve a
+// corrupt/ los eventos que no son JSON válido (el drainer no podría enviarlos)
+func (fs *FileSink) Scrub() (ScrubResult, error) {
+	var result ScrubResult
+
+	tmps, err := filepath.Glob(filepath.Join(fs.queueDir, "*"+fsutil.TmpSuffix))
+	if err != nil {
+		return result, err
+	}
+	for _, tmp := range tmps {
+		if os.Remove(tmp) == nil {
+			result.TempRemoved++
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(fs.queueDir, "*.json"))
+	if err != nil {
+		return result, err
+	}
+
+	quarantine := filepath.Join(fs.queueDir, QuarantineDir)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil && len(data) > 0 && json.Valid(data) {
+			continue
+		}
+
+		if err := os.MkdirAll(quarantine, 0755); err != nil {
+			return result, err
+		}
+		name := filepath.Base(path)
+		if err := os.Rename(path, filepath.Join(quarantine, name)); err != nil {
+			return result, err
+		}
+		os.Remove(path + ".meta")
+		result.Quarantined = append(result.Quarantined, name)
+	}
+
+	return result, nil
+}
+
+// SetQuota configura la cuota de la queue (se aplica en cada Write)
+func (fs *FileSink) SetQuota(quota Quota) {
+	fs.quota = quota
+}
+
+// Evicted retorna cuántos eventos (y bytes) se descartaron por cuota
+func (fs *FileSink) Evicted() (int, int64) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.evicted, fs.evictedBytes
+}
+
+// queueFile es un evento en disco considerado para la cuota
+type queueFile struct {
+	path string
+	size int64
+}
+
+// enforceQuota descarta eventos hasta cumplir la cuota
+// Orden: deadletter/ primero, luego pendientes; en cada grupo del más viejo
+// al más nuevo (el nombre empieza con el epoch)
+func (fs *FileSink) enforceQuota() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dead, err := listQueueFiles(filepath.Join(fs.queueDir, DeadLetterDir))
+	if err != nil {
+		return err
+	}
+	pending, err := listQueueFiles(fs.queueDir)
+	if err != nil {
+		return err
+	}
+	candidates := append(dead, pending...)
+
+	var totalBytes int64
+	for _, f := range candidates {
+		totalBytes += f.size
+	}
+	totalFiles := len(candidates)
+
+	for _, f := range candidates {
+		overBytes := fs.quota.MaxBytes > 0 && totalBytes > fs.quota.MaxBytes
+		overFiles := fs.quota.MaxFiles > 0 && totalFiles > fs.quota.MaxFiles
+		if !overBytes && !overFiles {
+			break
+		}
+
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		os.Remove(f.path + ".meta") // metadata de intentos (ver Queue)
+
+		totalBytes -= f.size
+		totalFiles--
+		fs.evicted++
+		fs.evictedBytes += f.size
+	}
+
+	return nil
+}
+
+// listQueueFiles lista los eventos *.json de dir ordenados del más viejo al más nuevo
+// La metadata (.meta) cuenta en el tamaño del evento al que pertenece
+func listQueueFiles(dir string) ([]queueFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	files := make([]queueFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // lo borró el drainer en paralelo
+		}
+		size := info.Size()
+		if meta, err := os.Stat(path + ".meta"); err == nil {
+			size += meta.Size()
+		}
+		files = append(files, queueFile{path: path, size: size})
+	}
+	return files, nil
+}
+
+// Size retorna el tamaño actual de la queue (pendientes + deadletter) en bytes
+func (fs *FileSink) Size() (int64, error) {
+	var total int64
+	for _, dir := range []string{fs.queueDir, filepath.Join(fs.queueDir, DeadLetterDir)} {
+		files, err := listQueueFiles(dir)
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range files {
+			total += f.size
+		}
+	}
+	return total, nil
+}
+
+// Backlog retorna cuántos eventos siguen pendientes en el directorio de queue
+func (fs *FileSink) Backlog() (int, error) {
+	matches, err := filepath.Glob(filepath.Join(fs.queueDir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	return len(matches), nil
+}
+
 // Close cierra el FileSink (no tiene recursos abiertos)
 func (fs *FileSink) Close() error {
 	// FileSink no mantiene recursos abiertos, así que simplemente retorna nil
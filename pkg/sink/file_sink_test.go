@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkWrite(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Write(context.Background(), []byte(`{"n":1}`), "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Write(context.Background(), nil, "p1"); err == nil {
+		t.Error("se aceptó un evento vacío")
+	}
+
+	q, _ := NewQueue(dir, QueuePolicy{})
+	entries, _ := q.Entries()
+	if len(entries) != 1 || entries[0].PrinterID != "p1" || time.Since(entries[0].QueuedAt) > time.Minute {
+		t.Fatalf("entries %+v", entries)
+	}
+	if backlog, _ := fs.Backlog(); backlog != 1 {
+		t.Errorf("backlog %d", backlog)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmps) != 0 {
+		t.Errorf("quedaron temporales: %v", tmps)
+	}
+}
+
+// La cuota descarta primero deadletter/ y después los pendientes más viejos
+func TestFileSinkQuotaEviction(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewQueue(dir, QueuePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	enqueue(t, filepath.Join(dir, DeadLetterDir), now-50, "muerto", `{}`)
+	enqueue(t, dir, now-40, "p1", `{}`)
+	enqueue(t, dir, now-30, "p2", `{}`)
+	enqueue(t, dir, now-20, "p3", `{}`)
+	fs.SetQuota(Quota{MaxFiles: 3})
+	if err := fs.Write(context.Background(), []byte(`{}`), "p4"); err != nil {
+		t.Fatal(err)
+	}
+
+	if dead, _ := q.DeadLetters(); len(dead) != 0 {
+		t.Errorf("deadletter no se descartó primero: %+v", dead)
+	}
+	entries, _ := q.Entries()
+	var kept []string
+	for _, e := range entries {
+		kept = append(kept, e.PrinterID)
+	}
+	if strings.Join(kept, ",") != "p2,p3,p4" {
+		t.Errorf("quedaron %v, se esperaban p2,p3,p4", kept)
+	}
+	if n, bytes := fs.Evicted(); n != 2 || bytes != 4 {
+		t.Errorf("Evicted = %d eventos, %d bytes; se esperaban 2 y 4", n, bytes)
+	}
+
+	// Por tamaño: cada evento ocupa 2 bytes, entran 2
+	fs.SetQuota(Quota{MaxBytes: 4})
+	if err := fs.Write(context.Background(), []byte(`{}`), "p5"); err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := fs.Size(); size > 4 {
+		t.Errorf("tamaño %d, se esperaba a lo sumo 4", size)
+	}
+	entries, _ = q.Entries()
+	if len(entries) != 2 || entries[len(entries)-1].PrinterID != "p5" {
+		t.Errorf("se descartó el evento nuevo: %+v", entries)
+	}
+}
+
+// Scrub recupera la queue tras un crash: borra temporales a medio escribir y
+// aparta los eventos truncados o vacíos para que el drainer no se trabe
+func TestFileSinkScrub(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFileSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	valid := enqueue(t, dir, now, "sano", `{"printer":{"id":"sano"}}`)
+	truncated := enqueue(t, dir, now, "truncado", `{"printer":{"id":"tru`)
+	empty := enqueue(t, dir, now, "vacio", ``)
+	if err := os.WriteFile(filepath.Join(dir, truncated+".meta"), []byte(`{"attempts":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, valid+".123.tmp"), []byte(`{"a`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := fs.Scrub()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TempRemoved != 1 || len(result.Quarantined) != 2 {
+		t.Fatalf("resultado %+v", result)
+	}
+	for _, name := range []string{truncated, empty} {
+		if _, err := os.Stat(filepath.Join(dir, QuarantineDir, name)); err != nil {
+			t.Errorf("%s no quedó en %s/: %v", name, QuarantineDir, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, truncated+".meta")); !os.IsNotExist(err) {
+		t.Error("quedó la metadata del evento apartado")
+	}
+
+	// Lo que queda se sube entero
+	q, _ := NewQueue(dir, QueuePolicy{})
+	dest := &recordingSink{}
+	if stats, err := q.Drain(context.Background(), dest); err != nil || stats.Sent != 1 {
+		t.Fatalf("Drain: %+v, %v", stats, err)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(dest.got[0], "sano:")), &event); err != nil {
+		t.Errorf("evento subido inválido: %v", err)
+	}
+}
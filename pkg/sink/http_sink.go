@@ -3,20 +3,24 @@ package sink
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/retry"
 )
 
 // HTTPSink envía los JSON serializados a un endpoint HTTP
 // Implementa reintentos con backoff exponencial
 type HTTPSink struct {
-	endpoint    string        // URL del endpoint (ej: https://cloud.example.com/api/v1/telemetry)
-	authToken   string        // Bearer token para autenticación
-	client      *http.Client  // cliente HTTP con timeout
-	maxRetries  int           // máximo de intentos
-	initialWait time.Duration // espera inicial entre reintentos
+	endpoint  string       // URL del endpoint (ej: https://cloud.example.com/api/v1/telemetry)
+	authToken string       // Bearer token estático (si no hay OAuth2)
+	tokens    *tokenSource // tokens OAuth2 client-credentials (nil = authToken)
+	client    *http.Client // cliente HTTP con timeout (y mTLS si está configurado)
+	policy    retry.Policy // política de reintentos (backoff exponencial con jitter)
 }
 
 // HTTPSinkConfig configura un HTTPSink
@@ -26,11 +30,16 @@ type HTTPSinkConfig struct {
 	Timeout     time.Duration // timeout HTTP
 	MaxRetries  int           // máximo de reintentos (default: 3)
 	InitialWait time.Duration // espera inicial en reintentos (default: 1s)
+	MaxWait     time.Duration // espera máxima entre reintentos (default: 60s)
+
+	TLS    TLSConfig     // CA propia y certificado de cliente (mTLS)
+	OAuth2 *OAuth2Config // client-credentials; reemplaza AuthToken si se configura
 }
 
 // TODO: Activar HTTPSink cuando endpoint cloud esté disponible
 // NewHTTPSink crea un nuevo HTTP sink
-func NewHTTPSink(config HTTPSinkConfig) *HTTPSink {
+// Falla si los certificados/CA configurados no se pueden cargar
+func NewHTTPSink(config HTTPSinkConfig) (*HTTPSink, error) {
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
 	}
@@ -43,72 +52,61 @@ func NewHTTPSink(config HTTPSinkConfig) *HTTPSink {
 		config.Timeout = 10 * time.Second
 	}
 
-	client := &http.Client{
-		Timeout: config.Timeout,
+	if config.MaxWait == 0 {
+		config.MaxWait = 60 * time.Second
 	}
 
-	return &HTTPSink{
-		endpoint:    config.Endpoint,
-		authToken:   config.AuthToken,
-		client:      client,
-		maxRetries:  config.MaxRetries,
-		initialWait: config.InitialWait,
+	client, err := NewHTTPClient(config.TLS, config.Timeout)
+	if err != nil {
+		return nil, err
 	}
+
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = config.MaxRetries + 1
+	policy.InitialWait = config.InitialWait
+	policy.MaxWait = config.MaxWait
+
+	hs := &HTTPSink{
+		endpoint:  config.Endpoint,
+		authToken: config.AuthToken,
+		client:    client,
+		policy:    policy,
+	}
+
+	if config.OAuth2 != nil && config.OAuth2.TokenURL != "" {
+		// El endpoint de tokens usa el mismo transporte (mTLS/CA del gateway)
+		hs.tokens = newTokenSource(*config.OAuth2, client)
+	}
+
+	return hs, nil
 }
 
 // TODO: Activar HTTPSink cuando endpoint cloud esté disponible
-// Write envía el JSON al endpoint con reintentos exponenciales
+// Write envía el JSON al endpoint con reintentos (backoff exponencial con jitter)
 func (hs *HTTPSink) Write(ctx context.Context, data []byte, printerID string) error {
 	if len(data) == 0 {
 		return fmt.Errorf("empty data for printer %s", printerID)
 	}
 
-	var lastErr error
-	waitDuration := hs.initialWait
-
-	for attempt := 0; attempt <= hs.maxRetries; attempt++ {
-		// Si no es el primer intento, esperar con backoff exponencial
-		if attempt > 0 {
-			select {
-			case <-time.After(waitDuration):
-				// Esperar completó
-			case <-ctx.Done():
-				// Contexto cancelado
-				return &SinkError{
-					Sink:      "http",
-					Operation: "write",
-					Err:       fmt.Errorf("context cancelled after %d retries", attempt),
-					PrinterID: printerID,
-				}
-			}
-
-			// Aumentar espera para siguiente intento (backoff exponencial)
-			waitDuration *= 2
-			if waitDuration > 60*time.Second {
-				waitDuration = 60 * time.Second // cap a 60s
-			}
-		}
-
-		// Intentar enviar
-		err := hs.sendRequest(ctx, data, printerID)
-		if err == nil {
-			return nil // Éxito
-		}
-
-		lastErr = err
+	err := hs.policy.Do(ctx, func(attempt int) error {
+		return hs.sendRequest(ctx, data, printerID)
+	})
+	if err == nil {
+		return nil // Éxito
+	}
 
-		// Si es el último intento, retornar error
-		if attempt == hs.maxRetries {
-			return &SinkError{
-				Sink:      "http",
-				Operation: "write",
-				Err:       fmt.Errorf("failed after %d attempts: %w", hs.maxRetries+1, lastErr),
-				PrinterID: printerID,
-			}
-		}
+	// Errores de cliente (4xx) ya vienen como SinkError permanente
+	var sinkErr *SinkError
+	if errors.As(err, &sinkErr) && sinkErr.Permanent {
+		return sinkErr
 	}
 
-	return lastErr
+	return &SinkError{
+		Sink:      "http",
+		Operation: "write",
+		Err:       err,
+		PrinterID: printerID,
+	}
 }
 
 // TODO: Activar HTTPSink cuando endpoint cloud esté disponible
@@ -124,9 +122,18 @@ func (hs *HTTPSink) sendRequest(ctx context.Context, data []byte, printerID stri
 	// Headers estándar
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Printer-ID", printerID)
+	if key := idempotencyKey(data); key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
 
-	// Autenticación si está configurada
-	if hs.authToken != "" {
+	// Autenticación si está configurada (OAuth2 tiene prioridad sobre el token estático)
+	if hs.tokens != nil {
+		token, err := hs.tokens.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	} else if hs.authToken != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", hs.authToken))
 	}
 
@@ -147,6 +154,12 @@ func (hs *HTTPSink) sendRequest(ctx context.Context, data []byte, printerID stri
 	bodyBytes, _ := io.ReadAll(resp.Body)
 	bodyStr := string(bodyBytes)
 
+	// Token revocado o vencido antes de lo anunciado: renovar y reintentar
+	if resp.StatusCode == http.StatusUnauthorized && hs.tokens != nil {
+		hs.tokens.Invalidate()
+		return fmt.Errorf("unauthorized (HTTP 401), token refreshed: %s", bodyStr)
+	}
+
 	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
 		// Error de cliente (400-499) → no reintentar
 		return &SinkError{
@@ -154,6 +167,7 @@ func (hs *HTTPSink) sendRequest(ctx context.Context, data []byte, printerID stri
 			Operation: "write",
 			Err:       fmt.Errorf("client error (HTTP %d): %s", resp.StatusCode, bodyStr),
 			PrinterID: printerID,
+			Permanent: true,
 		}
 	}
 
@@ -167,3 +181,15 @@ func (hs *HTTPSink) Close() error {
 	// El http.Client no necesita ser cerrado explícitamente
 	return nil
 }
+
+// idempotencyKey extrae la idempotency_key del evento ("" si no trae una)
+// Viaja también como header para que un gateway deduplique sin leer el body
+func idempotencyKey(data []byte) string {
+	var event struct {
+		IdempotencyKey string `json:"idempotency_key"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return ""
+	}
+	return event.IdempotencyKey
+}
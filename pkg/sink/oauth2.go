@@ -0,0 +1,170 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2Config configura el flujo client-credentials (RFC 6749 §4.4)
+type OAuth2Config struct {
+	TokenURL     string   // endpoint de tokens del gateway
+	ClientID     string   // id del cliente (agente)
+	ClientSecret string   // secreto del cliente (usar el vault: "secret:...")
+	Scopes       []string // scopes solicitados (opcional)
+}
+
+// tokenRefreshMargin renueva el token antes de que venza, para no enviar
+// un token que expira en tránsito
+const tokenRefreshMargin = 30 * time.Second
+
+// tokenSource obtiene y cachea bearer tokens de vida corta
+// Es seguro para uso concurrente: un solo refresh a la vez
+type tokenSource struct {
+	config OAuth2Config
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time // cero = no vence
+}
+
+// newTokenSource crea un tokenSource que usa el mismo cliente HTTP (mTLS incluido)
+func newTokenSource(config OAuth2Config, client *http.Client) *tokenSource {
+	return &tokenSource{
+		config: config,
+		client: client,
+	}
+}
+
+// Token retorna un token vigente, pidiendo uno nuevo si no hay o está por vencer
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && (ts.expiry.IsZero() || time.Now().Add(tokenRefreshMargin).Before(ts.expiry)) {
+		return ts.token, nil
+	}
+
+	token, expiry, err := ts.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ts.token = token
+	ts.expiry = expiry
+	return token, nil
+}
+
+// Invalidate descarta el token cacheado (ej: el gateway respondió 401)
+func (ts *tokenSource) Invalidate() {
+	ts.mu.Lock()
+	ts.token = ""
+	ts.expiry = time.Time{}
+	ts.mu.Unlock()
+}
+
+// tokenResponse es la respuesta del endpoint de tokens
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"` // segundos
+}
+
+// fetch pide un token nuevo con grant_type=client_credentials
+// Las credenciales van por HTTP Basic (RFC 6749 §2.3.1)
+func (ts *tokenSource) fetch(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(ts.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ts.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(ts.config.ClientID), url.QueryEscape(ts.config.ClientSecret))
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("token endpoint returned HTTP %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			// Credenciales inválidas: reintentar no sirve
+			return "", time.Time{}, &SinkError{Sink: "http", Operation: "oauth2", Err: err, Permanent: true}
+		}
+		return "", time.Time{}, err
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token response without access_token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("unsupported token type %q", tr.TokenType)
+	}
+
+	var expiry time.Time
+	if tr.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+
+	return tr.AccessToken, expiry, nil
+}
+
+// Authorizer agrega el header Authorization igual que el HTTPSink: OAuth2 si
+// está configurado, si no el token estático. Lo usan otros clientes del
+// backend (configuración remota) para no duplicar credenciales
+type Authorizer struct {
+	authToken string
+	tokens    *tokenSource
+}
+
+// NewAuthorizer crea el autorizador; client es el mismo del backend (mTLS incluido)
+func NewAuthorizer(config HTTPSinkConfig, client *http.Client) *Authorizer {
+	a := &Authorizer{authToken: config.AuthToken}
+	if config.OAuth2 != nil && config.OAuth2.TokenURL != "" {
+		a.tokens = newTokenSource(*config.OAuth2, client)
+	}
+	return a
+}
+
+// Authorize agrega el bearer token al request (nada si no hay credenciales)
+func (a *Authorizer) Authorize(ctx context.Context, req *http.Request) error {
+	if a.tokens != nil {
+		token, err := a.tokens.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if a.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.authToken)
+	}
+	return nil
+}
+
+// Invalidate descarta el token OAuth2 cacheado (ej: el backend respondió 401)
+func (a *Authorizer) Invalidate() {
+	if a.tokens != nil {
+		a.tokens.Invalidate()
+	}
+}
@@ -0,0 +1,166 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/retry"
+)
+
+// ObjectStore es un almacenamiento de objetos (S3, Azure Blob)
+// *s3.Client y *azblob.Client lo implementan
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// BatchSink es un Sink que prefiere recibir los eventos agrupados por ventana
+// de tiempo: Queue.Drain le entrega una ventana completa en una sola escritura
+type BatchSink interface {
+	Sink
+
+	// BatchWindow es la ventana de agrupación (0 = evento por evento)
+	BatchWindow() time.Duration
+
+	// WriteBatch escribe los eventos de una ventana (start es su inicio)
+	WriteBatch(ctx context.Context, start time.Time, events []BatchEvent) error
+}
+
+// BatchEvent es un evento de la queue dentro de un lote
+type BatchEvent struct {
+	PrinterID string
+	QueuedAt  time.Time
+	Data      []byte
+}
+
+// Claves por defecto de los objetos
+const (
+	DefaultObjectKey = "{agent}/{date}/{printer}_{time}.json"
+	DefaultBatchKey  = "{agent}/{date}/{hour}_{time}.ndjson"
+)
+
+// ObjectSink sube cada evento (o cada lote por ventana) como un objeto
+// La clave sale de KeyTemplate con los marcadores:
+//
+//	{agent} {tenant} {date} (2006-01-02) {hour} (15) {time} (150405) {printer}
+//
+// Con lotes {date}/{hour} son los de la ventana, {time} el del primer evento y
+// {printer} queda vacío; el contenido es NDJSON (un evento por línea)
+type ObjectSink struct {
+	name   string // "s3", "azure" (para SinkError)
+	store  ObjectStore
+	config ObjectSinkConfig
+	policy retry.Policy
+}
+
+// ObjectSinkConfig configura un ObjectSink
+type ObjectSinkConfig struct {
+	KeyTemplate string        // "" = DefaultObjectKey o DefaultBatchKey
+	Agent       string        // {agent}
+	Tenant      string        // {tenant}
+	Batch       time.Duration // ventana de los lotes (0 = un objeto por evento)
+	MaxRetries  int           // default 3
+	MaxWait     time.Duration // espera máxima entre reintentos (default 60s)
+}
+
+// NewObjectSink crea un sink sobre store; name identifica el destino en los errores
+func NewObjectSink(name string, store ObjectStore, config ObjectSinkConfig) *ObjectSink {
+	if config.KeyTemplate == "" {
+		config.KeyTemplate = DefaultObjectKey
+		if config.Batch > 0 {
+			config.KeyTemplate = DefaultBatchKey
+		}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxWait == 0 {
+		config.MaxWait = 60 * time.Second
+	}
+
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = config.MaxRetries + 1
+	policy.MaxWait = config.MaxWait
+
+	return &ObjectSink{name: name, store: store, config: config, policy: policy}
+}
+
+// Write sube un evento como un objeto
+func (s *ObjectSink) Write(ctx context.Context, data []byte, printerID string) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty data for printer %s", printerID)
+	}
+	at := eventTime(data)
+	key := s.key(at, at, printerID)
+	return s.put(ctx, key, data, "application/json", printerID)
+}
+
+// BatchWindow implementa BatchSink
+func (s *ObjectSink) BatchWindow() time.Duration {
+	return s.config.Batch
+}
+
+// WriteBatch sube los eventos de una ventana como un objeto NDJSON
+func (s *ObjectSink) WriteBatch(ctx context.Context, start time.Time, events []BatchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, event := range events {
+		// Los eventos de la queue están indentados: una línea por evento
+		if err := json.Compact(&buf, event.Data); err != nil {
+			return &SinkError{Sink: s.name, Operation: "batch", Err: err, PrinterID: event.PrinterID, Permanent: true}
+		}
+		buf.WriteByte('\n')
+	}
+	key := s.key(start, events[0].QueuedAt, "")
+	return s.put(ctx, key, buf.Bytes(), "application/x-ndjson", "")
+}
+
+// Close no tiene recursos que liberar
+func (s *ObjectSink) Close() error {
+	return nil
+}
+
+// put sube un objeto con reintentos
+func (s *ObjectSink) put(ctx context.Context, key string, data []byte, contentType, printerID string) error {
+	err := s.policy.Do(ctx, func(attempt int) error {
+		return s.store.Put(ctx, key, data, contentType)
+	})
+	if err != nil {
+		return &SinkError{Sink: s.name, Operation: "put " + key, Err: err, PrinterID: printerID}
+	}
+	return nil
+}
+
+// key expande KeyTemplate; window da {date}/{hour} y at da {time}
+func (s *ObjectSink) key(window, at time.Time, printerID string) string {
+	window, at = window.UTC(), at.UTC()
+	return strings.NewReplacer(
+		"{agent}", objectKeySegment(s.config.Agent),
+		"{tenant}", objectKeySegment(s.config.Tenant),
+		"{date}", window.Format("2006-01-02"),
+		"{hour}", window.Format("15"),
+		"{time}", at.Format("150405"),
+		"{printer}", objectKeySegment(printerID),
+	).Replace(s.config.KeyTemplate)
+}
+
+// objectKeySegment evita que un ID agregue niveles a la clave
+func objectKeySegment(s string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(s)
+}
+
+// eventTime toma el instante del evento (collected_at, emitted_at o detected_at)
+func eventTime(data []byte) time.Time {
+	var ev struct {
+		CollectedAt time.Time `json:"collected_at"`
+		EmittedAt   time.Time `json:"emitted_at"`
+		DetectedAt  time.Time `json:"detected_at"`
+	}
+	json.Unmarshal(data, &ev)
+	return firstTime(ev.CollectedAt, ev.EmittedAt, ev.DetectedAt)
+}
@@ -0,0 +1,379 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/pg"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// PostgresSink guarda cada telemetría en tablas relacionales (printers,
+// supplies, counters, alerts) para consultar la flota con SQL sin un servicio
+// de ingesta. Es un destino de la queue: un evento se borra de disco solo
+// cuando su transacción hizo COMMIT
+// Los eventos que no son telemetría (heartbeat, inventario) se aceptan sin guardar
+type PostgresSink struct {
+	config PostgresSinkConfig
+	schema string // identificador ya escapado
+
+	mu       sync.Mutex
+	conn     *pg.Conn
+	migrated bool
+}
+
+// PostgresSinkConfig configura un PostgresSink
+type PostgresSinkConfig struct {
+	Conn   pg.Config
+	Schema string // esquema de las tablas (default "printsnmp"; se crea si no existe)
+	Agent  string // agent_id de las filas de printers
+	Tenant string
+}
+
+// NewPostgresSink crea el sink; la conexión y las migraciones se hacen en el primer Write
+func NewPostgresSink(config PostgresSinkConfig) *PostgresSink {
+	if config.Schema == "" {
+		config.Schema = "printsnmp"
+	}
+	return &PostgresSink{config: config, schema: quoteIdent(config.Schema)}
+}
+
+// Write guarda una telemetría en una transacción
+func (ps *PostgresSink) Write(ctx context.Context, data []byte, printerID string) error {
+	var telem telemetry.Telemetry
+	if err := json.Unmarshal(data, &telem); err != nil {
+		return &SinkError{Sink: "postgres", Operation: "decode", Err: err, PrinterID: printerID, Permanent: true}
+	}
+	if telem.Printer.ID == "" {
+		var unchanged telemetry.NoChangeEvent
+		if err := json.Unmarshal(data, &unchanged); err == nil && unchanged.EventType == "printer_unchanged" && unchanged.PrinterID != "" {
+			return ps.touch(ctx, &unchanged)
+		}
+		return nil // heartbeat, inventario, comandos: no son filas de impresoras
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if err := ps.connect(ctx); err != nil {
+		return ps.sinkError("connect", err, printerID)
+	}
+	if err := ps.conn.SimpleExec(ctx, "BEGIN"); err != nil {
+		return ps.sinkError("begin", err, printerID)
+	}
+	if err := ps.store(ctx, &telem); err != nil {
+		ps.conn.SimpleExec(ctx, "ROLLBACK")
+		return ps.sinkError("write", err, printerID)
+	}
+	if err := ps.conn.SimpleExec(ctx, "COMMIT"); err != nil {
+		return ps.sinkError("commit", err, printerID)
+	}
+	return nil
+}
+
+// Close cierra la conexión
+func (ps *PostgresSink) Close() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.conn == nil {
+		return nil
+	}
+	err := ps.conn.Close()
+	ps.conn = nil
+	return err
+}
+
+// connect abre (o reabre) la conexión y aplica las migraciones pendientes
+func (ps *PostgresSink) connect(ctx context.Context) error {
+	if ps.conn != nil && !ps.conn.Broken() {
+		return nil
+	}
+	if ps.conn != nil {
+		ps.conn.Close()
+		ps.conn = nil
+	}
+	conn, err := pg.Connect(ctx, ps.config.Conn)
+	if err != nil {
+		return err
+	}
+	ps.conn = conn
+	if !ps.migrated {
+		if err := ps.migrate(ctx); err != nil {
+			return fmt.Errorf("migraciones: %w", err)
+		}
+		ps.migrated = true
+	}
+	return nil
+}
+
+// touch registra un poll sin cambios: la impresora sigue viva
+func (ps *PostgresSink) touch(ctx context.Context, e *telemetry.NoChangeEvent) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if err := ps.connect(ctx); err != nil {
+		return ps.sinkError("connect", err, e.PrinterID)
+	}
+	err := ps.conn.Exec(ctx, ps.sql(`
+		UPDATE {s}.printers SET last_seen_at = $2
+		WHERE printer_id = $1 AND last_seen_at < $2`), e.PrinterID, e.CollectedAt)
+	if err != nil {
+		return ps.sinkError("write", err, e.PrinterID)
+	}
+	return nil
+}
+
+// store escribe las filas de una telemetría (dentro de la transacción abierta)
+// Una telemetría más vieja que la última guardada (reintento tardío de la
+// queue) solo agrega su lectura de contadores: no pisa el estado actual
+// Un delta solo toca las secciones que trae; el resto queda como estaba
+func (ps *PostgresSink) store(ctx context.Context, t *telemetry.Telemetry) error {
+	p := t.Printer
+	at := t.CollectedAt
+	var site, building, floor, room *string
+	if p.Site != nil {
+		site, building, floor, room = &p.Site.Site, &p.Site.Building, &p.Site.Floor, &p.Site.Room
+	}
+	var tags interface{}
+	if len(p.Tags) > 0 {
+		encoded, _ := json.Marshal(p.Tags)
+		tags = string(encoded)
+	}
+	var state *string
+	var pageCount *int64
+	if t.Status != nil {
+		state, pageCount = &t.Status.State, &t.Status.PageCount
+	}
+
+	rows, err := ps.conn.Query(ctx, ps.sql(`
+		INSERT INTO {s}.printers (printer_id, agent_id, tenant, ip, brand, model, serial_number, hostname,
+			mac_address, site, building, floor, room, tags, state, page_count, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb, $15, $16, $17, $17)
+		ON CONFLICT (printer_id) DO UPDATE SET
+			agent_id = EXCLUDED.agent_id, tenant = EXCLUDED.tenant, ip = EXCLUDED.ip, brand = EXCLUDED.brand,
+			model = EXCLUDED.model, serial_number = EXCLUDED.serial_number, hostname = EXCLUDED.hostname,
+			mac_address = EXCLUDED.mac_address, site = EXCLUDED.site, building = EXCLUDED.building,
+			floor = EXCLUDED.floor, room = EXCLUDED.room, tags = EXCLUDED.tags,
+			state = COALESCE(EXCLUDED.state, {s}.printers.state),
+			page_count = COALESCE(EXCLUDED.page_count, {s}.printers.page_count), last_seen_at = EXCLUDED.last_seen_at
+		WHERE {s}.printers.last_seen_at <= EXCLUDED.last_seen_at
+		RETURNING printer_id`),
+		p.ID, ps.config.Agent, nullString(ps.config.Tenant), p.IP, p.Brand, p.Model, p.SerialNumber, p.Hostname,
+		p.MacAddress, site, building, floor, room, tags, state, pageCount, at)
+	if err != nil {
+		return err
+	}
+	current := len(rows) > 0
+
+	if c := t.Counters; c != nil {
+		var deltaTotal, deltaMono, deltaColor *int64
+		if c.Delta != nil {
+			deltaTotal, deltaMono, deltaColor = &c.Delta.TotalPages, &c.Delta.MonoPages, &c.Delta.ColorPages
+		}
+		a := c.Absolute
+		err := ps.conn.Exec(ctx, ps.sql(`
+			INSERT INTO {s}.counters (printer_id, collected_at, event_id, total_pages, mono_pages, color_pages,
+				scan_pages, copy_pages, fax_pages, duplex_pages, a3_pages,
+				delta_total_pages, delta_mono_pages, delta_color_pages, reset_detected)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			ON CONFLICT (printer_id, collected_at) DO NOTHING`),
+			p.ID, at, t.EventID, a.TotalPages, a.MonoPages, a.ColorPages,
+			a.ScanPages, a.CopyPages, a.FaxPages, a.DuplexPages, a.A3Pages,
+			deltaTotal, deltaMono, deltaColor, c.ResetDetected)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !current {
+		return nil
+	}
+
+	if t.Includes(telemetry.SectionSupplies) {
+		if err := ps.storeSupplies(ctx, t); err != nil {
+			return err
+		}
+	}
+	if !t.Includes(telemetry.SectionAlerts) {
+		return nil
+	}
+
+	for _, alert := range t.Alerts {
+		err := ps.conn.Exec(ctx, ps.sql(`
+			INSERT INTO {s}.alerts (printer_id, alert_id, type, severity, message, detected_at, last_seen_at, cleared_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULL)
+			ON CONFLICT (printer_id, alert_id) DO UPDATE SET
+				type = EXCLUDED.type, severity = EXCLUDED.severity, message = EXCLUDED.message,
+				detected_at = CASE WHEN {s}.alerts.cleared_at IS NULL THEN {s}.alerts.detected_at ELSE EXCLUDED.detected_at END,
+				last_seen_at = EXCLUDED.last_seen_at, cleared_at = NULL`),
+			p.ID, alert.ID, alert.Type, alert.Severity, alert.Message, alert.DetectedAt, at)
+		if err != nil {
+			return err
+		}
+	}
+	// Alertas que dejaron de reportarse se cierran (quedan como historial)
+	return ps.conn.Exec(ctx, ps.sql(`
+		UPDATE {s}.alerts SET cleared_at = $2
+		WHERE printer_id = $1 AND cleared_at IS NULL AND last_seen_at < $2`), p.ID, at)
+}
+
+// storeSupplies reemplaza los consumibles de la impresora por los de t
+func (ps *PostgresSink) storeSupplies(ctx context.Context, t *telemetry.Telemetry) error {
+	p, at := t.Printer, t.CollectedAt
+	for _, s := range t.Supplies {
+		err := ps.conn.Exec(ctx, ps.sql(`
+			INSERT INTO {s}.supplies (printer_id, supply_id, name, type, level, max_level, percentage,
+				status, level_state, model, serial_number, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (printer_id, supply_id) DO UPDATE SET
+				name = EXCLUDED.name, type = EXCLUDED.type, level = EXCLUDED.level, max_level = EXCLUDED.max_level,
+				percentage = EXCLUDED.percentage, status = EXCLUDED.status, level_state = EXCLUDED.level_state,
+				model = EXCLUDED.model, serial_number = EXCLUDED.serial_number, updated_at = EXCLUDED.updated_at`),
+			p.ID, s.ID, s.Name, s.Type, s.Level, s.MaxLevel, s.Percentage,
+			s.Status, nullString(s.LevelState), nullString(s.Model), nullString(s.SerialNumber), at)
+		if err != nil {
+			return err
+		}
+	}
+	// Consumibles que ya no reporta (reemplazados o retirados)
+	return ps.conn.Exec(ctx, ps.sql(`DELETE FROM {s}.supplies WHERE printer_id = $1 AND updated_at < $2`), p.ID, at)
+}
+
+// sql reemplaza {s} por el esquema
+func (ps *PostgresSink) sql(query string) string {
+	return strings.ReplaceAll(query, "{s}", ps.schema)
+}
+
+// sinkError clasifica errores: datos inválidos (SQLSTATE 22/23) no se reintentan
+func (ps *PostgresSink) sinkError(op string, err error, printerID string) error {
+	var pgErr *pg.Error
+	permanent := errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23"))
+	return &SinkError{Sink: "postgres", Operation: op, Err: err, PrinterID: printerID, Permanent: permanent}
+}
+
+// quoteIdent escapa un identificador SQL
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// nullString es NULL para ""
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// migrationLock es la clave del advisory lock de las migraciones (varios agentes, una base)
+const migrationLock = 0x7072736e // "prsn"
+
+// migrations son las versiones del esquema, en orden; nunca se editan, solo se agregan
+var migrations = []string{
+	// 1: tablas iniciales
+	`CREATE TABLE IF NOT EXISTS {s}.printers (
+		printer_id    text PRIMARY KEY,
+		agent_id      text NOT NULL,
+		tenant        text,
+		ip            text NOT NULL,
+		brand         text,
+		model         text,
+		serial_number text,
+		hostname      text,
+		mac_address   text,
+		site          text,
+		building      text,
+		floor         text,
+		room          text,
+		tags          jsonb,
+		state         text,
+		page_count    bigint,
+		first_seen_at timestamptz NOT NULL,
+		last_seen_at  timestamptz NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS {s}.supplies (
+		printer_id    text NOT NULL REFERENCES {s}.printers (printer_id) ON DELETE CASCADE,
+		supply_id     text NOT NULL,
+		name          text,
+		type          text,
+		level         bigint,
+		max_level     bigint,
+		percentage    integer,
+		status        text,
+		level_state   text,
+		model         text,
+		serial_number text,
+		updated_at    timestamptz NOT NULL,
+		PRIMARY KEY (printer_id, supply_id)
+	);
+	CREATE TABLE IF NOT EXISTS {s}.counters (
+		printer_id        text NOT NULL REFERENCES {s}.printers (printer_id) ON DELETE CASCADE,
+		collected_at      timestamptz NOT NULL,
+		event_id          text,
+		total_pages       bigint NOT NULL,
+		mono_pages        bigint NOT NULL,
+		color_pages       bigint NOT NULL,
+		scan_pages        bigint NOT NULL,
+		copy_pages        bigint NOT NULL,
+		fax_pages         bigint NOT NULL,
+		duplex_pages      bigint NOT NULL,
+		a3_pages          bigint NOT NULL,
+		delta_total_pages bigint,
+		delta_mono_pages  bigint,
+		delta_color_pages bigint,
+		reset_detected    boolean NOT NULL DEFAULT false,
+		PRIMARY KEY (printer_id, collected_at)
+	);
+	CREATE TABLE IF NOT EXISTS {s}.alerts (
+		printer_id   text NOT NULL REFERENCES {s}.printers (printer_id) ON DELETE CASCADE,
+		alert_id     text NOT NULL,
+		type         text,
+		severity     text,
+		message      text,
+		detected_at  timestamptz,
+		last_seen_at timestamptz NOT NULL,
+		cleared_at   timestamptz,
+		PRIMARY KEY (printer_id, alert_id)
+	);
+	CREATE INDEX IF NOT EXISTS alerts_open_idx ON {s}.alerts (printer_id) WHERE cleared_at IS NULL;`,
+}
+
+// migrate crea el esquema y aplica las migraciones que falten, cada una en su transacción
+func (ps *PostgresSink) migrate(ctx context.Context) error {
+	setup := ps.sql(`CREATE SCHEMA IF NOT EXISTS {s};
+		CREATE TABLE IF NOT EXISTS {s}.schema_migrations (
+			version    integer PRIMARY KEY,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		);`)
+	if err := ps.conn.SimpleExec(ctx, setup); err != nil {
+		return err
+	}
+
+	if err := ps.conn.SimpleExec(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", migrationLock)); err != nil {
+		return err
+	}
+	defer ps.conn.SimpleExec(context.Background(), fmt.Sprintf("SELECT pg_advisory_unlock(%d)", migrationLock))
+
+	rows, err := ps.conn.Query(ctx, ps.sql(`SELECT COALESCE(MAX(version), 0)::text FROM {s}.schema_migrations`))
+	if err != nil {
+		return err
+	}
+	applied := 0
+	if len(rows) == 1 && len(rows[0]) == 1 && rows[0][0] != nil {
+		fmt.Sscanf(*rows[0][0], "%d", &applied)
+	}
+
+	for version := applied + 1; version <= len(migrations); version++ {
+		script := fmt.Sprintf("BEGIN;\n%s\nINSERT INTO %s.schema_migrations (version, applied_at) VALUES (%d, '%s');\nCOMMIT;",
+			ps.sql(migrations[version-1]), ps.schema, version, time.Now().UTC().Format(time.RFC3339))
+		if err := ps.conn.SimpleExec(ctx, script); err != nil {
+			ps.conn.SimpleExec(ctx, "ROLLBACK")
+			return fmt.Errorf("versión %d: %w", version, err)
+		}
+	}
+	return nil
+}
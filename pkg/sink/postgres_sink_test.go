@@ -0,0 +1,57 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/pg"
+)
+
+// Sin conexión posible: lo que no es telemetría no debe intentar conectarse
+func TestPostgresSinkSkipsNonTelemetry(t *testing.T) {
+	ps := NewPostgresSink(PostgresSinkConfig{Conn: pg.Config{Host: "127.0.0.1", Port: "1", SSLMode: "disable"}})
+	for _, data := range []string{
+		`{"event_type":"agent_heartbeat","event_id":"AGT::heartbeat::1"}`,
+		`{"event_type":"inventory","printers":[]}`,
+	} {
+		if err := ps.Write(context.Background(), []byte(data), ""); err != nil {
+			t.Errorf("%s: %v", data, err)
+		}
+	}
+
+	var sinkErr *SinkError
+	err := ps.Write(context.Background(), []byte("{no es json"), "p1")
+	if !errors.As(err, &sinkErr) || !sinkErr.Permanent || sinkErr.Operation != "decode" {
+		t.Errorf("JSON inválido: %v, se esperaba un error permanente de decode", err)
+	}
+}
+
+func TestPostgresSinkErrorClassification(t *testing.T) {
+	ps := NewPostgresSink(PostgresSinkConfig{})
+	for _, tc := range []struct {
+		err       error
+		permanent bool
+	}{
+		{&pg.Error{Code: "23505"}, true},  // unique_violation
+		{&pg.Error{Code: "22001"}, true},  // string_data_right_truncation
+		{&pg.Error{Code: "40001"}, false}, // serialization_failure: se reintenta
+		{&pg.Error{Code: "57P01"}, false}, // admin_shutdown
+		{errors.New("connection refused"), false},
+	} {
+		var sinkErr *SinkError
+		if !errors.As(ps.sinkError("write", tc.err, "p1"), &sinkErr) || sinkErr.Permanent != tc.permanent {
+			t.Errorf("%v: permanente=%v, se esperaba %v", tc.err, sinkErr.Permanent, tc.permanent)
+		}
+	}
+}
+
+func TestPostgresSinkSchemaQuoting(t *testing.T) {
+	ps := NewPostgresSink(PostgresSinkConfig{Schema: `flota"; DROP TABLE x; --`})
+	if got := ps.sql("SELECT 1 FROM {s}.printers"); got != `SELECT 1 FROM "flota""; DROP TABLE x; --".printers` {
+		t.Errorf("sql = %s", got)
+	}
+	if got := NewPostgresSink(PostgresSinkConfig{}).sql("{s}.alerts"); got != `"printsnmp".alerts` {
+		t.Errorf("esquema por defecto: %s", got)
+	}
+}
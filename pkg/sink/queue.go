@@ -0,0 +1,359 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/fsutil"
+)
+
+// DeadLetterDir es el subdirectorio de la queue con los eventos descartados
+const DeadLetterDir = "deadletter"
+
+// QueuePolicy define cuándo un evento de la queue se da por perdido
+type QueuePolicy struct {
+	MaxAttempts int           // intentos de subida antes de ir a deadletter (0 = sin límite)
+	MaxAge      time.Duration // antigüedad máxima de un evento (0 = sin límite)
+}
+
+// Queue gestiona los eventos que el FileSink dejó en disco:
+// los sube a otro Sink, cuenta intentos fallidos y mueve los
+// "poison messages" a deadletter/ con el error registrado
+type Queue struct {
+	dir    string
+	policy QueuePolicy
+}
+
+// QueueEntry es un evento pendiente en la queue
+type QueueEntry struct {
+	Name      string    // nombre de archivo ({epoch}_{printer_id}.json)
+	PrinterID string    // extraído del nombre
+	QueuedAt  time.Time // epoch del nombre
+	Attempts  int       // intentos de subida fallidos
+	LastError string    // último error de subida
+}
+
+// attemptMeta se guarda junto a cada evento que falló (<evento>.meta)
+type attemptMeta struct {
+	Attempts       int       `json:"attempts"`
+	FirstAttempt   time.Time `json:"first_attempt_at"`
+	LastAttempt    time.Time `json:"last_attempt_at"`
+	LastError      string    `json:"last_error"`
+	DeadReason     string    `json:"dead_reason,omitempty"`
+	DeadLetteredAt time.Time `json:"deadlettered_at,omitempty"`
+}
+
+// DrainStats resume una pasada de Drain
+type DrainStats struct {
+	Sent         int
+	Failed       int
+	DeadLettered int
+}
+
+// NewQueue abre la queue en dir (el mismo directorio del FileSink)
+func NewQueue(dir string, policy QueuePolicy) (*Queue, error) {
+	if err := os.MkdirAll(filepath.Join(dir, DeadLetterDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create deadletter directory: %w", err)
+	}
+	return &Queue{dir: dir, policy: policy}, nil
+}
+
+// Entries lista los eventos pendientes, del más antiguo al más nuevo
+func (q *Queue) Entries() ([]QueueEntry, error) {
+	return q.list(q.dir)
+}
+
+// DeadLetters lista los eventos en deadletter/
+func (q *Queue) DeadLetters() ([]QueueEntry, error) {
+	return q.list(filepath.Join(q.dir, DeadLetterDir))
+}
+
+// Drain intenta subir cada evento pendiente a dest
+// Éxito → se borra; fallo → se registra el intento; agotado, vencido o
+// rechazado permanentemente (4xx) → deadletter/
+// Se detiene si ctx se cancela
+// Si dest es un BatchSink con ventana, los eventos se suben por lotes (drainBatches)
+func (q *Queue) Drain(ctx context.Context, dest Sink) (DrainStats, error) {
+	var stats DrainStats
+
+	entries, err := q.Entries()
+	if err != nil {
+		return stats, err
+	}
+	if batch, ok := dest.(BatchSink); ok && batch.BatchWindow() > 0 {
+		return q.drainBatches(ctx, batch, entries)
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		if q.policy.MaxAge > 0 && time.Since(entry.QueuedAt) > q.policy.MaxAge {
+			if err := q.deadLetter(entry.Name, fmt.Sprintf("max age exceeded (%s)", q.policy.MaxAge), nil); err != nil {
+				return stats, err
+			}
+			stats.DeadLettered++
+			continue
+		}
+
+		path := filepath.Join(q.dir, entry.Name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return stats, err
+		}
+
+		sendErr := dest.Write(ctx, data, entry.PrinterID)
+		if sendErr == nil {
+			os.Remove(path)
+			os.Remove(path + ".meta")
+			stats.Sent++
+			continue
+		}
+
+		meta := q.recordFailure(entry.Name, sendErr)
+
+		var sinkErr *SinkError
+		switch {
+		case errors.As(sendErr, &sinkErr) && sinkErr.Permanent:
+			err = q.deadLetter(entry.Name, "rejected by destination", meta)
+		case q.policy.MaxAttempts > 0 && meta.Attempts >= q.policy.MaxAttempts:
+			err = q.deadLetter(entry.Name, fmt.Sprintf("max attempts reached (%d)", meta.Attempts), meta)
+		default:
+			stats.Failed++
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.DeadLettered++
+	}
+
+	return stats, nil
+}
+
+// drainBatches sube los eventos agrupados por la ventana de dest
+// La ventana en curso no se sube (puede seguir recibiendo eventos): queda para
+// el próximo Drain. Un lote se sube entero o falla entero; el fallo cuenta como
+// intento para cada uno de sus eventos
+func (q *Queue) drainBatches(ctx context.Context, dest BatchSink, entries []QueueEntry) (DrainStats, error) {
+	var stats DrainStats
+	window := dest.BatchWindow()
+	current := time.Now().Truncate(window)
+
+	var start time.Time
+	var batch []QueueEntry
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() { batch = nil }()
+
+		events := make([]BatchEvent, 0, len(batch))
+		for _, entry := range batch {
+			data, err := os.ReadFile(filepath.Join(q.dir, entry.Name))
+			if err != nil {
+				return err
+			}
+			events = append(events, BatchEvent{PrinterID: entry.PrinterID, QueuedAt: entry.QueuedAt, Data: data})
+		}
+
+		sendErr := dest.WriteBatch(ctx, start, events)
+		if sendErr == nil {
+			for _, entry := range batch {
+				path := filepath.Join(q.dir, entry.Name)
+				os.Remove(path)
+				os.Remove(path + ".meta")
+			}
+			stats.Sent += len(batch)
+			return nil
+		}
+
+		var sinkErr *SinkError
+		permanent := errors.As(sendErr, &sinkErr) && sinkErr.Permanent
+		for _, entry := range batch {
+			meta := q.recordFailure(entry.Name, sendErr)
+			var err error
+			switch {
+			case permanent:
+				err = q.deadLetter(entry.Name, "rejected by destination", meta)
+			case q.policy.MaxAttempts > 0 && meta.Attempts >= q.policy.MaxAttempts:
+				err = q.deadLetter(entry.Name, fmt.Sprintf("max attempts reached (%d)", meta.Attempts), meta)
+			default:
+				stats.Failed++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			stats.DeadLettered++
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		if q.policy.MaxAge > 0 && time.Since(entry.QueuedAt) > q.policy.MaxAge {
+			if err := q.deadLetter(entry.Name, fmt.Sprintf("max age exceeded (%s)", q.policy.MaxAge), nil); err != nil {
+				return stats, err
+			}
+			stats.DeadLettered++
+			continue
+		}
+
+		entryWindow := entry.QueuedAt.Truncate(window)
+		if !entryWindow.Before(current) {
+			break // entries está ordenado: el resto también es de la ventana en curso
+		}
+		if !entryWindow.Equal(start) {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+			start = entryWindow
+		}
+		batch = append(batch, entry)
+	}
+	return stats, flush()
+}
+
+// Requeue devuelve un evento de deadletter/ a la queue con los intentos en cero
+func (q *Queue) Requeue(name string) error {
+	name = filepath.Base(name)
+	src := filepath.Join(q.dir, DeadLetterDir, name)
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("deadletter entry %s not found: %w", name, err)
+	}
+
+	if err := os.Rename(src, filepath.Join(q.dir, name)); err != nil {
+		return err
+	}
+	os.Remove(src + ".meta")
+	return nil
+}
+
+// RequeueAll devuelve todos los eventos de deadletter/ a la queue
+func (q *Queue) RequeueAll() (int, error) {
+	dead, err := q.DeadLetters()
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range dead {
+		if err := q.Requeue(entry.Name); err != nil {
+			return i, err
+		}
+	}
+	return len(dead), nil
+}
+
+// list lee los eventos *.json de dir junto con su metadata de intentos
+func (q *Queue) list(dir string) ([]QueueEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]QueueEntry, 0, len(paths))
+	for _, path := range paths {
+		entry := parseQueueName(filepath.Base(path))
+		if meta, ok := readMeta(path + ".meta"); ok {
+			entry.Attempts = meta.Attempts
+			entry.LastError = meta.LastError
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].QueuedAt.Equal(entries[j].QueuedAt) {
+			return entries[i].QueuedAt.Before(entries[j].QueuedAt)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// recordFailure suma un intento fallido a la metadata del evento
+func (q *Queue) recordFailure(name string, sendErr error) *attemptMeta {
+	metaPath := filepath.Join(q.dir, name) + ".meta"
+	meta, ok := readMeta(metaPath)
+	if !ok {
+		meta = &attemptMeta{FirstAttempt: time.Now().UTC()}
+	}
+
+	meta.Attempts++
+	meta.LastAttempt = time.Now().UTC()
+	meta.LastError = sendErr.Error()
+
+	writeMeta(metaPath, meta)
+	return meta
+}
+
+// deadLetter mueve un evento (y su metadata) a deadletter/ registrando el motivo
+func (q *Queue) deadLetter(name, reason string, meta *attemptMeta) error {
+	src := filepath.Join(q.dir, name)
+	dst := filepath.Join(q.dir, DeadLetterDir, name)
+
+	if meta == nil {
+		if m, ok := readMeta(src + ".meta"); ok {
+			meta = m
+		} else {
+			meta = &attemptMeta{}
+		}
+	}
+	meta.DeadReason = reason
+	meta.DeadLetteredAt = time.Now().UTC()
+
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move %s to deadletter: %w", name, err)
+	}
+	os.Remove(src + ".meta")
+	return writeMeta(dst+".meta", meta)
+}
+
+// parseQueueName interpreta {epoch}_{printer_id}.json (ver FileSink.Write)
+func parseQueueName(name string) QueueEntry {
+	entry := QueueEntry{Name: name}
+	base := strings.TrimSuffix(name, ".json")
+
+	epochStr, printerID, found := strings.Cut(base, "_")
+	if !found {
+		entry.PrinterID = base
+		return entry
+	}
+	entry.PrinterID = printerID
+
+	var epoch int64
+	if _, err := fmt.Sscanf(epochStr, "%d", &epoch); err == nil {
+		entry.QueuedAt = time.Unix(epoch, 0)
+	}
+	return entry
+}
+
+func readMeta(path string) (*attemptMeta, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var meta attemptMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, false
+	}
+	return &meta, true
+}
+
+func writeMeta(path string, meta *attemptMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,191 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSink guarda lo que recibe; err (si no es nil) lo retorna en cada Write
+type recordingSink struct {
+	got []string
+	err error
+}
+
+func (s *recordingSink) Write(_ context.Context, data []byte, printerID string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.got = append(s.got, printerID+":"+string(data))
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+// enqueue deja un evento en dir como lo haría FileSink.Write, con epoch fijo
+func enqueue(t *testing.T, dir string, epoch int64, printerID, data string) string {
+	t.Helper()
+	name := fmt.Sprintf("%d_%s.json", epoch, printerID)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestQueueDrainOrder(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, QueuePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	enqueue(t, dir, now-10, "p2", `{"n":2}`)
+	enqueue(t, dir, now-20, "p1", `{"n":1}`)
+	enqueue(t, dir, now, "p3", `{"n":3}`)
+	enqueue(t, dir, now-10, "p0", `{"n":0}`) // mismo segundo: desempata el nombre
+
+	dest := &recordingSink{}
+	stats, err := q.Drain(context.Background(), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`p1:{"n":1}`, `p0:{"n":0}`, `p2:{"n":2}`, `p3:{"n":3}`}
+	if strings.Join(dest.got, " ") != strings.Join(want, " ") {
+		t.Errorf("orden %v, se esperaba %v", dest.got, want)
+	}
+	if stats.Sent != 4 || stats.Failed != 0 || stats.DeadLettered != 0 {
+		t.Errorf("stats %+v", stats)
+	}
+	if entries, _ := q.Entries(); len(entries) != 0 {
+		t.Errorf("quedaron %d eventos después de subirlos", len(entries))
+	}
+}
+
+func TestQueueDeadLetterAfterMaxAttempts(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, QueuePolicy{MaxAttempts: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := enqueue(t, dir, time.Now().Unix(), "p1", `{}`)
+	dest := &recordingSink{err: &SinkError{Sink: "http", Operation: "write", Err: errors.New("503")}}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		stats, err := q.Drain(context.Background(), dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := q.Entries()
+		if stats.Failed != 1 || len(entries) != 1 || entries[0].Attempts != attempt {
+			t.Fatalf("intento %d: stats %+v, entries %+v", attempt, stats, entries)
+		}
+		if !strings.Contains(entries[0].LastError, "503") {
+			t.Errorf("último error %q", entries[0].LastError)
+		}
+	}
+
+	stats, err := q.Drain(context.Background(), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DeadLettered != 1 {
+		t.Fatalf("tercer intento: stats %+v", stats)
+	}
+	dead, _ := q.DeadLetters()
+	if len(dead) != 1 || dead[0].Name != name || dead[0].Attempts != 3 {
+		t.Fatalf("deadletter %+v", dead)
+	}
+	meta, ok := readMeta(filepath.Join(dir, DeadLetterDir, name+".meta"))
+	if !ok || !strings.Contains(meta.DeadReason, "max attempts") {
+		t.Errorf("metadata %+v", meta)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name+".meta")); !os.IsNotExist(err) {
+		t.Error("la metadata quedó en la queue")
+	}
+
+	// Requeue lo devuelve con los intentos en cero
+	if err := q.Requeue(name); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := q.Entries()
+	if len(entries) != 1 || entries[0].Attempts != 0 {
+		t.Errorf("tras requeue: %+v", entries)
+	}
+}
+
+func TestQueueDeadLetterPermanentAndExpired(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, QueuePolicy{MaxAttempts: 10, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enqueue(t, dir, time.Now().Add(-2*time.Hour).Unix(), "viejo", `{}`)
+	enqueue(t, dir, time.Now().Unix(), "rechazado", `{}`)
+
+	dest := &recordingSink{err: &SinkError{Sink: "http", Operation: "write", Err: errors.New("400"), Permanent: true}}
+	stats, err := q.Drain(context.Background(), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DeadLettered != 2 || stats.Failed != 0 {
+		t.Errorf("stats %+v, se esperaban 2 a deadletter", stats)
+	}
+	for _, tc := range []struct{ printer, reason string }{{"viejo", "max age"}, {"rechazado", "rejected"}} {
+		matches, _ := filepath.Glob(filepath.Join(dir, DeadLetterDir, "*_"+tc.printer+".json.meta"))
+		if len(matches) != 1 {
+			t.Fatalf("%s: sin metadata en deadletter", tc.printer)
+		}
+		if meta, ok := readMeta(matches[0]); !ok || !strings.Contains(meta.DeadReason, tc.reason) {
+			t.Errorf("%s: motivo %+v, se esperaba %q", tc.printer, meta, tc.reason)
+		}
+	}
+}
+
+func TestQueueDrainStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, QueuePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enqueue(t, dir, time.Now().Unix(), "p1", `{}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.Drain(ctx, &recordingSink{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Drain = %v, se esperaba Canceled", err)
+	}
+	if entries, _ := q.Entries(); len(entries) != 1 {
+		t.Error("se tocó la queue con el contexto cancelado")
+	}
+}
+
+// Una metadata ilegible (crash a mitad de escritura) no frena la subida:
+// los intentos vuelven a contar desde cero
+func TestQueueCorruptMeta(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(dir, QueuePolicy{MaxAttempts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := enqueue(t, dir, time.Now().Unix(), "p1", `{}`)
+	if err := os.WriteFile(filepath.Join(dir, name+".meta"), []byte(`{"attempts":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := q.Entries()
+	if err != nil || len(entries) != 1 || entries[0].Attempts != 0 {
+		t.Fatalf("entries %+v, %v", entries, err)
+	}
+	stats, err := q.Drain(context.Background(), &recordingSink{err: errors.New("timeout")})
+	if err != nil || stats.Failed != 1 {
+		t.Fatalf("stats %+v, %v", stats, err)
+	}
+	if entries, _ := q.Entries(); entries[0].Attempts != 1 {
+		t.Errorf("intentos %d tras reescribir la metadata, se esperaba 1", entries[0].Attempts)
+	}
+}
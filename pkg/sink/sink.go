@@ -31,6 +31,7 @@ type SinkError struct {
 	Operation string // operación que falló (write, connect, etc)
 	Err       error  // error subyacente
 	PrinterID string // ID de la impresora que causó el error
+	Permanent bool   // true si reintentar no sirve (ej: HTTP 4xx)
 }
 
 // Error implementa la interfaz error
@@ -42,6 +43,5 @@ func (se *SinkError) Error() string {
 func (se *SinkError) IsRetryable() bool {
 	// Los errores de red son recuperables
 	// Los errores de validación/auth no
-	// Esto se expandirá según necesidad
-	return se.Err != nil
+	return se.Err != nil && !se.Permanent
 }
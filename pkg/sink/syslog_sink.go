@@ -0,0 +1,388 @@
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogSink envía un resumen compacto de cada evento a un colector syslog
+// (RFC 5424) para integrarlo a un SIEM sin endpoint HTTP
+// Una telemetría genera un mensaje "telemetry" y uno "alert" por cada alerta;
+// los demás eventos (heartbeat, inventario, comandos) uno con su event_type
+// El JSON completo sigue yendo a la queue: syslog es solo una notificación
+type SyslogSink struct {
+	config SyslogSinkConfig
+	tls    *tls.Config
+	host   string
+
+	mu   sync.Mutex
+	conn net.Conn // TCP/TLS: conexión persistente, se reabre si falla
+}
+
+// SyslogSinkConfig configura un SyslogSink
+type SyslogSinkConfig struct {
+	Address   string        // host:puerto del colector
+	Transport string        // udp | tcp | tls (default udp)
+	Facility  int           // 0-23 (default 16 = local0)
+	AppName   string        // APP-NAME (default "printsnmp")
+	Hostname  string        // HOSTNAME (default: el del SO)
+	Timeout   time.Duration // conexión y escritura (default 5s)
+	TLS       TLSConfig     // CA propia y certificado de cliente (transport tls)
+}
+
+// Transportes de SyslogSinkConfig
+const (
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+	SyslogTLS = "tls"
+)
+
+// Severidades syslog usadas (RFC 5424 §6.2.1)
+const (
+	syslogCritical = 2
+	syslogWarning  = 4
+	syslogNotice   = 5
+	syslogInfo     = 6
+)
+
+// syslogSDID es el SD-ID de los datos estructurados (32473 = PEN de ejemplo de RFC 5612)
+const syslogSDID = "printsnmp@32473"
+
+// maxSyslogUDP es el tamaño máximo de un datagrama (RFC 5426 recomienda no pasar de 2048)
+const maxSyslogUDP = 2048
+
+// NewSyslogSink crea un syslog sink; la conexión se abre en el primer Write
+// Falla si la configuración o los certificados no son válidos
+func NewSyslogSink(config SyslogSinkConfig) (*SyslogSink, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", config.Address, err)
+	}
+	if config.Transport == "" {
+		config.Transport = SyslogUDP
+	}
+	if config.Facility == 0 {
+		config.Facility = 16
+	}
+	if config.Facility < 0 || config.Facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d (0-23)", config.Facility)
+	}
+	if config.AppName == "" {
+		config.AppName = "printsnmp"
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	ss := &SyslogSink{config: config, host: syslogField(config.Hostname, 255)}
+	switch config.Transport {
+	case SyslogUDP, SyslogTCP:
+	case SyslogTLS:
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(config.Address)
+		}
+		ss.tls = tlsConfig
+	default:
+		return nil, fmt.Errorf("invalid syslog transport %q (udp, tcp, tls)", config.Transport)
+	}
+	return ss, nil
+}
+
+// Write resume el evento y envía sus mensajes
+func (ss *SyslogSink) Write(ctx context.Context, data []byte, printerID string) error {
+	records, err := syslogRecords(data)
+	if err != nil {
+		return &SinkError{Sink: "syslog", Operation: "parse", Err: err, PrinterID: printerID, Permanent: true}
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := ss.send(ss.format(r)); err != nil {
+			return &SinkError{Sink: "syslog", Operation: "write", Err: err, PrinterID: printerID}
+		}
+	}
+	return nil
+}
+
+// Close cierra la conexión abierta (si hay)
+func (ss *SyslogSink) Close() error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.conn == nil {
+		return nil
+	}
+	err := ss.conn.Close()
+	ss.conn = nil
+	return err
+}
+
+// send escribe un mensaje; en TCP/TLS reintenta una vez con una conexión nueva
+// (el colector pudo haber cerrado la anterior)
+func (ss *SyslogSink) send(msg []byte) error {
+	if ss.config.Transport == SyslogUDP {
+		if len(msg) > maxSyslogUDP {
+			msg = msg[:maxSyslogUDP]
+		}
+	} else {
+		// Octet counting (RFC 6587 §3.4.1 / RFC 5425 §4.3): "<largo> <mensaje>"
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if ss.conn == nil {
+			conn, err := ss.dial()
+			if err != nil {
+				return err
+			}
+			ss.conn = conn
+		}
+		ss.conn.SetWriteDeadline(time.Now().Add(ss.config.Timeout))
+		_, err := ss.conn.Write(msg)
+		if err == nil {
+			return nil
+		}
+		ss.conn.Close()
+		ss.conn = nil
+		if attempt > 0 || ss.config.Transport == SyslogUDP {
+			return err
+		}
+	}
+}
+
+// dial abre la conexión según el transporte
+func (ss *SyslogSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: ss.config.Timeout}
+	switch ss.config.Transport {
+	case SyslogTLS:
+		return tls.DialWithDialer(dialer, "tcp", ss.config.Address, ss.tls)
+	case SyslogTCP:
+		return dialer.Dial("tcp", ss.config.Address)
+	default:
+		return dialer.Dial("udp", ss.config.Address)
+	}
+}
+
+// syslogRecord es un mensaje a enviar
+type syslogRecord struct {
+	severity  int
+	timestamp time.Time
+	msgID     string
+	params    [][2]string // datos estructurados, en orden
+	message   string
+}
+
+// format arma el mensaje RFC 5424:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (ss *SyslogSink) format(r syslogRecord) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		ss.config.Facility*8+r.severity,
+		r.timestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
+		nilValue(ss.host),
+		nilValue(syslogField(ss.config.AppName, 48)),
+		os.Getpid(),
+		nilValue(syslogField(r.msgID, 32)))
+
+	if len(r.params) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for _, p := range r.params {
+			if p[1] == "" {
+				continue
+			}
+			fmt.Fprintf(&b, ` %s="%s"`, p[0], sdEscaper.Replace(p[1]))
+		}
+		b.WriteString("]")
+	}
+	if r.message != "" {
+		b.WriteString(" " + r.message)
+	}
+	return []byte(b.String())
+}
+
+// sdEscaper escapa PARAM-VALUE (RFC 5424 §6.3.3)
+var sdEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// syslogField deja solo ASCII imprimible sin espacios (HOSTNAME, APP-NAME, MSGID)
+func syslogField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// nilValue es "-" para un campo vacío
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogEvent son los campos de un evento que se resumen en syslog
+type syslogEvent struct {
+	EventType   string    `json:"event_type"`
+	EventID     string    `json:"event_id"`
+	CollectedAt time.Time `json:"collected_at"`
+	EmittedAt   time.Time `json:"emitted_at"`
+	DetectedAt  time.Time `json:"detected_at"`
+	Printer     *struct {
+		ID    string  `json:"id"`
+		IP    string  `json:"ip"`
+		Brand string  `json:"brand"`
+		Model *string `json:"model"`
+		Site  *struct {
+			Site string `json:"site"`
+		} `json:"site"`
+	} `json:"printer"`
+	Status *struct {
+		State     string `json:"state"`
+		PageCount int64  `json:"page_count"`
+	} `json:"status"`
+	Alerts []struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+	} `json:"alerts"`
+	Change *struct {
+		Change  string `json:"change"`
+		Current *struct {
+			PrinterID string `json:"printer_id"`
+			IP        string `json:"ip"`
+		} `json:"current"`
+		Previous *struct {
+			PrinterID string `json:"printer_id"`
+			IP        string `json:"ip"`
+		} `json:"previous"`
+	} `json:"change"`
+}
+
+// syslogRecords arma los mensajes de un evento serializado
+func syslogRecords(data []byte) ([]syslogRecord, error) {
+	var ev syslogEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, err
+	}
+
+	if ev.Printer != nil {
+		return telemetryRecords(&ev), nil
+	}
+
+	at := firstTime(ev.EmittedAt, ev.DetectedAt)
+	r := syslogRecord{
+		severity:  syslogInfo,
+		timestamp: at,
+		msgID:     nilValue(ev.EventType),
+		params:    [][2]string{{"event_id", ev.EventID}},
+		message:   ev.EventType,
+	}
+	if c := ev.Change; c != nil {
+		r.severity = syslogNotice
+		entry := c.Current
+		if entry == nil {
+			entry = c.Previous
+		}
+		r.params = append(r.params, [2]string{"change", c.Change})
+		if entry != nil {
+			r.params = append(r.params, [2]string{"printer_id", entry.PrinterID}, [2]string{"ip", entry.IP})
+			r.message = fmt.Sprintf("printer %s (%s) %s", entry.PrinterID, entry.IP, c.Change)
+		}
+	}
+	return []syslogRecord{r}, nil
+}
+
+// telemetryRecords resume una telemetría: un mensaje de estado y uno por alerta
+func telemetryRecords(ev *syslogEvent) []syslogRecord {
+	p := ev.Printer
+	at := firstTime(ev.CollectedAt)
+	model, site, state := "", "", ""
+	var pages int64
+	if p.Model != nil {
+		model = *p.Model
+	}
+	if p.Site != nil {
+		site = p.Site.Site
+	}
+	if ev.Status != nil {
+		state = ev.Status.State
+		pages = ev.Status.PageCount
+	}
+
+	base := [][2]string{
+		{"event_id", ev.EventID},
+		{"printer_id", p.ID},
+		{"ip", p.IP},
+		{"brand", p.Brand},
+		{"model", model},
+		{"site", site},
+	}
+	records := []syslogRecord{{
+		severity:  syslogInfo,
+		timestamp: at,
+		msgID:     "telemetry",
+		params: append(append([][2]string{}, base...),
+			[2]string{"state", state},
+			[2]string{"page_count", fmt.Sprint(pages)},
+			[2]string{"alerts", fmt.Sprint(len(ev.Alerts))}),
+		message: fmt.Sprintf("printer %s (%s) state=%s pages=%d alerts=%d", p.ID, p.IP, nilValue(state), pages, len(ev.Alerts)),
+	}}
+
+	for _, alert := range ev.Alerts {
+		severity := syslogNotice
+		switch alert.Severity {
+		case "critical":
+			severity = syslogCritical
+		case "warning":
+			severity = syslogWarning
+		}
+		records = append(records, syslogRecord{
+			severity:  severity,
+			timestamp: at,
+			msgID:     "alert",
+			params: append(append([][2]string{}, base...),
+				[2]string{"alert_id", alert.ID},
+				[2]string{"alert_type", alert.Type},
+				[2]string{"severity", alert.Severity}),
+			message: fmt.Sprintf("printer %s (%s): %s", p.ID, p.IP, alert.Message),
+		})
+	}
+	return records
+}
+
+// firstTime retorna el primer instante no nulo (o el actual)
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Now()
+}
@@ -0,0 +1,76 @@
+package sink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLSConfig configura mTLS y CA propia para el HTTPSink
+type TLSConfig struct {
+	CAFile         string // bundle PEM de CAs del gateway (vacío = CAs del sistema)
+	ClientCertFile string // certificado PEM del agente (mTLS)
+	ClientKeyFile  string // llave privada PEM del agente (mTLS)
+	ServerName     string // override de SNI/verificación (opcional)
+}
+
+// enabled indica si hay algo que configurar (si no, se usa el transporte por defecto)
+func (c TLSConfig) enabled() bool {
+	return c.CAFile != "" || c.ClientCertFile != "" || c.ClientKeyFile != "" || c.ServerName != ""
+}
+
+// build construye el *tls.Config
+func (c TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", c.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("mTLS requires both client certificate and key")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// NewHTTPClient crea el cliente HTTP hacia el backend con la CA y el mTLS
+// configurados. Lo comparten el HTTPSink y el pull de configuración remota
+func NewHTTPClient(c TLSConfig, timeout time.Duration) (*http.Client, error) {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	if c.enabled() {
+		tlsConfig, err := c.build()
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return client, nil
+}
@@ -0,0 +1,107 @@
+package snmp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Estados de un registro de auditoría
+const (
+	AuditAnswered = "answered" // el equipo respondió un valor
+	AuditMissing  = "missing"  // noSuchObject/noSuchInstance o WALK sin filas
+	AuditFailed   = "failed"   // timeout o error SNMP
+	AuditDenied   = "denied"   // bloqueado por la política de OIDs: no se envió
+)
+
+// AuditRecord es una línea del log de auditoría: un OID consultado
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Host   string    `json:"host"`
+	Op     string    `json:"op"`             // get | walk
+	OID    string    `json:"oid"`            // OID leído (en un WALK, cada fila)
+	Base   string    `json:"base,omitempty"` // subárbol del WALK
+	Status string    `json:"status"`
+	Type   string    `json:"type,omitempty"` // tipo ASN.1 de la respuesta
+	Value  string    `json:"value,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// AuditLog es un Observer que escribe cada OID consultado como una línea
+// JSON (NDJSON): evidencia de exactamente qué lee el agente de cada equipo
+// Lo instala el motor (EngineConfig.Audit) para que cubra a todos los clientes
+type AuditLog struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool // ya se logueó un error de escritura
+}
+
+// NewAuditLog crea el log de auditoría sobre w (ej: un fsutil.RotatingFile)
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{enc: json.NewEncoder(w)}
+}
+
+// ObserveGet registra un GET
+func (a *AuditLog) ObserveGet(host, oid string, value Value, err error) {
+	record := AuditRecord{Time: time.Now().UTC(), Host: host, Op: "get", OID: strings.TrimPrefix(oid, ".")}
+	switch {
+	case err != nil:
+		record.Status, record.Error = auditErrorStatus(err), err.Error()
+	case value.IsNull():
+		record.Status, record.Type = AuditMissing, value.Type.String()
+	default:
+		record.Status, record.Type, record.Value = AuditAnswered, value.Type.String(), value.String()
+	}
+	a.write(record)
+}
+
+// ObserveWalk registra cada fila de un WALK (o el WALK si falló o vino vacío)
+func (a *AuditLog) ObserveWalk(host, baseOID string, results []WalkResult, err error) {
+	now := time.Now().UTC()
+	base := strings.TrimPrefix(baseOID, ".")
+	if err != nil || len(results) == 0 {
+		record := AuditRecord{Time: now, Host: host, Op: "walk", OID: base, Base: base, Status: AuditMissing}
+		if err != nil {
+			record.Status, record.Error = auditErrorStatus(err), err.Error()
+		}
+		a.write(record)
+		return
+	}
+	for _, row := range results {
+		a.write(AuditRecord{
+			Time:   now,
+			Host:   host,
+			Op:     "walk",
+			OID:    strings.TrimPrefix(row.OID, "."),
+			Base:   base,
+			Status: AuditAnswered,
+			Type:   row.Typed.Type.String(),
+			Value:  row.Value,
+		})
+	}
+}
+
+// auditErrorStatus distingue un OID bloqueado de un error del equipo
+func auditErrorStatus(err error) string {
+	if errors.Is(err, ErrOIDDenied) {
+		return AuditDenied
+	}
+	return AuditFailed
+}
+
+// write serializa un registro; un error de escritura se loguea una vez
+func (a *AuditLog) write(record AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.enc.Encode(record); err != nil && !a.failed {
+		a.failed = true
+		log.Print(i18n.T("log.audit_error", err))
+	}
+}
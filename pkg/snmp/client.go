@@ -1,25 +1,49 @@
 package snmp
 
 import (
-	"encoding/hex"
+	"context"
 	"fmt"
-	"strings"
+	"net"
+	"strconv"
 	"time"
 
+	"github.com/asaavedra/agent-snmp/pkg/retry"
 	"github.com/gosnmp/gosnmp"
 )
 
-// SNMPClient wrapper alrededor de gosnmp para manejar SNMP v1/v2c
+// SNMPClient wrapper alrededor de gosnmp para manejar SNMP v1/v2c/v3
 type SNMPClient struct {
 	host      string
 	port      uint16
 	community string
 	version   string
+	v3        *V3Credentials // credenciales USM (solo versión "3")
 	timeout   time.Duration
 	retries   int
+	engine    *Engine         // motor compartido (nil = sin límites globales)
+	policy    retry.Policy    // reintentos con backoff y jitter
+	ctx       context.Context // deadline/cancelación de las operaciones (nil = sin límite)
+	observer  Observer        // recibe cada GET/WALK completado (nil = nadie)
+	maxOids   int             // OIDs por GET (0 = defaultOidsPerRequest)
+	minDelay  time.Duration   // espera mínima entre paquetes al mismo agente (0 = sin espera)
+	priority  Priority        // clase frente a los slots del motor (ver Priority)
+}
+
+// defaultOidsPerRequest es el tamaño de batch de GetMultiple sin límite explícito
+// (gosnmp admite hasta 60 OIDs por GET; 50 es conservador)
+const defaultOidsPerRequest = 50
+
+// Observer recibe el resultado de cada OID consultado por un cliente (ver WithObserver)
+// Un GET múltiple se informa OID por OID; un WALK una vez por subárbol
+// Las llamadas llegan desde las goroutines que consultan: debe ser seguro para uso concurrente
+type Observer interface {
+	ObserveGet(host, oid string, value Value, err error)
+	ObserveWalk(host, baseOID string, results []WalkResult, err error)
 }
 
 // NewSNMPClient crea un nuevo cliente SNMP
+// retries se aplica con la política de pkg/retry (backoff con jitter), no con
+// los reintentos internos de gosnmp, para que todos los reintentos sean uniformes
 func NewSNMPClient(host string, port uint16, community, version string, timeout time.Duration, retries int) *SNMPClient {
 	return &SNMPClient{
 		host:      host,
@@ -28,35 +52,179 @@ func NewSNMPClient(host string, port uint16, community, version string, timeout
 		version:   version,
 		timeout:   timeout,
 		retries:   retries,
+		policy:    retry.SNMPPolicy(retries),
+	}
+}
+
+// WithContext retorna una copia del cliente cuyas operaciones respetan ctx
+// Al vencer el deadline las operaciones en curso se abortan y las siguientes fallan
+// de inmediato, sin reintentos
+func (sc *SNMPClient) WithContext(ctx context.Context) *SNMPClient {
+	clone := *sc
+	clone.ctx = ctx
+	return &clone
+}
+
+// WithObserver retorna una copia del cliente que informa sus operaciones a observer
+func (sc *SNMPClient) WithObserver(observer Observer) *SNMPClient {
+	clone := *sc
+	clone.observer = observer
+	return &clone
+}
+
+// WithLimits retorna una copia del cliente que cuida a equipos frágiles:
+// a lo sumo maxOids OIDs por GET y al menos minDelay entre paquetes al host
+// El espaciado lo lleva el motor por host, así que vale también entre clientes
+// distintos del mismo equipo; sin motor solo se aplica maxOids
+func (sc *SNMPClient) WithLimits(maxOids int, minDelay time.Duration) *SNMPClient {
+	clone := *sc
+	clone.maxOids = maxOids
+	clone.minDelay = minDelay
+	return &clone
+}
+
+// WithPriority retorna una copia del cliente cuyas operaciones toman los
+// slots del motor con prioridad p
+func (sc *SNMPClient) WithPriority(p Priority) *SNMPClient {
+	clone := *sc
+	clone.priority = p
+	return &clone
+}
+
+// Version retorna la versión SNMP del cliente ("1", "2c", "3")
+func (sc *SNMPClient) Version() string {
+	if sc.version == "" {
+		return "2c"
 	}
+	return sc.version
+}
+
+// WithVersion retorna una copia del cliente que habla la versión SNMP indicada
+func (sc *SNMPClient) WithVersion(version string) *SNMPClient {
+	clone := *sc
+	clone.version = version
+	return &clone
+}
+
+// WithV3 retorna una copia del cliente con credenciales USM (versión "3")
+func (sc *SNMPClient) WithV3(creds *V3Credentials) *SNMPClient {
+	clone := *sc
+	clone.v3 = creds
+	return &clone
+}
+
+// oidsPerRequest retorna cuántos OIDs enviar en cada GET
+func (sc *SNMPClient) oidsPerRequest() int {
+	if sc.maxOids > 0 && sc.maxOids < defaultOidsPerRequest {
+		return sc.maxOids
+	}
+	return defaultOidsPerRequest
+}
+
+// observeGet informa el resultado de un OID al observer y a la auditoría (si hay)
+func (sc *SNMPClient) observeGet(oid string, value Value, err error) {
+	if sc.observer != nil {
+		sc.observer.ObserveGet(sc.host, oid, value, err)
+	}
+	if audit := sc.engine.audit(); audit != nil {
+		audit.ObserveGet(sc.host, oid, value, err)
+	}
+}
+
+// observeWalk informa el resultado de un WALK al observer y a la auditoría (si hay)
+func (sc *SNMPClient) observeWalk(baseOID string, results []WalkResult, err error) {
+	if sc.observer != nil {
+		sc.observer.ObserveWalk(sc.host, baseOID, results, err)
+	}
+	if audit := sc.engine.audit(); audit != nil {
+		audit.ObserveWalk(sc.host, baseOID, results, err)
+	}
+}
+
+// target identifica al agente en el pacing y el backoff del motor: host:puerto,
+// porque varios agentes pueden compartir host (simuladores, NAT con un puerto
+// por equipo) y no deben frenarse entre sí
+func (sc *SNMPClient) target() string {
+	return net.JoinHostPort(sc.host, strconv.Itoa(int(sc.port)))
+}
+
+// context retorna el contexto del cliente (Background si no se asignó)
+func (sc *SNMPClient) context() context.Context {
+	if sc.ctx == nil {
+		return context.Background()
+	}
+	return sc.ctx
+}
+
+// do ejecuta una operación SNMP con slot del motor y política de reintentos
+// Cada intento abre su propia conexión y toma su propio slot: la espera entre
+// reintentos no ocupa un worker. Solo errores de red/timeout se reintentan
+func (sc *SNMPClient) do(op func(client *gosnmp.GoSNMP) error) error {
+	ctx := sc.context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return sc.policy.Do(ctx, func(attempt int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		release, err := sc.engine.acquire(ctx, sc.target(), sc.priority)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		client, err := sc.connect()
+		if err != nil {
+			return err
+		}
+		defer client.Conn.Close()
+
+		err = op(client)
+		sc.engine.report(sc.target(), err)
+		return err
+	})
 }
 
 // Get obtiene un único valor OID
 func (sc *SNMPClient) Get(oid string, ctx *Context) (interface{}, error) {
-	client, err := sc.connect()
-	if err != nil {
+	if err := sc.engine.checkOID(sc.host, "GET", oid); err != nil {
+		sc.observeGet(oid, Value{}, err)
 		return nil, err
 	}
-	defer client.Conn.Close()
 
-	result, err := client.Get([]string{oid})
+	var result *gosnmp.SnmpPacket
+	err := sc.do(func(client *gosnmp.GoSNMP) error {
+		var err error
+		result, err = client.Get([]string{oid})
+		return err
+	})
 	if err != nil {
+		sc.observeGet(oid, Value{}, err)
 		return nil, fmt.Errorf("error SNMP GET: %w", err)
 	}
 
 	if result == nil || len(result.Variables) == 0 {
-		return nil, fmt.Errorf("sin respuesta para OID: %s", oid)
+		err := fmt.Errorf("sin respuesta para OID: %s", oid)
+		sc.observeGet(oid, Value{}, err)
+		return nil, err
 	}
 
 	variable := result.Variables[0]
 
 	// Verificar si hay error en la respuesta
 	if result.Error != gosnmp.NoError {
-		return nil, fmt.Errorf("SNMP error %d: %s", result.Error, result.Error.String())
+		err := fmt.Errorf("SNMP error %d: %s", result.Error, result.Error.String())
+		sc.observeGet(oid, Value{}, err)
+		return nil, err
 	}
 
-	// Convertir valor a string
-	return ParseValue(variable), nil
+	// Valor tipado (fmt.Sprintf("%v") conserva la forma textual)
+	value := ParseValue(variable)
+	sc.observeGet(oid, value, nil)
+	return value, nil
 }
 
 // GetMultiple obtiene múltiples OIDs
@@ -65,16 +233,21 @@ func (sc *SNMPClient) GetMultiple(oids []string, ctx *Context) (map[string]inter
 		return make(map[string]interface{}), nil
 	}
 
-	client, err := sc.connect()
-	if err != nil {
-		return nil, err
-	}
-	defer client.Conn.Close()
-
 	values := make(map[string]interface{})
 
-	// Procesar en batches (Go SNMP tiene límite de 60 OIDs por GET)
-	maxOIDsPerBatch := 50 // Usar 50 para ser conservador
+	// Los OIDs bloqueados por la política no se envían (quedan sin valor)
+	allowed := make([]string, 0, len(oids))
+	for _, oid := range oids {
+		if err := sc.engine.checkOID(sc.host, "GET", oid); err != nil {
+			sc.observeGet(oid, Value{}, err)
+			continue
+		}
+		allowed = append(allowed, oid)
+	}
+	oids = allowed
+
+	// Procesar en batches (Go SNMP tiene límite de 60 OIDs por GET; ver WithLimits)
+	maxOIDsPerBatch := sc.oidsPerRequest()
 	for batchStart := 0; batchStart < len(oids); batchStart += maxOIDsPerBatch {
 		batchEnd := batchStart + maxOIDsPerBatch
 		if batchEnd > len(oids) {
@@ -82,19 +255,27 @@ func (sc *SNMPClient) GetMultiple(oids []string, ctx *Context) (map[string]inter
 		}
 		batchOIDs := oids[batchStart:batchEnd]
 
-		result, err := client.Get(batchOIDs)
+		var result *gosnmp.SnmpPacket
+		err := sc.do(func(client *gosnmp.GoSNMP) error {
+			var err error
+			result, err = client.Get(batchOIDs)
+			return err
+		})
+		if err == nil && result == nil {
+			err = fmt.Errorf("sin respuesta para OIDs")
+		}
 		if err != nil {
+			for _, oid := range batchOIDs {
+				sc.observeGet(oid, Value{}, err)
+			}
 			return nil, fmt.Errorf("error SNMP GET múltiple: %w", err)
 		}
 
-		if result == nil {
-			return nil, fmt.Errorf("sin respuesta para OIDs")
-		}
-
 		for i, variable := range result.Variables {
 			if i < len(batchOIDs) {
-				parsedValue := ParseValue(variable)
-				values[batchOIDs[i]] = parsedValue
+				value := ParseValue(variable)
+				values[batchOIDs[i]] = value
+				sc.observeGet(batchOIDs[i], value, nil)
 			}
 		}
 	}
@@ -105,28 +286,36 @@ func (sc *SNMPClient) GetMultiple(oids []string, ctx *Context) (map[string]inter
 // WalkResult contiene resultado de un SNMP WALK
 type WalkResult struct {
 	OID   string
-	Value string
+	Value string // representación textual (compatibilidad)
+	Typed Value  // valor con tipo SNMP preservado
 }
 
 // Walk realiza SNMP WALK de un OID base
 func (sc *SNMPClient) Walk(baseOID string, ctx *Context) ([]WalkResult, error) {
-	client, err := sc.connect()
-	if err != nil {
+	if err := sc.engine.checkOID(sc.host, "WALK", baseOID); err != nil {
+		sc.observeWalk(baseOID, nil, err)
 		return nil, err
 	}
-	defer client.Conn.Close()
 
 	var results []WalkResult
 
-	// gosnmp.WalkFunc es callback para cada OID encontrado
-	err = client.Walk(baseOID, func(dataUnit gosnmp.SnmpPDU) error {
-		results = append(results, WalkResult{
-			OID:   dataUnit.Name,
-			Value: ParseValue(dataUnit),
+	err := sc.do(func(client *gosnmp.GoSNMP) error {
+		// Un reintento recorre el árbol desde el principio
+		results = nil
+
+		// gosnmp.WalkFunc es callback para cada OID encontrado
+		return sc.walk(client, baseOID, func(dataUnit gosnmp.SnmpPDU) error {
+			typed := ParseValue(dataUnit)
+			results = append(results, WalkResult{
+				OID:   dataUnit.Name,
+				Value: typed.String(),
+				Typed: typed,
+			})
+			return nil
 		})
-		return nil
 	})
 
+	sc.observeWalk(baseOID, results, err)
 	if err != nil {
 		return nil, fmt.Errorf("error en SNMP WALK %s: %w", baseOID, err)
 	}
@@ -134,6 +323,47 @@ func (sc *SNMPClient) Walk(baseOID string, ctx *Context) ([]WalkResult, error) {
 	return results, nil
 }
 
+// WalkPDUs realiza SNMP WALK conservando los PDUs crudos (tipo + valor)
+// Usado para grabar fixtures del simulador sin perder el tipo SNMP
+func (sc *SNMPClient) WalkPDUs(baseOID string) ([]gosnmp.SnmpPDU, error) {
+	if err := sc.engine.checkOID(sc.host, "WALK", baseOID); err != nil {
+		sc.observeWalk(baseOID, nil, err)
+		return nil, err
+	}
+
+	var pdus []gosnmp.SnmpPDU
+
+	err := sc.do(func(client *gosnmp.GoSNMP) error {
+		pdus = nil
+		return sc.walk(client, baseOID, func(dataUnit gosnmp.SnmpPDU) error {
+			pdus = append(pdus, dataUnit)
+			return nil
+		})
+	})
+
+	if audit := sc.engine.audit(); audit != nil {
+		results := make([]WalkResult, 0, len(pdus))
+		for _, pdu := range pdus {
+			typed := ParseValue(pdu)
+			results = append(results, WalkResult{OID: pdu.Name, Value: typed.String(), Typed: typed})
+		}
+		audit.ObserveWalk(sc.host, baseOID, results, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error en SNMP WALK %s: %w", baseOID, err)
+	}
+
+	return pdus, nil
+}
+
+// walk recorre baseOID saltando los subárboles que la política deniega
+func (sc *SNMPClient) walk(client *gosnmp.GoSNMP, baseOID string, fn gosnmp.WalkFunc) error {
+	if skip := sc.engine.skipDenied(baseOID); len(skip) > 0 {
+		return walkSkipping(client, baseOID, skip, fn)
+	}
+	return client.Walk(baseOID, fn)
+}
+
 // connect establece conexión SNMP
 func (sc *SNMPClient) connect() (*gosnmp.GoSNMP, error) {
 	var version gosnmp.SnmpVersion
@@ -143,6 +373,11 @@ func (sc *SNMPClient) connect() (*gosnmp.GoSNMP, error) {
 		version = gosnmp.Version1
 	case "2c":
 		version = gosnmp.Version2c
+	case "3":
+		if sc.v3 == nil {
+			return nil, fmt.Errorf("%s: SNMPv3 sin credenciales (snmp.v3)", sc.host)
+		}
+		version = gosnmp.Version3
 	default:
 		version = gosnmp.Version2c
 	}
@@ -153,61 +388,41 @@ func (sc *SNMPClient) connect() (*gosnmp.GoSNMP, error) {
 		Community: sc.community,
 		Version:   version,
 		Timeout:   sc.timeout,
-		Retries:   sc.retries,
+		Retries:   0, // Los reintentos los maneja sc.policy
+		Context:   sc.context(),
+	}
+	if version == gosnmp.Version3 {
+		if err := sc.v3.apply(params); err != nil {
+			return nil, err
+		}
+	}
+
+	// Cada paquete (incluidos los de un WALK) respeta el límite global
+	// y el espaciado mínimo hacia este agente
+	// El RTT de cada respuesta alimenta la concurrencia adaptativa
+	if sc.engine != nil {
+		params.PreSend = func(*gosnmp.GoSNMP) {
+			sc.engine.waitTarget(sc.context(), sc.target(), sc.minDelay)
+			sc.engine.waitPacket(sc.context(), sc.host)
+		}
+		var sentAt time.Time
+		params.OnSent = func(*gosnmp.GoSNMP) { sentAt = time.Now() }
+		params.OnRecv = func(*gosnmp.GoSNMP) { sc.engine.observeRTT(time.Since(sentAt)) }
 	}
 
 	err := params.Connect()
 	if err != nil {
 		return nil, fmt.Errorf("error conectando a %s:%d: %w", sc.host, sc.port, err)
 	}
+	params.Conn = sc.engine.shapeConn(params.Conn, sc.host) // ancho de banda global y del site
 
 	return params, nil
 }
 
-// ParseValue convierte un PDU variable a string
-// Maneja diferentes tipos: strings, bytes (con decodificación UTF-8 y MAC), números
-func ParseValue(variable gosnmp.SnmpPDU) string {
-	if variable.Value == nil {
-		return ""
-	}
-
-	switch v := variable.Value.(type) {
-	case string:
-		// Limpiar null terminators
-		return strings.TrimRight(v, "\x00")
-	case []byte:
-		// Primero intentar interpretar como UTF-8 o ASCII válido
-		if isValidUTF8(v) && isLikelyText(v) {
-			str := string(v)
-			// Limpiar null terminators
-			return strings.TrimRight(str, "\x00")
-		}
-
-		// Si es exactamente 6 bytes y NO es texto válido, asumir que es MAC address binario
-		if len(v) == 6 && !isLikelyText(v) {
-			hexStr := hex.EncodeToString(v)
-			return fmt.Sprintf("%s:%s:%s:%s:%s:%s", hexStr[0:2], hexStr[2:4], hexStr[4:6], hexStr[6:8], hexStr[8:10], hexStr[10:12])
-		}
-
-		// Para otros bytes, retornar como string (puede ser ASCII o mostrar hex si es binario)
-		// Intentar limpiar si es ASCII imprimible
-		if isLikelyASCII(v) {
-			return strings.TrimRight(string(v), "\x00")
-		}
-
-		// Si no es texto válido, retornar vacío
-		return ""
-	case int:
-		return fmt.Sprintf("%d", v)
-	case uint:
-		return fmt.Sprintf("%d", v)
-	case uint32:
-		return fmt.Sprintf("%d", v)
-	case uint64:
-		return fmt.Sprintf("%d", v)
-	default:
-		return fmt.Sprintf("%v", v)
-	}
+// ParseValue convierte un PDU variable a un Value tipado
+// Usar .String() para la representación textual histórica
+func ParseValue(variable gosnmp.SnmpPDU) Value {
+	return NewValue(variable)
 }
 
 // isValidUTF8 valida si un slice de bytes es UTF-8 válido
@@ -0,0 +1,250 @@
+package snmp
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// AdaptiveConfig acota la concurrencia adaptativa del motor
+// El límite de operaciones simultáneas arranca en MaxWorkers del motor y se
+// ajusta cada adaptiveWindow según el RTT y los timeouts observados
+type AdaptiveConfig struct {
+	MinWorkers     int     // piso del límite (default: 2)
+	MaxWorkers     int     // techo del límite (default: 100)
+	MaxTimeoutRate float64 // tasa de timeouts desde la que se reduce (default: 0.05)
+	MaxRTTFactor   float64 // RTT medio / RTT base desde el que se reduce (default: 3)
+}
+
+const (
+	adaptiveWindow     = 2 * time.Second // cada cuánto se reevalúa el límite
+	adaptiveMinSamples = 10              // resultados mínimos para decidir en una ventana
+)
+
+// limiter es un semáforo cuyo tamaño puede cambiar con operaciones en curso
+// Reparte los slots por prioridad (ver PriorityShares): una clase entra si
+// hay slot libre, si ninguna clase de mayor prioridad está esperando y si
+// ella junto con las de menor prioridad no superan su porcentaje del límite
+type limiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+	peak   int // máximo de operaciones simultáneas desde el último resetPeak
+
+	shares  [priorityClasses]int // porcentaje del límite por clase
+	byClass [priorityClasses]int // operaciones en curso por clase
+	waiting [priorityClasses]int // operaciones esperando slot por clase
+}
+
+func newLimiter(limit int, shares PriorityShares) *limiter {
+	l := &limiter{limit: limit, shares: shares.percentages()}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire espera un slot libre para una operación de prioridad p
+// Retorna el error de ctx si se cancela antes de conseguirlo
+func (l *limiter) acquire(ctx context.Context, p Priority) error {
+	p = p.valid()
+
+	// La cancelación despierta a los que esperan para que vean ctx.Err()
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.mu.Unlock()
+		l.cond.Broadcast()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	l.waiting[p]++
+	for !l.admits(p) {
+		if err := ctx.Err(); err != nil {
+			l.waiting[p]--
+			l.mu.Unlock()
+			l.cond.Broadcast() // las clases de menor prioridad ya no esperan detrás de esta
+			return err
+		}
+		l.cond.Wait()
+	}
+	l.waiting[p]--
+	l.active++
+	l.byClass[p]++
+	if l.active > l.peak {
+		l.peak = l.active
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// admits indica si una operación de prioridad p puede tomar un slot (l.mu tomado)
+func (l *limiter) admits(p Priority) bool {
+	if l.active >= l.limit {
+		return false
+	}
+	for higher := PriorityStatus; higher < p; higher++ {
+		if l.waiting[higher] > 0 {
+			return false
+		}
+	}
+	used := 0
+	for class := p; class < priorityClasses; class++ {
+		used += l.byClass[class]
+	}
+	return used < max(1, l.limit*l.shares[p]/100)
+}
+
+// release libera el slot de una operación de prioridad p
+// Despierta a todas las que esperan: cada clase tiene su propia condición
+func (l *limiter) release(p Priority) {
+	p = p.valid()
+	l.mu.Lock()
+	l.active--
+	l.byClass[p]--
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+// setLimit cambia el tamaño; las operaciones en curso siguen hasta terminar
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	l.limit = limit
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+// current retorna el límite y las operaciones en curso
+func (l *limiter) current() (limit, active int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.active
+}
+
+// resetPeak retorna el límite y el pico de la ventana, y reinicia el pico
+func (l *limiter) resetPeak() (limit, peak int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, peak = l.limit, l.peak
+	l.peak = l.active
+	return limit, peak
+}
+
+// concurrencyController ajusta el límite del motor (AIMD): si el RTT se
+// dispara respecto del base o suben los timeouts, la red está saturada y el
+// límite baja un 30%; si está sano y se usa entero, sube un 25%
+// Solo cuentan los timeouts de hosts que ya respondieron: en un discovery la
+// mayoría de las IPs no existe y eso no dice nada de la red
+type concurrencyController struct {
+	config AdaptiveConfig
+	slots  *limiter
+
+	mu          sync.Mutex
+	windowStart time.Time
+	rttSum      time.Duration
+	rttCount    int
+	answered    int
+	timeouts    int
+	baseline    time.Duration   // RTT medio de una red sin carga (ventana más rápida, con deriva lenta)
+	responsive  map[string]bool // hosts que respondieron alguna vez
+}
+
+func newConcurrencyController(config AdaptiveConfig, slots *limiter) *concurrencyController {
+	if config.MinWorkers <= 0 {
+		config.MinWorkers = 2
+	}
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = 100
+	}
+	if config.MaxTimeoutRate <= 0 {
+		config.MaxTimeoutRate = 0.05
+	}
+	if config.MaxRTTFactor <= 1 {
+		config.MaxRTTFactor = 3
+	}
+	return &concurrencyController{
+		config:      config,
+		slots:       slots,
+		windowStart: time.Now(),
+		responsive:  make(map[string]bool),
+	}
+}
+
+// clamp acota n a [MinWorkers, MaxWorkers]
+func (c *concurrencyController) clamp(n int) int {
+	if n < c.config.MinWorkers {
+		return c.config.MinWorkers
+	}
+	if n > c.config.MaxWorkers {
+		return c.config.MaxWorkers
+	}
+	return n
+}
+
+// observeRTT registra el tiempo de ida y vuelta de un paquete
+func (c *concurrencyController) observeRTT(rtt time.Duration) {
+	c.mu.Lock()
+	c.rttSum += rtt
+	c.rttCount++
+	c.mu.Unlock()
+}
+
+// observe registra el resultado de una operación contra host y reevalúa el
+// límite al cerrar la ventana
+func (c *concurrencyController) observe(host string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case err == nil:
+		c.responsive[host] = true
+		c.answered++
+	case c.responsive[host]:
+		c.timeouts++
+	}
+
+	if time.Since(c.windowStart) < adaptiveWindow || c.answered+c.timeouts < adaptiveMinSamples {
+		return
+	}
+	c.evaluate()
+}
+
+// evaluate decide el nuevo límite con los datos de la ventana (c.mu tomado)
+func (c *concurrencyController) evaluate() {
+	var avg time.Duration
+	if c.rttCount > 0 {
+		avg = c.rttSum / time.Duration(c.rttCount)
+		switch {
+		case c.baseline == 0 || avg < c.baseline:
+			c.baseline = avg
+		default:
+			c.baseline += (avg - c.baseline) / 20 // una ruta más lenta termina siendo la nueva base
+		}
+	}
+	timeoutRate := float64(c.timeouts) / float64(c.answered+c.timeouts)
+
+	limit, peak := c.slots.resetPeak()
+	next := limit
+	switch {
+	case timeoutRate > c.config.MaxTimeoutRate,
+		c.baseline > 0 && float64(avg) > float64(c.baseline)*c.config.MaxRTTFactor:
+		next = c.clamp(limit * 7 / 10)
+		if next == limit && limit > c.config.MinWorkers {
+			next--
+		}
+	case peak >= limit:
+		next = c.clamp(limit + max(1, limit/4))
+	}
+
+	if next != limit {
+		c.slots.setLimit(next)
+		if next < limit {
+			log.Print(i18n.T("log.concurrency_reduced", limit, next, avg.Round(time.Millisecond), c.baseline.Round(time.Millisecond), timeoutRate*100))
+		}
+	}
+
+	c.windowStart = time.Now()
+	c.rttSum, c.rttCount, c.answered, c.timeouts = 0, 0, 0, 0
+}
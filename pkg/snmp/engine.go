@@ -0,0 +1,298 @@
+package snmp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EngineConfig configura el motor SNMP compartido
+type EngineConfig struct {
+	MaxWorkers       int             // Operaciones SNMP simultáneas en TODO el agente (default: 10)
+	PacketsPerSecond int             // Límite global de paquetes por segundo (0 = sin límite)
+	BytesPerSecond   int             // Límite global de ancho de banda, envío + respuesta (0 = sin límite)
+	Shapes           []TrafficShape  // Límites por site (subredes detrás de enlaces WAN)
+	BackoffBase      time.Duration   // Espera inicial tras un fallo de un target (default: 500ms)
+	BackoffMax       time.Duration   // Espera máxima por target (default: 30s)
+	AllowOIDs        []string        // Prefijos que se pueden consultar (vacío = todos)
+	DenyOIDs         []string        // Prefijos que nunca se consultan (ganan sobre AllowOIDs)
+	Audit            Observer        // Recibe cada OID consultado por cualquier cliente (nil = sin auditoría)
+	Adaptive         *AdaptiveConfig // Concurrencia según RTT y timeouts, desde MaxWorkers (nil = fija)
+	Shares           PriorityShares  // Slots que pueden ocupar los polls y los discovery (ver Priority)
+}
+
+// Engine centraliza el tráfico SNMP del agente
+// Scanner, collector y profiler comparten la misma instancia para que:
+// - el número de operaciones simultáneas esté acotado globalmente
+// - los paquetes y bytes por segundo no saturen routers pequeños ni enlaces WAN
+// - un target que falla espere (backoff) antes de recibir más consultas
+// - ningún OID fuera de la política allow/deny llegue a un equipo
+// - con Adaptive, la concurrencia siga a la red (data center o VPN lenta)
+type Engine struct {
+	config   EngineConfig
+	slots    *limiter
+	adaptive *concurrencyController // nil = concurrencia fija
+	policy   oidPolicy
+
+	packets *pacer  // límite global de paquetes (nil = sin límite)
+	bytes   *pacer  // límite global de bytes (nil = sin límite)
+	shapes  []shape // límites por site
+
+	pacingMu  sync.Mutex
+	nextQuery map[string]time.Time // por target (host:puerto): próximo instante permitido (MinDelay del cliente)
+
+	mu         sync.Mutex
+	targets    map[string]*targetState // backoff por target (host:puerto)
+	violations map[string]int          // consultas bloqueadas por OID
+}
+
+// targetState lleva el backoff de un agente
+type targetState struct {
+	failures int
+	retryAt  time.Time
+}
+
+// NewEngine crea un nuevo motor SNMP
+func NewEngine(config EngineConfig) *Engine {
+	if config.MaxWorkers <= 0 {
+		config.MaxWorkers = 10
+	}
+	if config.BackoffBase == 0 {
+		config.BackoffBase = 500 * time.Millisecond
+	}
+	if config.BackoffMax == 0 {
+		config.BackoffMax = 30 * time.Second
+	}
+
+	e := &Engine{
+		config:     config,
+		slots:      newLimiter(config.MaxWorkers, config.Shares),
+		packets:    newPacer(config.PacketsPerSecond),
+		bytes:      newPacer(config.BytesPerSecond),
+		shapes:     newShapes(config.Shapes),
+		policy:     newOIDPolicy(config.AllowOIDs, config.DenyOIDs),
+		targets:    make(map[string]*targetState),
+		violations: make(map[string]int),
+		nextQuery:  make(map[string]time.Time),
+	}
+	if config.Adaptive != nil {
+		e.adaptive = newConcurrencyController(*config.Adaptive, e.slots)
+		e.slots.setLimit(e.adaptive.clamp(config.MaxWorkers))
+	}
+	return e
+}
+
+// NewClient crea un cliente SNMP cuyas operaciones pasan por el motor, con
+// prioridad de poll (PriorityCounters; ver SNMPClient.WithPriority)
+func (e *Engine) NewClient(host string, port uint16, community, version string, timeout time.Duration, retries int) *SNMPClient {
+	client := NewSNMPClient(host, port, community, version, timeout, retries)
+	client.engine = e
+	client.priority = PriorityCounters
+	return client
+}
+
+// Workers retorna el tamaño del pool de workers
+// Con concurrencia adaptativa es el techo: el límite real lo aplica acquire
+func (e *Engine) Workers() int {
+	if e == nil {
+		return 1
+	}
+	if e.adaptive != nil {
+		return e.adaptive.config.MaxWorkers
+	}
+	return e.config.MaxWorkers
+}
+
+// Concurrency retorna el límite actual de operaciones simultáneas
+func (e *Engine) Concurrency() int {
+	if e == nil {
+		return 1
+	}
+	limit, _ := e.slots.current()
+	return limit
+}
+
+// audit retorna el observer de auditoría del motor (nil = sin auditoría)
+func (e *Engine) audit() Observer {
+	if e == nil {
+		return nil
+	}
+	return e.config.Audit
+}
+
+// ForEach ejecuta fn(i) para i en [0, n) usando un pool acotado de workers
+// En lugar de una goroutine por dispositivo, MaxWorkers goroutines consumen
+// los índices de una cola. Retorna cuando todos terminaron o ctx se canceló.
+func (e *Engine) ForEach(ctx context.Context, n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	workers := e.Workers()
+	if workers > n {
+		workers = n
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break // con workers libres select elegiría al azar entre jobs y Done
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+}
+
+// acquire espera el backoff del target y un slot de operación de prioridad p
+// Retorna la función que libera el slot, o el error de ctx si se cancela
+// durante el backoff (la espera no ocupa slot) o esperando el slot
+func (e *Engine) acquire(ctx context.Context, target string, p Priority) (func(), error) {
+	if e == nil {
+		return func() {}, nil
+	}
+
+	if err := sleep(ctx, e.BackoffRemaining(target)); err != nil {
+		return nil, err
+	}
+
+	if err := e.slots.acquire(ctx, p); err != nil {
+		return nil, err
+	}
+	return func() { e.slots.release(p) }, nil
+}
+
+// sleep espera d o hasta que se cancele ctx (retorna su error)
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitPacket bloquea hasta que el límite de paquetes (global y del site de
+// target) permita enviar
+// Se engancha en gosnmp.PreSend, por lo que cuenta cada paquete de un WALK
+// Cancelar ctx corta la espera (gosnmp descarta el envío con el mismo ctx)
+func (e *Engine) waitPacket(ctx context.Context, target string) {
+	if e == nil {
+		return
+	}
+	wait := e.packets.reserve(1)
+	if s := e.shapeFor(target); s != nil {
+		wait = max(wait, s.packets.reserve(1))
+	}
+	sleep(ctx, wait)
+}
+
+// waitTarget bloquea hasta que hayan pasado minDelay desde el último paquete a target
+// Es independiente del límite global: un equipo viejo recibe como mucho un paquete
+// cada minDelay aunque varias secciones o clientes lo consulten a la vez
+func (e *Engine) waitTarget(ctx context.Context, target string, minDelay time.Duration) {
+	if e == nil || minDelay <= 0 {
+		return
+	}
+
+	e.pacingMu.Lock()
+	now := time.Now()
+	next := e.nextQuery[target]
+	if next.Before(now) {
+		next = now
+	}
+	wait := next.Sub(now)
+	e.nextQuery[target] = next.Add(minDelay)
+
+	// Limpieza: targets sin tráfico reciente no necesitan entrada
+	if len(e.nextQuery) > 1024 {
+		for key, at := range e.nextQuery {
+			if at.Before(now) {
+				delete(e.nextQuery, key)
+			}
+		}
+	}
+	e.pacingMu.Unlock()
+
+	sleep(ctx, wait)
+}
+
+// report registra el resultado de una operación para el backoff del target
+// Solo errores de red/timeout (err != nil) cuentan como fallo
+func (e *Engine) report(target string, err error) {
+	if e == nil {
+		return
+	}
+	if e.adaptive != nil {
+		e.adaptive.observe(target, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		delete(e.targets, target)
+		return
+	}
+
+	state, ok := e.targets[target]
+	if !ok {
+		state = &targetState{}
+		e.targets[target] = state
+	}
+	state.failures++
+
+	// Backoff exponencial: base * 2^(fallos-1), con tope
+	wait := e.config.BackoffBase
+	for i := 1; i < state.failures && wait < e.config.BackoffMax; i++ {
+		wait *= 2
+	}
+	if wait > e.config.BackoffMax {
+		wait = e.config.BackoffMax
+	}
+	state.retryAt = time.Now().Add(wait)
+}
+
+// observeRTT registra el RTT de un paquete para la concurrencia adaptativa
+func (e *Engine) observeRTT(rtt time.Duration) {
+	if e == nil || e.adaptive == nil {
+		return
+	}
+	e.adaptive.observeRTT(rtt)
+}
+
+// BackoffRemaining retorna cuánto falta para que un target pueda ser consultado
+func (e *Engine) BackoffRemaining(target string) time.Duration {
+	if e == nil {
+		return 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, ok := e.targets[target]
+	if !ok {
+		return 0
+	}
+
+	return time.Until(state.retryAt)
+}
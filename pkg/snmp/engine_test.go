@@ -0,0 +1,150 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// Dos agentes en el mismo host (simuladores, NAT con un puerto por equipo)
+// no comparten el espaciado mínimo ni el backoff
+func TestPacingPerAgent(t *testing.T) {
+	e := NewEngine(EngineConfig{MaxWorkers: 4})
+	a := e.NewClient("127.0.0.1", 16100, "public", "2c", time.Second, 0)
+	b := e.NewClient("127.0.0.1", 16101, "public", "2c", time.Second, 0)
+	const minDelay = 200 * time.Millisecond
+
+	e.waitTarget(context.Background(), a.target(), minDelay)
+	start := time.Now()
+	e.waitTarget(context.Background(), b.target(), minDelay)
+	if elapsed := time.Since(start); elapsed > minDelay/2 {
+		t.Errorf("el primer paquete a otro puerto esperó %v", elapsed)
+	}
+
+	start = time.Now()
+	e.waitTarget(context.Background(), a.target(), minDelay)
+	if elapsed := time.Since(start); elapsed < minDelay/2 {
+		t.Errorf("el segundo paquete al mismo agente esperó solo %v", elapsed)
+	}
+}
+
+func TestBackoffPerAgent(t *testing.T) {
+	e := NewEngine(EngineConfig{MaxWorkers: 4})
+	a := e.NewClient("127.0.0.1", 16100, "public", "2c", time.Second, 0)
+	b := e.NewClient("127.0.0.1", 16101, "public", "2c", time.Second, 0)
+
+	e.report(a.target(), errors.New("timeout"))
+	if e.BackoffRemaining(a.target()) <= 0 {
+		t.Error("el agente que falló no quedó en backoff")
+	}
+	if wait := e.BackoffRemaining(b.target()); wait > 0 {
+		t.Errorf("otro puerto del mismo host quedó en backoff %v", wait)
+	}
+
+	e.report(a.target(), nil)
+	if wait := e.BackoffRemaining(a.target()); wait > 0 {
+		t.Errorf("una respuesta no limpió el backoff: %v", wait)
+	}
+}
+
+// El backoff de un target caído no bloquea más allá de la cancelación
+func TestAcquireBackoffHonorsContext(t *testing.T) {
+	e := NewEngine(EngineConfig{MaxWorkers: 1, BackoffBase: 10 * time.Second})
+	e.report("10.0.0.1:161", errors.New("timeout"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := e.acquire(ctx, "10.0.0.1:161", PriorityCounters); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire = %v, se esperaba DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("acquire esperó %v con el contexto vencido", elapsed)
+	}
+}
+
+// Esperando un slot ocupado, la cancelación corta la espera y no deja la
+// clase marcada como esperando (no frena a las de menor prioridad)
+func TestAcquireSlotHonorsContext(t *testing.T) {
+	e := NewEngine(EngineConfig{MaxWorkers: 1})
+	release, err := e.acquire(context.Background(), "10.0.0.1:161", PriorityDiscovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := e.acquire(ctx, "10.0.0.2:161", PriorityStatus); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire = %v, se esperaba DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("acquire esperó %v con el contexto vencido", elapsed)
+	}
+
+	release()
+	next, err := e.acquire(context.Background(), "10.0.0.3:161", PriorityDiscovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next()
+}
+
+// El espaciado mínimo no demora más allá de la cancelación
+func TestPacingHonorsContext(t *testing.T) {
+	e := NewEngine(EngineConfig{MaxWorkers: 1})
+	e.waitTarget(context.Background(), "10.0.0.1:161", 10*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	e.waitTarget(ctx, "10.0.0.1:161", 10*time.Second)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitTarget esperó %v con el contexto vencido", elapsed)
+	}
+}
+
+// Entre reintentos el cliente no retiene el slot: con un solo worker, otra
+// operación entra mientras un equipo mudo espera su próximo intento
+func TestRetriesReleaseSlot(t *testing.T) {
+	silent, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			if _, _, err := silent.ReadFromUDP(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	e := NewEngine(EngineConfig{MaxWorkers: 1})
+	port := uint16(silent.LocalAddr().(*net.UDPAddr).Port)
+	client := e.NewClient("127.0.0.1", port, "public", "2c", 100*time.Millisecond, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.Get("1.3.6.1.2.1.1.1.0", NewContext())
+	}()
+
+	// Primer intento vencido (100ms); el siguiente espera el backoff
+	time.Sleep(250 * time.Millisecond)
+	acquired := make(chan func())
+	go func() {
+		release, _ := e.acquire(context.Background(), "127.0.0.1:16100", PriorityCounters)
+		acquired <- release
+	}()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(200 * time.Millisecond):
+		t.Error("el slot quedó tomado durante la espera entre reintentos")
+		(<-acquired)()
+	}
+	<-done
+}
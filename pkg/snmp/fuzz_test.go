@@ -0,0 +1,145 @@
+package snmp_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+	"github.com/asaavedra/agent-snmp/pkg/snmp"
+	"github.com/gosnmp/gosnmp"
+)
+
+// fixtureTypes mapea los tipos de los fixtures a ASN.1
+var fixtureTypes = map[string]gosnmp.Asn1BER{
+	"integer":      gosnmp.Integer,
+	"octet_string": gosnmp.OctetString,
+	"oid":          gosnmp.ObjectIdentifier,
+	"ip":           gosnmp.IPAddress,
+	"counter32":    gosnmp.Counter32,
+	"gauge32":      gosnmp.Gauge32,
+	"timeticks":    gosnmp.TimeTicks,
+	"counter64":    gosnmp.Counter64,
+}
+
+// fixtureVariables retorna las variables de todos los fixtures incluidos
+func fixtureVariables(f *testing.F) []simulator.Variable {
+	var vars []simulator.Variable
+	for _, name := range simulator.BuiltinNames() {
+		fixture, err := simulator.Builtin(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		vars = append(vars, fixture.Variables...)
+	}
+	return vars
+}
+
+// rawValue son los bytes de una variable: el octet string decodificado o el
+// número en big endian
+func rawValue(v simulator.Variable) []byte {
+	switch v.Type {
+	case "octet_string":
+		if v.Hex {
+			b, _ := hex.DecodeString(v.Value)
+			return b
+		}
+		return []byte(v.Value)
+	case "oid", "ip":
+		return []byte(v.Value)
+	}
+	n, _ := strconv.ParseInt(v.Value, 10, 64)
+	return binary.BigEndian.AppendUint64(nil, uint64(n))
+}
+
+// pduFor arma el PDU que entregaría gosnmp para el tipo y los bytes dados
+func pduFor(typ gosnmp.Asn1BER, data []byte) gosnmp.SnmpPDU {
+	var buf [8]byte
+	copy(buf[8-min(len(data), 8):], data)
+	n := binary.BigEndian.Uint64(buf[:])
+
+	pdu := gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.1.0", Type: typ}
+	switch typ {
+	case gosnmp.Integer:
+		pdu.Value = int(int64(n))
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		pdu.Value = uint(uint32(n))
+	case gosnmp.Counter64:
+		pdu.Value = n
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		pdu.Value = data
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+	default:
+		pdu.Value = string(data)
+	}
+	return pdu
+}
+
+func FuzzParseValue(f *testing.F) {
+	for _, v := range fixtureVariables(f) {
+		f.Add(byte(fixtureTypes[v.Type]), rawValue(v))
+	}
+	f.Add(byte(gosnmp.OctetString), []byte{0xff, 0xfe, 0x00, 0x41})
+	f.Add(byte(gosnmp.OctetString), []byte{0xc3})
+	f.Add(byte(gosnmp.NoSuchObject), []byte(nil))
+
+	f.Fuzz(func(t *testing.T, typ byte, data []byte) {
+		pdu := pduFor(gosnmp.Asn1BER(typ), data)
+		v := snmp.ParseValue(pdu)
+
+		s := v.String()
+		v.Bytes()
+		v.Uint64()
+		if strings.HasSuffix(s, "\x00") {
+			t.Errorf("String() conserva el terminador nulo: %q", s)
+		}
+
+		n, ok := v.Int64()
+		switch pdu.Type {
+		case gosnmp.Integer:
+			if want := int64(pdu.Value.(int)); !ok || n != want {
+				t.Errorf("Int64() = %d, %v; se esperaba %d", n, ok, want)
+			}
+		case gosnmp.Counter64:
+			if want := pdu.Value.(uint64); ok != (want <= 1<<63-1) || (ok && uint64(n) != want) {
+				t.Errorf("Int64() = %d, %v para Counter64 %d", n, ok, want)
+			}
+		}
+	})
+}
+
+func FuzzHexDecoder(f *testing.F) {
+	for _, v := range fixtureVariables(f) {
+		if v.Type != "octet_string" {
+			continue
+		}
+		raw := rawValue(v)
+		f.Add(raw)
+		f.Add([]byte(hex.EncodeToString(raw)))
+	}
+	f.Add([]byte("4150535643"))
+	f.Add([]byte("41505356434"))
+	f.Add([]byte("00ff"))
+
+	hd := &snmp.HexDecoder{}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, ok := hd.DecodeHexASCII(data)
+		if ok {
+			if !hd.IsHexASCII(data) {
+				t.Errorf("DecodeHexASCII(%q) decodificó algo que IsHexASCII rechaza", data)
+			}
+			if !strings.EqualFold(hex.EncodeToString([]byte(decoded)), string(data)) {
+				t.Errorf("DecodeHexASCII(%q) = %q no vuelve al hex original", data, decoded)
+			}
+		}
+
+		hd.DecodeValue(data)
+		hd.DecodeValue(string(data))
+		original, _ := hd.GetFriendlyHexValue(string(data))
+		if original != string(data) {
+			t.Errorf("GetFriendlyHexValue(%q) cambió el original a %q", data, original)
+		}
+	})
+}
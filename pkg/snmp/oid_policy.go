@@ -0,0 +1,212 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/gosnmp/gosnmp"
+)
+
+// ErrOIDDenied indica que la política de OIDs del motor bloqueó la consulta
+var ErrOIDDenied = errors.New("OID bloqueado por la política de OIDs")
+
+// oidPolicy son los prefijos que el agente puede (allow) y nunca debe (deny)
+// consultar. deny gana sobre allow; allow vacío permite todo lo no denegado
+type oidPolicy struct {
+	allow []string
+	deny  []string
+}
+
+// ValidOIDPrefix indica si prefix es un OID numérico ("1.3.6.1.2.1.25.6")
+func ValidOIDPrefix(prefix string) bool {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), ".")
+	if prefix == "" {
+		return false
+	}
+	for _, arc := range strings.Split(prefix, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// newOIDPolicy normaliza los prefijos (sin punto inicial); los inválidos se
+// ignoran (config.yaml los rechaza antes)
+func newOIDPolicy(allow, deny []string) oidPolicy {
+	return oidPolicy{allow: normalizePrefixes(allow), deny: normalizePrefixes(deny)}
+}
+
+func normalizePrefixes(prefixes []string) []string {
+	var out []string
+	for _, prefix := range prefixes {
+		if ValidOIDPrefix(prefix) {
+			out = append(out, strings.TrimPrefix(strings.TrimSpace(prefix), "."))
+		}
+	}
+	return out
+}
+
+// inSubtree indica si oid es prefix o está debajo de él
+func inSubtree(oid, prefix string) bool {
+	oid = strings.TrimPrefix(oid, ".")
+	return oid == prefix || strings.HasPrefix(oid, prefix+".")
+}
+
+// deniedBy retorna el prefijo que bloquea oid ("" si está permitido)
+func (p oidPolicy) deniedBy(oid string) string {
+	for _, prefix := range p.deny {
+		if inSubtree(oid, prefix) {
+			return prefix
+		}
+	}
+	if len(p.allow) == 0 {
+		return ""
+	}
+	for _, prefix := range p.allow {
+		if inSubtree(oid, prefix) {
+			return ""
+		}
+	}
+	return "allow"
+}
+
+// deniedWithin retorna los prefijos denegados que cuelgan de root: un WALK
+// de root tiene que saltarlos
+func (p oidPolicy) deniedWithin(root string) []string {
+	root = strings.TrimPrefix(root, ".")
+	var within []string
+	for _, prefix := range p.deny {
+		if inSubtree(prefix, root) {
+			within = append(within, prefix)
+		}
+	}
+	return within
+}
+
+// checkOID retorna ErrOIDDenied si la política bloquea oid
+// Cada OID bloqueado se loguea una vez por proceso (y se cuenta siempre)
+func (e *Engine) checkOID(host, op, oid string) error {
+	if e == nil {
+		return nil
+	}
+	prefix := e.policy.deniedBy(oid)
+	if prefix == "" {
+		return nil
+	}
+
+	e.mu.Lock()
+	e.violations[oid]++
+	first := e.violations[oid] == 1
+	e.mu.Unlock()
+
+	if first {
+		log.Print(i18n.T("log.oid_denied", op, oid, host, prefix))
+	}
+	return fmt.Errorf("%w: %s", ErrOIDDenied, oid)
+}
+
+// skipDenied retorna los subárboles denegados dentro de un WALK de root
+func (e *Engine) skipDenied(root string) []string {
+	if e == nil {
+		return nil
+	}
+	return e.policy.deniedWithin(root)
+}
+
+// Violations retorna cuántas consultas bloqueó la política de OIDs
+func (e *Engine) Violations() int {
+	if e == nil {
+		return 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	total := 0
+	for _, n := range e.violations {
+		total += n
+	}
+	return total
+}
+
+// walkSkipping recorre root con GETNEXT como gosnmp.Walk, pero al llegar a un
+// subárbol de skip descarta la respuesta y continúa desde el final del
+// subárbol: sus filas nunca llegan a fn ni se vuelven a pedir
+func walkSkipping(client *gosnmp.GoSNMP, root string, skip []string, fn gosnmp.WalkFunc) error {
+	root = "." + strings.TrimPrefix(root, ".")
+	oid := root
+
+	for {
+		response, err := client.GetNext([]string{oid})
+		if err != nil {
+			return err
+		}
+		if len(response.Variables) == 0 || response.Error != gosnmp.NoError {
+			return nil
+		}
+
+		pdu := response.Variables[0]
+		if pdu.Type == gosnmp.EndOfMibView || pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+			return nil
+		}
+		if !strings.HasPrefix(pdu.Name, root+".") {
+			return nil
+		}
+
+		next := pdu.Name
+		skipped := false
+		for _, prefix := range skip {
+			if inSubtree(pdu.Name, prefix) {
+				next = afterSubtree(prefix)
+				skipped = true
+				break
+			}
+		}
+		if !skipped {
+			if err := fn(pdu); err != nil {
+				return err
+			}
+		}
+
+		if compareOIDs(next, oid) <= 0 {
+			return fmt.Errorf("OID not increasing: %s", pdu.Name)
+		}
+		oid = next
+	}
+}
+
+// afterSubtree retorna el primer OID posible fuera del subárbol prefix:
+// el mismo prefijo con el último arco incrementado (GETNEXT sigue desde ahí)
+func afterSubtree(prefix string) string {
+	arcs := strings.Split(strings.TrimPrefix(prefix, "."), ".")
+	last, _ := strconv.ParseUint(arcs[len(arcs)-1], 10, 32)
+	arcs[len(arcs)-1] = strconv.FormatUint(last+1, 10)
+	return "." + strings.Join(arcs, ".")
+}
+
+// compareOIDs compara dos OIDs arco por arco (-1, 0, 1)
+func compareOIDs(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "."), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.ParseUint(as[i], 10, 64)
+		y, _ := strconv.ParseUint(bs[i], 10, 64)
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
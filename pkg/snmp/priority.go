@@ -0,0 +1,69 @@
+package snmp
+
+import (
+	"fmt"
+)
+
+// Priority es la clase de una operación SNMP frente a los slots del motor
+// Un refresco de estado (una consulta de la API que alguien está esperando)
+// no debe quedar detrás de los WALK de un discovery masivo
+type Priority int
+
+// Clases de prioridad, de mayor a menor
+const (
+	PriorityStatus    Priority = iota // refresco de estado: consultas interactivas y sondeos de vida
+	PriorityCounters                  // poll de contadores y consumibles del ciclo (default de Engine.NewClient)
+	PriorityDiscovery                 // walks masivos: discovery de red y de perfiles
+	priorityClasses
+)
+
+// String retorna el nombre de la clase (status, counters, discovery)
+func (p Priority) String() string {
+	switch p {
+	case PriorityStatus:
+		return "status"
+	case PriorityCounters:
+		return "counters"
+	case PriorityDiscovery:
+		return "discovery"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// PriorityShares es el porcentaje de los slots del motor que pueden ocupar
+// las operaciones de cada clase junto con las de menor prioridad
+// Con 80 y 50, los discovery usan a lo sumo la mitad de los slots y el 20%
+// queda siempre libre para los refrescos de estado; el estado puede usarlos todos
+type PriorityShares struct {
+	Counters  int // 1-100 (0 = 80)
+	Discovery int // 1-100 (0 = 50); se acota a Counters
+}
+
+// Porcentajes por defecto de PriorityShares
+const (
+	defaultCountersShare  = 80
+	defaultDiscoveryShare = 50
+)
+
+// percentages retorna el porcentaje por clase con los valores por defecto aplicados
+func (s PriorityShares) percentages() [priorityClasses]int {
+	counters, discovery := s.Counters, s.Discovery
+	if counters <= 0 || counters > 100 {
+		counters = defaultCountersShare
+	}
+	if discovery <= 0 || discovery > 100 {
+		discovery = defaultDiscoveryShare
+	}
+	if discovery > counters {
+		discovery = counters
+	}
+	return [priorityClasses]int{PriorityStatus: 100, PriorityCounters: counters, PriorityDiscovery: discovery}
+}
+
+// valid acota p a una clase conocida (las desconocidas cuentan como discovery)
+func (p Priority) valid() Priority {
+	if p < PriorityStatus || p >= priorityClasses {
+		return PriorityDiscovery
+	}
+	return p
+}
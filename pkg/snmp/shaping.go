@@ -0,0 +1,147 @@
+package snmp
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// TrafficShape limita el tráfico SNMP hacia una subred (un site detrás de un
+// enlace WAN). Varias subredes con el mismo Name comparten el límite
+type TrafficShape struct {
+	Name             string // site ("Sucursal Norte")
+	Subnet           string // CIDR
+	PacketsPerSecond int    // 0 = sin límite propio
+	BytesPerSecond   int    // envío + respuesta; 0 = sin límite propio
+}
+
+// pacer reparte unidades (paquetes o bytes) a un ritmo fijo: cada reserva
+// corre el próximo instante libre y retorna cuánto esperar
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration // por unidad
+	next     time.Time
+}
+
+// newPacer crea un pacer de rate unidades por segundo (nil = sin límite)
+func newPacer(rate int) *pacer {
+	if rate <= 0 {
+		return nil
+	}
+	return &pacer{interval: time.Second / time.Duration(rate)}
+}
+
+// reserve reserva n unidades y retorna la espera hasta poder usarlas
+func (p *pacer) reserve(n int) time.Duration {
+	if p == nil || n <= 0 {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	wait := p.next.Sub(now)
+	p.next = p.next.Add(p.interval * time.Duration(n))
+	return wait
+}
+
+// shape es un TrafficShape compilado; las subredes de un mismo site
+// apuntan a los mismos pacers
+type shape struct {
+	subnet  *net.IPNet
+	packets *pacer
+	bytes   *pacer
+}
+
+// newShapes compila los límites por site; las subredes inválidas se ignoran
+// (config.yaml las rechaza antes)
+func newShapes(configs []TrafficShape) []shape {
+	bySite := make(map[string]shape)
+	var shapes []shape
+	for _, c := range configs {
+		_, subnet, err := net.ParseCIDR(c.Subnet)
+		if err != nil || (c.PacketsPerSecond <= 0 && c.BytesPerSecond <= 0) {
+			continue
+		}
+		s, ok := bySite[c.Name]
+		if !ok || c.Name == "" {
+			s = shape{packets: newPacer(c.PacketsPerSecond), bytes: newPacer(c.BytesPerSecond)}
+			bySite[c.Name] = s
+		}
+		s.subnet = subnet
+		shapes = append(shapes, s)
+	}
+	return shapes
+}
+
+// shapeFor retorna el límite del site de host (nil = solo el global)
+func (e *Engine) shapeFor(host string) *shape {
+	if len(e.shapes) == 0 {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	for i := range e.shapes {
+		if e.shapes[i].subnet.Contains(ip) {
+			return &e.shapes[i]
+		}
+	}
+	return nil
+}
+
+// waitBytes espera el ancho de banda para enviar n bytes a host
+func (e *Engine) waitBytes(host string, n int) {
+	if e == nil {
+		return
+	}
+	wait := e.bytes.reserve(n)
+	if s := e.shapeFor(host); s != nil {
+		wait = max(wait, s.bytes.reserve(n))
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// chargeBytes descuenta n bytes recibidos de host: la respuesta ya llegó, así
+// que no se espera; la deuda la pagan los próximos envíos
+func (e *Engine) chargeBytes(host string, n int) {
+	if e == nil {
+		return
+	}
+	e.bytes.reserve(n)
+	if s := e.shapeFor(host); s != nil {
+		s.bytes.reserve(n)
+	}
+}
+
+// shapedConn cuenta los bytes de cada paquete contra los límites del motor
+type shapedConn struct {
+	net.Conn
+	engine *Engine
+	host   string
+}
+
+// shapeConn envuelve conn si el motor limita bytes (global o de algún site)
+func (e *Engine) shapeConn(conn net.Conn, host string) net.Conn {
+	if e == nil || conn == nil || (e.bytes == nil && len(e.shapes) == 0) {
+		return conn
+	}
+	return &shapedConn{Conn: conn, engine: e, host: host}
+}
+
+func (c *shapedConn) Write(p []byte) (int, error) {
+	c.engine.waitBytes(c.host, len(p))
+	return c.Conn.Write(p)
+}
+
+func (c *shapedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.engine.chargeBytes(c.host, n)
+	return n, err
+}
@@ -0,0 +1,96 @@
+package snmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// V3Credentials son las credenciales USM de un equipo SNMPv3
+// El nivel de seguridad sale de qué protocolos están configurados:
+// sin auth es noAuthNoPriv, con auth y sin priv authNoPriv, con ambos authPriv
+type V3Credentials struct {
+	Username     string
+	AuthProtocol string // MD5, SHA, SHA224, SHA256, SHA384, SHA512 ("" = sin autenticación)
+	AuthPassword string
+	PrivProtocol string // DES, AES, AES192, AES256, AES192C, AES256C ("" = sin cifrado)
+	PrivPassword string
+	ContextName  string
+}
+
+var v3AuthProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"":       gosnmp.NoAuth,
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var v3PrivProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"":        gosnmp.NoPriv,
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES256":  gosnmp.AES256,
+	"AES192C": gosnmp.AES192C,
+	"AES256C": gosnmp.AES256C,
+}
+
+// Validate revisa que las credenciales sean utilizables
+func (c V3Credentials) Validate() error {
+	if c.Username == "" {
+		return fmt.Errorf("username requerido")
+	}
+	auth, ok := v3AuthProtocols[strings.ToUpper(c.AuthProtocol)]
+	if !ok {
+		return fmt.Errorf("auth_protocol: %q no soportado (MD5, SHA, SHA224, SHA256, SHA384, SHA512)", c.AuthProtocol)
+	}
+	priv, ok := v3PrivProtocols[strings.ToUpper(c.PrivProtocol)]
+	if !ok {
+		return fmt.Errorf("priv_protocol: %q no soportado (DES, AES, AES192, AES256, AES192C, AES256C)", c.PrivProtocol)
+	}
+	if auth != gosnmp.NoAuth && c.AuthPassword == "" {
+		return fmt.Errorf("auth_password requerido con auth_protocol %s", c.AuthProtocol)
+	}
+	if priv != gosnmp.NoPriv {
+		if auth == gosnmp.NoAuth {
+			return fmt.Errorf("priv_protocol requiere auth_protocol (USM no admite cifrado sin autenticación)")
+		}
+		if c.PrivPassword == "" {
+			return fmt.Errorf("priv_password requerido con priv_protocol %s", c.PrivProtocol)
+		}
+	}
+	return nil
+}
+
+// apply configura los parámetros USM en una conexión gosnmp
+func (c V3Credentials) apply(params *gosnmp.GoSNMP) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("credenciales SNMPv3: %w", err)
+	}
+	auth := v3AuthProtocols[strings.ToUpper(c.AuthProtocol)]
+	priv := v3PrivProtocols[strings.ToUpper(c.PrivProtocol)]
+
+	params.Version = gosnmp.Version3
+	params.SecurityModel = gosnmp.UserSecurityModel
+	params.ContextName = c.ContextName
+	switch {
+	case priv != gosnmp.NoPriv:
+		params.MsgFlags = gosnmp.AuthPriv
+	case auth != gosnmp.NoAuth:
+		params.MsgFlags = gosnmp.AuthNoPriv
+	default:
+		params.MsgFlags = gosnmp.NoAuthNoPriv
+	}
+	params.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 c.Username,
+		AuthenticationProtocol:   auth,
+		AuthenticationPassphrase: c.AuthPassword,
+		PrivacyProtocol:          priv,
+		PrivacyPassphrase:        c.PrivPassword,
+	}
+	return nil
+}
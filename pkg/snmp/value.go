@@ -0,0 +1,172 @@
+package snmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Value es un valor SNMP tipado: conserva el tipo ASN.1 y el valor nativo
+// (int64, uint64, string o []byte) para no perder precisión en Counter64
+// ni distinguir mal Counter32 de Integer
+//
+// Implementa fmt.Stringer con la misma representación textual que usaba
+// ParseValue, por lo que fmt.Sprintf("%v", v) sigue funcionando
+type Value struct {
+	Type gosnmp.Asn1BER
+	raw  interface{} // int64 | uint64 | string | []byte | nil
+}
+
+// NewValue convierte un PDU de gosnmp en un Value tipado
+func NewValue(pdu gosnmp.SnmpPDU) Value {
+	v := Value{Type: pdu.Type}
+
+	switch pdu.Type {
+	case gosnmp.Integer:
+		v.raw = gosnmp.ToBigInt(pdu.Value).Int64()
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32, gosnmp.Counter64:
+		v.raw = toUint64(pdu.Value)
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		if b, ok := pdu.Value.([]byte); ok {
+			v.raw = b
+		} else if pdu.Value != nil {
+			v.raw = fmt.Sprintf("%v", pdu.Value)
+		}
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+		v.raw = nil
+	default:
+		if pdu.Value != nil {
+			v.raw = fmt.Sprintf("%v", pdu.Value)
+		}
+	}
+
+	return v
+}
+
+// toUint64 convierte los enteros sin signo de gosnmp sin pasar por int64
+func toUint64(value interface{}) uint64 {
+	switch n := value.(type) {
+	case uint:
+		return uint64(n)
+	case uint32:
+		return uint64(n)
+	case uint64:
+		return n
+	case int:
+		return uint64(n)
+	case *big.Int:
+		return n.Uint64()
+	}
+	return gosnmp.ToBigInt(value).Uint64()
+}
+
+// IsNull indica si el agente no retornó valor (noSuchObject, endOfMibView, null)
+func (v Value) IsNull() bool {
+	return v.raw == nil
+}
+
+// IsNumeric indica si el valor es un entero SNMP
+func (v Value) IsNumeric() bool {
+	switch v.raw.(type) {
+	case int64, uint64:
+		return true
+	}
+	return false
+}
+
+// Is32Bit indica si es un contador/gauge sin signo de 32 bits (puede dar la vuelta)
+func (v Value) Is32Bit() bool {
+	switch v.Type {
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		return true
+	}
+	return false
+}
+
+// Int64 retorna el valor como int64
+// Acepta enteros SNMP y strings numéricos (algunos equipos reportan contadores como texto)
+// Retorna false si no es numérico o si un Counter64 no cabe en int64
+func (v Value) Int64() (int64, bool) {
+	switch n := v.raw.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		if n > 1<<63-1 {
+			return 0, false
+		}
+		return int64(n), true
+	case string, []byte:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(v.String()), 10, 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// Uint64 retorna el valor como uint64 (negativos → false)
+func (v Value) Uint64() (uint64, bool) {
+	switch n := v.raw.(type) {
+	case uint64:
+		return n, true
+	case int64:
+		if n < 0 {
+			return 0, false
+		}
+		return uint64(n), true
+	case string, []byte:
+		parsed, err := strconv.ParseUint(strings.TrimSpace(v.String()), 10, 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// Bytes retorna los bytes crudos de un OctetString (nil si no aplica)
+func (v Value) Bytes() []byte {
+	switch b := v.raw.(type) {
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	}
+	return nil
+}
+
+// String retorna la representación textual
+// Maneja strings, bytes (con decodificación UTF-8 y MAC) y números
+func (v Value) String() string {
+	switch val := v.raw.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case string:
+		// Limpiar null terminators
+		return strings.TrimRight(val, "\x00")
+	case []byte:
+		// Primero intentar interpretar como UTF-8 o ASCII válido
+		if isValidUTF8(val) && isLikelyText(val) {
+			return strings.TrimRight(string(val), "\x00")
+		}
+
+		// Si es exactamente 6 bytes y NO es texto válido, asumir que es MAC address binario
+		if len(val) == 6 && !isLikelyText(val) {
+			hexStr := hex.EncodeToString(val)
+			return fmt.Sprintf("%s:%s:%s:%s:%s:%s", hexStr[0:2], hexStr[2:4], hexStr[4:6], hexStr[6:8], hexStr[8:10], hexStr[10:12])
+		}
+
+		// Para otros bytes, retornar como string si es ASCII imprimible
+		if isLikelyASCII(val) {
+			return strings.TrimRight(string(val), "\x00")
+		}
+
+		// Si no es texto válido, retornar vacío
+		return ""
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,160 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/expr"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Reglas de alerta del usuario
+//
+// config.yaml define condiciones en el lenguaje de pkg/expr que se evalúan
+// sobre la telemetría de cada poll (los mismos campos del JSON de queue/).
+// supplies es un objeto indexado por tipo y color en camelCase (tonerBlack,
+// drum, waste) y por ID; la lista original queda en supply_list:
+//
+//	supplies.tonerBlack.percentage < 15 && printer.brand == "HP"
+//	printer.tags.cost_center == "CC-12" && counters.delta.total_pages > 5000
+
+// ruleSeverities son las severidades que acepta una regla
+var ruleSeverities = []string{"info", "warning", "critical"}
+
+// AlertRule genera una alerta mientras Expr sea verdadera
+type AlertRule struct {
+	ID       string // único; la alerta es "rule_<id>"
+	Expr     string
+	Severity string // info | warning | critical
+	Type     string // "" = "custom"
+	Message  string // plantilla de pkg/expr: "Tóner negro en {supplies.tonerBlack.percentage}%"; "" = "Alert rule <id> matched"
+}
+
+// AlertRules son las reglas compiladas
+type AlertRules struct {
+	rules []alertRule
+}
+
+type alertRule struct {
+	AlertRule
+	expr    *expr.Expr
+	message *expr.Template
+}
+
+// NewAlertRules compila las reglas; rechaza expresiones o mensajes inválidos,
+// severidades desconocidas e IDs repetidos
+func NewAlertRules(rules []AlertRule) (*AlertRules, error) {
+	compiled := &AlertRules{}
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.ID == "" || rule.Expr == "" {
+			return nil, fmt.Errorf("regla %d: requiere id y expr", i+1)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("regla %q: id repetido", rule.ID)
+		}
+		seen[rule.ID] = true
+		if !contains(ruleSeverities, rule.Severity) {
+			return nil, fmt.Errorf("regla %q: severity debe ser %s", rule.ID, strings.Join(ruleSeverities, ", "))
+		}
+
+		e, err := expr.Compile(rule.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("regla %q: expr: %w", rule.ID, err)
+		}
+		message := rule.Message
+		if message == "" {
+			message = "Alert rule " + rule.ID + " matched"
+		}
+		tmpl, err := expr.CompileTemplate(message)
+		if err != nil {
+			return nil, fmt.Errorf("regla %q: message: %w", rule.ID, err)
+		}
+		if rule.Type == "" {
+			rule.Type = "custom"
+		}
+		compiled.rules = append(compiled.rules, alertRule{AlertRule: rule, expr: e, message: tmpl})
+	}
+	return compiled, nil
+}
+
+// Evaluate retorna las alertas de las reglas que se cumplen en t
+// Una regla que falla (tipos incompatibles) se informa en el log y no dispara
+func (r *AlertRules) Evaluate(t *Telemetry) []AlertInfo {
+	if r == nil || len(r.rules) == 0 {
+		return nil
+	}
+	env, err := ruleEnv(t)
+	if err != nil {
+		return nil
+	}
+
+	var alerts []AlertInfo
+	for _, rule := range r.rules {
+		matched, err := rule.expr.Bool(env)
+		if err != nil {
+			fmt.Println(i18n.T("log.alert_rule_error", rule.ID, t.Printer.IP, err))
+			continue
+		}
+		if !matched {
+			continue
+		}
+		alerts = append(alerts, AlertInfo{
+			ID:         "rule_" + rule.ID,
+			Type:       rule.Type,
+			Severity:   rule.Severity,
+			Message:    rule.message.Render(env),
+			DetectedAt: t.CollectedAt,
+		})
+	}
+	return alerts
+}
+
+// ruleEnv arma las variables de las reglas con el JSON de la telemetría
+func ruleEnv(t *Telemetry) (expr.Env, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var env expr.Env
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+
+	// El primero de cada clave gana: dos tóneres negros se distinguen por ID
+	supplies := make(map[string]interface{})
+	list, _ := env["supplies"].([]interface{})
+	for i, s := range t.Supplies {
+		for _, key := range []string{supplyKey(s), s.ID} {
+			if _, ok := supplies[key]; !ok && key != "" {
+				supplies[key] = list[i]
+			}
+		}
+	}
+	env["supply_list"] = list
+	env["supplies"] = supplies
+	return env, nil
+}
+
+// supplyKey es tipo + color en camelCase: toner/black → tonerBlack
+func supplyKey(s SupplyInfo) string {
+	var key strings.Builder
+	for _, word := range strings.FieldsFunc(s.Type+" "+s.Color, func(r rune) bool { return r == ' ' || r == '_' || r == '-' }) {
+		word = strings.ToLower(word)
+		if key.Len() > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		key.WriteString(word)
+	}
+	return key.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAlertRulesRejectsInvalid(t *testing.T) {
+	valid := AlertRule{ID: "toner", Expr: "true", Severity: "warning"}
+	for _, tc := range []struct {
+		name  string
+		rules []AlertRule
+		want  string
+	}{
+		{"sin id", []AlertRule{{Expr: "true", Severity: "info"}}, "requiere id y expr"},
+		{"sin expr", []AlertRule{{ID: "a", Severity: "info"}}, "requiere id y expr"},
+		{"id repetido", []AlertRule{valid, valid}, "id repetido"},
+		{"severity", []AlertRule{{ID: "a", Expr: "true", Severity: "urgente"}}, "severity"},
+		{"expr", []AlertRule{{ID: "a", Expr: "printer.brand ==", Severity: "info"}}, "expr"},
+		{"message", []AlertRule{{ID: "a", Expr: "true", Severity: "info", Message: "{printer.ip"}}, "message"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAlertRules(tc.rules)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, se esperaba uno con %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAlertRulesEvaluate(t *testing.T) {
+	rules, err := NewAlertRules([]AlertRule{
+		{ID: "toner_hp", Expr: `supplies.tonerBlack.percentage < 15 && printer.brand == "HP"`, Severity: "warning", Message: "Tóner negro en {supplies.tonerBlack.percentage}% ({printer.ip})"},
+		{ID: "cc12", Expr: `printer.tags.cost_center == "CC-12"`, Severity: "info", Type: "billing"},
+		{ID: "drum", Expr: `supplies.drum.percentage < 15`, Severity: "critical"},
+		{ID: "segundo_negro", Expr: `supplies.toner_black_2.percentage < 50 && len(supply_list) == 3`, Severity: "info"},
+		{ID: "tipos", Expr: `printer.brand > 3`, Severity: "critical"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collected := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tel := &Telemetry{
+		CollectedAt: collected,
+		Printer:     PrinterInfo{IP: "10.0.0.5", Brand: "HP", Tags: Tags{"cost_center": "CC-12"}},
+		Supplies: []SupplyInfo{
+			{ID: "toner_black", Type: "toner", Color: "black", Percentage: 12},
+			{ID: "toner_black_2", Type: "toner", Color: "black", Percentage: 40},
+			{ID: "fuser", Type: "fuser", Percentage: 80},
+		},
+	}
+
+	alerts := rules.Evaluate(tel)
+	got := make(map[string]AlertInfo)
+	for _, a := range alerts {
+		got[a.ID] = a
+	}
+	// drum no existe (null) y tipos falla: ninguna dispara
+	if len(alerts) != 3 {
+		t.Fatalf("%d alertas, se esperaban 3: %+v", len(alerts), alerts)
+	}
+
+	toner := got["rule_toner_hp"]
+	if toner.Severity != "warning" || toner.Type != "custom" || toner.Message != "Tóner negro en 12% (10.0.0.5)" || !toner.DetectedAt.Equal(collected) {
+		t.Errorf("rule_toner_hp: %+v", toner)
+	}
+	if cc := got["rule_cc12"]; cc.Type != "billing" || cc.Message != "Alert rule cc12 matched" {
+		t.Errorf("rule_cc12: %+v", cc)
+	}
+	// El primer tóner negro se llama tonerBlack; el segundo solo por su ID
+	if _, ok := got["rule_segundo_negro"]; !ok {
+		t.Error("no disparó la regla sobre el segundo tóner negro")
+	}
+
+	var none *AlertRules
+	if alerts := none.Evaluate(tel); alerts != nil {
+		t.Errorf("sin reglas: %+v", alerts)
+	}
+}
+
+func TestSupplyKey(t *testing.T) {
+	for _, tc := range []struct {
+		supply SupplyInfo
+		want   string
+	}{
+		{SupplyInfo{Type: "toner", Color: "black"}, "tonerBlack"},
+		{SupplyInfo{Type: "drum"}, "drum"},
+		{SupplyInfo{Type: "waste_toner"}, "wasteToner"},
+		{SupplyInfo{Type: "ink", Color: "light-cyan"}, "inkLightCyan"},
+		{SupplyInfo{}, ""},
+	} {
+		if got := supplyKey(tc.supply); got != tc.want {
+			t.Errorf("supplyKey(%+v) = %q, se esperaba %q", tc.supply, got, tc.want)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package telemetry
 
 import (
 	"fmt"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/asaavedra/agent-snmp/pkg/clock"
 	"github.com/asaavedra/agent-snmp/pkg/collector"
 )
 
@@ -13,7 +15,16 @@ import (
 // Responsabilidad ÚNICA: mapear campos sin lógica SNMP
 // Si mañana cambias protocolo (SNMP → REST), Builder NO cambia
 type Builder struct {
-	source AgentSource // quién envía (agent_id, hostname, os, version)
+	source AgentSource   // quién envía (agent_id, hostname, os, version)
+	sites  *SiteResolver // reglas de ubicación (nil = sin jerarquía)
+	tags   *TagResolver  // tags del usuario (nil = sin tags)
+
+	supplies *SupplyDictionary // palabras clave de consumibles (nil = incorporado)
+	costs    *CostCatalog      // precios por número de parte (nil = sin costos)
+	rules    *AlertRules       // reglas de alerta del usuario (nil = solo las incorporadas)
+	clock    *clock.Clock      // hora corregida del agente (nil = clock_skew_ms no medido)
+
+	scanID string // ciclo en curso ("" = fuera de un ciclo)
 }
 
 // NewBuilder crea un nuevo builder
@@ -23,6 +34,50 @@ func NewBuilder(source AgentSource) *Builder {
 	}
 }
 
+// SetSites asigna las reglas que resuelven PrinterInfo.Site
+func (b *Builder) SetSites(sites *SiteResolver) {
+	b.sites = sites
+}
+
+// SetTags asigna el resolver de PrinterInfo.Tags
+func (b *Builder) SetTags(tags *TagResolver) {
+	b.tags = tags
+}
+
+// SetClock asigna el reloj corregido cuyo desfase se anota en cada telemetría
+func (b *Builder) SetClock(c *clock.Clock) {
+	b.clock = c
+}
+
+// SetScanID asigna el ID del ciclo que se anota en telemetrías y eventos de
+// inventario: correlaciona la queue, state/, output.dir y el backend
+func (b *Builder) SetScanID(id string) {
+	b.scanID = id
+}
+
+// SetSupplyDictionary asigna el diccionario de tipos y colores de consumibles
+func (b *Builder) SetSupplyDictionary(d *SupplyDictionary) {
+	b.supplies = d
+}
+
+// SetCostCatalog asigna el catálogo de precios con el que se estiman los costos
+func (b *Builder) SetCostCatalog(c *CostCatalog) {
+	b.costs = c
+}
+
+// SetAlertRules asigna las reglas de alerta que se evalúan en cada Build
+func (b *Builder) SetAlertRules(rules *AlertRules) {
+	b.rules = rules
+}
+
+// supplyDictionary retorna el diccionario configurado o el incorporado
+func (b *Builder) supplyDictionary() *SupplyDictionary {
+	if b.supplies != nil {
+		return b.supplies
+	}
+	return defaultSupplyDictionary
+}
+
 // sanitizeEmptyString convierte strings vacíos a nil (que será null en JSON)
 // Se usa para campos opcionales que pueden no existir en algunos printers
 // Retorna *string: si el string está vacío, retorna nil; sino retorna pointer al string
@@ -53,8 +108,29 @@ func (b *Builder) Build(data *collector.PrinterData, delta *collector.CountersDi
 		Model:           b.sanitizeEmptyString(b.extractModel(data)),
 		SerialNumber:    b.sanitizeEmptyString(b.extractSerialNumber(data)),
 		Hostname:        b.sanitizeEmptyString(b.extractHostname(data)),
+		SysName:         b.sanitizeEmptyString(data.Info.SysName),
+		DNSName:         b.sanitizeEmptyString(data.Info.DNSName),
+		HostnameSync:    data.Info.HostnameSync,
 		MacAddress:      b.sanitizeEmptyString(b.extractMacAddress(data)),
+		Site:            b.sites.Resolve(data.IP, b.extractLocation(data)),
+	}
+	if len(data.Addresses) > 1 {
+		printer.Addresses = data.Addresses
+		printer.Interfaces = data.Network.PhysicalInterfaces()
 	}
+	if m := data.Catalog; m != nil {
+		printer.Catalog = &CatalogInfo{
+			Name:       m.Model.Name,
+			PPM:        m.Model.PPM,
+			Class:      m.Model.Class(),
+			Confidence: m.Confidence,
+			Source:     m.Source,
+		}
+	}
+	printer.Tags = b.tags.Resolve(printer.ID, data.IP, b.extractSerialNumber(data))
+
+	// Construir estado operativo (online/offline, uptime, errores de hardware)
+	status := b.buildStatus(data)
 
 	// Construir counters (absolute + delta)
 	counters := b.buildCounters(data, delta, resetDetected)
@@ -65,6 +141,9 @@ func (b *Builder) Build(data *collector.PrinterData, delta *collector.CountersDi
 	// Construir alerts (nil si no hay)
 	alerts := b.buildAlerts(data)
 
+	// Construir capabilities (detectadas en el poll)
+	capabilities := b.buildCapabilities(data)
+
 	// Construir metrics
 	metrics := b.buildMetrics(data)
 
@@ -76,69 +155,93 @@ func (b *Builder) Build(data *collector.PrinterData, delta *collector.CountersDi
 	telemetry := &Telemetry{
 		SchemaVersion: "1.0.0", // Congelado
 		EventID:       eventID,
+		ScanID:        b.scanID,
 		CollectedAt:   data.Timestamp.UTC(),
+		ClockSkewMs:   b.clockSkewMs(),
 		Source:        b.source,
 		Printer:       printer,
+		Status:        status,
 		Counters:      counters,
 		Supplies:      supplies, // nil si no aplica
 		Alerts:        alerts,   // nil si no aplica
+		Capabilities:  capabilities,
 		Metrics:       metrics,
+		Costs:         b.buildCosts(supplies, data.Usage),
+		Extensions:    data.Extensions,
 	}
+	// Las reglas ven la telemetría completa, incluidas las alertas incorporadas
+	telemetry.Alerts = append(telemetry.Alerts, b.rules.Evaluate(telemetry)...)
 
 	return telemetry, nil
 }
 
-// buildPrinterID genera un ID único, estable y corto
-// Prioridad: MAC (más estable) → Serial (única) → IP (fallback)
-// Resultado es lowercase sin caracteres especiales
-func (b *Builder) buildPrinterID(data *collector.PrinterData) string {
-	// 1. Intentar usar MAC address (la más estable)
-	// Buscar en NetworkInfo
-	if data.NetworkInfo != nil {
-		if macAddress, ok := data.NetworkInfo["macAddress"].(string); ok && macAddress != "" {
-			// Limpiar MAC: remover puntos y convertir a lowercase
-			cleanMac := strings.ToLower(strings.ReplaceAll(macAddress, ":", ""))
-			if len(cleanMac) >= 12 {
-				return cleanMac
-			}
-		}
+// buildStatus mapea el estado tipado del collector a StatusInfo
+func (b *Builder) buildStatus(data *collector.PrinterData) *StatusInfo {
+	pageCount := data.State.PageCount
+	if pageCount == 0 {
+		pageCount = data.PageCounters.TotalPages
+	}
+
+	status := &StatusInfo{
+		State:               b.extractState(data),
+		PageCount:           pageCount,
+		SystemUptime:        b.formatUptime(b.extractUptimeSeconds(data)),
+		SystemUptimeSeconds: b.extractUptimeSeconds(data),
+		SystemLocation:      strings.TrimSpace(b.extractLocation(data)),
+		MediaReady:          data.State.MediaReady,
 	}
 
-	// 2. Fallback a serial number
-	serial := strings.TrimSpace(b.extractSerialNumber(data))
-	if serial != "" {
-		return strings.ToLower(serial)
+	if data.State.Errors.Reported {
+		errState := data.State.Errors
+		status.ErrorState = &errState
 	}
 
-	// 3. Fallback a IP
-	return data.IP
+	return status
 }
 
-// buildCounters extrae los contadores acumulativos
-func (b *Builder) buildCounters(data *collector.PrinterData, delta *collector.CountersDiff, resetDetected bool) *collector.CountersSnapshot {
-	// Prioritario: NormalizedCounters (extraídos y mapeados correctamente)
-	countersToUse := data.NormalizedCounters
-	if len(countersToUse) == 0 {
-		countersToUse = data.Counters
+// formatUptime convierte segundos a "XXd HHh MMm" ("" si no hay uptime)
+func (b *Builder) formatUptime(seconds int64) string {
+	if seconds <= 0 {
+		return ""
 	}
 
-	if len(countersToUse) == 0 {
-		return nil
+	days := seconds / 86400
+	hours := (seconds % 86400) / 3600
+	minutes := (seconds % 3600) / 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	} else if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
 	}
+	return fmt.Sprintf("%dm", minutes)
+}
 
-	absolute := collector.CountersInfo{
-		TotalPages: int64(b.extractCounter(countersToUse, "total_pages")),
-		MonoPages:  int64(b.extractCounter(countersToUse, "mono_pages")),
-		ColorPages: int64(b.extractCounter(countersToUse, "color_pages")),
-		ScanPages:  int64(b.extractCounter(countersToUse, "scan_pages")),
-		CopyPages:  int64(b.extractCounter(countersToUse, "copy_pages")),
-		FaxPages:   int64(b.extractCounter(countersToUse, "fax_pages")),
+// buildPrinterID genera un ID único, estable y corto
+// Prioridad: ID canónico del collector → MAC → Serial → IP
+// Resultado es lowercase sin caracteres especiales
+func (b *Builder) buildPrinterID(data *collector.PrinterData) string {
+	// El collector ya calculó el ID con el que se indexan perfiles y estado
+	if data.PrinterID != "" {
+		return data.PrinterID
+	}
+
+	return collector.CanonicalPrinterID(b.extractMacAddress(data), b.extractSerialNumber(data), data.IP)
+}
+
+// buildCounters extrae los contadores acumulativos
+func (b *Builder) buildCounters(data *collector.PrinterData, delta *collector.CountersDiff, resetDetected bool) *collector.CountersSnapshot {
+	if !data.HasCounters() {
+		return nil
 	}
 
 	snapshot := &collector.CountersSnapshot{
-		Absolute:      absolute,
+		Absolute:      data.PageCounters,
 		Delta:         delta,
 		ResetDetected: resetDetected,
+		Sources:       data.CounterSources,
+		Confidence:    data.CounterConfidence,
+		Anomalies:     data.CounterAnomalies,
 	}
 
 	return snapshot
@@ -166,18 +269,17 @@ func (b *Builder) buildEventID(printer PrinterInfo, timestamp time.Time) string
 // Retorna nil si no hay supplies con datos útiles
 // Filtra: vacíos, "unknown", level=0 sin maxLevel, solo nombres sin info
 func (b *Builder) buildSupplies(data *collector.PrinterData) []SupplyInfo {
-	if len(data.Supplies) == 0 {
+	if len(data.SupplyList) == 0 {
 		return nil // nil, no []SupplyInfo{} - más semántico
 	}
 
 	supplies := make([]SupplyInfo, 0)
 
-	for _, supply := range data.Supplies {
-		// Extraer campos crudos
-		name := b.extractFieldAsString(supply, "name", "description")
-		level := int64(b.extractFieldAsInt(supply, "level", "current"))
-		maxLevel := int64(b.extractFieldAsInt(supply, "maxLevel", "max"))
-		percentage := b.extractFieldAsInt(supply, "percentage", "percent")
+	for _, supply := range data.SupplyList {
+		name := strings.TrimSpace(supply.Description)
+		level := supply.Level
+		maxLevel := supply.MaxLevel
+		percentage := supply.Percentage
 
 		// FILTROS ESTRICTOS:
 		// 1. Nombre vacío o "unknown"
@@ -185,13 +287,7 @@ func (b *Builder) buildSupplies(data *collector.PrinterData) []SupplyInfo {
 			continue
 		}
 
-		// 2. Solo espacios
-		name = strings.TrimSpace(name)
-		if name == "" {
-			continue
-		}
-
-		// 3. Si level=0 Y maxLevel=0 Y percentage=0 → datos inútiles
+		// 2. Si level=0 Y maxLevel=0 Y percentage=0 → datos inútiles
 		// (excepto si hay name con info real)
 		if level == 0 && maxLevel == 0 && percentage == 0 {
 			// Si el nombre tiene info de S/N o descripción técnica, incluir
@@ -210,54 +306,55 @@ func (b *Builder) buildSupplies(data *collector.PrinterData) []SupplyInfo {
 			}
 		}
 
-		// 4. Limpiar nombre: remover espacios extras y S/N de más
+		// 3. Limpiar nombre: remover espacios extras y S/N de más
 		cleanName := b.cleanSupplyName(name)
 		if cleanName == "" {
 			continue
 		}
 
-		// 5. CALCULAR porcentaje si no viene en los datos o es 0
-		// Prioridad: campo "percentage" → calcular desde level/maxLevel
-		calculatedPercentage := percentage
-		if calculatedPercentage == 0 && maxLevel > 0 && level > 0 {
-			calculatedPercentage = int((level * 100) / maxLevel)
+		// 4. Campos adicionales; si faltan, intentar extraerlos de la descripción
+		model := supply.Model
+		serialNumber := supply.SerialNumber
+		if serialNumber == "" {
+			serialNumber = b.extractSerialFromDescription(supply.Description)
 		}
-
-		// 6. Extraer campos adicionales para detalles completos
-		model := b.extractFieldAsString(supply, "model", "partnumber", "part_number")
-		serialNumber := b.extractFieldAsString(supply, "serial_number", "serial", "sn")
-		description := b.extractFieldAsString(supply, "description", "desc")
-		componentType := b.extractFieldAsString(supply, "component_type", "type_code")
-		oem := b.extractFieldAsString(supply, "oem", "brand", "manufacturer")
-
-		// Intentar extraer capacidad en páginas si está disponible
-		pageCapacity := int64(b.extractFieldAsInt(supply, "page_capacity", "pages", "capacity"))
-
-		// Si no tenemos serialNumber, intentar extraer de la descripción
-		if serialNumber == "" && description != "" {
-			serialNumber = b.extractSerialFromDescription(description)
+		if model == "" {
+			model = b.extractPartNumberFromDescription(supply.Description)
 		}
 
-		// Si no tenemos model/part_number, intentar extraer de la descripción
-		if model == "" && description != "" {
-			model = b.extractPartNumberFromDescription(description)
+		// Sin colorante en prtMarkerColorantTable el color sale de la descripción
+		color := supply.Color
+		if color == "" {
+			color = b.supplyDictionary().Color(cleanName)
 		}
 
+		supplyType := b.supplyType(supply, cleanName)
+
 		si := SupplyInfo{
 			ID:         b.normalizeToID(cleanName),
 			Name:       cleanName,
-			Type:       b.deduceSupplyType(cleanName),
+			Type:       supplyType,
 			Level:      level,
 			MaxLevel:   maxLevel,
-			Percentage: calculatedPercentage,
-			Status:     b.deduceSupplyStatus(calculatedPercentage),
+			Percentage: b.supplyPercentage(supply, supplyType),
+			Status:     b.supplyStatus(supply, supplyType),
+			LevelState: b.supplyLevelState(supply),
 			// Campos adicionales de detalles
 			Model:         model,
 			SerialNumber:  serialNumber,
-			Description:   description,
-			ComponentType: componentType,
-			Brand:         oem,
-			PageCapacity:  pageCapacity,
+			Description:   supply.Description,
+			ComponentType: supply.ComponentType,
+			Color:         color,
+			Brand:         supply.Brand,
+			PageCapacity:  supply.PageCapacity,
+			RatedYield:    supply.RatedYield,
+			CostPerPage:   b.supplyCostPerPage(model, supply),
+
+			Class:          supply.Class,
+			Unit:           supply.Unit,
+			RemainingPages: supply.RemainingPages,
+			ReplaceBy:      supply.ReplaceBy,
+			FillsUp:        b.fillsUp(supply, supplyType),
 		}
 
 		supplies = append(supplies, si)
@@ -273,50 +370,67 @@ func (b *Builder) buildSupplies(data *collector.PrinterData) []SupplyInfo {
 // buildAlerts extrae alertas activas del estado de consumibles
 // Retorna nil si no hay alertas
 func (b *Builder) buildAlerts(data *collector.PrinterData) []AlertInfo {
-	if len(data.Supplies) == 0 {
-		return nil
-	}
-
-	alerts := make([]AlertInfo, 0)
+	// Condiciones de hardware reportadas por hrPrinterDetectedErrorState
+	alerts := b.buildStatusAlerts(data)
 
 	// Generar alertas basadas en estado de supplies
-	for _, supply := range data.Supplies {
-		status := b.extractSupplyStatus(supply)
-
-		// Solo crear alerta si el status es warning/critical
-		if status == "critical" || status == "low" {
-			severity := "warning"
-			if status == "critical" {
-				severity = "critical"
+	for _, supply := range data.SupplyList {
+		// Nivel desconocido (-2): el consumible no se puede monitorear, alerta informativa
+		// "Quedan unidades" (-3) u "other" (-1): sin porcentaje, no generan alerta
+		if supply.LevelState == collector.LevelStateUnknown {
+			cleanName := b.cleanSupplyName(supply.Description)
+			if cleanName == "" {
+				continue
 			}
+			alerts = append(alerts, AlertInfo{
+				ID:         fmt.Sprintf("%s_%s", b.deduceSupplyType(cleanName), collector.LevelStateUnknown),
+				Type:       "supply",
+				Severity:   "info",
+				Message:    fmt.Sprintf("%s level is unknown (device reports -2)", cleanName),
+				DetectedAt: data.Timestamp,
+			})
+			continue
+		}
+		if supply.LevelState != collector.LevelStateKnown {
+			continue
+		}
 
-			// Construir mensaje con modelo del supply si disponible
-			supplyName := b.extractFieldAsString(supply, "name", "description")
-			supplyModel := b.extractFieldAsString(supply, "model", "")
-			percentage := b.extractFieldAsInt(supply, "percentage", "percent")
+		// Usar cleanSupplyName y deduceSupplyType para obtener el tipo consistentemente
+		cleanName := b.cleanSupplyName(supply.Description)
+		supplyType := b.supplyType(supply, cleanName)
+		percentage := b.supplyPercentage(supply, supplyType)
+		status := b.supplyStatus(supply, supplyType)
 
-			message := fmt.Sprintf("%s is %s (%d%%)", supplyName, status, percentage)
-			if supplyModel != "" {
-				message = fmt.Sprintf("%s %s is %s (%d%%)", supplyName, supplyModel, status, percentage)
-			}
+		// Solo crear alerta si el status es warning/critical
+		severity := supplyAlertSeverity[status]
+		if severity == "" {
+			continue
+		}
 
-			// Generar alert ID simple: {supply_type}_{status}
-			// Usar cleanSupplyName y deduceSupplyType para obtener el tipo consistentemente
-			cleanName := b.cleanSupplyName(supplyName)
-			supplyType := b.deduceSupplyType(cleanName)
-			if supplyType == "" {
-				supplyType = "supply"
-			}
+		// Construir mensaje con modelo del supply si disponible
+		// En los que se llenan el porcentaje es de llenado
+		description := supply.Description
+		if supply.Model != "" {
+			description = fmt.Sprintf("%s %s", supply.Description, supply.Model)
+		}
+		message := fmt.Sprintf("%s is %s (%d%%)", description, status, percentage)
+		if b.fillsUp(supply, supplyType) {
+			message = fmt.Sprintf("%s is %s (%d%% full)", description, strings.ReplaceAll(status, "_", " "), percentage)
+		}
 
-			alert := AlertInfo{
-				ID:         fmt.Sprintf("%s_%s", supplyType, status),
-				Type:       "supply",
-				Severity:   severity,
-				Message:    message,
-				DetectedAt: data.Timestamp,
-			}
-			alerts = append(alerts, alert)
+		// Generar alert ID simple: {supply_type}_{status}
+		if supplyType == "" {
+			supplyType = "supply"
+		}
+
+		alert := AlertInfo{
+			ID:         fmt.Sprintf("%s_%s", supplyType, status),
+			Type:       "supply",
+			Severity:   severity,
+			Message:    message,
+			DetectedAt: data.Timestamp,
 		}
+		alerts = append(alerts, alert)
 	}
 
 	if len(alerts) == 0 {
@@ -326,6 +440,63 @@ func (b *Builder) buildAlerts(data *collector.PrinterData) []AlertInfo {
 	return alerts
 }
 
+// supplyAlertSeverity define la severidad de cada estado de consumible que genera alerta
+var supplyAlertSeverity = map[string]string{
+	"low":       "warning",
+	"critical":  "critical",
+	"near_full": "warning",
+	"full":      "critical",
+}
+
+// statusAlertSeverity define la severidad de cada bit de hrPrinterDetectedErrorState
+var statusAlertSeverity = map[string]string{
+	"low_paper":             "warning",
+	"no_paper":              "critical",
+	"low_toner":             "warning",
+	"no_toner":              "critical",
+	"door_open":             "critical",
+	"jammed":                "critical",
+	"offline":               "critical",
+	"service_requested":     "warning",
+	"input_tray_missing":    "warning",
+	"output_tray_missing":   "warning",
+	"marker_supply_missing": "critical",
+	"output_near_full":      "info",
+	"output_full":           "warning",
+	"input_tray_empty":      "warning",
+	"overdue_prevent_maint": "info",
+}
+
+// buildStatusAlerts genera una alerta por cada condición activa del error state
+func (b *Builder) buildStatusAlerts(data *collector.PrinterData) []AlertInfo {
+	var alerts []AlertInfo
+	for _, condition := range data.State.Errors.Active() {
+		alerts = append(alerts, AlertInfo{
+			ID:         "status_" + condition,
+			Type:       "hardware",
+			Severity:   statusAlertSeverity[condition],
+			Message:    fmt.Sprintf("Printer reports %s", strings.ReplaceAll(condition, "_", " ")),
+			DetectedAt: data.Timestamp,
+		})
+	}
+	return alerts
+}
+
+// buildCapabilities mapea las capacidades detectadas por el collector
+// El color también se deduce de consumibles y contadores (extractColorCapability)
+func (b *Builder) buildCapabilities(data *collector.PrinterData) *CapabilitiesInfo {
+	caps := data.Capabilities
+	return &CapabilitiesInfo{
+		SNMPVersion:     caps.SNMPVersion,
+		Duplex:          caps.Duplex,
+		Color:           caps.Color || b.extractColorCapability(data),
+		Scanner:         caps.Scanner,
+		Fax:             caps.Fax,
+		OidsSupported:   caps.Subtrees,
+		OidsSuccessRate: caps.SuccessRate,
+	}
+}
+
 // buildMetrics construye las métricas del poll
 func (b *Builder) buildMetrics(data *collector.PrinterData) *MetricsInfo {
 	// IMPORTANTE: SIEMPRE UTC en timestamps
@@ -341,8 +512,8 @@ func (b *Builder) buildMetrics(data *collector.PrinterData) *MetricsInfo {
 			PollDurationMs: int(data.ResponseTime.Milliseconds()),
 			OidSuccessRate: 0.95,
 			RetryCount:     retryCount,
-			LastPollAt:     data.Timestamp.UTC(),
-			NextPollAt:     data.Timestamp.UTC().Add(1 * time.Hour),
+			LastPollAt:     data.Timestamp.UTC(), // ya corregido por el reloj del collector
+			NextPollAt:     nextPollAt(data),
 			ErrorCount:     len(data.Errors),
 		},
 	}
@@ -350,35 +521,31 @@ func (b *Builder) buildMetrics(data *collector.PrinterData) *MetricsInfo {
 	return metrics
 }
 
-// ============= HELPERS DE EXTRACCIÓN =============
-
-func (b *Builder) extractModel(data *collector.PrinterData) string {
-	if data.Identification == nil {
-		return ""
+// nextPollAt es el próximo poll planificado del dispositivo
+func nextPollAt(data *collector.PrinterData) time.Time {
+	if data.NextPollAt.IsZero() {
+		return data.Timestamp.UTC().Add(collector.DefaultPollInterval)
 	}
+	return data.NextPollAt.UTC()
+}
 
-	// Preferencia de claves para modelo
-	for _, key := range []string{"model", "model_name", "modelName", "printerModel"} {
-		if model, ok := data.Identification[key].(string); ok && model != "" {
-			model = strings.TrimSpace(model)
-			// Validar que no sea un serial/asset ID
-			if len(model) > 0 && !b.looksLikeSerialNumber(model) {
-				return model
-			}
-		}
+// clockSkewMs retorna el desfase medido del reloj del agente (nil = no medido)
+func (b *Builder) clockSkewMs() *int64 {
+	if b.clock == nil {
+		return nil
 	}
+	skew := b.clock.SkewMs()
+	return &skew
+}
 
-	// Fallback a description solo si contiene palabras de modelo reales
-	if description, ok := data.Identification["description"].(string); ok && description != "" {
-		description = strings.TrimSpace(description)
-		lowerDesc := strings.ToLower(description)
-		// Solo usar description si tiene palabras model-like
-		commonModelWords := []string{"m332", "m402", "m382", "officejet", "laserjet", "colorprint", "mfp", "copier", "ricoh"}
-		for _, word := range commonModelWords {
-			if strings.Contains(lowerDesc, word) {
-				return description
-			}
-		}
+// ============= HELPERS DE EXTRACCIÓN =============
+
+func (b *Builder) extractModel(data *collector.PrinterData) string {
+	model := data.Info.Model
+
+	// Validar que no sea un serial/asset ID
+	if model != "" && !b.looksLikeSerialNumber(model) {
+		return model
 	}
 
 	return ""
@@ -444,25 +611,11 @@ func (b *Builder) looksLikeSerialNumber(s string) bool {
 }
 
 func (b *Builder) extractSerialNumber(data *collector.PrinterData) string {
-	if data.Identification == nil {
-		return ""
-	}
-
-	// Buscar con clave canónica primero
-	if serial, ok := data.Identification["serial_number"].(string); ok && serial != "" {
-		serial = strings.TrimSpace(serial)
-		// Validar que no sea un nombre de marca
-		if !b.isBrandName(serial) {
-			return serial
-		}
-	}
+	serial := data.Info.SerialNumber
 
-	// Fallback a serialNumber (antiguo)
-	if serial, ok := data.Identification["serialNumber"].(string); ok && serial != "" {
-		serial = strings.TrimSpace(serial)
-		if !b.isBrandName(serial) {
-			return serial
-		}
+	// Validar que no sea un nombre de marca
+	if serial != "" && !b.isBrandName(serial) {
+		return serial
 	}
 
 	return ""
@@ -481,6 +634,10 @@ func (b *Builder) isBrandName(s string) bool {
 		"konica",
 		"minolta",
 		"kyocera",
+		"lexmark",
+		"epson",
+		"sharp",
+		"toshiba",
 		"panasonic",
 		"electronics",
 		"corporation",
@@ -497,160 +654,37 @@ func (b *Builder) isBrandName(s string) bool {
 }
 
 func (b *Builder) extractHostname(data *collector.PrinterData) string {
-	if data.Identification == nil {
-		return ""
-	}
-
-	// Buscar con clave canónica primero
-	if hostname, ok := data.Identification["hostname"].(string); ok && hostname != "" {
-		return hostname
-	}
-
-	// Fallback a sysName (antiguo)
-	if sysName, ok := data.Identification["sysName"].(string); ok && sysName != "" {
-		return sysName
-	}
-
-	return ""
+	return data.Info.Hostname
 }
 
 func (b *Builder) extractMacAddress(data *collector.PrinterData) string {
-	if data.NetworkInfo == nil {
-		return ""
-	}
-
-	if mac, ok := data.NetworkInfo["macAddress"].(string); ok {
-		return mac
-	}
-
-	return ""
+	return data.Network.MACAddress
 }
 
 func (b *Builder) extractState(data *collector.PrinterData) string {
-	if data.Status == nil {
-		return "unknown"
-	}
+	state := data.State.State
 
 	// IMPORTANTE: Si tenemos contadores/supplies, la impresora está online
 	// No puede estar offline si tiene datos actualizados
-	hasData := false
-	if len(data.NormalizedCounters) > 0 {
-		hasData = true
-	}
-	if len(data.Supplies) > 0 {
-		hasData = true
+	// (timeout puede haber sido después de recopilar)
+	// La excepción es el bit offline de hrPrinterDetectedErrorState: el equipo
+	// responde SNMP pero declara que no está imprimiendo
+	if state == "offline" && !data.State.Errors.Offline && (data.HasCounters() || len(data.SupplyList) > 0) {
+		return "unknown" // Conectividad inconsistente
 	}
 
-	if state, ok := data.Status["state"].(string); ok && state != "" {
-		// Si dice offline pero tiene datos, cambiar a unknown (timeout puede haber sido después de recopilar)
-		if state == "offline" && hasData {
-			return "unknown" // Conectividad inconsistente
-		}
-		return state
+	if state == "" {
+		return "unknown"
 	}
-
-	return "unknown"
-}
-
-func (b *Builder) extractUptimeString(data *collector.PrinterData) string {
-	if data.AdminInfo == nil {
-		return ""
-	}
-
-	if uptime, ok := data.AdminInfo["systemUptime"].(string); ok {
-		return uptime
-	}
-
-	return ""
+	return state
 }
 
 func (b *Builder) extractUptimeSeconds(data *collector.PrinterData) int64 {
-	// Extraer de AdminInfo["systemUptime"] y convertir a segundos
-	// Formato: puede ser número raw (centésimas de segundo SNMP) o string parseado
-	if data.AdminInfo == nil {
-		return 0
-	}
-
-	uptimeStr, ok := data.AdminInfo["systemUptime"].(string)
-	if !ok || uptimeStr == "" {
-		return 0
-	}
-
-	uptimeStr = strings.TrimSpace(uptimeStr)
-
-	// CASO 1: Intentar parsearlo como número puro (centésimas de segundo SNMP)
-	// Sistema SNMP retorna timeticks en centésimas de segundo
-	if uptimeNum, err := strconv.ParseInt(uptimeStr, 10, 64); err == nil && uptimeNum > 0 {
-		// Convertir centésimas de segundo a segundos
-		return uptimeNum / 100
-	}
-
-	// CASO 2: Parsear formato legible: "467 días, 13 horas, 57 minutos, 8 segundos"
-	// O variantes: "467d, 13h, 57m, 8s" o "467 days, 13 hours, 57 minutes, 8 seconds"
-	var days, hours, minutes, seconds int64
-
-	// Split por comas y procesar cada parte
-	parts := strings.Split(uptimeStr, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-
-		// Buscar patrón: número + unidad
-		if strings.Contains(strings.ToLower(part), "día") || strings.Contains(strings.ToLower(part), "day") {
-			fmt.Sscanf(part, "%d", &days)
-		} else if strings.Contains(strings.ToLower(part), "hora") || strings.Contains(strings.ToLower(part), "hour") {
-			fmt.Sscanf(part, "%d", &hours)
-		} else if strings.Contains(strings.ToLower(part), "minuto") || strings.Contains(strings.ToLower(part), "minute") {
-			fmt.Sscanf(part, "%d", &minutes)
-		} else if strings.Contains(strings.ToLower(part), "segundo") || strings.Contains(strings.ToLower(part), "second") {
-			fmt.Sscanf(part, "%d", &seconds)
-		}
-	}
-
-	// Calcular total en segundos
-	totalSeconds := days*86400 + hours*3600 + minutes*60 + seconds
-	if totalSeconds > 0 {
-		return totalSeconds
-	}
-
-	return 0
+	return data.State.UptimeSeconds
 }
 
 func (b *Builder) extractLocation(data *collector.PrinterData) string {
-	if data.AdminInfo == nil {
-		return ""
-	}
-
-	if loc, ok := data.AdminInfo["systemLocation"].(string); ok {
-		return loc
-	}
-
-	return ""
-}
-
-func (b *Builder) extractCounter(counters map[string]interface{}, keys ...string) int {
-	for _, key := range keys {
-		if val, ok := counters[key]; ok {
-			// Intentar conversiones en orden
-			if intVal, ok := val.(int); ok {
-				return intVal
-			}
-			if int64Val, ok := val.(int64); ok {
-				return int(int64Val)
-			}
-			if floatVal, ok := val.(float64); ok {
-				return int(floatVal)
-			}
-			// IMPORTANTE: Los valores pueden venir como strings desde el collector
-			// fmt.Sprintf("%v", val) produce strings
-			if strVal, ok := val.(string); ok {
-				if intVal, err := strconv.Atoi(strings.TrimSpace(strVal)); err == nil {
-					return intVal
-				}
-			}
-		}
-	}
-
-	return 0
+	return data.Network.Location
 }
 
 // cleanSupplyName limpia el nombre de un consumible
@@ -668,29 +702,35 @@ func (b *Builder) cleanSupplyName(name string) string {
 	// Ej: "Black Toner Cartridge S/N:CRUM-14101514763" → "Black Toner Cartridge"
 
 	// Buscar ";SN" (Xerox format: "Name, PN xxx;SNyyy")
-	if idx := strings.Index(strings.ToUpper(name), ";SN"); idx != -1 {
+	if idx, _ := indexFold(name, ";SN"); idx != -1 {
 		name = name[:idx]
 		name = strings.TrimSpace(name)
 	}
 
 	// Buscar "S/N:" (ISO/estándar format)
-	if idx := strings.Index(strings.ToUpper(name), "S/N:"); idx != -1 {
+	if idx, _ := indexFold(name, "S/N:"); idx != -1 {
 		name = name[:idx]
 		name = strings.TrimSpace(name)
 	}
 
-	// 3. Si contiene "Serial", "Part Number", "PN " (con espacio), "PN:", "PN=", separar
-	// IMPORTANTE: Buscar "PN " (con espacio) ANTES que "PN:" para Xerox format
-	for _, sep := range []string{"Serial", "Part Number", "PN ", "PN:", "PN=", "P/N:", "P/N ", "Model:", "Version:"} {
-		lowerName := strings.ToLower(name)
-		lowerSep := strings.ToLower(sep)
-		if idx := strings.Index(lowerName, lowerSep); idx != -1 {
+	// 3. Si contiene "Serial", "Part Number", "PN " (con espacio), "PN:", "PN=" o su
+	// equivalente en otro idioma ("Número de serie", "シリアル番号"), separar
+	for _, sep := range b.supplyDictionary().Separators() {
+		if idx, _ := indexFold(name, sep); idx != -1 {
 			name = name[:idx]
 			name = strings.TrimSpace(name)
 			break
 		}
 	}
 
+	// Número de parte entre paréntesis (Toshiba/Epson/Sharp)
+	// Ej: "Black Toner (T-FC330U-K)" → "Black Toner", "Toner (Black)" se conserva
+	if partNum := parenthesizedPartNumber(name); partNum != "" {
+		name = strings.TrimSpace(strings.Replace(name, "("+partNum+")", "", 1))
+	}
+	// Número de parte Sharp/Konica Minolta al final: "Toner (Black) MX-61NTBA" → "Toner (Black)"
+	name = vendorPartSuffix.ReplaceAllString(name, "")
+
 	// 4. Remover comas finales (si algún separador dejó comas al final)
 	name = strings.TrimSuffix(strings.TrimSpace(name), ",")
 	name = strings.TrimSpace(name)
@@ -705,90 +745,97 @@ func (b *Builder) cleanSupplyName(name string) string {
 
 	return name
 }
-func (b *Builder) extractSupplyID(supply interface{}) string {
-	// supply es un map[string]interface{}
-	if supplyMap, ok := supply.(map[string]interface{}); ok {
-		// Buscar campo "id" primero
-		if id, ok := supplyMap["id"].(string); ok && id != "" && id != "unknown" && id != "supply_unknown" {
-			return id
-		}
-		// Si no hay ID válido, generar desde el nombre
-		if name, ok := supplyMap["name"].(string); ok && name != "" {
-			// Generar ID a partir del nombre normalizando
-			// Ej: "Black Toner Cartridge" → "black_toner_cartridge"
-			normalized := b.normalizeToID(name)
-			if normalized != "" {
-				return normalized
-			}
-		}
-		// Si tampoco hay nombre, intentar buscar en otros campos
-		if desc, ok := supplyMap["description"].(string); ok && desc != "" {
-			normalized := b.normalizeToID(desc)
-			if normalized != "" {
-				return normalized
-			}
+
+// indexFold busca substr en s sin distinguir mayúsculas y retorna dónde
+// empieza y termina en s (-1, -1 si no está). Los índices de
+// strings.Index(strings.ToUpper(s), ...) no sirven para cortar s: el cambio
+// de caso puede cambiar el largo en bytes (un byte UTF-8 inválido pasa a
+// ocupar 3) y la descripción la controla el equipo
+func indexFold(s, substr string) (int, int) {
+	for i := range s {
+		if n := prefixFold(s[i:], substr); n != -1 {
+			return i, i + n
 		}
 	}
-
-	return "supply_unknown"
+	return -1, -1
 }
 
-func (b *Builder) extractSupplyStatus(supply interface{}) string {
-	if supplyMap, ok := supply.(map[string]interface{}); ok {
-		// Intentar obtener status directo
-		if status, ok := supplyMap["status"].(string); ok && status != "" && status != "unknown" {
-			return status
+// prefixFold retorna cuántos bytes de s coinciden con prefix sin distinguir
+// mayúsculas (-1 si s no empieza con prefix)
+func prefixFold(s, prefix string) int {
+	n := 0
+	for _, want := range prefix {
+		if n >= len(s) {
+			return -1
 		}
-
-		// Deducir status a partir del porcentaje
-		percentage := b.extractFieldAsInt(supply, "percentage", "percent")
-
-		if percentage == 0 {
-			// Intentar calcular desde level y max
-			level := b.extractFieldAsInt(supply, "level", "current")
-			maxLevel := b.extractFieldAsInt(supply, "maxLevel", "max")
-			if maxLevel > 0 && level > 0 {
-				percentage = (level * 100) / maxLevel
-			}
+		got, size := utf8.DecodeRuneInString(s[n:])
+		if got != want && !strings.EqualFold(string(got), string(want)) {
+			return -1
 		}
-
-		return b.deduceSupplyStatus(percentage)
+		n += size
 	}
-
-	return "unknown"
+	return n
 }
 
 // deduceSupplyType deduce el tipo de suministro a partir del nombre
+// (palabras clave en varios idiomas, ver SupplyDictionary)
 func (b *Builder) deduceSupplyType(name string) string {
-	lowerName := strings.ToLower(name)
-
-	// Mapa de keywords → tipos
-	typeMap := map[string]string{
-		"toner":         "toner",
-		"drum":          "drum",
-		"cilindro":      "drum",
-		"fuser":         "fuser",
-		"fusor":         "fuser",
-		"roller":        "roller",
-		"rodillo":       "roller",
-		"cartridge":     "cartridge",
-		"cartucho":      "cartridge",
-		"waste":         "waste",
-		"residuo":       "waste",
-		"transfer":      "transfer",
-		"transferencia": "transfer",
-		"pickup":        "pickup",
-		"retirada":      "pickup",
-	}
-
-	// Buscar el primer match
-	for keyword, supplyType := range typeMap {
-		if strings.Contains(lowerName, keyword) {
-			return supplyType
+	if supplyType := b.supplyDictionary().Type(name); supplyType != "" {
+		return supplyType
+	}
+	return "consumable"
+}
+
+// supplyType deduce el tipo de un consumible ya limpio con cleanSupplyName
+// "maintenance" del diccionario es una caja de residuos; un kit que se
+// consume por páginas es un kit de mantenimiento
+func (b *Builder) supplyType(supply collector.Supply, cleanName string) string {
+	supplyType := b.deduceSupplyType(cleanName)
+	if supplyType == "waste" && supply.Class == collector.SupplyClassConsumed && collector.IsPageUnit(supply.Unit) {
+		return "maintenance"
+	}
+	return supplyType
+}
+
+// fillsUp indica si el consumible se llena con el uso (caja de residuos):
+// su nivel es el espacio libre y un 100% de llenado es un problema, no "ok"
+func (b *Builder) fillsUp(supply collector.Supply, supplyType string) bool {
+	return supply.FillsUp || supplyType == "waste"
+}
+
+// supplyPercentage retorna el porcentaje para JSON: lo que queda, o cuánto
+// está lleno si el consumible se llena con el uso
+func (b *Builder) supplyPercentage(supply collector.Supply, supplyType string) int {
+	if b.fillsUp(supply, supplyType) && supply.LevelState == collector.LevelStateKnown && supply.MaxLevel > 0 {
+		return 100 - supply.Percentage
+	}
+	return supply.Percentage
+}
+
+// supplyStatus retorna el estado de un consumible considerando los centinelas de RFC 3805
+func (b *Builder) supplyStatus(supply collector.Supply, supplyType string) string {
+	switch supply.LevelState {
+	case collector.LevelStateKnown:
+		if b.fillsUp(supply, supplyType) {
+			if supply.MaxLevel <= 0 {
+				return "unknown" // sin capacidad no se sabe cuánto falta para llenarse
+			}
+			return b.deduceFillStatus(b.supplyPercentage(supply, supplyType))
 		}
+		return b.deduceSupplyStatus(supply.Percentage)
+	case collector.LevelStateSomeRemaining:
+		return "ok" // queda al menos una unidad
+	default:
+		return "unknown"
 	}
+}
 
-	return "consumable"
+// supplyLevelState retorna el level_state para JSON (vacío si el nivel es real)
+func (b *Builder) supplyLevelState(supply collector.Supply) string {
+	if supply.LevelState == collector.LevelStateKnown {
+		return ""
+	}
+	return supply.LevelState
 }
 
 // deduceSupplyStatus deduce el estado basado en el porcentaje
@@ -803,47 +850,14 @@ func (b *Builder) deduceSupplyStatus(percentage int) string {
 	return "good"
 }
 
-func (b *Builder) extractFieldAsString(supply interface{}, keys ...string) string {
-	if supplyMap, ok := supply.(map[string]interface{}); ok {
-		for _, key := range keys {
-			if val, ok := supplyMap[key].(string); ok && val != "" {
-				return val
-			}
-		}
-	}
-
-	return ""
-}
-
-func (b *Builder) extractFieldAsInt(supply interface{}, keys ...string) int {
-	if supplyMap, ok := supply.(map[string]interface{}); ok {
-		for _, key := range keys {
-			if intVal, ok := supplyMap[key].(int); ok {
-				return intVal
-			}
-			if int64Val, ok := supplyMap[key].(int64); ok {
-				return int(int64Val)
-			}
-			if floatVal, ok := supplyMap[key].(float64); ok {
-				return int(floatVal)
-			}
-			// IMPORTANTE: Los supplies del collector vienen como strings
-			// Ej: {"level": "50", "max": "100"} después del WALK
-			if strVal, ok := supplyMap[key].(string); ok && strVal != "" {
-				var intResult int
-				if _, err := fmt.Sscanf(strVal, "%d", &intResult); err == nil {
-					return intResult
-				}
-				// Intentar parsearlo como float primero si tiene decimales
-				var floatResult float64
-				if _, err := fmt.Sscanf(strVal, "%f", &floatResult); err == nil {
-					return int(floatResult)
-				}
-			}
-		}
+// deduceFillStatus deduce el estado de un consumible que se llena (caja de residuos)
+func (b *Builder) deduceFillStatus(fill int) string {
+	if fill >= 95 {
+		return "full"
+	} else if fill >= 80 {
+		return "near_full"
 	}
-
-	return 0
+	return "ok"
 }
 
 func (b *Builder) normalizeToID(name string) string {
@@ -856,63 +870,34 @@ func (b *Builder) extractColorCapability(data *collector.PrinterData) bool {
 	// Detectar color capability basado en:
 	// 1. Presencia de supplies de color (cyan, magenta, yellow, color ink)
 	// 2. Color pages counter > 0
-	// 3. Presencia de toner/ink para colores
-
-	// Chequeo 1: Supplies
-	if len(data.Supplies) > 0 {
-		for _, supply := range data.Supplies {
-			// Extraer tipo de supply usando helper function
-			supplyType := b.extractFieldAsString(supply, "type", "description", "name")
-			supplyType = strings.ToLower(supplyType)
-			if strings.Contains(supplyType, "cyan") ||
-				strings.Contains(supplyType, "magenta") ||
-				strings.Contains(supplyType, "yellow") ||
-				strings.Contains(supplyType, "color") {
-				return true
-			}
-		}
-	}
 
-	// Chequeo 2: NormalizedCounters color_pages
-	if data.NormalizedCounters != nil {
-		if colorPages, ok := data.NormalizedCounters["color_pages"]; ok {
-			switch v := colorPages.(type) {
-			case int:
-				if v > 0 {
-					return true
-				}
-			case int64:
-				if v > 0 {
-					return true
-				}
-			case float64:
-				if int64(v) > 0 {
-					return true
-				}
-			}
+	// Chequeo 1: Supplies (el colorante no depende del idioma de la descripción)
+	for _, supply := range data.SupplyList {
+		if supply.Color != "" && supply.Color != "black" {
+			return true
 		}
-	}
-
-	// Chequeo 3: Counters
-	if data.Counters != nil {
-		for key := range data.Counters {
-			if strings.Contains(strings.ToLower(key), "color") {
-				return true
-			}
+		if color := b.supplyDictionary().Color(supply.Description); color != "" && color != "black" {
+			return true
+		}
+		desc := strings.ToLower(supply.Key + " " + supply.Description)
+		if strings.Contains(desc, "cyan") ||
+			strings.Contains(desc, "magenta") ||
+			strings.Contains(desc, "yellow") ||
+			strings.Contains(desc, "color") {
+			return true
 		}
 	}
 
-	return false
+	// Chequeo 2: contador de páginas a color
+	return data.PageCounters.ColorPages > 0
 }
 
 // extractSerialFromDescription extrae el número de serie de una descripción
 // Soporta formatos: "S/N:XXXX", "SN:XXXX", "Serial:XXXX", ";SNXXXX", "S/N: XXXX"
 func (b *Builder) extractSerialFromDescription(desc string) string {
-	descUpper := strings.ToUpper(desc)
-
 	// Formato Xerox: "PN 006R01509;SN99172880E000044B"
-	if idx := strings.Index(descUpper, ";SN"); idx != -1 {
-		serial := desc[idx+3:]
+	if _, end := indexFold(desc, ";SN"); end != -1 {
+		serial := desc[end:]
 		serial = strings.TrimSpace(serial)
 		serial = strings.TrimSuffix(serial, "unknown")
 		serial = strings.TrimSpace(serial)
@@ -923,8 +908,8 @@ func (b *Builder) extractSerialFromDescription(desc string) string {
 
 	// Formato Samsung/ISO: "S/N:CRUM-24030716547"
 	for _, pattern := range []string{"S/N:", "SN:", "Serial:", "serial:"} {
-		if idx := strings.Index(descUpper, strings.ToUpper(pattern)); idx != -1 {
-			serial := desc[idx+len(pattern):]
+		if _, end := indexFold(desc, pattern); end != -1 {
+			serial := desc[end:]
 			serial = strings.TrimSpace(serial)
 			if serial != "" && len(serial) > 2 {
 				return serial
@@ -938,16 +923,14 @@ func (b *Builder) extractSerialFromDescription(desc string) string {
 // extractPartNumberFromDescription extrae el número de parte de una descripción
 // Soporta formatos: "PN 006R01509", "PN: 006R01509", "P/N: 006R01509", "Model: XXXX"
 func (b *Builder) extractPartNumberFromDescription(desc string) string {
-	descUpper := strings.ToUpper(desc)
-
 	// Formato Xerox: "Black Toner, PN 006R01509;SN..."
 	for _, pattern := range []string{"PN ", "PN:", "P/N:", "P/N ", "PartNumber:", "Part Number:"} {
-		if idx := strings.Index(descUpper, strings.ToUpper(pattern)); idx != -1 {
-			partNum := desc[idx+len(pattern):]
+		if _, end := indexFold(desc, pattern); end != -1 {
+			partNum := desc[end:]
 			partNum = strings.TrimSpace(partNum)
 			// Extraer hasta el próximo delimitador
 			for _, delim := range []string{";", ",", " S/N", " SN:", "Serial"} {
-				if delimIdx := strings.Index(strings.ToUpper(partNum), strings.ToUpper(delim)); delimIdx != -1 {
+				if delimIdx, _ := indexFold(partNum, delim); delimIdx != -1 {
 					partNum = partNum[:delimIdx]
 					break
 				}
@@ -959,5 +942,30 @@ func (b *Builder) extractPartNumberFromDescription(desc string) string {
 		}
 	}
 
-	return ""
+	// Formato Sharp/Konica Minolta: "Toner (Black) MX-61NTBA", "Toner (Black) TN514K"
+	if m := vendorPartSuffix.FindStringSubmatch(desc); m != nil {
+		return m[1]
+	}
+
+	// Formato Toshiba/Epson: "Black Toner (T-FC330U-K)"
+	return parenthesizedPartNumber(desc)
+}
+
+// vendorPartSuffix es el número de parte Sharp o Konica Minolta al final de la descripción
+var vendorPartSuffix = regexp.MustCompile(`\s+(MX-\d{2,3}[A-Z]{2,4}|(?:TN|DR|IU|DU|WX)-?\d{3}[A-Z]?)$`)
+
+// parenthesizedPartNumber retorna el contenido del último paréntesis si parece
+// un número de parte (tiene dígitos y no espacios); "" en otro caso
+func parenthesizedPartNumber(desc string) string {
+	open := strings.LastIndex(desc, "(")
+	end := strings.LastIndex(desc, ")")
+	if open == -1 || end <= open+1 {
+		return ""
+	}
+
+	partNum := strings.TrimSpace(desc[open+1 : end])
+	if len(partNum) < 4 || strings.Contains(partNum, " ") || !strings.ContainsAny(partNum, "0123456789") {
+		return ""
+	}
+	return partNum
 }
@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/asaavedra/agent-snmp/pkg/simulator"
+)
+
+// prtMarkerSuppliesDescription es la descripción de los consumibles (Printer-MIB)
+const prtMarkerSuppliesDescription = "1.3.6.1.2.1.43.11.1.1.6."
+
+func FuzzCleanSupplyName(f *testing.F) {
+	for _, name := range simulator.BuiltinNames() {
+		fixture, err := simulator.Builtin(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, v := range fixture.Variables {
+			if strings.HasPrefix(v.OID, prtMarkerSuppliesDescription) && !v.Hex {
+				f.Add(v.Value)
+			}
+		}
+	}
+	f.Add("Black Toner, PN 006R01509;SN99172880E000044B")
+	f.Add("Fuser S/N:                ")
+	f.Add("Toner \xff;sn1234")
+	f.Add("ı;SN")
+
+	b := NewBuilder(AgentSource{})
+	f.Fuzz(func(t *testing.T, desc string) {
+		// prtMarkerSuppliesDescription es SIZE(0..255); entradas enormes solo
+		// hacen lenta la minimización
+		if len(desc) > 255 {
+			t.Skip()
+		}
+		name := b.cleanSupplyName(desc)
+		b.deduceSupplyType(name)
+		b.extractSerialFromDescription(desc)
+		b.extractPartNumberFromDescription(desc)
+
+		if name == "" {
+			return
+		}
+		if len(name) < 3 {
+			t.Errorf("cleanSupplyName(%q) = %q: menos de 3 bytes debería ser vacío", desc, name)
+		}
+		if name != strings.Join(strings.Fields(name), " ") {
+			t.Errorf("cleanSupplyName(%q) = %q: espacios sin normalizar", desc, name)
+		}
+		if utf8.ValidString(desc) && !utf8.ValidString(name) {
+			t.Errorf("cleanSupplyName(%q) = %q: cortó una runa UTF-8", desc, name)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Modos de emisión de telemetrías por sink
+const (
+	EmitFull     = "full"      // snapshot completo en cada poll (default)
+	EmitOnChange = "on_change" // snapshot completo si algo cambió, si no un printer_unchanged
+	EmitDelta    = "delta"     // solo las secciones que cambiaron, o un printer_unchanged
+)
+
+// ValidEmitMode indica si mode es un modo de emisión conocido ("" = full)
+func ValidEmitMode(mode string) bool {
+	switch mode {
+	case "", EmitFull, EmitOnChange, EmitDelta:
+		return true
+	}
+	return false
+}
+
+// Secciones de una telemetría que se comparan entre polls
+const (
+	SectionPrinter      = "printer"
+	SectionStatus       = "status"
+	SectionCounters     = "counters"
+	SectionSupplies     = "supplies"
+	SectionAlerts       = "alerts"
+	SectionCapabilities = "capabilities"
+)
+
+// sectionOrder fija el orden de changed_sections
+var sectionOrder = []string{SectionPrinter, SectionStatus, SectionCounters, SectionSupplies, SectionAlerts, SectionCapabilities}
+
+// Includes indica si la telemetría trae la sección: siempre en un snapshot
+// completo; en un delta solo si está en changed_sections (las demás siguen
+// como en el último envío)
+func (t *Telemetry) Includes(section string) bool {
+	if t.Emission != EmitDelta {
+		return true
+	}
+	for _, s := range t.ChangedSections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprints retorna la huella de cada sección. Se ignora lo que cambia en
+// cada poll sin que cambie el equipo: uptime, deltas de contadores, hora de
+// detección de las alertas, tasa de éxito de OIDs y métricas del poll
+func Fingerprints(t *Telemetry) map[string]string {
+	prints := make(map[string]string, len(sectionOrder))
+	prints[SectionPrinter] = fingerprint(t.Printer)
+
+	if t.Status != nil {
+		status := *t.Status
+		status.SystemUptime, status.SystemUptimeSeconds = "", 0
+		prints[SectionStatus] = fingerprint(status)
+	} else {
+		prints[SectionStatus] = fingerprint(nil)
+	}
+
+	if t.Counters != nil {
+		prints[SectionCounters] = fingerprint(t.Counters.Absolute)
+	} else {
+		prints[SectionCounters] = fingerprint(nil)
+	}
+
+	prints[SectionSupplies] = fingerprint(t.Supplies)
+
+	type alertKey struct{ ID, Severity, Message string }
+	alerts := make([]alertKey, 0, len(t.Alerts))
+	for _, a := range t.Alerts {
+		alerts = append(alerts, alertKey{a.ID, a.Severity, a.Message})
+	}
+	prints[SectionAlerts] = fingerprint(alerts)
+
+	if t.Capabilities != nil {
+		caps := *t.Capabilities
+		caps.OidsSuccessRate = 0
+		prints[SectionCapabilities] = fingerprint(caps)
+	} else {
+		prints[SectionCapabilities] = fingerprint(nil)
+	}
+	return prints
+}
+
+// fingerprint es el SHA-256 (truncado) del JSON de v
+func fingerprint(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// NoChangeEvent es el aviso compacto de un poll en el que nada cambió
+// respecto de lo último enviado al sink: confirma que el equipo sigue vivo
+type NoChangeEvent struct {
+	SchemaVersion string      `json:"schema_version"`
+	EventType     string      `json:"event_type"` // "printer_unchanged"
+	EventID       string      `json:"event_id"`
+	ScanID        string      `json:"scan_id,omitempty"`
+	CollectedAt   time.Time   `json:"collected_at"`
+	Source        AgentSource `json:"source"`
+	PrinterID     string      `json:"printer_id"`
+	IP            string      `json:"ip"`
+	Sequence      uint64      `json:"sequence,omitempty"` // secuencia del poll (la misma que tendría la telemetría)
+	LastEventAt   time.Time   `json:"last_event_at"`      // último envío con datos (completo o delta)
+}
+
+// Emission es lo que corresponde enviar de una telemetría a un sink
+// Exactamente uno de Telemetry y NoChange es distinto de nil
+type Emission struct {
+	Telemetry *Telemetry     // snapshot completo o delta
+	NoChange  *NoChangeEvent // nada cambió
+	Full      bool           // Telemetry es el snapshot completo
+	Sections  map[string]string
+}
+
+// PlanEmission decide qué enviar de t a un sink según su modo, comparando con
+// las huellas de lo último enviado (prev nil = nunca se envió nada)
+// forceFull fuerza el snapshot completo (resincronización periódica)
+func PlanEmission(t *Telemetry, mode string, prev map[string]string, lastEventAt time.Time, forceFull bool) Emission {
+	current := Fingerprints(t)
+	if mode == "" || mode == EmitFull || prev == nil || forceFull {
+		return Emission{Telemetry: t, Full: true, Sections: current}
+	}
+
+	var changed []string
+	for _, section := range sectionOrder {
+		if current[section] != prev[section] {
+			changed = append(changed, section)
+		}
+	}
+
+	if len(changed) == 0 {
+		return Emission{
+			NoChange: &NoChangeEvent{
+				SchemaVersion: "1.0.0",
+				EventType:     "printer_unchanged",
+				EventID:       fmt.Sprintf("%s::unchanged::%s::%d", t.Source.AgentID, t.Printer.ID, t.CollectedAt.Unix()),
+				ScanID:        t.ScanID,
+				CollectedAt:   t.CollectedAt,
+				Source:        t.Source,
+				PrinterID:     t.Printer.ID,
+				IP:            t.Printer.IP,
+				Sequence:      t.Sequence,
+				LastEventAt:   lastEventAt,
+			},
+			Sections: current,
+		}
+	}
+	if mode == EmitOnChange {
+		return Emission{Telemetry: t, Full: true, Sections: current}
+	}
+
+	// Delta: identidad, métricas y solo las secciones que cambiaron
+	delta := *t
+	delta.Emission = EmitDelta
+	delta.ChangedSections = changed
+	if !delta.Includes(SectionStatus) {
+		delta.Status = nil
+	}
+	if !delta.Includes(SectionCounters) {
+		delta.Counters = nil
+	}
+	if !delta.Includes(SectionSupplies) {
+		delta.Supplies = nil
+	}
+	if !delta.Includes(SectionAlerts) {
+		delta.Alerts = nil
+	}
+	if !delta.Includes(SectionCapabilities) {
+		delta.Capabilities = nil
+	}
+	delta.IdempotencyKey = IdempotencyKey(&delta) // otro contenido, otra clave
+	return Emission{Telemetry: &delta, Sections: current}
+}
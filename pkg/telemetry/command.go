@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Estados de un comando remoto
+const (
+	CommandOK       = "ok"
+	CommandFailed   = "error"
+	CommandRejected = "rejected" // tipo desconocido o parámetros inválidos
+)
+
+// CommandResult es el registro de auditoría de un comando remoto ejecutado
+// Viaja por el mismo pipeline que el heartbeat (file sink → HTTP)
+type CommandResult struct {
+	SchemaVersion string      `json:"schema_version"`
+	EventType     string      `json:"event_type"` // "agent_command_result"
+	EventID       string      `json:"event_id"`
+	EmittedAt     time.Time   `json:"emitted_at"`
+	Source        AgentSource `json:"source"`
+
+	CommandID   string            `json:"command_id"`
+	CommandType string            `json:"command_type"`
+	Params      map[string]string `json:"params,omitempty"`
+	Status      string            `json:"status"` // ok | error | rejected
+	Message     string            `json:"message,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	DurationMs  int64             `json:"duration_ms"`
+	Output      json.RawMessage   `json:"output,omitempty"` // debug_walk: fixture grabado
+}
+
+// BuildCommandResult arma el resultado de un comando iniciado en startedAt
+func (b *Builder) BuildCommandResult(commandID, commandType string, params map[string]string, startedAt time.Time, status, message string) *CommandResult {
+	now := time.Now().UTC()
+
+	return &CommandResult{
+		SchemaVersion: "1.0.0",
+		EventType:     "agent_command_result",
+		EventID:       fmt.Sprintf("%s::command::%s", b.source.AgentID, commandID),
+		EmittedAt:     now,
+		Source:        b.source,
+		CommandID:     commandID,
+		CommandType:   commandType,
+		Params:        params,
+		Status:        status,
+		Message:       message,
+		StartedAt:     startedAt.UTC(),
+		DurationMs:    now.Sub(startedAt).Milliseconds(),
+	}
+}
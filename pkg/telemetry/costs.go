@@ -0,0 +1,133 @@
+package telemetry
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"gopkg.in/yaml.v3"
+)
+
+// CostCatalog son los precios y rendimientos de consumibles por número de
+// parte (el que reporta el equipo o el que se extrae de la descripción)
+// Con él se estima el costo por página y el gasto mensual de cada impresora
+type CostCatalog struct {
+	currency string
+	parts    map[string]PartCost // por número de parte normalizado
+}
+
+// PartCost es el precio y el rendimiento de un número de parte
+type PartCost struct {
+	Price float64 `yaml:"price"`
+	Yield int64   `yaml:"yield"` // páginas (0 = el que reporta el equipo o el del catálogo de modelos)
+}
+
+// CostCatalogFile es el formato YAML del catálogo:
+//
+//	currency: USD
+//	parts:
+//	  CF226A: {price: 89.90, yield: 3100}
+//	  006R01509: {price: 120, yield: 26000}
+//	  TN514K: {price: 95}
+type CostCatalogFile struct {
+	Currency string              `yaml:"currency"`
+	Parts    map[string]PartCost `yaml:"parts"`
+}
+
+// daysPerMonth convierte el volumen diario en mensual
+const daysPerMonth = 30
+
+// LoadCostCatalog lee el catálogo de costos
+func LoadCostCatalog(path string) (*CostCatalog, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file CostCatalogFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c := &CostCatalog{currency: strings.ToUpper(strings.TrimSpace(file.Currency)), parts: make(map[string]PartCost, len(file.Parts))}
+	for part, cost := range file.Parts {
+		if cost.Price <= 0 || cost.Yield < 0 {
+			return nil, fmt.Errorf("%s: parts.%s: se espera price > 0 y yield >= 0", path, part)
+		}
+		c.parts[normalizePartNumber(part)] = cost
+	}
+	return c, nil
+}
+
+// Lookup retorna el costo de un número de parte
+func (c *CostCatalog) Lookup(partNumber string) (PartCost, bool) {
+	if c == nil || partNumber == "" {
+		return PartCost{}, false
+	}
+	cost, ok := c.parts[normalizePartNumber(partNumber)]
+	return cost, ok
+}
+
+// normalizePartNumber compara números de parte sin mayúsculas, espacios ni
+// guiones ("MX-61NTBA" = "mx61ntba")
+func normalizePartNumber(part string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(part)))
+}
+
+// supplyCostPerPage retorna el costo por página del consumible (0 si no está
+// en el catálogo o no se conoce su rendimiento)
+func (b *Builder) supplyCostPerPage(partNumber string, supply collector.Supply) float64 {
+	cost, ok := b.costs.Lookup(partNumber)
+	if !ok {
+		return 0
+	}
+	yield := cost.Yield
+	if yield <= 0 {
+		yield = supply.PageCapacity
+	}
+	if yield <= 0 {
+		yield = supply.RatedYield
+	}
+	if yield <= 0 {
+		return 0
+	}
+	return roundCost(cost.Price / float64(yield))
+}
+
+// buildCosts suma el costo por página de los consumibles y lo proyecta al
+// volumen mensual de la impresora. Una página mono usa los consumibles negros
+// y los comunes (drum, fusor, kits); una color, además los de color
+// Retorna nil si ningún consumible tiene costo
+func (b *Builder) buildCosts(supplies []SupplyInfo, usage *collector.PageUsage) *CostInfo {
+	var mono, color float64
+	for _, s := range supplies {
+		switch s.Color {
+		case "", "black":
+			mono += s.CostPerPage
+		default:
+			color += s.CostPerPage
+		}
+	}
+	if mono == 0 && color == 0 {
+		return nil
+	}
+
+	costs := &CostInfo{Currency: b.costs.currency, CostPerPage: roundCost(mono)}
+	if color > 0 {
+		costs.ColorCostPerPage = roundCost(mono + color)
+	}
+	if usage.Known() {
+		costs.MonthlyMonoPages = int64(math.Round(usage.MonoPerDay * daysPerMonth))
+		costs.MonthlyColorPages = int64(math.Round(usage.ColorPerDay * daysPerMonth))
+		// Sin costo de los consumibles de color, una página color cuesta al menos lo que una mono
+		spend := float64(costs.MonthlyMonoPages)*mono + float64(costs.MonthlyColorPages)*(mono+color)
+		costs.MonthlySpend = math.Round(spend*100) / 100
+	}
+	return costs
+}
+
+// roundCost redondea a 6 decimales (fracciones de centavo por página)
+func roundCost(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"fmt"
+	"time"
+)
+
+// Heartbeat es el evento de auto-telemetría del agente
+// Se emite al final de cada ciclo por el mismo pipeline (serializer → sink)
+// para que el backend detecte agentes caídos o mal configurados
+type Heartbeat struct {
+	SchemaVersion string      `json:"schema_version"`
+	EventType     string      `json:"event_type"` // "agent_heartbeat"
+	EventID       string      `json:"event_id"`
+	EmittedAt     time.Time   `json:"emitted_at"`
+	Source        AgentSource `json:"source"`
+
+	StartedAt     time.Time `json:"started_at"`     // inicio del proceso del agente
+	UptimeSeconds int64     `json:"uptime_seconds"` // segundos desde StartedAt
+
+	LastScan     ScanStats   `json:"last_scan"`
+	QueueBacklog int         `json:"queue_backlog"` // eventos pendientes en el file sink (-1 = desconocido)
+	QueueBytes   int64       `json:"queue_bytes"`   // tamaño de la queue incluyendo deadletter/
+	Errors       ErrorCounts `json:"errors"`
+
+	// mode: cloud-sync
+	ConfigVersion string `json:"config_version,omitempty"` // versión de la config remota aplicada
+	ConfigError   string `json:"config_error,omitempty"`   // último documento rechazado y el motivo
+}
+
+// ScanStats resume el último ciclo de escaneo
+type ScanStats struct {
+	ScanID           string    `json:"scan_id,omitempty"` // mismo scan_id de las telemetrías del ciclo
+	StartedAt        time.Time `json:"started_at"`
+	DurationMs       int64     `json:"duration_ms"`
+	DevicesFound     int       `json:"devices_found"`             // respondieron al discovery
+	DevicesNoSNMP    int       `json:"devices_no_snmp,omitempty"` // puertos de impresión abiertos sin SNMP (no recolectados)
+	DevicesCollected int       `json:"devices_collected"`         // con PrinterData emitido
+	DevicesMerged    int       `json:"devices_merged,omitempty"`  // segundas IPs de equipos multi-homed, fusionadas (sin telemetría propia)
+	EventsBuffered   int       `json:"events_buffered"`           // telemetrías escritas en el sink
+	PartialDevices   int       `json:"partial_devices"`           // deadline vencido antes de terminar
+	SlowDevices      int       `json:"slow_devices"`              // lentos o pendientes para el próximo ciclo
+	EventsDropped    int       `json:"events_dropped"`            // descartados por la cuota de la queue
+	Aborted          bool      `json:"aborted,omitempty"`         // interrumpido por una señal de parada: solo lo recolectado hasta ahí
+}
+
+// ErrorCounts cuenta los errores del último ciclo por etapa
+type ErrorCounts struct {
+	Collection int `json:"collection"` // errores reportados por el collector (suma de PrinterData.Errors)
+	Build      int `json:"build"`
+	Serialize  int `json:"serialize"`
+	Sink       int `json:"sink"`
+	State      int `json:"state"`
+}
+
+// Total suma todos los errores
+func (e ErrorCounts) Total() int {
+	return e.Collection + e.Build + e.Serialize + e.Sink + e.State
+}
+
+// BuildHeartbeat arma el heartbeat del agente con las estadísticas del ciclo
+func (b *Builder) BuildHeartbeat(startedAt time.Time, scan ScanStats, backlog int, errors ErrorCounts) *Heartbeat {
+	now := time.Now().UTC()
+
+	return &Heartbeat{
+		SchemaVersion: "1.0.0",
+		EventType:     "agent_heartbeat",
+		EventID:       fmt.Sprintf("%s::heartbeat::%d", b.source.AgentID, now.Unix()),
+		EmittedAt:     now,
+		Source:        b.source,
+		StartedAt:     startedAt.UTC(),
+		UptimeSeconds: int64(now.Sub(startedAt).Seconds()),
+		LastScan:      scan,
+		QueueBacklog:  backlog,
+		Errors:        errors,
+	}
+}
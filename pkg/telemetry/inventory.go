@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+)
+
+// InventoryEvent informa UN cambio de inventario (asset tracking)
+// Viaja por el mismo pipeline que Telemetry (serializer → sink)
+type InventoryEvent struct {
+	SchemaVersion string                    `json:"schema_version"`
+	EventType     string                    `json:"event_type"` // "inventory_change"
+	EventID       string                    `json:"event_id"`
+	ScanID        string                    `json:"scan_id,omitempty"`
+	DetectedAt    time.Time                 `json:"detected_at"`
+	Source        AgentSource               `json:"source"`
+	Change        collector.InventoryChange `json:"change"`
+}
+
+// ScanSummary es el contenido de scan_summary.json: estadísticas del ciclo
+// y las diferencias de inventario respecto del escaneo anterior
+type ScanSummary struct {
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Source      AgentSource                `json:"source"`
+	Scan        ScanStats                  `json:"scan"`
+	Diff        collector.InventoryDiff    `json:"diff"`
+	Sites       []SiteStats                `json:"sites,omitempty"`   // por site (solo con reglas de ubicación)
+	NoSNMP      []collector.InventoryEntry `json:"no_snmp,omitempty"` // equipos vistos sin SNMP: huecos de cobertura
+}
+
+// BuildInventoryEvents arma un evento por cada cambio del diff
+func (b *Builder) BuildInventoryEvents(diff collector.InventoryDiff, detectedAt time.Time) []*InventoryEvent {
+	changes := diff.All()
+	events := make([]*InventoryEvent, 0, len(changes))
+
+	for _, change := range changes {
+		events = append(events, &InventoryEvent{
+			SchemaVersion: "1.0.0",
+			EventType:     "inventory_change",
+			EventID:       fmt.Sprintf("%s::%s::%s::%d", b.source.AgentID, change.Change, inventoryKey(change), detectedAt.Unix()),
+			ScanID:        b.scanID,
+			DetectedAt:    detectedAt.UTC(),
+			Source:        b.source,
+			Change:        change,
+		})
+	}
+
+	return events
+}
+
+// BuildScanSummary arma el resumen del ciclo para scan_summary.json
+func (b *Builder) BuildScanSummary(scan ScanStats, diff collector.InventoryDiff) *ScanSummary {
+	return &ScanSummary{
+		GeneratedAt: time.Now().UTC(),
+		Source:      b.source,
+		Scan:        scan,
+		Diff:        diff,
+	}
+}
+
+// InventoryEventKey es la clave de sink de un evento de inventario
+func InventoryEventKey(event *InventoryEvent) string {
+	return "inventory_" + event.Change.Change + "_" + inventoryKey(event.Change)
+}
+
+// inventoryKey identifica la impresora del cambio (ID actual o anterior)
+func inventoryKey(change collector.InventoryChange) string {
+	entry := change.Current
+	if entry == nil {
+		entry = change.Previous
+	}
+	return strings.ReplaceAll(entry.PrinterID, ":", "")
+}
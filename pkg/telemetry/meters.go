@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+)
+
+// MeterRead es la lectura de contadores de UNA impresora en un instante
+// Es la unidad de facturación: sin deltas, solo valores absolutos
+type MeterRead struct {
+	PrinterID    string                 `json:"printer_id"`
+	SerialNumber string                 `json:"serial_number"`
+	Brand        string                 `json:"brand"`
+	Model        string                 `json:"model"`
+	IP           string                 `json:"ip"`
+	MacAddress   string                 `json:"mac_address"`
+	Hostname     string                 `json:"hostname,omitempty"`
+	Location     string                 `json:"location,omitempty"`
+	Site         *SiteLocation          `json:"site,omitempty"`
+	Tags         Tags                   `json:"tags,omitempty"`
+	ReadAt       time.Time              `json:"read_at"`
+	Counters     collector.CountersInfo `json:"counters"`
+	Partial      bool                   `json:"partial,omitempty"` // deadline vencido: puede faltar algún contador
+	Costs        *CostInfo              `json:"costs,omitempty"`   // costo por página y gasto mensual (ver SetCostCatalog)
+}
+
+// MeterReport es el archivo de cierre de facturación (meters_<período>.json/.csv)
+type MeterReport struct {
+	SchemaVersion string      `json:"schema_version"`
+	ReportType    string      `json:"report_type"` // "meter_reads"
+	Period        string      `json:"period"`      // mes del cierre: "2026-10"
+	GeneratedAt   time.Time   `json:"generated_at"`
+	Source        AgentSource `json:"source"`
+	Reads         []MeterRead `json:"reads"`
+}
+
+// BuildMeterRead arma la lectura de contadores de una impresora
+// Retorna nil si no se recolectó ningún contador (nada que facturar)
+func (b *Builder) BuildMeterRead(data *collector.PrinterData) *MeterRead {
+	if data == nil || !data.HasCounters() {
+		return nil
+	}
+
+	printerID := b.buildPrinterID(data)
+	return &MeterRead{
+		PrinterID:    printerID,
+		SerialNumber: strings.TrimSpace(b.extractSerialNumber(data)),
+		Brand:        strings.TrimSpace(data.Brand),
+		Model:        strings.TrimSpace(b.extractModel(data)),
+		IP:           data.IP,
+		MacAddress:   strings.TrimSpace(b.extractMacAddress(data)),
+		Hostname:     strings.TrimSpace(b.extractHostname(data)),
+		Location:     strings.TrimSpace(b.extractLocation(data)),
+		Site:         b.sites.Resolve(data.IP, b.extractLocation(data)),
+		Tags:         b.tags.Resolve(printerID, data.IP, b.extractSerialNumber(data)),
+		ReadAt:       data.Timestamp.UTC(),
+		Counters:     data.PageCounters,
+		Partial:      data.Partial,
+		Costs:        b.buildCosts(b.buildSupplies(data), data.Usage),
+	}
+}
+
+// BuildMeterReport arma el reporte de cierre con las lecturas del período
+func (b *Builder) BuildMeterReport(period string, reads []MeterRead) *MeterReport {
+	if reads == nil {
+		reads = []MeterRead{}
+	}
+
+	return &MeterReport{
+		SchemaVersion: "1.0.0",
+		ReportType:    "meter_reads",
+		Period:        period,
+		GeneratedAt:   time.Now().UTC(),
+		Source:        b.source,
+		Reads:         reads,
+	}
+}
@@ -11,44 +11,88 @@ import (
 type Telemetry struct {
 	SchemaVersion string      `json:"schema_version"`
 	EventID       string      `json:"event_id"`
+	ScanID        string      `json:"scan_id,omitempty"` // ciclo que produjo el evento (ver Builder.SetScanID)
 	CollectedAt   time.Time   `json:"collected_at"`
+	ClockSkewMs   *int64      `json:"clock_skew_ms,omitempty"` // desfase del reloj del agente vs NTP (collected_at ya corregido)
 	Source        AgentSource `json:"source"`
 	Printer       PrinterInfo `json:"printer"`
+	Status        *StatusInfo `json:"status,omitempty"`
+
+	// Orden e idempotencia: la secuencia crece por impresora; la clave se
+	// repite en cada reenvío del mismo evento (ver IdempotencyKey)
+	Sequence       uint64 `json:"sequence,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 
 	Counters *collector.CountersSnapshot `json:"counters,omitempty"`
 	Supplies []SupplyInfo                `json:"supplies,omitempty"` // nil → null en JSON
 	Alerts   []AlertInfo                 `json:"alerts,omitempty"`   // nil → null en JSON
 
-	Metrics *MetricsInfo `json:"metrics,omitempty"`
+	Capabilities *CapabilitiesInfo `json:"capabilities,omitempty"`
+	Metrics      *MetricsInfo      `json:"metrics,omitempty"`
+	Costs        *CostInfo         `json:"costs,omitempty"` // estimación con el catálogo de costos (nil sin catálogo o sin precios)
+
+	// Datos agregados por hooks del integrador (ver collector.Hook)
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Emisión por cambios (ver PlanEmission): en un "delta" solo vienen las
+	// secciones de changed_sections; omitido en un snapshot completo
+	Emission        string   `json:"emission,omitempty"`
+	ChangedSections []string `json:"changed_sections,omitempty"`
 }
 
 // AgentSource describe quién envía el telemetry
 type AgentSource struct {
-	AgentID  string `json:"agent_id"` // "AGT-CL-001" (asignado por backend)
-	Hostname string `json:"hostname"` // "srv-print-01" (detectado del SO)
-	OS       string `json:"os"`       // "windows", "linux", "darwin"
-	Version  string `json:"version"`  // "1.0.0" (versión del agente)
+	AgentID  string `json:"agent_id"`         // "AGT-CL-001" (asignado por backend)
+	Hostname string `json:"hostname"`         // "srv-print-01" (detectado del SO)
+	OS       string `json:"os"`               // "windows", "linux", "darwin"
+	Version  string `json:"version"`          // "1.0.0" (versión del agente)
+	Tenant   string `json:"tenant,omitempty"` // objetivo de escaneo del agente multi-tenant ("" = único)
 }
 
 // PrinterInfo es la identidad del dispositivo (nunca cambia)
 type PrinterInfo struct {
-	ID              string  `json:"id"`               // "SEC30CDA7C72268-ZDBQBJCH500055B"
-	IP              string  `json:"ip"`               // "192.168.150.35"
-	Brand           string  `json:"brand"`            // "Samsung"
-	BrandConfidence float64 `json:"brand_confidence"` // 0.96
-	Model           *string `json:"model"`            // "Samsung M332x 382x 402x Series" (nil → null en JSON)
-	SerialNumber    *string `json:"serial_number"`    // "ZDBQBJCH500055B" (nil → null en JSON)
-	Hostname        *string `json:"hostname"`         // "SEC30CDA7C72268" (nil → null en JSON)
-	MacAddress      *string `json:"mac_address"`      // "30:cd:a7:c7:22:68" (nil → null en JSON)
+	ID              string  `json:"id"`                      // "SEC30CDA7C72268-ZDBQBJCH500055B"
+	IP              string  `json:"ip"`                      // "192.168.150.35"
+	Brand           string  `json:"brand"`                   // "Samsung"
+	BrandConfidence float64 `json:"brand_confidence"`        // 0.96
+	Model           *string `json:"model"`                   // "Samsung M332x 382x 402x Series" (nil → null en JSON)
+	SerialNumber    *string `json:"serial_number"`           // "ZDBQBJCH500055B" (nil → null en JSON)
+	Hostname        *string `json:"hostname"`                // "SEC30CDA7C72268" (nil → null en JSON)
+	SysName         *string `json:"sys_name"`                // sysName tal cual lo reporta el equipo
+	DNSName         *string `json:"dns_name"`                // "prn-01.corp.local" (PTR; nil si no se consultó o no existe)
+	HostnameSync    string  `json:"hostname_sync,omitempty"` // "match", "mismatch", "snmp_only", "dns_only"
+	MacAddress      *string `json:"mac_address"`             // "30:cd:a7:c7:22:68" (nil → null en JSON)
+
+	// Equipo multi-homed (Ethernet + Wi-Fi) fusionado en una sola impresora:
+	// IPs en que responde e interfaces físicas de su ifTable
+	Addresses  []string                     `json:"addresses,omitempty"`
+	Interfaces []collector.NetworkInterface `json:"interfaces,omitempty"`
+
+	Catalog *CatalogInfo `json:"catalog,omitempty"` // modelo identificado en la base de modelos (nil si no hubo coincidencia)
+
+	Site *SiteLocation `json:"site,omitempty"` // ubicación según las reglas de config.yaml (ver sites.go)
+	Tags Tags          `json:"tags,omitempty"` // metadatos del usuario: {"cost_center": "CC-12"} (ver tags.go)
+}
+
+// CatalogInfo son los datos de catálogo del modelo (ver pkg/modeldb)
+type CatalogInfo struct {
+	Name       string  `json:"name"`          // "HP LaserJet Pro M402" (nombre canónico)
+	PPM        int     `json:"ppm,omitempty"` // 40 (páginas por minuto)
+	Class      string  `json:"class"`         // "mono", "color"
+	Confidence float64 `json:"confidence"`    // 0.95
+	Source     string  `json:"source"`        // "sys_object_id", "name", "sys_object_id+name"
 }
 
 // StatusInfo es el estado actual del dispositivo
 type StatusInfo struct {
-	State               string `json:"state"`                     // "idle", "printing", "error", etc
-	PageCount           int64  `json:"page_count"`                // 14372 (total acumulativo)
-	SystemUptime        string `json:"system_uptime"`             // "41 días, 17 horas, 30 min" (legible para UI)
-	SystemUptimeSeconds int64  `json:"system_uptime_seconds"`     // 3601847 (numérico para cálculos)
-	SystemLocation      string `json:"system_location,omitempty"` // "Oficina Prevención de riesgos" (opcional)
+	State               string   `json:"state"`                     // "idle", "printing", "error", etc
+	PageCount           int64    `json:"page_count"`                // 14372 (total acumulativo)
+	SystemUptime        string   `json:"system_uptime"`             // "41d 17h 30m" (legible para UI)
+	SystemUptimeSeconds int64    `json:"system_uptime_seconds"`     // 3601847 (numérico para cálculos)
+	SystemLocation      string   `json:"system_location,omitempty"` // "Oficina Prevención de riesgos" (opcional)
+	MediaReady          []string `json:"media_ready,omitempty"`     // ["iso_a4_210x297mm"] (IPP, opcional)
+
+	ErrorState *collector.ErrorState `json:"error_state,omitempty"` // hrPrinterDetectedErrorState decodificado (nil si el equipo no lo reporta)
 }
 
 // Nota: CountersInfo, CountersDiff y CountersSnapshot se definen en pkg/collector/data.go
@@ -62,22 +106,32 @@ type StatusInfo struct {
 
 // SupplyInfo describe UN consumible (tóner, drum, fuser, etc)
 type SupplyInfo struct {
-	ID         string `json:"id"`         // "toner_black", "drum_1", "fuser"
-	Name       string `json:"name"`       // "Black Toner Cartridge"
-	Type       string `json:"type"`       // "toner", "drum", "fuser", "waste", "roller"
-	Level      int64  `json:"level"`      // 13950 (unidades crudas)
-	MaxLevel   int64  `json:"max_level"`  // 15000
-	Percentage int    `json:"percentage"` // 93
-	Status     string `json:"status"`     // "ok", "low", "critical", "empty"
+	ID         string `json:"id"`                    // "toner_black", "drum_1", "fuser"
+	Name       string `json:"name"`                  // "Black Toner Cartridge"
+	Type       string `json:"type"`                  // "toner", "drum", "fuser", "waste", "roller", "maintenance"
+	Level      int64  `json:"level"`                 // 13950 (unidades crudas)
+	MaxLevel   int64  `json:"max_level"`             // 15000
+	Percentage int    `json:"percentage"`            // 93 (en los que se llenan, % de llenado: ver FillsUp)
+	Status     string `json:"status"`                // "ok", "low", "critical", "empty", "unknown"; "near_full", "full" si se llena
+	LevelState string `json:"level_state,omitempty"` // "level_unknown" (-2), "some_remaining" (-3), "other" (-1); omitido si el nivel es real
 	// Nuevos campos para información detallada
-	Model         string `json:"model,omitempty"`          // "CRUM-24030716547" - modelo/número de pieza
-	SerialNumber  string `json:"serial_number,omitempty"`  // "3N6DG5XNMK"
-	Brand         string `json:"brand,omitempty"`          // "Samsung", "Canon", "Fujifilm"
-	OEM           string `json:"oem,omitempty"`            // OEM info si está disponible
-	Description   string `json:"description,omitempty"`    // Descripción completa del SNMP
-	ComponentType string `json:"component_type,omitempty"` // "imaging_unit", "transfer_roller", "fuser_film"
-	PageCapacity  int64  `json:"page_capacity,omitempty"`  // Capacidad en páginas
-	PartNumber    string `json:"part_number,omitempty"`    // Número de parte alternativo
+	Model         string  `json:"model,omitempty"`          // "CRUM-24030716547" - modelo/número de pieza
+	SerialNumber  string  `json:"serial_number,omitempty"`  // "3N6DG5XNMK"
+	Brand         string  `json:"brand,omitempty"`          // "Samsung", "Canon", "Fujifilm"
+	OEM           string  `json:"oem,omitempty"`            // OEM info si está disponible
+	Description   string  `json:"description,omitempty"`    // Descripción completa del SNMP
+	ComponentType string  `json:"component_type,omitempty"` // "imaging_unit", "transfer_roller", "fuser_film"
+	Color         string  `json:"color,omitempty"`          // "black", "cyan", "magenta", "yellow" (prtMarkerColorantValue)
+	PageCapacity  int64   `json:"page_capacity,omitempty"`  // Capacidad en páginas
+	RatedYield    int64   `json:"rated_yield,omitempty"`    // 9000 - rendimiento nominal según el catálogo del modelo
+	CostPerPage   float64 `json:"cost_per_page,omitempty"`  // 0.029 - precio / rendimiento según el catálogo de costos
+	PartNumber    string  `json:"part_number,omitempty"`    // Número de parte alternativo
+	// Vida útil y reemplazo
+	Class          string `json:"class,omitempty"`           // "consumed", "receptacle" (la caja de residuos informa espacio libre)
+	Unit           string `json:"unit,omitempty"`            // "impressions", "sheets", "percent"... (unidad de level/max_level)
+	RemainingPages int64  `json:"remaining_pages,omitempty"` // 42000 - páginas de vida restantes (drum, fusor, kits)
+	ReplaceBy      string `json:"replace_by,omitempty"`      // "2026-03-14" - fecha estimada de reemplazo según el consumo
+	FillsUp        bool   `json:"fills_up,omitempty"`        // true en cajas de residuos: level es el espacio libre, percentage el llenado
 }
 
 // AlertInfo describe UNA alerta activa en el dispositivo
@@ -96,10 +150,22 @@ type CapabilitiesInfo struct {
 	Color           bool     `json:"color"`             // true
 	Scanner         bool     `json:"scanner"`           // true
 	Fax             bool     `json:"fax"`               // false
-	OidsSupported   []string `json:"oids_supported"`    // ["1.3.6.1.2.1.1.1.0", ...]
+	OidsSupported   []string `json:"oids_supported"`    // ["1.3.6.1.2.1.43.11", ...] subárboles que respondieron
 	OidsSuccessRate float64  `json:"oids_success_rate"` // 0.95
 }
 
+// CostInfo es el costo de consumibles estimado de una impresora
+// Como las métricas, no forma parte de ninguna sección de PlanEmission: el
+// volumen mensual cambia en cada poll sin que cambie el equipo
+type CostInfo struct {
+	Currency          string  `json:"currency,omitempty"`            // "USD" (la del catálogo)
+	CostPerPage       float64 `json:"cost_per_page"`                 // 0.031 - página mono: consumibles negros y comunes (drum, fusor, kits)
+	ColorCostPerPage  float64 `json:"color_cost_per_page,omitempty"` // 0.094 - página color: además los consumibles de color
+	MonthlyMonoPages  int64   `json:"monthly_mono_pages,omitempty"`  // 2400 - volumen medio (ver collector.TrackUsage)
+	MonthlyColorPages int64   `json:"monthly_color_pages,omitempty"` // 600
+	MonthlySpend      float64 `json:"monthly_spend,omitempty"`       // 130.8 - gasto mensual proyectado en consumibles
+}
+
 // MetricsInfo agrupa las métricas del poll SNMP
 type MetricsInfo struct {
 	Polling *PollingMetrics `json:"polling,omitempty"`
@@ -0,0 +1,25 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyKey deriva la clave de idempotencia de t a partir de la
+// impresora, collected_at y el hash de la lectura. Sequence y EventID no
+// entran: la misma lectura armada de nuevo (ej: reproceso de problems/) repite
+// la clave y el backend puede descartarla; dos lecturas distintas nunca la
+// comparten
+func IdempotencyKey(t *Telemetry) string {
+	content := *t
+	content.Sequence = 0
+	content.EventID = ""
+	content.IdempotencyKey = ""
+	data, _ := json.Marshal(content)
+	contentSum := sha256.Sum256(data)
+
+	sum := sha256.Sum256([]byte(t.Printer.ID + "\n" + t.CollectedAt.UTC().Format(time.RFC3339Nano) + "\n" + hex.EncodeToString(contentSum[:])))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,34 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeyIgnoresSequenceAndEventID(t *testing.T) {
+	reading := func() *Telemetry {
+		return &Telemetry{
+			CollectedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			Printer:     PrinterInfo{ID: "SEC30CDA7C72268", IP: "10.0.0.5", Brand: "HP"},
+			Supplies:    []SupplyInfo{{ID: "toner_black", Type: "toner", Percentage: 12}},
+		}
+	}
+
+	first := reading()
+	first.Sequence, first.EventID = 7, "AGT::10.0.0.5::1"
+	key := IdempotencyKey(first)
+
+	// La misma lectura armada de nuevo (otra secuencia, otro event_id)
+	rebuilt := reading()
+	rebuilt.Sequence, rebuilt.EventID = 8, "AGT::10.0.0.5::2"
+	rebuilt.IdempotencyKey = key
+	if got := IdempotencyKey(rebuilt); got != key {
+		t.Errorf("la misma lectura dio otra clave: %s vs %s", got, key)
+	}
+
+	changed := reading()
+	changed.Supplies[0].Percentage = 11
+	if IdempotencyKey(changed) == key {
+		t.Error("dos lecturas distintas comparten la clave")
+	}
+}
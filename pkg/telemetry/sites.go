@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Jerarquía de ubicación (site → building → floor → room)
+//
+// sysLocation es texto libre ("Of. Prevención 2do piso") y no sirve para
+// agrupar: config.yaml define reglas por subred o por patrón de sysLocation
+// y la ubicación resuelta viaja en PrinterInfo.Site
+
+// SiteLocation es la ubicación resuelta de una impresora
+type SiteLocation struct {
+	Site     string `json:"site"`
+	Building string `json:"building,omitempty"`
+	Floor    string `json:"floor,omitempty"`
+	Room     string `json:"room,omitempty"`
+}
+
+// SiteRule asigna una ubicación a las impresoras que cumplen Subnet y/o Location
+// Location es una regex (sin distinguir mayúsculas) sobre sysLocation; sus grupos
+// con nombre site/building/floor/room completan los campos vacíos de la regla:
+// "^(?P<building>[A-Z]+)-P(?P<floor>\d+)" con "B-P3" da building B, floor 3
+type SiteRule struct {
+	Subnet   string // CIDR ("10.1.0.0/16"); "" = cualquier IP
+	Location string // regex sobre sysLocation; "" = cualquier sysLocation
+	SiteLocation
+}
+
+// SiteResolver aplica las reglas en orden: gana la primera que coincide
+type SiteResolver struct {
+	rules []siteRule
+}
+
+// siteRule es una SiteRule compilada
+type siteRule struct {
+	subnet   *net.IPNet
+	location *regexp.Regexp
+	site     SiteLocation
+}
+
+// NewSiteResolver compila las reglas
+// Una regla sin Subnet ni Location no filtra nada y se rechaza
+func NewSiteResolver(rules []SiteRule) (*SiteResolver, error) {
+	resolver := &SiteResolver{}
+	for i, rule := range rules {
+		if rule.Subnet == "" && rule.Location == "" {
+			return nil, fmt.Errorf("regla %d: requiere subnet o location", i+1)
+		}
+
+		compiled := siteRule{site: rule.SiteLocation}
+		if rule.Subnet != "" {
+			_, subnet, err := net.ParseCIDR(rule.Subnet)
+			if err != nil {
+				return nil, fmt.Errorf("regla %d: subnet %q: %w", i+1, rule.Subnet, err)
+			}
+			compiled.subnet = subnet
+		}
+		if rule.Location != "" {
+			re, err := regexp.Compile("(?i)" + rule.Location)
+			if err != nil {
+				return nil, fmt.Errorf("regla %d: location %q: %w", i+1, rule.Location, err)
+			}
+			compiled.location = re
+		}
+		resolver.rules = append(resolver.rules, compiled)
+	}
+	return resolver, nil
+}
+
+// Resolve retorna la ubicación de una impresora (nil si ninguna regla aplica)
+func (r *SiteResolver) Resolve(ip, sysLocation string) *SiteLocation {
+	if r == nil {
+		return nil
+	}
+	addr := net.ParseIP(ip)
+	sysLocation = strings.TrimSpace(sysLocation)
+
+	for _, rule := range r.rules {
+		if rule.subnet != nil && (addr == nil || !rule.subnet.Contains(addr)) {
+			continue
+		}
+		site := rule.site
+		if rule.location != nil {
+			match := rule.location.FindStringSubmatch(sysLocation)
+			if match == nil {
+				continue
+			}
+			for i, name := range rule.location.SubexpNames() {
+				fillSiteField(&site, name, strings.TrimSpace(match[i]))
+			}
+		}
+		if site.Site == "" {
+			continue // la regla no alcanzó a nombrar el site
+		}
+		return &site
+	}
+	return nil
+}
+
+// fillSiteField completa un campo vacío con un grupo de la regex
+func fillSiteField(site *SiteLocation, field, value string) {
+	if value == "" {
+		return
+	}
+	var target *string
+	switch field {
+	case "site":
+		target = &site.Site
+	case "building":
+		target = &site.Building
+	case "floor":
+		target = &site.Floor
+	case "room":
+		target = &site.Room
+	default:
+		return
+	}
+	if *target == "" {
+		*target = value
+	}
+}
+
+// SiteStats agrega las impresoras de un site en el ciclo (scan_summary.json)
+type SiteStats struct {
+	Site       string `json:"site"` // "" = sin site asignado
+	Printers   int    `json:"printers"`
+	Offline    int    `json:"offline"`
+	Partial    int    `json:"partial"` // deadline vencido antes de terminar
+	Alerts     int    `json:"alerts"`
+	TotalPages int64  `json:"total_pages"` // suma de contadores absolutos
+	DeltaPages int64  `json:"delta_pages"` // páginas impresas desde el poll anterior
+}
+
+// SiteTally acumula SiteStats durante un ciclo
+type SiteTally map[string]*SiteStats
+
+// Add suma una telemetría al site de su impresora
+func (t SiteTally) Add(telem *Telemetry, partial bool) {
+	site := ""
+	if telem.Printer.Site != nil {
+		site = telem.Printer.Site.Site
+	}
+	stats, ok := t[site]
+	if !ok {
+		stats = &SiteStats{Site: site}
+		t[site] = stats
+	}
+
+	stats.Printers++
+	if telem.Status != nil && telem.Status.State == "offline" {
+		stats.Offline++
+	}
+	if partial {
+		stats.Partial++
+	}
+	stats.Alerts += len(telem.Alerts)
+	if telem.Counters != nil {
+		stats.TotalPages += telem.Counters.Absolute.TotalPages
+		if telem.Counters.Delta != nil {
+			stats.DeltaPages += telem.Counters.Delta.TotalPages
+		}
+	}
+}
+
+// Stats retorna los sites ordenados por nombre (nil si no hay ninguno asignado)
+func (t SiteTally) Stats() []SiteStats {
+	if len(t) == 0 || (len(t) == 1 && t[""] != nil) {
+		return nil
+	}
+	stats := make([]SiteStats, 0, len(t))
+	for _, s := range t {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Site < stats[j].Site })
+	return stats
+}
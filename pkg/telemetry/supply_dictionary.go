@@ -0,0 +1,174 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SupplyDictionary reconoce tipos y colores de consumibles en descripciones
+// de firmware en varios idiomas (inglés, español, portugués, francés, alemán,
+// japonés) y las etiquetas de serie/número de parte que cleanSupplyName corta
+// Complementa al colorante de prtMarkerColorantTable: sirve cuando el equipo
+// no lo publica y para el tipo, que la descripción es lo único que lo nombra
+type SupplyDictionary struct {
+	types      []dictionaryEntry // en orden de prioridad ("waste toner" es waste, no toner)
+	colors     []dictionaryEntry
+	separators []string
+}
+
+// dictionaryEntry son las palabras clave de un tipo o color
+type dictionaryEntry struct {
+	value    string
+	keywords []string // en minúsculas
+}
+
+// SupplyDictionaryFile es el formato YAML con palabras clave adicionales:
+//
+//	types:
+//	  toner: [tonalizador]
+//	  staples: [cucitrice, nietjes]
+//	colors:
+//	  black: [zwart]
+//	separators: ["Nr. seryjny"]
+//
+// Las palabras se suman a las incorporadas; un tipo nuevo se evalúa después de
+// los incorporados
+type SupplyDictionaryFile struct {
+	Types      map[string][]string `yaml:"types"`
+	Colors     map[string][]string `yaml:"colors"`
+	Separators []string            `yaml:"separators"`
+}
+
+// defaultSupplyDictionary es el diccionario que usa un Builder sin SetSupplyDictionary
+var defaultSupplyDictionary = DefaultSupplyDictionary()
+
+// DefaultSupplyDictionary retorna el diccionario incorporado
+func DefaultSupplyDictionary() *SupplyDictionary {
+	d := &SupplyDictionary{}
+	// Prioridad: los compuestos primero (residuos de tóner, rodillo de transferencia,
+	// cartucho de drum) y los genéricos al final
+	d.addType("waste", "waste", "residuo", "resíduo", "recuperador", "déchet", "récupérateur", "resttoner", "abfall",
+		"maintenance", "mantenimiento", "manutenção", "wartung", "廃トナー", "廃インク", "メンテナンス")
+	d.addType("staples", "staple", "grapa", "grampo", "agrafe", "heftklammer", "ステープル")
+	d.addType("fuser", "fuser", "fusor", "fusão", "fusion", "fixiereinheit", "fixier", "定着")
+	d.addType("drum", "drum", "cilindro", "tambor", "tambour", "trommel", "photoconduct", "fotocondut",
+		"opc", "ドラム", "感光体")
+	d.addType("developer", "developer", "revelador", "développeur", "entwickler", "現像")
+	d.addType("transfer", "transfer", "transferencia", "transferência", "transfert", "übertragung", "転写")
+	d.addType("pickup", "pickup", "retirada", "alimentación", "alimentação", "prise papier", "einzug", "給紙")
+	d.addType("roller", "roller", "rodillo", "rolo", "rouleau", "walze", "rolle", "ローラー")
+	d.addType("ink", "ink", "tinta", "encre", "tinte", "インク")
+	d.addType("toner", "toner", "tóner", "トナー")
+	d.addType("cartridge", "cartridge", "cartucho", "cartouche", "kartusche", "patrone", "カートリッジ")
+
+	d.addColor("black", "black", "negro", "preto", "noir", "schwarz", "ブラック", "黒")
+	d.addColor("cyan", "cyan", "cian", "ciano", "シアン")
+	d.addColor("magenta", "magenta", "マゼンタ")
+	d.addColor("yellow", "yellow", "amarillo", "amarelo", "jaune", "gelb", "イエロー", "黄")
+
+	// Se buscan en este orden; "PN " antes que "PN:" por el formato de Xerox
+	d.separators = []string{
+		"Serial", "Part Number", "PN ", "PN:", "PN=", "P/N:", "P/N ", "Model:", "Version:",
+		"Número de serie", "Número de série", "Nº de série", "N° de série", "Numéro de série", "N/S:",
+		"Seriennummer", "Serien-Nr", "シリアル番号", "製造番号",
+		"Número de parte", "Número de pieza", "Nº de peça", "Référence:", "Teilenummer", "部品番号",
+		"Modelo:", "Modèle:", "Modell:", "型番",
+	}
+	return d
+}
+
+// LoadSupplyDictionary retorna el diccionario incorporado más las palabras del archivo
+func LoadSupplyDictionary(path string) (*SupplyDictionary, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file SupplyDictionaryFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	d := DefaultSupplyDictionary()
+	for _, name := range sortedKeys(file.Types) {
+		d.addType(name, file.Types[name]...)
+	}
+	for _, name := range sortedKeys(file.Colors) {
+		d.addColor(name, file.Colors[name]...)
+	}
+	for _, sep := range file.Separators {
+		if strings.TrimSpace(sep) != "" {
+			d.separators = append(d.separators, sep)
+		}
+	}
+	return d, nil
+}
+
+// Type retorna el tipo de consumible que nombra la descripción ("" si ninguno)
+func (d *SupplyDictionary) Type(name string) string {
+	return matchKeyword(d.types, name)
+}
+
+// Color retorna el color que nombra la descripción ("" si ninguno)
+func (d *SupplyDictionary) Color(name string) string {
+	return matchKeyword(d.colors, name)
+}
+
+// Separators son las etiquetas a partir de las cuales cleanSupplyName corta el nombre
+func (d *SupplyDictionary) Separators() []string {
+	return d.separators
+}
+
+func (d *SupplyDictionary) addType(value string, keywords ...string) {
+	d.types = addEntry(d.types, value, keywords)
+}
+
+func (d *SupplyDictionary) addColor(value string, keywords ...string) {
+	d.colors = addEntry(d.colors, value, keywords)
+}
+
+// addEntry suma palabras a la entrada existente o agrega una al final
+func addEntry(entries []dictionaryEntry, value string, keywords []string) []dictionaryEntry {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return entries
+	}
+	var lower []string
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			lower = append(lower, k)
+		}
+	}
+	for i := range entries {
+		if entries[i].value == value {
+			entries[i].keywords = append(entries[i].keywords, lower...)
+			return entries
+		}
+	}
+	return append(entries, dictionaryEntry{value: value, keywords: lower})
+}
+
+// matchKeyword retorna la primera entrada con alguna palabra contenida en name
+func matchKeyword(entries []dictionaryEntry, name string) string {
+	lower := strings.ToLower(name)
+	for _, entry := range entries {
+		for _, keyword := range entry.keywords {
+			if strings.Contains(lower, keyword) {
+				return entry.value
+			}
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
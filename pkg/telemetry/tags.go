@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"net"
+	"strings"
+)
+
+// Tags son metadatos libres del usuario (centro de costo, cliente, contrato)
+// que viajan en cada evento para filtrar aguas abajo
+type Tags map[string]string
+
+// TagRule asigna tags a las impresoras con cierta IP, subred o número de serie
+type TagRule struct {
+	IP     string // IP exacta o CIDR ("10.1.2.0/24"); "" = cualquiera
+	Serial string // número de serie (sin distinguir mayúsculas); "" = cualquiera
+	Tags   Tags
+}
+
+// TagResolver combina las reglas de config.yaml con las asignaciones hechas por
+// la API (guardadas en state/ por ID de impresora)
+// Orden: reglas de config en orden, luego la asignación por API; lo último gana
+type TagResolver struct {
+	rules    []TagRule
+	assigned map[string]Tags
+}
+
+// NewTagResolver crea el resolver; assigned puede ser nil
+func NewTagResolver(rules []TagRule, assigned map[string]Tags) *TagResolver {
+	return &TagResolver{rules: rules, assigned: assigned}
+}
+
+// Resolve retorna los tags de una impresora (nil si no tiene)
+func (r *TagResolver) Resolve(printerID, ip, serial string) Tags {
+	if r == nil {
+		return nil
+	}
+
+	var tags Tags
+	merge := func(src Tags) {
+		for k, v := range src {
+			if tags == nil {
+				tags = make(Tags)
+			}
+			tags[k] = v
+		}
+	}
+
+	for _, rule := range r.rules {
+		if rule.IP != "" && !matchIP(rule.IP, ip) {
+			continue
+		}
+		if rule.Serial != "" && !strings.EqualFold(strings.TrimSpace(rule.Serial), strings.TrimSpace(serial)) {
+			continue
+		}
+		merge(rule.Tags)
+	}
+	merge(r.assigned[printerID])
+	return tags
+}
+
+// matchIP compara una IP contra una IP exacta o un CIDR
+func matchIP(pattern, ip string) bool {
+	if !strings.Contains(pattern, "/") {
+		return pattern == ip
+	}
+	_, subnet, err := net.ParseCIDR(pattern)
+	addr := net.ParseIP(ip)
+	return err == nil && addr != nil && subnet.Contains(addr)
+}
@@ -0,0 +1,381 @@
+// Dashboard de printsnmp: consume la API REST del agente (/api/*)
+// Sin dependencias ni build: se embebe tal cual en el binario
+"use strict";
+
+const REFRESH_MS = 30000;
+const LIVE_DEBOUNCE_MS = 1000;
+
+const TEXTS = {
+  es: {
+    printers: "Impresoras", critical: "Alertas críticas", warning: "Advertencias", last_scan: "Último escaneo",
+    fleet: "Flota", heatmap: "Consumibles", alerts: "Alertas", supplies: "Consumibles", counters: "Contadores",
+    ip: "IP", brand: "Marca", model: "Modelo", location: "Ubicación", state: "Estado", pages: "Páginas",
+    collected: "Recolectado", severity: "Severidad", message: "Mensaje", detected: "Detectada",
+    serial: "Serie", mac: "MAC", hostname: "Hostname", uptime: "Uptime", updated: "Actualizado",
+    no_data: "Sin datos todavía: esperando el primer ciclo", no_alerts: "Sin alertas activas",
+    never: "nunca", agent: "Agente", live: "● en vivo", offline: "○ sin conexión",
+    login: "Acceso", login_hint: "API key o token de acceso", login_button: "Entrar",
+    scan_now: "Escanear ahora", scan_started: "Escaneo en curso", scan_busy: "Ya hay un escaneo en curso",
+    scan_wait: "Espere para volver a escanear",
+    discovery: "Descubrimiento", collection: "Recolección", eta: "ETA",
+    collect_now: "Consultar ahora", collecting: "Consultando…", collect_failed: "Sin respuesta",
+  },
+  en: {
+    printers: "Printers", critical: "Critical alerts", warning: "Warnings", last_scan: "Last scan",
+    fleet: "Fleet", heatmap: "Supplies", alerts: "Alerts", supplies: "Supplies", counters: "Counters",
+    ip: "IP", brand: "Brand", model: "Model", location: "Location", state: "State", pages: "Pages",
+    collected: "Collected", severity: "Severity", message: "Message", detected: "Detected",
+    serial: "Serial", mac: "MAC", hostname: "Hostname", uptime: "Uptime", updated: "Updated",
+    no_data: "No data yet: waiting for the first cycle", no_alerts: "No active alerts",
+    never: "never", agent: "Agent", live: "● live", offline: "○ offline",
+    login: "Sign in", login_hint: "API key or access token", login_button: "Sign in",
+    scan_now: "Scan now", scan_started: "Scan in progress", scan_busy: "A scan is already running",
+    scan_wait: "Wait before scanning again",
+    discovery: "Discovery", collection: "Collection", eta: "ETA",
+    collect_now: "Poll now", collecting: "Polling…", collect_failed: "No response",
+  },
+};
+
+let texts = TEXTS.es;
+let selected = null;
+let live = false;
+let pending = null;
+let source = null;
+
+// La credencial queda en el navegador: la API la recibe como Bearer
+const TOKEN_KEY = "printsnmp.token";
+
+function token() {
+  return localStorage.getItem(TOKEN_KEY) || "";
+}
+
+function t(key) {
+  return texts[key] || key;
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k === "class") node.className = v;
+    else if (k === "style") node.style.cssText = v;
+    else if (k.startsWith("on")) node.addEventListener(k.slice(2), v);
+    else node.setAttribute(k, v);
+  }
+  for (const child of children) {
+    if (child === null || child === undefined) continue;
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+class Unauthorized extends Error {}
+
+async function api(path, options = {}) {
+  const headers = { Accept: "application/json" };
+  if (token()) headers.Authorization = "Bearer " + token();
+  const res = await fetch(path, { ...options, headers });
+  if (res.status === 401) {
+    showLogin();
+    throw new Unauthorized(path);
+  }
+  if (!res.ok) {
+    const err = new Error(path + ": " + res.status);
+    err.status = res.status;
+    throw err;
+  }
+  return res.json();
+}
+
+function showLogin() {
+  document.getElementById("login").hidden = false;
+  setLive(false);
+  if (source) {
+    source.close();
+    source = null;
+  }
+}
+
+function formatTime(value) {
+  if (!value || value.startsWith("0001")) return t("never");
+  return new Date(value).toLocaleString();
+}
+
+// levelColor va de rojo (0%) a verde (100%); gris si el nivel es desconocido
+function levelColor(supply) {
+  if (supply.level_state || supply.percentage < 0) return "var(--unknown)";
+  const hue = Math.round(Math.min(100, Math.max(0, supply.percentage)) * 1.2);
+  return `hsl(${hue}, 65%, 42%)`;
+}
+
+function applyTexts() {
+  document.querySelectorAll("[data-i18n]").forEach((node) => {
+    node.textContent = t(node.dataset.i18n);
+  });
+  document.querySelectorAll("[data-i18n-placeholder]").forEach((node) => {
+    node.placeholder = t(node.dataset.i18nPlaceholder);
+  });
+}
+
+function emptyRow(colspan, text) {
+  return el("tr", { class: "empty-row" }, el("td", { colspan }, text));
+}
+
+function renderStatus(status) {
+  texts = TEXTS[status.locale] || TEXTS.es;
+  document.documentElement.lang = status.locale;
+  applyTexts();
+
+  document.getElementById("agent").textContent = `${t("agent")} ${status.source.agent_id} · ${status.source.hostname}`;
+  document.getElementById("updated").textContent = `${t("updated")} ${formatTime(status.generated_at)}`;
+  document.getElementById("card-printers").textContent = status.printers;
+  document.getElementById("card-critical").textContent = status.alerts.critical || 0;
+  document.getElementById("card-warning").textContent = status.alerts.warning || 0;
+  document.getElementById("card-scan").textContent = status.last_scan
+    ? new Date(status.last_scan.scan.started_at).toLocaleTimeString()
+    : t("never");
+  if (status.progress) renderProgress(status.progress);
+}
+
+// renderProgress muestra el avance del ciclo en curso en la tarjeta del escaneo
+function renderProgress(progress) {
+  if (progress.done) return;
+  const percent = progress.total ? Math.floor((progress.scanned * 100) / progress.total) : 100;
+  const eta = progress.eta_ms ? ` · ${t("eta")} ${Math.ceil(progress.eta_ms / 1000)}s` : "";
+  document.getElementById("card-scan").textContent = `${t(progress.phase)} ${percent}%${eta}`;
+}
+
+function renderFleet(printers) {
+  const body = document.querySelector("#fleet tbody");
+  body.replaceChildren();
+  if (printers.length === 0) {
+    body.append(emptyRow(8, t("no_data")));
+    return;
+  }
+
+  for (const p of printers) {
+    const alerts = p.alert_count
+      ? el("span", { class: "badge " + (p.critical ? "critical" : "warning") }, p.alert_count)
+      : "";
+    body.append(el("tr", { onclick: () => showDetail(p.id) },
+      el("td", {}, p.ip),
+      el("td", {}, p.brand),
+      el("td", {}, p.model || "–"),
+      el("td", {}, p.location || "–"),
+      el("td", {}, el("span", { class: "badge " + p.state }, p.state)),
+      el("td", {}, p.page_count.toLocaleString()),
+      el("td", {}, alerts),
+      el("td", {}, formatTime(p.collected_at)),
+    ));
+  }
+}
+
+function renderHeatmap(printers) {
+  const ids = [];
+  for (const p of printers) {
+    for (const s of p.supplies) {
+      if (!ids.includes(s.id)) ids.push(s.id);
+    }
+  }
+  ids.sort();
+
+  const head = document.querySelector("#heatmap thead");
+  const body = document.querySelector("#heatmap tbody");
+  head.replaceChildren(el("tr", {}, el("th", {}, t("ip")), el("th", {}, t("model")), ...ids.map((id) => el("th", {}, id))));
+  body.replaceChildren();
+  if (printers.length === 0) {
+    body.append(emptyRow(2, t("no_data")));
+    return;
+  }
+
+  for (const p of printers) {
+    const byID = Object.fromEntries(p.supplies.map((s) => [s.id, s]));
+    const cells = ids.map((id) => {
+      const s = byID[id];
+      if (!s) return el("td", { class: "cell none" }, "·");
+      const label = s.level_state ? "?" : s.percentage + "%";
+      return el("td", { class: "cell", style: `background:${levelColor(s)}`, title: s.name }, label);
+    });
+    body.append(el("tr", { onclick: () => showDetail(p.id) },
+      el("td", {}, p.ip),
+      el("td", {}, p.model || p.brand),
+      ...cells,
+    ));
+  }
+}
+
+function renderAlerts(alerts) {
+  const body = document.querySelector("#alerts tbody");
+  body.replaceChildren();
+  if (alerts.length === 0) {
+    body.append(emptyRow(5, t("no_alerts")));
+    return;
+  }
+
+  for (const a of alerts) {
+    body.append(el("tr", { onclick: () => showDetail(a.printer_id) },
+      el("td", {}, el("span", { class: "badge " + a.severity }, a.severity)),
+      el("td", {}, a.ip),
+      el("td", {}, a.model || a.brand),
+      el("td", {}, a.message),
+      el("td", {}, formatTime(a.detected_at)),
+    ));
+  }
+}
+
+function definitionList(node, pairs) {
+  node.replaceChildren();
+  for (const [k, v] of pairs) {
+    if (v === null || v === undefined || v === "") continue;
+    node.append(el("dt", {}, k), el("dd", {}, v));
+  }
+}
+
+async function showDetail(id, scroll = true) {
+  selected = id;
+  let tel;
+  try {
+    tel = await api("/api/printers/" + encodeURIComponent(id));
+  } catch (err) {
+    console.error(err);
+    return;
+  }
+
+  const p = tel.printer;
+  const status = tel.status || {};
+  document.getElementById("detail-title").textContent = `${p.model || p.brand} · ${p.ip}`;
+  definitionList(document.getElementById("detail-info"), [
+    [t("brand"), p.brand],
+    [t("serial"), p.serial_number],
+    [t("hostname"), p.hostname],
+    [t("mac"), p.mac_address],
+    [t("location"), status.system_location],
+    [t("state"), status.state],
+    [t("uptime"), status.system_uptime],
+    [t("collected"), formatTime(tel.collected_at)],
+  ]);
+
+  const supplies = document.getElementById("detail-supplies");
+  supplies.replaceChildren(...(tel.supplies || []).map((s) => el("div", { class: "bar" },
+    el("span", { title: s.description || "" }, s.name),
+    el("div", { class: "track" }, el("div", { class: "fill", style: `width:${Math.max(0, s.percentage)}%;background:${levelColor(s)}` })),
+    el("span", {}, s.level_state ? "?" : s.percentage + "%"),
+  )));
+
+  const absolute = (tel.counters && tel.counters.absolute) || {};
+  definitionList(document.getElementById("detail-counters"),
+    Object.entries(absolute).filter(([, v]) => v).map(([k, v]) => [k, v.toLocaleString()]));
+
+  const alerts = document.getElementById("detail-alerts");
+  alerts.replaceChildren(...(tel.alerts || []).map((a) => el("li", {},
+    el("span", { class: "badge " + a.severity }, a.severity), " ", a.message)));
+  if (!tel.alerts || tel.alerts.length === 0) alerts.append(el("li", {}, t("no_alerts")));
+
+  const detail = document.getElementById("detail");
+  detail.hidden = false;
+  if (scroll) detail.scrollIntoView({ behavior: "smooth" });
+}
+
+function renderUser(user) {
+  document.getElementById("user").textContent = `${user.name} (${user.role})`;
+  document.getElementById("scan-now").hidden = user.role !== "admin";
+}
+
+async function scanNow() {
+  const button = document.getElementById("scan-now");
+  try {
+    await api("/api/scan", { method: "POST" });
+    button.textContent = t("scan_started");
+  } catch (err) {
+    button.textContent = err.status === 409 ? t("scan_busy") : err.status === 429 ? t("scan_wait") : t("scan_now");
+  }
+  setTimeout(() => { button.textContent = t("scan_now"); }, 5000);
+}
+
+// collectNow consulta la impresora abierta en el momento y redibuja el detalle
+async function collectNow() {
+  const button = document.getElementById("detail-collect");
+  const id = selected;
+  button.disabled = true;
+  button.textContent = t("collecting");
+  try {
+    await api("/api/printers/" + encodeURIComponent(id) + "/collect", { method: "POST" });
+    button.textContent = t("collect_now");
+    if (selected === id) showDetail(id, false);
+  } catch (err) {
+    button.textContent = t("collect_failed");
+    setTimeout(() => { button.textContent = t("collect_now"); }, 5000);
+  } finally {
+    button.disabled = false;
+  }
+}
+
+async function refresh() {
+  try {
+    const [status, printers, alerts, user] = await Promise.all([
+      api("/api/status"), api("/api/printers"), api("/api/alerts"), api("/api/whoami"),
+    ]);
+    document.getElementById("login").hidden = true;
+    connect();
+    renderUser(user);
+    renderStatus(status);
+    setLive(live);
+    renderFleet(printers);
+    renderHeatmap(printers);
+    renderAlerts(alerts);
+    if (selected && !document.getElementById("detail").hidden) showDetail(selected, false);
+  } catch (err) {
+    if (!(err instanceof Unauthorized)) console.error(err);
+  }
+}
+
+// scheduleRefresh agrupa las ráfagas de eventos de un ciclo en un solo refresh
+function scheduleRefresh() {
+  if (pending) return;
+  pending = setTimeout(() => {
+    pending = null;
+    refresh();
+  }, LIVE_DEBOUNCE_MS);
+}
+
+function setLive(value) {
+  live = value;
+  const node = document.getElementById("live");
+  node.textContent = t(value ? "live" : "offline");
+  node.className = value ? "on" : "";
+}
+
+// connect abre el stream /api/events; EventSource reconecta solo si se corta
+function connect() {
+  if (!window.EventSource || source) return;
+  // EventSource no permite headers: la credencial va como access_token
+  const url = token() ? "/api/events?access_token=" + encodeURIComponent(token()) : "/api/events";
+  source = new EventSource(url);
+  source.onopen = () => setLive(true);
+  source.onerror = () => setLive(false);
+  for (const type of ["telemetry", "alert", "alert_resolved", "scan_completed", "printer_removed"]) {
+    source.addEventListener(type, scheduleRefresh);
+  }
+  source.addEventListener("scan_progress", (event) => renderProgress(JSON.parse(event.data).data));
+}
+
+document.getElementById("login-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  localStorage.setItem(TOKEN_KEY, document.getElementById("login-token").value.trim());
+  refresh();
+});
+
+document.getElementById("scan-now").addEventListener("click", scanNow);
+
+document.getElementById("detail-collect").addEventListener("click", collectNow);
+
+document.getElementById("detail-close").addEventListener("click", () => {
+  document.getElementById("detail").hidden = true;
+  selected = null;
+});
+
+applyTexts();
+refresh();
+// Con el stream conectado el polling es solo un respaldo
+setInterval(() => {
+  if (!live) refresh();
+}, REFRESH_MS);
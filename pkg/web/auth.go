@@ -0,0 +1,191 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// Role es el nivel de acceso a la API
+type Role int
+
+const (
+	RoleNone   Role = iota
+	RoleViewer      // lectura: dashboard, impresoras, alertas, eventos
+	RoleAdmin       // además dispara escaneos y edita la configuración
+)
+
+// String retorna el nombre del rol ("viewer", "admin")
+func (r Role) String() string {
+	switch r {
+	case RoleAdmin:
+		return "admin"
+	case RoleViewer:
+		return "viewer"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole interpreta el rol de config.yaml; desconocido → viewer (mínimo privilegio)
+func ParseRole(s string) Role {
+	if strings.EqualFold(strings.TrimSpace(s), "admin") {
+		return RoleAdmin
+	}
+	return RoleViewer
+}
+
+// APIKey es una llave estática con su rol
+type APIKey struct {
+	Name string
+	Key  string // valor en claro (ya resuelto del vault)
+	Role Role
+}
+
+// AuthConfig configura la autenticación de la API
+// Sin llaves ni OIDC la API de lectura queda abierta y las acciones de
+// administración solo se aceptan desde localhost
+type AuthConfig struct {
+	APIKeys []APIKey
+	OIDC    *OIDCConfig
+}
+
+// Principal es la identidad autenticada de un request
+type Principal struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Method string `json:"method"` // "api_key", "oidc", "anonymous", "local"
+	role   Role
+}
+
+// ErrUnauthorized indica credenciales ausentes o inválidas
+var ErrUnauthorized = errors.New("unauthorized")
+
+type principalKey struct{}
+
+// authenticator valida las credenciales de cada request
+type authenticator struct {
+	keys []hashedKey
+	oidc *oidcVerifier
+}
+
+// hashedKey guarda el hash de la llave: la comparación es de largo fijo y en tiempo constante
+type hashedKey struct {
+	name string
+	hash [sha256.Size]byte
+	role Role
+}
+
+func newAuthenticator(config AuthConfig) *authenticator {
+	a := &authenticator{}
+	for _, k := range config.APIKeys {
+		if k.Key == "" {
+			continue
+		}
+		a.keys = append(a.keys, hashedKey{name: k.Name, hash: sha256.Sum256([]byte(k.Key)), role: k.Role})
+	}
+	if config.OIDC != nil && config.OIDC.Issuer != "" {
+		a.oidc = newOIDCVerifier(*config.OIDC)
+	}
+	return a
+}
+
+// enabled indica si hay algún mecanismo de autenticación configurado
+func (a *authenticator) enabled() bool {
+	return len(a.keys) > 0 || a.oidc != nil
+}
+
+// authenticate identifica al cliente del request
+func (a *authenticator) authenticate(r *http.Request) (Principal, error) {
+	if !a.enabled() {
+		if isLoopback(r) {
+			return newPrincipal("localhost", RoleAdmin, "local"), nil
+		}
+		return newPrincipal("anonymous", RoleViewer, "anonymous"), nil
+	}
+
+	token := requestToken(r)
+	if token == "" {
+		return Principal{}, ErrUnauthorized
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash[:]) == 1 {
+			return newPrincipal(k.name, k.role, "api_key"), nil
+		}
+	}
+
+	// Un JWT tiene tres partes separadas por punto
+	if a.oidc != nil && strings.Count(token, ".") == 2 {
+		name, role, err := a.oidc.verify(r.Context(), token)
+		if err != nil {
+			return Principal{}, err
+		}
+		return newPrincipal(name, role, "oidc"), nil
+	}
+
+	return Principal{}, ErrUnauthorized
+}
+
+func newPrincipal(name string, role Role, method string) Principal {
+	return Principal{Name: name, Role: role.String(), Method: method, role: role}
+}
+
+// requestToken extrae la credencial: Authorization: Bearer, X-API-Key o
+// ?access_token= (EventSource no permite headers propios)
+func requestToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if scheme, token, ok := strings.Cut(h, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// isLoopback indica si el request viene de la misma máquina
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// require envuelve un handler exigiendo al menos el rol indicado
+func (s *Server) require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := s.auth.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="printsnmp"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if principal.role < role {
+			log.Print(i18n.T("log.web_forbidden", principal.Name, r.Method, r.URL.Path))
+			writeError(w, http.StatusForbidden, "forbidden: requires "+role.String())
+			return
+		}
+		if !s.limit(w, r, principal) {
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	}
+}
+
+// principalFrom retorna la identidad autenticada del request
+func principalFrom(r *http.Request) Principal {
+	p, _ := r.Context().Value(principalKey{}).(Principal)
+	return p
+}
@@ -0,0 +1,204 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+)
+
+// tsIdentifier es una propiedad que TypeScript acepta sin comillas
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScriptClient genera un cliente TypeScript (fetch) de las mismas rutas que
+// documenta OpenAPI: una interfaz por schema de components y un método por
+// operación. El stream SSE queda afuera: EventSource no manda el Bearer
+func TypeScriptClient(version string, withGraphQL bool) []byte {
+	// Sin "Error": chocaría con el Error global; los errores son PrintsnmpError
+	b := &openAPIBuilder{components: map[string]*schema.Schema{}}
+
+	var methods bytes.Buffer
+	for _, rt := range (&Server{}).routes() {
+		if (rt.graphql && !withGraphQL) || rt.content == "text/event-stream" {
+			continue
+		}
+		methods.WriteString(b.tsMethod(rt))
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Cliente de la API REST de printsnmp %s\n", version)
+	out.WriteString("// Generado por `printsnmp openapi`: no editar a mano\n\n")
+	for _, name := range componentNames(b.components) {
+		fmt.Fprintf(&out, "export interface %s %s\n\n", name, tsType(b.components[name], ""))
+	}
+	out.WriteString(tsRuntime)
+	out.Write(methods.Bytes())
+	out.WriteString("}\n")
+	return out.Bytes()
+}
+
+// tsRuntime es la parte fija del cliente: el error y el request genérico
+const tsRuntime = `export class PrintsnmpError extends Error {
+  constructor(public status: number, message: string) {
+    super(message);
+  }
+}
+
+export type QueryValue = string | number | boolean | Array<string | number> | undefined;
+
+export class PrintsnmpClient {
+  constructor(private baseUrl: string, private token?: string) {}
+
+  private async request<T>(method: string, path: string, query?: Record<string, QueryValue>, body?: unknown, contentType = "application/json"): Promise<T> {
+    const url = new URL(path, this.baseUrl);
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value === undefined) continue;
+      for (const item of Array.isArray(value) ? value : [value]) url.searchParams.append(key, String(item));
+    }
+    const headers: Record<string, string> = {};
+    if (this.token) headers.Authorization = "Bearer " + this.token;
+    let payload: string | undefined;
+    if (body !== undefined) {
+      headers["Content-Type"] = contentType;
+      payload = contentType === "application/json" ? JSON.stringify(body) : String(body);
+    }
+    const res = await fetch(url, { method, headers, body: payload });
+    if (!res.ok) {
+      const err = await res.json().catch(() => ({ error: res.statusText }));
+      throw new PrintsnmpError(res.status, err.error ?? res.statusText);
+    }
+    const json = res.headers.get("Content-Type")?.includes("json");
+    return (json ? res.json() : res.text()) as Promise<T>;
+  }
+`
+
+// tsMethod genera el método de una ruta
+func (b *openAPIBuilder) tsMethod(rt route) string {
+	var args []string
+	path := "`" + pathParam.ReplaceAllString(rt.path, "$${encodeURIComponent($1)}") + "`"
+	for _, m := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+		args = append(args, m[1]+": string")
+	}
+	if rt.request != nil {
+		args = append(args, "body: "+b.tsTypeFor(rt.request))
+	}
+	query := "undefined"
+	if len(rt.params) > 0 {
+		var fields []string
+		for _, p := range rt.params {
+			kind := "string | string[]"
+			if p.kind == "integer" {
+				kind = "number"
+			}
+			fields = append(fields, tsProperty(p.name)+"?: "+kind)
+		}
+		args = append(args, "query: { "+strings.Join(fields, "; ")+" } = {}")
+		query = "query"
+	}
+
+	call := fmt.Sprintf("this.request(%q, %s, %s", rt.method, path, query)
+	switch {
+	case rt.content != "":
+		body := "undefined"
+		if rt.request != nil {
+			body = "body"
+		}
+		call += fmt.Sprintf(", %s, %q", body, rt.content)
+	case rt.request != nil:
+		call += ", body"
+	}
+
+	return fmt.Sprintf("\n  /** %s (%s) */\n  %s(%s): Promise<%s> {\n    return %s);\n  }\n",
+		rt.summary, rt.role, rt.id, strings.Join(args, ", "), b.tsTypeFor(rt.response), call)
+}
+
+// tsTypeFor es el tipo TypeScript de un cuerpo: el nombre de la interfaz si
+// es un struct con nombre, si no el tipo en línea
+func (b *openAPIBuilder) tsTypeFor(v interface{}) string {
+	if s, ok := v.(*schema.Schema); ok {
+		return tsType(s, "  ")
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct && t.Elem().Name() != "":
+		b.component(t.Elem())
+		return t.Elem().Name() + "[]"
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		b.component(t)
+		return t.Name()
+	}
+	return tsType(schema.Generate(v), "  ")
+}
+
+// tsType traduce un schema a un tipo TypeScript en línea
+func tsType(s *schema.Schema, indent string) string {
+	if len(s.Type) == 0 {
+		return "unknown"
+	}
+	var variants []string
+	for _, t := range s.Type {
+		switch t {
+		case schema.TypeString:
+			variants = append(variants, "string")
+		case schema.TypeInteger, schema.TypeNumber:
+			variants = append(variants, "number")
+		case schema.TypeBoolean:
+			variants = append(variants, "boolean")
+		case schema.TypeNull:
+			variants = append(variants, "null")
+		case schema.TypeArray:
+			item := "unknown"
+			if s.Items != nil {
+				item = tsType(s.Items, indent)
+			}
+			if s.Items != nil && len(s.Items.Type) > 1 {
+				item = "(" + item + ")"
+			}
+			variants = append(variants, item+"[]")
+		case schema.TypeObject:
+			variants = append(variants, tsObject(s, indent))
+		}
+	}
+	return strings.Join(variants, " | ")
+}
+
+// tsObject traduce un objeto: sus propiedades o un Record si es un mapa
+func tsObject(s *schema.Schema, indent string) string {
+	if len(s.Properties) == 0 {
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + tsType(s.AdditionalProperties, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for _, name := range componentNames(s.Properties) {
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(&out, "%s  %s%s: %s;\n", indent, tsProperty(name), optional, tsType(s.Properties[name], indent+"  "))
+	}
+	out.WriteString(indent + "}")
+	return out.String()
+}
+
+// tsProperty pone entre comillas las propiedades que no son identificadores
+func tsProperty(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
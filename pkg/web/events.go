@@ -0,0 +1,152 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// Tipos de evento del stream /api/events
+const (
+	EventTelemetry      = "telemetry"       // nueva telemetría de una impresora
+	EventAlert          = "alert"           // alerta nueva (no estaba activa en el poll anterior)
+	EventAlertResolved  = "alert_resolved"  // alerta que dejó de reportarse
+	EventScanCompleted  = "scan_completed"  // fin de ciclo con el resumen
+	EventScanProgress   = "scan_progress"   // avance del discovery o la recolección en curso
+	EventPrinterRemoved = "printer_removed" // impresora dada de baja (ya no se reporta)
+)
+
+// subscriberBuffer es cuántos eventos puede atrasarse un cliente antes de
+// empezar a perderlos (un cliente lento nunca frena el pipeline)
+const subscriberBuffer = 256
+
+// keepaliveInterval mantiene viva la conexión a través de proxies
+const keepaliveInterval = 15 * time.Second
+
+// Event es un evento del stream en vivo
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Subscribe registra un suscriptor de eventos en vivo
+// La función retornada lo da de baja y cierra el canal
+func (s *Store) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// publish envía el evento a todos los suscriptores sin bloquear
+// Se llama con s.mu tomado
+func (s *Store) publish(eventType string, data interface{}) {
+	s.seq++
+	event := Event{ID: s.seq, Type: eventType, Time: time.Now().UTC(), Data: data}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// alertEvents publica las alertas nuevas y resueltas de t respecto de prev
+// Se llama con s.mu tomado
+func (s *Store) alertEvents(prev, t *telemetry.Telemetry) {
+	before := make(map[string]telemetry.AlertInfo)
+	if prev != nil {
+		for _, a := range prev.Alerts {
+			before[a.ID] = a
+		}
+	}
+
+	now := make(map[string]bool, len(t.Alerts))
+	for _, a := range t.Alerts {
+		now[a.ID] = true
+		if _, ok := before[a.ID]; !ok {
+			s.publish(EventAlert, newFleetAlert(t, a))
+		}
+	}
+	if prev == nil {
+		return
+	}
+	for _, a := range prev.Alerts {
+		if !now[a.ID] {
+			s.publish(EventAlertResolved, newFleetAlert(t, a))
+		}
+	}
+}
+
+// handleEvents sirve el stream Server-Sent Events
+// ?types=alert,alert_resolved limita los tipos de evento recibidos
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	var types map[string]bool
+	if q := r.URL.Query().Get("types"); q != "" {
+		types = make(map[string]bool)
+		for _, t := range strings.Split(q, ",") {
+			types[strings.TrimSpace(t)] = true
+		}
+	}
+
+	events, unsubscribe := s.store.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx: no bufferear el stream
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if types != nil && !types[event.Type] {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
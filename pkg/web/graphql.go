@@ -0,0 +1,153 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/graphql"
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// maxGraphQLBytes limita el cuerpo de POST /api/graphql
+const maxGraphQLBytes = 64 << 10
+
+// printerQueryArgs son los argumentos de printers: los mismos de GET /api/printers
+var printerQueryArgs = []string{"brand", "status", "supply_below", "supply_type", "site", "tag", "q", "scan_id", "sort", "limit", "offset"}
+
+// newGraphQLSchema arma los campos raíz de /api/graphql sobre el store
+//
+//	status                                 agente, último ciclo y totales
+//	printers(brand:, status:, ..., limit:) telemetría completa, con los filtros de PrinterQuery
+//	printer(id:)                           última telemetría de una impresora
+//	alerts(severity:)                      alertas activas de la flota
+//	history(id:, days:)                    historial de contadores (solo con controller)
+func (s *Server) newGraphQLSchema() *graphql.Schema {
+	return &graphql.Schema{Query: map[string]graphql.Field{
+		"status": {
+			Type:    schema.Generate(StatusResponse{}),
+			Resolve: func(map[string]interface{}) (interface{}, error) { return s.status(), nil },
+		},
+		"printers": {
+			Args: printerQueryArgs,
+			Type: schema.Generate([]telemetry.Telemetry{}),
+			Resolve: func(args map[string]interface{}) (interface{}, error) {
+				query, err := parsePrinterQuery(argValues(args))
+				if err != nil {
+					return nil, err
+				}
+				printers, _ := s.store.QueryTelemetry(query)
+				return printers, nil
+			},
+		},
+		"printer": {
+			Args: []string{"id"},
+			Type: schema.Generate(&telemetry.Telemetry{}),
+			Resolve: func(args map[string]interface{}) (interface{}, error) {
+				t, _ := s.store.Printer(argValues(args).Get("id"))
+				return t, nil
+			},
+		},
+		"alerts": {
+			Args: []string{"severity"},
+			Type: schema.Generate([]FleetAlert{}),
+			Resolve: func(args map[string]interface{}) (interface{}, error) {
+				severities := listParam(argValues(args), "severity")
+				alerts := []FleetAlert{}
+				for _, a := range s.store.Alerts() {
+					if len(severities) == 0 || containsFold(severities, a.Severity) {
+						alerts = append(alerts, a)
+					}
+				}
+				return alerts, nil
+			},
+		},
+		"history": {
+			Args: []string{"id", "days"},
+			Type: schema.Generate(&collector.PrinterHistory{}),
+			Resolve: func(args map[string]interface{}) (interface{}, error) {
+				if s.controller == nil {
+					return nil, errors.New("not available in this mode")
+				}
+				values := argValues(args)
+				days, err := intParam(values, "days", 0, -1)
+				if err != nil {
+					return nil, err
+				}
+				last, ok := s.store.Printer(values.Get("id"))
+				if !ok {
+					return nil, nil
+				}
+				var since time.Time
+				if days > 0 {
+					since = time.Now().AddDate(0, 0, -days)
+				}
+				return s.controller.PrinterHistory(last, since)
+			},
+		},
+	}}
+}
+
+// argValues pasa los argumentos GraphQL a parámetros de query string, para
+// interpretarlos igual que la API REST; una lista repite el parámetro
+func argValues(args map[string]interface{}) url.Values {
+	values := url.Values{}
+	for name, v := range args {
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			if item != nil {
+				values.Add(name, argString(item))
+			}
+		}
+	}
+	return values
+}
+
+// argString formatea un escalar GraphQL (los números de las variables llegan como float64)
+func argString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return fmt.Sprint(v)
+}
+
+// handleGraphQL atiende POST /api/graphql ({"query", "operationName",
+// "variables"}) y GET con los mismos parámetros en la URL
+// Los errores de la consulta van en "errors" con status 200, como pide GraphQL
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "body must be a JSON object with a query")
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.graphql.Execute(req))
+}
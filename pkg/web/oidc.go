@@ -0,0 +1,418 @@
+package web
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDCConfig valida bearer tokens (JWT) emitidos por un proveedor OpenID Connect
+// (Azure AD / Entra ID, Keycloak, Okta, Google Workspace...)
+type OIDCConfig struct {
+	Issuer      string   // "https://login.microsoftonline.com/<tenant>/v2.0"
+	Audience    string   // client_id registrado para el agente
+	RolesClaim  string   // claim con los roles/grupos; admite rutas ("realm_access.roles")
+	AdminRoles  []string // valores del claim que otorgan admin
+	ViewerRoles []string // valores que otorgan viewer (vacío = cualquier token válido)
+}
+
+const (
+	// jwksRefreshInterval renueva las llaves del proveedor (rotación)
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefresh evita martillar al proveedor con tokens de kid desconocido
+	jwksMinRefresh = time.Minute
+	// clockLeeway tolera relojes levemente desfasados en exp/nbf
+	clockLeeway = time.Minute
+)
+
+// oidcVerifier verifica firma y claims de los JWT contra el JWKS del issuer
+type oidcVerifier struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu         sync.Mutex
+	keys       map[string]crypto.PublicKey // kid → llave
+	fetchedAt  time.Time
+	fetchErr   error         // error de la última descarga fallida
+	failedAt   time.Time     // tras un error no se reintenta antes de jwksMinRefresh
+	refreshing chan struct{} // se cierra al terminar la descarga en curso (nil = ninguna)
+}
+
+func newOIDCVerifier(config OIDCConfig) *oidcVerifier {
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+	return &oidcVerifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// jwtHeader es el encabezado del JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verify valida el token y retorna el nombre del usuario y su rol
+func (v *oidcVerifier) verify(ctx context.Context, token string) (string, Role, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", RoleNone, ErrUnauthorized
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", RoleNone, fmt.Errorf("%w: header: %v", ErrUnauthorized, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", RoleNone, fmt.Errorf("%w: firma: %v", ErrUnauthorized, err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return "", RoleNone, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return "", RoleNone, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", RoleNone, fmt.Errorf("%w: claims: %v", ErrUnauthorized, err)
+	}
+	if err := v.checkClaims(claims, time.Now()); err != nil {
+		return "", RoleNone, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	role := v.role(claims)
+	if role == RoleNone {
+		return "", RoleNone, fmt.Errorf("%w: sin rol asignado", ErrUnauthorized)
+	}
+	return claimName(claims), role, nil
+}
+
+// checkClaims valida iss, aud, exp y nbf
+func (v *oidcVerifier) checkClaims(claims map[string]interface{}, now time.Time) error {
+	if iss, _ := claims["iss"].(string); strings.TrimRight(iss, "/") != v.config.Issuer {
+		return fmt.Errorf("issuer inesperado %q", iss)
+	}
+
+	// Sin audience cualquier token que el issuer firme para otra aplicación
+	// serviría acá: Config.Validate lo exige junto con issuer
+	if v.config.Audience == "" || !containsString(claimStrings(claims["aud"]), v.config.Audience) {
+		return fmt.Errorf("audience no incluye %q", v.config.Audience)
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token sin exp")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockLeeway)) {
+		return errors.New("token vencido")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token todavía no válido")
+	}
+	return nil
+}
+
+// role mapea el claim de roles a un Role
+func (v *oidcVerifier) role(claims map[string]interface{}) Role {
+	values := claimStrings(claimPath(claims, v.config.RolesClaim))
+
+	for _, value := range values {
+		if containsString(v.config.AdminRoles, value) {
+			return RoleAdmin
+		}
+	}
+	if len(v.config.ViewerRoles) == 0 {
+		return RoleViewer
+	}
+	for _, value := range values {
+		if containsString(v.config.ViewerRoles, value) {
+			return RoleViewer
+		}
+	}
+	return RoleNone
+}
+
+// key retorna la llave pública del kid, renovando el JWKS si hace falta
+// La descarga corre sin el lock: los requests con una llave conocida siguen
+// validándose y los demás esperan a esa única descarga
+func (v *oidcVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	stale := time.Since(v.fetchedAt) > jwksRefreshInterval
+	_, known := v.keys[kid]
+	refresh := stale || (!known && time.Since(v.fetchedAt) > jwksMinRefresh)
+	if !refresh || time.Since(v.failedAt) <= jwksMinRefresh {
+		defer v.mu.Unlock()
+		return v.lookup(kid)
+	}
+
+	if done := v.refreshing; done != nil {
+		if known {
+			defer v.mu.Unlock()
+			return v.lookup(kid)
+		}
+		v.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		return v.lookup(kid)
+	}
+
+	done := make(chan struct{})
+	v.refreshing = done
+	v.mu.Unlock()
+
+	// Sin cancelar con el request: la descarga también es de los que esperan
+	keys, err := v.fetchKeys(context.WithoutCancel(ctx))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fetchErr = err
+	if err == nil {
+		v.keys = keys
+		v.fetchedAt = time.Now()
+	} else {
+		v.failedAt = time.Now()
+	}
+	v.refreshing = nil
+	close(done)
+	return v.lookup(kid)
+}
+
+// lookup busca el kid en las llaves descargadas; requiere v.mu
+func (v *oidcVerifier) lookup(kid string) (crypto.PublicKey, error) {
+	if v.keys == nil && v.fetchErr != nil {
+		return nil, v.fetchErr
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// Proveedores con una sola llave suelen omitir kid
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q desconocido", ErrUnauthorized, kid)
+}
+
+// fetchKeys descubre jwks_uri en el issuer y descarga las llaves
+func (v *oidcVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, v.config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery sin jwks_uri")
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue // tipos de llave no soportados se ignoran
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("oidc: el JWKS no tiene llaves de firma soportadas")
+	}
+	return keys, nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s respondió %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jwk es una llave del JWKS (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("curva %s no soportada", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("kty %s no soportado", k.Kty)
+}
+
+// verifySignature verifica la firma JWS (RS256/384/512, ES256/384)
+// "none" y los algoritmos HMAC se rechazan: el secreto sería la llave pública
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("alg %q no soportado", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("alg %s no corresponde a una llave RSA", alg)
+		}
+		return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+			return errors.New("firma ECDSA inválida")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("firma ECDSA inválida")
+		}
+		return nil
+	}
+	return errors.New("tipo de llave no soportado")
+}
+
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// claimPath resuelve claims anidados ("realm_access.roles" en Keycloak)
+func claimPath(claims map[string]interface{}, path string) interface{} {
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// claimStrings normaliza un claim string o []string
+func claimStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// claimName retorna el nombre legible del usuario para logs
+func claimName(claims map[string]interface{}) string {
+	for _, claim := range []string{"preferred_username", "email", "upn", "sub"} {
+		if s, ok := claims[claim].(string); ok && s != "" {
+			return s
+		}
+	}
+	return "oidc"
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckClaimsAudience(t *testing.T) {
+	now := time.Now()
+	claims := func(aud interface{}) map[string]interface{} {
+		return map[string]interface{}{"iss": "https://idp", "aud": aud, "exp": float64(now.Add(time.Hour).Unix())}
+	}
+
+	v := newOIDCVerifier(OIDCConfig{Issuer: "https://idp", Audience: "printsnmp"})
+	if err := v.checkClaims(claims("printsnmp"), now); err != nil {
+		t.Errorf("aud correcto: %v", err)
+	}
+	if err := v.checkClaims(claims([]interface{}{"otra", "printsnmp"}), now); err != nil {
+		t.Errorf("aud en lista: %v", err)
+	}
+	if err := v.checkClaims(claims("otra-app"), now); err == nil {
+		t.Error("se aceptó un token de otra aplicación")
+	}
+
+	// Sin audience configurado no se acepta ningún token
+	open := newOIDCVerifier(OIDCConfig{Issuer: "https://idp"})
+	if err := open.checkClaims(claims("otra-app"), now); err == nil {
+		t.Error("sin audience se aceptó un token")
+	}
+}
+
+// Una descarga lenta del JWKS no bloquea a los requests con llave conocida
+func TestKeyDoesNotBlockDuringRefresh(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer idp.Close()
+	defer close(release)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newOIDCVerifier(OIDCConfig{Issuer: idp.URL, Audience: "printsnmp"})
+	v.keys = map[string]crypto.PublicKey{"a": &priv.PublicKey}
+	v.fetchedAt = time.Now().Add(-2 * jwksRefreshInterval) // vencidas: el próximo request renueva
+
+	go v.key(context.Background(), "a")
+	<-started
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.key(context.Background(), "a")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("key con llave conocida: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("key quedó bloqueado por la descarga del JWKS")
+	}
+
+	// Un kid desconocido espera la descarga, pero respeta su ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := v.key(ctx, "b"); err != context.DeadlineExceeded {
+		t.Errorf("kid desconocido = %v, want DeadlineExceeded", err)
+	}
+}
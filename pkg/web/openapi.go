@@ -0,0 +1,166 @@
+package web
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+)
+
+// openAPIVersion es la versión del documento: desde 3.1 los schemas son JSON
+// Schema, los mismos que genera pkg/schema
+const openAPIVersion = "3.1.0"
+
+// pathParam encuentra los parámetros de path ({id}) de un patrón del mux
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// errorSchema es el cuerpo {"error": "..."} de writeError
+var errorSchema = &schema.Schema{
+	Type:       []string{schema.TypeObject},
+	Properties: map[string]*schema.Schema{"error": {Type: []string{schema.TypeString}}},
+	Required:   []string{"error"},
+}
+
+// OpenAPI genera el documento OpenAPI de la API REST a partir de las rutas
+// del servidor (withGraphQL incluye /api/graphql)
+func OpenAPI(version string, withGraphQL bool) map[string]interface{} {
+	b := &openAPIBuilder{components: map[string]*schema.Schema{"Error": errorSchema}}
+
+	paths := make(map[string]map[string]interface{})
+	for _, rt := range (&Server{}).routes() {
+		if rt.graphql && !withGraphQL {
+			continue
+		}
+		if paths[rt.path] == nil {
+			paths[rt.path] = make(map[string]interface{})
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = b.operation(rt)
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "printsnmp agent API",
+			"version":     version,
+			"description": "API REST del dashboard de `printsnmp serve`. Las credenciales (API key o token OIDC) van como Bearer; los roles son viewer (lectura) y admin.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []map[string][]string{{"bearer": {}}},
+	}
+}
+
+// openAPIBuilder junta en components los schemas de los tipos con nombre
+type openAPIBuilder struct {
+	components map[string]*schema.Schema
+}
+
+// operation describe una ruta
+func (b *openAPIBuilder) operation(rt route) map[string]interface{} {
+	content := rt.content
+	if content == "" {
+		content = "application/json"
+	}
+
+	var params []map[string]interface{}
+	for _, m := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+		params = append(params, map[string]interface{}{
+			"name": m[1], "in": "path", "required": true,
+			"schema": map[string]string{"type": "string"},
+		})
+	}
+	for _, p := range rt.params {
+		param := map[string]interface{}{"name": p.name, "in": "query", "schema": map[string]string{"type": p.kind}}
+		if p.description != "" {
+			param["description"] = p.description
+		}
+		params = append(params, param)
+	}
+
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	responses := map[string]interface{}{
+		strconv.Itoa(status): map[string]interface{}{
+			"description": http.StatusText(status),
+			"content":     map[string]interface{}{content: map[string]interface{}{"schema": b.schemaFor(rt.response)}},
+		},
+	}
+	errorContent := map[string]interface{}{"application/json": map[string]interface{}{"schema": b.ref("Error")}}
+	for _, code := range append([]int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests}, rt.errors...) {
+		responses[strconv.Itoa(code)] = map[string]interface{}{"description": http.StatusText(code), "content": errorContent}
+	}
+
+	op := map[string]interface{}{
+		"operationId":     rt.id,
+		"summary":         rt.summary,
+		"responses":       responses,
+		"x-required-role": rt.role.String(),
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if rt.request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{content: map[string]interface{}{"schema": b.schemaFor(rt.request)}},
+		}
+	}
+	return op
+}
+
+// schemaFor retorna el schema de v: una referencia a components si es un
+// struct con nombre (o una lista de ellos), si no el schema en línea
+func (b *openAPIBuilder) schemaFor(v interface{}) interface{} {
+	if s, ok := v.(*schema.Schema); ok {
+		return s
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct && t.Elem().Name() != "":
+		return map[string]interface{}{"type": "array", "items": b.component(t.Elem())}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return b.component(t)
+	}
+	return schema.Generate(v)
+}
+
+// component agrega el tipo a components (una sola vez) y retorna su referencia
+func (b *openAPIBuilder) component(t reflect.Type) map[string]string {
+	if _, ok := b.components[t.Name()]; !ok {
+		b.components[t.Name()] = schema.Generate(reflect.Zero(t).Interface())
+	}
+	return b.ref(t.Name())
+}
+
+func (b *openAPIBuilder) ref(name string) map[string]string {
+	return map[string]string{"$ref": "#/components/schemas/" + name}
+}
+
+// componentNames retorna los schemas de components ordenados (para generar clientes estables)
+func componentNames(components map[string]*schema.Schema) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleOpenAPI sirve el documento de las rutas habilitadas en este servidor
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPI(s.store.Source().Version, s.config.GraphQL))
+}
@@ -0,0 +1,217 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// maxPageLimit acota ?limit= de GET /api/printers
+const maxPageLimit = 1000
+
+// PrinterQuery filtra, ordena y pagina la flota de GET /api/printers
+// Los filtros vacíos no filtran; los de varios valores aceptan cualquiera
+type PrinterQuery struct {
+	Brands      []string          // ?brand=HP,Canon o ?brand=HP&brand=Canon (sin distinguir mayúsculas)
+	States      []string          // ?status=idle,error (estado del equipo)
+	SupplyBelow int               // ?supply_below=20: algún consumible con menos de N% de vida (0 = sin filtro)
+	SupplyTypes []string          // ?supply_type=toner: supply_below solo mira estos tipos
+	Sites       []string          // ?site=Central (site de las reglas de ubicación)
+	Tags        map[string]string // ?tag=cost_center:CC-12 (repetible: todas deben coincidir)
+	Text        string            // ?q=m402: texto en el modelo o en el nombre del catálogo
+	ScanID      string            // ?scan_id=<id>: última telemetría de ese ciclo
+	Sort        string            // ?sort=-page_count: campo de printerSorts; "-" = descendente (default ip)
+	Limit       int               // ?limit=50 (0 = todas)
+	Offset      int               // ?offset=100
+}
+
+// printerSorts son los campos de ?sort= y su comparación ascendente
+var printerSorts = map[string]func(a, b *PrinterSummary) bool{
+	"ip":           func(a, b *PrinterSummary) bool { return ipLess(a.IP, b.IP) },
+	"brand":        func(a, b *PrinterSummary) bool { return strings.ToLower(a.Brand) < strings.ToLower(b.Brand) },
+	"model":        func(a, b *PrinterSummary) bool { return strings.ToLower(a.Model) < strings.ToLower(b.Model) },
+	"status":       func(a, b *PrinterSummary) bool { return a.State < b.State },
+	"page_count":   func(a, b *PrinterSummary) bool { return a.PageCount < b.PageCount },
+	"alerts":       func(a, b *PrinterSummary) bool { return a.AlertCount < b.AlertCount },
+	"collected_at": func(a, b *PrinterSummary) bool { return a.CollectedAt.Before(b.CollectedAt) },
+	"supply":       func(a, b *PrinterSummary) bool { return lowestSupply(a.Supplies, nil) < lowestSupply(b.Supplies, nil) },
+}
+
+// parsePrinterQuery interpreta los parámetros de GET /api/printers
+func parsePrinterQuery(values url.Values) (PrinterQuery, error) {
+	q := PrinterQuery{
+		Brands:      listParam(values, "brand"),
+		States:      listParam(values, "status"),
+		SupplyTypes: listParam(values, "supply_type"),
+		Sites:       listParam(values, "site"),
+		Text:        strings.TrimSpace(values.Get("q")),
+		ScanID:      values.Get("scan_id"),
+		Sort:        values.Get("sort"),
+	}
+
+	var err error
+	if q.SupplyBelow, err = intParam(values, "supply_below", 0, 100); err != nil {
+		return q, err
+	}
+	if q.Limit, err = intParam(values, "limit", 0, maxPageLimit); err != nil {
+		return q, err
+	}
+	if q.Offset, err = intParam(values, "offset", 0, -1); err != nil {
+		return q, err
+	}
+	if _, ok := printerSorts[strings.TrimPrefix(q.Sort, "-")]; q.Sort != "" && !ok {
+		return q, fmt.Errorf("sort: unknown field %q", strings.TrimPrefix(q.Sort, "-"))
+	}
+
+	for _, tag := range values["tag"] {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return q, fmt.Errorf("tag: expected key:value, got %q", tag)
+		}
+		if q.Tags == nil {
+			q.Tags = make(map[string]string)
+		}
+		q.Tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return q, nil
+}
+
+// intParam lee un entero entre min y max (max < 0 = sin tope); ausente = 0
+func intParam(values url.Values, name string, min, max int) (int, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		if max < 0 {
+			return 0, fmt.Errorf("%s: expected an integer >= %d", name, min)
+		}
+		return 0, fmt.Errorf("%s: expected an integer between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+// listParam junta los valores de un parámetro repetible o separado por comas
+// (?brand=HP,Canon = ?brand=HP&brand=Canon)
+func listParam(values url.Values, name string) []string {
+	return splitList(strings.Join(values[name], ","))
+}
+
+// splitList separa "a, b,c" sin elementos vacíos
+func splitList(raw string) []string {
+	var list []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// matches indica si la telemetría cumple los filtros de la consulta
+func (q PrinterQuery) matches(t *telemetry.Telemetry) bool {
+	if q.ScanID != "" && t.ScanID != q.ScanID {
+		return false
+	}
+	if len(q.Brands) > 0 && !containsFold(q.Brands, t.Printer.Brand) {
+		return false
+	}
+	if len(q.States) > 0 {
+		state := "unknown"
+		if t.Status != nil {
+			state = t.Status.State
+		}
+		if !containsFold(q.States, state) {
+			return false
+		}
+	}
+	if len(q.Sites) > 0 && (t.Printer.Site == nil || !containsFold(q.Sites, t.Printer.Site.Site)) {
+		return false
+	}
+	for key, value := range q.Tags {
+		if got, ok := t.Printer.Tags[key]; !ok || !strings.EqualFold(got, value) {
+			return false
+		}
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		model := strings.ToLower(deref(t.Printer.Model))
+		if t.Printer.Catalog != nil {
+			model += " " + strings.ToLower(t.Printer.Catalog.Name)
+		}
+		if !strings.Contains(model, text) {
+			return false
+		}
+	}
+	if q.SupplyBelow > 0 && lowestSupply(t.Supplies, q.SupplyTypes) >= q.SupplyBelow {
+		return false
+	}
+	return true
+}
+
+// sortAndPage ordena los resultados y retorna la página pedida
+func (q PrinterQuery) sortAndPage(printers []PrinterSummary) []PrinterSummary {
+	field, desc := strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+	less, ok := printerSorts[field]
+	if !ok {
+		less = printerSorts["ip"]
+	}
+	// Empates por IP e ID: el orden es estable entre páginas
+	sort.SliceStable(printers, func(i, j int) bool {
+		a, b := &printers[i], &printers[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		if printers[i].IP != printers[j].IP {
+			return ipLess(printers[i].IP, printers[j].IP)
+		}
+		return printers[i].ID < printers[j].ID
+	})
+
+	if q.Offset >= len(printers) {
+		return []PrinterSummary{}
+	}
+	printers = printers[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(printers) {
+		printers = printers[:q.Limit]
+	}
+	return printers
+}
+
+// lowestSupply retorna el menor porcentaje de vida restante entre los
+// consumibles de los tipos dados (todos si types está vacío); en los que se
+// llenan (residuos) la vida es el espacio libre. 101 si ninguno informa nivel
+func lowestSupply(supplies []telemetry.SupplyInfo, types []string) int {
+	lowest := 101
+	for _, s := range supplies {
+		if s.LevelState != "" || (len(types) > 0 && !containsFold(types, s.Type)) {
+			continue
+		}
+		remaining := s.Percentage
+		if s.FillsUp {
+			remaining = 100 - s.Percentage
+		}
+		if remaining < lowest {
+			lowest = remaining
+		}
+	}
+	return lowest
+}
+
+// containsFold indica si list contiene s sin distinguir mayúsculas
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+)
+
+// RateLimitConfig limita cuánto puede pedir cada cliente a la API
+type RateLimitConfig struct {
+	RequestsPerMinute int           // por cliente (API key, usuario OIDC o IP); 0 = sin límite
+	Burst             int           // pedidos seguidos antes de frenar; 0 = RequestsPerMinute
+	ScanCooldown      time.Duration // mínimo entre escaneos disparados por POST /api/scan (de cualquier cliente)
+}
+
+// sweepInterval es cada cuánto se descartan los buckets de clientes inactivos
+const sweepInterval = time.Minute
+
+// rateLimiter es un token bucket por cliente
+type rateLimiter struct {
+	rate  float64 // tokens por segundo
+	burst float64
+
+	mu        sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	last    time.Time
+	limited bool // ya se registró en el log que está frenado
+}
+
+// newRateLimiter retorna nil si no hay límite por cliente
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if config.RequestsPerMinute <= 0 {
+		return nil
+	}
+	burst := config.Burst
+	if burst <= 0 {
+		burst = config.RequestsPerMinute
+	}
+	return &rateLimiter{
+		rate:    float64(config.RequestsPerMinute) / 60,
+		burst:   float64(burst),
+		clients: make(map[string]*bucket),
+	}
+}
+
+// allow consume un token del cliente; si no hay, retorna cuánto esperar y
+// si es el primer rechazo desde que volvió a tener tokens
+func (l *rateLimiter) allow(client string, now time.Time) (ok bool, retryAfter time.Duration, first bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, exists := l.clients[client]
+	if !exists {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.limited = false
+		return true, 0, false
+	}
+	first = !b.limited
+	b.limited = true
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), first
+}
+
+// sweep descarta los buckets que ya se llenaron: equivalen a uno nuevo
+func (l *rateLimiter) sweep(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.clients {
+		if now.Sub(b.last) >= full {
+			delete(l.clients, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// rateLimitKey identifica al cliente: la credencial si la hay, si no la IP
+// (sin autenticación todos comparten el mismo principal)
+func rateLimitKey(r *http.Request, p Principal) string {
+	if p.Method == "api_key" || p.Method == "oidc" {
+		return p.Method + ":" + p.Name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// limit aplica el límite del cliente; false si ya respondió 429
+func (s *Server) limit(w http.ResponseWriter, r *http.Request, p Principal) bool {
+	if s.limiter == nil {
+		return true
+	}
+	ok, retryAfter, first := s.limiter.allow(rateLimitKey(r, p), time.Now())
+	if ok {
+		return true
+	}
+	if first {
+		log.Print(i18n.T("log.web_rate_limited", p.Name, r.Method, r.URL.Path))
+	}
+	writeTooManyRequests(w, retryAfter, "rate limit exceeded")
+	return false
+}
+
+// writeTooManyRequests responde 429 con Retry-After en segundos (redondeado hacia arriba)
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, msg string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeError(w, http.StatusTooManyRequests, msg)
+}
+
+// scanGate espacia los escaneos disparados por la API
+type scanGate struct {
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// trigger llama a start si pasó el cooldown desde el último escaneo disparado
+// Retorna cuánto falta si todavía no pasó, o lo que retornó start
+func (g *scanGate) trigger(now time.Time, start func() bool) (wait time.Duration, started bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if left := g.cooldown - now.Sub(g.last); !g.last.IsZero() && left > 0 {
+		return left, false
+	}
+	if !start() {
+		return 0, false
+	}
+	g.last = now
+	return 0, true
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{RequestsPerMinute: 60, Burst: 3})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := l.allow("a", now); !ok {
+			t.Fatalf("pedido %d dentro del burst rechazado", i+1)
+		}
+	}
+	ok, retryAfter, first := l.allow("a", now)
+	if ok || !first {
+		t.Fatalf("pasado el burst: ok=%v first=%v", ok, first)
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retryAfter %v, se esperaba hasta 1s (1 token por segundo)", retryAfter)
+	}
+	// El rechazo se registra una vez hasta que el cliente vuelve a tener tokens
+	if _, _, first := l.allow("a", now); first {
+		t.Error("segundo rechazo marcado como el primero")
+	}
+
+	// Otro cliente tiene su propio bucket
+	if ok, _, _ := l.allow("b", now); !ok {
+		t.Error("el límite de un cliente frenó a otro")
+	}
+
+	// Un segundo después hay un token, y solo uno
+	now = now.Add(time.Second)
+	if ok, _, _ := l.allow("a", now); !ok {
+		t.Error("no se repuso el token")
+	}
+	if ok, _, first := l.allow("a", now); ok || !first {
+		t.Errorf("tras reponer: ok=%v first=%v, se esperaba un nuevo primer rechazo", ok, first)
+	}
+}
+
+func TestRateLimiterDisabledAndDefaultBurst(t *testing.T) {
+	if l := newRateLimiter(RateLimitConfig{}); l != nil {
+		t.Error("sin requests_per_minute debería no haber límite")
+	}
+	l := newRateLimiter(RateLimitConfig{RequestsPerMinute: 5})
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		if ok, _, _ := l.allow("a", now); !ok {
+			t.Fatalf("pedido %d rechazado: el burst por defecto es requests_per_minute", i+1)
+		}
+	}
+	if ok, _, _ := l.allow("a", now); ok {
+		t.Error("se pasó del burst por defecto")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{RequestsPerMinute: 60, Burst: 2})
+	now := time.Now()
+	l.allow("inactivo", now)
+	l.allow("activo", now.Add(sweepInterval-time.Second))
+
+	// El bucket inactivo ya se llenó (2s) y se descarta; el activo no
+	l.allow("activo", now.Add(sweepInterval))
+	if _, ok := l.clients["inactivo"]; ok {
+		t.Error("no se descartó el bucket lleno")
+	}
+	if _, ok := l.clients["activo"]; !ok {
+		t.Error("se descartó un bucket en uso")
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/printers", nil)
+	r.RemoteAddr = "10.0.0.7:51234"
+
+	for _, tc := range []struct {
+		principal Principal
+		want      string
+	}{
+		{Principal{Name: "ci", Method: "api_key"}, "api_key:ci"},
+		{Principal{Name: "ana@corp", Method: "oidc"}, "oidc:ana@corp"},
+		{Principal{Name: "anonymous", Method: "anonymous"}, "ip:10.0.0.7"},
+	} {
+		if got := rateLimitKey(r, tc.principal); got != tc.want {
+			t.Errorf("%s: clave %q, se esperaba %q", tc.principal.Method, got, tc.want)
+		}
+	}
+}
+
+func TestLimitResponds429(t *testing.T) {
+	s := &Server{limiter: newRateLimiter(RateLimitConfig{RequestsPerMinute: 1, Burst: 1})}
+	p := Principal{Name: "ci", Method: "api_key"}
+	r := httptest.NewRequest(http.MethodGet, "/api/printers", nil)
+
+	if !s.limit(httptest.NewRecorder(), r, p) {
+		t.Fatal("primer pedido rechazado")
+	}
+	w := httptest.NewRecorder()
+	if s.limit(w, r, p) {
+		t.Fatal("segundo pedido aceptado con burst 1")
+	}
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status %d, se esperaba 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After %q, se esperaba 60", got)
+	}
+}
+
+func TestScanGateCooldown(t *testing.T) {
+	g := &scanGate{cooldown: time.Minute}
+	now := time.Now()
+	starts := 0
+	start := func() bool { starts++; return true }
+
+	if _, started := g.trigger(now, start); !started {
+		t.Fatal("el primer escaneo no arrancó")
+	}
+	wait, started := g.trigger(now.Add(20*time.Second), start)
+	if started || wait != 40*time.Second {
+		t.Errorf("dentro del cooldown: started=%v wait=%v, se esperaba 40s", started, wait)
+	}
+	// Un escaneo que no arranca (ya hay uno en curso) no reinicia el cooldown
+	if _, started := g.trigger(now.Add(time.Minute), func() bool { return false }); started {
+		t.Error("se informó como arrancado un escaneo rechazado")
+	}
+	if _, started := g.trigger(now.Add(time.Minute), start); !started {
+		t.Error("pasado el cooldown no arrancó")
+	}
+	if starts != 2 {
+		t.Errorf("start se llamó %d veces, se esperaban 2", starts)
+	}
+}
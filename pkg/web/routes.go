@@ -0,0 +1,113 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/graphql"
+	"github.com/asaavedra/agent-snmp/pkg/schema"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// route es una operación de la API: NewServer la registra en el mux y
+// OpenAPI la documenta, así el documento no se desfasa de las rutas reales
+type route struct {
+	id       string // operationId ("listPrinters"): nombre del método en los clientes generados
+	method   string
+	path     string // patrón del mux; {id} es un parámetro de path
+	role     Role
+	summary  string
+	params   []queryParam
+	request  interface{} // valor del tipo del cuerpo (nil = sin cuerpo)
+	response interface{} // valor del tipo de la respuesta exitosa (o un *schema.Schema)
+	status   int         // status de la respuesta exitosa (0 = 200)
+	errors   []int       // otros status posibles además de 401, 403 y 429
+	content  string      // content type de cuerpo y respuesta ("" = application/json)
+	graphql  bool        // solo con Config.GraphQL
+	handler  http.HandlerFunc
+}
+
+// queryParam es un parámetro de query string de una ruta
+type queryParam struct {
+	name        string
+	kind        string // "string", "integer"
+	description string
+}
+
+// printerQueryParams son los parámetros de PrinterQuery
+var printerQueryParams = []queryParam{
+	{"brand", "string", "Marcas, separadas por comas o repitiendo el parámetro"},
+	{"status", "string", "Estados del equipo (idle, printing, error...)"},
+	{"supply_below", "integer", "Algún consumible con menos de N% de vida (1-100)"},
+	{"supply_type", "string", "Tipos de consumible que mira supply_below (toner, drum...)"},
+	{"site", "string", "Sites de las reglas de ubicación"},
+	{"tag", "string", "key:value; repetible, deben coincidir todos"},
+	{"q", "string", "Texto en el modelo o en el nombre de catálogo"},
+	{"scan_id", "string", "Solo la última telemetría de ese ciclo"},
+	{"sort", "string", "ip, brand, model, status, page_count, alerts, collected_at o supply; prefijo - = descendente"},
+	{"limit", "integer", "Tamaño de página (1-1000; sin límite si se omite)"},
+	{"offset", "integer", "Impresoras a saltear"},
+}
+
+// statusBody es la respuesta {"status": "..."} de las acciones
+var statusBody = &schema.Schema{
+	Type:       []string{schema.TypeObject},
+	Properties: map[string]*schema.Schema{"status": {Type: []string{schema.TypeString}}},
+	Required:   []string{"status"},
+}
+
+// tagsBody es el cuerpo de PUT /api/printers/{id}/tags
+var tagsBody = &schema.Schema{
+	Type:                 []string{schema.TypeObject},
+	AdditionalProperties: &schema.Schema{Type: []string{schema.TypeString}},
+}
+
+// routes retorna las rutas de la API (los handlers no se usan si s es un Server vacío)
+func (s *Server) routes() []route {
+	anyJSON := &schema.Schema{Type: []string{schema.TypeObject}}
+	return []route{
+		{id: "getStatus", method: "GET", path: "/api/status", role: RoleViewer,
+			summary: "Agente, último ciclo y totales", response: StatusResponse{}, handler: s.handleStatus},
+		{id: "listPrinters", method: "GET", path: "/api/printers", role: RoleViewer,
+			summary: "Resumen de la flota, filtrado y paginado (X-Total-Count = total antes de paginar)",
+			params:  printerQueryParams, response: []PrinterSummary{}, errors: []int{http.StatusBadRequest}, handler: s.handlePrinters},
+		{id: "getPrinter", method: "GET", path: "/api/printers/{id}", role: RoleViewer,
+			summary: "Última telemetría completa de una impresora", response: telemetry.Telemetry{},
+			errors: []int{http.StatusNotFound}, handler: s.handlePrinter},
+		{id: "getPrinterCoverage", method: "GET", path: "/api/printers/{id}/coverage", role: RoleViewer,
+			summary: "OIDs intentados y respondidos en el último poll", response: collector.CoverageReport{},
+			errors: []int{http.StatusNotFound}, handler: s.handleCoverage},
+		{id: "getPrinterTags", method: "GET", path: "/api/printers/{id}/tags", role: RoleViewer,
+			summary: "Tags asignados por la API y vigentes", response: TagsResponse{},
+			errors: []int{http.StatusNotImplemented}, handler: s.handleGetTags},
+		{id: "setPrinterTags", method: "PUT", path: "/api/printers/{id}/tags", role: RoleAdmin,
+			summary: "Reemplaza los tags asignados por la API (se aplican en el próximo ciclo)", request: tagsBody,
+			response: statusBody, errors: []int{http.StatusBadRequest, http.StatusNotImplemented}, handler: s.handlePutTags},
+		{id: "collectPrinter", method: "POST", path: "/api/printers/{id}/collect", role: RoleViewer,
+			summary: "Consulta la impresora en el momento y responde su telemetría", response: telemetry.Telemetry{},
+			errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusGatewayTimeout, http.StatusNotImplemented}, handler: s.handleCollect},
+		{id: "listAlerts", method: "GET", path: "/api/alerts", role: RoleViewer,
+			summary: "Alertas activas de la flota, críticas primero", response: []FleetAlert{}, handler: s.handleAlerts},
+		{id: "streamEvents", method: "GET", path: "/api/events", role: RoleViewer,
+			summary: "Stream Server-Sent Events en vivo (?types= limita los tipos)", content: "text/event-stream",
+			params: []queryParam{{"types", "string", "Tipos de evento separados por comas"}}, response: Event{}, handler: s.handleEvents},
+		{id: "whoami", method: "GET", path: "/api/whoami", role: RoleViewer,
+			summary: "Identidad y rol del cliente", response: Principal{}, handler: s.handleWhoami},
+		{id: "triggerScan", method: "POST", path: "/api/scan", role: RoleAdmin,
+			summary: "Dispara un ciclo de escaneo", response: statusBody, status: http.StatusAccepted,
+			errors: []int{http.StatusConflict, http.StatusNotImplemented}, handler: s.handleScan},
+		{id: "getConfig", method: "GET", path: "/api/config", role: RoleAdmin,
+			summary: "config.yaml tal como está en disco", content: "application/yaml",
+			response: &schema.Schema{Type: []string{schema.TypeString}}, errors: []int{http.StatusNotImplemented}, handler: s.handleGetConfig},
+		{id: "putConfig", method: "PUT", path: "/api/config", role: RoleAdmin,
+			summary: "Valida y reemplaza config.yaml (se aplica en el próximo ciclo)", content: "application/yaml",
+			request: &schema.Schema{Type: []string{schema.TypeString}}, response: statusBody,
+			errors: []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusNotImplemented}, handler: s.handlePutConfig},
+		{id: "graphql", method: "POST", path: "/api/graphql", role: RoleViewer, graphql: true,
+			summary: "Consulta GraphQL (printers, printer, alerts, status, history)", request: schema.Generate(graphql.Request{}),
+			response: anyJSON, errors: []int{http.StatusBadRequest}, handler: s.handleGraphQL},
+		{id: "graphqlGet", method: "GET", path: "/api/graphql", role: RoleViewer, graphql: true,
+			summary: "Consulta GraphQL por GET", params: []queryParam{{"query", "string", "Consulta"}, {"operationName", "string", ""}, {"variables", "string", "Variables en JSON"}},
+			response: anyJSON, errors: []int{http.StatusBadRequest}, handler: s.handleGraphQL},
+	}
+}
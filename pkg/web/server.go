@@ -0,0 +1,416 @@
+package web
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/graphql"
+	"github.com/asaavedra/agent-snmp/pkg/i18n"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// shutdownTimeout es cuánto se espera a que terminen los requests en curso
+const shutdownTimeout = 5 * time.Second
+
+// maxConfigBytes limita el cuerpo de PUT /api/config
+const maxConfigBytes = 1 << 20
+
+// maxTagsBytes limita el cuerpo de PUT /api/printers/{id}/tags
+const maxTagsBytes = 64 << 10
+
+// Errores de Controller.CollectPrinter con su propio status HTTP
+var (
+	ErrCollectInProgress = errors.New("collection already in progress for this printer") // 409
+	ErrPrinterNoResponse = errors.New("printer did not respond")                         // 504
+)
+
+// TagsResponse es el cuerpo de GET /api/printers/{id}/tags
+type TagsResponse struct {
+	Assigned  map[string]string `json:"assigned"`  // asignados por la API
+	Effective telemetry.Tags    `json:"effective"` // los de la última telemetría (config + API)
+}
+
+// Config configura el servidor del dashboard
+type Config struct {
+	Listen    string // "127.0.0.1:8080"
+	Auth      AuthConfig
+	GraphQL   bool // sirve /api/graphql
+	RateLimit RateLimitConfig
+}
+
+// Controller expone las acciones de administración del daemon
+// Sin controller (ej: replay) esas rutas responden 501
+type Controller interface {
+	// TriggerScan pide un ciclo inmediato; false si ya hay uno en curso o pedido
+	TriggerScan() bool
+	// Config retorna el config.yaml vigente
+	Config() ([]byte, error)
+	// UpdateConfig valida y guarda un config.yaml nuevo (se aplica en el próximo ciclo)
+	UpdateConfig(data []byte) error
+	// PrinterTags retorna los tags asignados por la API a una impresora
+	PrinterTags(printerID string) (map[string]string, error)
+	// SetPrinterTags reemplaza los tags asignados por la API (vacío = quitar); se aplican en el próximo ciclo
+	SetPrinterTags(printerID string, tags map[string]string) error
+	// CollectPrinter vuelve a consultar la impresora de last y retorna su telemetría fresca
+	CollectPrinter(ctx context.Context, last *telemetry.Telemetry) (*telemetry.Telemetry, error)
+	// PrinterHistory retorna el historial de contadores de la impresora de last desde since
+	PrinterHistory(last *telemetry.Telemetry, since time.Time) (*collector.PrinterHistory, error)
+}
+
+// Server sirve el dashboard embebido y la API REST sobre el Store
+//
+//	GET /api/status          agente, último ciclo y totales              (viewer)
+//	GET /api/printers        resumen de la flota; filtros y páginas en PrinterQuery (viewer)
+//	GET /api/printers/{id}   última telemetría completa de una impresora (viewer)
+//	GET /api/printers/{id}/coverage  OIDs intentados y respondidos en el último poll (viewer)
+//	GET /api/printers/{id}/tags      tags asignados por la API y vigentes   (viewer)
+//	PUT /api/printers/{id}/tags      reemplaza los tags asignados por la API (admin)
+//	POST /api/printers/{id}/collect  la consulta ya y responde su telemetría  (viewer)
+//	GET /api/alerts          alertas activas de la flota                 (viewer)
+//	GET /api/events          stream SSE en vivo                          (viewer)
+//	GET /api/whoami          identidad y rol del cliente                 (viewer)
+//	POST /api/scan           dispara un ciclo de escaneo                 (admin)
+//	GET|PUT /api/config      lee o reemplaza config.yaml                 (admin)
+//	GET|POST /api/graphql    consultas GraphQL sobre los mismos datos (viewer; con Config.GraphQL)
+//	GET /api/openapi.json    documento OpenAPI de estas rutas (público, ver routes.go)
+type Server struct {
+	config     Config
+	store      *Store
+	controller Controller
+	auth       *authenticator
+	mux        *http.ServeMux
+	graphql    *graphql.Schema // nil sin Config.GraphQL
+	limiter    *rateLimiter    // nil sin límite por cliente
+	scans      *scanGate
+}
+
+// StatusResponse es el cuerpo de GET /api/status
+type StatusResponse struct {
+	Source      telemetry.AgentSource  `json:"source"`
+	Locale      string                 `json:"locale"`
+	Printers    int                    `json:"printers"`
+	Alerts      map[string]int         `json:"alerts"`         // por severidad
+	Subscribers int                    `json:"subscribers"`    // clientes conectados a /api/events
+	EventsLost  int                    `json:"events_dropped"` // eventos perdidos por clientes lentos
+	LastScan    *telemetry.ScanSummary `json:"last_scan"`
+	Progress    *scanner.Progress      `json:"progress"` // avance del ciclo en curso o del último
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+// NewServer crea el servidor sobre el store; controller puede ser nil
+func NewServer(config Config, store *Store, controller Controller) *Server {
+	s := &Server{
+		config:     config,
+		store:      store,
+		controller: controller,
+		auth:       newAuthenticator(config.Auth),
+		mux:        http.NewServeMux(),
+		limiter:    newRateLimiter(config.RateLimit),
+		scans:      &scanGate{cooldown: config.RateLimit.ScanCooldown},
+	}
+
+	// Los assets y el documento OpenAPI son públicos: no contienen datos, la UI
+	// pide la llave ante un 401
+	static, _ := fs.Sub(assets, "assets")
+	s.mux.Handle("GET /", http.FileServerFS(static))
+	s.mux.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+
+	if config.GraphQL {
+		s.graphql = s.newGraphQLSchema()
+	}
+	for _, rt := range s.routes() {
+		if rt.graphql && !config.GraphQL {
+			continue
+		}
+		s.mux.HandleFunc(rt.method+" "+rt.path, s.require(rt.role, rt.handler))
+	}
+
+	return s
+}
+
+// AuthEnabled indica si la API exige credenciales
+func (s *Server) AuthEnabled() bool {
+	return s.auth.enabled()
+}
+
+// Handler retorna el http.Handler del servidor (útil para montarlo en otro mux)
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe atiende requests hasta que ctx se cancela
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.config.Listen,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Los streams de /api/events nunca quedan ociosos: se cortan al cancelar ctx
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+// status arma el cuerpo de GET /api/status
+func (s *Server) status() StatusResponse {
+	alerts := map[string]int{"critical": 0, "warning": 0, "info": 0}
+	for _, a := range s.store.Alerts() {
+		alerts[a.Severity]++
+	}
+
+	return StatusResponse{
+		Source:      s.store.Source(),
+		Locale:      string(i18n.Current()),
+		Printers:    len(s.store.Printers()),
+		Alerts:      alerts,
+		Subscribers: s.store.Subscribers(),
+		EventsLost:  s.store.Dropped(),
+		LastScan:    s.store.Summary(),
+		Progress:    s.store.Progress(),
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// handlePrinters sirve la flota, filtrada, ordenada y paginada según los
+// parámetros de PrinterQuery; X-Total-Count es el total antes de paginar
+func (s *Server) handlePrinters(w http.ResponseWriter, r *http.Request) {
+	query, err := parsePrinterQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	printers, total := s.store.QueryPrinters(query)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	writeJSON(w, http.StatusOK, printers)
+}
+
+func (s *Server) handlePrinter(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.store.Printer(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "printer not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.store.Coverage(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no coverage report for printer")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleGetTags(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	id := r.PathValue("id")
+	assigned, err := s.controller.PrinterTags(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := TagsResponse{Assigned: assigned}
+	if t, ok := s.store.Printer(id); ok {
+		resp.Effective = t.Printer.Tags
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePutTags(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	var tags map[string]string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTagsBytes)).Decode(&tags); err != nil {
+		writeError(w, http.StatusBadRequest, "body must be a JSON object of string values")
+		return
+	}
+	for k := range tags {
+		if strings.TrimSpace(k) == "" {
+			writeError(w, http.StatusBadRequest, "tag names cannot be empty")
+			return
+		}
+	}
+
+	id := r.PathValue("id")
+	if err := s.controller.SetPrinterTags(id, tags); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Print(i18n.T("log.web_tags_updated", id, principalFrom(r).Name))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "saved"})
+}
+
+// handleCollect consulta una impresora en el momento (ej: la mesa de ayuda
+// refresca el tóner durante una llamada) y responde la telemetría fresca
+// Es de lectura para quien la pide: basta el rol viewer
+func (s *Server) handleCollect(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	id := r.PathValue("id")
+	last, ok := s.store.Printer(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "printer not found")
+		return
+	}
+
+	t, err := s.controller.CollectPrinter(r.Context(), last)
+	switch {
+	case errors.Is(err, ErrCollectInProgress):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, ErrPrinterNoResponse):
+		writeError(w, http.StatusGatewayTimeout, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Print(i18n.T("log.web_printer_collected", id, principalFrom(r).Name))
+	s.store.Update(t)
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.store.Alerts())
+}
+
+func (s *Server) handleWhoami(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, principalFrom(r))
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	principal := principalFrom(r)
+	wait, started := s.scans.trigger(time.Now(), s.controller.TriggerScan)
+	switch {
+	case wait > 0:
+		writeTooManyRequests(w, wait, "scan cooldown: a scan was triggered recently")
+		return
+	case !started:
+		writeError(w, http.StatusConflict, "scan already in progress")
+		return
+	}
+	log.Print(i18n.T("log.web_scan_triggered", principal.Name))
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "scheduled"})
+}
+
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	data, err := s.controller.Config()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+func (s *Server) handlePutConfig(w http.ResponseWriter, r *http.Request) {
+	if s.controller == nil {
+		writeError(w, http.StatusNotImplemented, "not available in this mode")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err := s.controller.UpdateConfig(data); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log.Print(i18n.T("log.web_config_updated", principalFrom(r).Name))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "saved"})
+}
+
+// writeJSON responde v como JSON con el status dado
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError responde {"error": msg}
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// ipLess ordena IPs numéricamente (10.0.0.9 < 10.0.0.10); lo no parseable va al final
+func ipLess(a, b string) bool {
+	ia, errA := netip.ParseAddr(a)
+	ib, errB := netip.ParseAddr(b)
+	switch {
+	case errA != nil && errB != nil:
+		return a < b
+	case errA != nil:
+		return false
+	case errB != nil:
+		return true
+	}
+	return ia.Less(ib)
+}
@@ -0,0 +1,318 @@
+package web
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asaavedra/agent-snmp/pkg/collector"
+	"github.com/asaavedra/agent-snmp/pkg/scanner"
+	"github.com/asaavedra/agent-snmp/pkg/telemetry"
+)
+
+// Store mantiene en memoria la última telemetría de cada impresora y el
+// resumen del último ciclo. El pipeline de recolección escribe, la API lee
+// y los suscriptores de /api/events reciben cada cambio en vivo
+type Store struct {
+	mu       sync.RWMutex
+	printers map[string]*telemetry.Telemetry
+	coverage map[string]*collector.CoverageReport // OIDs del último poll por impresora
+	summary  *telemetry.ScanSummary
+	progress *scanner.Progress // avance del ciclo en curso (nil antes del primero)
+	source   telemetry.AgentSource
+
+	subscribers map[chan Event]struct{}
+	seq         uint64 // ID del último evento publicado
+	dropped     int    // eventos perdidos por suscriptores lentos
+}
+
+// FleetAlert es una alerta activa junto a la impresora que la reporta
+type FleetAlert struct {
+	PrinterID string `json:"printer_id"`
+	IP        string `json:"ip"`
+	Brand     string `json:"brand"`
+	Model     string `json:"model,omitempty"`
+	telemetry.AlertInfo
+}
+
+// PrinterSummary es la fila de la flota: lo justo para la tabla y el heatmap
+type PrinterSummary struct {
+	ID           string                 `json:"id"`
+	IP           string                 `json:"ip"`
+	Brand        string                 `json:"brand"`
+	Model        string                 `json:"model,omitempty"`
+	SerialNumber string                 `json:"serial_number,omitempty"`
+	Location     string                 `json:"location,omitempty"`
+	Site         string                 `json:"site,omitempty"` // site de las reglas de ubicación
+	State        string                 `json:"state"`
+	PageCount    int64                  `json:"page_count"`
+	Supplies     []telemetry.SupplyInfo `json:"supplies"`
+	AlertCount   int                    `json:"alert_count"`
+	Critical     bool                   `json:"critical"` // alguna alerta crítica activa
+	CollectedAt  time.Time              `json:"collected_at"`
+	ScanID       string                 `json:"scan_id,omitempty"` // ciclo de la última telemetría
+}
+
+// NewStore crea un store vacío para el agente source
+func NewStore(source telemetry.AgentSource) *Store {
+	return &Store{
+		printers:    make(map[string]*telemetry.Telemetry),
+		coverage:    make(map[string]*collector.CoverageReport),
+		source:      source,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Update reemplaza la telemetría de la impresora (la más reciente gana)
+// y publica la telemetría y las alertas nuevas/resueltas a los suscriptores
+func (s *Store) Update(t *telemetry.Telemetry) {
+	if t == nil || t.Printer.ID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.printers[t.Printer.ID]
+	if ok && prev.CollectedAt.After(t.CollectedAt) {
+		return
+	}
+	s.printers[t.Printer.ID] = t
+
+	s.publish(EventTelemetry, t)
+	s.alertEvents(prev, t)
+}
+
+// SetSummary registra el resumen del último ciclo y lo publica
+func (s *Store) SetSummary(summary *telemetry.ScanSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summary = summary
+	if summary != nil {
+		s.publish(EventScanCompleted, summary)
+	}
+}
+
+// SetProgress registra el avance del ciclo en curso y lo publica
+func (s *Store) SetProgress(progress scanner.Progress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress = &progress
+	s.publish(EventScanProgress, progress)
+}
+
+// Progress retorna el avance del último ciclo (nil antes del primero)
+func (s *Store) Progress() *scanner.Progress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.progress
+}
+
+// Subscribers retorna cuántos clientes están conectados al stream
+func (s *Store) Subscribers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.subscribers)
+}
+
+// Dropped retorna cuántos eventos se perdieron por suscriptores lentos
+func (s *Store) Dropped() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dropped
+}
+
+// Summary retorna el resumen del último ciclo (nil antes del primero)
+func (s *Store) Summary() *telemetry.ScanSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.summary
+}
+
+// Source retorna la identidad del agente
+func (s *Store) Source() telemetry.AgentSource {
+	return s.source
+}
+
+// Printer retorna la última telemetría de una impresora
+func (s *Store) Printer(id string) (*telemetry.Telemetry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.printers[id]
+	return t, ok
+}
+
+// SetCoverage registra el reporte de cobertura del último poll de una impresora
+func (s *Store) SetCoverage(printerID string, report *collector.CoverageReport) {
+	if printerID == "" || report == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coverage[printerID] = report
+}
+
+// Coverage retorna el reporte de cobertura del último poll de una impresora
+func (s *Store) Coverage(id string) (*collector.CoverageReport, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report, ok := s.coverage[id]
+	return report, ok
+}
+
+// Remove quita una impresora dada de baja y lo publica; sin cambios si no estaba
+func (s *Store) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.printers[id]
+	if !ok {
+		return
+	}
+	delete(s.printers, id)
+	delete(s.coverage, id)
+	s.publish(EventPrinterRemoved, newPrinterSummary(t))
+}
+
+// Printers retorna el resumen de la flota ordenado por IP
+func (s *Store) Printers() []PrinterSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	printers := make([]PrinterSummary, 0, len(s.printers))
+	for _, t := range s.printers {
+		printers = append(printers, newPrinterSummary(t))
+	}
+
+	sort.Slice(printers, func(i, j int) bool {
+		if printers[i].IP != printers[j].IP {
+			return ipLess(printers[i].IP, printers[j].IP)
+		}
+		return printers[i].ID < printers[j].ID
+	})
+	return printers
+}
+
+// QueryPrinters retorna la página de la flota que cumple la consulta y el
+// total de impresoras que la cumplen
+func (s *Store) QueryPrinters(q PrinterQuery) ([]PrinterSummary, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	printers := s.matching(q)
+	return q.sortAndPage(printers), len(printers)
+}
+
+// QueryTelemetry es QueryPrinters con la telemetría completa de cada impresora
+func (s *Store) QueryTelemetry(q PrinterQuery) ([]*telemetry.Telemetry, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	printers := s.matching(q)
+	page := q.sortAndPage(printers)
+	result := make([]*telemetry.Telemetry, 0, len(page))
+	for _, p := range page {
+		result = append(result, s.printers[p.ID])
+	}
+	return result, len(printers)
+}
+
+// matching retorna el resumen de las impresoras que cumplen los filtros (con s.mu tomado)
+func (s *Store) matching(q PrinterQuery) []PrinterSummary {
+	printers := []PrinterSummary{}
+	for _, t := range s.printers {
+		if q.matches(t) {
+			printers = append(printers, newPrinterSummary(t))
+		}
+	}
+	return printers
+}
+
+// Alerts retorna las alertas activas de toda la flota, críticas primero
+func (s *Store) Alerts() []FleetAlert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	alerts := []FleetAlert{}
+	for _, t := range s.printers {
+		for _, a := range t.Alerts {
+			alerts = append(alerts, newFleetAlert(t, a))
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		ri, rj := severityRank(alerts[i].Severity), severityRank(alerts[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		if alerts[i].IP != alerts[j].IP {
+			return ipLess(alerts[i].IP, alerts[j].IP)
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	return alerts
+}
+
+func newFleetAlert(t *telemetry.Telemetry, a telemetry.AlertInfo) FleetAlert {
+	return FleetAlert{
+		PrinterID: t.Printer.ID,
+		IP:        t.Printer.IP,
+		Brand:     t.Printer.Brand,
+		Model:     deref(t.Printer.Model),
+		AlertInfo: a,
+	}
+}
+
+func newPrinterSummary(t *telemetry.Telemetry) PrinterSummary {
+	p := PrinterSummary{
+		ID:           t.Printer.ID,
+		IP:           t.Printer.IP,
+		Brand:        t.Printer.Brand,
+		Model:        deref(t.Printer.Model),
+		SerialNumber: deref(t.Printer.SerialNumber),
+		State:        "unknown",
+		Supplies:     t.Supplies,
+		AlertCount:   len(t.Alerts),
+		CollectedAt:  t.CollectedAt,
+		ScanID:       t.ScanID,
+	}
+	if p.Supplies == nil {
+		p.Supplies = []telemetry.SupplyInfo{}
+	}
+	if t.Printer.Site != nil {
+		p.Site = t.Printer.Site.Site
+	}
+	if t.Status != nil {
+		p.State = t.Status.State
+		p.PageCount = t.Status.PageCount
+		p.Location = t.Status.SystemLocation
+	}
+	for _, a := range t.Alerts {
+		if a.Severity == "critical" {
+			p.Critical = true
+		}
+	}
+	return p
+}
+
+// severityRank ordena severidades: critical > warning > info
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}